@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"hr-platform/bff/internal/config"
+	"hr-platform/bff/internal/repository"
+	"hr-platform/bff/internal/signing"
+)
+
+const keysUsage = `usage: bff keys <command>
+
+  list                       show signing keys and when they retire
+  rotate [-alg EdDSA|RS256]  add a key that signs from now on; older keys keep
+         [-grace DURATION]   verifying for DURATION (default: longest token lifetime + 1m)
+  retire <kid>               stop a key verifying immediately, e.g. if it leaked`
+
+// runKeys manages access token signing keys. Running instances pick up changes within
+// signing.ReloadInterval.
+func runKeys(ctx context.Context, cfg *config.Config, repo *repository.SigningKeyRepository, args []string) error {
+	if len(args) == 0 {
+		return errors.New(keysUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		return listKeys(ctx, repo)
+
+	case "rotate":
+		fs := flag.NewFlagSet("rotate", flag.ContinueOnError)
+		alg := fs.String("alg", signing.DefaultAlgorithm, "signing algorithm")
+		// Impersonation tokens can outlive access tokens, up to an hour
+		longest := max(cfg.AccessTokenTTL(), time.Hour)
+		grace := fs.Duration("grace", longest+signing.ReloadInterval, "how long older keys keep verifying")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		k, err := signing.Rotate(ctx, repo, cfg.JWTSecret, *alg, *grace)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s key %s; older keys retire at %s\n",
+			k.Algorithm, k.ID, time.Now().Add(*grace).Format(time.RFC3339))
+		return nil
+
+	case "retire":
+		if len(args) != 2 {
+			return errors.New(keysUsage)
+		}
+		return retireKey(ctx, repo, args[1])
+	}
+	return errors.New(keysUsage)
+}
+
+func listKeys(ctx context.Context, repo *repository.SigningKeyRepository) error {
+	keys, err := repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KID\tALG\tCREATED\tSTATUS")
+	signingFound := false
+	for _, k := range keys {
+		status := "retired " + k.RetiredAt.Format(time.RFC3339)
+		switch {
+		case k.RetiredAt == nil && !signingFound:
+			status, signingFound = "signing", true
+		case k.RetiredAt == nil:
+			status = "verifying"
+		case k.Verifies(now):
+			status = "verifying until " + k.RetiredAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", k.ID, k.Algorithm, k.CreatedAt.Format(time.RFC3339), status)
+	}
+	return w.Flush()
+}
+
+// retireKey refuses to retire the last key that can sign, since running instances would
+// then be unable to issue tokens; rotate first instead.
+func retireKey(ctx context.Context, repo *repository.SigningKeyRepository, kid string) error {
+	keys, err := repo.ListVerifying(ctx)
+	if err != nil {
+		return err
+	}
+	canSign := 0
+	for _, k := range keys {
+		if k.RetiredAt == nil && k.ID != kid {
+			canSign++
+		}
+	}
+	if canSign == 0 {
+		return fmt.Errorf("%s is the only key that can sign; run `bff keys rotate` first", kid)
+	}
+
+	if err := repo.Retire(ctx, kid); errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no verifying key %s", kid)
+	} else if err != nil {
+		return err
+	}
+	fmt.Printf("Retired %s; tokens it signed are no longer accepted\n", kid)
+	return nil
+}
@@ -1,18 +1,28 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"strings"
+	"time"
 
+	"hr-platform/bff/internal/auth"
+	"hr-platform/bff/internal/cache"
 	"hr-platform/bff/internal/client"
 	"hr-platform/bff/internal/config"
 	"hr-platform/bff/internal/database"
+	"hr-platform/bff/internal/directory"
 	"hr-platform/bff/internal/handler"
+	"hr-platform/bff/internal/mail"
 	"hr-platform/bff/internal/middleware"
-	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/provisioning"
 	"hr-platform/bff/internal/repository"
+	"hr-platform/bff/internal/signing"
+	"hr-platform/bff/internal/sso"
+	"hr-platform/bff/internal/webhook"
 	"hr-platform/bff/migrations"
 
 	"github.com/labstack/echo/v4"
@@ -21,6 +31,9 @@ import (
 
 func main() {
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Refusing to start: %v", err)
+	}
 
 	// --- Database ---
 	db, err := database.Connect(cfg.DatabaseURL)
@@ -35,38 +48,111 @@ func main() {
 	}
 	log.Println("Database migrations complete.")
 
+	signingKeyRepo := repository.NewSigningKeyRepository(db)
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		if err := runKeys(context.Background(), cfg, signingKeyRepo, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// --- Token signing keys ---
+	keys, err := signing.Load(context.Background(), signingKeyRepo, cfg.JWTSecret)
+	if err != nil {
+		log.Fatalf("Failed to load signing keys: %v", err)
+	}
+	go keys.Watch(context.Background())
+
 	// --- Repositories ---
 	companyRepo := repository.NewCompanyRepository(db)
+	signupRepo := repository.NewSignupRepository(db)
+	provisioningRepo := repository.NewProvisioningRepository(db)
 	userRepo := repository.NewUserRepository(db)
 	inviteRepo := repository.NewInviteRepository(db)
 	auditRepo := repository.NewAuditRepository(db)
 	notifRepo := repository.NewNotificationRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	resetRepo := repository.NewPasswordResetRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	oidcStateRepo := repository.NewOIDCStateRepository(db)
+	throttleRepo := repository.NewLoginThrottleRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	deliveryRepo := repository.NewWebhookDeliveryRepository(db)
+	employeeCacheRepo := repository.NewEmployeeCacheRepository(db)
+
+	if err := sealTOTPSecrets(context.Background(), mfaRepo, cfg.JWTSecret); err != nil {
+		log.Fatalf("Failed to seal mfa secrets: %v", err)
+	}
 
 	// --- Clients ---
-	frappeClient := client.NewFrappeClient(cfg.FrappeURL, cfg.FrappeAPIKey, cfg.FrappeAPISecret)
+	frappeOpts := cfg.FrappeOptions()
+	frappeOpts.Cache, err = newCache(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up cache: %v", err)
+	}
+	frappeClient := client.NewFrappeClient(cfg.FrappeURL, cfg.FrappeAPIKey, cfg.FrappeAPISecret, frappeOpts)
+	mailer, err := newMailer(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up mail: %v", err)
+	}
+	ssoClient := sso.NewClient(nil)
+
+	// --- Background jobs ---
+	provisioner := provisioning.NewRunner(provisioningRepo, companyRepo, userRepo, frappeClient)
+	go provisioner.Watch(context.Background())
+	employees := directory.NewDirectory(employeeCacheRepo, companyRepo, frappeClient, cfg.EmployeeSyncInterval())
+	go employees.Watch(context.Background())
 
 	// --- Handlers ---
-	authHandler := handler.NewAuthHandler(userRepo, companyRepo, auditRepo, frappeClient, cfg)
-	inviteHandler := handler.NewInviteHandler(inviteRepo, userRepo, companyRepo, auditRepo, cfg)
-	userHandler := handler.NewUserHandler(userRepo, auditRepo)
-	employeeHandler := handler.NewEmployeeHandler(frappeClient, companyRepo)
-	leaveHandler := handler.NewLeaveHandler(frappeClient, notifRepo, userRepo)
-	attendanceHandler := handler.NewAttendanceHandler(frappeClient)
-	payrollHandler := handler.NewPayrollHandler(frappeClient)
-	shiftHandler := handler.NewShiftHandler(frappeClient, notifRepo, userRepo)
-	ssoHandler := handler.NewSocialSecurityHandler(frappeClient)
-	pvdHandler := handler.NewProvidentFundHandler(frappeClient)
-	overtimeHandler := handler.NewOvertimeHandler(frappeClient, notifRepo, userRepo)
-	taxHandler := handler.NewTaxHandler(frappeClient)
-	notifHandler := handler.NewNotificationHandler(notifRepo)
-	reportsHandler := handler.NewReportsHandler(frappeClient)
-	orgchartHandler := handler.NewOrgChartHandler(frappeClient)
-	chatHandler := handler.NewChatHandler(frappeClient, cfg)
-	departmentHandler := handler.NewDepartmentHandler(frappeClient, companyRepo)
+	h := &handlers{
+		auth:          handler.NewAuthHandler(userRepo, companyRepo, sessionRepo, mfaRepo, throttleRepo, auditRepo, signupRepo, provisioningRepo, provisioner, mailer, keys, cfg),
+		session:       handler.NewSessionHandler(sessionRepo, userRepo, companyRepo, auditRepo, keys, cfg),
+		mfa:           handler.NewMFAHandler(userRepo, companyRepo, roleRepo, mfaRepo, sessionRepo, auditRepo, keys, cfg),
+		oidc:          handler.NewOIDCHandler(userRepo, companyRepo, sessionRepo, mfaRepo, oidcStateRepo, auditRepo, ssoClient, keys, cfg),
+		password:      handler.NewPasswordHandler(userRepo, resetRepo, sessionRepo, auditRepo, mailer, cfg),
+		invite:        handler.NewInviteHandler(inviteRepo, userRepo, companyRepo, sessionRepo, auditRepo, frappeClient, mailer, keys, cfg),
+		apiKey:        handler.NewAPIKeyHandler(apiKeyRepo, auditRepo),
+		role:          handler.NewRoleHandler(roleRepo, auditRepo),
+		user:          handler.NewUserHandler(userRepo, sessionRepo, throttleRepo, roleRepo, auditRepo, frappeClient),
+		impersonation: handler.NewImpersonationHandler(userRepo, companyRepo, sessionRepo, roleRepo, auditRepo, keys, cfg),
+		jwks:          handler.NewJWKSHandler(keys),
+		company:       handler.NewCompanyHandler(companyRepo, provisioningRepo, auditRepo, provisioner),
+		scim:          handler.NewSCIMHandler(userRepo, roleRepo, companyRepo, sessionRepo, auditRepo, frappeClient, cfg),
+		employee:      handler.NewEmployeeHandler(frappeClient, employees),
+		leave:         handler.NewLeaveHandler(frappeClient, notifRepo, userRepo),
+		attendance:    handler.NewAttendanceHandler(frappeClient),
+		payroll:       handler.NewPayrollHandler(frappeClient),
+		shift:         handler.NewShiftHandler(frappeClient, notifRepo, userRepo),
+		sso:           handler.NewSocialSecurityHandler(frappeClient),
+		pvd:           handler.NewProvidentFundHandler(frappeClient),
+		overtime:      handler.NewOvertimeHandler(frappeClient, notifRepo, userRepo),
+		tax:           handler.NewTaxHandler(frappeClient),
+		notif:         handler.NewNotificationHandler(notifRepo),
+		reports:       handler.NewReportsHandler(frappeClient),
+		orgchart:      handler.NewOrgChartHandler(frappeClient),
+		chat:          handler.NewChatHandler(frappeClient, cfg),
+		department:    handler.NewDepartmentHandler(frappeClient),
+		webhook:       handler.NewWebhookHandler(cfg.FrappeWebhookKey, deliveryRepo, webhook.NewDispatcher(companyRepo, userRepo, notifRepo, auditRepo, employees)),
+	}
 
 	// --- Echo ---
 	e := echo.New()
 	e.HideBanner = true
+	// Resolve the client address as the right-most X-Forwarded-For hop that is not one of
+	// our proxies (private networks, plus TRUSTED_PROXY_RANGES). Echo's default takes the
+	// left-most entry, which the client controls and could use to dodge per-IP throttling.
+	trustOptions := []echo.TrustOption{}
+	if extra := os.Getenv("TRUSTED_PROXY_RANGES"); extra != "" {
+		for _, r := range strings.Split(extra, ",") {
+			_, ipNet, err := net.ParseCIDR(strings.TrimSpace(r))
+			if err != nil {
+				log.Fatalf("invalid TRUSTED_PROXY_RANGES entry %q: %v", r, err)
+			}
+			trustOptions = append(trustOptions, echo.TrustIPRange(ipNet))
+		}
+	}
+	e.IPExtractor = echo.ExtractIPFromXFFHeader(trustOptions...)
 
 	// Global middleware
 	e.Use(echomw.Logger())
@@ -84,163 +170,82 @@ func main() {
 		AllowCredentials: true,
 	}))
 
-	// Health check
-	e.GET("/api/health", func(c echo.Context) error {
-		return c.JSON(200, map[string]string{"status": "ok"})
-	})
-
-	// Public routes
-	e.POST("/api/auth/login", authHandler.Login)
-	e.POST("/api/auth/signup", authHandler.Signup)
-	e.POST("/api/invites/accept", inviteHandler.Accept)
-
-	// Protected routes (all roles)
-	api := e.Group("/api", middleware.JWTMiddleware(cfg.JWTSecret))
-	api.GET("/me", authHandler.Me)
-	api.POST("/auth/logout", authHandler.Logout)
-
-	// Employee routes (all roles, self-filtered in handlers)
-	api.GET("/employees", employeeHandler.List)
-	api.GET("/employees/:id", employeeHandler.Get)
-	api.GET("/employees/:id/full", employeeHandler.GetFull)
-	api.GET("/employees/:id/leave", employeeHandler.GetLeave)
-	api.GET("/employees/:id/attendance", employeeHandler.GetAttendance)
-	api.GET("/employees/:id/documents", employeeHandler.GetDocuments)
-	api.PUT("/employees/:id/contact", employeeHandler.UpdateContact)
-	api.GET("/employees/:id/timeline", employeeHandler.GetTimeline)
-
-	// Leave routes (all roles)
-	api.POST("/leaves", leaveHandler.Create)
-	api.GET("/leaves", leaveHandler.List)
-	api.GET("/leaves/balance", leaveHandler.Balance)
-	api.PUT("/leaves/:id", leaveHandler.Update)
-	api.DELETE("/leaves/:id", leaveHandler.Cancel)
-
-	// Check-in / Check-out routes (all roles)
-	api.POST("/checkin", attendanceHandler.Checkin)
-	api.POST("/checkout", attendanceHandler.Checkout)
-	api.GET("/checkin/today", attendanceHandler.TodayCheckin)
-	api.GET("/checkin/history", attendanceHandler.CheckinHistory)
-
-	// Attendance routes (all roles)
-	api.GET("/attendance/me", attendanceHandler.Me)
-	api.POST("/attendance/requests", attendanceHandler.CreateRequest)
-	api.GET("/attendance/requests", attendanceHandler.ListRequests)
-
-	// Payroll routes (all roles, self-filtered in handler)
-	api.GET("/payroll/slips", payrollHandler.ListSlips)
-	api.GET("/payroll/slips/detail", payrollHandler.GetSlip)
-
-	// Shift routes (all roles, self-filtered in handler)
-	api.GET("/shifts/types", shiftHandler.ListShiftTypes)
-	api.GET("/shifts/me", shiftHandler.GetMyShift)
-	api.GET("/shifts/assignments", shiftHandler.ListAssignments)
-	api.GET("/shifts/requests", shiftHandler.ListRequests)
-	api.POST("/shifts/requests", shiftHandler.CreateRequest)
-
-	// Overtime routes (all roles, self-filtered in handler)
-	api.POST("/overtime", overtimeHandler.Create)
-	api.GET("/overtime", overtimeHandler.List)
-	api.DELETE("/overtime/:id", overtimeHandler.Cancel)
-
-	// Tax routes (all roles, self-filtered in handler)
-	api.GET("/tax/slabs", taxHandler.GetSlabs)
-	api.GET("/tax/employees/:id/deductions", taxHandler.GetEmployeeDeductions)
-	api.PUT("/tax/employees/:id/deductions", taxHandler.UpdateEmployeeDeductions)
-	api.GET("/tax/employees/:id/summary", taxHandler.GetEmployeeSummary)
-
-	// SSO routes (all roles can view own)
-	api.GET("/sso/config", ssoHandler.GetConfig)
-	api.GET("/sso/employees/:id", ssoHandler.GetEmployeeSSO)
-
-	// PVD routes (all roles can view own)
-	api.GET("/pvd/config", pvdHandler.GetConfig)
-	api.GET("/pvd/employees/:id", pvdHandler.GetEmployee)
-
-	// Notification routes (all roles)
-	api.GET("/notifications", notifHandler.List)
-	api.GET("/notifications/count", notifHandler.Count)
-	api.PUT("/notifications/:id/read", notifHandler.MarkRead)
-	api.PUT("/notifications/read-all", notifHandler.MarkAllRead)
-
-	// Org chart routes (all roles)
-	api.GET("/orgchart/tree", orgchartHandler.GetTree)
-	api.GET("/orgchart/departments", orgchartHandler.GetDepartments)
-
-	// AI Chat route (all roles)
-	api.POST("/chat", chatHandler.Chat)
-
-	// Department routes (all roles can read)
-	api.GET("/departments", departmentHandler.List)
-	api.GET("/departments/:id", departmentHandler.Get)
-
-	// Admin/HR/Manager routes
-	api.PUT("/leaves/:id/approve", leaveHandler.Approve, middleware.RequireRole(model.RoleAdmin, model.RoleHR, model.RoleManager))
-	api.GET("/attendance", attendanceHandler.List, middleware.RequireRole(model.RoleAdmin, model.RoleHR, model.RoleManager))
-	api.PUT("/attendance/requests/:id/approve", attendanceHandler.ApproveRequest, middleware.RequireRole(model.RoleAdmin, model.RoleHR, model.RoleManager))
-	api.PUT("/shifts/requests/:id/approve", shiftHandler.ApproveRequest, middleware.RequireRole(model.RoleAdmin, model.RoleHR, model.RoleManager))
-	api.PUT("/overtime/:id/approve", overtimeHandler.Approve, middleware.RequireRole(model.RoleAdmin, model.RoleHR, model.RoleManager))
-
-	// Admin/HR only routes
-	admin := api.Group("", middleware.RequireAdminOrHR())
-	admin.POST("/employees", employeeHandler.Create)
-	admin.PUT("/employees/:id", employeeHandler.Update)
-	admin.GET("/employees/:id/compensation", employeeHandler.GetCompensation)
-	admin.GET("/employees/:id/promotions", employeeHandler.GetPromotions)
-	admin.POST("/employees/:id/documents", employeeHandler.UploadDocument)
-	admin.DELETE("/employees/:id/documents/:doc_id", employeeHandler.DeleteDocument)
-	admin.GET("/users", userHandler.List)
-	admin.GET("/users/:id", userHandler.Get)
-	admin.PUT("/users/:id/role", userHandler.ChangeRole)
-	admin.PUT("/users/:id/status", userHandler.ChangeStatus)
-	admin.PUT("/users/:id/employee", userHandler.LinkEmployee)
-	admin.POST("/invites", inviteHandler.Create)
-	admin.GET("/invites", inviteHandler.List)
-	admin.DELETE("/invites/:id", inviteHandler.Revoke)
-	admin.POST("/payroll/employees/:id/setup", payrollHandler.SetupEmployee)
-	admin.POST("/payroll/employees/:id/generate", payrollHandler.GenerateSlip)
-	admin.POST("/payroll/process", payrollHandler.Process)
-	admin.POST("/payroll/submit", payrollHandler.Submit)
-	admin.POST("/shifts/types", shiftHandler.CreateShiftType)
-	admin.PUT("/shifts/types/:name", shiftHandler.UpdateShiftType)
-	admin.POST("/shifts/assignments", shiftHandler.AssignShift)
-	admin.DELETE("/shifts/assignments/:id", shiftHandler.UnassignShift)
-	admin.POST("/shifts/auto-attendance", shiftHandler.ProcessAutoAttendance)
-
-	// SSO admin routes
-	admin.PUT("/sso/config", ssoHandler.UpdateConfig)
-	admin.PUT("/sso/employees/:id", ssoHandler.UpdateEmployeeSSO)
-	admin.GET("/sso/report", ssoHandler.GetReport)
-
-	// PVD admin routes
-	admin.PUT("/pvd/config", pvdHandler.UpdateConfig)
-	admin.POST("/pvd/employees/:id", pvdHandler.EnrollEmployee)
-	admin.PUT("/pvd/employees/:id", pvdHandler.UpdateEmployee)
-	admin.DELETE("/pvd/employees/:id", pvdHandler.UnenrollEmployee)
-	admin.GET("/pvd/report", pvdHandler.GetReport)
-
-	// OT admin routes
-	admin.GET("/overtime/config", overtimeHandler.GetConfig)
-	admin.PUT("/overtime/config", overtimeHandler.UpdateConfig)
-
-	// Tax admin routes
-	admin.GET("/tax/pnd1", taxHandler.GetPND1)
-	admin.GET("/tax/withholding-cert/:id", taxHandler.GetWithholdingCert)
-
-	// Department write routes (admin/HR only)
-	admin.POST("/departments", departmentHandler.Create)
-	admin.PUT("/departments/:id", departmentHandler.Update)
-	admin.DELETE("/departments/:id", departmentHandler.Delete)
-
-	// Reports routes (admin/HR only)
-	admin.GET("/reports/employees", reportsHandler.EmployeeSummary)
-	admin.GET("/reports/attendance", reportsHandler.AttendanceReport)
-	admin.GET("/reports/leave", reportsHandler.LeaveReport)
-	admin.GET("/reports/payroll", reportsHandler.PayrollReport)
-	admin.GET("/reports/tax", reportsHandler.TaxReport)
-	admin.GET("/reports/export", reportsHandler.ExportCSV)
+	registerRoutes(e, h, guards{
+		scim: middleware.SCIMAuth(companyRepo),
+		api: []echo.MiddlewareFunc{
+			middleware.JWTMiddleware(keys, sessionRepo, apiKeyRepo, userRepo, auditRepo),
+			middleware.TenantMiddleware(companyRepo),
+			middleware.PermissionMiddleware(roleRepo),
+			middleware.ImpersonationMiddleware(auditRepo),
+		},
+	}, frappeClient)
 
 	addr := fmt.Sprintf(":%s", cfg.Port)
 	log.Printf("BFF server starting on %s", addr)
 	log.Fatal(e.Start(addr))
 }
+
+// sealTOTPSecrets seals the TOTP secrets stored in plaintext before secrets were sealed.
+// Instances starting together may both seal a secret; only the first replacement is kept.
+func sealTOTPSecrets(ctx context.Context, repo *repository.MFARepository, key string) error {
+	secrets, err := repo.UnsealedSecrets(ctx)
+	if err != nil {
+		return err
+	}
+	for userID, secret := range secrets {
+		sealed, err := auth.SealTOTPSecret(secret, key)
+		if err != nil {
+			return err
+		}
+		if err := repo.ReplaceSecret(ctx, userID, secret, sealed); err != nil {
+			return err
+		}
+	}
+	if len(secrets) > 0 {
+		log.Printf("Sealed %d mfa secrets.", len(secrets))
+	}
+	return nil
+}
+
+// newMailer picks the mail transport from MAIL_TRANSPORT: "log" (the default, development
+// only; see Config.Validate) prints messages, "smtp" sends through SMTP_HOST and "file"
+// writes .eml files to MAIL_DIR.
+func newMailer(cfg *config.Config) (mail.Sender, error) {
+	switch cfg.MailTransport {
+	case "", "log":
+		return mail.NewLogSender(), nil
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("MAIL_TRANSPORT=smtp needs SMTP_HOST")
+		}
+		return mail.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	case "file":
+		return mail.NewFileSender(cfg.MailDir, cfg.MailFrom)
+	}
+	return nil, fmt.Errorf("unknown MAIL_TRANSPORT %q (use log, smtp or file)", cfg.MailTransport)
+}
+
+// newCache picks the cache for slow-changing Frappe reads from BFF_CACHE_BACKEND:
+// "memory" (the default) keeps it per process, "redis" shares it through BFF_REDIS_URL
+// and "none" turns caching off.
+func newCache(cfg *config.Config) (*cache.Cache, error) {
+	switch cfg.CacheBackend {
+	case "memory":
+		return cache.New(cache.NewMemory(), cfg.CacheTTLs()), nil
+	case "redis":
+		store, err := cache.NewRedis(cfg.RedisURL)
+		if err != nil {
+			return nil, err
+		}
+		// An unreachable server only makes every read a miss, so keep starting.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := store.Ping(ctx); err != nil {
+			log.Printf("cache: redis is unreachable, reads will go to Frappe: %v", err)
+		}
+		return cache.New(store, cfg.CacheTTLs()), nil
+	case "none":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown BFF_CACHE_BACKEND %q (use memory, redis or none)", cfg.CacheBackend)
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"hr-platform/bff/internal/client"
+	"hr-platform/bff/internal/handler"
+	"hr-platform/bff/internal/middleware"
+	"hr-platform/bff/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handlers are the route handlers the server mounts, built in main.
+type handlers struct {
+	auth          *handler.AuthHandler
+	session       *handler.SessionHandler
+	mfa           *handler.MFAHandler
+	oidc          *handler.OIDCHandler
+	password      *handler.PasswordHandler
+	invite        *handler.InviteHandler
+	apiKey        *handler.APIKeyHandler
+	role          *handler.RoleHandler
+	user          *handler.UserHandler
+	impersonation *handler.ImpersonationHandler
+	jwks          *handler.JWKSHandler
+	company       *handler.CompanyHandler
+	scim          *handler.SCIMHandler
+	employee      *handler.EmployeeHandler
+	leave         *handler.LeaveHandler
+	attendance    *handler.AttendanceHandler
+	payroll       *handler.PayrollHandler
+	shift         *handler.ShiftHandler
+	sso           *handler.SocialSecurityHandler
+	pvd           *handler.ProvidentFundHandler
+	overtime      *handler.OvertimeHandler
+	tax           *handler.TaxHandler
+	notif         *handler.NotificationHandler
+	reports       *handler.ReportsHandler
+	orgchart      *handler.OrgChartHandler
+	chat          *handler.ChatHandler
+	department    *handler.DepartmentHandler
+	webhook       *handler.WebhookHandler
+}
+
+// guards authenticate requests. scim checks a company's SCIM token; api runs in order on
+// every route under /api that is not public (JWT, tenant, permissions, impersonation).
+type guards struct {
+	scim echo.MiddlewareFunc
+	api  []echo.MiddlewareFunc
+}
+
+// registerRoutes mounts the route table on e. frappe backs the tenant guards on
+// :id employee and :doc_id document params.
+func registerRoutes(e *echo.Echo, h *handlers, g guards, frappe *client.FrappeClient) {
+	// Health check
+	e.GET("/api/health", func(c echo.Context) error {
+		return c.JSON(200, map[string]string{"status": "ok"})
+	})
+
+	// Public keys for verifying access tokens
+	e.GET("/.well-known/jwks.json", h.jwks.JWKS)
+
+	// Public routes
+	e.POST("/api/auth/login", h.auth.Login)
+	e.POST("/api/auth/signup", h.auth.Signup)
+	e.POST("/api/auth/verify-email", h.auth.VerifyEmail)
+	e.POST("/api/auth/refresh", h.session.Refresh)
+	e.POST("/api/auth/forgot-password", h.password.Forgot)
+	e.POST("/api/auth/reset-password", h.password.Reset)
+	e.POST("/api/auth/mfa/verify", h.mfa.Verify)
+	e.POST("/api/auth/mfa/enroll", h.mfa.Enroll)
+	e.GET("/api/auth/oidc/callback", h.oidc.Callback)
+	e.GET("/api/auth/oidc/:slug/login", h.oidc.Login)
+	e.POST("/api/invites/accept", h.invite.Accept)
+
+	// Document changes pushed by Frappe, authenticated by their signature
+	e.POST("/api/webhooks/frappe", h.webhook.Frappe)
+
+	// SCIM 2.0 provisioning, authenticated by the company's SCIM token instead of a user
+	scim := e.Group("/api/scim/v2", middleware.SCIMErrors, g.scim)
+	scim.GET("/ServiceProviderConfig", h.scim.ServiceProviderConfig)
+	scim.GET("/Users", h.scim.ListUsers)
+	scim.POST("/Users", h.scim.CreateUser)
+	scim.GET("/Users/:id", h.scim.GetUser)
+	scim.PUT("/Users/:id", h.scim.ReplaceUser)
+	scim.PATCH("/Users/:id", h.scim.PatchUser)
+	scim.DELETE("/Users/:id", h.scim.DeleteUser)
+	scim.GET("/Groups", h.scim.ListGroups)
+	scim.GET("/Groups/:id", h.scim.GetGroup)
+	scim.PUT("/Groups/:id", h.scim.ReplaceGroup)
+	scim.PATCH("/Groups/:id", h.scim.PatchGroup)
+
+	// Protected routes (all roles)
+	api := e.Group("/api", g.api...)
+
+	// Credential and sensitive personal-data changes are refused while impersonating
+	noImpersonation := middleware.BlockImpersonation
+
+	api.GET("/me", h.auth.Me)
+	api.POST("/auth/logout", h.auth.Logout)
+	api.PUT("/auth/password", h.password.Change, noImpersonation)
+	api.GET("/auth/mfa", h.mfa.Status)
+	api.POST("/auth/mfa/setup", h.mfa.Setup, noImpersonation)
+	api.POST("/auth/mfa/activate", h.mfa.Activate, noImpersonation)
+	api.POST("/auth/mfa/recovery-codes", h.mfa.RegenerateRecoveryCodes, noImpersonation)
+	api.DELETE("/auth/mfa", h.mfa.Disable, noImpersonation)
+	api.GET("/auth/sessions", h.session.List)
+	api.DELETE("/auth/sessions/:id", h.session.Revoke, noImpersonation)
+	api.POST("/auth/impersonation/stop", h.impersonation.Stop)
+	api.POST("/auth/switch-company", h.session.SwitchCompany, noImpersonation)
+	api.GET("/api-keys", h.apiKey.List)
+	api.POST("/api-keys", h.apiKey.Create, noImpersonation)
+	api.DELETE("/api-keys/:id", h.apiKey.Revoke, noImpersonation)
+
+	// Tenant guards for :id employee, department and shift assignment params and :doc_id
+	// document params
+	tenantEmployee := middleware.RequireTenantEmployee(frappe, "id")
+	tenantDocument := middleware.RequireTenantDocument(frappe, "id", "doc_id")
+	tenantDepartment := middleware.RequireTenantRecord(frappe, client.DoctypeDepartment, "id")
+	tenantAssignment := middleware.RequireTenantRecord(frappe, client.DoctypeShiftAssignment, "id")
+
+	// Employee routes (all roles, self-filtered in handlers)
+	api.GET("/employees", h.employee.List)
+	api.GET("/employees/search", h.employee.Search)
+	api.GET("/employees/:id", h.employee.Get, tenantEmployee)
+	api.GET("/employees/:id/full", h.employee.GetFull, tenantEmployee)
+	api.GET("/employees/:id/leave", h.employee.GetLeave, tenantEmployee)
+	api.GET("/employees/:id/attendance", h.employee.GetAttendance, tenantEmployee)
+	api.GET("/employees/:id/documents", h.employee.GetDocuments, tenantEmployee)
+	api.GET("/employees/:id/documents/:doc_id/download", h.employee.DownloadDocument, tenantDocument)
+	api.PUT("/employees/:id/contact", h.employee.UpdateContact, noImpersonation, tenantEmployee)
+	api.GET("/employees/:id/timeline", h.employee.GetTimeline, tenantEmployee)
+
+	// Leave routes (all roles)
+	api.POST("/leaves", h.leave.Create)
+	api.GET("/leaves", h.leave.List)
+	api.GET("/leaves/balance", h.leave.Balance)
+	api.PUT("/leaves/:id", h.leave.Update)
+	api.DELETE("/leaves/:id", h.leave.Cancel)
+
+	// Check-in / Check-out routes (all roles)
+	api.POST("/checkin", h.attendance.Checkin)
+	api.POST("/checkout", h.attendance.Checkout)
+	api.GET("/checkin/today", h.attendance.TodayCheckin)
+	api.GET("/checkin/history", h.attendance.CheckinHistory)
+
+	// Attendance routes (all roles)
+	api.GET("/attendance/me", h.attendance.Me)
+	api.POST("/attendance/requests", h.attendance.CreateRequest)
+	api.GET("/attendance/requests", h.attendance.ListRequests)
+
+	// Payroll routes (all roles, self-filtered in handler)
+	api.GET("/payroll/slips", h.payroll.ListSlips)
+	api.GET("/payroll/slips/detail", h.payroll.GetSlip)
+
+	// Shift routes (all roles, self-filtered in handler)
+	api.GET("/shifts/types", h.shift.ListShiftTypes)
+	api.GET("/shifts/me", h.shift.GetMyShift)
+	api.GET("/shifts/assignments", h.shift.ListAssignments)
+	api.GET("/shifts/requests", h.shift.ListRequests)
+	api.POST("/shifts/requests", h.shift.CreateRequest)
+
+	// Overtime routes (all roles, self-filtered in handler)
+	api.POST("/overtime", h.overtime.Create)
+	api.GET("/overtime", h.overtime.List)
+	api.DELETE("/overtime/:id", h.overtime.Cancel)
+
+	// Tax routes (all roles, self-filtered in handler)
+	api.GET("/tax/slabs", h.tax.GetSlabs)
+	api.GET("/tax/employees/:id/deductions", h.tax.GetEmployeeDeductions, tenantEmployee)
+	api.PUT("/tax/employees/:id/deductions", h.tax.UpdateEmployeeDeductions, noImpersonation, tenantEmployee)
+	api.GET("/tax/employees/:id/summary", h.tax.GetEmployeeSummary, tenantEmployee)
+
+	// SSO routes (all roles can view own)
+	api.GET("/sso/config", h.sso.GetConfig)
+	api.GET("/sso/employees/:id", h.sso.GetEmployeeSSO, tenantEmployee)
+
+	// PVD routes (all roles can view own)
+	api.GET("/pvd/config", h.pvd.GetConfig)
+	api.GET("/pvd/employees/:id", h.pvd.GetEmployee, tenantEmployee)
+
+	// Notification routes (all roles)
+	api.GET("/notifications", h.notif.List)
+	api.GET("/notifications/count", h.notif.Count)
+	api.PUT("/notifications/:id/read", h.notif.MarkRead)
+	api.PUT("/notifications/read-all", h.notif.MarkAllRead)
+
+	// Org chart routes (all roles)
+	api.GET("/orgchart/tree", h.orgchart.GetTree)
+	api.GET("/orgchart/departments", h.orgchart.GetDepartments)
+
+	// AI Chat route (all roles)
+	api.POST("/chat", h.chat.Chat)
+
+	// Department routes (all roles can read)
+	api.GET("/departments", h.department.List)
+	api.GET("/departments/:id", h.department.Get, tenantDepartment)
+
+	// Routes below require a named permission (see model.Permission and migration 013).
+	perm := middleware.RequirePermission
+
+	// Approvals
+	api.PUT("/leaves/:id/approve", h.leave.Approve, perm(model.PermLeaveApprove))
+	api.GET("/attendance", h.attendance.List, perm(model.PermAttendanceViewTeam))
+	api.PUT("/attendance/requests/:id/approve", h.attendance.ApproveRequest, perm(model.PermAttendanceApprove))
+	api.PUT("/shifts/requests/:id/approve", h.shift.ApproveRequest, perm(model.PermShiftApprove))
+	api.PUT("/overtime/:id/approve", h.overtime.Approve, perm(model.PermOvertimeApprove))
+
+	// Employee administration
+	api.POST("/employees", h.employee.Create, perm(model.PermEmployeeCreate))
+	api.PUT("/employees/:id", h.employee.Update, perm(model.PermEmployeeUpdateSensitive), tenantEmployee)
+	api.GET("/employees/:id/compensation", h.employee.GetCompensation, perm(model.PermEmployeeViewSensitive), tenantEmployee)
+	api.GET("/employees/:id/promotions", h.employee.GetPromotions, perm(model.PermEmployeeViewSensitive), tenantEmployee)
+	api.POST("/employees/:id/documents", h.employee.UploadDocument, perm(model.PermEmployeeUpdateSensitive), tenantEmployee)
+	api.DELETE("/employees/:id/documents/:doc_id", h.employee.DeleteDocument, perm(model.PermEmployeeUpdateSensitive), tenantDocument)
+
+	// Users, invites and company security
+	api.GET("/users", h.user.List, perm(model.PermUserView))
+	api.GET("/users/:id", h.user.Get, perm(model.PermUserView))
+	api.PUT("/users/:id/role", h.user.ChangeRole, perm(model.PermUserManage))
+	api.PUT("/users/:id/status", h.user.ChangeStatus, perm(model.PermUserManage))
+	api.PUT("/users/:id/employee", h.user.LinkEmployee, perm(model.PermUserManage))
+	api.POST("/users/:id/unlock", h.user.Unlock, perm(model.PermUserManage))
+	api.POST("/users/:id/impersonate", h.impersonation.Start, perm(model.PermUserImpersonate), noImpersonation)
+	api.DELETE("/users/:id/mfa", h.mfa.ResetUser, perm(model.PermUserResetMFA))
+	api.GET("/company/mfa-policy", h.mfa.GetPolicy, perm(model.PermUserManage))
+	api.PUT("/company/mfa-policy", h.mfa.UpdatePolicy, perm(model.PermCompanySecurity))
+	api.GET("/company/provisioning", h.company.GetProvisioning, perm(model.PermUserManage))
+	api.POST("/company/provisioning/retry", h.company.RetryProvisioning, perm(model.PermCompanySecurity))
+	api.GET("/company/email-domains", h.company.GetEmailDomains, perm(model.PermUserManage))
+	api.PUT("/company/email-domains", h.company.UpdateEmailDomains, perm(model.PermCompanySecurity))
+	api.GET("/company/oidc", h.oidc.GetConfig, perm(model.PermCompanySecurity))
+	api.PUT("/company/oidc", h.oidc.UpdateConfig, perm(model.PermCompanySecurity))
+	api.GET("/company/scim", h.scim.GetConfig, perm(model.PermCompanySecurity))
+	api.PUT("/company/scim", h.scim.UpdateConfig, perm(model.PermCompanySecurity))
+	api.POST("/company/scim/token", h.scim.RotateToken, perm(model.PermCompanySecurity), noImpersonation)
+	api.DELETE("/company/scim/token", h.scim.RevokeToken, perm(model.PermCompanySecurity), noImpersonation)
+	api.POST("/invites", h.invite.Create, perm(model.PermUserInvite))
+	api.GET("/invites", h.invite.List, perm(model.PermUserInvite))
+	api.POST("/invites/bulk", h.invite.Bulk, perm(model.PermUserInvite))
+	api.POST("/invites/:id/resend", h.invite.Resend, perm(model.PermUserInvite))
+	api.POST("/invites/:id/extend", h.invite.Extend, perm(model.PermUserInvite))
+	api.DELETE("/invites/:id", h.invite.Revoke, perm(model.PermUserInvite))
+
+	// Roles and permissions
+	api.GET("/permissions", h.role.ListPermissions, perm(model.PermRoleManage))
+	api.GET("/roles", h.role.List, perm(model.PermUserView))
+	api.POST("/roles", h.role.Create, perm(model.PermRoleManage))
+	api.PUT("/roles/:id", h.role.Update, perm(model.PermRoleManage))
+	api.DELETE("/roles/:id", h.role.Delete, perm(model.PermRoleManage))
+
+	// Payroll processing
+	api.POST("/payroll/employees/:id/setup", h.payroll.SetupEmployee, perm(model.PermPayrollProcess), tenantEmployee)
+	api.POST("/payroll/employees/:id/generate", h.payroll.GenerateSlip, perm(model.PermPayrollProcess), tenantEmployee)
+	api.POST("/payroll/process", h.payroll.Process, perm(model.PermPayrollProcess))
+	api.POST("/payroll/submit", h.payroll.Submit, perm(model.PermPayrollProcess))
+
+	// Shift administration
+	api.POST("/shifts/types", h.shift.CreateShiftType, perm(model.PermShiftManage))
+	api.PUT("/shifts/types/:name", h.shift.UpdateShiftType, perm(model.PermShiftManage))
+	api.POST("/shifts/assignments", h.shift.AssignShift, perm(model.PermShiftManage))
+	api.DELETE("/shifts/assignments/:id", h.shift.UnassignShift, perm(model.PermShiftManage), tenantAssignment)
+	api.POST("/shifts/auto-attendance", h.shift.ProcessAutoAttendance, perm(model.PermShiftManage))
+
+	// SSO admin routes
+	api.PUT("/sso/config", h.sso.UpdateConfig, perm(model.PermBenefitsManage))
+	api.PUT("/sso/employees/:id", h.sso.UpdateEmployeeSSO, perm(model.PermBenefitsManage), tenantEmployee)
+	api.GET("/sso/report", h.sso.GetReport, perm(model.PermBenefitsManage))
+
+	// PVD admin routes
+	api.PUT("/pvd/config", h.pvd.UpdateConfig, perm(model.PermBenefitsManage))
+	api.POST("/pvd/employees/:id", h.pvd.EnrollEmployee, perm(model.PermBenefitsManage), tenantEmployee)
+	api.PUT("/pvd/employees/:id", h.pvd.UpdateEmployee, perm(model.PermBenefitsManage), tenantEmployee)
+	api.DELETE("/pvd/employees/:id", h.pvd.UnenrollEmployee, perm(model.PermBenefitsManage), tenantEmployee)
+	api.GET("/pvd/report", h.pvd.GetReport, perm(model.PermBenefitsManage))
+
+	// OT admin routes
+	api.GET("/overtime/config", h.overtime.GetConfig, perm(model.PermOvertimeConfigure))
+	api.PUT("/overtime/config", h.overtime.UpdateConfig, perm(model.PermOvertimeConfigure))
+
+	// Tax admin routes
+	api.GET("/tax/pnd1", h.tax.GetPND1, perm(model.PermTaxReport))
+	api.GET("/tax/withholding-cert/:id", h.tax.GetWithholdingCert, perm(model.PermTaxReport), tenantEmployee)
+
+	// Department write routes
+	api.POST("/departments", h.department.Create, perm(model.PermDepartmentManage))
+	api.PUT("/departments/:id", h.department.Update, perm(model.PermDepartmentManage), tenantDepartment)
+	api.DELETE("/departments/:id", h.department.Delete, perm(model.PermDepartmentManage), tenantDepartment)
+
+	// Reports routes
+	api.GET("/reports/employees", h.reports.EmployeeSummary, perm(model.PermReportView))
+	api.GET("/reports/attendance", h.reports.AttendanceReport, perm(model.PermReportView))
+	api.GET("/reports/leave", h.reports.LeaveReport, perm(model.PermReportView))
+	api.GET("/reports/payroll", h.reports.PayrollReport, perm(model.PermReportView))
+	api.GET("/reports/tax", h.reports.TaxReport, perm(model.PermReportView))
+	api.GET("/reports/export", h.reports.ExportCSV, perm(model.PermReportView))
+}
@@ -0,0 +1,1240 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"hr-platform/bff/internal/cache"
+	"hr-platform/bff/internal/client"
+	"hr-platform/bff/internal/config"
+	"hr-platform/bff/internal/frappetest"
+	"hr-platform/bff/internal/handler"
+	"hr-platform/bff/internal/middleware"
+	"hr-platform/bff/internal/model"
+
+	"github.com/labstack/echo/v4"
+	echomw "github.com/labstack/echo/v4/middleware"
+)
+
+// These tests run the real route table and Frappe-backed handlers against the fake
+// Frappe in internal/frappetest. Postgres is replaced by the in-memory stores below,
+// so handlers that only talk to the database are left nil and their routes are only
+// checked for the permission they require.
+
+const (
+	acmeID   = "company-acme"
+	globexID = "company-globex"
+)
+
+// testUser is what a bearer token in these tests stands for.
+type testUser struct {
+	id, employee, companyID, impersonator string
+	role                                  model.UserRole
+	// custom is the permissions of a custom role built on role; nil for the built-in role.
+	custom model.PermissionSet
+}
+
+var testUsers = map[string]testUser{
+	"admin":    {id: "user-admin", employee: frappetest.EmpAdmin, companyID: acmeID, role: model.RoleAdmin},
+	"hr":       {id: "user-hr", employee: frappetest.EmpHR, companyID: acmeID, role: model.RoleHR},
+	"manager":  {id: "user-manager", employee: frappetest.EmpManager, companyID: acmeID, role: model.RoleManager},
+	"alice":    {id: "user-alice", employee: frappetest.EmpAlice, companyID: acmeID, role: model.RoleEmployee},
+	"carol":    {id: "user-carol", employee: frappetest.EmpCarol, companyID: acmeID, role: model.RoleEmployee},
+	"unlinked": {id: "user-unlinked", companyID: acmeID, role: model.RoleEmployee},
+	"gus":      {id: "user-gus", employee: frappetest.EmpGus, companyID: globexID, role: model.RoleAdmin},
+	// An admin signed in as Alice.
+	"as-alice": {id: "user-alice", employee: frappetest.EmpAlice, companyID: acmeID, role: model.RoleEmployee, impersonator: "user-admin"},
+	// A custom role on the employee base that was granted approvals before they were
+	// refused for it.
+	"clerk": {id: "user-clerk", employee: frappetest.EmpBob, companyID: acmeID, role: model.RoleEmployee,
+		custom: permissions(model.TeamPermissions...)},
+	// Holds a token for Acme but is not a member of it.
+	"outsider": {id: "user-outsider", companyID: acmeID, role: model.RoleEmployee},
+}
+
+// allPermissions and rolePermissions mirror the built-in roles seeded by migrations 013 and 014.
+var allPermissions = []model.Permission{
+	model.PermEmployeeCreate, model.PermEmployeeUpdateSensitive, model.PermEmployeeViewSensitive, model.PermEmployeeViewAll,
+	model.PermLeaveApprove, model.PermAttendanceViewTeam, model.PermAttendanceApprove, model.PermShiftApprove,
+	model.PermShiftManage, model.PermOvertimeApprove, model.PermOvertimeConfigure, model.PermPayrollViewAll,
+	model.PermPayrollProcess, model.PermTaxViewAll, model.PermTaxReport, model.PermBenefitsManage,
+	model.PermDepartmentManage, model.PermReportView, model.PermUserView, model.PermUserManage,
+	model.PermUserInvite, model.PermUserImpersonate, model.PermUserResetMFA, model.PermCompanySecurity,
+	model.PermRoleManage,
+}
+
+var rolePermissions = map[model.UserRole]model.PermissionSet{
+	model.RoleAdmin: permissions(allPermissions...),
+	model.RoleHR: func() model.PermissionSet {
+		s := permissions(allPermissions...)
+		delete(s, model.PermUserResetMFA)
+		delete(s, model.PermCompanySecurity)
+		delete(s, model.PermRoleManage)
+		return s
+	}(),
+	model.RoleManager: permissions(model.PermLeaveApprove, model.PermAttendanceViewTeam, model.PermAttendanceApprove,
+		model.PermShiftApprove, model.PermOvertimeApprove, model.PermTaxViewAll),
+	model.RoleEmployee: permissions(),
+}
+
+func permissions(ps ...model.Permission) model.PermissionSet {
+	s := model.PermissionSet{}
+	for _, p := range ps {
+		s[p] = true
+	}
+	return s
+}
+
+// stubAuth stands in for JWTMiddleware, setting the claims of the user the bearer token names.
+func stubAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		u, ok := testUsers[strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")]
+		if !ok {
+			return echo.NewHTTPError(http.StatusUnauthorized, "missing or invalid token")
+		}
+		c.Set("user_id", u.id)
+		c.Set("user_name", u.id)
+		c.Set("employee_id", u.employee)
+		c.Set("user_role", string(u.role))
+		c.Set("company_id", u.companyID)
+		c.Set("session_id", "session-"+u.id)
+		c.Set("impersonator_id", u.impersonator)
+		return next(c)
+	}
+}
+
+type companyStore map[string]*model.Company
+
+func (s companyStore) GetByID(_ context.Context, id string) (*model.Company, error) {
+	if co, ok := s[id]; ok {
+		return co, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// accessStore resolves a user's role from testUsers, refusing the outsider.
+type accessStore struct{}
+
+func (accessStore) AccessForUser(_ context.Context, userID, companyID string) (*model.Access, error) {
+	for _, u := range testUsers {
+		if u.id == userID && u.companyID == companyID && u.id != "user-outsider" {
+			if u.custom != nil {
+				return &model.Access{Role: u.role, RoleName: "clerk", Permissions: u.custom}, nil
+			}
+			return &model.Access{Role: u.role, RoleName: string(u.role), Permissions: rolePermissions[u.role]}, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+type auditRecorder struct {
+	mu      sync.Mutex
+	actions []string
+}
+
+func (a *auditRecorder) Log(_ context.Context, actorID, companyID, action, targetType, targetID string, details interface{}) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+// directoryStore stands in for the employee directory, which lives in Postgres. It
+// filters its rows by company, employee and department, and records each search.
+type directoryStore struct {
+	mu       sync.Mutex
+	rows     map[string][]model.DirectoryEmployee
+	searches []model.EmployeeSearch
+}
+
+func newDirectoryStore() *directoryStore {
+	return &directoryStore{rows: map[string][]model.DirectoryEmployee{
+		acmeID: {
+			{EmployeeID: frappetest.EmpAlice, EmployeeName: "Alice Arun", Department: "Engineering - AC", Status: "Active"},
+			{EmployeeID: frappetest.EmpBob, EmployeeName: "Bob Boonmee", Department: "Engineering - AC", Status: "Active"},
+			{EmployeeID: frappetest.EmpCarol, EmployeeName: "Carol Chai", Department: "Sales - AC", Status: "Active"},
+		},
+		globexID: {
+			{EmployeeID: frappetest.EmpGus, EmployeeName: "Gus Globex", Status: "Active"},
+		},
+	}}
+}
+
+func (d *directoryStore) Search(_ context.Context, companyID string, q model.EmployeeSearch, pg client.Page) ([]model.DirectoryEmployee, int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.searches = append(d.searches, q)
+
+	matches := []model.DirectoryEmployee{}
+	for _, e := range d.rows[companyID] {
+		if (q.EmployeeID == "" || e.EmployeeID == q.EmployeeID) && (q.Department == "" || e.Department == q.Department) {
+			matches = append(matches, e)
+		}
+	}
+	page := matches[min(pg.Offset, len(matches)):min(pg.Offset+pg.Limit, len(matches))]
+	return page, len(matches), nil
+}
+
+type testServer struct {
+	*testing.T
+	frappe    *frappetest.Server
+	echo      *echo.Echo
+	audit     *auditRecorder
+	directory *directoryStore
+}
+
+// newTestServer mounts the route table on a fresh fake Frappe. opts configures the
+// Frappe client; retries are off unless a test turns them on.
+func newTestServer(t *testing.T, opts client.Options) *testServer {
+	t.Helper()
+	fake := frappetest.New()
+	t.Cleanup(fake.Close)
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = -1
+	}
+	fc := fake.Client(opts)
+
+	directory := newDirectoryStore()
+	h := &handlers{
+		employee:   handler.NewEmployeeHandler(fc, directory),
+		leave:      handler.NewLeaveHandler(fc, nil, nil),
+		attendance: handler.NewAttendanceHandler(fc),
+		payroll:    handler.NewPayrollHandler(fc),
+		shift:      handler.NewShiftHandler(fc, nil, nil),
+		sso:        handler.NewSocialSecurityHandler(fc),
+		pvd:        handler.NewProvidentFundHandler(fc),
+		overtime:   handler.NewOvertimeHandler(fc, nil, nil),
+		tax:        handler.NewTaxHandler(fc),
+		reports:    handler.NewReportsHandler(fc),
+		orgchart:   handler.NewOrgChartHandler(fc),
+		chat:       handler.NewChatHandler(fc, &config.Config{}),
+		department: handler.NewDepartmentHandler(fc),
+	}
+	companies := companyStore{
+		acmeID:   {ID: acmeID, Name: "Acme", FrappeCompanyName: frappetest.CompanyAcme},
+		globexID: {ID: globexID, Name: "Globex", FrappeCompanyName: frappetest.CompanyGlobex},
+	}
+	audit := &auditRecorder{}
+
+	e := echo.New()
+	// Routes whose handler needs Postgres reach a nil handler; report that as a 500.
+	e.Use(echomw.Recover())
+	registerRoutes(e, h, guards{
+		scim: func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(echo.Context) error { return echo.NewHTTPError(http.StatusUnauthorized) }
+		},
+		api: []echo.MiddlewareFunc{
+			stubAuth,
+			middleware.TenantMiddleware(companies),
+			middleware.PermissionMiddleware(accessStore{}),
+			middleware.ImpersonationMiddleware(audit),
+		},
+	}, fc)
+
+	return &testServer{T: t, frappe: fake, echo: e, audit: audit, directory: directory}
+}
+
+type response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Data decodes the "data" field of the response into v.
+func (r response) Data(t *testing.T, v any) {
+	t.Helper()
+	var env struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(r.Body, &env); err != nil {
+		t.Fatalf("decoding %s: %v", r.Body, err)
+	}
+	if err := json.Unmarshal(env.Data, v); err != nil {
+		t.Fatalf("decoding data %s: %v", env.Data, err)
+	}
+}
+
+// pageInfo is the paging part of a list response.
+type pageInfo struct {
+	Meta struct {
+		Total      int     `json:"total"`
+		Limit      int     `json:"limit"`
+		Sort       string  `json:"sort"`
+		NextCursor *string `json:"next_cursor"`
+	} `json:"meta"`
+	Links struct {
+		Next *string `json:"next"`
+	} `json:"links"`
+}
+
+// Page decodes the meta and links of a list response.
+func (r response) Page(t *testing.T) pageInfo {
+	t.Helper()
+	var p pageInfo
+	if err := json.Unmarshal(r.Body, &p); err != nil {
+		t.Fatalf("decoding %s: %v", r.Body, err)
+	}
+	return p
+}
+
+// Message returns the "message" of an error response.
+func (r response) Message() string {
+	var body struct {
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(r.Body, &body)
+	return body.Message
+}
+
+func (ts *testServer) do(token, method, path, body string) response {
+	ts.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	switch {
+	case strings.HasPrefix(body, "--"+uploadBoundary):
+		req.Header.Set("Content-Type", "multipart/form-data; boundary="+uploadBoundary)
+	case body != "":
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	ts.echo.ServeHTTP(rec, req)
+	return response{Status: rec.Code, Header: rec.Header(), Body: rec.Body.Bytes()}
+}
+
+// uploadBoundary separates the parts of the bodies documentUpload builds. do sends a
+// body that starts with it as multipart/form-data.
+const uploadBoundary = "test-upload-boundary"
+
+// documentUpload is a multipart document upload of content as filename, with a
+// doc_type part first unless docType is empty.
+func documentUpload(docType, filename, content string) string {
+	var b strings.Builder
+	w := multipart.NewWriter(&b)
+	_ = w.SetBoundary(uploadBoundary)
+	if docType != "" {
+		_ = w.WriteField("doc_type", docType)
+	}
+	part, _ := w.CreateFormFile("file", filename)
+	_, _ = io.WriteString(part, content)
+	_ = w.Close()
+	return b.String()
+}
+
+// testPDF is enough of a PDF for content sniffing.
+const testPDF = "%PDF-1.7\n1 0 obj\n<<>>\nendobj\n"
+
+// expect calls the route and fails unless it answers want.
+func (ts *testServer) expect(token, method, path, body string, want int) response {
+	ts.Helper()
+	res := ts.do(token, method, path, body)
+	if res.Status != want {
+		ts.Fatalf("%s %s as %s: status %d, want %d: %s", method, path, token, res.Status, want, res.Body)
+	}
+	return res
+}
+
+// recordOf returns the name of the first fixture record of doctype raised by employee.
+func (ts *testServer) recordOf(doctype, employee string) string {
+	ts.Helper()
+	for _, r := range ts.frappe.Records(doctype) {
+		if r.Employee == employee {
+			return r.Name
+		}
+	}
+	ts.Fatalf("no %s fixture for %s", doctype, employee)
+	return ""
+}
+
+// expand replaces {leave}, {attendance_request}, {shift_request}, {ot}, {assignment} and
+// {slip} in path with Alice's fixture record of that kind.
+func (ts *testServer) expand(path string) string {
+	ts.Helper()
+	for key, doctype := range map[string]string{
+		"{leave}":              client.DoctypeLeaveApplication,
+		"{attendance_request}": client.DoctypeAttendanceRequest,
+		"{shift_request}":      client.DoctypeShiftRequest,
+		"{ot}":                 client.DoctypeOvertimeRequest,
+		"{assignment}":         frappetest.DoctypeShiftAssignment,
+		"{slip}":               frappetest.DoctypeSalarySlip,
+	} {
+		if strings.Contains(path, key) {
+			path = strings.ReplaceAll(path, key, url.PathEscape(ts.recordOf(doctype, frappetest.EmpAlice)))
+		}
+	}
+	return path
+}
+
+func TestPublicAndUnauthenticatedRoutes(t *testing.T) {
+	ts := newTestServer(t, client.Options{})
+
+	ts.expect("", http.MethodGet, "/api/health", "", http.StatusOK)
+	for _, path := range []string{"/api/me", "/api/employees", "/api/leaves", "/api/departments", "/api/payroll/slips"} {
+		ts.expect("", http.MethodGet, path, "", http.StatusUnauthorized)
+	}
+	ts.expect("", http.MethodGet, "/api/scim/v2/Users", "", http.StatusUnauthorized)
+	if calls := ts.frappe.Calls(""); len(calls) != 0 {
+		t.Errorf("unauthenticated requests reached Frappe: %+v", calls)
+	}
+}
+
+func TestNonMemberIsRefused(t *testing.T) {
+	ts := newTestServer(t, client.Options{})
+
+	res := ts.expect("outsider", http.MethodGet, "/api/employees", "", http.StatusForbidden)
+	if got := res.Message(); got != "not a member of this company" {
+		t.Errorf("message = %q", got)
+	}
+}
+
+// permissionRoutes lists every route guarded by RequirePermission. want is the status a
+// caller holding the permission gets; zero marks routes whose handler needs Postgres,
+// for which only the refusal is checked.
+var permissionRoutes = []struct {
+	method, path, body string
+	perm               model.Permission
+	want               int
+}{
+	{http.MethodPut, "/api/leaves/{leave}/approve", `{"status":"Approved"}`, model.PermLeaveApprove, http.StatusOK},
+	{http.MethodGet, "/api/attendance?employee_id=" + frappetest.EmpAlice, "", model.PermAttendanceViewTeam, http.StatusOK},
+	{http.MethodPut, "/api/attendance/requests/{attendance_request}/approve", `{"action":"approve"}`, model.PermAttendanceApprove, http.StatusOK},
+	{http.MethodPut, "/api/shifts/requests/{shift_request}/approve", `{"action":"approve"}`, model.PermShiftApprove, http.StatusOK},
+	{http.MethodPut, "/api/overtime/{ot}/approve", `{"action":"approve"}`, model.PermOvertimeApprove, http.StatusOK},
+
+	{http.MethodPost, "/api/employees", `{"employee_name":"Dana New"}`, model.PermEmployeeCreate, http.StatusCreated},
+	{http.MethodPut, "/api/employees/" + frappetest.EmpAlice, `{"designation":"Senior Engineer"}`, model.PermEmployeeUpdateSensitive, http.StatusOK},
+	{http.MethodGet, "/api/employees/" + frappetest.EmpAlice + "/compensation", "", model.PermEmployeeViewSensitive, http.StatusOK},
+	{http.MethodGet, "/api/employees/" + frappetest.EmpAlice + "/promotions", "", model.PermEmployeeViewSensitive, http.StatusOK},
+	{http.MethodPost, "/api/employees/" + frappetest.EmpAlice + "/documents", documentUpload("ID Card", "id.pdf", testPDF), model.PermEmployeeUpdateSensitive, http.StatusCreated},
+	{http.MethodDelete, "/api/employees/" + frappetest.EmpAlice + "/documents/file-alice-contract", "", model.PermEmployeeUpdateSensitive, http.StatusOK},
+
+	{http.MethodGet, "/api/users", "", model.PermUserView, 0},
+	{http.MethodGet, "/api/users/x", "", model.PermUserView, 0},
+	{http.MethodPut, "/api/users/x/role", `{}`, model.PermUserManage, 0},
+	{http.MethodPut, "/api/users/x/status", `{}`, model.PermUserManage, 0},
+	{http.MethodPut, "/api/users/x/employee", `{}`, model.PermUserManage, 0},
+	{http.MethodPost, "/api/users/x/unlock", "", model.PermUserManage, 0},
+	{http.MethodPost, "/api/users/x/impersonate", "", model.PermUserImpersonate, 0},
+	{http.MethodDelete, "/api/users/x/mfa", "", model.PermUserResetMFA, 0},
+	{http.MethodGet, "/api/company/mfa-policy", "", model.PermUserManage, 0},
+	{http.MethodPut, "/api/company/mfa-policy", `{}`, model.PermCompanySecurity, 0},
+	{http.MethodGet, "/api/company/provisioning", "", model.PermUserManage, 0},
+	{http.MethodPost, "/api/company/provisioning/retry", "", model.PermCompanySecurity, 0},
+	{http.MethodGet, "/api/company/email-domains", "", model.PermUserManage, 0},
+	{http.MethodPut, "/api/company/email-domains", `{}`, model.PermCompanySecurity, 0},
+	{http.MethodGet, "/api/company/oidc", "", model.PermCompanySecurity, 0},
+	{http.MethodPut, "/api/company/oidc", `{}`, model.PermCompanySecurity, 0},
+	{http.MethodGet, "/api/company/scim", "", model.PermCompanySecurity, 0},
+	{http.MethodPut, "/api/company/scim", `{}`, model.PermCompanySecurity, 0},
+	{http.MethodPost, "/api/company/scim/token", "", model.PermCompanySecurity, 0},
+	{http.MethodDelete, "/api/company/scim/token", "", model.PermCompanySecurity, 0},
+	{http.MethodPost, "/api/invites", `{}`, model.PermUserInvite, 0},
+	{http.MethodGet, "/api/invites", "", model.PermUserInvite, 0},
+	{http.MethodPost, "/api/invites/bulk", `{}`, model.PermUserInvite, 0},
+	{http.MethodPost, "/api/invites/x/resend", "", model.PermUserInvite, 0},
+	{http.MethodPost, "/api/invites/x/extend", "", model.PermUserInvite, 0},
+	{http.MethodDelete, "/api/invites/x", "", model.PermUserInvite, 0},
+
+	{http.MethodGet, "/api/permissions", "", model.PermRoleManage, 0},
+	{http.MethodGet, "/api/roles", "", model.PermUserView, 0},
+	{http.MethodPost, "/api/roles", `{}`, model.PermRoleManage, 0},
+	{http.MethodPut, "/api/roles/x", `{}`, model.PermRoleManage, 0},
+	{http.MethodDelete, "/api/roles/x", "", model.PermRoleManage, 0},
+
+	{http.MethodPost, "/api/payroll/employees/" + frappetest.EmpAlice + "/setup", `{"base_salary":32000}`, model.PermPayrollProcess, http.StatusCreated},
+	{http.MethodPost, "/api/payroll/employees/" + frappetest.EmpAlice + "/generate", `{"month":3,"year":2026}`, model.PermPayrollProcess, http.StatusCreated},
+	{http.MethodPost, "/api/payroll/process", `{"month":3,"year":2026}`, model.PermPayrollProcess, http.StatusOK},
+	{http.MethodPost, "/api/payroll/submit", `{"month":2,"year":2026}`, model.PermPayrollProcess, http.StatusOK},
+
+	{http.MethodPost, "/api/shifts/types", `{"name":"Evening Shift","start_time":"14:00","end_time":"22:00"}`, model.PermShiftManage, http.StatusCreated},
+	{http.MethodPut, "/api/shifts/types/Early%20Shift", `{"start_time":"06:30"}`, model.PermShiftManage, http.StatusOK},
+	{http.MethodPost, "/api/shifts/assignments", `{"employee_id":"` + frappetest.EmpBob + `","shift_type":"Night Shift","start_date":"2026-04-01"}`, model.PermShiftManage, http.StatusCreated},
+	{http.MethodDelete, "/api/shifts/assignments/{assignment}", "", model.PermShiftManage, http.StatusOK},
+	{http.MethodPost, "/api/shifts/auto-attendance", `{"date":"2026-03-02"}`, model.PermShiftManage, http.StatusOK},
+
+	{http.MethodPut, "/api/sso/config", `{"rate":4.5}`, model.PermBenefitsManage, http.StatusOK},
+	{http.MethodPut, "/api/sso/employees/" + frappetest.EmpAlice, `{"sso_number":"1234567890123"}`, model.PermBenefitsManage, http.StatusOK},
+	{http.MethodGet, "/api/sso/report?month=2&year=2026", "", model.PermBenefitsManage, http.StatusOK},
+	{http.MethodPut, "/api/pvd/config", `{"min_rate":2}`, model.PermBenefitsManage, http.StatusOK},
+	{http.MethodPost, "/api/pvd/employees/" + frappetest.EmpAlice, `{"employee_rate":5}`, model.PermBenefitsManage, http.StatusCreated},
+	{http.MethodPut, "/api/pvd/employees/" + frappetest.EmpAlice, `{"employee_rate":7}`, model.PermBenefitsManage, http.StatusOK},
+	{http.MethodDelete, "/api/pvd/employees/" + frappetest.EmpAlice, "", model.PermBenefitsManage, http.StatusOK},
+	{http.MethodGet, "/api/pvd/report?month=2&year=2026", "", model.PermBenefitsManage, http.StatusOK},
+
+	{http.MethodGet, "/api/overtime/config", "", model.PermOvertimeConfigure, http.StatusOK},
+	{http.MethodPut, "/api/overtime/config", `{"weekday_ot_rate":1.75}`, model.PermOvertimeConfigure, http.StatusOK},
+
+	{http.MethodGet, "/api/tax/pnd1?month=2&year=2026", "", model.PermTaxReport, http.StatusOK},
+	{http.MethodGet, "/api/tax/withholding-cert/" + frappetest.EmpAlice + "?year=2026", "", model.PermTaxReport, http.StatusOK},
+
+	{http.MethodPost, "/api/departments", `{"department_name":"Research"}`, model.PermDepartmentManage, http.StatusCreated},
+	{http.MethodPut, "/api/departments/Management%20-%20AC", `{"department_name":"Leadership"}`, model.PermDepartmentManage, http.StatusOK},
+	{http.MethodDelete, "/api/departments/Legal%20-%20AC", "", model.PermDepartmentManage, http.StatusOK},
+
+	{http.MethodGet, "/api/reports/employees", "", model.PermReportView, http.StatusOK},
+	{http.MethodGet, "/api/reports/attendance?month=2&year=2026", "", model.PermReportView, http.StatusOK},
+	{http.MethodGet, "/api/reports/leave?year=2026", "", model.PermReportView, http.StatusOK},
+	{http.MethodGet, "/api/reports/payroll?month=2&year=2026", "", model.PermReportView, http.StatusOK},
+	{http.MethodGet, "/api/reports/tax?year=2026", "", model.PermReportView, http.StatusOK},
+	{http.MethodGet, "/api/reports/export?type=employee", "", model.PermReportView, http.StatusOK},
+}
+
+func TestPermissionRoutes(t *testing.T) {
+	for _, rt := range permissionRoutes {
+		for _, token := range []string{"admin", "hr", "manager", "alice"} {
+			role := testUsers[token].role
+			held := rolePermissions[role].Has(rt.perm)
+			if held && rt.want == 0 {
+				continue
+			}
+			t.Run(fmt.Sprintf("%s %s as %s", rt.method, rt.path, token), func(t *testing.T) {
+				ts := newTestServer(t, client.Options{})
+				path := ts.expand(rt.path)
+				if !held {
+					res := ts.expect(token, rt.method, path, rt.body, http.StatusForbidden)
+					if got := res.Message(); got != "insufficient permissions" {
+						t.Errorf("message = %q", got)
+					}
+					return
+				}
+				ts.expect(token, rt.method, path, rt.body, rt.want)
+			})
+		}
+	}
+}
+
+// selfServiceRoutes are the routes open to every role, called as an employee acting on
+// their own data.
+var selfServiceRoutes = []struct {
+	method, path, body string
+	want               int
+}{
+	{http.MethodGet, "/api/employees", "", http.StatusOK},
+	{http.MethodGet, "/api/employees/search?q=alice", "", http.StatusOK},
+	{http.MethodGet, "/api/employees/" + frappetest.EmpAlice, "", http.StatusOK},
+	{http.MethodGet, "/api/employees/" + frappetest.EmpAlice + "/full", "", http.StatusOK},
+	{http.MethodGet, "/api/employees/" + frappetest.EmpAlice + "/leave", "", http.StatusOK},
+	{http.MethodGet, "/api/employees/" + frappetest.EmpAlice + "/attendance", "", http.StatusOK},
+	{http.MethodGet, "/api/employees/" + frappetest.EmpAlice + "/documents", "", http.StatusOK},
+	{http.MethodGet, "/api/employees/" + frappetest.EmpAlice + "/documents/file-alice-contract/download", "", http.StatusOK},
+	{http.MethodPut, "/api/employees/" + frappetest.EmpAlice + "/contact", `{"cell_phone":"0812345678"}`, http.StatusOK},
+	{http.MethodGet, "/api/employees/" + frappetest.EmpAlice + "/timeline", "", http.StatusOK},
+
+	{http.MethodPost, "/api/leaves", `{"leave_type":"Annual Leave","from_date":"2026-04-06","to_date":"2026-04-07","reason":"Trip"}`, http.StatusCreated},
+	{http.MethodGet, "/api/leaves", "", http.StatusOK},
+	{http.MethodGet, "/api/leaves/balance", "", http.StatusOK},
+	{http.MethodPut, "/api/leaves/{leave}", `{"reason":"Family trip"}`, http.StatusOK},
+	{http.MethodDelete, "/api/leaves/{leave}", "", http.StatusOK},
+
+	{http.MethodPost, "/api/checkin", "", http.StatusOK},
+	{http.MethodPost, "/api/checkout", "", http.StatusOK},
+	{http.MethodGet, "/api/checkin/today", "", http.StatusOK},
+	{http.MethodGet, "/api/checkin/history", "", http.StatusOK},
+
+	{http.MethodGet, "/api/attendance/me", "", http.StatusOK},
+	{http.MethodPost, "/api/attendance/requests", `{"attendance_date":"2026-03-03","reason":"Forgot to check in"}`, http.StatusCreated},
+	{http.MethodGet, "/api/attendance/requests", "", http.StatusOK},
+
+	{http.MethodGet, "/api/payroll/slips", "", http.StatusOK},
+	{http.MethodGet, "/api/payroll/slips/detail?id={slip}", "", http.StatusOK},
+
+	{http.MethodGet, "/api/shifts/types", "", http.StatusOK},
+	{http.MethodGet, "/api/shifts/me", "", http.StatusOK},
+	{http.MethodGet, "/api/shifts/assignments", "", http.StatusOK},
+	{http.MethodGet, "/api/shifts/requests", "", http.StatusOK},
+	{http.MethodPost, "/api/shifts/requests", `{"shift_type":"Night Shift","from_date":"2026-04-01","to_date":"2026-04-05"}`, http.StatusCreated},
+
+	{http.MethodPost, "/api/overtime", `{"ot_date":"2026-03-05","ot_type":"weekday_ot","hours":2,"reason":"Release"}`, http.StatusCreated},
+	{http.MethodGet, "/api/overtime", "", http.StatusOK},
+	{http.MethodDelete, "/api/overtime/{ot}", "", http.StatusOK},
+
+	{http.MethodGet, "/api/tax/slabs", "", http.StatusOK},
+	{http.MethodGet, "/api/tax/employees/" + frappetest.EmpAlice + "/deductions", "", http.StatusOK},
+	{http.MethodPut, "/api/tax/employees/" + frappetest.EmpAlice + "/deductions", `{"life_insurance_premium":10000}`, http.StatusOK},
+	{http.MethodGet, "/api/tax/employees/" + frappetest.EmpAlice + "/summary?year=2026", "", http.StatusOK},
+
+	{http.MethodGet, "/api/sso/config", "", http.StatusOK},
+	{http.MethodGet, "/api/sso/employees/" + frappetest.EmpAlice, "", http.StatusOK},
+	{http.MethodGet, "/api/pvd/config", "", http.StatusOK},
+	{http.MethodGet, "/api/pvd/employees/" + frappetest.EmpAlice, "", http.StatusOK},
+
+	{http.MethodGet, "/api/orgchart/tree", "", http.StatusOK},
+	{http.MethodGet, "/api/orgchart/departments", "", http.StatusOK},
+	{http.MethodGet, "/api/departments", "", http.StatusOK},
+	{http.MethodGet, "/api/departments/Engineering%20-%20AC", "", http.StatusOK},
+
+	// The chat route needs an LLM key, so only its input check runs here.
+	{http.MethodPost, "/api/chat", `{"messages":[]}`, http.StatusBadRequest},
+}
+
+func TestSelfServiceRoutes(t *testing.T) {
+	for _, rt := range selfServiceRoutes {
+		t.Run(rt.method+" "+rt.path, func(t *testing.T) {
+			ts := newTestServer(t, client.Options{})
+			ts.expect("alice", rt.method, ts.expand(rt.path), rt.body, rt.want)
+		})
+	}
+}
+
+func TestEmployeesSeeOnlyThemselves(t *testing.T) {
+	ts := newTestServer(t, client.Options{})
+
+	var employees []struct {
+		EmployeeID string `json:"employee_id"`
+	}
+	ts.expect("alice", http.MethodGet, "/api/employees", "", http.StatusOK).Data(t, &employees)
+	if len(employees) != 1 || employees[0].EmployeeID != frappetest.EmpAlice {
+		t.Errorf("alice sees %+v, want only herself", employees)
+	}
+
+	ts.expect("admin", http.MethodGet, "/api/employees", "", http.StatusOK).Data(t, &employees)
+	if len(employees) != 6 {
+		t.Errorf("admin sees %d employees, want the 6 at Acme", len(employees))
+	}
+
+	ts.expect("alice", http.MethodGet, "/api/employees/search", "", http.StatusOK).Data(t, &employees)
+	if len(employees) != 1 || employees[0].EmployeeID != frappetest.EmpAlice {
+		t.Errorf("alice finds %+v, want only herself", employees)
+	}
+	ts.expect("unlinked", http.MethodGet, "/api/employees/search", "", http.StatusOK).Data(t, &employees)
+	if len(employees) != 0 {
+		t.Errorf("an employee without a record finds %+v", employees)
+	}
+
+	bob := frappetest.EmpBob
+	for _, path := range []string{"/full", "/leave", "/attendance", "/documents", "/timeline"} {
+		ts.expect("alice", http.MethodGet, "/api/employees/"+bob+path, "", http.StatusForbidden)
+	}
+	ts.expect("alice", http.MethodPut, "/api/employees/"+bob+"/contact", `{"cell_phone":"1"}`, http.StatusForbidden)
+	ts.frappe.AddDocument(frappetest.Document{Name: "file-bob-id", Employee: bob, FileName: "id.pdf", Content: []byte(testPDF)})
+	ts.expect("alice", http.MethodGet, "/api/employees/"+bob+"/documents/file-bob-id/download", "", http.StatusForbidden)
+	ts.expect("alice", http.MethodGet, "/api/tax/employees/"+bob+"/deductions", "", http.StatusForbidden)
+	ts.expect("alice", http.MethodPut, "/api/tax/employees/"+bob+"/deductions", `{}`, http.StatusForbidden)
+	ts.expect("alice", http.MethodGet, "/api/tax/employees/"+bob+"/summary?year=2026", "", http.StatusForbidden)
+
+	ts.expect("unlinked", http.MethodGet, "/api/leaves/balance", "", http.StatusBadRequest)
+	ts.expect("unlinked", http.MethodGet, "/api/payroll/slips", "", http.StatusBadRequest)
+}
+
+func TestManagersAreLimitedToTheirTeam(t *testing.T) {
+	ts := newTestServer(t, client.Options{})
+	alice, carol := frappetest.EmpAlice, frappetest.EmpCarol
+
+	ts.expect("manager", http.MethodGet, "/api/employees/"+alice+"/leave", "", http.StatusOK)
+	ts.expect("manager", http.MethodGet, "/api/employees/"+carol+"/leave", "", http.StatusForbidden)
+	ts.expect("manager", http.MethodGet, "/api/employees/"+carol+"/attendance", "", http.StatusForbidden)
+	ts.expect("manager", http.MethodGet, "/api/attendance?employee_id="+carol, "", http.StatusForbidden)
+	ts.expect("manager", http.MethodGet, "/api/shifts/assignments?employee_id="+carol, "", http.StatusForbidden)
+
+	lists := map[string]string{
+		"/api/leaves":              "employee",
+		"/api/attendance/requests": "employee",
+		"/api/shifts/requests":     "employee",
+		"/api/shifts/assignments":  "employee",
+		"/api/overtime":            "employee",
+	}
+	for path := range lists {
+		var rows []struct {
+			Employee string `json:"employee"`
+		}
+		ts.expect("manager", http.MethodGet, path, "", http.StatusOK).Data(t, &rows)
+		if len(rows) == 0 {
+			t.Errorf("%s: manager sees nothing, want Alice's records", path)
+		}
+		for _, r := range rows {
+			if r.Employee != alice {
+				t.Errorf("%s: manager sees a record of %s", path, r.Employee)
+			}
+		}
+	}
+
+	approvals := []struct{ path, doctype, body string }{
+		{"/api/leaves/%s/approve", client.DoctypeLeaveApplication, `{"status":"Approved"}`},
+		{"/api/attendance/requests/%s/approve", client.DoctypeAttendanceRequest, `{"action":"approve"}`},
+		{"/api/shifts/requests/%s/approve", client.DoctypeShiftRequest, `{"action":"approve"}`},
+		{"/api/overtime/%s/approve", client.DoctypeOvertimeRequest, `{"action":"approve"}`},
+	}
+	for _, a := range approvals {
+		res := ts.expect("manager", http.MethodPut, fmt.Sprintf(a.path, url.PathEscape(ts.recordOf(a.doctype, carol))), a.body, http.StatusForbidden)
+		if got := res.Message(); got != "employee is outside your reporting line" {
+			t.Errorf("%s: message = %q", a.path, got)
+		}
+		ts.expect("manager", http.MethodPut, fmt.Sprintf(a.path, url.PathEscape(ts.recordOf(a.doctype, alice))), a.body, http.StatusOK)
+	}
+
+	// Carol's leave was not touched; Alice's was approved.
+	if r, _ := ts.frappe.Record(client.DoctypeLeaveApplication, ts.recordOf(client.DoctypeLeaveApplication, carol)); r.Status != "Open" {
+		t.Errorf("carol's leave is %s, want Open", r.Status)
+	}
+	if r, _ := ts.frappe.Record(client.DoctypeLeaveApplication, ts.recordOf(client.DoctypeLeaveApplication, alice)); r.Status != "Approved" {
+		t.Errorf("alice's leave is %s, want Approved", r.Status)
+	}
+}
+
+// A custom role's permissions do not widen the rows its base role reaches.
+func TestCustomRoleKeepsEmployeeScope(t *testing.T) {
+	ts := newTestServer(t, client.Options{})
+	alice := frappetest.EmpAlice
+
+	approvals := []struct{ path, doctype, body string }{
+		{"/api/leaves/%s/approve", client.DoctypeLeaveApplication, `{"status":"Approved"}`},
+		{"/api/attendance/requests/%s/approve", client.DoctypeAttendanceRequest, `{"action":"approve"}`},
+		{"/api/shifts/requests/%s/approve", client.DoctypeShiftRequest, `{"action":"approve"}`},
+		{"/api/overtime/%s/approve", client.DoctypeOvertimeRequest, `{"action":"approve"}`},
+	}
+	for _, a := range approvals {
+		res := ts.expect("clerk", http.MethodPut, fmt.Sprintf(a.path, url.PathEscape(ts.recordOf(a.doctype, alice))), a.body, http.StatusForbidden)
+		if got := res.Message(); got != "employee is outside your reporting line" {
+			t.Errorf("%s: message = %q", a.path, got)
+		}
+	}
+	if r, _ := ts.frappe.Record(client.DoctypeLeaveApplication, ts.recordOf(client.DoctypeLeaveApplication, alice)); r.Status != "Open" {
+		t.Errorf("alice's leave is %s, want Open", r.Status)
+	}
+
+	ts.expect("clerk", http.MethodGet, "/api/attendance?employee_id="+alice, "", http.StatusForbidden)
+	ts.expect("clerk", http.MethodGet, "/api/employees/"+alice+"/attendance", "", http.StatusForbidden)
+	for _, path := range []string{"/api/leaves", "/api/attendance/requests", "/api/shifts/requests", "/api/overtime"} {
+		var rows []struct {
+			Employee string `json:"employee"`
+		}
+		ts.expect("clerk", http.MethodGet, path, "", http.StatusOK).Data(t, &rows)
+		for _, r := range rows {
+			if r.Employee != frappetest.EmpBob {
+				t.Errorf("%s: clerk sees a record of %s", path, r.Employee)
+			}
+		}
+	}
+}
+
+func TestEmployeesCannotChangeOthersRequests(t *testing.T) {
+	ts := newTestServer(t, client.Options{})
+
+	ts.expect("carol", http.MethodPut, ts.expand("/api/leaves/{leave}"), `{"reason":"x"}`, http.StatusForbidden)
+	ts.expect("carol", http.MethodDelete, ts.expand("/api/leaves/{leave}"), "", http.StatusForbidden)
+	ts.expect("carol", http.MethodDelete, ts.expand("/api/overtime/{ot}"), "", http.StatusForbidden)
+	ts.expect("carol", http.MethodGet, ts.expand("/api/payroll/slips/detail?id={slip}"), "", http.StatusForbidden)
+	// Alice's manager may cancel on her behalf.
+	ts.expect("manager", http.MethodDelete, ts.expand("/api/leaves/{leave}"), "", http.StatusOK)
+
+	var slips []struct {
+		Employee string `json:"employee"`
+	}
+	ts.expect("carol", http.MethodGet, "/api/payroll/slips", "", http.StatusOK).Data(t, &slips)
+	if len(slips) != 1 || slips[0].Employee != frappetest.EmpCarol {
+		t.Errorf("carol sees slips %+v, want only her own", slips)
+	}
+}
+
+func TestOtherTenantsRecordsAreNotFound(t *testing.T) {
+	ts := newTestServer(t, client.Options{})
+	alice := frappetest.EmpAlice
+
+	for _, path := range []string{"", "/full", "/documents", "/timeline"} {
+		ts.expect("gus", http.MethodGet, "/api/employees/"+alice+path, "", http.StatusNotFound)
+	}
+	ts.expect("gus", http.MethodPut, "/api/employees/"+alice, `{"designation":"x"}`, http.StatusNotFound)
+	ts.expect("gus", http.MethodGet, "/api/tax/employees/"+alice+"/deductions", "", http.StatusNotFound)
+	ts.expect("gus", http.MethodPost, "/api/payroll/employees/"+alice+"/setup", `{"base_salary":1}`, http.StatusNotFound)
+	ts.expect("gus", http.MethodGet, "/api/attendance?employee_id="+alice, "", http.StatusNotFound)
+	ts.expect("gus", http.MethodGet, "/api/shifts/assignments?employee_id="+alice, "", http.StatusNotFound)
+	ts.expect("gus", http.MethodGet, ts.expand("/api/payroll/slips/detail?id={slip}"), "", http.StatusNotFound)
+	for _, path := range []string{"/api/leaves/{leave}/approve", "/api/overtime/{ot}/approve"} {
+		ts.expect("gus", http.MethodPut, ts.expand(path), `{"status":"Approved","action":"approve"}`, http.StatusNotFound)
+	}
+	ts.expect("gus", http.MethodDelete, "/api/employees/"+alice+"/documents/file-alice-contract", "", http.StatusNotFound)
+	ts.expect("gus", http.MethodDelete, ts.expand("/api/shifts/assignments/{assignment}"), "", http.StatusNotFound)
+	for _, r := range ts.frappe.Records(frappetest.DoctypeShiftAssignment) {
+		if r.Employee == alice && r.Status != "Active" {
+			t.Errorf("gus cancelled Alice's assignment %s", r.Name)
+		}
+	}
+	acmeDepartment := "/api/departments/Engineering%20-%20AC"
+	ts.expect("gus", http.MethodGet, acmeDepartment, "", http.StatusNotFound)
+	ts.expect("gus", http.MethodPut, acmeDepartment, `{"department_name":"Taken"}`, http.StatusNotFound)
+	ts.expect("gus", http.MethodDelete, "/api/departments/Legal%20-%20AC", "", http.StatusNotFound)
+	ts.expect("gus", http.MethodPost, "/api/departments", `{"department_name":"Branch","parent_department":"Engineering - AC"}`, http.StatusBadRequest)
+	if calls := ts.frappe.Calls("hr_core_ext.api.department.update_department"); len(calls) != 0 {
+		t.Errorf("another tenant's department update reached Frappe")
+	}
+	ts.expect("gus", http.MethodGet, "/api/employees/"+alice+"/documents/file-alice-contract/download", "", http.StatusNotFound)
+	// A document of another employee is not found under Alice either.
+	ts.expect("admin", http.MethodDelete, "/api/employees/"+alice+"/documents/file-gus-contract", "", http.StatusNotFound)
+	ts.expect("admin", http.MethodGet, "/api/employees/"+alice+"/documents/file-gus-contract/download", "", http.StatusNotFound)
+
+	var employees []struct {
+		EmployeeID string `json:"employee_id"`
+	}
+	ts.expect("gus", http.MethodGet, "/api/employees", "", http.StatusOK).Data(t, &employees)
+	if len(employees) != 1 || employees[0].EmployeeID != frappetest.EmpGus {
+		t.Errorf("gus sees %+v, want only Globex", employees)
+	}
+}
+
+func TestImpersonation(t *testing.T) {
+	ts := newTestServer(t, client.Options{})
+
+	ts.expect("as-alice", http.MethodGet, "/api/employees", "", http.StatusOK)
+	res := ts.expect("as-alice", http.MethodPut, "/api/employees/"+frappetest.EmpAlice+"/contact", `{"cell_phone":"1"}`, http.StatusForbidden)
+	if got := res.Message(); got != "not allowed while impersonating" {
+		t.Errorf("message = %q", got)
+	}
+	ts.expect("as-alice", http.MethodPut, "/api/tax/employees/"+frappetest.EmpAlice+"/deductions", `{}`, http.StatusForbidden)
+	if len(ts.audit.actions) != 3 {
+		t.Errorf("audited %v, want one impersonation.request per call", ts.audit.actions)
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	ts := newTestServer(t, client.Options{})
+
+	res := ts.expect("alice", http.MethodPost, "/api/leaves", `{"leave_type":"Annual Leave","from_date":"06/04/2026","to_date":"2026-04-07"}`, http.StatusBadRequest)
+	if !strings.Contains(res.Message(), "from_date") {
+		t.Errorf("message = %q", res.Message())
+	}
+	ts.expect("alice", http.MethodGet, "/api/payroll/slips?month=13&year=2026", "", http.StatusBadRequest)
+	ts.expect("admin", http.MethodPost, "/api/payroll/process", `{"month":0,"year":2026}`, http.StatusBadRequest)
+	ts.expect("admin", http.MethodPost, "/api/shifts/types", `{"name":"Late","start_time":"9am","end_time":"18:00"}`, http.StatusBadRequest)
+	if calls := ts.frappe.Calls("hr_core_ext.api.payroll.process_payroll"); len(calls) != 0 {
+		t.Errorf("invalid payroll run reached Frappe")
+	}
+
+	// Frappe's own validation errors come back as 502 with Frappe's message.
+	res = ts.expect("admin", http.MethodDelete, "/api/departments/Engineering%20-%20AC", "", http.StatusBadGateway)
+	if !strings.Contains(res.Message(), "employees are still assigned") {
+		t.Errorf("message = %q", res.Message())
+	}
+}
+
+func TestDocumentUploads(t *testing.T) {
+	documents := "/api/employees/" + frappetest.EmpAlice + "/documents"
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		stored string // file name Frappe was given
+	}{
+		{name: "pdf", body: documentUpload("Contract", " contract  2026.pdf", testPDF), status: http.StatusCreated, stored: "contract 2026.pdf"},
+		{name: "without a doc type", body: documentUpload("", "photo.PNG", png), status: http.StatusCreated, stored: "photo.PNG"},
+		{name: "path and reserved characters are dropped", body: documentUpload("", "..\\..\\c:\\id|\u202ecard.pdf", testPDF), status: http.StatusCreated, stored: "idcard.pdf"},
+		{name: "extension outside the allow-list", body: documentUpload("", "setup.exe", "MZ\x90\x00"), status: http.StatusUnsupportedMediaType},
+		{name: "content that does not match the extension", body: documentUpload("", "scan.pdf", png), status: http.StatusUnsupportedMediaType},
+		{name: "larger than the doc type allows", body: documentUpload("ID Card", "id.pdf", testPDF+strings.Repeat("x", 5<<20)), status: http.StatusRequestEntityTooLarge},
+		{name: "unknown doc type", body: documentUpload("Passport Scan", "id.pdf", testPDF), status: http.StatusBadRequest},
+		{name: "empty file", body: documentUpload("", "id.pdf", ""), status: http.StatusBadRequest},
+		{name: "base64 JSON", body: `{"filename":"id.pdf","content":"aGk="}`, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A threshold of one shows that refused uploads do not count against Frappe
+			ts := newTestServer(t, client.Options{BreakerThreshold: 1, BreakerCooldown: time.Minute})
+			res := ts.expect("hr", http.MethodPost, documents, tt.body, tt.status)
+
+			calls := ts.frappe.Calls("upload_file")
+			if tt.stored == "" {
+				var docs []map[string]any
+				ts.expect("hr", http.MethodGet, documents, "", http.StatusOK).Data(t, &docs)
+				if len(docs) != 1 {
+					t.Errorf("refused upload was stored: %v", docs)
+				}
+				return
+			}
+			if len(calls) != 1 {
+				t.Fatalf("made %d upload_file calls, want 1", len(calls))
+			}
+			p := calls[0].Params
+			if p.Get("file_name") != tt.stored || p.Get("is_private") != "1" || p.Get("doctype") != "Employee" || p.Get("docname") != frappetest.EmpAlice {
+				t.Errorf("upload_file params = %v", p)
+			}
+			var doc struct {
+				Name     string `json:"name"`
+				FileName string `json:"file_name"`
+			}
+			res.Data(t, &doc)
+			if doc.FileName != tt.stored {
+				t.Errorf("uploaded %+v, want file name %q", doc, tt.stored)
+			}
+			ts.expect("alice", http.MethodGet, documents+"/"+doc.Name+"/download", "", http.StatusOK)
+		})
+	}
+
+	t.Run("download", func(t *testing.T) {
+		ts := newTestServer(t, client.Options{})
+		ts.frappe.AddDocument(frappetest.Document{Name: "file-alice-id", Employee: frappetest.EmpAlice, FileName: "บัตร ประชาชน.pdf", Content: []byte(testPDF)})
+
+		res := ts.expect("alice", http.MethodGet, documents+"/file-alice-id/download", "", http.StatusOK)
+		if string(res.Body) != testPDF {
+			t.Errorf("body = %q", res.Body)
+		}
+		for key, want := range map[string]string{
+			"Content-Type":           "application/pdf",
+			"Content-Disposition":    "attachment; filename*=utf-8''%E0%B8%9A%E0%B8%B1%E0%B8%95%E0%B8%A3%20%E0%B8%9B%E0%B8%A3%E0%B8%B0%E0%B8%8A%E0%B8%B2%E0%B8%8A%E0%B8%99.pdf",
+			"X-Content-Type-Options": "nosniff",
+		} {
+			if got := res.Header.Get(key); got != want {
+				t.Errorf("%s = %q, want %q", key, got, want)
+			}
+		}
+
+		ts.frappe.Inject("download_file", frappetest.Fault{Status: http.StatusInternalServerError})
+		ts.expect("alice", http.MethodGet, documents+"/file-alice-id/download", "", http.StatusBadGateway)
+	})
+}
+
+func TestListPaging(t *testing.T) {
+	ts := newTestServer(t, client.Options{})
+
+	t.Run("next links walk every page in order", func(t *testing.T) {
+		var names []string
+		path := "/api/employees?limit=2&sort=-employee_name"
+		for pages := 0; path != ""; pages++ {
+			if pages > 3 {
+				t.Fatalf("more than 3 pages of 2 for 6 employees")
+			}
+			res := ts.expect("admin", http.MethodGet, path, "", http.StatusOK)
+			var rows []struct {
+				Name string `json:"employee_name"`
+			}
+			res.Data(t, &rows)
+			for _, r := range rows {
+				names = append(names, r.Name)
+			}
+			p := res.Page(t)
+			if p.Meta.Total != 6 || p.Meta.Limit != 2 || p.Meta.Sort != "-employee_name" {
+				t.Errorf("meta = %+v", p.Meta)
+			}
+			if (p.Links.Next == nil) != (p.Meta.NextCursor == nil) {
+				t.Errorf("links.next = %v but next_cursor = %v", p.Links.Next, p.Meta.NextCursor)
+			}
+			path = ""
+			if p.Links.Next != nil {
+				path = *p.Links.Next
+			}
+		}
+		want := []string{"Somchai Admin", "Manop Manager", "Hathai HR", "Carol Chai", "Bob Boonmee", "Alice Arun"}
+		if !slices.Equal(names, want) {
+			t.Errorf("employees = %v, want %v", names, want)
+		}
+	})
+
+	t.Run("search pages through the caller's company directory", func(t *testing.T) {
+		path := "/api/employees/search?q=" + url.QueryEscape("ช") + "&department=" + url.QueryEscape("Engineering - AC") + "&branch=Bangkok&limit=1"
+		res := ts.expect("admin", http.MethodGet, path, "", http.StatusOK)
+		p := res.Page(t)
+		if p.Meta.Total != 2 || p.Links.Next == nil {
+			t.Fatalf("meta = %+v, links = %+v", p.Meta, p.Links)
+		}
+		ts.expect("admin", http.MethodGet, *p.Links.Next, "", http.StatusOK)
+
+		got := ts.directory.searches[len(ts.directory.searches)-1]
+		want := model.EmployeeSearch{Query: "ช", Department: "Engineering - AC", Branch: "Bangkok"}
+		if got != want {
+			t.Errorf("search = %+v, want %+v", got, want)
+		}
+
+		var employees []struct {
+			EmployeeID string `json:"employee_id"`
+		}
+		ts.expect("gus", http.MethodGet, "/api/employees/search", "", http.StatusOK).Data(t, &employees)
+		if len(employees) != 1 || employees[0].EmployeeID != frappetest.EmpGus {
+			t.Errorf("gus finds %+v, want only Globex", employees)
+		}
+		ts.expect("admin", http.MethodGet, "/api/employees/search?q="+strings.Repeat("a", 101), "", http.StatusBadRequest)
+	})
+
+	t.Run("filters narrow the list and the total", func(t *testing.T) {
+		res := ts.expect("admin", http.MethodGet, "/api/employees?department="+url.QueryEscape("Engineering - AC"), "", http.StatusOK)
+		if p := res.Page(t); p.Meta.Total != 3 || p.Meta.Limit != 50 || p.Links.Next != nil {
+			t.Errorf("meta = %+v, links = %+v", p.Meta, p.Links)
+		}
+	})
+
+	t.Run("managers page through their team only", func(t *testing.T) {
+		ts.frappe.ResetCalls()
+		res := ts.expect("manager", http.MethodGet, "/api/leaves?limit=1", "", http.StatusOK)
+		if p := res.Page(t); p.Meta.Total != 1 || p.Links.Next != nil {
+			t.Errorf("meta = %+v, want only Alice's leave", p.Meta)
+		}
+		calls := ts.frappe.Calls("hr_core_ext.api.leave.get_leave_applications")
+		if len(calls) != 1 {
+			t.Fatalf("%d list calls, want 1", len(calls))
+		}
+		var ids []string
+		_ = json.Unmarshal([]byte(calls[0].Params.Get("employee_ids")), &ids)
+		want := []string{frappetest.EmpManager, frappetest.EmpAlice, frappetest.EmpBob}
+		slices.Sort(want)
+		if !slices.Equal(ids, want) {
+			t.Errorf("employee_ids = %v, want %v", ids, want)
+		}
+	})
+
+	t.Run("bad paging arguments are rejected before Frappe", func(t *testing.T) {
+		res := ts.expect("admin", http.MethodGet, "/api/employees?limit=2", "", http.StatusOK)
+		cursor := *res.Page(t).Meta.NextCursor
+
+		ts.frappe.ResetCalls()
+		for _, path := range []string{
+			"/api/employees?limit=0",
+			"/api/employees?limit=201",
+			"/api/employees?cursor=not-a-cursor",
+			"/api/employees?sort=salary",
+			"/api/employees?sort=employee_name&cursor=" + cursor,
+			"/api/leaves?cursor=" + cursor,
+		} {
+			ts.expect("admin", http.MethodGet, path, "", http.StatusBadRequest)
+		}
+		if calls := ts.frappe.Calls("hr_core_ext.api.employee.get_employees"); len(calls) != 0 {
+			t.Errorf("%d invalid list requests reached Frappe", len(calls))
+		}
+	})
+
+	lists := []string{
+		"/api/leaves",
+		"/api/attendance/requests",
+		"/api/shifts/requests",
+		"/api/shifts/assignments",
+		"/api/overtime",
+		"/api/payroll/slips",
+	}
+	for _, path := range lists {
+		res := ts.expect("admin", http.MethodGet, path+"?limit=1", "", http.StatusOK)
+		var rows []json.RawMessage
+		res.Data(t, &rows)
+		if p := res.Page(t); len(rows) != 1 || p.Meta.Total != 2 || p.Links.Next == nil {
+			t.Errorf("%s: %d rows, meta = %+v, want 1 of Alice's and Carol's 2", path, len(rows), p.Meta)
+		}
+	}
+}
+
+func TestFrappeFailures(t *testing.T) {
+	t.Run("error is a 502 with Frappe's message", func(t *testing.T) {
+		ts := newTestServer(t, client.Options{})
+		ts.frappe.Inject("hr_core_ext.api.employee.get_employees", frappetest.Fault{Status: http.StatusInternalServerError, Message: "database is locked"})
+		res := ts.expect("admin", http.MethodGet, "/api/employees", "", http.StatusBadGateway)
+		if got := res.Message(); got != "database is locked" {
+			t.Errorf("message = %q", got)
+		}
+	})
+
+	t.Run("timeout is a 504", func(t *testing.T) {
+		ts := newTestServer(t, client.Options{Timeout: 50 * time.Millisecond})
+		ts.frappe.Inject(client.MethodTaxSlabs, frappetest.Fault{Delay: time.Second})
+		ts.expect("alice", http.MethodGet, "/api/tax/slabs", "", http.StatusGatewayTimeout)
+	})
+
+	t.Run("open breaker is a 503", func(t *testing.T) {
+		ts := newTestServer(t, client.Options{BreakerThreshold: 2, BreakerCooldown: time.Minute})
+		ts.frappe.Inject("*", frappetest.Fault{Status: http.StatusServiceUnavailable})
+		ts.expect("alice", http.MethodGet, "/api/leaves/balance", "", http.StatusBadGateway)
+		ts.expect("alice", http.MethodGet, "/api/leaves/balance", "", http.StatusBadGateway)
+		ts.frappe.ResetCalls()
+		ts.expect("alice", http.MethodGet, "/api/leaves/balance", "", http.StatusServiceUnavailable)
+		if calls := ts.frappe.Calls(""); len(calls) != 0 {
+			t.Errorf("open breaker let %d calls through", len(calls))
+		}
+	})
+
+	t.Run("GETs are retried", func(t *testing.T) {
+		ts := newTestServer(t, client.Options{MaxRetries: 2, RetryDelay: time.Millisecond})
+		ts.frappe.Inject(client.MethodShiftTypes, frappetest.Fault{Status: http.StatusBadGateway, Times: 2})
+		ts.expect("alice", http.MethodGet, "/api/shifts/types", "", http.StatusOK)
+		if n := len(ts.frappe.Calls(client.MethodShiftTypes)); n != 3 {
+			t.Errorf("made %d calls, want 3", n)
+		}
+	})
+
+	t.Run("writes are not retried", func(t *testing.T) {
+		ts := newTestServer(t, client.Options{MaxRetries: 2, RetryDelay: time.Millisecond})
+		ts.frappe.Inject("hr_core_ext.api.attendance.checkin", frappetest.Fault{Status: http.StatusBadGateway, Times: 1})
+		ts.expect("alice", http.MethodPost, "/api/checkin", "", http.StatusBadGateway)
+		if n := len(ts.frappe.Calls("hr_core_ext.api.attendance.checkin")); n != 1 {
+			t.Errorf("made %d calls, want 1", n)
+		}
+	})
+}
+
+func TestCachedReadsAreInvalidatedByWrites(t *testing.T) {
+	ts := newTestServer(t, client.Options{Cache: cache.New(cache.NewMemory(), client.DefaultCacheTTLs)})
+
+	count := func(method string) int { return len(ts.frappe.Calls(method)) }
+
+	for range 2 {
+		ts.expect("alice", http.MethodGet, "/api/departments", "", http.StatusOK)
+		ts.expect("alice", http.MethodGet, "/api/orgchart/departments", "", http.StatusOK)
+	}
+	if n := count(client.MethodDepartments); n != 1 {
+		t.Fatalf("departments read %d times from Frappe, want 1", n)
+	}
+	if n := count(client.MethodDepartmentTree); n != 1 {
+		t.Fatalf("department tree read %d times from Frappe, want 1", n)
+	}
+
+	ts.expect("admin", http.MethodPost, "/api/departments", `{"department_name":"Research"}`, http.StatusCreated)
+	var list struct {
+		Departments []struct {
+			Name string `json:"name"`
+		} `json:"departments"`
+	}
+	ts.expect("alice", http.MethodGet, "/api/departments", "", http.StatusOK).Data(t, &list)
+	if n := count(client.MethodDepartments); n != 2 {
+		t.Errorf("departments read %d times after a write, want 2", n)
+	}
+	ts.expect("alice", http.MethodGet, "/api/orgchart/departments", "", http.StatusOK)
+	if n := count(client.MethodDepartmentTree); n != 2 {
+		t.Errorf("department tree read %d times after a write, want 2", n)
+	}
+	found := false
+	for _, d := range list.Departments {
+		found = found || d.Name == "Research - AC"
+	}
+	if !found {
+		t.Errorf("new department missing from %+v", list.Departments)
+	}
+
+	// Each tenant has its own entry.
+	ts.expect("gus", http.MethodGet, "/api/departments", "", http.StatusOK)
+	if n := count(client.MethodDepartments); n != 3 {
+		t.Errorf("departments read %d times, want a miss for Globex", n)
+	}
+
+	ts.expect("alice", http.MethodGet, "/api/overtime/config", "", http.StatusForbidden)
+	ts.expect("admin", http.MethodGet, "/api/overtime/config", "", http.StatusOK)
+	ts.expect("admin", http.MethodPut, "/api/overtime/config", `{"weekday_ot_rate":2}`, http.StatusOK)
+	var cfg map[string]any
+	ts.expect("admin", http.MethodGet, "/api/overtime/config", "", http.StatusOK).Data(t, &cfg)
+	if cfg["weekday_ot_rate"] != 2.0 {
+		t.Errorf("weekday_ot_rate = %v after update, want 2", cfg["weekday_ot_rate"])
+	}
+}
+
+func TestSettingsArePerCompany(t *testing.T) {
+	ts := newTestServer(t, client.Options{Cache: cache.New(cache.NewMemory(), client.DefaultCacheTTLs)})
+
+	configs := []struct{ path, body, key string }{
+		{"/api/overtime/config", `{"weekday_ot_rate":2}`, "weekday_ot_rate"},
+		{"/api/sso/config", `{"rate":4}`, "rate"},
+		{"/api/pvd/config", `{"min_rate":1}`, "min_rate"},
+	}
+	for _, c := range configs {
+		var before, acme, globex map[string]any
+		ts.expect("gus", http.MethodGet, c.path, "", http.StatusOK).Data(t, &before)
+		ts.expect("admin", http.MethodPut, c.path, c.body, http.StatusOK)
+		ts.expect("admin", http.MethodGet, c.path, "", http.StatusOK).Data(t, &acme)
+		ts.expect("gus", http.MethodGet, c.path, "", http.StatusOK).Data(t, &globex)
+		if acme[c.key] == before[c.key] {
+			t.Errorf("%s: Acme's %s is still %v after its update", c.path, c.key, acme[c.key])
+		}
+		if globex[c.key] != before[c.key] {
+			t.Errorf("%s: Globex's %s changed to %v by Acme's update", c.path, c.key, globex[c.key])
+		}
+	}
+
+	ts.expect("admin", http.MethodPost, "/api/shifts/types", `{"name":"Late Shift","start_time":"12:00","end_time":"21:00"}`, http.StatusCreated)
+	var types []struct {
+		Name string `json:"name"`
+	}
+	ts.expect("gus", http.MethodGet, "/api/shifts/types", "", http.StatusOK).Data(t, &types)
+	for _, st := range types {
+		if st.Name == "Early Shift" || st.Name == "Late Shift" {
+			t.Errorf("Globex sees Acme's shift type %s", st.Name)
+		}
+	}
+	ts.expect("gus", http.MethodPut, "/api/shifts/types/Late%20Shift", `{"start_time":"13:00"}`, http.StatusNotFound)
+	for _, token := range []string{"admin", "gus"} {
+		ts.expect(token, http.MethodPut, "/api/shifts/types/Day%20Shift", `{"start_time":"07:00"}`, http.StatusForbidden)
+	}
+	ts.expect("gus", http.MethodPost, "/api/shifts/assignments", `{"employee_id":"`+frappetest.EmpGus+`","shift_type":"Early Shift","start_date":"2026-04-01"}`, http.StatusBadRequest)
+}
+
+// accountRoutes are open to every signed-in user and served from Postgres alone.
+// blocked marks those refused while impersonating.
+var accountRoutes = []struct {
+	method, path string
+	blocked      bool
+}{
+	{http.MethodGet, "/api/me", false},
+	{http.MethodPost, "/api/auth/logout", false},
+	{http.MethodPut, "/api/auth/password", true},
+	{http.MethodGet, "/api/auth/mfa", false},
+	{http.MethodPost, "/api/auth/mfa/setup", true},
+	{http.MethodPost, "/api/auth/mfa/activate", true},
+	{http.MethodPost, "/api/auth/mfa/recovery-codes", true},
+	{http.MethodDelete, "/api/auth/mfa", true},
+	{http.MethodGet, "/api/auth/sessions", false},
+	{http.MethodDelete, "/api/auth/sessions/x", true},
+	{http.MethodPost, "/api/auth/impersonation/stop", false},
+	{http.MethodPost, "/api/auth/switch-company", true},
+	{http.MethodGet, "/api/api-keys", false},
+	{http.MethodPost, "/api/api-keys", true},
+	{http.MethodDelete, "/api/api-keys/x", true},
+	{http.MethodGet, "/api/notifications", false},
+	{http.MethodGet, "/api/notifications/count", false},
+	{http.MethodPut, "/api/notifications/x/read", false},
+	{http.MethodPut, "/api/notifications/read-all", false},
+}
+
+func TestAccountRoutes(t *testing.T) {
+	ts := newTestServer(t, client.Options{})
+	for _, rt := range accountRoutes {
+		ts.expect("", rt.method, rt.path, "", http.StatusUnauthorized)
+		if rt.blocked {
+			ts.expect("as-alice", rt.method, rt.path, "", http.StatusForbidden)
+		}
+	}
+}
+
+// TestEveryRouteIsCovered fails when a route is added without a case in the tables above.
+func TestEveryRouteIsCovered(t *testing.T) {
+	ts := newTestServer(t, client.Options{})
+
+	covered := map[string]bool{}
+	cover := func(method, path string) {
+		path, _, _ = strings.Cut(path, "?")
+		c := ts.echo.NewContext(httptest.NewRequest(method, "/", nil), httptest.NewRecorder())
+		ts.echo.Router().Find(method, path, c)
+		covered[method+" "+c.Path()] = true
+	}
+	for _, rt := range permissionRoutes {
+		cover(rt.method, ts.expand(rt.path))
+	}
+	for _, rt := range selfServiceRoutes {
+		cover(rt.method, ts.expand(rt.path))
+	}
+	for _, rt := range accountRoutes {
+		cover(rt.method, rt.path)
+	}
+
+	for _, r := range ts.echo.Routes() {
+		if publicRoutes[r.Path] || strings.HasPrefix(r.Path, "/api/scim/") || r.Method == echo.RouteNotFound {
+			continue
+		}
+		if !covered[r.Method+" "+r.Path] {
+			t.Errorf("%s %s has no test case", r.Method, r.Path)
+		}
+	}
+}
+
+// publicRoutes need no token; SCIM routes are also left out, as scim_test.go covers them.
+var publicRoutes = map[string]bool{
+	"/api/health": true, "/.well-known/jwks.json": true,
+	"/api/auth/login": true, "/api/auth/signup": true, "/api/auth/verify-email": true,
+	"/api/auth/refresh": true, "/api/auth/forgot-password": true, "/api/auth/reset-password": true,
+	"/api/auth/mfa/verify": true, "/api/auth/mfa/enroll": true,
+	"/api/auth/oidc/callback": true, "/api/auth/oidc/:slug/login": true, "/api/invites/accept": true,
+	"/api/webhooks/frappe": true,
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed of RFC 6238 appendix B, "12345678901234567890", in base32.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// The appendix lists 8-digit codes; these are their last six digits, as truncation to
+// totpDigits keeps them.
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTPWindow(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := TOTPStep(now)
+	code := func(s int64) string {
+		c, err := TOTPCode(rfcSecret, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	for _, s := range []int64{step - totpSkew, step, step + totpSkew} {
+		got, ok := ValidateTOTP(rfcSecret, code(s), now)
+		if !ok || got != s {
+			t.Errorf("code of step %+d: got step %d, %v, want it accepted as its own step", s-step, got, ok)
+		}
+	}
+	for _, s := range []int64{step - totpSkew - 1, step + totpSkew + 1} {
+		if _, ok := ValidateTOTP(rfcSecret, code(s), now); ok {
+			t.Errorf("code of step %+d accepted outside the skew window", s-step)
+		}
+	}
+}
+
+func TestValidateTOTPInput(t *testing.T) {
+	now := time.Unix(59, 0)
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{"exact", "287082", true},
+		{"spaced", " 287 082 ", true},
+		{"wrong", "287083", false},
+		{"eight digits", "94287082", false},
+		{"short", "28708", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		if _, ok := ValidateTOTP(rfcSecret, tt.code, now); ok != tt.want {
+			t.Errorf("%s: accepted = %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+	if _, ok := ValidateTOTP("not base32!", "287082", now); ok {
+		t.Error("invalid secret accepted a code")
+	}
+}
+
+func TestTOTPSecretSealing(t *testing.T) {
+	sealed, err := SealTOTPSecret(rfcSecret, "jwt-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sealed, rfcSecret) || len(sealed) == len(rfcSecret) {
+		t.Fatalf("sealed secret %q looks like the plaintext", sealed)
+	}
+	if got, err := OpenTOTPSecret(sealed, "jwt-secret"); err != nil || got != rfcSecret {
+		t.Fatalf("OpenTOTPSecret = %q, %v", got, err)
+	}
+	if _, err := OpenTOTPSecret(sealed, "another-secret"); err == nil {
+		t.Fatal("opened a totp secret with the wrong JWT secret")
+	}
+	if _, err := OpenTOTPSecret(rfcSecret, "jwt-secret"); err == nil {
+		t.Fatal("opened a plaintext secret")
+	}
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Store holds cached values until they expire. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Get returns the value for key and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// DeletePrefix removes every key starting with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+	// Incr adds one to the counter at key, which starts at zero and never expires, and
+	// returns the new value. Get returns the counter in decimal.
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// Cache fronts a Store with a TTL per Frappe method and coalesces concurrent loads of
+// the same key into one call. A nil *Cache caches nothing.
+//
+// Each method has a generation counter in the store, part of every key, that Invalidate
+// bumps. A load that started before an Invalidate, on this instance or another sharing
+// the store, writes under the old generation where no later Fetch looks.
+type Cache struct {
+	store Store
+	ttls  map[string]time.Duration
+	group singleflight.Group
+}
+
+// New returns a cache that keeps the result of each method in ttls for that long.
+// Methods without an entry are never cached.
+func New(store Store, ttls map[string]time.Duration) *Cache {
+	return &Cache{store: store, ttls: ttls}
+}
+
+// Caches reports whether results of method are cached.
+func (c *Cache) Caches(method string) bool {
+	return c != nil && c.ttls[method] > 0
+}
+
+// Fetch returns the cached result of calling method with params for tenant, calling
+// load to fill it on a miss. Concurrent misses for the same call share one load, which
+// runs detached from the caller's cancellation so one caller giving up does not fail
+// the others. Store errors are logged and treated as a miss: a broken cache only costs
+// speed.
+func (c *Cache) Fetch(ctx context.Context, tenant, method string, params map[string]string, load func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	gen, err := c.generation(ctx, method)
+	if err != nil {
+		log.Printf("cache: generation of %s: %v", method, err)
+		return load(ctx)
+	}
+	key := Key(tenant, method, gen, params)
+	if v, ok, err := c.store.Get(ctx, key); err != nil {
+		log.Printf("cache: get %s: %v", key, err)
+	} else if ok {
+		return v, nil
+	}
+
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		v, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.store.Set(loadCtx, key, v, c.ttls[method]); err != nil {
+			log.Printf("cache: set %s: %v", key, err)
+		}
+		return v, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]byte), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Invalidate drops every cached result of method for tenant, or for all tenants if
+// tenant is empty. Bumping the generation is what hides the old results, including any
+// still being loaded; deleting them only frees the space early.
+func (c *Cache) Invalidate(ctx context.Context, tenant, method string) error {
+	if c == nil {
+		return nil
+	}
+	if _, err := c.store.Incr(ctx, generationKey(method)); err != nil {
+		log.Printf("cache: invalidate %s: %v", method, err)
+		return err
+	}
+
+	prefix := Prefix(tenant, method)
+	if err := c.store.DeletePrefix(ctx, prefix); err != nil {
+		log.Printf("cache: invalidate %s: %v", prefix, err)
+		return err
+	}
+	return nil
+}
+
+// generation reads method's counter from the store; a method never invalidated is at 0.
+func (c *Cache) generation(ctx context.Context, method string) (int64, error) {
+	v, ok, err := c.store.Get(ctx, generationKey(method))
+	if err != nil || !ok {
+		return 0, err
+	}
+	return strconv.ParseInt(string(v), 10, 64)
+}
+
+// generationKey is outside every Prefix, so DeletePrefix never resets a counter.
+func generationKey(method string) string {
+	return "frappe-gen|" + method
+}
+
+// Prefix is the start of every key Key builds for tenant and method. With an empty
+// tenant it is the start of the keys of method for every tenant.
+func Prefix(tenant, method string) string {
+	if tenant == "" {
+		return "frappe|" + method + "|"
+	}
+	return "frappe|" + method + "|" + url.QueryEscape(tenant) + "|"
+}
+
+// Key is the cache key for a call of method with params on behalf of tenant, which
+// must not be empty, at generation gen of method. Params are sorted so the same call
+// always maps to the same key.
+func Key(tenant, method string, gen int64, params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for k := range params {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(Prefix(tenant, method))
+	b.WriteString(strconv.FormatInt(gen, 10))
+	b.WriteByte('|')
+	for i, k := range names {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(url.QueryEscape(k))
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(params[k]))
+	}
+	return b.String()
+}
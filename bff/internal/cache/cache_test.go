@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testMethod = "hr_core_ext.api.employee.get_employees"
+
+// counter is a load function that returns how many times it has been called.
+type counter struct{ calls atomic.Int64 }
+
+func (c *counter) load(context.Context) ([]byte, error) {
+	return []byte(strconv.FormatInt(c.calls.Add(1), 10)), nil
+}
+
+func fetch(t *testing.T, c *Cache, tenant string, load func(context.Context) ([]byte, error)) string {
+	t.Helper()
+	v, err := c.Fetch(context.Background(), tenant, testMethod, map[string]string{"status": "Active"}, load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(v)
+}
+
+// Two BFF instances share one store, as they share Redis in production.
+func newPair() (*Cache, *Cache) {
+	store := NewMemory()
+	ttls := map[string]time.Duration{testMethod: time.Minute}
+	return New(store, ttls), New(store, ttls)
+}
+
+func TestInvalidateReachesOtherInstance(t *testing.T) {
+	a, b := newPair()
+	var frappe counter
+	ctx := context.Background()
+
+	if got := fetch(t, a, "Acme", frappe.load); got != "1" {
+		t.Fatalf("first fetch = %s, want 1", got)
+	}
+	if got := fetch(t, b, "Acme", frappe.load); got != "1" {
+		t.Fatalf("other instance fetched %s, want the cached 1", got)
+	}
+
+	if err := b.Invalidate(ctx, "Acme", testMethod); err != nil {
+		t.Fatal(err)
+	}
+	if got := fetch(t, a, "Acme", frappe.load); got != "2" {
+		t.Fatalf("fetch after the other instance invalidated = %s, want a reload", got)
+	}
+
+	// Invalidating every tenant also reaches the other instance.
+	if err := a.Invalidate(ctx, "", testMethod); err != nil {
+		t.Fatal(err)
+	}
+	if got := fetch(t, b, "Acme", frappe.load); got != "3" {
+		t.Fatalf("fetch after invalidating all tenants = %s, want a reload", got)
+	}
+}
+
+// A load that started before another instance's Invalidate must not be served after it.
+func TestInvalidateDuringLoadOnOtherInstance(t *testing.T) {
+	a, b := newPair()
+	ctx := context.Background()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan string)
+	go func() {
+		v, err := a.Fetch(ctx, "Acme", testMethod, map[string]string{"status": "Active"}, func(context.Context) ([]byte, error) {
+			close(started)
+			<-release
+			return []byte("stale"), nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		done <- string(v)
+	}()
+
+	<-started
+	if err := b.Invalidate(ctx, "Acme", testMethod); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	if got := <-done; got != "stale" {
+		t.Fatalf("in-flight fetch = %s, want its own load", got)
+	}
+
+	fresh := func(context.Context) ([]byte, error) { return []byte("fresh"), nil }
+	for name, c := range map[string]*Cache{"loading instance": a, "invalidating instance": b} {
+		if got := fetch(t, c, "Acme", fresh); got != "fresh" {
+			t.Errorf("%s served %s after the invalidate, want fresh", name, got)
+		}
+	}
+}
+
+func TestInvalidateKeepsOtherMethods(t *testing.T) {
+	const other = "hr_core_ext.api.leave.get_leave_types"
+	store := NewMemory()
+	ttls := map[string]time.Duration{testMethod: time.Minute, other: time.Minute}
+	a, b := New(store, ttls), New(store, ttls)
+	var employees, leaveTypes counter
+	ctx := context.Background()
+
+	fetch(t, a, "Acme", employees.load)
+	if _, err := a.Fetch(ctx, "Acme", other, nil, leaveTypes.load); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Invalidate(ctx, "Acme", testMethod); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fetch(t, b, "Acme", employees.load); got != "2" {
+		t.Errorf("invalidated method = %s, want a reload", got)
+	}
+	if v, err := b.Fetch(ctx, "Acme", other, nil, leaveTypes.load); err != nil || string(v) != "1" {
+		t.Errorf("other method = %s, %v, want the cached 1", v, err)
+	}
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a Store shared by every BFF instance pointing at the same server.
+// Keys are namespaced so the database can be shared with other services.
+type Redis struct {
+	rdb *redis.Client
+}
+
+const redisNamespace = "hr-bff:"
+
+// NewRedis connects to the server at url, e.g. redis://redis:6379/2.
+func NewRedis(url string) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("parsing redis url: %w", err)
+	}
+	return &Redis{rdb: redis.NewClient(opts)}, nil
+}
+
+// Ping checks the server is reachable.
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.rdb.Ping(ctx).Err()
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	v, err := r.rdb.Get(ctx, redisNamespace+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.rdb.Set(ctx, redisNamespace+key, value, ttl).Err()
+}
+
+func (r *Redis) Incr(ctx context.Context, key string) (int64, error) {
+	return r.rdb.Incr(ctx, redisNamespace+key).Result()
+}
+
+// DeletePrefix scans for matching keys rather than using KEYS, so it does not block
+// the server.
+func (r *Redis) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := r.rdb.Scan(ctx, 0, escapeGlob(redisNamespace+prefix)+"*", 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.rdb.Del(ctx, keys...).Err()
+}
+
+// escapeGlob quotes the characters SCAN MATCH treats as wildcards.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
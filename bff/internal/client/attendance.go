@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+
+	"hr-platform/bff/internal/model"
+)
+
+// AttendanceClient calls the hr_core_ext.api.attendance methods for one tenant.
+type AttendanceClient struct {
+	t *TenantClient
+}
+
+// Attendance returns the tenant's attendance client.
+func (t *TenantClient) Attendance() *AttendanceClient {
+	return &AttendanceClient{t: t}
+}
+
+// DateRange bounds a read by date. Frappe defaults an empty From to the first of this
+// month and an empty To to today.
+type DateRange struct {
+	From string
+	To   string
+}
+
+func (r DateRange) params(employeeID string) *params {
+	return newParams().
+		required("employee_id", employeeID).
+		date("from_date", r.From, false).
+		date("to_date", r.To, false).
+		dateRange("from_date", "to_date")
+}
+
+// AttendanceRequestFilter narrows Requests.
+type AttendanceRequestFilter struct {
+	EmployeeID string
+}
+
+// AttendanceRequestSort lists the fields RequestPage can sort by.
+var AttendanceRequestSort = []string{"employee_name", "from_date", "to_date", "created"}
+
+func (f AttendanceRequestFilter) params() *params {
+	return newParams().optional("employee_id", f.EmployeeID)
+}
+
+// Summary returns the employee's attendance records in r and how many days they were
+// present, absent and on leave.
+func (a *AttendanceClient) Summary(ctx context.Context, employeeID string, r DateRange) (*model.EmployeeAttendance, error) {
+	var res model.EmployeeAttendance
+	if err := a.t.get(ctx, "hr_core_ext.api.attendance.get_attendance_summary", r.params(employeeID), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Detail is Summary with late entries and the check-ins of the range.
+func (a *AttendanceClient) Detail(ctx context.Context, employeeID string, r DateRange) (*model.AttendanceDetail, error) {
+	var res model.AttendanceDetail
+	if err := a.t.get(ctx, "hr_core_ext.api.attendance.get_attendance_detail", r.params(employeeID), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Requests returns the most recent attendance correction requests.
+func (a *AttendanceClient) Requests(ctx context.Context, f AttendanceRequestFilter) ([]model.AttendanceRequest, error) {
+	requests := []model.AttendanceRequest{}
+	if err := a.t.get(ctx, "hr_core_ext.api.attendance.get_attendance_requests", f.params(), &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// RequestPage returns one page of attendance correction requests, newest first unless pg sorts them.
+func (a *AttendanceClient) RequestPage(ctx context.Context, f AttendanceRequestFilter, pg Page) (*Paged[model.AttendanceRequest], error) {
+	return getPage[model.AttendanceRequest](ctx, a.t, "hr_core_ext.api.attendance.get_attendance_requests", f.params(), pg, AttendanceRequestSort)
+}
+
+// CreateRequest asks for the employee's attendance on a day to be corrected. An empty
+// Status is Frappe's default of "Present".
+func (a *AttendanceClient) CreateRequest(ctx context.Context, employeeID string, req model.CreateAttendanceRequestBody) (*model.AttendanceRequestResult, error) {
+	p := newParams().
+		required("employee_id", employeeID).
+		date("attendance_date", req.AttendanceDate, true).
+		required("reason", req.Reason)
+	if req.Status != "" {
+		p.oneOf("status", req.Status, "Present", "Work From Home", "Half Day")
+	}
+	var res model.AttendanceRequestResult
+	if err := a.t.post(ctx, "hr_core_ext.api.attendance.create_attendance_request", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DecideRequest approves or rejects an attendance request. action is "approve" or "reject".
+func (a *AttendanceClient) DecideRequest(ctx context.Context, requestID, action string) (*model.AttendanceDecision, error) {
+	p := newParams().required("request_id", requestID).oneOf("action", action, "approve", "reject")
+	var res model.AttendanceDecision
+	if err := a.t.post(ctx, "hr_core_ext.api.attendance.approve_attendance_request", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Checkin records the employee checking in now.
+func (a *AttendanceClient) Checkin(ctx context.Context, employeeID string) (*model.AttendanceCheckin, error) {
+	return a.log(ctx, "hr_core_ext.api.attendance.checkin", employeeID)
+}
+
+// Checkout records the employee checking out now.
+func (a *AttendanceClient) Checkout(ctx context.Context, employeeID string) (*model.AttendanceCheckin, error) {
+	return a.log(ctx, "hr_core_ext.api.attendance.checkout", employeeID)
+}
+
+func (a *AttendanceClient) log(ctx context.Context, method, employeeID string) (*model.AttendanceCheckin, error) {
+	p := newParams().required("employee_id", employeeID)
+	var res model.AttendanceCheckin
+	if err := a.t.post(ctx, method, p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Today returns the employee's check-ins today and the hours worked so far.
+func (a *AttendanceClient) Today(ctx context.Context, employeeID string) (*model.TodayCheckin, error) {
+	p := newParams().required("employee_id", employeeID)
+	var res model.TodayCheckin
+	if err := a.t.get(ctx, "hr_core_ext.api.attendance.get_today_checkin", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// History returns the employee's check-ins in r by day, most recent first.
+func (a *AttendanceClient) History(ctx context.Context, employeeID string, r DateRange) (*model.CheckinHistory, error) {
+	var res model.CheckinHistory
+	if err := a.t.get(ctx, "hr_core_ext.api.attendance.get_checkin_history", r.params(employeeID), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// Frappe reads that change rarely and are worth caching per tenant.
+const (
+	MethodTaxSlabs       = "hr_core_ext.api.tax.get_tax_slabs"
+	MethodShiftTypes     = "hr_core_ext.api.shift.get_shift_types"
+	MethodSSOConfig      = "hr_core_ext.api.social_security.get_sso_config"
+	MethodPVDConfig      = "hr_core_ext.api.provident_fund.get_pvd_config"
+	MethodOTConfig       = "hr_core_ext.api.overtime.get_ot_config"
+	MethodOrgTree        = "hr_core_ext.api.orgchart.get_org_tree"
+	MethodDepartmentTree = "hr_core_ext.api.orgchart.get_department_tree"
+	MethodDepartments    = "hr_core_ext.api.department.get_departments"
+)
+
+// DefaultCacheTTLs is how long each cached read is kept. The org chart and department
+// lists also change when employees are edited in Frappe directly, so they expire sooner.
+var DefaultCacheTTLs = map[string]time.Duration{
+	MethodTaxSlabs:       time.Hour,
+	MethodShiftTypes:     10 * time.Minute,
+	MethodSSOConfig:      time.Hour,
+	MethodPVDConfig:      time.Hour,
+	MethodOTConfig:       time.Hour,
+	MethodOrgTree:        5 * time.Minute,
+	MethodDepartmentTree: 5 * time.Minute,
+	MethodDepartments:    5 * time.Minute,
+}
+
+// Invalidate drops the tenant's cached results of methods after a change to the data
+// behind them. Methods that are not cached are skipped.
+func (t *TenantClient) Invalidate(ctx context.Context, methods ...string) error {
+	c := t.opts.Cache
+	var firstErr error
+	for _, m := range methods {
+		if !c.Caches(m) {
+			continue
+		}
+		if err := c.Invalidate(ctx, t.Company, m); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
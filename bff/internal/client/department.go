@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+
+	"hr-platform/bff/internal/model"
+)
+
+// DepartmentClient calls the hr_core_ext.api.department methods for one tenant.
+type DepartmentClient struct {
+	t *TenantClient
+}
+
+// Department returns the tenant's department client.
+func (t *TenantClient) Department() *DepartmentClient {
+	return &DepartmentClient{t: t}
+}
+
+// List returns the company's departments by name, with how many active employees each has.
+func (d *DepartmentClient) List(ctx context.Context) (*model.DepartmentList, error) {
+	var res model.DepartmentList
+	if err := d.t.get(ctx, MethodDepartments, newParams(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Get returns a department and its active employees.
+func (d *DepartmentClient) Get(ctx context.Context, name string) (*model.DepartmentDetail, error) {
+	p := newParams().required("name", name)
+	var res model.DepartmentDetail
+	if err := d.t.get(ctx, "hr_core_ext.api.department.get_department", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Create adds a department to the company, under req.ParentDepartment if it is set.
+func (d *DepartmentClient) Create(ctx context.Context, req model.DepartmentRequest) (*model.DepartmentResult, error) {
+	p := newParams().
+		required("department_name", req.DepartmentName).
+		optional("parent_department", req.ParentDepartment)
+	var res model.DepartmentResult
+	if err := d.t.post(ctx, "hr_core_ext.api.department.create_department", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Update renames a department or moves it under another; empty fields are left as they are.
+func (d *DepartmentClient) Update(ctx context.Context, name string, req model.DepartmentRequest) (*model.DepartmentResult, error) {
+	p := newParams().
+		required("name", name).
+		optional("department_name", req.DepartmentName).
+		optional("parent_department", req.ParentDepartment)
+	var res model.DepartmentResult
+	if err := d.t.post(ctx, "hr_core_ext.api.department.update_department", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Delete removes a department. Frappe refuses while active employees are assigned to it.
+func (d *DepartmentClient) Delete(ctx context.Context, name string) (*model.DepartmentDeleted, error) {
+	p := newParams().required("name", name)
+	var res model.DepartmentDeleted
+	if err := d.t.post(ctx, "hr_core_ext.api.department.delete_department", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// OrgChartClient calls the hr_core_ext.api.orgchart methods for one tenant.
+type OrgChartClient struct {
+	t *TenantClient
+}
+
+// OrgChart returns the tenant's org chart client.
+func (t *TenantClient) OrgChart() *OrgChartClient {
+	return &OrgChartClient{t: t}
+}
+
+// Tree returns the company's active employees arranged by who they report to.
+func (o *OrgChartClient) Tree(ctx context.Context) (*model.OrgTree, error) {
+	var res model.OrgTree
+	if err := o.t.get(ctx, MethodOrgTree, newParams(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Departments returns the company's active employees grouped by department.
+func (o *OrgChartClient) Departments(ctx context.Context) (*model.DepartmentTree, error) {
+	var res model.DepartmentTree
+	if err := o.t.get(ctx, MethodDepartmentTree, newParams(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+
+	"hr-platform/bff/internal/model"
+)
+
+// DirectoryBatch is the most employees EmployeesChangedSince returns per call.
+const DirectoryBatch = 500
+
+const methodEmployeeDirectory = "hr_core_ext.api.employee.get_employee_directory"
+
+// EmployeesChangedSince returns up to limit employees of every status, in the order they
+// were last modified, starting after the employee afterID last modified at modified.
+// Empty modified starts from the beginning.
+func (t *TenantClient) EmployeesChangedSince(ctx context.Context, modified, afterID string, limit int) ([]model.DirectoryEmployee, error) {
+	p := newParams().count("limit_page_length", limit)
+	if modified != "" {
+		p.set("modified_after", modified).set("after", afterID)
+	}
+	employees := []model.DirectoryEmployee{}
+	if err := t.get(ctx, methodEmployeeDirectory, p, &employees); err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
+
+// DirectoryEmployee returns one employee as EmployeesChangedSince would, or nil if the
+// tenant has no such employee.
+func (t *TenantClient) DirectoryEmployee(ctx context.Context, employeeID string) (*model.DirectoryEmployee, error) {
+	p := newParams().required("employee_id", employeeID)
+	var employees []model.DirectoryEmployee
+	if err := t.get(ctx, methodEmployeeDirectory, p, &employees); err != nil {
+		return nil, err
+	}
+	if len(employees) == 0 {
+		return nil, nil
+	}
+	return &employees[0], nil
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+
+	"hr-platform/bff/internal/model"
+)
+
+// EmployeeClient calls the hr_core_ext.api.employee methods for one tenant, and the
+// compensation, document and promotion methods that read one employee.
+type EmployeeClient struct {
+	t *TenantClient
+}
+
+// Employee returns the tenant's employee client.
+func (t *TenantClient) Employee() *EmployeeClient {
+	return &EmployeeClient{t: t}
+}
+
+// EmployeeFilter narrows List and Page.
+type EmployeeFilter struct {
+	EmployeeID  string
+	Department  string
+	Designation string
+	Status      string
+}
+
+// EmployeeSort lists the fields Page can sort by.
+var EmployeeSort = []string{"employee_id", "employee_name", "department", "designation", "date_of_joining"}
+
+func (f EmployeeFilter) params() *params {
+	return newParams().
+		optional("employee_id", f.EmployeeID).
+		optional("department", f.Department).
+		optional("designation", f.Designation).
+		optional("status", f.Status)
+}
+
+// List returns the first 20 employees matching f, by name.
+func (e *EmployeeClient) List(ctx context.Context, f EmployeeFilter) ([]model.Employee, error) {
+	employees := []model.Employee{}
+	if err := e.t.get(ctx, "hr_core_ext.api.employee.get_employees", f.params(), &employees); err != nil {
+		return nil, err
+	}
+	return employees, nil
+}
+
+// Page returns one page of employees matching f, by name unless pg sorts them.
+func (e *EmployeeClient) Page(ctx context.Context, f EmployeeFilter, pg Page) (*Paged[model.Employee], error) {
+	return getPage[model.Employee](ctx, e.t, "hr_core_ext.api.employee.get_employees", f.params(), pg, EmployeeSort)
+}
+
+// Get returns an employee with their personal and contact details.
+func (e *EmployeeClient) Get(ctx context.Context, employeeID string) (*model.EmployeeDetail, error) {
+	var res model.EmployeeDetail
+	if err := e.t.get(ctx, "hr_core_ext.api.employee.get_employee", employeeParams(employeeID), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Full returns the whole employee profile, with the names of their manager and leave approver.
+func (e *EmployeeClient) Full(ctx context.Context, employeeID string) (*model.EmployeeFull, error) {
+	var res model.EmployeeFull
+	if err := e.t.get(ctx, "hr_core_ext.api.employee.get_employee_full", employeeParams(employeeID), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ValidateManager reports whether managerID may become the employee's manager without
+// making a reporting cycle.
+func (e *EmployeeClient) ValidateManager(ctx context.Context, employeeID, managerID string) (*model.ManagerValidation, error) {
+	p := employeeParams(employeeID).required("manager_id", managerID)
+	var res model.ManagerValidation
+	if err := e.t.get(ctx, "hr_core_ext.api.employee.validate_manager", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Update changes the fields that are set in req. Frappe refuses any change but status
+// to an employee who is not Active.
+func (e *EmployeeClient) Update(ctx context.Context, employeeID string, req model.EmployeeUpdate) (*model.EmployeeUpdated, error) {
+	p := employeeParams(employeeID)
+	fields := []struct {
+		key string
+		v   *string
+	}{
+		{"employee_name", req.EmployeeName},
+		{"department", req.Department},
+		{"designation", req.Designation},
+		{"employment_type", req.EmploymentType},
+		{"branch", req.Branch},
+		{"reports_to", req.ReportsTo},
+		{"leave_approver", req.LeaveApprover},
+		{"cell_phone", req.CellPhone},
+		{"personal_email", req.PersonalEmail},
+		{"company_email", req.CompanyEmail},
+		{"current_address", req.CurrentAddress},
+		{"permanent_address", req.PermanentAddress},
+		{"emergency_phone_number", req.EmergencyPhone},
+		{"person_to_be_contacted", req.PersonToBeContacted},
+		{"relation", req.Relation},
+		{"gender", req.Gender},
+		{"marital_status", req.MaritalStatus},
+		{"blood_group", req.BloodGroup},
+	}
+	for _, f := range fields {
+		if f.v != nil {
+			p.set(f.key, *f.v)
+		}
+	}
+	if req.Status != nil {
+		p.oneOf("status", *req.Status, "Active", "Inactive", "Suspended", "Left")
+	}
+	if req.DateOfBirth != nil {
+		if *req.DateOfBirth == "" {
+			p.set("date_of_birth", "")
+		} else {
+			p.date("date_of_birth", *req.DateOfBirth, true)
+		}
+	}
+	var res model.EmployeeUpdated
+	if err := e.t.post(ctx, "hr_core_ext.api.employee.update_employee", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateContact changes the contact fields that are set in req; it is the part of the
+// profile employees may edit themselves.
+func (e *EmployeeClient) UpdateContact(ctx context.Context, employeeID string, req model.EmployeeContactUpdate) (*model.EmployeeUpdated, error) {
+	p := employeeParams(employeeID)
+	fields := []struct {
+		key string
+		v   *string
+	}{
+		{"cell_phone", req.CellPhone},
+		{"personal_email", req.PersonalEmail},
+		{"current_address", req.CurrentAddress},
+		{"permanent_address", req.PermanentAddress},
+		{"emergency_phone_number", req.EmergencyPhone},
+		{"person_to_be_contacted", req.PersonToBeContacted},
+		{"relation", req.Relation},
+	}
+	for _, f := range fields {
+		if f.v != nil {
+			p.set(f.key, *f.v)
+		}
+	}
+	var res model.EmployeeUpdated
+	if err := e.t.post(ctx, "hr_core_ext.api.employee.update_employee_contact", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Timeline returns the employee's profile changes and leave applications, most recent first.
+func (e *EmployeeClient) Timeline(ctx context.Context, employeeID string) ([]model.TimelineEvent, error) {
+	events := []model.TimelineEvent{}
+	if err := e.t.get(ctx, "hr_core_ext.api.employee.get_employee_timeline", employeeParams(employeeID), &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Compensation returns the employee's current salary structure assignment and its components.
+func (e *EmployeeClient) Compensation(ctx context.Context, employeeID string) (*model.SalaryStructureResponse, error) {
+	var res model.SalaryStructureResponse
+	if err := e.t.get(ctx, "hr_core_ext.api.compensation.get_salary_structure", employeeParams(employeeID), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Promotions returns the employee's submitted promotions, most recent first.
+func (e *EmployeeClient) Promotions(ctx context.Context, employeeID string) ([]model.Promotion, error) {
+	promotions := []model.Promotion{}
+	if err := e.t.get(ctx, "hr_core_ext.api.promotion.get_promotions", employeeParams(employeeID), &promotions); err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// Documents returns the private files attached to the employee, newest first.
+func (e *EmployeeClient) Documents(ctx context.Context, employeeID string) ([]model.EmployeeDocument, error) {
+	docs := []model.EmployeeDocument{}
+	if err := e.t.get(ctx, "hr_core_ext.api.document.get_employee_documents", employeeParams(employeeID), &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// DeleteDocument deletes an attached file by its File name.
+func (e *EmployeeClient) DeleteDocument(ctx context.Context, fileName string) (*model.DocumentDeleted, error) {
+	p := newParams().required("file_name", fileName)
+	var res model.DocumentDeleted
+	if err := e.t.post(ctx, "hr_core_ext.api.document.delete_employee_document", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func employeeParams(employeeID string) *params {
+	return newParams().required("employee_id", employeeID)
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Ops for MethodTimeouts. Both bound the whole transfer, so large files may need more
+// than the default Timeout.
+const (
+	OpUploadFile   = "upload_file"
+	OpDownloadFile = "download_file"
+)
+
+// Upload is a file for UploadFile to attach to a document.
+type Upload struct {
+	Doctype  string
+	Docname  string
+	FileName string
+	// Folder is the File folder, "Home/Attachments" if empty.
+	Folder string
+	// Private files are only served to authenticated users, which for the BFF means OpenFile.
+	Private bool
+	Body    io.Reader
+}
+
+// UploadFile streams u.Body to Frappe's upload_file as multipart/form-data and returns
+// the File document Frappe created, without holding the file in memory. It is never
+// retried, since the body can only be read once. If reading u.Body fails, the upload is
+// abandoned and that error is returned as is.
+func (c *FrappeClient) UploadFile(ctx context.Context, u Upload) (json.RawMessage, error) {
+	if !c.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout(OpUploadFile))
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	written := make(chan error, 1)
+	go func() {
+		err := writeUpload(mw, u)
+		written <- err
+		pw.CloseWithError(err)
+	}()
+
+	body, err := c.send(ctx, call{
+		op:          OpUploadFile,
+		method:      http.MethodPost,
+		path:        "/api/method/upload_file",
+		contentType: mw.FormDataContentType(),
+		stream:      pr,
+		auth:        true,
+	})
+	// Unblocks the writer if Frappe answered before reading all of it
+	pr.Close()
+	if err != nil {
+		// A failed read of u.Body reaches Frappe's request as a pipe error, after the
+		// writer has reported it. It says nothing about Frappe's health.
+		select {
+		case werr := <-written:
+			if werr != nil && !errors.Is(werr, io.ErrClosedPipe) {
+				c.breaker.release()
+				return nil, werr
+			}
+		default:
+		}
+	}
+	c.settle(err)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Message json.RawMessage `json:"message"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	return resp.Message, nil
+}
+
+// writeUpload writes u's form fields, then its file, which upload_file expects last.
+func writeUpload(mw *multipart.Writer, u Upload) error {
+	folder := u.Folder
+	if folder == "" {
+		folder = "Home/Attachments"
+	}
+	private := "0"
+	if u.Private {
+		private = "1"
+	}
+	for _, f := range [][2]string{
+		{"doctype", u.Doctype},
+		{"docname", u.Docname},
+		{"folder", folder},
+		{"is_private", private},
+		{"file_name", u.FileName},
+	} {
+		if err := mw.WriteField(f[0], f[1]); err != nil {
+			return err
+		}
+	}
+	part, err := mw.CreateFormFile("file", u.FileName)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, u.Body); err != nil {
+		return err
+	}
+	return mw.Close()
+}
+
+// File is a download from Frappe. Closing Body ends the download.
+type File struct {
+	Body        io.ReadCloser
+	ContentType string
+	// Size is -1 if Frappe did not say.
+	Size int64
+}
+
+// OpenFile starts downloading the file Frappe serves at fileURL, the file_url of a File
+// document, so the caller can stream it on without holding it in memory.
+func (c *FrappeClient) OpenFile(ctx context.Context, fileURL string) (*File, error) {
+	if !strings.HasPrefix(fileURL, "/private/files/") && !strings.HasPrefix(fileURL, "/files/") {
+		return nil, fmt.Errorf("not a Frappe file URL: %q", fileURL)
+	}
+	segments := strings.Split(fileURL, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	if !c.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout(OpDownloadFile))
+	resp, err := c.request(ctx, call{
+		op:     OpDownloadFile,
+		method: http.MethodGet,
+		path:   strings.Join(segments, "/"),
+		auth:   true,
+	})
+	c.settle(err)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	size := int64(-1)
+	if n, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+		size = n
+	}
+	return &File{
+		Body:        cancelOnClose{ReadCloser: resp.Body, cancel: cancel},
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        size,
+	}, nil
+}
+
+// cancelOnClose releases a download's context along with its body.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
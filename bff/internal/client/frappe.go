@@ -1,13 +1,19 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"hr-platform/bff/internal/cache"
 )
 
 // FrappeError represents an error returned by the Frappe API with a human-readable message.
@@ -64,38 +70,145 @@ func parseFrappeError(statusCode int, body []byte) *FrappeError {
 	return fe
 }
 
+// Options tunes how the client talks to Frappe.
+type Options struct {
+	// Timeout bounds each attempt of a call unless MethodTimeouts has an entry for it.
+	Timeout time.Duration
+	// MethodTimeouts overrides Timeout per Frappe method (e.g. "hr_core_ext.api.reports.get_headcount")
+	// or per resource doctype (e.g. "Employee"). A key can also be a dotted prefix, such as
+	// "hr_core_ext.api.reports", to cover a module; the longest match wins.
+	MethodTimeouts map[string]time.Duration
+	// MaxRetries is how many more times an idempotent GET is tried after a transport
+	// error, timeout or 429/502/503/504, waiting a jittered, doubling delay in between.
+	MaxRetries int
+	// RetryDelay is the upper bound of the first wait between attempts.
+	RetryDelay time.Duration
+	// BreakerThreshold consecutive failures open the circuit breaker for BreakerCooldown,
+	// during which calls fail with ErrCircuitOpen. Zero disables the breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// Cache, if set, serves the tenant-scoped reads it has a TTL for (see DefaultCacheTTLs).
+	Cache *cache.Cache
+}
+
+// DefaultOptions are used for any zero field passed to NewFrappeClient.
+var DefaultOptions = Options{
+	Timeout:          15 * time.Second,
+	MaxRetries:       2,
+	RetryDelay:       200 * time.Millisecond,
+	BreakerThreshold: 5,
+	BreakerCooldown:  30 * time.Second,
+}
+
+const maxRetryDelay = 2 * time.Second
+
 type FrappeClient struct {
 	BaseURL    string
 	APIKey     string
 	APISecret  string
 	HTTPClient *http.Client
+
+	opts    Options
+	breaker *breaker
 }
 
-func NewFrappeClient(baseURL, apiKey, apiSecret string) *FrappeClient {
+func NewFrappeClient(baseURL, apiKey, apiSecret string, opts Options) *FrappeClient {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultOptions.Timeout
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = DefaultOptions.RetryDelay
+	}
+	if opts.BreakerCooldown <= 0 {
+		opts.BreakerCooldown = DefaultOptions.BreakerCooldown
+	}
 	return &FrappeClient{
 		BaseURL:   strings.TrimRight(baseURL, "/"),
 		APIKey:    apiKey,
 		APISecret: apiSecret,
-		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		// Deadlines come from the caller's context and the per-method timeout
+		HTTPClient: &http.Client{},
+		opts:       opts,
+		breaker:    newBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
 	}
 }
 
-func (c *FrappeClient) doRequest(method, path string, body io.Reader) ([]byte, error) {
-	reqURL := fmt.Sprintf("%s%s", c.BaseURL, path)
-	req, err := http.NewRequest(method, reqURL, body)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+// call is one Frappe request: op names it for MethodTimeouts, and idempotent calls are retried.
+type call struct {
+	op          string
+	method      string
+	path        string
+	contentType string
+	body        string
+	// stream, if set, is sent instead of body. It can only be read once, so a call
+	// with a stream must not be idempotent.
+	stream     io.Reader
+	auth       bool
+	idempotent bool
+}
+
+// do sends c, retrying if it is idempotent, and returns the response body. Non-2xx
+// responses become a *FrappeError.
+func (c *FrappeClient) do(ctx context.Context, rc call) ([]byte, error) {
+	attempts := 1
+	if rc.idempotent {
+		attempts += c.opts.MaxRetries
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("token %s:%s", c.APIKey, c.APISecret))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			if sleep(ctx, c.retryDelay(i)) != nil {
+				return nil, err // the last attempt's failure says more than the cancellation
+			}
+		}
+		var body []byte
+		body, err = c.attempt(ctx, rc)
+		if err == nil {
+			return body, nil
+		}
+		if !retryable(ctx, err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
 
-	resp, err := c.HTTPClient.Do(req)
+// attempt makes a single request under the op's timeout and the circuit breaker.
+func (c *FrappeClient) attempt(ctx context.Context, rc call) ([]byte, error) {
+	if !c.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout(rc.op))
+	defer cancel()
+
+	body, err := c.send(ctx, rc)
+	c.settle(err)
+	return body, err
+}
+
+// settle reports the outcome of a request the breaker allowed.
+func (c *FrappeClient) settle(err error) {
+	switch {
+	case err == nil:
+		c.breaker.record(true)
+	case errors.Is(err, context.Canceled):
+		// The caller gave up; that says nothing about Frappe's health
+		c.breaker.release()
+	default:
+		var fe *FrappeError
+		c.breaker.record(errors.As(err, &fe) && fe.StatusCode < 500)
+	}
+}
+
+func (c *FrappeClient) send(ctx context.Context, rc call) ([]byte, error) {
+	resp, err := c.request(ctx, rc)
 	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -103,54 +216,142 @@ func (c *FrappeClient) doRequest(method, path string, body io.Reader) ([]byte, e
 	if err != nil {
 		return nil, fmt.Errorf("reading response: %w", err)
 	}
+	return respBody, nil
+}
+
+// request sends rc and returns the response for the caller to read and close. Non-2xx
+// responses are read here and returned as a *FrappeError.
+func (c *FrappeClient) request(ctx context.Context, rc call) (*http.Response, error) {
+	var body io.Reader
+	switch {
+	case rc.stream != nil:
+		body = rc.stream
+	case rc.body != "":
+		body = strings.NewReader(rc.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, rc.method, c.BaseURL+rc.path, body)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	if rc.auth {
+		req.Header.Set("Authorization", fmt.Sprintf("token %s:%s", c.APIKey, c.APISecret))
+	}
+	if rc.contentType != "" {
+		req.Header.Set("Content-Type", rc.contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("executing request: %w", err)
+	}
 
 	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("reading response: %w", err)
+		}
 		return nil, parseFrappeError(resp.StatusCode, respBody)
 	}
 
-	return respBody, nil
+	return resp, nil
+}
+
+// timeout returns the timeout for op: an exact MethodTimeouts entry, else the longest
+// dotted prefix, else Timeout.
+func (c *FrappeClient) timeout(op string) time.Duration {
+	for key := op; key != ""; {
+		if d, ok := c.opts.MethodTimeouts[key]; ok && d > 0 {
+			return d
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return c.opts.Timeout
+}
+
+// retryDelay is a random wait in [0, RetryDelay*2^(retry-1)), capped at maxRetryDelay.
+func (c *FrappeClient) retryDelay(retry int) time.Duration {
+	d := c.opts.RetryDelay << (retry - 1)
+	if d <= 0 || d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return time.Duration(rand.Int64N(int64(d)))
+}
+
+// retryable reports whether a failed attempt is worth repeating: not if the caller is done
+// or the breaker is open, and for Frappe responses only on 429 and gateway errors.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	var fe *FrappeError
+	if errors.As(err, &fe) {
+		switch fe.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
 }
 
 // Login authenticates a user against Frappe and returns user info.
-func (c *FrappeClient) Login(username, password string) (map[string]interface{}, error) {
+func (c *FrappeClient) Login(ctx context.Context, username, password string) (map[string]interface{}, error) {
 	data := url.Values{}
 	data.Set("usr", username)
 	data.Set("pwd", password)
 
-	reqURL := fmt.Sprintf("%s/api/method/login", c.BaseURL)
-	req, err := http.NewRequest("POST", reqURL, strings.NewReader(data.Encode()))
+	body, err := c.do(ctx, call{
+		op:          "login",
+		method:      http.MethodPost,
+		path:        "/api/method/login",
+		contentType: "application/x-www-form-urlencoded",
+		body:        data.Encode(),
+	})
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("login failed with status %d", resp.StatusCode)
-	}
 
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
 
-// CallMethod calls a whitelisted Frappe method.
-func (c *FrappeClient) CallMethod(method string, params map[string]string) (json.RawMessage, error) {
+// CallMethod calls a whitelisted Frappe method. It is a GET, so it is retried.
+func (c *FrappeClient) CallMethod(ctx context.Context, method string, params map[string]string) (json.RawMessage, error) {
 	query := url.Values{}
 	for k, v := range params {
 		query.Set(k, v)
 	}
 
-	path := fmt.Sprintf("/api/method/%s?%s", method, query.Encode())
-	body, err := c.doRequest("GET", path, nil)
+	body, err := c.do(ctx, call{
+		op:          method,
+		method:      http.MethodGet,
+		path:        fmt.Sprintf("/api/method/%s?%s", method, query.Encode()),
+		contentType: "application/json",
+		auth:        true,
+		idempotent:  true,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -166,14 +367,14 @@ func (c *FrappeClient) CallMethod(method string, params map[string]string) (json
 }
 
 // CreateCompany creates a new company in Frappe. Returns the Frappe company name.
-func (c *FrappeClient) CreateCompany(name, abbr, country string) (string, error) {
+func (c *FrappeClient) CreateCompany(ctx context.Context, name, abbr, country string) (string, error) {
 	payload := map[string]string{
 		"company_name": name,
 		"abbr":         abbr,
 		"country":      country,
 	}
 
-	result, err := c.CallMethodPost("hr_core_ext.api.company.create_company", payload)
+	result, err := c.CallMethodPost(ctx, "hr_core_ext.api.company.create_company", payload)
 	if err != nil {
 		return "", fmt.Errorf("creating Frappe company: %w", err)
 	}
@@ -191,7 +392,7 @@ func (c *FrappeClient) CreateCompany(name, abbr, country string) (string, error)
 }
 
 // CreateEmployee creates a new employee in Frappe. Returns the employee_id.
-func (c *FrappeClient) CreateEmployee(employeeName, company, department, designation string) (string, error) {
+func (c *FrappeClient) CreateEmployee(ctx context.Context, employeeName, company, department, designation string) (string, error) {
 	payload := map[string]string{
 		"employee_name": employeeName,
 		"company":       company,
@@ -203,7 +404,7 @@ func (c *FrappeClient) CreateEmployee(employeeName, company, department, designa
 		payload["designation"] = designation
 	}
 
-	result, err := c.CallMethodPost("hr_core_ext.api.company.create_employee", payload)
+	result, err := c.CallMethodPost(ctx, "hr_core_ext.api.company.create_employee", payload)
 	if err != nil {
 		return "", fmt.Errorf("creating Frappe employee: %w", err)
 	}
@@ -217,36 +418,52 @@ func (c *FrappeClient) CreateEmployee(employeeName, company, department, designa
 	return resp.EmployeeID, nil
 }
 
-// CallMethodPost calls a whitelisted Frappe method via POST (for mutations).
-func (c *FrappeClient) CallMethodPost(method string, params map[string]string) (json.RawMessage, error) {
-	payload := url.Values{}
-	for k, v := range params {
-		payload.Set(k, v)
-	}
-
-	reqURL := fmt.Sprintf("%s/api/method/%s", c.BaseURL, method)
-	req, err := http.NewRequest("POST", reqURL, strings.NewReader(payload.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
+// FindCompany returns the Frappe name of the company called companyName, or "" if there
+// is none. Provisioning uses it to pick up a company an interrupted attempt already created.
+func (c *FrappeClient) FindCompany(ctx context.Context, companyName string) (string, error) {
+	return c.findName(ctx, "Company", map[string]string{"company_name": companyName})
+}
 
-	req.Header.Set("Authorization", fmt.Sprintf("token %s:%s", c.APIKey, c.APISecret))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("Accept", "application/json")
+// FindEmployee returns the ID of an employee with this name in company, or "" if there is none.
+func (c *FrappeClient) FindEmployee(ctx context.Context, employeeName, company string) (string, error) {
+	return c.findName(ctx, "Employee", map[string]string{"employee_name": employeeName, "company": company})
+}
 
-	resp, err := c.HTTPClient.Do(req)
+func (c *FrappeClient) findName(ctx context.Context, doctype string, filters map[string]string) (string, error) {
+	data, err := c.GetResource(ctx, doctype, ResourceQuery{Filters: filters, Fields: []string{"name"}, Limit: 1})
 	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
+		return "", err
 	}
-	defer resp.Body.Close()
+	var rows []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return "", fmt.Errorf("decoding %s lookup: %w", doctype, err)
+	}
+	if len(rows) == 0 {
+		return "", nil
+	}
+	return rows[0].Name, nil
+}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
+// CallMethodPost calls a whitelisted Frappe method via POST (for mutations). It is never
+// retried, since Frappe may have applied a request whose response was lost.
+func (c *FrappeClient) CallMethodPost(ctx context.Context, method string, params map[string]string) (json.RawMessage, error) {
+	payload := url.Values{}
+	for k, v := range params {
+		payload.Set(k, v)
 	}
 
-	if resp.StatusCode >= 400 {
-		return nil, parseFrappeError(resp.StatusCode, respBody)
+	respBody, err := c.do(ctx, call{
+		op:          method,
+		method:      http.MethodPost,
+		path:        "/api/method/" + method,
+		contentType: "application/x-www-form-urlencoded",
+		body:        payload.Encode(),
+		auth:        true,
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	var result struct {
@@ -259,21 +476,45 @@ func (c *FrappeClient) CallMethodPost(method string, params map[string]string) (
 	return result.Message, nil
 }
 
-// GetResource fetches a list of Frappe resources.
-func (c *FrappeClient) GetResource(doctype string, filters map[string]string, fields []string, limit int) (json.RawMessage, error) {
+// ResourceQuery selects rows of a doctype for GetResource. A zero Limit lets Frappe
+// apply its default page length; OrderBy is a Frappe order clause such as "modified desc".
+type ResourceQuery struct {
+	Filters map[string]string
+	Fields  []string
+	Limit   int
+	Offset  int
+	OrderBy string
+}
+
+// GetResource fetches one page of a list of Frappe resources.
+func (c *FrappeClient) GetResource(ctx context.Context, doctype string, q ResourceQuery) (json.RawMessage, error) {
 	query := url.Values{}
-	if len(filters) > 0 {
-		filtersJSON, _ := json.Marshal(filters)
+	if len(q.Filters) > 0 {
+		filtersJSON, _ := json.Marshal(q.Filters)
 		query.Set("filters", string(filtersJSON))
 	}
-	if len(fields) > 0 {
-		fieldsJSON, _ := json.Marshal(fields)
+	if len(q.Fields) > 0 {
+		fieldsJSON, _ := json.Marshal(q.Fields)
 		query.Set("fields", string(fieldsJSON))
 	}
-	query.Set("limit_page_length", fmt.Sprintf("%d", limit))
+	if q.Limit > 0 {
+		query.Set("limit_page_length", strconv.Itoa(q.Limit))
+	}
+	if q.Offset > 0 {
+		query.Set("limit_start", strconv.Itoa(q.Offset))
+	}
+	if q.OrderBy != "" {
+		query.Set("order_by", q.OrderBy)
+	}
 
-	path := fmt.Sprintf("/api/resource/%s?%s", doctype, query.Encode())
-	body, err := c.doRequest("GET", path, nil)
+	body, err := c.do(ctx, call{
+		op:          doctype,
+		method:      http.MethodGet,
+		path:        fmt.Sprintf("/api/resource/%s?%s", url.PathEscape(doctype), query.Encode()),
+		contentType: "application/json",
+		auth:        true,
+		idempotent:  true,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+
+	"hr-platform/bff/internal/model"
+)
+
+// LeaveClient calls the hr_core_ext.api.leave methods for one tenant.
+type LeaveClient struct {
+	t *TenantClient
+}
+
+// Leave returns the tenant's leave client.
+func (t *TenantClient) Leave() *LeaveClient {
+	return &LeaveClient{t: t}
+}
+
+// LeaveFilter narrows List. Empty fields match everything.
+type LeaveFilter struct {
+	EmployeeID string
+	Status     string
+}
+
+// CheckBalance fails if Frappe cannot compute the employee's leave balance, which
+// happens when the employee does not exist or has no leave policy.
+func (l *LeaveClient) CheckBalance(ctx context.Context, employeeID string) error {
+	p := newParams().required("employee_id", employeeID)
+	if err := p.err(); err != nil {
+		return err
+	}
+	_, err := l.t.CallMethod(ctx, "hr_core_ext.api.leave.get_leave_balance", p.values)
+	return err
+}
+
+// LeaveSort lists the fields ListPage can sort by.
+var LeaveSort = []string{"employee_name", "leave_type", "from_date", "to_date", "posting_date", "status"}
+
+func (f LeaveFilter) params() *params {
+	return newParams().optional("employee_id", f.EmployeeID).optional("status", f.Status)
+}
+
+// List returns leave applications, newest first.
+func (l *LeaveClient) List(ctx context.Context, f LeaveFilter) ([]model.LeaveApplication, error) {
+	apps := []model.LeaveApplication{}
+	if err := l.t.get(ctx, "hr_core_ext.api.leave.get_leave_applications", f.params(), &apps); err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+// ListPage returns one page of leave applications, newest first unless pg sorts them.
+func (l *LeaveClient) ListPage(ctx context.Context, f LeaveFilter, pg Page) (*Paged[model.LeaveApplication], error) {
+	return getPage[model.LeaveApplication](ctx, l.t, "hr_core_ext.api.leave.get_leave_applications", f.params(), pg, LeaveSort)
+}
+
+// Allocations returns the employee's current allocation, usage and remaining days per leave type.
+func (l *LeaveClient) Allocations(ctx context.Context, employeeID string) ([]model.LeaveAllocation, error) {
+	p := newParams().required("employee_id", employeeID)
+	allocs := []model.LeaveAllocation{}
+	if err := l.t.get(ctx, "hr_core_ext.api.leave.get_leave_allocations", p, &allocs); err != nil {
+		return nil, err
+	}
+	return allocs, nil
+}
+
+// Create opens a leave application for the employee.
+func (l *LeaveClient) Create(ctx context.Context, employeeID string, req model.CreateLeaveRequest) (*model.LeaveResult, error) {
+	p := newParams().
+		required("employee_id", employeeID).
+		required("leave_type", req.LeaveType).
+		date("from_date", req.FromDate, true).
+		date("to_date", req.ToDate, true).
+		dateRange("from_date", "to_date").
+		set("reason", req.Reason)
+	var res model.LeaveResult
+	if err := l.t.post(ctx, "hr_core_ext.api.leave.create_leave_application", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Update changes the fields of an open leave application that are set in req.
+func (l *LeaveClient) Update(ctx context.Context, leaveID string, req model.UpdateLeaveRequest) (*model.LeaveResult, error) {
+	p := newParams().required("leave_id", leaveID)
+	if req.LeaveType != nil {
+		p.required("leave_type", *req.LeaveType)
+	}
+	if req.FromDate != nil {
+		p.date("from_date", *req.FromDate, true)
+	}
+	if req.ToDate != nil {
+		p.date("to_date", *req.ToDate, true)
+	}
+	p.dateRange("from_date", "to_date")
+	if req.Reason != nil {
+		p.set("reason", *req.Reason)
+	}
+	var res model.LeaveResult
+	if err := l.t.post(ctx, "hr_core_ext.api.leave.update_leave_application", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Decide approves or rejects a leave application. status is "Approved" or "Rejected".
+func (l *LeaveClient) Decide(ctx context.Context, leaveID, status string) (*model.LeaveResult, error) {
+	p := newParams().required("leave_id", leaveID).oneOf("status", status, "Approved", "Rejected")
+	var res model.LeaveResult
+	if err := l.t.post(ctx, "hr_core_ext.api.leave.approve_leave_application", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Cancel cancels an open leave application.
+func (l *LeaveClient) Cancel(ctx context.Context, leaveID string) (*model.LeaveResult, error) {
+	p := newParams().required("leave_id", leaveID)
+	var res model.LeaveResult
+	if err := l.t.post(ctx, "hr_core_ext.api.leave.cancel_leave_application", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
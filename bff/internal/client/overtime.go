@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"strconv"
+
+	"hr-platform/bff/internal/model"
+)
+
+// OvertimeClient calls the hr_core_ext.api.overtime methods for one tenant.
+type OvertimeClient struct {
+	t *TenantClient
+}
+
+// Overtime returns the tenant's overtime client.
+func (t *TenantClient) Overtime() *OvertimeClient {
+	return &OvertimeClient{t: t}
+}
+
+// Config returns the company's OT pay multipliers and standard working time.
+func (o *OvertimeClient) Config(ctx context.Context) (*model.OTConfig, error) {
+	var res model.OTConfig
+	if err := o.t.get(ctx, MethodOTConfig, newParams(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateConfig changes the settings that are set in req and returns the result.
+func (o *OvertimeClient) UpdateConfig(ctx context.Context, req model.OTConfigRequest) (*model.OTConfig, error) {
+	p := newParams()
+	rates := []struct {
+		key string
+		v   *float64
+	}{
+		{"weekday_ot_rate", req.WeekdayOTRate},
+		{"holiday_work_monthly", req.HolidayWorkMonthly},
+		{"holiday_work_daily", req.HolidayWorkDaily},
+		{"holiday_ot_rate", req.HolidayOTRate},
+	}
+	for _, r := range rates {
+		if r.v != nil {
+			p.amount(r.key, *r.v)
+		}
+	}
+	if req.StandardHoursPerDay != nil {
+		p.count("standard_hours_per_day", *req.StandardHoursPerDay)
+	}
+	if req.StandardWorkingDays != nil {
+		p.count("standard_working_days", *req.StandardWorkingDays)
+	}
+	var res model.OTConfig
+	if err := o.t.post(ctx, "hr_core_ext.api.overtime.update_ot_config", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// OTRequestFilter narrows Requests. Month and Year only filter when both are set.
+type OTRequestFilter struct {
+	EmployeeID string
+	Status     string
+	Month      int
+	Year       int
+}
+
+// OvertimeSort lists the fields RequestPage can sort by.
+var OvertimeSort = []string{"employee_name", "ot_date", "amount"}
+
+func (f OTRequestFilter) params() *params {
+	p := newParams().optional("employee_id", f.EmployeeID)
+	if f.Status != "" {
+		p.oneOf("status", f.Status, "Pending", "Approved", "Rejected", "Cancelled")
+	}
+	if f.Month != 0 || f.Year != 0 {
+		p.period(f.Month, f.Year)
+	}
+	return p
+}
+
+// Requests returns the OT requests matching f, most recent first.
+func (o *OvertimeClient) Requests(ctx context.Context, f OTRequestFilter) ([]model.OTRequest, error) {
+	requests := []model.OTRequest{}
+	if err := o.t.get(ctx, "hr_core_ext.api.overtime.get_ot_requests", f.params(), &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// RequestPage returns one page of OT requests, most recent first unless pg sorts them.
+func (o *OvertimeClient) RequestPage(ctx context.Context, f OTRequestFilter, pg Page) (*Paged[model.OTRequest], error) {
+	return getPage[model.OTRequest](ctx, o.t, "hr_core_ext.api.overtime.get_ot_requests", f.params(), pg, OvertimeSort)
+}
+
+// Create asks for overtime the employee worked. Its pay is worked out when it is approved.
+func (o *OvertimeClient) Create(ctx context.Context, employeeID string, req model.CreateOTRequestBody) (*model.OTRequest, error) {
+	p := newParams().
+		required("employee_id", employeeID).
+		date("ot_date", req.OTDate, true).
+		oneOf("ot_type", req.OTType, "weekday_ot", "holiday_work", "holiday_ot").
+		hours("hours", req.Hours).
+		optional("reason", req.Reason)
+	var res model.OTRequest
+	if err := o.t.post(ctx, "hr_core_ext.api.overtime.create_ot_request", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Decide approves or rejects a pending OT request. action is "approve" or "reject".
+func (o *OvertimeClient) Decide(ctx context.Context, requestID, action string) (*model.OTDecision, error) {
+	method := "hr_core_ext.api.overtime.approve_ot_request"
+	p := newParams().required("request_id", requestID)
+	switch action {
+	case "approve":
+	case "reject":
+		method = "hr_core_ext.api.overtime.reject_ot_request"
+	default:
+		p.fail(invalid("action must be 'approve' or 'reject'"))
+	}
+	var res model.OTDecision
+	if err := o.t.post(ctx, method, p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Cancel withdraws a pending OT request.
+func (o *OvertimeClient) Cancel(ctx context.Context, requestID string) (*model.OTDecision, error) {
+	p := newParams().required("request_id", requestID)
+	var res model.OTDecision
+	if err := o.t.post(ctx, "hr_core_ext.api.overtime.cancel_ot_request", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// hours sets key to a number of hours worked in one day.
+func (p *params) hours(key string, v float64) *params {
+	if v <= 0 || v > 24 {
+		p.fail(invalid("%s must be more than 0 and at most 24", key))
+		return p
+	}
+	p.values[key] = strconv.FormatFloat(v, 'f', 2, 64)
+	return p
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Page selects one page of a list method's rows.
+type Page struct {
+	Limit  int
+	Offset int
+	// Sort is a field the method can be sorted by, prefixed with "-" for descending.
+	// Empty keeps the method's own order.
+	Sort string
+	// Employees, if non-nil, restricts rows to these employees. An empty slice matches none.
+	Employees []string
+}
+
+// Paged is one page of rows and the number of rows across all pages.
+type Paged[T any] struct {
+	Rows  []T `json:"rows"`
+	Total int `json:"total"`
+}
+
+// page sets the paging arguments the hr_core_ext list methods share, asking for the
+// {"rows", "total"} shape. Sort must name one of sortable.
+func (p *params) page(pg Page, sortable []string) *params {
+	if pg.Limit < 1 {
+		p.fail(invalid("limit must be at least 1"))
+	}
+	if pg.Offset < 0 {
+		p.fail(invalid("offset must not be negative"))
+	}
+	p.set("limit_page_length", strconv.Itoa(pg.Limit)).
+		set("limit_start", strconv.Itoa(pg.Offset)).
+		set("with_total", "1")
+
+	if pg.Sort != "" {
+		field, dir := pg.Sort, "asc"
+		if rest, ok := strings.CutPrefix(pg.Sort, "-"); ok {
+			field, dir = rest, "desc"
+		}
+		if !slices.Contains(sortable, field) {
+			p.fail(invalid("cannot sort by %s; sort must be one of %q", field, sortable))
+		} else {
+			p.set("order_by", field+" "+dir)
+		}
+	}
+	if pg.Employees != nil {
+		ids, _ := json.Marshal(pg.Employees)
+		p.set("employee_ids", string(ids))
+	}
+	return p
+}
+
+// getPage calls a list method for one page and decodes its rows into T.
+func getPage[T any](ctx context.Context, t *TenantClient, method string, p *params, pg Page, sortable []string) (*Paged[T], error) {
+	p.page(pg, sortable)
+	var res Paged[T]
+	if err := t.get(ctx, method, p, &res); err != nil {
+		return nil, err
+	}
+	if res.Rows == nil {
+		res.Rows = []T{}
+	}
+	return &res, nil
+}
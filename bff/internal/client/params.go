@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ValidationError is returned by the typed domain clients when a request is rejected
+// before it is sent to Frappe. Message is safe to show to the caller.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func invalid(format string, args ...any) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// params builds the string form arguments of a Frappe method call, checking each value
+// as it is added. The first problem is kept and reported by err.
+type params struct {
+	values map[string]string
+	bad    error
+}
+
+func newParams() *params {
+	return &params{values: map[string]string{}}
+}
+
+func (p *params) fail(err error) {
+	if p.bad == nil {
+		p.bad = err
+	}
+}
+
+// required sets key, failing if v is empty.
+func (p *params) required(key, v string) *params {
+	if v == "" {
+		p.fail(invalid("%s is required", key))
+		return p
+	}
+	p.values[key] = v
+	return p
+}
+
+// optional sets key only if v is non-empty.
+func (p *params) optional(key, v string) *params {
+	if v != "" {
+		p.values[key] = v
+	}
+	return p
+}
+
+// date sets key to a YYYY-MM-DD date, failing on any other format.
+// An empty v is skipped unless required is set.
+func (p *params) date(key, v string, required bool) *params {
+	if v == "" {
+		if required {
+			p.fail(invalid("%s is required", key))
+		}
+		return p
+	}
+	if _, err := time.Parse(time.DateOnly, v); err != nil {
+		p.fail(invalid("%s must be a date in YYYY-MM-DD format", key))
+		return p
+	}
+	p.values[key] = v
+	return p
+}
+
+// clock sets key to a HH:MM or HH:MM:SS time of day.
+func (p *params) clock(key, v string, required bool) *params {
+	if v == "" {
+		if required {
+			p.fail(invalid("%s is required", key))
+		}
+		return p
+	}
+	if _, err := time.Parse(time.TimeOnly, v); err != nil {
+		if _, err := time.Parse("15:04", v); err != nil {
+			p.fail(invalid("%s must be a time in HH:MM format", key))
+			return p
+		}
+	}
+	p.values[key] = v
+	return p
+}
+
+// amount sets key to a non-negative money amount with two decimals.
+func (p *params) amount(key string, v float64) *params {
+	if v < 0 {
+		p.fail(invalid("%s must not be negative", key))
+		return p
+	}
+	p.values[key] = strconv.FormatFloat(v, 'f', 2, 64)
+	return p
+}
+
+// count sets key to a non-negative integer.
+func (p *params) count(key string, v int) *params {
+	if v < 0 {
+		p.fail(invalid("%s must not be negative", key))
+		return p
+	}
+	p.values[key] = strconv.Itoa(v)
+	return p
+}
+
+// percent sets key to a percentage between 0 and 100 with two decimals.
+func (p *params) percent(key string, v float64) *params {
+	if v < 0 || v > 100 {
+		p.fail(invalid("%s must be between 0 and 100", key))
+		return p
+	}
+	p.values[key] = strconv.FormatFloat(v, 'f', 2, 64)
+	return p
+}
+
+// period sets month and year, failing unless month is 1-12 and year is 2000 or later.
+func (p *params) period(month, year int) *params {
+	return p.month(month).year(year)
+}
+
+func (p *params) month(month int) *params {
+	if month < 1 || month > 12 {
+		p.fail(invalid("month must be between 1 and 12"))
+	}
+	return p.set("month", strconv.Itoa(month))
+}
+
+func (p *params) year(year int) *params {
+	if year < 2000 || year > 9999 {
+		p.fail(invalid("year must be between 2000 and 9999"))
+	}
+	return p.set("year", strconv.Itoa(year))
+}
+
+// oneOf sets key, failing unless v is one of allowed.
+func (p *params) oneOf(key, v string, allowed ...string) *params {
+	for _, a := range allowed {
+		if v == a {
+			p.values[key] = v
+			return p
+		}
+	}
+	p.fail(invalid("%s must be one of %q", key, allowed))
+	return p
+}
+
+func (p *params) set(key, v string) *params {
+	p.values[key] = v
+	return p
+}
+
+// dateRange fails if both dates are set and from is after to.
+func (p *params) dateRange(fromKey, toKey string) *params {
+	from, to := p.values[fromKey], p.values[toKey]
+	if from != "" && to != "" && from > to {
+		p.fail(invalid("%s must not be after %s", fromKey, toKey))
+	}
+	return p
+}
+
+func (p *params) err() error {
+	return p.bad
+}
+
+// get calls a read-only method and decodes its result into out.
+func (t *TenantClient) get(ctx context.Context, method string, p *params, out any) error {
+	if err := p.err(); err != nil {
+		return err
+	}
+	data, err := t.CallMethod(ctx, method, p.values)
+	if err != nil {
+		return err
+	}
+	return decode(method, data, out)
+}
+
+// post calls a method that changes data and decodes its result into out.
+func (t *TenantClient) post(ctx context.Context, method string, p *params, out any) error {
+	if err := p.err(); err != nil {
+		return err
+	}
+	data, err := t.CallMethodPost(ctx, method, p.values)
+	if err != nil {
+		return err
+	}
+	return decode(method, data, out)
+}
+
+func decode(method string, data json.RawMessage, out any) error {
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding %s: %w", method, err)
+	}
+	return nil
+}
+
+// ParsePeriod parses month and year query values. Empty values are returned as zero.
+func ParsePeriod(month, year string) (int, int, error) {
+	m, err := atoiOrZero("month", month)
+	if err != nil {
+		return 0, 0, err
+	}
+	y, err := atoiOrZero("year", year)
+	if err != nil {
+		return 0, 0, err
+	}
+	return m, y, nil
+}
+
+func atoiOrZero(key, v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, invalid("%s must be a number", key)
+	}
+	return n, nil
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+
+	"hr-platform/bff/internal/model"
+)
+
+// PayrollClient calls the hr_core_ext.api.payroll methods for one tenant.
+type PayrollClient struct {
+	t *TenantClient
+}
+
+// Payroll returns the tenant's payroll client.
+func (t *TenantClient) Payroll() *PayrollClient {
+	return &PayrollClient{t: t}
+}
+
+// SlipFilter narrows Slips. Month is only applied together with Year; zero means any.
+type SlipFilter struct {
+	EmployeeID string
+	Year       int
+	Month      int
+}
+
+// SlipSort lists the fields SlipPage can sort by.
+var SlipSort = []string{"employee_name", "start_date", "posting_date", "gross_pay", "net_pay"}
+
+func (f SlipFilter) params() *params {
+	p := newParams().optional("employee_id", f.EmployeeID)
+	switch {
+	case f.Month != 0:
+		p.period(f.Month, f.Year)
+	case f.Year != 0:
+		p.year(f.Year)
+	}
+	return p
+}
+
+// Slips returns salary slips, most recent period first.
+func (pc *PayrollClient) Slips(ctx context.Context, f SlipFilter) ([]model.SalarySlip, error) {
+	slips := []model.SalarySlip{}
+	if err := pc.t.get(ctx, "hr_core_ext.api.payroll.get_salary_slips", f.params(), &slips); err != nil {
+		return nil, err
+	}
+	return slips, nil
+}
+
+// SlipPage returns one page of salary slips, most recent period first unless pg sorts them.
+func (pc *PayrollClient) SlipPage(ctx context.Context, f SlipFilter, pg Page) (*Paged[model.SalarySlip], error) {
+	return getPage[model.SalarySlip](ctx, pc.t, "hr_core_ext.api.payroll.get_salary_slips", f.params(), pg, SlipSort)
+}
+
+// Slip returns a salary slip with its earnings and deductions. A slip from another
+// company is reported as ErrCrossTenant.
+func (pc *PayrollClient) Slip(ctx context.Context, slipID string) (*model.SalarySlip, error) {
+	p := newParams().required("slip_id", slipID)
+	var slip model.SalarySlip
+	if err := pc.t.get(ctx, "hr_core_ext.api.payroll.get_salary_slip_detail", p, &slip); err != nil {
+		return nil, notFoundAsCrossTenant(err)
+	}
+	if slip.Company != pc.t.Company {
+		return nil, ErrCrossTenant
+	}
+	return &slip, nil
+}
+
+// SetupEmployee assigns the employee the standard salary structure with these amounts.
+func (pc *PayrollClient) SetupEmployee(ctx context.Context, employeeID string, req model.SetupEmployeePayrollRequest) (*model.PayrollSetup, error) {
+	p := newParams().
+		required("employee_id", employeeID).
+		amount("base_salary", req.BaseSalary).
+		amount("housing", req.Housing).
+		amount("transport", req.Transport)
+	if req.BaseSalary <= 0 {
+		p.fail(invalid("base_salary is required and must be positive"))
+	}
+	var res model.PayrollSetup
+	if err := pc.t.post(ctx, "hr_core_ext.api.payroll.setup_employee_payroll", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Process creates draft salary slips for every assigned, active employee for the month.
+func (pc *PayrollClient) Process(ctx context.Context, month, year int) (*model.PayrollRun, error) {
+	p := newParams().period(month, year)
+	var res model.PayrollRun
+	if err := pc.t.post(ctx, "hr_core_ext.api.payroll.process_payroll", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Submit submits every draft salary slip of the month.
+func (pc *PayrollClient) Submit(ctx context.Context, month, year int) (*model.PayrollSubmission, error) {
+	p := newParams().period(month, year)
+	var res model.PayrollSubmission
+	if err := pc.t.post(ctx, "hr_core_ext.api.payroll.submit_payroll", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GenerateSlip creates a draft salary slip for one employee and month.
+func (pc *PayrollClient) GenerateSlip(ctx context.Context, employeeID string, month, year int) (*model.SalarySlip, error) {
+	p := newParams().required("employee_id", employeeID).period(month, year)
+	var slip model.SalarySlip
+	if err := pc.t.post(ctx, "hr_core_ext.api.payroll.generate_salary_slip", p, &slip); err != nil {
+		return nil, err
+	}
+	return &slip, nil
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+
+	"hr-platform/bff/internal/model"
+)
+
+// ProvidentFundClient calls the hr_core_ext.api.provident_fund methods for one tenant.
+type ProvidentFundClient struct {
+	t *TenantClient
+}
+
+// ProvidentFund returns the tenant's provident fund client.
+func (t *TenantClient) ProvidentFund() *ProvidentFundClient {
+	return &ProvidentFundClient{t: t}
+}
+
+// Config returns the contribution rates the company allows and enrolls employees at.
+func (pf *ProvidentFundClient) Config(ctx context.Context) (*model.PVDConfig, error) {
+	var res model.PVDConfig
+	if err := pf.t.get(ctx, MethodPVDConfig, newParams(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateConfig changes the rates that are set in req and returns the result.
+func (pf *ProvidentFundClient) UpdateConfig(ctx context.Context, req model.PVDConfigRequest) (*model.PVDConfig, error) {
+	p := newParams()
+	rates := []struct {
+		key string
+		v   *float64
+	}{
+		{"min_rate", req.MinRate},
+		{"max_rate", req.MaxRate},
+		{"default_employee_rate", req.DefaultEmployeeRate},
+		{"default_employer_rate", req.DefaultEmployerRate},
+	}
+	for _, r := range rates {
+		if r.v != nil {
+			p.percent(r.key, *r.v)
+		}
+	}
+	var res model.PVDConfig
+	if err := pf.t.post(ctx, "hr_core_ext.api.provident_fund.update_pvd_config", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Employee returns whether the employee is enrolled and at what rates.
+func (pf *ProvidentFundClient) Employee(ctx context.Context, employeeID string) (*model.EmployeePVD, error) {
+	var res model.EmployeePVD
+	if err := pf.t.get(ctx, "hr_core_ext.api.provident_fund.get_employee_pvd", employeeParams(employeeID), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Enroll enrolls the employee from today. Rates that are not set in req are the
+// company's defaults; Frappe checks them against its minimum and maximum.
+func (pf *ProvidentFundClient) Enroll(ctx context.Context, employeeID string, req model.EnrollPVDRequest) (*model.EmployeePVD, error) {
+	return pf.setRates(ctx, "hr_core_ext.api.provident_fund.enroll_employee_pvd", employeeID, req)
+}
+
+// Update changes the rates that are set in req.
+func (pf *ProvidentFundClient) Update(ctx context.Context, employeeID string, req model.EnrollPVDRequest) (*model.EmployeePVD, error) {
+	return pf.setRates(ctx, "hr_core_ext.api.provident_fund.update_employee_pvd", employeeID, req)
+}
+
+func (pf *ProvidentFundClient) setRates(ctx context.Context, method, employeeID string, req model.EnrollPVDRequest) (*model.EmployeePVD, error) {
+	p := employeeParams(employeeID)
+	if req.EmployeeRate != nil {
+		p.percent("employee_rate", *req.EmployeeRate)
+	}
+	if req.EmployerRate != nil {
+		p.percent("employer_rate", *req.EmployerRate)
+	}
+	var res model.EmployeePVD
+	if err := pf.t.post(ctx, method, p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Unenroll takes the employee out of the fund and zeroes their rates.
+func (pf *ProvidentFundClient) Unenroll(ctx context.Context, employeeID string) (*model.EmployeePVD, error) {
+	var res model.EmployeePVD
+	if err := pf.t.post(ctx, "hr_core_ext.api.provident_fund.unenroll_employee_pvd", employeeParams(employeeID), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Report returns each enrolled employee's contributions for a month.
+func (pf *ProvidentFundClient) Report(ctx context.Context, month, year int) (*model.PVDReport, error) {
+	p := newParams().period(month, year)
+	var res model.PVDReport
+	if err := pf.t.get(ctx, "hr_core_ext.api.provident_fund.get_pvd_report", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"hr-platform/bff/internal/model"
+)
+
+// Frappe doctypes that back the BFF's request records.
+const (
+	DoctypeLeaveApplication  = "Leave Application"
+	DoctypeAttendanceRequest = "Attendance Request"
+	DoctypeShiftRequest      = "Shift Request"
+	DoctypeOvertimeRequest   = "Additional Salary"
+)
+
+// Doctypes RecordOwner also resolves, for records that are not requests. A Department
+// belongs to a company only, so its owner has no employee.
+const (
+	DoctypeShiftAssignment = "Shift Assignment"
+	DoctypeDepartment      = "Department"
+)
+
+// ReportingLines returns the reports_to / leave_approver links of every active employee in the tenant.
+func (t *TenantClient) ReportingLines(ctx context.Context) ([]model.ReportingLine, error) {
+	data, err := t.CallMethod(ctx, "hr_core_ext.api.employee.get_reporting_lines", map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []model.ReportingLine
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return nil, fmt.Errorf("decoding reporting lines: %w", err)
+	}
+	return lines, nil
+}
+
+// Reports returns the set of employees reporting directly or indirectly to managerID.
+// The manager is not included in the set.
+func (t *TenantClient) Reports(ctx context.Context, managerID string) (map[string]bool, error) {
+	lines, err := t.ReportingLines(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ResolveReports(lines, managerID), nil
+}
+
+// ResolveReports walks the reporting graph down from managerID. An employee is a direct
+// report if their reports_to is the manager or their leave_approver is the manager's user.
+func ResolveReports(lines []model.ReportingLine, managerID string) map[string]bool {
+	children := make(map[string][]string)
+	for _, l := range lines {
+		if l.ReportsTo != "" {
+			children[l.ReportsTo] = append(children[l.ReportsTo], l.EmployeeID)
+		}
+	}
+	userToEmployee := make(map[string]string)
+	for _, l := range lines {
+		if l.UserID != "" {
+			userToEmployee[l.UserID] = l.EmployeeID
+		}
+	}
+	for _, l := range lines {
+		if approver, ok := userToEmployee[l.LeaveApprover]; ok && approver != l.ReportsTo {
+			children[approver] = append(children[approver], l.EmployeeID)
+		}
+	}
+
+	reports := make(map[string]bool)
+	queue := []string{managerID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range children[current] {
+			if child == managerID || reports[child] {
+				continue // guards against cycles in reports_to
+			}
+			reports[child] = true
+			queue = append(queue, child)
+		}
+	}
+	return reports
+}
+
+// RecordOwner returns the owner of a record, or ErrCrossTenant if it belongs to another company.
+func (t *TenantClient) RecordOwner(ctx context.Context, doctype, name string) (*model.RecordOwner, error) {
+	data, err := t.FrappeClient.CallMethod(ctx, "hr_core_ext.api.access.get_record_owner", map[string]string{
+		"doctype": doctype,
+		"name":    name,
+	})
+	if err != nil {
+		return nil, notFoundAsCrossTenant(err)
+	}
+
+	var owner model.RecordOwner
+	if err := json.Unmarshal(data, &owner); err != nil {
+		return nil, fmt.Errorf("decoding record owner: %w", err)
+	}
+	if owner.Company != t.Company {
+		return nil, ErrCrossTenant
+	}
+	return &owner, nil
+}
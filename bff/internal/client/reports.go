@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+
+	"hr-platform/bff/internal/model"
+)
+
+// ReportClient calls the hr_core_ext.api.reports methods for one tenant.
+type ReportClient struct {
+	t *TenantClient
+}
+
+// Report returns the tenant's report client.
+func (t *TenantClient) Report() *ReportClient {
+	return &ReportClient{t: t}
+}
+
+// EmployeeSummary returns the company's headcount by department and this year's turnover.
+func (rc *ReportClient) EmployeeSummary(ctx context.Context) (*model.EmployeeSummaryReport, error) {
+	var res model.EmployeeSummaryReport
+	if err := rc.t.get(ctx, "hr_core_ext.api.reports.get_employee_summary", newParams(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Attendance returns each employee's submitted attendance for a month.
+func (rc *ReportClient) Attendance(ctx context.Context, month, year int) (*model.AttendanceReport, error) {
+	p := newParams().period(month, year)
+	var res model.AttendanceReport
+	if err := rc.t.get(ctx, "hr_core_ext.api.reports.get_attendance_report", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Leave returns the approved leave of a year by leave type and by employee.
+func (rc *ReportClient) Leave(ctx context.Context, year int) (*model.LeaveReport, error) {
+	p := newParams().year(year)
+	var res model.LeaveReport
+	if err := rc.t.get(ctx, "hr_core_ext.api.reports.get_leave_report", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Payroll totals the salary slips of a month, or of the whole year if month is 0.
+func (rc *ReportClient) Payroll(ctx context.Context, month, year int) (*model.PayrollReport, error) {
+	p := newParams().year(year)
+	if month != 0 {
+		p.month(month)
+	}
+	var res model.PayrollReport
+	if err := rc.t.get(ctx, "hr_core_ext.api.reports.get_payroll_report", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Tax totals the income and withholding of each month of year.
+func (rc *ReportClient) Tax(ctx context.Context, year int) (*model.TaxYearReport, error) {
+	p := newParams().year(year)
+	var res model.TaxYearReport
+	if err := rc.t.get(ctx, "hr_core_ext.api.reports.get_tax_report", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// TaxMonth returns the PND1 return of one month.
+func (rc *ReportClient) TaxMonth(ctx context.Context, month, year int) (*model.PND1Report, error) {
+	p := newParams().period(month, year)
+	var res model.PND1Report
+	if err := rc.t.get(ctx, "hr_core_ext.api.reports.get_tax_report", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Export returns a report as a header row and data rows for a spreadsheet. A zero
+// month or year is Frappe's default of the current one.
+func (rc *ReportClient) Export(ctx context.Context, reportType string, month, year int) (*model.ReportExport, error) {
+	p := newParams().oneOf("report_type", reportType, "employee", "attendance", "leave", "payroll")
+	if year != 0 {
+		p.year(year)
+	}
+	if month != 0 {
+		p.month(month)
+	}
+	var res model.ReportExport
+	if err := rc.t.get(ctx, "hr_core_ext.api.reports.export_report_csv", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
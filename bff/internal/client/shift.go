@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+
+	"hr-platform/bff/internal/model"
+)
+
+// ShiftClient calls the hr_core_ext.api.shift methods for one tenant.
+type ShiftClient struct {
+	t *TenantClient
+}
+
+// Shift returns the tenant's shift client.
+func (t *TenantClient) Shift() *ShiftClient {
+	return &ShiftClient{t: t}
+}
+
+// ShiftAssignmentFilter narrows Assignments. Date keeps assignments active on that day.
+type ShiftAssignmentFilter struct {
+	EmployeeID string
+	ShiftType  string
+	Date       string
+}
+
+// ShiftRequestFilter narrows Requests.
+type ShiftRequestFilter struct {
+	EmployeeID string
+	Status     string
+}
+
+// Types returns the shift types shared by every company and the tenant's own, ordered by name.
+func (s *ShiftClient) Types(ctx context.Context) ([]model.ShiftType, error) {
+	types := []model.ShiftType{}
+	if err := s.t.get(ctx, MethodShiftTypes, newParams(), &types); err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+// CreateType creates a shift type. Zero grace periods fall back to Frappe's default of 15 minutes.
+func (s *ShiftClient) CreateType(ctx context.Context, req model.CreateShiftTypeRequest) (*model.ShiftTypeResult, error) {
+	p := newParams().
+		required("name", req.Name).
+		clock("start_time", req.StartTime, true).
+		clock("end_time", req.EndTime, true).
+		optional("holiday_list", req.HolidayList)
+	if req.LateEntryGracePeriod != 0 {
+		p.count("late_entry_grace_period", req.LateEntryGracePeriod)
+	}
+	if req.EarlyExitGracePeriod != 0 {
+		p.count("early_exit_grace_period", req.EarlyExitGracePeriod)
+	}
+	var res model.ShiftTypeResult
+	if err := s.t.post(ctx, "hr_core_ext.api.shift.create_shift_type", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateType changes the fields of a shift type that are set in req. Only the tenant's
+// own shift types can be changed; the shared ones are refused.
+func (s *ShiftClient) UpdateType(ctx context.Context, name string, req model.UpdateShiftTypeRequest) (*model.ShiftTypeResult, error) {
+	p := newParams().required("shift_type_name", name)
+	if req.StartTime != nil {
+		p.clock("start_time", *req.StartTime, true)
+	}
+	if req.EndTime != nil {
+		p.clock("end_time", *req.EndTime, true)
+	}
+	if req.LateEntryGracePeriod != nil {
+		p.count("late_entry_grace_period", *req.LateEntryGracePeriod)
+	}
+	if req.EarlyExitGracePeriod != nil {
+		p.count("early_exit_grace_period", *req.EarlyExitGracePeriod)
+	}
+	var res model.ShiftTypeResult
+	if err := s.t.post(ctx, "hr_core_ext.api.shift.update_shift_type", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ShiftAssignmentSort lists the fields AssignmentPage can sort by.
+var ShiftAssignmentSort = []string{"employee_name", "shift_type", "start_date", "end_date"}
+
+func (f ShiftAssignmentFilter) params() *params {
+	return newParams().
+		optional("employee_id", f.EmployeeID).
+		optional("shift_type", f.ShiftType).
+		date("date", f.Date, false)
+}
+
+// Assignments returns submitted shift assignments, most recent start first.
+func (s *ShiftClient) Assignments(ctx context.Context, f ShiftAssignmentFilter) ([]model.ShiftAssignment, error) {
+	assignments := []model.ShiftAssignment{}
+	if err := s.t.get(ctx, "hr_core_ext.api.shift.get_shift_assignments", f.params(), &assignments); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// AssignmentPage returns one page of submitted shift assignments, most recent start
+// first unless pg sorts them.
+func (s *ShiftClient) AssignmentPage(ctx context.Context, f ShiftAssignmentFilter, pg Page) (*Paged[model.ShiftAssignment], error) {
+	return getPage[model.ShiftAssignment](ctx, s.t, "hr_core_ext.api.shift.get_shift_assignments", f.params(), pg, ShiftAssignmentSort)
+}
+
+// Assign assigns the employee to a shift type. An empty EndDate leaves it open-ended.
+func (s *ShiftClient) Assign(ctx context.Context, req model.AssignShiftRequest) (*model.ShiftAssignment, error) {
+	p := newParams().
+		required("employee_id", req.EmployeeID).
+		required("shift_type", req.ShiftType).
+		date("start_date", req.StartDate, true).
+		date("end_date", req.EndDate, false).
+		dateRange("start_date", "end_date")
+	var res model.ShiftAssignment
+	if err := s.t.post(ctx, "hr_core_ext.api.shift.assign_shift", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Unassign cancels a shift assignment.
+func (s *ShiftClient) Unassign(ctx context.Context, assignmentID string) (*model.ShiftAssignmentResult, error) {
+	p := newParams().required("assignment_id", assignmentID)
+	var res model.ShiftAssignmentResult
+	if err := s.t.post(ctx, "hr_core_ext.api.shift.unassign_shift", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ShiftRequestSort lists the fields RequestPage can sort by.
+var ShiftRequestSort = []string{"employee_name", "shift_type", "from_date", "to_date", "status"}
+
+func (f ShiftRequestFilter) params() *params {
+	return newParams().optional("employee_id", f.EmployeeID).optional("status", f.Status)
+}
+
+// Requests returns shift change requests, newest first.
+func (s *ShiftClient) Requests(ctx context.Context, f ShiftRequestFilter) ([]model.ShiftRequest, error) {
+	requests := []model.ShiftRequest{}
+	if err := s.t.get(ctx, "hr_core_ext.api.shift.get_shift_requests", f.params(), &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// RequestPage returns one page of shift change requests, newest first unless pg sorts them.
+func (s *ShiftClient) RequestPage(ctx context.Context, f ShiftRequestFilter, pg Page) (*Paged[model.ShiftRequest], error) {
+	return getPage[model.ShiftRequest](ctx, s.t, "hr_core_ext.api.shift.get_shift_requests", f.params(), pg, ShiftRequestSort)
+}
+
+// CreateRequest asks for the employee to work a different shift type between two dates.
+func (s *ShiftClient) CreateRequest(ctx context.Context, employeeID string, req model.CreateShiftRequestBody) (*model.ShiftRequestResult, error) {
+	p := newParams().
+		required("employee_id", employeeID).
+		required("shift_type", req.ShiftType).
+		date("from_date", req.FromDate, true).
+		date("to_date", req.ToDate, true).
+		dateRange("from_date", "to_date")
+	var res model.ShiftRequestResult
+	if err := s.t.post(ctx, "hr_core_ext.api.shift.create_shift_request", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// DecideRequest approves or rejects a shift request. action is "approve" or "reject".
+func (s *ShiftClient) DecideRequest(ctx context.Context, requestID, action string) (*model.ShiftRequestResult, error) {
+	p := newParams().required("request_id", requestID).oneOf("action", action, "approve", "reject")
+	var res model.ShiftRequestResult
+	if err := s.t.post(ctx, "hr_core_ext.api.shift.approve_shift_request", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Current returns the shift the employee is assigned to today.
+func (s *ShiftClient) Current(ctx context.Context, employeeID string) (*model.CurrentShift, error) {
+	p := newParams().required("employee_id", employeeID)
+	var res model.CurrentShift
+	if err := s.t.get(ctx, "hr_core_ext.api.shift.get_employee_current_shift", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ProcessAutoAttendance turns the check-ins of date into attendance records for every
+// assigned employee. An empty date means yesterday.
+func (s *ShiftClient) ProcessAutoAttendance(ctx context.Context, date string) (*model.AutoAttendanceRun, error) {
+	p := newParams().date("date", date, false)
+	var res model.AutoAttendanceRun
+	if err := s.t.post(ctx, "hr_core_ext.api.shift.process_auto_attendance", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+
+	"hr-platform/bff/internal/model"
+)
+
+// SocialSecurityClient calls the hr_core_ext.api.social_security methods for one tenant.
+type SocialSecurityClient struct {
+	t *TenantClient
+}
+
+// SocialSecurity returns the tenant's social security client.
+func (t *TenantClient) SocialSecurity() *SocialSecurityClient {
+	return &SocialSecurityClient{t: t}
+}
+
+// Config returns the contribution rate and the salary and contribution caps.
+func (s *SocialSecurityClient) Config(ctx context.Context) (*model.SSOConfig, error) {
+	var res model.SSOConfig
+	if err := s.t.get(ctx, MethodSSOConfig, newParams(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateConfig changes the settings that are set in req and returns the result.
+func (s *SocialSecurityClient) UpdateConfig(ctx context.Context, req model.SSOConfigRequest) (*model.SSOConfig, error) {
+	p := newParams()
+	if req.Rate != nil {
+		p.percent("rate", *req.Rate)
+	}
+	if req.MaxSalary != nil {
+		p.amount("max_salary", *req.MaxSalary)
+	}
+	if req.MaxContribution != nil {
+		p.amount("max_contribution", *req.MaxContribution)
+	}
+	var res model.SSOConfig
+	if err := s.t.post(ctx, "hr_core_ext.api.social_security.update_sso_config", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Report returns each active employee's contributions for a month.
+func (s *SocialSecurityClient) Report(ctx context.Context, month, year int) (*model.SSOReport, error) {
+	p := newParams().period(month, year)
+	var res model.SSOReport
+	if err := s.t.get(ctx, "hr_core_ext.api.social_security.get_sso_report", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Number returns the employee's social security number, "" if it is not set.
+func (s *SocialSecurityClient) Number(ctx context.Context, employeeID string) (*model.EmployeeSSO, error) {
+	var res model.EmployeeSSO
+	if err := s.t.get(ctx, "hr_core_ext.api.social_security.get_employee_sso_number", employeeParams(employeeID), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateNumber sets the employee's social security number, which is their 13-digit
+// national ID. An empty number clears it.
+func (s *SocialSecurityClient) UpdateNumber(ctx context.Context, employeeID, number string) (*model.EmployeeSSO, error) {
+	p := employeeParams(employeeID)
+	if number != "" && !isTaxID(number) {
+		p.fail(invalid("sso_number must be 13 digits"))
+	}
+	p.set("sso_number", number)
+	var res model.EmployeeSSO
+	if err := s.t.post(ctx, "hr_core_ext.api.social_security.update_employee_sso_number", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+
+	"hr-platform/bff/internal/model"
+)
+
+// TaxClient calls the hr_core_ext.api.tax methods for one tenant.
+type TaxClient struct {
+	t *TenantClient
+}
+
+// Tax returns the tenant's tax client.
+func (t *TenantClient) Tax() *TaxClient {
+	return &TaxClient{t: t}
+}
+
+// Slabs returns the income tax slabs in effect this year.
+func (tc *TaxClient) Slabs(ctx context.Context) (*model.TaxSlabs, error) {
+	var res model.TaxSlabs
+	if err := tc.t.get(ctx, MethodTaxSlabs, newParams(), &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Deductions returns the allowances and deductions the employee claims.
+func (tc *TaxClient) Deductions(ctx context.Context, employeeID string) (*model.TaxDeductions, error) {
+	p := newParams().required("employee_id", employeeID)
+	var res model.TaxDeductions
+	if err := tc.t.get(ctx, "hr_core_ext.api.tax.get_employee_tax_deductions", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UpdateDeductions changes the deductions that are set in req and returns the result.
+func (tc *TaxClient) UpdateDeductions(ctx context.Context, employeeID string, req model.UpdateTaxDeductionsRequest) (*model.TaxDeductions, error) {
+	p := newParams().required("employee_id", employeeID)
+	if req.TaxID != nil {
+		if id := *req.TaxID; id != "" && !isTaxID(id) {
+			p.fail(invalid("tax_id must be 13 digits"))
+		}
+		p.set("tax_id", *req.TaxID)
+	}
+	amounts := []struct {
+		key string
+		v   *float64
+	}{
+		{"personal_allowance", req.PersonalAllowance},
+		{"spouse_allowance", req.SpouseAllowance},
+		{"life_insurance_premium", req.LifeInsurancePremium},
+		{"health_insurance_premium", req.HealthInsurancePremium},
+		{"housing_loan_interest", req.HousingLoanInterest},
+		{"donation_deduction", req.DonationDeduction},
+	}
+	for _, a := range amounts {
+		if a.v != nil {
+			p.amount(a.key, *a.v)
+		}
+	}
+	if req.ChildrenCount != nil {
+		p.count("children_count", *req.ChildrenCount)
+	}
+	var res model.TaxDeductions
+	if err := tc.t.post(ctx, "hr_core_ext.api.tax.update_employee_tax_deductions", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Summary returns the employee's withholding for each month of year.
+func (tc *TaxClient) Summary(ctx context.Context, employeeID string, year int) (*model.TaxSummary, error) {
+	p := newParams().required("employee_id", employeeID).year(year)
+	var res model.TaxSummary
+	if err := tc.t.get(ctx, "hr_core_ext.api.tax.get_employee_tax_summary", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// PND1 returns the monthly withholding return for every active employee.
+func (tc *TaxClient) PND1(ctx context.Context, month, year int) (*model.PND1Report, error) {
+	p := newParams().period(month, year)
+	var res model.PND1Report
+	if err := tc.t.get(ctx, "hr_core_ext.api.tax.get_pnd1_data", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// WithholdingCert returns the data for the employee's annual withholding certificate.
+func (tc *TaxClient) WithholdingCert(ctx context.Context, employeeID string, year int) (*model.WithholdingCert, error) {
+	p := newParams().required("employee_id", employeeID).year(year)
+	var res model.WithholdingCert
+	if err := tc.t.get(ctx, "hr_core_ext.api.tax.get_withholding_cert_data", p, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// isTaxID reports whether id is a 13-digit Thai tax identification number,
+// optionally grouped with dashes or spaces.
+func isTaxID(id string) bool {
+	digits := 0
+	for _, r := range id {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '-' || r == ' ':
+		default:
+			return false
+		}
+	}
+	return digits == 13
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrCrossTenant is returned when a Frappe record belongs to a different company than the caller.
+var ErrCrossTenant = errors.New("record belongs to another company")
+
+// TenantClient scopes every Frappe call to a single company.
+// The company param is always overwritten so callers cannot widen their scope.
+type TenantClient struct {
+	*FrappeClient
+	Company string
+}
+
+// ForCompany returns a client that injects the given Frappe company into every call.
+func (c *FrappeClient) ForCompany(company string) *TenantClient {
+	return &TenantClient{FrappeClient: c, Company: company}
+}
+
+// CallMethod calls a whitelisted Frappe method with the tenant's company added.
+// Methods the cache has a TTL for are served from it.
+func (t *TenantClient) CallMethod(ctx context.Context, method string, params map[string]string) (json.RawMessage, error) {
+	if c := t.opts.Cache; c.Caches(method) && t.Company != "" {
+		return c.Fetch(ctx, t.Company, method, params, func(ctx context.Context) ([]byte, error) {
+			return t.FrappeClient.CallMethod(ctx, method, t.scope(params))
+		})
+	}
+	return t.FrappeClient.CallMethod(ctx, method, t.scope(params))
+}
+
+// CallMethodPost calls a whitelisted Frappe method via POST with the tenant's company added.
+func (t *TenantClient) CallMethodPost(ctx context.Context, method string, params map[string]string) (json.RawMessage, error) {
+	return t.FrappeClient.CallMethodPost(ctx, method, t.scope(params))
+}
+
+// VerifyEmployee returns ErrCrossTenant if the employee is not part of the tenant's company.
+// A missing employee is reported the same way so IDs from other tenants cannot be probed.
+func (t *TenantClient) VerifyEmployee(ctx context.Context, employeeID string) error {
+	data, err := t.FrappeClient.CallMethod(ctx, "hr_core_ext.api.employee.get_employee", map[string]string{
+		"employee_id": employeeID,
+	})
+	if err != nil {
+		return notFoundAsCrossTenant(err)
+	}
+
+	var emp struct {
+		Company string `json:"company"`
+	}
+	if err := json.Unmarshal(data, &emp); err != nil {
+		return fmt.Errorf("decoding employee: %w", err)
+	}
+	if emp.Company != t.Company {
+		return ErrCrossTenant
+	}
+	return nil
+}
+
+// VerifyDocument returns ErrCrossTenant if the file is not attached to an employee of the tenant's company.
+// If employeeID is non-empty, the file must also be attached to that employee.
+func (t *TenantClient) VerifyDocument(ctx context.Context, fileName, employeeID string) error {
+	_, err := t.Document(ctx, fileName, employeeID)
+	return err
+}
+
+// EmployeeFile is the File document behind an employee document.
+type EmployeeFile struct {
+	Name     string `json:"name"`
+	Employee string `json:"employee"`
+	Company  string `json:"company"`
+	FileName string `json:"file_name"`
+	FileURL  string `json:"file_url"`
+}
+
+// Document returns the file fileName, checked the way VerifyDocument checks it.
+func (t *TenantClient) Document(ctx context.Context, fileName, employeeID string) (*EmployeeFile, error) {
+	data, err := t.FrappeClient.CallMethod(ctx, "hr_core_ext.api.document.get_document_owner", map[string]string{
+		"file_name": fileName,
+	})
+	if err != nil {
+		return nil, notFoundAsCrossTenant(err)
+	}
+
+	var file EmployeeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decoding document owner: %w", err)
+	}
+	if file.Company != t.Company {
+		return nil, ErrCrossTenant
+	}
+	if employeeID != "" && file.Employee != employeeID {
+		return nil, ErrCrossTenant
+	}
+	return &file, nil
+}
+
+func (t *TenantClient) scope(params map[string]string) map[string]string {
+	scoped := make(map[string]string, len(params)+1)
+	for k, v := range params {
+		scoped[k] = v
+	}
+	if t.Company != "" {
+		scoped["company"] = t.Company
+	}
+	return scoped
+}
+
+func notFoundAsCrossTenant(err error) error {
+	var fe *FrappeError
+	if errors.As(err, &fe) && fe.StatusCode == http.StatusNotFound {
+		return ErrCrossTenant
+	}
+	return err
+}
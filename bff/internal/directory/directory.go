@@ -0,0 +1,208 @@
+// Package directory keeps employees_cache, the BFF's copy of each company's Frappe
+// employees, and searches it. Watch pulls the employees Frappe has changed since the
+// last sync; Refresh and Remove apply single changes as Frappe's webhooks report them.
+// A full sync once a day also drops employees deleted while no webhook got through.
+package directory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"hr-platform/bff/internal/client"
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/repository"
+)
+
+const (
+	// PollInterval is how often Watch looks for companies that are due a sync.
+	PollInterval = time.Minute
+	// FullSyncInterval is how often a company's sync reads every employee.
+	FullSyncInterval = 24 * time.Hour
+	// lease bounds how long a sync may run before another instance may take it over.
+	lease     = 10 * time.Minute
+	pollBatch = 20
+)
+
+// Sort lists the fields Search can sort by.
+var Sort = []string{"employee_id", "employee_name", "department", "designation", "status", "branch", "employment_type", "date_of_joining"}
+
+type Directory struct {
+	cache     *repository.EmployeeCacheRepository
+	companies *repository.CompanyRepository
+	frappe    *client.FrappeClient
+	interval  time.Duration
+}
+
+// NewDirectory returns a directory whose companies are synced every interval.
+func NewDirectory(
+	cache *repository.EmployeeCacheRepository,
+	companies *repository.CompanyRepository,
+	frappe *client.FrappeClient,
+	interval time.Duration,
+) *Directory {
+	return &Directory{cache: cache, companies: companies, frappe: frappe, interval: interval}
+}
+
+// maxAge is how long a row may go unconfirmed before Search reports it stale: a few
+// missed syncs, not one that is merely running late.
+func (d *Directory) maxAge() time.Duration {
+	return 3 * d.interval
+}
+
+// Search returns one page of the company's employees matching q, and the number of matches.
+func (d *Directory) Search(ctx context.Context, companyID string, q model.EmployeeSearch, pg client.Page) ([]model.DirectoryEmployee, int, error) {
+	if field := strings.TrimPrefix(pg.Sort, "-"); pg.Sort != "" && !slices.Contains(Sort, field) {
+		return nil, 0, &client.ValidationError{Message: fmt.Sprintf("cannot sort by %s; sort must be one of %q", field, Sort)}
+	}
+	rows, total, err := d.cache.Search(ctx, companyID, q, pg.Limit, pg.Offset, pg.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+	now := time.Now()
+	for i := range rows {
+		rows[i].Stale = rows[i].StaleSince != nil || now.Sub(rows[i].SyncedAt) > d.maxAge()
+	}
+	return rows, total, nil
+}
+
+// Watch syncs due companies every PollInterval until ctx is done, starting at once so
+// a new deployment fills the cache without waiting.
+func (d *Directory) Watch(ctx context.Context) {
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+	for {
+		ids, err := d.cache.DueSyncs(ctx, pollBatch)
+		if err != nil {
+			log.Printf("directory: listing due syncs failed: %v", err)
+		}
+		for _, id := range ids {
+			if err := d.Sync(ctx, id); err != nil {
+				log.Printf("directory: company %s: %v", id, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync brings the company's cache up to date if its sync is due and no other instance
+// is running it. Progress is kept when a sync fails part way, so the next one resumes.
+func (d *Directory) Sync(ctx context.Context, companyID string) error {
+	state, err := d.cache.ClaimSync(ctx, companyID, lease)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	full := state.FullSyncAt == nil || start.Sub(*state.FullSyncAt) >= FullSyncInterval
+	modified, afterID := state.Watermark, state.WatermarkID
+	if full {
+		modified, afterID = "", ""
+	}
+
+	company, err := d.companies.GetByID(ctx, companyID)
+	if err == nil {
+		err = d.pull(ctx, company, &modified, &afterID, start)
+	}
+	if err == nil {
+		if full {
+			err = d.cache.DeleteUnseen(ctx, companyID, start)
+		} else {
+			err = d.refreshStale(ctx, company)
+		}
+	}
+	if err == nil && !full {
+		err = d.cache.Confirm(ctx, companyID, start)
+	}
+
+	// A full sync that failed has not read past the old watermark
+	if err == nil || !full {
+		state.Watermark, state.WatermarkID = modified, afterID
+	}
+	state.LastError = ""
+	state.NextSyncAt = time.Now().Add(d.interval)
+	if err != nil {
+		state.LastError = err.Error()
+	} else {
+		state.SyncedAt = &start
+		if full {
+			state.FullSyncAt = &start
+		}
+	}
+	if serr := d.cache.SaveSync(context.WithoutCancel(ctx), state); serr != nil {
+		log.Printf("directory: recording sync of company %s: %v", companyID, serr)
+	}
+	return err
+}
+
+// pull reads the employees changed after modified and afterID into the cache in
+// batches, advancing both to the last employee stored.
+func (d *Directory) pull(ctx context.Context, company *model.Company, modified, afterID *string, start time.Time) error {
+	tc := d.frappe.ForCompany(company.FrappeCompanyName)
+	for {
+		batch, err := tc.EmployeesChangedSince(ctx, *modified, *afterID, client.DirectoryBatch)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := d.cache.Upsert(ctx, company.ID, batch, start); err != nil {
+			return err
+		}
+		last := batch[len(batch)-1]
+		*modified, *afterID = last.Modified, last.EmployeeID
+		if len(batch) < client.DirectoryBatch {
+			return nil
+		}
+	}
+}
+
+// refreshStale retries the employees whose webhook refresh failed. An incremental sync
+// does not see them again if it had already read the change the webhook was about.
+func (d *Directory) refreshStale(ctx context.Context, company *model.Company) error {
+	ids, err := d.cache.StaleIDs(ctx, company.ID, pollBatch)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := d.Refresh(ctx, company, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Refresh reads one employee back from Frappe after a webhook reported a change. If
+// that fails the row is flagged stale until a later refresh or sync succeeds.
+func (d *Directory) Refresh(ctx context.Context, company *model.Company, employeeID string) error {
+	e, err := d.frappe.ForCompany(company.FrappeCompanyName).DirectoryEmployee(ctx, employeeID)
+	if err != nil {
+		if serr := d.cache.MarkStale(context.WithoutCancel(ctx), company.ID, employeeID); serr != nil {
+			log.Printf("directory: marking %s of company %s stale: %v", employeeID, company.ID, serr)
+		}
+		return err
+	}
+	if e == nil {
+		return d.cache.Delete(ctx, company.ID, employeeID)
+	}
+	return d.cache.Upsert(ctx, company.ID, []model.DirectoryEmployee{*e}, time.Now())
+}
+
+// Remove drops an employee Frappe reported deleted.
+func (d *Directory) Remove(ctx context.Context, company *model.Company, employeeID string) error {
+	return d.cache.Delete(ctx, company.ID, employeeID)
+}
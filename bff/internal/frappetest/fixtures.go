@@ -0,0 +1,364 @@
+package frappetest
+
+import (
+	"fmt"
+	"sort"
+
+	"hr-platform/bff/internal/client"
+)
+
+// Companies in the default fixtures. Globex exists so tests can check that one tenant
+// cannot reach another's records.
+const (
+	CompanyAcme   = "Acme Co"
+	CompanyGlobex = "Globex Co"
+)
+
+// Employees in the default fixtures. At Acme, Manager and HR report to Admin, Alice
+// and Bob report to Manager, and Carol reports to HR, so she is outside Manager's team.
+// Gus works for Globex.
+const (
+	EmpAdmin   = "HR-EMP-00001"
+	EmpHR      = "HR-EMP-00002"
+	EmpManager = "HR-EMP-00003"
+	EmpAlice   = "HR-EMP-00004"
+	EmpBob     = "HR-EMP-00005"
+	EmpCarol   = "HR-EMP-00006"
+	EmpGus     = "HR-EMP-00101"
+)
+
+// Doctypes of the records the fake keeps besides the request doctypes in client.
+const (
+	DoctypeShiftAssignment = client.DoctypeShiftAssignment
+	DoctypeSalarySlip      = "Salary Slip"
+	DoctypeShiftType       = "Shift Type"
+)
+
+// Employee is an Employee document. UserID is the Frappe user linked to it, which is
+// what another employee's leave_approver points at.
+type Employee struct {
+	ID            string
+	Name          string
+	Company       string
+	Department    string
+	Designation   string
+	Status        string
+	ReportsTo     string
+	LeaveApprover string
+	UserID        string
+	Fields        map[string]any
+}
+
+// Record is any other document: requests, shift assignments, shift types and salary
+// slips. Fields holds the doctype's own columns as Frappe returns them.
+type Record struct {
+	Doctype   string
+	Name      string
+	Employee  string
+	Company   string
+	Status    string
+	DocStatus int
+	Fields    map[string]any
+}
+
+// Document is a private File attached to an employee. The fake serves Content at FileURL.
+type Document struct {
+	Name     string
+	Employee string
+	FileName string
+	FileURL  string
+	Content  []byte
+}
+
+// Department is a Department document.
+type Department struct {
+	Name    string
+	Label   string
+	Company string
+	Parent  string
+}
+
+// AddEmployee adds or replaces an employee.
+func (s *Server) AddEmployee(e Employee) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.putEmployee(e)
+}
+
+// Employee returns a copy of the employee with id.
+func (s *Server) Employee(id string) (Employee, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.employee(id); e != nil {
+		return *e, true
+	}
+	return Employee{}, false
+}
+
+// AddRecord stores r, naming it if Name is empty, and returns its name.
+func (s *Server) AddRecord(r Record) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.putRecord(r).Name
+}
+
+// Record returns a copy of the record.
+func (s *Server) Record(doctype, name string) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r := s.record(doctype, name); r != nil {
+		return *r, true
+	}
+	return Record{}, false
+}
+
+// Records returns copies of every record of doctype, in the order they were added.
+func (s *Server) Records(doctype string) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Record
+	for _, r := range s.records {
+		if r.Doctype == doctype {
+			out = append(out, *r)
+		}
+	}
+	return out
+}
+
+// AddDocument attaches a file to an employee.
+func (s *Server) AddDocument(d Document) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d.FileURL == "" {
+		d.FileURL = s.fileURL(d.FileName)
+	}
+	s.documents = append(s.documents, &d)
+}
+
+// Setting returns a copy of a company's settings document: "sso", "pvd" or "ot".
+func (s *Server) Setting(name, company string) map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return clone(s.setting(name, company))
+}
+
+// setting is the settings document name of company. Like hr_core_ext.api.settings, a
+// company reads the site-wide document until it saves its own copy.
+func (s *Server) setting(name, company string) map[string]any {
+	if m, ok := s.settings[name+"::"+company]; ok && company != "" {
+		return m
+	}
+	return s.settings[name]
+}
+
+func (s *Server) putEmployee(e Employee) {
+	if e.Status == "" {
+		e.Status = "Active"
+	}
+	for i, old := range s.employees {
+		if old.ID == e.ID {
+			s.employees[i] = &e
+			return
+		}
+	}
+	s.employees = append(s.employees, &e)
+}
+
+func (s *Server) employee(id string) *Employee {
+	for _, e := range s.employees {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (s *Server) employeeName(id string) string {
+	if e := s.employee(id); e != nil {
+		return e.Name
+	}
+	return ""
+}
+
+// namePrefixes follow Frappe's naming series for each doctype.
+var namePrefixes = map[string]string{
+	client.DoctypeLeaveApplication:  "HR-LAP-2026-",
+	client.DoctypeAttendanceRequest: "HR-ARQ-2026-",
+	client.DoctypeShiftRequest:      "HR-SHR-2026-",
+	client.DoctypeOvertimeRequest:   "HR-ADS-2026-",
+	DoctypeShiftAssignment:          "HR-SHA-2026-",
+	DoctypeSalarySlip:               "Sal Slip/",
+}
+
+func (s *Server) putRecord(r Record) *Record {
+	if r.Name == "" {
+		s.seq++
+		if r.Doctype == DoctypeSalarySlip {
+			r.Name = fmt.Sprintf("Sal Slip/%s/%05d", r.Employee, s.seq)
+		} else {
+			r.Name = fmt.Sprintf("%s%05d", namePrefixes[r.Doctype], s.seq)
+		}
+	}
+	if r.Fields == nil {
+		r.Fields = map[string]any{}
+	}
+	s.records = append(s.records, &r)
+	return &r
+}
+
+func (s *Server) record(doctype, name string) *Record {
+	for _, r := range s.records {
+		if r.Doctype == doctype && r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// recordsOf returns the records of doctype that belong to company (if set) and employee (if set).
+func (s *Server) recordsOf(doctype, company, employee string) []*Record {
+	var out []*Record
+	for _, r := range s.records {
+		if r.Doctype != doctype {
+			continue
+		}
+		if company != "" && r.Company != company {
+			continue
+		}
+		if employee != "" && r.Employee != employee {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// activeEmployees returns the active employees of company, or of every company if it is empty.
+func (s *Server) activeEmployees(company string) []*Employee {
+	var out []*Employee
+	for _, e := range s.employees {
+		if e.Status == "Active" && (company == "" || e.Company == company) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func clone(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// seed loads the default fixtures.
+func (s *Server) seed() {
+	s.companies = []string{CompanyAcme, CompanyGlobex}
+
+	for _, e := range []Employee{
+		{ID: EmpAdmin, Name: "Somchai Admin", Company: CompanyAcme, Department: "Management - AC", Designation: "CEO", UserID: "admin@acme.test"},
+		{ID: EmpHR, Name: "Hathai HR", Company: CompanyAcme, Department: "Human Resources - AC", Designation: "HR Manager", ReportsTo: EmpAdmin, UserID: "hr@acme.test"},
+		{ID: EmpManager, Name: "Manop Manager", Company: CompanyAcme, Department: "Engineering - AC", Designation: "Engineering Manager", ReportsTo: EmpAdmin, UserID: "manager@acme.test"},
+		{ID: EmpAlice, Name: "Alice Arun", Company: CompanyAcme, Department: "Engineering - AC", Designation: "Engineer", ReportsTo: EmpManager, UserID: "alice@acme.test"},
+		{ID: EmpBob, Name: "Bob Boonmee", Company: CompanyAcme, Department: "Engineering - AC", Designation: "Engineer", ReportsTo: EmpManager},
+		{ID: EmpCarol, Name: "Carol Chai", Company: CompanyAcme, Department: "Human Resources - AC", Designation: "Recruiter", ReportsTo: EmpHR, UserID: "carol@acme.test"},
+		{ID: EmpGus, Name: "Gus Globex", Company: CompanyGlobex, Department: "Operations - GX", Designation: "Operator", UserID: "gus@globex.test"},
+	} {
+		s.putEmployee(e)
+	}
+
+	for _, d := range []Department{
+		{Name: "All Departments", Label: "All Departments"},
+		{Name: "Management - AC", Label: "Management", Company: CompanyAcme, Parent: "All Departments"},
+		{Name: "Human Resources - AC", Label: "Human Resources", Company: CompanyAcme, Parent: "All Departments"},
+		{Name: "Engineering - AC", Label: "Engineering", Company: CompanyAcme, Parent: "All Departments"},
+		{Name: "Legal - AC", Label: "Legal", Company: CompanyAcme, Parent: "All Departments"},
+		{Name: "Operations - GX", Label: "Operations", Company: CompanyGlobex, Parent: "All Departments"},
+	} {
+		d := d
+		s.departments = append(s.departments, &d)
+	}
+
+	// Shift types without a company are shared by every company
+	for _, st := range []struct{ name, company, start, end string }{
+		{"Day Shift", "", "09:00:00", "18:00:00"},
+		{"Night Shift", "", "21:00:00", "06:00:00"},
+		{"Early Shift", CompanyAcme, "06:00:00", "15:00:00"},
+	} {
+		s.putRecord(Record{Doctype: DoctypeShiftType, Name: st.name, Company: st.company, Fields: map[string]any{
+			"start_time": st.start, "end_time": st.end, "holiday_list": "Thailand 2026",
+			"late_entry_grace_period": 15, "early_exit_grace_period": 15, "enable_auto_attendance": 1,
+			"working_hours_threshold_for_half_day": 4.0, "working_hours_threshold_for_absent": 2.0,
+		}})
+	}
+
+	for _, emp := range []string{EmpAlice, EmpCarol, EmpGus} {
+		company := s.employee(emp).Company
+		s.putRecord(Record{Doctype: client.DoctypeLeaveApplication, Employee: emp, Company: company, Status: "Open", Fields: map[string]any{
+			"leave_type": "Annual Leave", "from_date": "2026-04-13", "to_date": "2026-04-15",
+			"total_leave_days": 3.0, "posting_date": "2026-03-20", "description": "Songkran",
+		}})
+		s.putRecord(Record{Doctype: client.DoctypeAttendanceRequest, Employee: emp, Company: company, Status: "Pending", Fields: map[string]any{
+			"from_date": "2026-03-02", "to_date": "2026-03-02", "reason": "Forgot to check in", "half_day": 0,
+		}})
+		s.putRecord(Record{Doctype: client.DoctypeShiftRequest, Employee: emp, Company: company, Status: "Draft", Fields: map[string]any{
+			"shift_type": "Night Shift", "from_date": "2026-04-01", "to_date": "2026-04-07", "approver": "",
+		}})
+		s.putRecord(Record{Doctype: client.DoctypeOvertimeRequest, Employee: emp, Company: company, Status: "Pending", Fields: map[string]any{
+			"ot_date": "2026-03-05", "ot_type": "weekday_ot", "hours": 2.0, "reason": "Release", "approved_by": "", "amount": 0.0,
+		}})
+		s.putRecord(Record{Doctype: DoctypeShiftAssignment, Employee: emp, Company: company, Status: "Active", DocStatus: 1, Fields: map[string]any{
+			"shift_type": "Day Shift", "start_date": "2026-01-01", "end_date": nil,
+		}})
+		s.putRecord(Record{Doctype: DoctypeSalarySlip, Employee: emp, Company: company, Status: "Submitted", DocStatus: 1, Fields: salarySlipFields(2026, 2, 30000)})
+	}
+
+	contract := []byte("%PDF-1.4\n% employment contract\n")
+	s.documents = []*Document{
+		{Name: "file-alice-contract", Employee: EmpAlice, FileName: "contract.pdf", FileURL: "/private/files/contract.pdf", Content: contract},
+		{Name: "file-gus-contract", Employee: EmpGus, FileName: "contract.pdf", FileURL: "/private/files/contract1a2b3c.pdf", Content: contract},
+	}
+
+	s.settings["sso"] = map[string]any{"rate": 5.0, "max_salary": 15000.0, "max_contribution": 750.0}
+	s.settings["pvd"] = map[string]any{"min_rate": 2.0, "max_rate": 15.0, "default_employee_rate": 5.0, "default_employer_rate": 5.0}
+	s.settings["ot"] = map[string]any{
+		"weekday_ot_rate": 1.5, "holiday_work_monthly": 1.0, "holiday_work_daily": 2.0, "holiday_ot_rate": 3.0,
+		"standard_hours_per_day": 8.0, "standard_working_days": 30.0,
+	}
+	s.settings["tax_slabs"] = map[string]any{
+		"name": "Thailand PIT 2026", "effective_from": "2026-01-01",
+		"slabs": []map[string]any{
+			{"from_amount": 0.0, "to_amount": 150000.0, "percent_deduction": 0.0},
+			{"from_amount": 150000.0, "to_amount": 300000.0, "percent_deduction": 5.0},
+			{"from_amount": 300000.0, "to_amount": 500000.0, "percent_deduction": 10.0},
+			{"from_amount": 500000.0, "to_amount": 0.0, "percent_deduction": 15.0},
+		},
+	}
+}
+
+func salarySlipFields(year, month int, base float64) map[string]any {
+	sso := base * 0.05
+	if sso > 750 {
+		sso = 750
+	}
+	return map[string]any{
+		"start_date":      fmt.Sprintf("%d-%02d-01", year, month),
+		"end_date":        fmt.Sprintf("%d-%02d-28", year, month),
+		"posting_date":    fmt.Sprintf("%d-%02d-28", year, month),
+		"gross_pay":       base,
+		"total_deduction": sso,
+		"net_pay":         base - sso,
+		"earnings":        []map[string]any{{"salary_component": "Basic Salary", "amount": base, "formula": nil}},
+		"deductions":      []map[string]any{{"salary_component": "Social Security", "amount": sso, "formula": nil}},
+	}
+}
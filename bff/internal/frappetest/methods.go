@@ -0,0 +1,1738 @@
+package frappetest
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"hr-platform/bff/internal/client"
+)
+
+// methods maps each hr_core_ext.api method to its fake. Reads filter by the company
+// param like the Python API does; writes change the fixtures so later reads see them.
+func (s *Server) methods() map[string]HandlerFunc {
+	return map[string]HandlerFunc{
+		"login": s.login,
+
+		"hr_core_ext.api.access.get_record_owner": s.getRecordOwner,
+
+		"hr_core_ext.api.company.create_company":  s.createCompany,
+		"hr_core_ext.api.company.create_employee": s.createEmployee,
+
+		"hr_core_ext.api.employee.get_employees":            s.getEmployees,
+		"hr_core_ext.api.employee.get_employee":             s.getEmployee,
+		"hr_core_ext.api.employee.get_employee_full":        s.getEmployeeFull,
+		"hr_core_ext.api.employee.update_employee":          s.updateEmployee,
+		"hr_core_ext.api.employee.update_employee_contact":  s.updateEmployeeContact,
+		"hr_core_ext.api.employee.get_reporting_lines":      s.getReportingLines,
+		"hr_core_ext.api.employee.validate_manager":         s.validateManager,
+		"hr_core_ext.api.employee.get_employee_timeline":    s.getEmployeeTimeline,
+		"hr_core_ext.api.compensation.get_salary_structure": s.getSalaryStructure,
+		"hr_core_ext.api.promotion.get_promotions":          s.getPromotions,
+
+		"hr_core_ext.api.document.get_employee_documents":   s.getEmployeeDocuments,
+		"hr_core_ext.api.document.delete_employee_document": s.deleteEmployeeDocument,
+		"hr_core_ext.api.document.get_document_owner":       s.getDocumentOwner,
+		"upload_file": s.uploadFile,
+
+		"hr_core_ext.api.department.get_departments":   s.getDepartments,
+		"hr_core_ext.api.department.get_department":    s.getDepartment,
+		"hr_core_ext.api.department.create_department": s.createDepartment,
+		"hr_core_ext.api.department.update_department": s.updateDepartment,
+		"hr_core_ext.api.department.delete_department": s.deleteDepartment,
+		client.MethodOrgTree:                           s.getOrgTree,
+		client.MethodDepartmentTree:                    s.getDepartmentTree,
+
+		"hr_core_ext.api.leave.get_leave_balance":         s.getLeaveBalance,
+		"hr_core_ext.api.leave.get_leave_allocations":     s.getLeaveAllocations,
+		"hr_core_ext.api.leave.get_leave_applications":    s.getLeaveApplications,
+		"hr_core_ext.api.leave.create_leave_application":  s.createLeaveApplication,
+		"hr_core_ext.api.leave.update_leave_application":  s.updateLeaveApplication,
+		"hr_core_ext.api.leave.approve_leave_application": s.approveLeaveApplication,
+		"hr_core_ext.api.leave.cancel_leave_application":  s.cancelLeaveApplication,
+
+		"hr_core_ext.api.attendance.get_attendance_summary":     s.getAttendanceSummary,
+		"hr_core_ext.api.attendance.get_attendance_detail":      s.getAttendanceDetail,
+		"hr_core_ext.api.attendance.get_attendance_requests":    s.getAttendanceRequests,
+		"hr_core_ext.api.attendance.create_attendance_request":  s.createAttendanceRequest,
+		"hr_core_ext.api.attendance.approve_attendance_request": s.approveAttendanceRequest,
+		"hr_core_ext.api.attendance.checkin":                    s.checkin("IN"),
+		"hr_core_ext.api.attendance.checkout":                   s.checkin("OUT"),
+		"hr_core_ext.api.attendance.get_today_checkin":          s.getTodayCheckin,
+		"hr_core_ext.api.attendance.get_checkin_history":        s.getCheckinHistory,
+
+		client.MethodShiftTypes:                            s.getShiftTypes,
+		"hr_core_ext.api.shift.create_shift_type":          s.createShiftType,
+		"hr_core_ext.api.shift.update_shift_type":          s.updateShiftType,
+		"hr_core_ext.api.shift.get_shift_assignments":      s.getShiftAssignments,
+		"hr_core_ext.api.shift.assign_shift":               s.assignShift,
+		"hr_core_ext.api.shift.unassign_shift":             s.unassignShift,
+		"hr_core_ext.api.shift.get_shift_requests":         s.getShiftRequests,
+		"hr_core_ext.api.shift.create_shift_request":       s.createShiftRequest,
+		"hr_core_ext.api.shift.approve_shift_request":      s.approveShiftRequest,
+		"hr_core_ext.api.shift.get_employee_current_shift": s.getCurrentShift,
+		"hr_core_ext.api.shift.process_auto_attendance":    s.processAutoAttendance,
+
+		client.MethodOTConfig:                         s.getSetting("ot"),
+		"hr_core_ext.api.overtime.update_ot_config":   s.updateSetting("ot"),
+		"hr_core_ext.api.overtime.get_ot_requests":    s.getOTRequests,
+		"hr_core_ext.api.overtime.create_ot_request":  s.createOTRequest,
+		"hr_core_ext.api.overtime.approve_ot_request": s.decideOTRequest("Approved"),
+		"hr_core_ext.api.overtime.reject_ot_request":  s.decideOTRequest("Rejected"),
+		"hr_core_ext.api.overtime.cancel_ot_request":  s.cancelOTRequest,
+
+		"hr_core_ext.api.payroll.get_salary_slips":       s.getSalarySlips,
+		"hr_core_ext.api.payroll.get_salary_slip_detail": s.getSalarySlipDetail,
+		"hr_core_ext.api.payroll.setup_employee_payroll": s.setupEmployeePayroll,
+		"hr_core_ext.api.payroll.process_payroll":        s.processPayroll,
+		"hr_core_ext.api.payroll.submit_payroll":         s.submitPayroll,
+		"hr_core_ext.api.payroll.generate_salary_slip":   s.generateSalarySlip,
+
+		client.MethodTaxSlabs:                                s.getSetting("tax_slabs"),
+		"hr_core_ext.api.tax.get_employee_tax_deductions":    s.getTaxDeductions,
+		"hr_core_ext.api.tax.update_employee_tax_deductions": s.updateTaxDeductions,
+		"hr_core_ext.api.tax.get_employee_tax_summary":       s.getTaxSummary,
+		"hr_core_ext.api.tax.get_pnd1_data":                  s.getPND1,
+		"hr_core_ext.api.tax.get_withholding_cert_data":      s.getWithholdingCert,
+
+		client.MethodSSOConfig:                                       s.getSetting("sso"),
+		"hr_core_ext.api.social_security.update_sso_config":          s.updateSetting("sso"),
+		"hr_core_ext.api.social_security.get_employee_sso_number":    s.getEmployeeSSO,
+		"hr_core_ext.api.social_security.update_employee_sso_number": s.updateEmployeeSSO,
+		"hr_core_ext.api.social_security.get_sso_report":             s.getSSOReport,
+
+		client.MethodPVDConfig:                                 s.getSetting("pvd"),
+		"hr_core_ext.api.provident_fund.update_pvd_config":     s.updateSetting("pvd"),
+		"hr_core_ext.api.provident_fund.get_employee_pvd":      s.getEmployeePVD,
+		"hr_core_ext.api.provident_fund.enroll_employee_pvd":   s.enrollEmployeePVD,
+		"hr_core_ext.api.provident_fund.update_employee_pvd":   s.updateEmployeePVD,
+		"hr_core_ext.api.provident_fund.unenroll_employee_pvd": s.unenrollEmployeePVD,
+		"hr_core_ext.api.provident_fund.get_pvd_report":        s.getPVDReport,
+
+		"hr_core_ext.api.reports.get_employee_summary":  s.getEmployeeSummary,
+		"hr_core_ext.api.reports.get_attendance_report": s.getAttendanceReport,
+		"hr_core_ext.api.reports.get_leave_report":      s.getLeaveReport,
+		"hr_core_ext.api.reports.get_payroll_report":    s.getPayrollReport,
+		"hr_core_ext.api.reports.get_tax_report":        s.getTaxReport,
+		"hr_core_ext.api.reports.export_report_csv":     s.exportReportCSV,
+	}
+}
+
+func (s *Server) requireEmployee(id string) (*Employee, error) {
+	if id == "" {
+		return nil, Validation("employee_id is required")
+	}
+	e := s.employee(id)
+	if e == nil {
+		return nil, NotFound("Employee", id)
+	}
+	return e, nil
+}
+
+func (s *Server) requireRecord(doctype, name string) (*Record, error) {
+	if name == "" {
+		return nil, Validation("name is required")
+	}
+	r := s.record(doctype, name)
+	if r == nil {
+		return nil, NotFound(doctype, name)
+	}
+	return r, nil
+}
+
+// row is a record as it appears in a list: its fields plus name, employee and status.
+func (s *Server) row(r *Record) map[string]any {
+	m := clone(r.Fields)
+	m["name"] = r.Name
+	if r.Employee != "" {
+		m["employee"] = r.Employee
+		m["employee_name"] = s.employeeName(r.Employee)
+	}
+	if r.Status != "" {
+		m["status"] = r.Status
+	}
+	return m
+}
+
+func (s *Server) rows(records []*Record, keep func(*Record) bool) []map[string]any {
+	out := []map[string]any{}
+	for _, r := range records {
+		if keep == nil || keep(r) {
+			out = append(out, s.row(r))
+		}
+	}
+	return out
+}
+
+// page applies the paging arguments of the list methods (see paging.py): employee_ids
+// keeps rows whose employeeKey is listed, order_by sorts, limit_start and
+// limit_page_length slice, and with_total switches to the {"rows", "total"} shape.
+func page(r *Request, rows []map[string]any, employeeKey string) (any, error) {
+	if v := r.Get("employee_ids"); v != "" {
+		var ids []string
+		if err := json.Unmarshal([]byte(v), &ids); err != nil {
+			return nil, Validation("employee_ids must be a JSON list")
+		}
+		kept := []map[string]any{}
+		for _, row := range rows {
+			if slices.Contains(ids, fmt.Sprint(row[employeeKey])) {
+				kept = append(kept, row)
+			}
+		}
+		rows = kept
+	}
+	if v := r.Get("order_by"); v != "" {
+		field, dir, _ := strings.Cut(v, " ")
+		if dir != "asc" && dir != "desc" {
+			return nil, Validation("Cannot sort by '%s'", v)
+		}
+		slices.SortStableFunc(rows, func(a, b map[string]any) int {
+			c := compare(a[field], b[field])
+			if c == 0 {
+				c = strings.Compare(fmt.Sprint(a["name"]), fmt.Sprint(b["name"]))
+			}
+			if dir == "desc" {
+				c = -c
+			}
+			return c
+		})
+	}
+
+	total := len(rows)
+	start := min(integer(r, "limit_start"), total)
+	end := total
+	if n := integer(r, "limit_page_length"); n > 0 {
+		end = min(start+n, total)
+	}
+	rows = rows[start:end]
+	if r.Get("with_total") != "1" {
+		return rows, nil
+	}
+	return map[string]any{"rows": rows, "total": total}, nil
+}
+
+func compare(a, b any) int {
+	x, xok := a.(float64)
+	y, yok := b.(float64)
+	if xok && yok {
+		return cmp.Compare(x, y)
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func number(r *Request, key string) float64 {
+	f, _ := strconv.ParseFloat(r.Get(key), 64)
+	return f
+}
+
+func integer(r *Request, key string) int {
+	n, _ := strconv.Atoi(r.Get(key))
+	return n
+}
+
+// --- access ---
+
+func (s *Server) getRecordOwner(r *Request) (any, error) {
+	doctype := r.Get("doctype")
+	switch doctype {
+	case client.DoctypeLeaveApplication, client.DoctypeAttendanceRequest, client.DoctypeShiftRequest, client.DoctypeOvertimeRequest,
+		client.DoctypeShiftAssignment:
+	case client.DoctypeDepartment:
+		d := s.department(r.Get("name"))
+		if d == nil {
+			return nil, NotFound(doctype, r.Get("name"))
+		}
+		return map[string]any{"name": d.Name, "employee": nil, "company": d.Company, "docstatus": 0}, nil
+	default:
+		return nil, Validation("Owner lookup is not allowed for %s", doctype)
+	}
+	rec, err := s.requireRecord(doctype, r.Get("name"))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"name": rec.Name, "employee": rec.Employee, "company": rec.Company, "docstatus": rec.DocStatus}, nil
+}
+
+// --- company ---
+
+func (s *Server) createCompany(r *Request) (any, error) {
+	name := r.Get("company_name")
+	if name == "" {
+		return nil, Validation("company_name is required")
+	}
+	for _, c := range s.companies {
+		if c == name {
+			return nil, Validation("Company %s already exists", name)
+		}
+	}
+	s.companies = append(s.companies, name)
+	return map[string]any{"name": name, "abbr": r.Get("abbr")}, nil
+}
+
+func (s *Server) createEmployee(r *Request) (any, error) {
+	if r.Get("employee_name") == "" || r.Get("company") == "" {
+		return nil, Validation("employee_name and company are required")
+	}
+	s.seq++
+	id := fmt.Sprintf("HR-EMP-%05d", 200+s.seq)
+	s.putEmployee(Employee{ID: id, Name: r.Get("employee_name"), Company: r.Get("company"), Department: r.Get("department"), Designation: r.Get("designation")})
+	return map[string]any{"employee_id": id, "employee_name": r.Get("employee_name")}, nil
+}
+
+// --- employee ---
+
+func (s *Server) employeeSummary(e *Employee) map[string]any {
+	return map[string]any{
+		"name": e.ID, "employee_id": e.ID, "employee_name": e.Name, "department": e.Department,
+		"designation": e.Designation, "status": e.Status, "company": e.Company,
+		"date_of_joining": "2024-01-15", "image": nil,
+	}
+}
+
+func (s *Server) getEmployees(r *Request) (any, error) {
+	out := []map[string]any{}
+	for _, e := range s.employees {
+		if c := r.Get("company"); c != "" && e.Company != c {
+			continue
+		}
+		if id := r.Get("employee_id"); id != "" && e.ID != id {
+			continue
+		}
+		if d := r.Get("department"); d != "" && e.Department != d {
+			continue
+		}
+		if d := r.Get("designation"); d != "" && e.Designation != d {
+			continue
+		}
+		if st := r.Get("status"); st != "" && e.Status != st {
+			continue
+		}
+		out = append(out, s.employeeSummary(e))
+	}
+	return page(r, out, "employee_id")
+}
+
+func (s *Server) getEmployee(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	m := s.employeeSummary(e)
+	delete(m, "name")
+	for k, v := range e.Fields {
+		m[k] = v
+	}
+	return m, nil
+}
+
+func (s *Server) getEmployeeFull(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	m := s.employeeSummary(e)
+	m["reports_to"] = nilIfEmpty(e.ReportsTo)
+	m["reports_to_name"] = nilIfEmpty(s.employeeName(e.ReportsTo))
+	m["leave_approver"] = nilIfEmpty(e.LeaveApprover)
+	for k, v := range e.Fields {
+		m[k] = v
+	}
+	return m, nil
+}
+
+func nilIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// employeeFields are the columns update_employee may change, by the name the BFF sends.
+var employeeFields = []string{
+	"employee_name", "department", "designation", "employment_type", "branch", "reports_to",
+	"leave_approver", "status", "cell_phone", "personal_email", "company_email",
+	"current_address", "permanent_address", "emergency_phone_number",
+	"person_to_be_contacted", "relation", "date_of_birth", "gender", "marital_status", "blood_group",
+}
+
+func (s *Server) updateEmployee(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	if e.Status != "Active" {
+		for _, f := range employeeFields {
+			if f != "status" && r.Params.Has(f) {
+				return nil, Validation("Cannot edit employee with status '%s'. Change status to Active first.", e.Status)
+			}
+		}
+	}
+	for _, f := range employeeFields {
+		if !r.Params.Has(f) {
+			continue
+		}
+		v := r.Get(f)
+		switch f {
+		case "employee_name":
+			e.Name = v
+		case "department":
+			e.Department = v
+		case "designation":
+			e.Designation = v
+		case "status":
+			e.Status = v
+		case "reports_to":
+			if v != "" && s.employee(v) == nil {
+				return nil, Validation("Manager %s not found", v)
+			}
+			e.ReportsTo = v
+		case "leave_approver":
+			e.LeaveApprover = v
+		default:
+			if e.Fields == nil {
+				e.Fields = map[string]any{}
+			}
+			e.Fields[f] = v
+		}
+	}
+	return map[string]any{"employee_id": e.ID, "status": "updated"}, nil
+}
+
+func (s *Server) updateEmployeeContact(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	if e.Fields == nil {
+		e.Fields = map[string]any{}
+	}
+	for _, f := range []string{"cell_phone", "personal_email", "current_address", "permanent_address", "emergency_phone_number", "person_to_be_contacted", "relation"} {
+		if r.Params.Has(f) {
+			e.Fields[f] = r.Get(f)
+		}
+	}
+	return map[string]any{"employee_id": e.ID, "status": "updated"}, nil
+}
+
+func (s *Server) getReportingLines(r *Request) (any, error) {
+	out := []map[string]any{}
+	for _, e := range s.activeEmployees(r.Get("company")) {
+		out = append(out, map[string]any{
+			"employee_id": e.ID, "reports_to": e.ReportsTo, "leave_approver": e.LeaveApprover, "user_id": e.UserID,
+		})
+	}
+	return out, nil
+}
+
+func (s *Server) validateManager(r *Request) (any, error) {
+	employeeID, managerID := r.Get("employee_id"), r.Get("manager_id")
+	if employeeID == managerID {
+		return map[string]any{"valid": false, "reason": "Employee cannot be their own manager"}, nil
+	}
+	visited := map[string]bool{employeeID: true}
+	for current := managerID; current != ""; {
+		if visited[current] {
+			return map[string]any{"valid": false, "reason": "Circular reporting chain detected"}, nil
+		}
+		visited[current] = true
+		e := s.employee(current)
+		if e == nil {
+			break
+		}
+		current = e.ReportsTo
+	}
+	return map[string]any{"valid": true}, nil
+}
+
+func (s *Server) getEmployeeTimeline(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	return []map[string]any{
+		{"type": "leave", "date": "2026-03-20", "actor": "", "description": "Annual Leave: 2026-04-13 to 2026-04-15 (Open)", "status": "Open"},
+		{"type": "field_change", "date": "2024-01-15 09:00:00", "actor": "Administrator", "field": "designation", "old_value": "", "new_value": e.Designation},
+	}, nil
+}
+
+func (s *Server) getSalaryStructure(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"assignment": map[string]any{"name": "HR-SSA-" + e.ID, "from_date": "2024-01-15", "base": 30000.0, "variable": 0.0},
+		"structure":  "Standard Thai Salary",
+		"earnings":   []map[string]any{{"salary_component": "Basic Salary", "amount": 30000.0, "formula": nil, "amount_based_on_formula": 0}},
+		"deductions": []map[string]any{{"salary_component": "Social Security", "amount": 750.0, "formula": nil, "amount_based_on_formula": 0}},
+	}, nil
+}
+
+func (s *Server) getPromotions(r *Request) (any, error) {
+	if _, err := s.requireEmployee(r.Get("employee_id")); err != nil {
+		return nil, err
+	}
+	return []map[string]any{}, nil
+}
+
+// --- documents ---
+
+func (s *Server) getEmployeeDocuments(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	out := []map[string]any{}
+	for _, d := range s.documents {
+		if d.Employee == e.ID {
+			out = append(out, map[string]any{
+				"name": d.Name, "file_name": d.FileName, "file_url": d.FileURL, "file_size": len(d.Content),
+				"creation": "2026-03-02 09:00:00", "modified": "2026-03-02 09:00:00", "owner": "Administrator",
+			})
+		}
+	}
+	return out, nil
+}
+
+// uploadFile is frappe.handler.upload_file, as the BFF calls it to attach a file to an employee.
+func (s *Server) uploadFile(r *Request) (any, error) {
+	f, ok := r.Files["file"]
+	if !ok {
+		return nil, Validation("No file attached")
+	}
+	if r.Get("doctype") != "Employee" {
+		return nil, Validation("The fake only attaches files to employees")
+	}
+	e, err := s.requireEmployee(r.Get("docname"))
+	if err != nil {
+		return nil, err
+	}
+	name := r.Get("file_name")
+	if name == "" {
+		name = f.FileName
+	}
+	s.seq++
+	d := &Document{Name: fmt.Sprintf("file-%05d", s.seq), Employee: e.ID, FileName: name, FileURL: s.fileURL(name), Content: f.Content}
+	s.documents = append(s.documents, d)
+	return map[string]any{
+		"name": d.Name, "file_name": d.FileName, "file_url": d.FileURL, "file_size": len(d.Content),
+		"is_private": r.Get("is_private"), "attached_to_doctype": "Employee", "attached_to_name": e.ID,
+	}, nil
+}
+
+// fileURL returns a free URL for a private file, adding a suffix as Frappe does when
+// the name is taken.
+func (s *Server) fileURL(fileName string) string {
+	url := "/private/files/" + fileName
+	for s.documentAt(url) != nil {
+		s.seq++
+		ext := path.Ext(fileName)
+		url = fmt.Sprintf("/private/files/%s%06x%s", strings.TrimSuffix(fileName, ext), s.seq, ext)
+	}
+	return url
+}
+
+func (s *Server) documentAt(fileURL string) *Document {
+	for _, d := range s.documents {
+		if d.FileURL == fileURL {
+			return d
+		}
+	}
+	return nil
+}
+
+func (s *Server) deleteEmployeeDocument(r *Request) (any, error) {
+	name := r.Get("file_name")
+	for i, d := range s.documents {
+		if d.Name == name {
+			s.documents = append(s.documents[:i], s.documents[i+1:]...)
+			return map[string]any{"status": "deleted", "name": name}, nil
+		}
+	}
+	return nil, NotFound("File", name)
+}
+
+func (s *Server) getDocumentOwner(r *Request) (any, error) {
+	name := r.Get("file_name")
+	for _, d := range s.documents {
+		if d.Name == name {
+			company := ""
+			if e := s.employee(d.Employee); e != nil {
+				company = e.Company
+			}
+			return map[string]any{"name": d.Name, "employee": d.Employee, "company": company, "file_name": d.FileName, "file_url": d.FileURL}, nil
+		}
+	}
+	return nil, NotFound("File", name)
+}
+
+// --- departments and org chart ---
+
+func (s *Server) department(name string) *Department {
+	for _, d := range s.departments {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+func (s *Server) departmentRow(d *Department) map[string]any {
+	count := 0
+	for _, e := range s.activeEmployees(d.Company) {
+		if e.Department == d.Name {
+			count++
+		}
+	}
+	isGroup := 0
+	for _, other := range s.departments {
+		if other.Parent == d.Name {
+			isGroup = 1
+		}
+	}
+	return map[string]any{
+		"name": d.Name, "department_name": d.Label, "parent_department": d.Parent,
+		"company": d.Company, "is_group": isGroup, "employee_count": count,
+	}
+}
+
+func (s *Server) getDepartments(r *Request) (any, error) {
+	out := []map[string]any{}
+	for _, d := range s.departments {
+		if c := r.Get("company"); c != "" && d.Company != c {
+			continue
+		}
+		out = append(out, s.departmentRow(d))
+	}
+	return map[string]any{"departments": out, "total": len(out)}, nil
+}
+
+// companyDepartment is _get_department: with a company param, departments of other
+// companies are not found.
+func (s *Server) companyDepartment(r *Request) (*Department, error) {
+	d := s.department(r.Get("name"))
+	if d == nil || (r.Get("company") != "" && d.Company != r.Get("company")) {
+		return nil, NotFound("Department", r.Get("name"))
+	}
+	return d, nil
+}
+
+// checkParent is _check_parent: a parent_department of another company is not found.
+func (s *Server) checkParent(r *Request) error {
+	name, company := r.Get("parent_department"), r.Get("company")
+	if p := s.department(name); name != "" && company != "" && p != nil && p.Company != "" && p.Company != company {
+		return NotFound("Department", name)
+	}
+	return nil
+}
+
+func (s *Server) getDepartment(r *Request) (any, error) {
+	d, err := s.companyDepartment(r)
+	if err != nil {
+		return nil, err
+	}
+	m := s.departmentRow(d)
+	delete(m, "employee_count")
+	members := []map[string]any{}
+	for _, e := range s.activeEmployees(d.Company) {
+		if e.Department == d.Name {
+			members = append(members, map[string]any{"employee_id": e.ID, "employee_name": e.Name, "designation": e.Designation, "image": "", "status": e.Status})
+		}
+	}
+	return map[string]any{"department": m, "employees": members}, nil
+}
+
+func (s *Server) createDepartment(r *Request) (any, error) {
+	label := r.Get("department_name")
+	if label == "" {
+		return nil, Validation("department_name is required")
+	}
+	name := label
+	if c := r.Get("company"); c != "" {
+		name = label + " - " + abbr(c)
+	}
+	if s.department(name) != nil {
+		return nil, Validation("Department %s already exists", name)
+	}
+	if err := s.checkParent(r); err != nil {
+		return nil, err
+	}
+	s.departments = append(s.departments, &Department{Name: name, Label: label, Company: r.Get("company"), Parent: r.Get("parent_department")})
+	return map[string]any{"name": name, "department_name": label}, nil
+}
+
+func abbr(company string) string {
+	var b strings.Builder
+	for _, w := range strings.Fields(company) {
+		b.WriteString(strings.ToUpper(w[:1]))
+	}
+	return b.String()
+}
+
+func (s *Server) updateDepartment(r *Request) (any, error) {
+	d, err := s.companyDepartment(r)
+	if err != nil {
+		return nil, err
+	}
+	if v := r.Get("department_name"); v != "" {
+		d.Label = v
+	}
+	if err := s.checkParent(r); err != nil {
+		return nil, err
+	}
+	if r.Params.Has("parent_department") {
+		d.Parent = r.Get("parent_department")
+	}
+	return map[string]any{"name": d.Name, "department_name": d.Label, "parent_department": d.Parent}, nil
+}
+
+func (s *Server) deleteDepartment(r *Request) (any, error) {
+	if _, err := s.companyDepartment(r); err != nil {
+		return nil, err
+	}
+	name := r.Get("name")
+	for i, d := range s.departments {
+		if d.Name != name {
+			continue
+		}
+		for _, e := range s.employees {
+			if e.Department == name {
+				return nil, Validation("Cannot delete department %s: employees are still assigned to it", name)
+			}
+		}
+		s.departments = append(s.departments[:i], s.departments[i+1:]...)
+		return map[string]any{"message": "Department deleted"}, nil
+	}
+	return nil, NotFound("Department", name)
+}
+
+func (s *Server) getOrgTree(r *Request) (any, error) {
+	employees := s.activeEmployees(r.Get("company"))
+	nodes := map[string]map[string]any{}
+	for _, e := range employees {
+		nodes[e.ID] = map[string]any{"id": e.ID, "name": e.Name, "designation": e.Designation, "department": e.Department, "image": "", "children": []map[string]any{}}
+	}
+	roots := []map[string]any{}
+	for _, e := range employees {
+		if parent, ok := nodes[e.ReportsTo]; ok {
+			parent["children"] = append(parent["children"].([]map[string]any), nodes[e.ID])
+		} else {
+			roots = append(roots, nodes[e.ID])
+		}
+	}
+	return map[string]any{"tree": roots, "total_employees": len(employees)}, nil
+}
+
+func (s *Server) getDepartmentTree(r *Request) (any, error) {
+	groups := map[string][]map[string]any{}
+	for _, e := range s.activeEmployees(r.Get("company")) {
+		dept := e.Department
+		if dept == "" {
+			dept = "Unassigned"
+		}
+		groups[dept] = append(groups[dept], map[string]any{"id": e.ID, "name": e.Name, "designation": e.Designation, "image": ""})
+	}
+	out := []map[string]any{}
+	for _, dept := range sortedKeys(groups) {
+		out = append(out, map[string]any{"department": dept, "members": groups[dept], "count": len(groups[dept])})
+	}
+	return map[string]any{"departments": out, "total_departments": len(out)}, nil
+}
+
+// --- leave ---
+
+func (s *Server) getLeaveBalance(r *Request) (any, error) {
+	if _, err := s.requireEmployee(r.Get("employee_id")); err != nil {
+		return nil, err
+	}
+	return []map[string]any{
+		{"leave_type": "Annual Leave", "total_allocated": 10.0, "used": 3.0, "remaining": 7.0},
+		{"leave_type": "Sick Leave", "total_allocated": 30.0, "used": 0.0, "remaining": 30.0},
+	}, nil
+}
+
+func (s *Server) getLeaveAllocations(r *Request) (any, error) {
+	if _, err := s.requireEmployee(r.Get("employee_id")); err != nil {
+		return nil, err
+	}
+	return []map[string]any{
+		{"leave_type": "Annual Leave", "total_allocated": 10.0, "used": 3.0, "remaining": 7.0, "from_date": "2026-01-01", "to_date": "2026-12-31"},
+		{"leave_type": "Sick Leave", "total_allocated": 30.0, "used": 0.0, "remaining": 30.0, "from_date": "2026-01-01", "to_date": "2026-12-31"},
+	}, nil
+}
+
+func (s *Server) getLeaveApplications(r *Request) (any, error) {
+	status := r.Get("status")
+	return page(r, s.rows(s.recordsOf(client.DoctypeLeaveApplication, r.Get("company"), r.Get("employee_id")), func(rec *Record) bool {
+		return status == "" || rec.Status == status
+	}), "employee")
+}
+
+func (s *Server) createLeaveApplication(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	if r.Get("to_date") < r.Get("from_date") {
+		return nil, Validation("To date cannot be before from date")
+	}
+	rec := s.putRecord(Record{Doctype: client.DoctypeLeaveApplication, Employee: e.ID, Company: e.Company, Status: "Open", Fields: map[string]any{
+		"leave_type": r.Get("leave_type"), "from_date": r.Get("from_date"), "to_date": r.Get("to_date"),
+		"total_leave_days": 1.0, "posting_date": "2026-03-20", "description": r.Get("reason"),
+	}})
+	return map[string]any{"name": rec.Name, "status": rec.Status}, nil
+}
+
+func (s *Server) openLeave(name string) (*Record, error) {
+	rec, err := s.requireRecord(client.DoctypeLeaveApplication, name)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Status != "Open" {
+		return nil, Validation("Leave application %s is already %s", name, rec.Status)
+	}
+	return rec, nil
+}
+
+func (s *Server) updateLeaveApplication(r *Request) (any, error) {
+	rec, err := s.openLeave(r.Get("leave_id"))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range []string{"leave_type", "from_date", "to_date"} {
+		if r.Params.Has(f) {
+			rec.Fields[f] = r.Get(f)
+		}
+	}
+	if r.Params.Has("reason") {
+		rec.Fields["description"] = r.Get("reason")
+	}
+	return map[string]any{"name": rec.Name, "status": rec.Status}, nil
+}
+
+func (s *Server) approveLeaveApplication(r *Request) (any, error) {
+	rec, err := s.openLeave(r.Get("leave_id"))
+	if err != nil {
+		return nil, err
+	}
+	rec.Status = r.Get("status")
+	rec.DocStatus = 1
+	return map[string]any{"name": rec.Name, "status": rec.Status}, nil
+}
+
+func (s *Server) cancelLeaveApplication(r *Request) (any, error) {
+	rec, err := s.openLeave(r.Get("leave_id"))
+	if err != nil {
+		return nil, err
+	}
+	rec.Status = "Cancelled"
+	rec.DocStatus = 2
+	return map[string]any{"name": rec.Name, "status": rec.Status}, nil
+}
+
+// --- attendance ---
+
+func attendanceRecords() []map[string]any {
+	return []map[string]any{
+		{"attendance_date": "2026-03-03", "status": "On Leave", "working_hours": 0.0, "leave_type": "Annual Leave"},
+		{"attendance_date": "2026-03-02", "status": "Present", "working_hours": 8.5, "leave_type": nil},
+	}
+}
+
+func (s *Server) getAttendanceSummary(r *Request) (any, error) {
+	if _, err := s.requireEmployee(r.Get("employee_id")); err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"records": attendanceRecords(),
+		"summary": map[string]any{"total_days": 2, "present": 1, "absent": 0, "on_leave": 1},
+	}, nil
+}
+
+func (s *Server) getAttendanceDetail(r *Request) (any, error) {
+	if _, err := s.requireEmployee(r.Get("employee_id")); err != nil {
+		return nil, err
+	}
+	records := attendanceRecords()
+	for i, rec := range records {
+		rec["name"] = fmt.Sprintf("HR-ATT-2026-%05d", i+1)
+		rec["late_entry"], rec["early_exit"] = 0, 0
+	}
+	return map[string]any{
+		"records":  records,
+		"checkins": []map[string]any{{"time": "2026-03-02 09:00:00", "log_type": "IN"}, {"time": "2026-03-02 17:30:00", "log_type": "OUT"}},
+		"summary":  map[string]any{"total_days": 2, "present": 1, "absent": 0, "on_leave": 1, "late_days": 0},
+	}, nil
+}
+
+func (s *Server) getAttendanceRequests(r *Request) (any, error) {
+	return page(r, s.rows(s.recordsOf(client.DoctypeAttendanceRequest, r.Get("company"), r.Get("employee_id")), nil), "employee")
+}
+
+func (s *Server) createAttendanceRequest(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	rec := s.putRecord(Record{Doctype: client.DoctypeAttendanceRequest, Employee: e.ID, Company: e.Company, Status: "Pending", Fields: map[string]any{
+		"from_date": r.Get("attendance_date"), "to_date": r.Get("attendance_date"), "reason": r.Get("reason"), "half_day": 0,
+	}})
+	return map[string]any{"name": rec.Name, "status": rec.Status}, nil
+}
+
+func (s *Server) approveAttendanceRequest(r *Request) (any, error) {
+	rec, err := s.requireRecord(client.DoctypeAttendanceRequest, r.Get("request_id"))
+	if err != nil {
+		return nil, err
+	}
+	if rec.Status != "Pending" {
+		return nil, Validation("Attendance request %s is already %s", rec.Name, rec.Status)
+	}
+	switch r.Get("action") {
+	case "approve":
+		rec.Status, rec.DocStatus = "Approved", 1
+	case "reject":
+		rec.Status, rec.DocStatus = "Rejected", 2
+	default:
+		return nil, Validation("Action must be 'approve' or 'reject'")
+	}
+	return map[string]any{"name": rec.Name, "action": r.Get("action")}, nil
+}
+
+func (s *Server) checkin(logType string) HandlerFunc {
+	return func(r *Request) (any, error) {
+		if _, err := s.requireEmployee(r.Get("employee_id")); err != nil {
+			return nil, err
+		}
+		s.seq++
+		return map[string]any{"name": fmt.Sprintf("EMP-CKIN-%05d", s.seq), "time": "2026-03-02 09:00:00", "log_type": logType}, nil
+	}
+}
+
+func (s *Server) getTodayCheckin(r *Request) (any, error) {
+	if _, err := s.requireEmployee(r.Get("employee_id")); err != nil {
+		return nil, err
+	}
+	return map[string]any{"checkins": []any{}, "first_in": nil, "last_out": nil, "working_hours": 0.0, "is_checked_in": false}, nil
+}
+
+func (s *Server) getCheckinHistory(r *Request) (any, error) {
+	if _, err := s.requireEmployee(r.Get("employee_id")); err != nil {
+		return nil, err
+	}
+	return map[string]any{"days": []map[string]any{{
+		"date": "2026-03-02", "first_in": "2026-03-02 09:00:00", "last_out": "2026-03-02 18:00:00", "working_hours": 9.0, "checkin_count": 2,
+		"checkins": []map[string]any{{"time": "2026-03-02 09:00:00", "log_type": "IN"}, {"time": "2026-03-02 18:00:00", "log_type": "OUT"}},
+	}}}, nil
+}
+
+// --- shifts ---
+
+// shiftType mirrors _check_shift_type: with company, other companies' shift types are not found.
+func (s *Server) shiftType(name, company string) (*Record, error) {
+	rec, err := s.requireRecord(DoctypeShiftType, name)
+	if err != nil {
+		return nil, err
+	}
+	if company != "" && rec.Company != "" && rec.Company != company {
+		return nil, NotFound(DoctypeShiftType, name)
+	}
+	return rec, nil
+}
+
+func (s *Server) getShiftTypes(r *Request) (any, error) {
+	company := r.Get("company")
+	return s.rows(s.recordsOf(DoctypeShiftType, "", ""), func(rec *Record) bool {
+		return company == "" || rec.Company == "" || rec.Company == company
+	}), nil
+}
+
+func (s *Server) createShiftType(r *Request) (any, error) {
+	name := r.Get("name")
+	if s.record(DoctypeShiftType, name) != nil {
+		return nil, Validation("Shift Type %s already exists", name)
+	}
+	grace := func(key string) int {
+		if r.Params.Has(key) {
+			return integer(r, key)
+		}
+		return 15
+	}
+	rec := s.putRecord(Record{Doctype: DoctypeShiftType, Name: name, Company: r.Get("company"), Fields: map[string]any{
+		"start_time": r.Get("start_time"), "end_time": r.Get("end_time"), "holiday_list": r.Get("holiday_list"),
+		"late_entry_grace_period": grace("late_entry_grace_period"), "early_exit_grace_period": grace("early_exit_grace_period"),
+		"enable_auto_attendance": 1, "working_hours_threshold_for_half_day": 4.0, "working_hours_threshold_for_absent": 2.0,
+	}})
+	return map[string]any{"name": rec.Name, "start_time": rec.Fields["start_time"], "end_time": rec.Fields["end_time"]}, nil
+}
+
+func (s *Server) updateShiftType(r *Request) (any, error) {
+	rec, err := s.shiftType(r.Get("shift_type_name"), r.Get("company"))
+	if err != nil {
+		return nil, err
+	}
+	if r.Get("company") != "" && rec.Company == "" {
+		return nil, Forbidden("Shift Type %s is shared by every company and cannot be changed", rec.Name)
+	}
+	for _, f := range []string{"start_time", "end_time"} {
+		if r.Params.Has(f) {
+			rec.Fields[f] = r.Get(f)
+		}
+	}
+	for _, f := range []string{"late_entry_grace_period", "early_exit_grace_period"} {
+		if r.Params.Has(f) {
+			rec.Fields[f] = integer(r, f)
+		}
+	}
+	return map[string]any{
+		"name": rec.Name, "start_time": rec.Fields["start_time"], "end_time": rec.Fields["end_time"],
+		"late_entry_grace_period": rec.Fields["late_entry_grace_period"], "early_exit_grace_period": rec.Fields["early_exit_grace_period"],
+	}, nil
+}
+
+func (s *Server) assignmentRow(rec *Record) map[string]any {
+	m := s.row(rec)
+	m["company"] = rec.Company
+	return m
+}
+
+func (s *Server) getShiftAssignments(r *Request) (any, error) {
+	out := []map[string]any{}
+	for _, rec := range s.recordsOf(DoctypeShiftAssignment, r.Get("company"), r.Get("employee_id")) {
+		if st := r.Get("shift_type"); st != "" && rec.Fields["shift_type"] != st {
+			continue
+		}
+		if rec.Status != "Active" {
+			continue
+		}
+		out = append(out, s.assignmentRow(rec))
+	}
+	return page(r, out, "employee")
+}
+
+func (s *Server) assignShift(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.shiftType(r.Get("shift_type"), e.Company); err != nil {
+		return nil, err
+	}
+	var end any
+	if v := r.Get("end_date"); v != "" {
+		end = v
+	}
+	rec := s.putRecord(Record{Doctype: DoctypeShiftAssignment, Employee: e.ID, Company: e.Company, Status: "Active", DocStatus: 1, Fields: map[string]any{
+		"shift_type": r.Get("shift_type"), "start_date": r.Get("start_date"), "end_date": end,
+	}})
+	m := s.row(rec)
+	delete(m, "status")
+	return m, nil
+}
+
+func (s *Server) unassignShift(r *Request) (any, error) {
+	rec, err := s.requireRecord(DoctypeShiftAssignment, r.Get("assignment_id"))
+	if err != nil {
+		return nil, err
+	}
+	if c := r.Get("company"); c != "" && rec.Company != c {
+		return nil, NotFound(DoctypeShiftAssignment, rec.Name)
+	}
+	rec.Status, rec.DocStatus = "Cancelled", 2
+	return map[string]any{"name": rec.Name, "status": rec.Status}, nil
+}
+
+func (s *Server) getShiftRequests(r *Request) (any, error) {
+	status := r.Get("status")
+	return page(r, s.rows(s.recordsOf(client.DoctypeShiftRequest, r.Get("company"), r.Get("employee_id")), func(rec *Record) bool {
+		return status == "" || rec.Status == status
+	}), "employee")
+}
+
+func (s *Server) createShiftRequest(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.shiftType(r.Get("shift_type"), e.Company); err != nil {
+		return nil, err
+	}
+	rec := s.putRecord(Record{Doctype: client.DoctypeShiftRequest, Employee: e.ID, Company: e.Company, Status: "Draft", Fields: map[string]any{
+		"shift_type": r.Get("shift_type"), "from_date": r.Get("from_date"), "to_date": r.Get("to_date"), "approver": "",
+	}})
+	return map[string]any{"name": rec.Name, "status": rec.Status, "shift_type": r.Get("shift_type")}, nil
+}
+
+func (s *Server) approveShiftRequest(r *Request) (any, error) {
+	rec, err := s.requireRecord(client.DoctypeShiftRequest, r.Get("request_id"))
+	if err != nil {
+		return nil, err
+	}
+	if rec.Status != "Draft" {
+		return nil, Validation("Shift request %s is already %s", rec.Name, rec.Status)
+	}
+	switch r.Get("action") {
+	case "approve":
+		rec.Status = "Approved"
+	case "reject":
+		rec.Status = "Rejected"
+	default:
+		return nil, Validation("Action must be 'approve' or 'reject'")
+	}
+	rec.DocStatus = 1
+	return map[string]any{"name": rec.Name, "status": rec.Status, "action": r.Get("action")}, nil
+}
+
+func (s *Server) getCurrentShift(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range s.recordsOf(DoctypeShiftAssignment, "", e.ID) {
+		if rec.Status != "Active" {
+			continue
+		}
+		st := s.record(DoctypeShiftType, fmt.Sprint(rec.Fields["shift_type"]))
+		if st == nil {
+			continue
+		}
+		return map[string]any{
+			"has_shift": true, "shift_type": st.Name, "start_time": st.Fields["start_time"], "end_time": st.Fields["end_time"],
+			"assignment_start": rec.Fields["start_date"], "assignment_end": rec.Fields["end_date"], "assignment_name": rec.Name,
+			"late_entry_grace_period": st.Fields["late_entry_grace_period"], "early_exit_grace_period": st.Fields["early_exit_grace_period"],
+		}, nil
+	}
+	return map[string]any{"has_shift": false}, nil
+}
+
+func (s *Server) processAutoAttendance(r *Request) (any, error) {
+	date := r.Get("date")
+	if date == "" {
+		date = "2026-03-01"
+	}
+	created := []map[string]any{}
+	for _, rec := range s.recordsOf(DoctypeShiftAssignment, r.Get("company"), "") {
+		if rec.Status == "Active" {
+			created = append(created, map[string]any{
+				"employee": rec.Employee, "employee_name": s.employeeName(rec.Employee), "status": "Present",
+				"working_hours": 8.0, "late_entry": false, "early_exit": false,
+			})
+		}
+	}
+	return map[string]any{
+		"date": date, "processed_count": len(created), "skipped_count": 0, "error_count": 0,
+		"late_count": 0, "early_exit_count": 0, "created": created, "skipped": []any{}, "errors": []any{},
+	}, nil
+}
+
+// --- overtime ---
+
+func (s *Server) getOTRequests(r *Request) (any, error) {
+	status := r.Get("status")
+	return page(r, s.rows(s.recordsOf(client.DoctypeOvertimeRequest, r.Get("company"), r.Get("employee_id")), func(rec *Record) bool {
+		return rec.DocStatus != 2 && (status == "" || rec.Status == status)
+	}), "employee")
+}
+
+func (s *Server) createOTRequest(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	switch r.Get("ot_type") {
+	case "weekday_ot", "holiday_work", "holiday_ot":
+	default:
+		return nil, Validation("ot_type must be one of: weekday_ot, holiday_work, holiday_ot")
+	}
+	if h := number(r, "hours"); h <= 0 || h > 24 {
+		return nil, Validation("Hours must be between 0 and 24")
+	}
+	rec := s.putRecord(Record{Doctype: client.DoctypeOvertimeRequest, Employee: e.ID, Company: e.Company, Status: "Pending", Fields: map[string]any{
+		"ot_date": r.Get("ot_date"), "ot_type": r.Get("ot_type"), "hours": number(r, "hours"), "reason": r.Get("reason"), "approved_by": "", "amount": 0.0,
+	}})
+	return map[string]any{
+		"name": rec.Name, "employee": e.ID, "employee_name": e.Name, "ot_date": r.Get("ot_date"), "ot_type": r.Get("ot_type"),
+		"hours": number(r, "hours"), "reason": r.Get("reason"), "status": rec.Status,
+	}, nil
+}
+
+func (s *Server) decideOTRequest(status string) HandlerFunc {
+	return func(r *Request) (any, error) {
+		rec, err := s.requireRecord(client.DoctypeOvertimeRequest, r.Get("request_id"))
+		if err != nil {
+			return nil, err
+		}
+		if rec.Status != "Pending" {
+			return nil, Validation("OT request %s is already %s", rec.Name, rec.Status)
+		}
+		rec.Status = status
+		if status != "Approved" {
+			return map[string]any{"name": rec.Name, "status": rec.Status}, nil
+		}
+		// A 30,000 monthly salary over 26 days of 8 hours, at the weekday rate
+		hours, _ := rec.Fields["hours"].(float64)
+		rec.DocStatus = 1
+		rec.Fields["amount"] = 216.35 * hours
+		return map[string]any{
+			"name": rec.Name, "status": rec.Status, "amount": rec.Fields["amount"], "hourly_rate": 144.23, "multiplier": 1.5, "hours": hours,
+		}, nil
+	}
+}
+
+func (s *Server) cancelOTRequest(r *Request) (any, error) {
+	rec, err := s.requireRecord(client.DoctypeOvertimeRequest, r.Get("request_id"))
+	if err != nil {
+		return nil, err
+	}
+	rec.Status, rec.DocStatus = "Cancelled", 2
+	return map[string]any{"name": rec.Name, "status": rec.Status}, nil
+}
+
+// --- settings ---
+
+func (s *Server) getSetting(name string) HandlerFunc {
+	return func(r *Request) (any, error) {
+		return clone(s.setting(name, r.Get("company"))), nil
+	}
+}
+
+// updateSetting stores every param except company in the company's own copy, as numbers
+// where they parse.
+func (s *Server) updateSetting(name string) HandlerFunc {
+	return func(r *Request) (any, error) {
+		company := r.Get("company")
+		if company == "" {
+			return nil, Validation("company is required")
+		}
+		m := clone(s.setting(name, company))
+		s.settings[name+"::"+company] = m
+		for key := range r.Params {
+			if key == "company" {
+				continue
+			}
+			if f, err := strconv.ParseFloat(r.Get(key), 64); err == nil {
+				m[key] = f
+			} else {
+				m[key] = r.Get(key)
+			}
+		}
+		return clone(m), nil
+	}
+}
+
+// --- payroll ---
+
+func (s *Server) slipRow(rec *Record, detail bool) map[string]any {
+	m := s.row(rec)
+	if detail {
+		m["company"] = rec.Company
+	} else {
+		delete(m, "earnings")
+		delete(m, "deductions")
+	}
+	return m
+}
+
+func inPeriod(rec *Record, month, year int) bool {
+	start, _ := rec.Fields["start_date"].(string)
+	switch {
+	case month > 0 && year > 0:
+		return strings.HasPrefix(start, fmt.Sprintf("%d-%02d-", year, month))
+	case year > 0:
+		return strings.HasPrefix(start, fmt.Sprintf("%d-", year))
+	}
+	return true
+}
+
+func (s *Server) getSalarySlips(r *Request) (any, error) {
+	month, year := integer(r, "month"), integer(r, "year")
+	out := []map[string]any{}
+	for _, rec := range s.recordsOf(DoctypeSalarySlip, r.Get("company"), r.Get("employee_id")) {
+		if rec.DocStatus != 2 && inPeriod(rec, month, year) {
+			out = append(out, s.slipRow(rec, false))
+		}
+	}
+	return page(r, out, "employee")
+}
+
+func (s *Server) getSalarySlipDetail(r *Request) (any, error) {
+	rec, err := s.requireRecord(DoctypeSalarySlip, r.Get("slip_id"))
+	if err != nil {
+		return nil, err
+	}
+	return s.slipRow(rec, true), nil
+}
+
+func (s *Server) setupEmployeePayroll(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	s.seq++
+	return map[string]any{
+		"name": fmt.Sprintf("HR-SSA-2026-%05d", s.seq), "employee": e.ID, "salary_structure": "Standard Thai Salary",
+		"base": number(r, "base_salary"), "from_date": "2026-03-01",
+	}, nil
+}
+
+func (s *Server) slipTotal(rec *Record) map[string]any {
+	return map[string]any{
+		"name": rec.Name, "employee": rec.Employee, "employee_name": s.employeeName(rec.Employee),
+		"gross_pay": rec.Fields["gross_pay"], "total_deduction": rec.Fields["total_deduction"], "net_pay": rec.Fields["net_pay"],
+	}
+}
+
+func (s *Server) processPayroll(r *Request) (any, error) {
+	month, year := integer(r, "month"), integer(r, "year")
+	slips, skipped := []map[string]any{}, []map[string]any{}
+	var gross, deduction, net float64
+	for _, e := range s.activeEmployees(r.Get("company")) {
+		exists := false
+		for _, rec := range s.recordsOf(DoctypeSalarySlip, "", e.ID) {
+			if rec.DocStatus != 2 && inPeriod(rec, month, year) {
+				exists = true
+			}
+		}
+		if exists {
+			skipped = append(skipped, map[string]any{"employee": e.ID, "employee_name": e.Name, "reason": "Salary slip already exists"})
+			continue
+		}
+		rec := s.putRecord(Record{Doctype: DoctypeSalarySlip, Employee: e.ID, Company: e.Company, Status: "Draft", Fields: salarySlipFields(year, month, 30000)})
+		slips = append(slips, s.slipTotal(rec))
+		gross += rec.Fields["gross_pay"].(float64)
+		deduction += rec.Fields["total_deduction"].(float64)
+		net += rec.Fields["net_pay"].(float64)
+	}
+	return map[string]any{
+		"month": month, "year": year, "created_count": len(slips), "skipped_count": len(skipped), "error_count": 0,
+		"total_gross": gross, "total_deduction": deduction, "total_net": net,
+		"slips": slips, "skipped": skipped, "errors": []any{},
+	}, nil
+}
+
+func (s *Server) submitPayroll(r *Request) (any, error) {
+	month, year := integer(r, "month"), integer(r, "year")
+	submitted := []map[string]any{}
+	for _, rec := range s.recordsOf(DoctypeSalarySlip, r.Get("company"), "") {
+		if rec.DocStatus == 0 && inPeriod(rec, month, year) {
+			rec.Status, rec.DocStatus = "Submitted", 1
+			submitted = append(submitted, map[string]any{"name": rec.Name, "employee": rec.Employee, "employee_name": s.employeeName(rec.Employee), "net_pay": rec.Fields["net_pay"]})
+		}
+	}
+	return map[string]any{"submitted_count": len(submitted), "error_count": 0, "submitted": submitted, "errors": []any{}}, nil
+}
+
+func (s *Server) generateSalarySlip(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	month, year := integer(r, "month"), integer(r, "year")
+	for _, rec := range s.recordsOf(DoctypeSalarySlip, "", e.ID) {
+		if rec.DocStatus != 2 && inPeriod(rec, month, year) {
+			return nil, Validation("Salary slip for %s already exists for %d-%02d", e.ID, year, month)
+		}
+	}
+	rec := s.putRecord(Record{Doctype: DoctypeSalarySlip, Employee: e.ID, Company: e.Company, Status: "Draft", Fields: salarySlipFields(year, month, 30000)})
+	return s.slipRow(rec, true), nil
+}
+
+// --- tax ---
+
+var deductionFields = []string{
+	"personal_allowance", "spouse_allowance", "life_insurance_premium",
+	"health_insurance_premium", "housing_loan_interest", "donation_deduction",
+}
+
+func (s *Server) deductionsOf(e *Employee) map[string]any {
+	d, ok := s.deductions[e.ID]
+	if !ok {
+		d = map[string]any{"tax_id": "", "personal_allowance": 60000.0, "spouse_allowance": 0.0, "children_count": 0}
+		for _, f := range deductionFields[2:] {
+			d[f] = 0.0
+		}
+		s.deductions[e.ID] = d
+	}
+	m := clone(d)
+	m["employee"] = e.ID
+	m["employee_name"] = e.Name
+	return m
+}
+
+func (s *Server) getTaxDeductions(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	return s.deductionsOf(e), nil
+}
+
+func (s *Server) updateTaxDeductions(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	s.deductionsOf(e)
+	d := s.deductions[e.ID]
+	if r.Params.Has("tax_id") {
+		d["tax_id"] = r.Get("tax_id")
+	}
+	if r.Params.Has("children_count") {
+		d["children_count"] = integer(r, "children_count")
+	}
+	for _, f := range deductionFields {
+		if r.Params.Has(f) {
+			d[f] = number(r, f)
+		}
+	}
+	return s.deductionsOf(e), nil
+}
+
+func monthlyTax() []map[string]any {
+	out := make([]map[string]any, 0, 12)
+	for m := 1; m <= 12; m++ {
+		out = append(out, map[string]any{"month": m, "monthly_withholding": 250.0, "taxable_income": 30000.0})
+	}
+	return out
+}
+
+func (s *Server) getTaxSummary(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"employee": e.ID, "year": integer(r, "year"), "monthly_data": monthlyTax(), "total_annual_tax": 3000.0}, nil
+}
+
+func (s *Server) getPND1(r *Request) (any, error) {
+	records := []map[string]any{}
+	for _, e := range s.activeEmployees(r.Get("company")) {
+		records = append(records, map[string]any{
+			"employee": e.ID, "employee_name": e.Name, "tax_id": s.deductionsOf(e)["tax_id"],
+			"monthly_income": 30000.0, "tax_withheld": 250.0,
+		})
+	}
+	return map[string]any{
+		"month": integer(r, "month"), "year": integer(r, "year"), "records": records,
+		"total_income": 30000.0 * float64(len(records)), "total_tax": 250.0 * float64(len(records)), "employee_count": len(records),
+	}, nil
+}
+
+func (s *Server) getWithholdingCert(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"employee": e.ID, "employee_name": e.Name, "tax_id": s.deductionsOf(e)["tax_id"], "year": integer(r, "year"),
+		"total_income": 360000.0, "total_deduction": 9000.0, "total_tax_withheld": 3000.0, "monthly_breakdown": monthlyTax(),
+	}, nil
+}
+
+// --- social security and provident fund ---
+
+func (s *Server) employeeField(e *Employee, key string, fallback any) any {
+	if v, ok := e.Fields[key]; ok {
+		return v
+	}
+	return fallback
+}
+
+func (s *Server) setEmployeeField(e *Employee, key string, v any) {
+	if e.Fields == nil {
+		e.Fields = map[string]any{}
+	}
+	e.Fields[key] = v
+}
+
+func (s *Server) getEmployeeSSO(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"employee": e.ID, "sso_number": s.employeeField(e, "sso_number", "")}, nil
+}
+
+func (s *Server) updateEmployeeSSO(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	s.setEmployeeField(e, "sso_number", r.Get("sso_number"))
+	return map[string]any{"employee": e.ID, "sso_number": r.Get("sso_number")}, nil
+}
+
+// getSSOReport has every active employee on a 30,000 base salary, capped by the settings.
+func (s *Server) getSSOReport(r *Request) (any, error) {
+	cfg := s.setting("sso", r.Get("company"))
+	contribution := min(min(30000.0, cfg["max_salary"].(float64))*cfg["rate"].(float64)/100, cfg["max_contribution"].(float64))
+	rows := []map[string]any{}
+	for _, e := range s.activeEmployees(r.Get("company")) {
+		rows = append(rows, map[string]any{
+			"employee": e.ID, "employee_name": e.Name, "base_salary": 30000.0,
+			"employee_contribution": contribution, "employer_contribution": contribution,
+		})
+	}
+	total := contribution * float64(len(rows))
+	return map[string]any{
+		"month": integer(r, "month"), "year": integer(r, "year"), "employees": rows,
+		"total_employee_contribution": total, "total_employer_contribution": total, "employee_count": len(rows),
+	}, nil
+}
+
+func (s *Server) getEmployeePVD(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"employee": e.ID, "employee_name": e.Name,
+		"pvd_enrolled":        s.employeeField(e, "pvd_enrolled", false),
+		"pvd_employee_rate":   s.employeeField(e, "pvd_employee_rate", 0.0),
+		"pvd_employer_rate":   s.employeeField(e, "pvd_employer_rate", 0.0),
+		"pvd_enrollment_date": s.employeeField(e, "pvd_enrollment_date", nil),
+	}, nil
+}
+
+// pvdRate is the rate param key, or fallback if it is not given, checked against the
+// settings of e's company.
+func (s *Server) pvdRate(r *Request, e *Employee, key string, fallback float64) (float64, error) {
+	rate := fallback
+	if r.Get(key) != "" {
+		rate = number(r, key)
+	}
+	cfg := s.setting("pvd", e.Company)
+	if rate < cfg["min_rate"].(float64) || rate > cfg["max_rate"].(float64) {
+		return 0, Validation("%s must be between %v%% and %v%%", key, cfg["min_rate"], cfg["max_rate"])
+	}
+	return rate, nil
+}
+
+func (s *Server) enrollEmployeePVD(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	cfg := s.setting("pvd", e.Company)
+	employeeRate, err := s.pvdRate(r, e, "employee_rate", cfg["default_employee_rate"].(float64))
+	if err != nil {
+		return nil, err
+	}
+	employerRate, err := s.pvdRate(r, e, "employer_rate", cfg["default_employer_rate"].(float64))
+	if err != nil {
+		return nil, err
+	}
+	s.setEmployeeField(e, "pvd_enrolled", true)
+	s.setEmployeeField(e, "pvd_employee_rate", employeeRate)
+	s.setEmployeeField(e, "pvd_employer_rate", employerRate)
+	s.setEmployeeField(e, "pvd_enrollment_date", "2026-03-02")
+	return map[string]any{
+		"employee": e.ID, "pvd_enrolled": true, "pvd_employee_rate": employeeRate, "pvd_employer_rate": employerRate,
+		"pvd_enrollment_date": "2026-03-02",
+	}, nil
+}
+
+func (s *Server) updateEmployeePVD(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"employee_rate", "employer_rate"} {
+		if !r.Params.Has(key) {
+			continue
+		}
+		rate, err := s.pvdRate(r, e, key, 0)
+		if err != nil {
+			return nil, err
+		}
+		s.setEmployeeField(e, "pvd_"+key, rate)
+	}
+	return map[string]any{
+		"employee": e.ID, "pvd_enrolled": s.employeeField(e, "pvd_enrolled", false),
+		"pvd_employee_rate": s.employeeField(e, "pvd_employee_rate", 0.0), "pvd_employer_rate": s.employeeField(e, "pvd_employer_rate", 0.0),
+	}, nil
+}
+
+func (s *Server) unenrollEmployeePVD(r *Request) (any, error) {
+	e, err := s.requireEmployee(r.Get("employee_id"))
+	if err != nil {
+		return nil, err
+	}
+	s.setEmployeeField(e, "pvd_enrolled", false)
+	s.setEmployeeField(e, "pvd_employee_rate", 0.0)
+	s.setEmployeeField(e, "pvd_employer_rate", 0.0)
+	return map[string]any{"employee": e.ID, "pvd_enrolled": false}, nil
+}
+
+// getPVDReport has every enrolled employee on a 30,000 base salary.
+func (s *Server) getPVDReport(r *Request) (any, error) {
+	rows := []map[string]any{}
+	var totalEmployee, totalEmployer float64
+	for _, e := range s.activeEmployees(r.Get("company")) {
+		if enrolled, _ := s.employeeField(e, "pvd_enrolled", false).(bool); !enrolled {
+			continue
+		}
+		employeeRate, _ := s.employeeField(e, "pvd_employee_rate", 0.0).(float64)
+		employerRate, _ := s.employeeField(e, "pvd_employer_rate", 0.0).(float64)
+		rows = append(rows, map[string]any{
+			"employee": e.ID, "employee_name": e.Name, "employee_rate": employeeRate, "employer_rate": employerRate,
+			"employee_contribution": 300 * employeeRate, "employer_contribution": 300 * employerRate,
+		})
+		totalEmployee += 300 * employeeRate
+		totalEmployer += 300 * employerRate
+	}
+	return map[string]any{
+		"month": integer(r, "month"), "year": integer(r, "year"), "employees": rows,
+		"total_employee_contribution": totalEmployee, "total_employer_contribution": totalEmployer, "enrolled_count": len(rows),
+	}, nil
+}
+
+// --- reports ---
+
+func (s *Server) getEmployeeSummary(r *Request) (any, error) {
+	counts := map[string][2]int{}
+	total, active := 0, 0
+	for _, e := range s.employees {
+		if c := r.Get("company"); c != "" && e.Company != c {
+			continue
+		}
+		dept := e.Department
+		if dept == "" {
+			dept = "Unassigned"
+		}
+		n := counts[dept]
+		n[0]++
+		total++
+		if e.Status == "Active" {
+			n[1]++
+			active++
+		}
+		counts[dept] = n
+	}
+	departments := []map[string]any{}
+	for _, dept := range sortedKeys(counts) {
+		departments = append(departments, map[string]any{"department": dept, "total": counts[dept][0], "active": counts[dept][1]})
+	}
+	return map[string]any{
+		"total_employees": total, "active_employees": active, "inactive_employees": total - active,
+		"turnover_rate": 0.0, "left_this_year": 0, "departments": departments,
+	}, nil
+}
+
+// attendanceTotals gives every active employee 20 days present and one on leave.
+func (s *Server) attendanceTotals(company string) []map[string]any {
+	rows := []map[string]any{}
+	for _, e := range s.activeEmployees(company) {
+		rows = append(rows, map[string]any{
+			"employee": e.ID, "employee_name": e.Name, "present": 20, "absent": 0, "half_day": 0, "on_leave": 1,
+			"late": 1, "early_exit": 0, "total_hours": 170.0,
+		})
+	}
+	return rows
+}
+
+func (s *Server) getAttendanceReport(r *Request) (any, error) {
+	rows := s.attendanceTotals(r.Get("company"))
+	return map[string]any{
+		"month": integer(r, "month"), "year": integer(r, "year"), "working_days": 31, "employees": rows, "total_records": 21 * len(rows),
+	}, nil
+}
+
+// leaveTotals gives every active employee three days of annual leave.
+func (s *Server) leaveTotals(company string) []map[string]any {
+	rows := []map[string]any{}
+	for _, e := range s.activeEmployees(company) {
+		rows = append(rows, map[string]any{"employee": e.ID, "employee_name": e.Name, "total_days": 3.0, "count": 1})
+	}
+	return rows
+}
+
+func (s *Server) getLeaveReport(r *Request) (any, error) {
+	rows := s.leaveTotals(r.Get("company"))
+	return map[string]any{
+		"year":               integer(r, "year"),
+		"by_type":            []map[string]any{{"leave_type": "Annual Leave", "total_days": 3.0 * float64(len(rows)), "count": len(rows)}},
+		"by_employee":        rows,
+		"total_applications": len(rows),
+		"total_days":         3.0 * float64(len(rows)),
+	}, nil
+}
+
+func (s *Server) getPayrollReport(r *Request) (any, error) {
+	month, year := integer(r, "month"), integer(r, "year")
+	m := map[string]any{"year": year, "month": nil, "total_slips": 0, "draft_count": 0, "submitted_count": 0}
+	if month != 0 {
+		m["month"] = month
+	}
+	var gross, deduction, net float64
+	for _, rec := range s.recordsOf(DoctypeSalarySlip, r.Get("company"), "") {
+		if rec.DocStatus == 2 || !inPeriod(rec, month, year) {
+			continue
+		}
+		m["total_slips"] = m["total_slips"].(int) + 1
+		if rec.DocStatus == 0 {
+			m["draft_count"] = m["draft_count"].(int) + 1
+		} else {
+			m["submitted_count"] = m["submitted_count"].(int) + 1
+		}
+		g, _ := rec.Fields["gross_pay"].(float64)
+		d, _ := rec.Fields["total_deduction"].(float64)
+		n, _ := rec.Fields["net_pay"].(float64)
+		gross, deduction, net = gross+g, deduction+d, net+n
+	}
+	m["total_gross"], m["total_deduction"], m["total_net"] = gross, deduction, net
+	return m, nil
+}
+
+// getTaxReport is get_pnd1_data for a month, or its totals for each month of the year.
+func (s *Server) getTaxReport(r *Request) (any, error) {
+	if r.Get("month") != "" {
+		return s.getPND1(r)
+	}
+	employees := len(s.activeEmployees(r.Get("company")))
+	totals := []map[string]any{}
+	for m := 1; m <= 12; m++ {
+		totals = append(totals, map[string]any{
+			"month": m, "total_income": 30000.0 * float64(employees), "total_tax": 250.0 * float64(employees), "employee_count": employees,
+		})
+	}
+	return map[string]any{
+		"year": integer(r, "year"), "monthly_totals": totals,
+		"annual_income": 360000.0 * float64(employees), "annual_tax": 3000.0 * float64(employees),
+	}, nil
+}
+
+func (s *Server) exportReportCSV(r *Request) (any, error) {
+	var headers []string
+	rows := [][]any{}
+	switch kind := r.Get("report_type"); kind {
+	case "employee":
+		summary, _ := s.getEmployeeSummary(r)
+		headers = []string{"Department", "Total", "Active"}
+		for _, d := range summary.(map[string]any)["departments"].([]map[string]any) {
+			rows = append(rows, []any{d["department"], d["total"], d["active"]})
+		}
+	case "attendance":
+		headers = []string{"Employee", "Name", "Present", "Absent", "Half Day", "Late", "Early Exit", "Total Hours"}
+		for _, e := range s.attendanceTotals(r.Get("company")) {
+			rows = append(rows, []any{e["employee"], e["employee_name"], e["present"], e["absent"], e["half_day"], e["late"], e["early_exit"], e["total_hours"]})
+		}
+	case "leave":
+		headers = []string{"Employee", "Name", "Total Days", "Count"}
+		for _, e := range s.leaveTotals(r.Get("company")) {
+			rows = append(rows, []any{e["employee"], e["employee_name"], e["total_days"], e["count"]})
+		}
+	case "payroll":
+		report, _ := s.getPayrollReport(r)
+		p := report.(map[string]any)
+		headers = []string{"Total Slips", "Draft", "Submitted", "Total Gross", "Total Deduction", "Total Net"}
+		rows = append(rows, []any{p["total_slips"], p["draft_count"], p["submitted_count"], p["total_gross"], p["total_deduction"], p["total_net"]})
+	default:
+		return nil, Validation("Unknown report type: %s", kind)
+	}
+	return map[string]any{"headers": headers, "rows": rows, "report_type": r.Get("report_type")}, nil
+}
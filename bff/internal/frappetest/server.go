@@ -0,0 +1,392 @@
+// Package frappetest runs an in-process fake of the Frappe site behind the BFF. It
+// answers the hr_core_ext.api.* methods the BFF calls from in-memory fixtures, along
+// with upload_file and the private files it stores, and tests can script failures and
+// slow responses per method with Inject.
+package frappetest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"hr-platform/bff/internal/client"
+)
+
+// Credentials the fake accepts; Client uses them.
+const (
+	APIKey    = "test-key"
+	APISecret = "test-secret"
+)
+
+// HandlerFunc answers one Frappe method. The value it returns is sent as "message".
+// It runs with the server locked, so it may read and change fixtures directly.
+type HandlerFunc func(r *Request) (any, error)
+
+// Request is a call to a whitelisted method, with the query string or form body as Params.
+type Request struct {
+	Method string
+	Post   bool
+	Params url.Values
+	// Files holds the files of a multipart body, by field name.
+	Files map[string]File
+}
+
+// File is a file uploaded in a multipart body.
+type File struct {
+	FileName string
+	Content  []byte
+}
+
+// Get returns the first value of the param key.
+func (r *Request) Get(key string) string {
+	return r.Params.Get(key)
+}
+
+// Call is a request the server received, recorded in order.
+type Call struct {
+	Method     string
+	HTTPMethod string
+	Params     url.Values
+}
+
+// Fault makes calls to a method fail or stall. A zero Status with a Delay only slows
+// the call down; Times limits how many calls it applies to, zero meaning all of them.
+type Fault struct {
+	Status  int
+	Message string
+	Delay   time.Duration
+	Times   int
+}
+
+// Server is a fake Frappe site. The zero value is not usable; call New.
+type Server struct {
+	URL string
+
+	srv *httptest.Server
+
+	mu          sync.Mutex
+	companies   []string
+	employees   []*Employee
+	records     []*Record
+	documents   []*Document
+	departments []*Department
+	settings    map[string]map[string]any
+	deductions  map[string]map[string]any
+	handlers    map[string]HandlerFunc
+	faults      map[string][]*Fault
+	calls       []Call
+	seq         int
+}
+
+// New starts a server loaded with the fixtures described in fixtures.go.
+// Close it when the test is done.
+func New() *Server {
+	s := &Server{
+		settings:   map[string]map[string]any{},
+		deductions: map[string]map[string]any{},
+		faults:     map[string][]*Fault{},
+	}
+	s.handlers = s.methods()
+	s.seed()
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a Frappe client for the server, built by client.NewFrappeClient with opts.
+func (s *Server) Client(opts client.Options) *client.FrappeClient {
+	return client.NewFrappeClient(s.URL, APIKey, APISecret, opts)
+}
+
+// Handle replaces the handler of method, or adds one the fake does not implement.
+func (s *Server) Handle(method string, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = fn
+}
+
+// Respond makes method always answer v.
+func (s *Server) Respond(method string, v any) {
+	s.Handle(method, func(*Request) (any, error) { return v, nil })
+}
+
+// Inject adds a fault to method, or to every method if method is "*". Faults on the
+// same method apply in the order they were added.
+func (s *Server) Inject(method string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[method] = append(s.faults[method], &f)
+}
+
+// ClearFaults removes every injected fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = map[string][]*Fault{}
+}
+
+// Calls returns the calls made to method, or every call if method is empty.
+func (s *Server) Calls(method string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Call
+	for _, c := range s.calls {
+		if method == "" || c.Method == method {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// ResetCalls forgets the calls recorded so far.
+func (s *Server) ResetCalls() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/method/login" && r.Header.Get("Authorization") != "token "+APIKey+":"+APISecret {
+		writeError(w, &Error{Status: http.StatusUnauthorized, Exception: "frappe.exceptions.AuthenticationError", Message: "Invalid API key or secret"})
+		return
+	}
+	files, err := parseForm(r)
+	if err != nil {
+		writeError(w, Validation("malformed request: %v", err))
+		return
+	}
+
+	var name string
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/private/files/"):
+		name = "download_file"
+	case strings.HasPrefix(r.URL.Path, "/api/method/"):
+		name = strings.TrimPrefix(r.URL.Path, "/api/method/")
+	case strings.HasPrefix(r.URL.Path, "/api/resource/"):
+		doctype, _ := url.PathUnescape(strings.TrimPrefix(r.URL.Path, "/api/resource/"))
+		name = "resource:" + doctype
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	req := &Request{Method: name, Post: r.Method == http.MethodPost, Params: r.Form, Files: files}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, Call{Method: name, HTTPMethod: r.Method, Params: r.Form})
+	fault := s.takeFault(name)
+	fn := s.handlers[name]
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Delay > 0 {
+			if err := wait(r.Context(), fault.Delay); err != nil {
+				return
+			}
+		}
+		if fault.Status != 0 {
+			msg := fault.Message
+			if msg == "" {
+				msg = "injected failure"
+			}
+			writeError(w, &Error{Status: fault.Status, Exception: "frappe.exceptions.ValidationError", Message: msg})
+			return
+		}
+	}
+
+	if name == "download_file" {
+		s.mu.Lock()
+		d := s.documentAt(r.URL.Path)
+		s.mu.Unlock()
+		if d == nil {
+			writeError(w, NotFound("File", r.URL.Path))
+			return
+		}
+		w.Header().Set("Content-Type", http.DetectContentType(d.Content))
+		w.Header().Set("Content-Length", strconv.Itoa(len(d.Content)))
+		_, _ = w.Write(d.Content)
+		return
+	}
+
+	if strings.HasPrefix(name, "resource:") {
+		s.mu.Lock()
+		data, err := s.resource(strings.TrimPrefix(name, "resource:"), req)
+		s.mu.Unlock()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+		return
+	}
+
+	if fn == nil {
+		writeError(w, &Error{Status: http.StatusNotFound, Exception: "frappe.exceptions.DoesNotExistError", Message: "Method " + name + " not found"})
+		return
+	}
+	s.mu.Lock()
+	msg, err := fn(req)
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"message": msg})
+}
+
+// parseForm parses the query string and a urlencoded or multipart body into r.Form,
+// and returns a multipart body's files.
+func parseForm(r *http.Request) (map[string]File, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return nil, r.ParseForm()
+	}
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return nil, err
+	}
+	files := map[string]File{}
+	for field, headers := range r.MultipartForm.File {
+		f, err := headers[0].Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[field] = File{FileName: headers[0].Filename, Content: content}
+	}
+	return files, nil
+}
+
+// takeFault returns the next fault for method, counting it against its Times.
+func (s *Server) takeFault(method string) *Fault {
+	for _, key := range []string{method, "*"} {
+		list := s.faults[key]
+		if len(list) == 0 {
+			continue
+		}
+		f := list[0]
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults[key] = list[1:]
+			}
+		}
+		copied := *f
+		return &copied
+	}
+	return nil
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Error is a Frappe exception. A HandlerFunc returns one to fail the call the way
+// Frappe would; any other error is reported as a 500.
+type Error struct {
+	Status    int
+	Exception string
+	Message   string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Validation is what frappe.throw raises: HTTP 417 with the message shown to the user.
+func Validation(format string, args ...any) *Error {
+	return &Error{Status: http.StatusExpectationFailed, Exception: "frappe.exceptions.ValidationError", Message: fmt.Sprintf(format, args...)}
+}
+
+// NotFound is frappe.DoesNotExistError for a missing document.
+func NotFound(doctype, name string) *Error {
+	return &Error{Status: http.StatusNotFound, Exception: "frappe.exceptions.DoesNotExistError", Message: fmt.Sprintf("%s %s not found", doctype, name)}
+}
+
+// Forbidden is frappe.PermissionError: HTTP 403 with the message shown to the user.
+func Forbidden(format string, args ...any) *Error {
+	return &Error{Status: http.StatusForbidden, Exception: "frappe.exceptions.PermissionError", Message: fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	fe, ok := err.(*Error)
+	if !ok {
+		fe = &Error{Status: http.StatusInternalServerError, Exception: "Exception", Message: err.Error()}
+	}
+	msg, _ := json.Marshal(map[string]string{"message": fe.Message})
+	serverMessages, _ := json.Marshal([]string{string(msg)})
+	writeJSON(w, fe.Status, map[string]any{
+		"exc_type":         fe.Exception[strings.LastIndex(fe.Exception, ".")+1:],
+		"exception":        fe.Exception + ": " + fe.Message,
+		"_server_messages": string(serverMessages),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// resource serves /api/resource/<doctype> lists for the doctypes the BFF looks up.
+func (s *Server) resource(doctype string, r *Request) (any, error) {
+	filters := map[string]string{}
+	if f := r.Get("filters"); f != "" {
+		if err := json.Unmarshal([]byte(f), &filters); err != nil {
+			return nil, Validation("filters must be a JSON object")
+		}
+	}
+	rows := []map[string]any{}
+	switch doctype {
+	case "Company":
+		for _, c := range s.companies {
+			if name, ok := filters["company_name"]; !ok || name == c {
+				rows = append(rows, map[string]any{"name": c})
+			}
+		}
+	case "Employee":
+		for _, e := range s.employees {
+			if match(filters, "employee_name", e.Name) && match(filters, "company", e.Company) {
+				rows = append(rows, map[string]any{"name": e.ID})
+			}
+		}
+	default:
+		return nil, &Error{Status: http.StatusForbidden, Exception: "frappe.exceptions.PermissionError", Message: "No permission for " + doctype}
+	}
+	return rows, nil
+}
+
+func match(filters map[string]string, key, value string) bool {
+	want, ok := filters[key]
+	return !ok || want == value
+}
+
+// login answers /api/method/login, which is not behind the API key.
+func (s *Server) login(r *Request) (any, error) {
+	if r.Get("usr") == "" || r.Get("pwd") == "" {
+		return nil, &Error{Status: http.StatusUnauthorized, Exception: "frappe.exceptions.AuthenticationError", Message: "Incorrect password"}
+	}
+	return "Logged In", nil
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"hr-platform/bff/internal/auth"
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxAPIKeyDays caps how long a key can be issued for.
+const maxAPIKeyDays = 365
+
+type APIKeyHandler struct {
+	apiKeyRepo *repository.APIKeyRepository
+	auditRepo  *repository.AuditRepository
+}
+
+func NewAPIKeyHandler(apiKeyRepo *repository.APIKeyRepository, auditRepo *repository.AuditRepository) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyRepo: apiKeyRepo, auditRepo: auditRepo}
+}
+
+// Create issues a key owned by the caller. The key acts with the caller's role, limited to
+// the requested scopes, and is only shown in this response.
+func (h *APIKeyHandler) Create(c echo.Context) error {
+	var req model.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		return echo.NewHTTPError(http.StatusBadRequest, "name is required (max 100 characters)")
+	}
+	if len(req.Scopes) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "at least one scope is required")
+	}
+	seen := map[string]bool{}
+	scopes := []string{}
+	for _, s := range req.Scopes {
+		if !model.ValidAPIScope(s) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid scope: "+s)
+		}
+		if !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	sort.Strings(scopes)
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxAPIKeyDays {
+		return echo.NewHTTPError(http.StatusBadRequest, "expires_in_days must be between 0 and 365")
+	}
+
+	secret, err := auth.NewOpaqueToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate key")
+	}
+	raw := model.APIKeyPrefix + secret
+
+	ctx := c.Request().Context()
+	userID := c.Get("user_id").(string)
+	companyID := c.Get("company_id").(string)
+
+	key := &model.APIKey{
+		CompanyID: companyID,
+		UserID:    userID,
+		Name:      req.Name,
+		KeyPrefix: raw[:len(model.APIKeyPrefix)+6],
+		KeyHash:   auth.HashToken(raw),
+		Scopes:    strings.Join(scopes, ","),
+	}
+	if req.ExpiresInDays > 0 {
+		exp := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &exp
+	}
+	if err := h.apiKeyRepo.Create(ctx, key); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create api key")
+	}
+
+	_ = h.auditRepo.Log(ctx, userID, companyID, "api_key.created", "api_key", key.ID, map[string]interface{}{
+		"name":       key.Name,
+		"scopes":     scopes,
+		"expires_at": key.ExpiresAt,
+	})
+
+	resp := apiKeyToResponse(key)
+	resp.Key = raw
+	return c.JSON(http.StatusCreated, map[string]interface{}{"data": resp})
+}
+
+// List returns the caller's keys, or every key in the company for admins.
+func (h *APIKeyHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var (
+		keys []model.APIKey
+		err  error
+	)
+	if hasPermission(c, model.PermCompanySecurity) {
+		keys, err = h.apiKeyRepo.ListByCompany(ctx, c.Get("company_id").(string))
+	} else {
+		keys, err = h.apiKeyRepo.ListByUser(ctx, c.Get("user_id").(string))
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to list api keys")
+	}
+
+	result := make([]model.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		result = append(result, apiKeyToResponse(&keys[i]))
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": result})
+}
+
+// Revoke disables a key. Owners can revoke their own keys; admins can revoke any key in the company.
+func (h *APIKeyHandler) Revoke(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := c.Get("user_id").(string)
+	companyID := c.Get("company_id").(string)
+
+	key, err := h.apiKeyRepo.GetByID(ctx, c.Param("id"))
+	if err != nil || key.CompanyID != companyID {
+		return echo.NewHTTPError(http.StatusNotFound, "api key not found")
+	}
+	if key.UserID != userID && !hasPermission(c, model.PermCompanySecurity) {
+		return echo.NewHTTPError(http.StatusNotFound, "api key not found")
+	}
+
+	if err := h.apiKeyRepo.Revoke(ctx, key.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, "api key not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke api key")
+	}
+
+	_ = h.auditRepo.Log(ctx, userID, companyID, "api_key.revoked", "api_key", key.ID, map[string]string{
+		"owner_id": key.UserID,
+	})
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "api key revoked"})
+}
+
+func apiKeyToResponse(k *model.APIKey) model.APIKeyResponse {
+	return model.APIKeyResponse{APIKey: *k, ScopeNames: k.ScopeList()}
+}
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"hr-platform/bff/internal/client"
@@ -25,53 +24,37 @@ func (h *AttendanceHandler) Me(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "employee not linked to this user")
 	}
 
-	fromDate := c.QueryParam("from_date")
-	toDate := c.QueryParam("to_date")
-
-	params := map[string]string{
-		"employee_id": employeeID,
-	}
-	if fromDate != "" {
-		params["from_date"] = fromDate
-	}
-	if toDate != "" {
-		params["to_date"] = toDate
-	}
-
-	data, err := h.frappe.CallMethod("hr_core_ext.api.attendance.get_attendance_summary", params)
+	attendance, err := tenantFrappe(c, h.frappe).Attendance().Summary(c.Request().Context(), employeeID, queryDateRange(c))
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch attendance")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": attendance,
 	})
 }
 
-// List returns attendance for all employees (admin/HR/manager).
+// List returns attendance for an employee (admin/HR/manager). Managers are limited to their reporting chain.
 func (h *AttendanceHandler) List(c echo.Context) error {
-	fromDate := c.QueryParam("from_date")
-	toDate := c.QueryParam("to_date")
 	employeeID := c.QueryParam("employee_id")
-
-	params := map[string]string{}
-	if employeeID != "" {
-		params["employee_id"] = employeeID
+	if employeeID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "employee_id is required")
 	}
-	if fromDate != "" {
-		params["from_date"] = fromDate
+
+	if err := requireInTeam(c, h.frappe, employeeID); err != nil {
+		return err
 	}
-	if toDate != "" {
-		params["to_date"] = toDate
+	if err := verifyTenantEmployee(c, h.frappe, employeeID); err != nil {
+		return err
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.attendance.get_attendance_summary", params)
+	attendance, err := tenantFrappe(c, h.frappe).Attendance().Summary(c.Request().Context(), employeeID, queryDateRange(c))
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch attendance")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": attendance,
 	})
 }
 
@@ -91,45 +74,39 @@ func (h *AttendanceHandler) CreateRequest(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "attendance_date and reason are required")
 	}
 
-	params := map[string]string{
-		"employee_id":     employeeID,
-		"attendance_date": req.AttendanceDate,
-		"reason":          req.Reason,
-	}
-	if req.Status != "" {
-		params["status"] = req.Status
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.attendance.create_attendance_request", params)
+	res, err := tenantFrappe(c, h.frappe).Attendance().CreateRequest(c.Request().Context(), employeeID, req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to create attendance request")
 	}
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": res,
 	})
 }
 
-// ListRequests returns attendance requests.
+// ListRequests returns a page of attendance requests.
 func (h *AttendanceHandler) ListRequests(c echo.Context) error {
 	employeeID := c.Get("employee_id").(string)
 	role := model.UserRole(c.Get("user_role").(string))
 
-	params := map[string]string{}
+	var filter client.AttendanceRequestFilter
 
-	// Employee can only see own requests
+	// Employee can only see own requests; managers see their reporting chain
 	if role == model.RoleEmployee && employeeID != "" {
-		params["employee_id"] = employeeID
+		filter.EmployeeID = employeeID
+	}
+
+	pg, scope, err := readTeamPage(c, h.frappe)
+	if err != nil {
+		return err
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.attendance.get_attendance_requests", params)
+	page, err := tenantFrappe(c, h.frappe).Attendance().RequestPage(c.Request().Context(), filter, pg)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch attendance requests")
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
-	})
+	return respondPage(c, pg, inScope(page.Rows, scope), page.Total)
 }
 
 // Checkin records an employee check-in.
@@ -139,15 +116,13 @@ func (h *AttendanceHandler) Checkin(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "employee not linked to this user")
 	}
 
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.attendance.checkin", map[string]string{
-		"employee_id": employeeID,
-	})
+	checkin, err := tenantFrappe(c, h.frappe).Attendance().Checkin(c.Request().Context(), employeeID)
 	if err != nil {
 		return frappeHTTPError(err, "failed to check in")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": checkin,
 	})
 }
 
@@ -158,15 +133,13 @@ func (h *AttendanceHandler) Checkout(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "employee not linked to this user")
 	}
 
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.attendance.checkout", map[string]string{
-		"employee_id": employeeID,
-	})
+	checkin, err := tenantFrappe(c, h.frappe).Attendance().Checkout(c.Request().Context(), employeeID)
 	if err != nil {
 		return frappeHTTPError(err, "failed to check out")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": checkin,
 	})
 }
 
@@ -177,15 +150,13 @@ func (h *AttendanceHandler) TodayCheckin(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "employee not linked to this user")
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.attendance.get_today_checkin", map[string]string{
-		"employee_id": employeeID,
-	})
+	today, err := tenantFrappe(c, h.frappe).Attendance().Today(c.Request().Context(), employeeID)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch today's check-in status")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": today,
 	})
 }
 
@@ -196,23 +167,13 @@ func (h *AttendanceHandler) CheckinHistory(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "employee not linked to this user")
 	}
 
-	params := map[string]string{
-		"employee_id": employeeID,
-	}
-	if fromDate := c.QueryParam("from_date"); fromDate != "" {
-		params["from_date"] = fromDate
-	}
-	if toDate := c.QueryParam("to_date"); toDate != "" {
-		params["to_date"] = toDate
-	}
-
-	data, err := h.frappe.CallMethod("hr_core_ext.api.attendance.get_checkin_history", params)
+	history, err := tenantFrappe(c, h.frappe).Attendance().History(c.Request().Context(), employeeID, queryDateRange(c))
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch check-in history")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": history,
 	})
 }
 
@@ -229,15 +190,21 @@ func (h *AttendanceHandler) ApproveRequest(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "action must be 'approve' or 'reject'")
 	}
 
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.attendance.approve_attendance_request", map[string]string{
-		"request_id": requestID,
-		"action":     req.Action,
-	})
+	if _, err := authorizeApproval(c, h.frappe, client.DoctypeAttendanceRequest, requestID); err != nil {
+		return err
+	}
+
+	res, err := tenantFrappe(c, h.frappe).Attendance().DecideRequest(c.Request().Context(), requestID, req.Action)
 	if err != nil {
 		return frappeHTTPError(err, "failed to process attendance request")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": res,
 	})
 }
+
+// queryDateRange reads the optional from_date and to_date query params.
+func queryDateRange(c echo.Context) client.DateRange {
+	return client.DateRange{From: c.QueryParam("from_date"), To: c.QueryParam("to_date")}
+}
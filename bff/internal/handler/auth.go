@@ -2,46 +2,82 @@ package handler
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	netmail "net/mail"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
 	"hr-platform/bff/internal/auth"
-	"hr-platform/bff/internal/client"
 	"hr-platform/bff/internal/config"
-	"hr-platform/bff/internal/middleware"
+	"hr-platform/bff/internal/mail"
 	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/provisioning"
 	"hr-platform/bff/internal/repository"
+	"hr-platform/bff/internal/signing"
 
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	signupVerificationTTL = 24 * time.Hour
+	signupResendInterval  = time.Minute
+)
+
 type AuthHandler struct {
-	userRepo    *repository.UserRepository
-	companyRepo *repository.CompanyRepository
-	auditRepo   *repository.AuditRepository
-	frappe      *client.FrappeClient
-	cfg         *config.Config
+	userRepo         *repository.UserRepository
+	companyRepo      *repository.CompanyRepository
+	sessionRepo      *repository.SessionRepository
+	mfaRepo          *repository.MFARepository
+	throttleRepo     *repository.LoginThrottleRepository
+	auditRepo        *repository.AuditRepository
+	signupRepo       *repository.SignupRepository
+	provisioningRepo *repository.ProvisioningRepository
+	provisioner      *provisioning.Runner
+	mailer           mail.Sender
+	keys             *signing.KeySet
+	cfg              *config.Config
 }
 
 func NewAuthHandler(
 	userRepo *repository.UserRepository,
 	companyRepo *repository.CompanyRepository,
+	sessionRepo *repository.SessionRepository,
+	mfaRepo *repository.MFARepository,
+	throttleRepo *repository.LoginThrottleRepository,
 	auditRepo *repository.AuditRepository,
-	frappe *client.FrappeClient,
+	signupRepo *repository.SignupRepository,
+	provisioningRepo *repository.ProvisioningRepository,
+	provisioner *provisioning.Runner,
+	mailer mail.Sender,
+	keys *signing.KeySet,
 	cfg *config.Config,
 ) *AuthHandler {
 	return &AuthHandler{
-		userRepo:    userRepo,
-		companyRepo: companyRepo,
-		auditRepo:   auditRepo,
-		frappe:      frappe,
-		cfg:         cfg,
+		userRepo:         userRepo,
+		companyRepo:      companyRepo,
+		sessionRepo:      sessionRepo,
+		mfaRepo:          mfaRepo,
+		throttleRepo:     throttleRepo,
+		auditRepo:        auditRepo,
+		signupRepo:       signupRepo,
+		provisioningRepo: provisioningRepo,
+		provisioner:      provisioner,
+		mailer:           mailer,
+		keys:             keys,
+		cfg:              cfg,
 	}
 }
 
+// Login checks the password. If the user has MFA, or their company requires it for their
+// role, it returns an mfa_required challenge instead of a token (see MFAHandler.Verify).
+//
+// Attempts are throttled per account and per client IP (see throttle.go). Unknown emails,
+// wrong passwords and inactive accounts all get the same "invalid credentials" response
+// so the endpoint cannot be used to discover accounts.
 func (h *AuthHandler) Login(c echo.Context) error {
 	var req model.LoginRequest
 	if err := c.Bind(&req); err != nil {
@@ -52,48 +88,116 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "email and password are required")
 	}
 
-	user, err := h.userRepo.GetByEmail(c.Request().Context(), req.Email)
+	ctx := c.Request().Context()
+	accountKey := accountThrottleKey(req.Email)
+	ipKey := ipThrottleKey(c.RealIP())
+
+	wait, err := throttleWait(ctx, h.throttleRepo, accountKey, ipKey)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusUnauthorized, "invalid credentials")
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to check login attempts")
+	}
+	if wait > 0 {
+		return tooManyAttempts(c, wait)
 	}
 
-	if user.Status != model.StatusActive {
-		return echo.NewHTTPError(http.StatusUnauthorized, "account is not active")
+	user, err := h.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		auth.CheckPassword("", req.Password)
+		return h.loginFailed(c, nil, req.Email, "unknown_email")
 	}
 
 	if !auth.CheckPassword(user.PasswordHash, req.Password) {
-		return echo.NewHTTPError(http.StatusUnauthorized, "invalid credentials")
+		return h.loginFailed(c, user, req.Email, "bad_password")
+	}
+
+	if user.Status != model.StatusActive {
+		return h.loginFailed(c, user, req.Email, "inactive")
 	}
 
+	_ = h.throttleRepo.Clear(ctx, accountKey)
+
 	company, err := h.companyRepo.GetByID(c.Request().Context(), user.CompanyID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
 	}
 
-	token, err := h.generateToken(user)
+	if user.MFAEnabled || company.RequiresMFA(user.Role) {
+		challenge, err := createMFAChallenge(c.Request().Context(), h.mfaRepo, user, false)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to start mfa challenge")
+		}
+		return c.JSON(http.StatusOK, challenge)
+	}
+
+	token, refreshToken, err := startSession(c, h.keys, h.cfg, h.sessionRepo, user)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
 	}
 
 	_ = h.userRepo.UpdateLastLogin(c.Request().Context(), user.ID)
 
-	h.setTokenCookie(c, token)
+	setAuthCookies(c, h.cfg, token, refreshToken)
 
 	return c.JSON(http.StatusOK, model.LoginResponse{
-		Token: token,
-		User:  userToInfo(user, company.Name),
+		Token:        token,
+		RefreshToken: refreshToken,
+		User:         userToInfo(user, company.Name),
 	})
 }
 
+// loginFailed records a failed attempt against the account and IP counters, audits it
+// and returns the uniform error.
+func (h *AuthHandler) loginFailed(c echo.Context, user *model.User, email, reason string) error {
+	ctx := c.Request().Context()
+	ip := c.RealIP()
+
+	failures, err := recordThrottleFailure(ctx, h.throttleRepo, accountThrottleKey(email), accountThrottle)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to record login attempt")
+	}
+	if _, err := recordThrottleFailure(ctx, h.throttleRepo, ipThrottleKey(ip), ipThrottle); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to record login attempt")
+	}
+	_ = h.throttleRepo.DeleteStale(ctx, throttleWindow)
+
+	var userID, companyID string
+	if user != nil {
+		userID, companyID = user.ID, user.CompanyID
+	}
+	_ = h.auditRepo.Log(ctx, "", companyID, "auth.login_failed", "user", userID, map[string]interface{}{
+		"email":      email,
+		"ip_address": ip,
+		"reason":     reason,
+		"failures":   failures,
+	})
+	if user != nil && failures == accountThrottle.lockAt {
+		_ = h.auditRepo.Log(ctx, "", companyID, "user.locked", "user", userID, map[string]interface{}{
+			"ip_address":   ip,
+			"locked_until": time.Now().Add(accountThrottle.lockout),
+		})
+	}
+
+	return echo.NewHTTPError(http.StatusUnauthorized, "invalid credentials")
+}
+
+// Signup validates a new company and its admin, then emails a verification link instead of
+// creating anything. Nothing is provisioned, here or in Frappe, until VerifyEmail, so an
+// unverified address cannot claim a company name. Signing up again with the same email
+// replaces the earlier request.
 func (h *AuthHandler) Signup(c echo.Context) error {
 	var req model.SignupRequest
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
+	req.Email = strings.TrimSpace(req.Email)
+	req.CompanyName = strings.TrimSpace(req.CompanyName)
 	if req.CompanyName == "" || req.Email == "" || req.Password == "" || req.FullName == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "company_name, email, password, and full_name are required")
 	}
+	if addr, err := netmail.ParseAddress(req.Email); err != nil || addr.Address != req.Email {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid email address")
+	}
 
 	if len(req.Password) < 8 {
 		return echo.NewHTTPError(http.StatusBadRequest, "password must be at least 8 characters")
@@ -111,71 +215,141 @@ func (h *AuthHandler) Signup(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusConflict, "company name already taken")
 	}
 
-	// Create company in BFF DB
-	company := &model.Company{
-		Name:     req.CompanyName,
-		Slug:     slugify(req.CompanyName),
-		Industry: req.Industry,
-		Size:     req.Size,
-	}
-	if err := h.companyRepo.Create(ctx, company); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create company")
+	pending := func() error {
+		return c.JSON(http.StatusAccepted, map[string]interface{}{
+			"message":               "check your email to finish signing up",
+			"verification_required": true,
+		})
 	}
 
-	// Create company in Frappe
-	abbr := abbreviate(req.CompanyName)
-	frappeCompanyName, err := h.frappe.CreateCompany(req.CompanyName, abbr, "Thailand")
-	if err != nil {
-		frappeCompanyName = req.CompanyName
+	_ = h.signupRepo.DeleteExpired(ctx, signupVerificationTTL)
+	// Repeated submits within a minute reuse the link already sent rather than mailing again
+	if prev, err := h.signupRepo.GetByEmail(ctx, req.Email); err == nil && time.Since(prev.CreatedAt) < signupResendInterval {
+		return pending()
 	}
-	company.FrappeCompanyName = frappeCompanyName
-	_ = h.companyRepo.Update(ctx, company)
 
-	// Hash password
 	hash, err := auth.HashPassword(req.Password)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to process password")
 	}
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
+	}
 
-	// Create admin user
-	user := &model.User{
+	signup := &model.PendingSignup{
 		Email:        req.Email,
+		TokenHash:    auth.HashToken(token),
 		PasswordHash: hash,
 		FullName:     req.FullName,
+		CompanyName:  req.CompanyName,
+		Industry:     req.Industry,
+		Size:         req.Size,
+		Locale:       mail.Language(req.Locale),
+		ExpiresAt:    time.Now().Add(signupVerificationTTL),
+	}
+	if err := h.signupRepo.Save(ctx, signup); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to save signup")
+	}
+
+	msg, err := mail.Render("verify_email", signup.Locale, signup.Email, struct {
+		FullName    string
+		CompanyName string
+		Link        string
+		ExpiresAt   time.Time
+	}{
+		FullName:    signup.FullName,
+		CompanyName: signup.CompanyName,
+		Link:        fmt.Sprintf("%s/verify-email/%s", h.cfg.AppBaseURL, token),
+		ExpiresAt:   signup.ExpiresAt,
+	})
+	if err == nil {
+		err = h.mailer.Send(ctx, msg)
+	}
+	if err != nil {
+		log.Printf("verification email to %s failed: %v", signup.Email, err)
+		return echo.NewHTTPError(http.StatusBadGateway, "failed to send verification email")
+	}
+
+	return pending()
+}
+
+// VerifyEmail redeems a signup's verification link. The company, the admin account and
+// the company's provisioning jobs are committed in one transaction; the Frappe company and
+// the admin's employee record are then created by the provisioning runner. One attempt is
+// made before responding, and a Frappe failure does not fail the signup: the steps are
+// retried in the background and their progress is at GET /api/company/provisioning.
+func (h *AuthHandler) VerifyEmail(c echo.Context) error {
+	var req model.VerifyEmailRequest
+	if err := c.Bind(&req); err != nil || req.Token == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "token is required")
+	}
+
+	ctx := c.Request().Context()
+	signup, err := h.signupRepo.GetByTokenHash(ctx, auth.HashToken(req.Token))
+	if err != nil || time.Now().After(signup.ExpiresAt) {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid or expired verification link")
+	}
+
+	// Someone may have registered the email or the name since the link was sent
+	if _, err := h.userRepo.GetByEmail(ctx, signup.Email); err == nil {
+		return echo.NewHTTPError(http.StatusConflict, "email already registered")
+	}
+	if _, err := h.companyRepo.GetByName(ctx, signup.CompanyName); err == nil {
+		return echo.NewHTTPError(http.StatusConflict, "company name already taken; please sign up again with another name")
+	}
+
+	company := &model.Company{
+		Name:     signup.CompanyName,
+		Slug:     slugify(signup.CompanyName),
+		Industry: signup.Industry,
+		Size:     signup.Size,
+	}
+	user := &model.User{
+		Email:        signup.Email,
+		PasswordHash: signup.PasswordHash,
+		FullName:     signup.FullName,
 		Role:         model.RoleAdmin,
 		Status:       model.StatusActive,
-		CompanyID:    company.ID,
 	}
-	if err := h.userRepo.Create(ctx, user); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create user")
+	err = h.provisioningRepo.CreateTenant(ctx, signup.ID, company, user, model.SignupSteps)
+	if errors.Is(err, sql.ErrNoRows) {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid or expired verification link")
 	}
-
-	// Create employee in Frappe for admin
-	employeeID, err := h.frappe.CreateEmployee(req.FullName, frappeCompanyName, "", "")
-	if err == nil && employeeID != "" {
-		user.FrappeEmployeeID = &employeeID
-		_ = h.userRepo.LinkEmployee(ctx, user.ID, employeeID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create company")
 	}
 
-	_ = h.auditRepo.Log(ctx, user.ID, company.ID, "company.created", "company", company.ID, nil)
+	_ = h.auditRepo.Log(ctx, user.ID, company.ID, "company.created", "company", company.ID, map[string]string{
+		"verified_email": signup.Email,
+	})
 
-	token, err := h.generateToken(user)
+	if err := h.provisioner.Run(ctx, company.ID); err != nil {
+		log.Printf("provisioning company %s: %v", company.ID, err)
+	}
+	// Pick up the employee ID if provisioning got that far
+	if linked, err := h.userRepo.GetInCompany(ctx, user.ID, company.ID); err == nil {
+		user = linked
+	}
+
+	token, refreshToken, err := startSession(c, h.keys, h.cfg, h.sessionRepo, user)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
 	}
 
-	h.setTokenCookie(c, token)
+	setAuthCookies(c, h.cfg, token, refreshToken)
 
 	return c.JSON(http.StatusCreated, model.LoginResponse{
-		Token: token,
-		User:  userToInfo(user, company.Name),
+		Token:        token,
+		RefreshToken: refreshToken,
+		User:         userToInfo(user, company.Name),
 	})
 }
 
 func (h *AuthHandler) Me(c echo.Context) error {
 	userID := c.Get("user_id").(string)
 
-	user, err := h.userRepo.GetByID(c.Request().Context(), userID)
+	user, err := h.userRepo.GetInCompany(c.Request().Context(), userID, c.Get("company_id").(string))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return echo.NewHTTPError(http.StatusUnauthorized, "user not found")
@@ -188,60 +362,41 @@ func (h *AuthHandler) Me(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
 	}
 
-	return c.JSON(http.StatusOK, userToInfo(user, company.Name))
+	info := userToInfo(user, company.Name)
+	info.RoleName, _ = c.Get("role_name").(string)
+	if perms, ok := c.Get("permissions").(model.PermissionSet); ok {
+		info.Permissions = perms.List()
+	}
+	// An impersonating admin only sees the company they are impersonating in
+	if impersonatorID, _ := c.Get("impersonator_id").(string); impersonatorID == "" {
+		if info.Memberships, err = h.userRepo.ListMemberships(c.Request().Context(), userID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to load memberships")
+		}
+	} else {
+		session, err := h.sessionRepo.GetByID(c.Request().Context(), c.Get("session_id").(string))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to load session")
+		}
+		if info.Impersonation, err = impersonationInfo(c, h.userRepo, session); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to load impersonator")
+		}
+	}
+	return c.JSON(http.StatusOK, info)
 }
 
+// Logout revokes the current session and clears the auth cookies.
 func (h *AuthHandler) Logout(c echo.Context) error {
-	c.SetCookie(&http.Cookie{
-		Name:     "token",
-		Value:    "",
-		Path:     "/",
-		HttpOnly: true,
-		MaxAge:   -1,
-	})
+	if sessionID, _ := c.Get("session_id").(string); sessionID != "" {
+		if err := h.sessionRepo.Revoke(c.Request().Context(), sessionID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke session")
+		}
+	}
+	clearAuthCookies(c)
 	return c.JSON(http.StatusOK, map[string]string{"message": "logged out"})
 }
 
 // --- helpers ---
 
-func (h *AuthHandler) generateToken(user *model.User) (string, error) {
-	expiryHours, _ := strconv.Atoi(h.cfg.JWTExpiryHours)
-	if expiryHours == 0 {
-		expiryHours = 24
-	}
-
-	employeeID := ""
-	if user.FrappeEmployeeID != nil {
-		employeeID = *user.FrappeEmployeeID
-	}
-
-	return middleware.GenerateToken(
-		h.cfg.JWTSecret,
-		user.ID,
-		user.Email,
-		user.FullName,
-		employeeID,
-		string(user.Role),
-		user.CompanyID,
-		expiryHours,
-	)
-}
-
-func (h *AuthHandler) setTokenCookie(c echo.Context, token string) {
-	expiryHours, _ := strconv.Atoi(h.cfg.JWTExpiryHours)
-	if expiryHours == 0 {
-		expiryHours = 24
-	}
-	c.SetCookie(&http.Cookie{
-		Name:     "token",
-		Value:    token,
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		Expires:  time.Now().Add(time.Duration(expiryHours) * time.Hour),
-	})
-}
-
 func userToInfo(user *model.User, companyName string) model.UserInfo {
 	employeeID := ""
 	if user.FrappeEmployeeID != nil {
@@ -255,6 +410,7 @@ func userToInfo(user *model.User, companyName string) model.UserInfo {
 		CompanyID:        user.CompanyID,
 		CompanyName:      companyName,
 		FrappeEmployeeID: employeeID,
+		MFAEnabled:       user.MFAEnabled,
 	}
 }
 
@@ -266,20 +422,3 @@ func slugify(name string) string {
 	slug = strings.Trim(slug, "-")
 	return slug
 }
-
-func abbreviate(name string) string {
-	words := strings.Fields(name)
-	abbr := ""
-	for _, w := range words {
-		if len(w) > 0 {
-			abbr += strings.ToUpper(w[:1])
-		}
-	}
-	if len(abbr) < 2 {
-		abbr = strings.ToUpper(name)
-		if len(abbr) > 4 {
-			abbr = abbr[:4]
-		}
-	}
-	return abbr
-}
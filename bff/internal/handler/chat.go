@@ -8,6 +8,7 @@ import (
 
 	"hr-platform/bff/internal/client"
 	"hr-platform/bff/internal/config"
+	"hr-platform/bff/internal/model"
 
 	"github.com/labstack/echo/v4"
 )
@@ -45,18 +46,21 @@ func (h *ChatHandler) Chat(c echo.Context) error {
 	employeeID, _ := c.Get("employee_id").(string)
 	userName, _ := c.Get("full_name").(string)
 	userRole, _ := c.Get("user_role").(string)
+	perms, _ := c.Get("permissions").(model.PermissionSet)
 
 	if employeeID == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "employee not linked to this user")
 	}
 
 	tctx := ToolContext{
-		EmployeeID: employeeID,
-		UserRole:   userRole,
+		EmployeeID:  employeeID,
+		UserRole:    userRole,
+		Permissions: perms,
+		Company:     c.Get("frappe_company").(string),
 	}
 
-	// Filter tools to only what this role can use
-	tools := toolsForRole(userRole)
+	// Filter tools to only what this caller's permissions allow
+	tools := toolsForPermissions(perms)
 
 	systemPrompt := buildSystemPrompt(userName, employeeID, userRole, time.Now())
 
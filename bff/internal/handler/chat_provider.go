@@ -108,7 +108,7 @@ func (p *anthropicProvider) StreamChat(ctx context.Context, systemPrompt string,
 			var toolResults []anthropic.ContentBlockParamUnion
 			for _, block := range message.Content {
 				if block.Type == "tool_use" {
-					result := executeTool(frappe, tctx, block.Name, block.Input)
+					result := executeTool(ctx, frappe, tctx, block.Name, block.Input)
 					toolResults = append(toolResults, anthropic.ContentBlockParamUnion{
 						OfToolResult: &anthropic.ToolResultBlockParam{
 							ToolUseID: block.ID,
@@ -182,7 +182,7 @@ func (p *openAIProvider) StreamChat(ctx context.Context, systemPrompt string, me
 
 		oaiMessages = append(oaiMessages, acc.Choices[0].Message.ToParam())
 		for _, tc := range acc.Choices[0].Message.ToolCalls {
-			result := executeTool(frappe, tctx, tc.Function.Name, json.RawMessage(tc.Function.Arguments))
+			result := executeTool(ctx, frappe, tctx, tc.Function.Name, json.RawMessage(tc.Function.Arguments))
 			oaiMessages = append(oaiMessages, openai.ToolMessage(result, tc.ID))
 		}
 	}
@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strconv"
 
 	"hr-platform/bff/internal/client"
+	"hr-platform/bff/internal/model"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	openai "github.com/openai/openai-go"
@@ -14,8 +16,10 @@ import (
 
 // ToolContext carries per-request caller info for tool execution.
 type ToolContext struct {
-	EmployeeID string
-	UserRole   string
+	EmployeeID  string
+	UserRole    string
+	Permissions model.PermissionSet
+	Company     string // Frappe company every tool call is scoped to
 }
 
 // ToolDef is a provider-agnostic tool definition.
@@ -24,16 +28,10 @@ type ToolDef struct {
 	Description string
 	Properties  map[string]any // JSON Schema properties (nil = no params)
 	Required    []string
-	// Roles that may use this tool. nil/empty = all roles.
-	Roles []string
+	// Permission required to use this tool. Empty = everyone.
+	Permission model.Permission
 }
 
-var (
-	rolesAll        = []string{"admin", "hr", "manager", "employee"}
-	rolesManagement = []string{"admin", "hr", "manager"} // approve actions
-	rolesAdminHR    = []string{"admin", "hr"}
-)
-
 // allTools defines every HR assistant tool in one place.
 // To add a new tool: (1) add ToolDef here, (2) add a case in executeTool.
 func allTools() []ToolDef {
@@ -42,12 +40,10 @@ func allTools() []ToolDef {
 		{
 			Name:        "get_leave_balance",
 			Description: "ดึงข้อมูลยอดวันลาคงเหลือของพนักงาน แยกตามประเภทการลา (Annual, Sick, Casual, ฯลฯ)",
-			Roles:       rolesAll,
 		},
 		{
 			Name:        "get_leave_applications",
 			Description: "ดึงรายการใบลาของพนักงาน พร้อมสถานะ (รอพิจารณา/อนุมัติ/ปฏิเสธ)",
-			Roles:       rolesAll,
 		},
 		{
 			Name:        "create_leave_application",
@@ -59,7 +55,6 @@ func allTools() []ToolDef {
 				"reason":     map[string]any{"type": "string", "description": "เหตุผลการลา"},
 			},
 			Required: []string{"leave_type", "from_date", "to_date"},
-			Roles:    rolesAll,
 		},
 		{
 			Name:        "cancel_leave_application",
@@ -68,7 +63,6 @@ func allTools() []ToolDef {
 				"leave_id": map[string]any{"type": "string", "description": "รหัสใบลาที่ต้องการยกเลิก"},
 			},
 			Required: []string{"leave_id"},
-			Roles:    rolesAll,
 		},
 		{
 			Name:        "approve_leave_application",
@@ -77,25 +71,22 @@ func allTools() []ToolDef {
 				"leave_id": map[string]any{"type": "string", "description": "รหัสใบลา"},
 				"status":   map[string]any{"type": "string", "description": "\"Approved\" หรือ \"Rejected\""},
 			},
-			Required: []string{"leave_id", "status"},
-			Roles:    rolesManagement,
+			Required:   []string{"leave_id", "status"},
+			Permission: model.PermLeaveApprove,
 		},
 
 		// ── Attendance / Check-in ──────────────────────────────────────
 		{
 			Name:        "get_today_checkin",
 			Description: "ดูสถานะเช็คอิน/เช็คเอาท์วันนี้ รวมเวลาเข้า-ออกและชั่วโมงทำงาน",
-			Roles:       rolesAll,
 		},
 		{
 			Name:        "checkin",
 			Description: "บันทึกเช็คอินเข้างาน ณ เวลาปัจจุบัน (ต้องถามยืนยันก่อนเช็คอิน)",
-			Roles:       rolesAll,
 		},
 		{
 			Name:        "checkout",
 			Description: "บันทึกเช็คเอาท์ออกงาน ณ เวลาปัจจุบัน (ต้องถามยืนยันก่อนเช็คเอาท์)",
-			Roles:       rolesAll,
 		},
 		{
 			Name:        "get_checkin_history",
@@ -104,7 +95,6 @@ func allTools() []ToolDef {
 				"from_date": map[string]any{"type": "string", "description": "วันเริ่มต้น (YYYY-MM-DD)"},
 				"to_date":   map[string]any{"type": "string", "description": "วันสิ้นสุด (YYYY-MM-DD)"},
 			},
-			Roles: rolesAll,
 		},
 		{
 			Name:        "get_attendance_summary",
@@ -113,7 +103,6 @@ func allTools() []ToolDef {
 				"from_date": map[string]any{"type": "string", "description": "วันเริ่มต้น (YYYY-MM-DD) ถ้าไม่ระบุจะใช้ต้นเดือนปัจจุบัน"},
 				"to_date":   map[string]any{"type": "string", "description": "วันสิ้นสุด (YYYY-MM-DD) ถ้าไม่ระบุจะใช้วันนี้"},
 			},
-			Roles: rolesAll,
 		},
 		{
 			Name:        "create_attendance_correction",
@@ -123,12 +112,10 @@ func allTools() []ToolDef {
 				"reason":          map[string]any{"type": "string", "description": "เหตุผลที่ขอแก้ไข"},
 			},
 			Required: []string{"attendance_date", "reason"},
-			Roles:    rolesAll,
 		},
 		{
 			Name:        "get_attendance_requests",
 			Description: "ดูรายการคำขอแก้ไขเวลาเข้างาน (ของตัวเองหรือทีม ขึ้นอยู่กับบทบาท)",
-			Roles:       rolesAll,
 		},
 		{
 			Name:        "approve_attendance_request",
@@ -137,25 +124,22 @@ func allTools() []ToolDef {
 				"request_id": map[string]any{"type": "string", "description": "รหัสคำขอแก้ไข"},
 				"action":     map[string]any{"type": "string", "description": "\"approve\" หรือ \"reject\""},
 			},
-			Required: []string{"request_id", "action"},
-			Roles:    rolesManagement,
+			Required:   []string{"request_id", "action"},
+			Permission: model.PermAttendanceApprove,
 		},
 
 		// ── Shift ──────────────────────────────────────────────────────
 		{
 			Name:        "get_my_shift",
 			Description: "ดูข้อมูลกะทำงานปัจจุบัน (ชื่อกะ เวลาเข้า-ออก ช่วงเวลาที่มอบหมาย)",
-			Roles:       rolesAll,
 		},
 		{
 			Name:        "get_shift_types",
 			Description: "ดูรายการกะทำงานทั้งหมดที่มีในระบบ (ชื่อกะ เวลาเข้า-ออก)",
-			Roles:       rolesAll,
 		},
 		{
 			Name:        "get_shift_requests",
 			Description: "ดูรายการคำขอเปลี่ยนกะ (ของตัวเองหรือทีม ขึ้นอยู่กับบทบาท)",
-			Roles:       rolesAll,
 		},
 		{
 			Name:        "create_shift_request",
@@ -166,7 +150,6 @@ func allTools() []ToolDef {
 				"to_date":    map[string]any{"type": "string", "description": "วันสิ้นสุด (YYYY-MM-DD)"},
 			},
 			Required: []string{"shift_type", "from_date", "to_date"},
-			Roles:    rolesAll,
 		},
 		{
 			Name:        "approve_shift_request",
@@ -175,8 +158,8 @@ func allTools() []ToolDef {
 				"request_id": map[string]any{"type": "string", "description": "รหัสคำขอเปลี่ยนกะ"},
 				"action":     map[string]any{"type": "string", "description": "\"approve\" หรือ \"reject\""},
 			},
-			Required: []string{"request_id", "action"},
-			Roles:    rolesManagement,
+			Required:   []string{"request_id", "action"},
+			Permission: model.PermShiftApprove,
 		},
 		{
 			Name:        "assign_shift",
@@ -187,8 +170,8 @@ func allTools() []ToolDef {
 				"start_date":  map[string]any{"type": "string", "description": "วันที่เริ่มกะ (YYYY-MM-DD)"},
 				"end_date":    map[string]any{"type": "string", "description": "วันที่สิ้นสุดกะ (YYYY-MM-DD) ถ้าไม่ระบุจะไม่มีวันหมดอายุ"},
 			},
-			Required: []string{"employee_id", "shift_type", "start_date"},
-			Roles:    rolesAdminHR,
+			Required:   []string{"employee_id", "shift_type", "start_date"},
+			Permission: model.PermShiftManage,
 		},
 
 		// ── Overtime ───────────────────────────────────────────────────
@@ -202,7 +185,6 @@ func allTools() []ToolDef {
 				"reason":  map[string]any{"type": "string", "description": "เหตุผลที่ทำ OT"},
 			},
 			Required: []string{"ot_date", "ot_type", "hours"},
-			Roles:    rolesAll,
 		},
 		{
 			Name:        "get_overtime_requests",
@@ -212,7 +194,6 @@ func allTools() []ToolDef {
 				"month":  map[string]any{"type": "string", "description": "เดือน (1-12)"},
 				"year":   map[string]any{"type": "string", "description": "ปี (YYYY)"},
 			},
-			Roles: rolesAll,
 		},
 		{
 			Name:        "cancel_overtime_request",
@@ -221,7 +202,6 @@ func allTools() []ToolDef {
 				"request_id": map[string]any{"type": "string", "description": "รหัสคำขอ OT"},
 			},
 			Required: []string{"request_id"},
-			Roles:    rolesAll,
 		},
 		{
 			Name:        "approve_overtime_request",
@@ -230,8 +210,8 @@ func allTools() []ToolDef {
 				"request_id": map[string]any{"type": "string", "description": "รหัสคำขอ OT"},
 				"action":     map[string]any{"type": "string", "description": "\"approve\" หรือ \"reject\""},
 			},
-			Required: []string{"request_id", "action"},
-			Roles:    rolesManagement,
+			Required:   []string{"request_id", "action"},
+			Permission: model.PermOvertimeApprove,
 		},
 
 		// ── Payroll ────────────────────────────────────────────────────
@@ -242,7 +222,6 @@ func allTools() []ToolDef {
 				"year":  map[string]any{"type": "string", "description": "ปี (YYYY)"},
 				"month": map[string]any{"type": "string", "description": "เดือน (1-12)"},
 			},
-			Roles: rolesAll,
 		},
 		{
 			Name:        "get_payroll_slip_detail",
@@ -251,7 +230,6 @@ func allTools() []ToolDef {
 				"slip_id": map[string]any{"type": "string", "description": "รหัสสลิปเงินเดือน (ได้จาก get_payroll_slips)"},
 			},
 			Required: []string{"slip_id"},
-			Roles:    rolesAll,
 		},
 		{
 			Name:        "process_payroll",
@@ -260,8 +238,8 @@ func allTools() []ToolDef {
 				"month": map[string]any{"type": "string", "description": "เดือน (1-12)"},
 				"year":  map[string]any{"type": "string", "description": "ปี (YYYY)"},
 			},
-			Required: []string{"month", "year"},
-			Roles:    rolesAdminHR,
+			Required:   []string{"month", "year"},
+			Permission: model.PermPayrollProcess,
 		},
 
 		// ── Tax ────────────────────────────────────────────────────────
@@ -272,12 +250,10 @@ func allTools() []ToolDef {
 				"year": map[string]any{"type": "string", "description": "ปี (YYYY) เช่น 2025"},
 			},
 			Required: []string{"year"},
-			Roles:    rolesAll,
 		},
 		{
 			Name:        "get_tax_deductions",
 			Description: "ดูรายการค่าลดหย่อนภาษีปัจจุบัน เช่น ประกันชีวิต กองทุนสำรองเลี้ยงชีพ บุตร",
-			Roles:       rolesAll,
 		},
 		{
 			Name:        "get_pnd1_report",
@@ -286,20 +262,18 @@ func allTools() []ToolDef {
 				"month": map[string]any{"type": "string", "description": "เดือน (1-12)"},
 				"year":  map[string]any{"type": "string", "description": "ปี (YYYY)"},
 			},
-			Required: []string{"month", "year"},
-			Roles:    rolesAdminHR,
+			Required:   []string{"month", "year"},
+			Permission: model.PermTaxReport,
 		},
 
 		// ── Benefits (SSO / PVD) ───────────────────────────────────────
 		{
 			Name:        "get_social_security_info",
 			Description: "ดูข้อมูลประกันสังคม เลขที่ผู้ประกันตน และสถานะการส่งสมทบ",
-			Roles:       rolesAll,
 		},
 		{
 			Name:        "get_provident_fund_info",
 			Description: "ดูข้อมูลกองทุนสำรองเลี้ยงชีพ (PVD) อัตราสะสมของลูกจ้างและนายจ้าง",
-			Roles:       rolesAll,
 		},
 
 		// ── Employee Management (HR/Admin) ─────────────────────────────
@@ -309,9 +283,8 @@ func allTools() []ToolDef {
 			Properties: map[string]any{
 				"department": map[string]any{"type": "string", "description": "กรองตามแผนก (ถ้าไม่ระบุจะดึงทั้งหมด)"},
 				"status":     map[string]any{"type": "string", "description": "สถานะ: Active, Left (ถ้าไม่ระบุจะดึงเฉพาะ Active)"},
-				"search":     map[string]any{"type": "string", "description": "ค้นหาชื่อพนักงาน"},
 			},
-			Roles: rolesAdminHR,
+			Permission: model.PermEmployeeViewAll,
 		},
 		{
 			Name:        "get_employee_detail",
@@ -319,8 +292,8 @@ func allTools() []ToolDef {
 			Properties: map[string]any{
 				"employee_id": map[string]any{"type": "string", "description": "รหัสพนักงาน"},
 			},
-			Required: []string{"employee_id"},
-			Roles:    rolesAdminHR,
+			Required:   []string{"employee_id"},
+			Permission: model.PermEmployeeViewAll,
 		},
 
 		// ── Reports (HR/Admin) ─────────────────────────────────────────
@@ -331,8 +304,8 @@ func allTools() []ToolDef {
 				"month": map[string]any{"type": "string", "description": "เดือน (1-12)"},
 				"year":  map[string]any{"type": "string", "description": "ปี (YYYY)"},
 			},
-			Required: []string{"month", "year"},
-			Roles:    rolesAdminHR,
+			Required:   []string{"month", "year"},
+			Permission: model.PermReportView,
 		},
 		{
 			Name:        "get_leave_report",
@@ -340,8 +313,8 @@ func allTools() []ToolDef {
 			Properties: map[string]any{
 				"year": map[string]any{"type": "string", "description": "ปี (YYYY)"},
 			},
-			Required: []string{"year"},
-			Roles:    rolesAdminHR,
+			Required:   []string{"year"},
+			Permission: model.PermReportView,
 		},
 		{
 			Name:        "get_payroll_report",
@@ -350,51 +323,35 @@ func allTools() []ToolDef {
 				"month": map[string]any{"type": "string", "description": "เดือน (1-12)"},
 				"year":  map[string]any{"type": "string", "description": "ปี (YYYY)"},
 			},
-			Required: []string{"month", "year"},
-			Roles:    rolesAdminHR,
+			Required:   []string{"month", "year"},
+			Permission: model.PermReportView,
 		},
 
 		// ── Org Chart ──────────────────────────────────────────────────
 		{
 			Name:        "get_org_chart",
 			Description: "ดูโครงสร้างองค์กร ลำดับผู้บังคับบัญชา และแผนกต่างๆ",
-			Roles:       rolesAll,
 		},
 	}
 }
 
-// toolsForRole filters allTools() to only those the given role may use.
-func toolsForRole(role string) []ToolDef {
+// toolsForPermissions filters allTools() to only those the given permissions allow.
+func toolsForPermissions(perms model.PermissionSet) []ToolDef {
 	all := allTools()
 	out := make([]ToolDef, 0, len(all))
 	for _, t := range all {
-		if len(t.Roles) == 0 {
+		if t.Permission == "" || perms.Has(t.Permission) {
 			out = append(out, t)
-			continue
-		}
-		for _, r := range t.Roles {
-			if r == role {
-				out = append(out, t)
-				break
-			}
 		}
 	}
 	return out
 }
 
-// canUseToolRole checks if a role is allowed to execute a given tool name.
-func canUseToolRole(role, toolName string) bool {
+// canUseTool checks if the given permissions allow executing a tool name.
+func canUseTool(perms model.PermissionSet, toolName string) bool {
 	for _, t := range allTools() {
 		if t.Name == toolName {
-			if len(t.Roles) == 0 {
-				return true
-			}
-			for _, r := range t.Roles {
-				if r == role {
-					return true
-				}
-			}
-			return false
+			return t.Permission == "" || perms.Has(t.Permission)
 		}
 	}
 	return false
@@ -447,53 +404,31 @@ func toOpenAITools(defs []ToolDef) []openai.ChatCompletionToolParam {
 }
 
 // executeTool runs a tool call against Frappe and returns a JSON string result.
-// Server-side role check prevents privilege escalation even if the model misbehaves.
-func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string, input json.RawMessage) string {
+// Server-side permission check prevents privilege escalation even if the model misbehaves.
+func executeTool(ctx context.Context, fc *client.FrappeClient, tctx ToolContext, toolName string, input json.RawMessage) string {
 	// Server-side permission guard
-	if !canUseToolRole(tctx.UserRole, toolName) {
+	if !canUseTool(tctx.Permissions, toolName) {
 		return fmt.Sprintf(`{"error": "permission denied: role %q cannot use tool %q"}`, tctx.UserRole, toolName)
 	}
 
+	frappe := fc.ForCompany(tctx.Company)
 	empID := tctx.EmployeeID
 
 	switch toolName {
 
 	// ── Leave ─────────────────────────────────────────────────────────
 	case "get_leave_balance":
-		data, err := frappe.CallMethod("hr_core_ext.api.leave.get_leave_allocations", map[string]string{"employee_id": empID})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.Leave().Allocations(ctx, empID))
 
 	case "get_leave_applications":
-		data, err := frappe.CallMethod("hr_core_ext.api.leave.get_leave_applications", map[string]string{"employee_id": empID})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.Leave().List(ctx, client.LeaveFilter{EmployeeID: empID}))
 
 	case "create_leave_application":
-		var args struct {
-			LeaveType string `json:"leave_type"`
-			FromDate  string `json:"from_date"`
-			ToDate    string `json:"to_date"`
-			Reason    string `json:"reason"`
-		}
+		var args model.CreateLeaveRequest
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethodPost("hr_core_ext.api.leave.create_leave_application", map[string]string{
-			"employee_id": empID,
-			"leave_type":  args.LeaveType,
-			"from_date":   args.FromDate,
-			"to_date":     args.ToDate,
-			"reason":      args.Reason,
-		})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.Leave().Create(ctx, empID, args))
 
 	case "cancel_leave_application":
 		var args struct {
@@ -502,13 +437,10 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethodPost("hr_core_ext.api.leave.cancel_leave_application", map[string]string{
-			"leave_id": args.LeaveID,
-		})
-		if err != nil {
+		if _, err := checkOwnerOrApprover(ctx, frappe, tctx.UserRole, empID, client.DoctypeLeaveApplication, args.LeaveID); err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Leave().Cancel(ctx, args.LeaveID))
 
 	case "approve_leave_application":
 		var args struct {
@@ -518,105 +450,54 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethodPost("hr_core_ext.api.leave.approve_leave_application", map[string]string{
-			"leave_id": args.LeaveID,
-			"status":   args.Status,
-		})
-		if err != nil {
+		if _, err := checkApprover(ctx, frappe, tctx.UserRole, empID, client.DoctypeLeaveApplication, args.LeaveID); err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Leave().Decide(ctx, args.LeaveID, args.Status))
 
 	// ── Attendance / Check-in ─────────────────────────────────────────
 	case "get_today_checkin":
-		data, err := frappe.CallMethod("hr_core_ext.api.attendance.get_today_checkin", map[string]string{"employee_id": empID})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.Attendance().Today(ctx, empID))
 
 	case "checkin":
-		data, err := frappe.CallMethodPost("hr_core_ext.api.attendance.checkin", map[string]string{"employee_id": empID})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.Attendance().Checkin(ctx, empID))
 
 	case "checkout":
-		data, err := frappe.CallMethodPost("hr_core_ext.api.attendance.checkout", map[string]string{"employee_id": empID})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.Attendance().Checkout(ctx, empID))
 
 	case "get_checkin_history":
-		params := map[string]string{"employee_id": empID}
 		var args struct {
 			FromDate string `json:"from_date"`
 			ToDate   string `json:"to_date"`
 		}
-		if err := json.Unmarshal(input, &args); err == nil {
-			if args.FromDate != "" {
-				params["from_date"] = args.FromDate
-			}
-			if args.ToDate != "" {
-				params["to_date"] = args.ToDate
-			}
-		}
-		data, err := frappe.CallMethod("hr_core_ext.api.attendance.get_checkin_history", params)
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		_ = json.Unmarshal(input, &args)
+		return toolResult(frappe.Attendance().History(ctx, empID, client.DateRange{From: args.FromDate, To: args.ToDate}))
 
 	case "get_attendance_summary":
-		params := map[string]string{"employee_id": empID}
 		var args struct {
 			FromDate string `json:"from_date"`
 			ToDate   string `json:"to_date"`
 		}
-		if err := json.Unmarshal(input, &args); err == nil {
-			if args.FromDate != "" {
-				params["from_date"] = args.FromDate
-			}
-			if args.ToDate != "" {
-				params["to_date"] = args.ToDate
-			}
-		}
-		data, err := frappe.CallMethod("hr_core_ext.api.attendance.get_attendance_summary", params)
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		_ = json.Unmarshal(input, &args)
+		return toolResult(frappe.Attendance().Summary(ctx, empID, client.DateRange{From: args.FromDate, To: args.ToDate}))
 
 	case "create_attendance_correction":
-		var args struct {
-			AttendanceDate string `json:"attendance_date"`
-			Reason         string `json:"reason"`
-		}
+		var args model.CreateAttendanceRequestBody
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethodPost("hr_core_ext.api.attendance.create_attendance_request", map[string]string{
-			"employee_id":     empID,
-			"attendance_date": args.AttendanceDate,
-			"reason":          args.Reason,
-		})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.Attendance().CreateRequest(ctx, empID, args))
 
 	case "get_attendance_requests":
-		params := map[string]string{}
+		var filter client.AttendanceRequestFilter
 		if tctx.UserRole == "employee" {
-			params["employee_id"] = empID
+			filter.EmployeeID = empID
 		}
-		data, err := frappe.CallMethod("hr_core_ext.api.attendance.get_attendance_requests", params)
+		requests, err := frappe.Attendance().Requests(ctx, filter)
 		if err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolTeam(ctx, frappe, tctx, requests)
 
 	case "approve_attendance_request":
 		var args struct {
@@ -626,60 +507,35 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethodPost("hr_core_ext.api.attendance.approve_attendance_request", map[string]string{
-			"request_id": args.RequestID,
-			"action":     args.Action,
-		})
-		if err != nil {
+		if _, err := checkApprover(ctx, frappe, tctx.UserRole, empID, client.DoctypeAttendanceRequest, args.RequestID); err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Attendance().DecideRequest(ctx, args.RequestID, args.Action))
 
 	// ── Shift ─────────────────────────────────────────────────────────
 	case "get_my_shift":
-		data, err := frappe.CallMethod("hr_core_ext.api.shift.get_employee_current_shift", map[string]string{"employee_id": empID})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.Shift().Current(ctx, empID))
 
 	case "get_shift_types":
-		data, err := frappe.CallMethod("hr_core_ext.api.shift.get_shift_types", map[string]string{})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.Shift().Types(ctx))
 
 	case "get_shift_requests":
-		params := map[string]string{}
+		var filter client.ShiftRequestFilter
 		if tctx.UserRole == "employee" {
-			params["employee_id"] = empID
+			filter.EmployeeID = empID
 		}
-		data, err := frappe.CallMethod("hr_core_ext.api.shift.get_shift_requests", params)
+		requests, err := frappe.Shift().Requests(ctx, filter)
 		if err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolTeam(ctx, frappe, tctx, requests)
 
 	case "create_shift_request":
-		var args struct {
-			ShiftType string `json:"shift_type"`
-			FromDate  string `json:"from_date"`
-			ToDate    string `json:"to_date"`
-		}
+		var args model.CreateShiftRequestBody
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethodPost("hr_core_ext.api.shift.create_shift_request", map[string]string{
-			"employee_id": empID,
-			"shift_type":  args.ShiftType,
-			"from_date":   args.FromDate,
-			"to_date":     args.ToDate,
-		})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.Shift().CreateRequest(ctx, empID, args))
 
 	case "approve_shift_request":
 		var args struct {
@@ -689,38 +545,20 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethodPost("hr_core_ext.api.shift.approve_shift_request", map[string]string{
-			"request_id": args.RequestID,
-			"action":     args.Action,
-		})
-		if err != nil {
+		if _, err := checkApprover(ctx, frappe, tctx.UserRole, empID, client.DoctypeShiftRequest, args.RequestID); err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Shift().DecideRequest(ctx, args.RequestID, args.Action))
 
 	case "assign_shift":
-		var args struct {
-			EmployeeID string `json:"employee_id"`
-			ShiftType  string `json:"shift_type"`
-			StartDate  string `json:"start_date"`
-			EndDate    string `json:"end_date"`
-		}
+		var args model.AssignShiftRequest
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		params := map[string]string{
-			"employee_id": args.EmployeeID,
-			"shift_type":  args.ShiftType,
-			"start_date":  args.StartDate,
-		}
-		if args.EndDate != "" {
-			params["end_date"] = args.EndDate
-		}
-		data, err := frappe.CallMethodPost("hr_core_ext.api.shift.assign_shift", params)
-		if err != nil {
+		if err := frappe.VerifyEmployee(ctx, args.EmployeeID); err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Shift().Assign(ctx, args))
 
 	// ── Overtime ──────────────────────────────────────────────────────
 	case "create_overtime_request":
@@ -733,49 +571,38 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		// validate hours is numeric
-		if _, err := strconv.Atoi(args.Hours); err != nil {
-			return `{"error": "hours must be a numeric string e.g. \"2\""}`
-		}
-		data, err := frappe.CallMethodPost("hr_core_ext.api.overtime.create_ot_request", map[string]string{
-			"employee_id": empID,
-			"ot_date":     args.OTDate,
-			"ot_type":     args.OTType,
-			"hours":       args.Hours,
-			"reason":      args.Reason,
-		})
+		hours, err := strconv.ParseFloat(args.Hours, 64)
 		if err != nil {
-			return toolError(err)
+			return `{"error": "hours must be a numeric string e.g. \"2\""}`
 		}
-		return string(data)
+		return toolResult(frappe.Overtime().Create(ctx, empID, model.CreateOTRequestBody{
+			OTDate: args.OTDate,
+			OTType: args.OTType,
+			Hours:  hours,
+			Reason: args.Reason,
+		}))
 
 	case "get_overtime_requests":
-		params := map[string]string{"employee_id": empID}
 		var args struct {
 			Status string `json:"status"`
 			Month  string `json:"month"`
 			Year   string `json:"year"`
 		}
-		if err := json.Unmarshal(input, &args); err == nil {
-			if args.Status != "" {
-				params["status"] = args.Status
-			}
-			if args.Month != "" {
-				params["month"] = args.Month
-			}
-			if args.Year != "" {
-				params["year"] = args.Year
-			}
+		_ = json.Unmarshal(input, &args)
+		month, year, err := client.ParsePeriod(args.Month, args.Year)
+		if err != nil {
+			return toolError(err)
 		}
-		// Manager/admin/hr see all; employee sees only own
-		if tctx.UserRole != "employee" {
-			delete(params, "employee_id")
+		filter := client.OTRequestFilter{Status: args.Status, Month: month, Year: year}
+		// Admin/HR see all; managers their reporting chain; employee sees only own
+		if tctx.UserRole == "employee" {
+			filter.EmployeeID = empID
 		}
-		data, err := frappe.CallMethod("hr_core_ext.api.overtime.get_ot_requests", params)
+		requests, err := frappe.Overtime().Requests(ctx, filter)
 		if err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolTeam(ctx, frappe, tctx, requests)
 
 	case "cancel_overtime_request":
 		var args struct {
@@ -784,13 +611,10 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethodPost("hr_core_ext.api.overtime.cancel_ot_request", map[string]string{
-			"request_id": args.RequestID,
-		})
-		if err != nil {
+		if _, err := checkOwnerOrApprover(ctx, frappe, tctx.UserRole, empID, client.DoctypeOvertimeRequest, args.RequestID); err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Overtime().Cancel(ctx, args.RequestID))
 
 	case "approve_overtime_request":
 		var args struct {
@@ -800,38 +624,26 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		method := "hr_core_ext.api.overtime.approve_ot_request"
-		if args.Action == "reject" {
-			method = "hr_core_ext.api.overtime.reject_ot_request"
-		}
-		data, err := frappe.CallMethodPost(method, map[string]string{
-			"request_id": args.RequestID,
-		})
-		if err != nil {
+		if _, err := checkApprover(ctx, frappe, tctx.UserRole, empID, client.DoctypeOvertimeRequest, args.RequestID); err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Overtime().Decide(ctx, args.RequestID, args.Action))
 
 	// ── Payroll ───────────────────────────────────────────────────────
 	case "get_payroll_slips":
-		params := map[string]string{"employee_id": empID}
+		filter := client.SlipFilter{EmployeeID: empID}
 		var args struct {
 			Year  string `json:"year"`
 			Month string `json:"month"`
 		}
 		if err := json.Unmarshal(input, &args); err == nil {
-			if args.Year != "" {
-				params["year"] = args.Year
-			}
-			if args.Month != "" {
-				params["month"] = args.Month
+			month, year, err := client.ParsePeriod(args.Month, args.Year)
+			if err != nil {
+				return toolError(err)
 			}
+			filter.Month, filter.Year = month, year
 		}
-		data, err := frappe.CallMethod("hr_core_ext.api.payroll.get_salary_slips", params)
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.Payroll().Slips(ctx, filter))
 
 	case "get_payroll_slip_detail":
 		var args struct {
@@ -840,13 +652,14 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethod("hr_core_ext.api.payroll.get_salary_slip_detail", map[string]string{
-			"slip_id": args.SlipID,
-		})
+		slip, err := frappe.Payroll().Slip(ctx, args.SlipID)
 		if err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		if !tctx.Permissions.Has(model.PermPayrollViewAll) && slip.Employee != empID {
+			return `{"error": "permission denied: salary slip belongs to another employee"}`
+		}
+		return toolResult(slip, nil)
 
 	case "process_payroll":
 		var args struct {
@@ -856,14 +669,11 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethodPost("hr_core_ext.api.payroll.process_payroll", map[string]string{
-			"month": args.Month,
-			"year":  args.Year,
-		})
+		month, year, err := client.ParsePeriod(args.Month, args.Year)
 		if err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Payroll().Process(ctx, month, year))
 
 	// ── Tax ───────────────────────────────────────────────────────────
 	case "get_tax_summary":
@@ -873,23 +683,14 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethod("hr_core_ext.api.tax.get_employee_tax_summary", map[string]string{
-			"employee_id": empID,
-			"year":        args.Year,
-		})
+		_, year, err := client.ParsePeriod("", args.Year)
 		if err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Tax().Summary(ctx, empID, year))
 
 	case "get_tax_deductions":
-		data, err := frappe.CallMethod("hr_core_ext.api.tax.get_employee_tax_deductions", map[string]string{
-			"employee_id": empID,
-		})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.Tax().Deductions(ctx, empID))
 
 	case "get_pnd1_report":
 		var args struct {
@@ -899,58 +700,27 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethod("hr_core_ext.api.tax.get_pnd1_data", map[string]string{
-			"month": args.Month,
-			"year":  args.Year,
-		})
+		month, year, err := client.ParsePeriod(args.Month, args.Year)
 		if err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Tax().PND1(ctx, month, year))
 
 	// ── Benefits ──────────────────────────────────────────────────────
 	case "get_social_security_info":
-		data, err := frappe.CallMethod("hr_core_ext.api.social_security.get_employee_sso_number", map[string]string{
-			"employee_id": empID,
-		})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.SocialSecurity().Number(ctx, empID))
 
 	case "get_provident_fund_info":
-		data, err := frappe.CallMethod("hr_core_ext.api.provident_fund.get_employee_pvd", map[string]string{
-			"employee_id": empID,
-		})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.ProvidentFund().Employee(ctx, empID))
 
 	// ── Employee Management ────────────────────────────────────────────
 	case "list_employees":
-		params := map[string]string{}
 		var args struct {
 			Department string `json:"department"`
 			Status     string `json:"status"`
-			Search     string `json:"search"`
-		}
-		if err := json.Unmarshal(input, &args); err == nil {
-			if args.Department != "" {
-				params["department"] = args.Department
-			}
-			if args.Status != "" {
-				params["status"] = args.Status
-			}
-			if args.Search != "" {
-				params["search"] = args.Search
-			}
 		}
-		data, err := frappe.CallMethod("hr_core_ext.api.employee.get_employees", params)
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		_ = json.Unmarshal(input, &args)
+		return toolResult(frappe.Employee().List(ctx, client.EmployeeFilter{Department: args.Department, Status: args.Status}))
 
 	case "get_employee_detail":
 		var args struct {
@@ -959,13 +729,10 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethod("hr_core_ext.api.employee.get_employee", map[string]string{
-			"employee_id": args.EmployeeID,
-		})
-		if err != nil {
+		if err := frappe.VerifyEmployee(ctx, args.EmployeeID); err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Employee().Get(ctx, args.EmployeeID))
 
 	// ── Reports ───────────────────────────────────────────────────────
 	case "get_attendance_report":
@@ -976,14 +743,11 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethod("hr_core_ext.api.reports.get_attendance_report", map[string]string{
-			"month": args.Month,
-			"year":  args.Year,
-		})
+		month, year, err := client.ParsePeriod(args.Month, args.Year)
 		if err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Report().Attendance(ctx, month, year))
 
 	case "get_leave_report":
 		var args struct {
@@ -992,13 +756,11 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethod("hr_core_ext.api.reports.get_leave_report", map[string]string{
-			"year": args.Year,
-		})
+		_, year, err := client.ParsePeriod("", args.Year)
 		if err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Report().Leave(ctx, year))
 
 	case "get_payroll_report":
 		var args struct {
@@ -1008,28 +770,42 @@ func executeTool(frappe *client.FrappeClient, tctx ToolContext, toolName string,
 		if err := json.Unmarshal(input, &args); err != nil {
 			return toolError(err)
 		}
-		data, err := frappe.CallMethod("hr_core_ext.api.reports.get_payroll_report", map[string]string{
-			"month": args.Month,
-			"year":  args.Year,
-		})
+		month, year, err := client.ParsePeriod(args.Month, args.Year)
 		if err != nil {
 			return toolError(err)
 		}
-		return string(data)
+		return toolResult(frappe.Report().Payroll(ctx, month, year))
 
 	// ── Org Chart ─────────────────────────────────────────────────────
 	case "get_org_chart":
-		data, err := frappe.CallMethod("hr_core_ext.api.orgchart.get_org_tree", map[string]string{})
-		if err != nil {
-			return toolError(err)
-		}
-		return string(data)
+		return toolResult(frappe.OrgChart().Tree(ctx))
 
 	default:
 		return fmt.Sprintf(`{"error": "unknown tool: %s"}`, toolName)
 	}
 }
 
+// toolTeam filters decoded list rows down to the manager's reporting chain.
+func toolTeam[T employeeRecord](ctx context.Context, frappe *client.TenantClient, tctx ToolContext, rows []T) string {
+	scope, err := teamScope(ctx, frappe, tctx.UserRole, tctx.EmployeeID)
+	if err != nil {
+		return toolError(err)
+	}
+	return toolResult(inScope(rows, scope), nil)
+}
+
+// toolResult renders a typed client result, or its error, as the tool's JSON output.
+func toolResult(v any, err error) string {
+	if err != nil {
+		return toolError(err)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return toolError(err)
+	}
+	return string(out)
+}
+
 func toolError(err error) string {
 	msg, _ := json.Marshal(err.Error())
 	return fmt.Sprintf(`{"error": %s}`, string(msg))
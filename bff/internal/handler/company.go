@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/provisioning"
+	"hr-platform/bff/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+const maxEmailDomains = 50
+
+var emailDomainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+type CompanyHandler struct {
+	companyRepo      *repository.CompanyRepository
+	provisioningRepo *repository.ProvisioningRepository
+	auditRepo        *repository.AuditRepository
+	provisioner      *provisioning.Runner
+}
+
+func NewCompanyHandler(
+	companyRepo *repository.CompanyRepository,
+	provisioningRepo *repository.ProvisioningRepository,
+	auditRepo *repository.AuditRepository,
+	provisioner *provisioning.Runner,
+) *CompanyHandler {
+	return &CompanyHandler{
+		companyRepo:      companyRepo,
+		provisioningRepo: provisioningRepo,
+		auditRepo:        auditRepo,
+		provisioner:      provisioner,
+	}
+}
+
+// GetProvisioning reports the progress of the company's Frappe setup after signup.
+func (h *CompanyHandler) GetProvisioning(c echo.Context) error {
+	jobs, err := h.provisioningRepo.ListByCompany(c.Request().Context(), c.Get("company_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load provisioning status")
+	}
+	return c.JSON(http.StatusOK, model.NewProvisioningStatus(jobs))
+}
+
+// RetryProvisioning re-runs the company's failed or waiting steps now, with a fresh set of
+// automatic retries, and returns the resulting status. A step that fails again is still
+// reported with 200; its last_error says why.
+func (h *CompanyHandler) RetryProvisioning(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+	actorID := c.Get("user_id").(string)
+
+	n, err := h.provisioningRepo.Retry(ctx, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to retry provisioning")
+	}
+	if n == 0 {
+		return echo.NewHTTPError(http.StatusConflict, "no provisioning steps to retry")
+	}
+
+	_ = h.auditRepo.Log(ctx, actorID, companyID, "company.provisioning_retried", "company", companyID, nil)
+
+	if err := h.provisioner.Run(ctx, companyID); err != nil {
+		log.Printf("provisioning company %s: %v", companyID, err)
+	}
+
+	jobs, err := h.provisioningRepo.ListByCompany(ctx, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load provisioning status")
+	}
+	return c.JSON(http.StatusOK, model.NewProvisioningStatus(jobs))
+}
+
+// GetEmailDomains returns the company's email domain allow-list.
+func (h *CompanyHandler) GetEmailDomains(c echo.Context) error {
+	company, err := h.companyRepo.GetByID(c.Request().Context(), c.Get("company_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+	return c.JSON(http.StatusOK, model.EmailDomainPolicy{AllowedDomains: company.EmailDomains()})
+}
+
+// UpdateEmailDomains replaces the allow-list. Once set, invites can only be sent to and
+// accepted by those domains, and SSO logins from other domains are refused. Existing
+// users are not affected. An empty list lifts the restriction.
+func (h *CompanyHandler) UpdateEmailDomains(c echo.Context) error {
+	var req model.EmailDomainPolicy
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if len(req.AllowedDomains) > maxEmailDomains {
+		return echo.NewHTTPError(http.StatusBadRequest, "too many email domains")
+	}
+
+	seen := map[string]bool{}
+	domains := []string{}
+	for _, d := range req.AllowedDomains {
+		d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "@")
+		if d == "" {
+			continue
+		}
+		if !emailDomainPattern.MatchString(d) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid email domain: "+d)
+		}
+		if !seen[d] {
+			seen[d] = true
+			domains = append(domains, d)
+		}
+	}
+
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+	actorID := c.Get("user_id").(string)
+
+	if err := h.companyRepo.UpdateEmailDomains(ctx, companyID, strings.Join(domains, ",")); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update email domains")
+	}
+
+	_ = h.auditRepo.Log(ctx, actorID, companyID, "company.email_domains_updated", "company", companyID, map[string][]string{
+		"allowed_domains": domains,
+	})
+
+	return c.JSON(http.StatusOK, model.EmailDomainPolicy{AllowedDomains: domains})
+}
@@ -1,53 +1,45 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 
 	"hr-platform/bff/internal/client"
-	"hr-platform/bff/internal/repository"
+	"hr-platform/bff/internal/model"
 
 	"github.com/labstack/echo/v4"
 )
 
 type DepartmentHandler struct {
-	frappe      *client.FrappeClient
-	companyRepo *repository.CompanyRepository
+	frappe *client.FrappeClient
 }
 
-func NewDepartmentHandler(frappe *client.FrappeClient, companyRepo *repository.CompanyRepository) *DepartmentHandler {
-	return &DepartmentHandler{frappe: frappe, companyRepo: companyRepo}
-}
+// departmentReads are the cached reads that list departments or group employees by them.
+var departmentReads = []string{client.MethodDepartments, client.MethodDepartmentTree, client.MethodOrgTree}
 
-// frappeCompanyName looks up the Frappe company name from the JWT company_id.
-func (h *DepartmentHandler) frappeCompanyName(c echo.Context) (string, error) {
-	companyID := c.Get("company_id").(string)
-	company, err := h.companyRepo.GetByID(c.Request().Context(), companyID)
-	if err != nil {
-		return "", err
+// departmentWriteError reports a parent_department Frappe does not find in the tenant's
+// company as a bad request; the department itself is checked by RequireTenantRecord.
+func departmentWriteError(err error, fallback string) *echo.HTTPError {
+	var fe *client.FrappeError
+	if errors.As(err, &fe) && fe.StatusCode == http.StatusNotFound {
+		return echo.NewHTTPError(http.StatusBadRequest, fe.Message)
 	}
-	return company.FrappeCompanyName, nil
+	return frappeHTTPError(err, fallback)
+}
+
+func NewDepartmentHandler(frappe *client.FrappeClient) *DepartmentHandler {
+	return &DepartmentHandler{frappe: frappe}
 }
 
 // List returns all departments scoped to the caller's company.
 func (h *DepartmentHandler) List(c echo.Context) error {
-	frappeCompany, err := h.frappeCompanyName(c)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
-	}
-
-	params := map[string]string{}
-	if frappeCompany != "" {
-		params["company"] = frappeCompany
-	}
-
-	data, err := h.frappe.CallMethod("hr_core_ext.api.department.get_departments", params)
+	departments, err := tenantFrappe(c, h.frappe).Department().List(c.Request().Context())
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch departments")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": departments,
 	})
 }
 
@@ -58,24 +50,19 @@ func (h *DepartmentHandler) Get(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "department id required")
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.department.get_department", map[string]string{
-		"name": name,
-	})
+	department, err := tenantFrappe(c, h.frappe).Department().Get(c.Request().Context(), name)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch department")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": department,
 	})
 }
 
 // Create creates a new department scoped to the caller's company (admin/HR only).
 func (h *DepartmentHandler) Create(c echo.Context) error {
-	var body struct {
-		DepartmentName   string `json:"department_name"`
-		ParentDepartment string `json:"parent_department"`
-	}
+	var body model.DepartmentRequest
 	if err := c.Bind(&body); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
@@ -83,28 +70,15 @@ func (h *DepartmentHandler) Create(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "department_name required")
 	}
 
-	frappeCompany, err := h.frappeCompanyName(c)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
-	}
-
-	params := map[string]string{
-		"department_name": body.DepartmentName,
-	}
-	if body.ParentDepartment != "" {
-		params["parent_department"] = body.ParentDepartment
-	}
-	if frappeCompany != "" {
-		params["company"] = frappeCompany
-	}
-
-	data, err := h.frappe.CallMethod("hr_core_ext.api.department.create_department", params)
+	tc := tenantFrappe(c, h.frappe)
+	created, err := tc.Department().Create(c.Request().Context(), body)
 	if err != nil {
-		return frappeHTTPError(err, "failed to create department")
+		return departmentWriteError(err, "failed to create department")
 	}
+	_ = tc.Invalidate(c.Request().Context(), departmentReads...)
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": created,
 	})
 }
 
@@ -115,31 +89,20 @@ func (h *DepartmentHandler) Update(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "department id required")
 	}
 
-	var body struct {
-		DepartmentName   string `json:"department_name"`
-		ParentDepartment string `json:"parent_department"`
-	}
+	var body model.DepartmentRequest
 	if err := c.Bind(&body); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	params := map[string]string{
-		"name": name,
-	}
-	if body.DepartmentName != "" {
-		params["department_name"] = body.DepartmentName
-	}
-	if body.ParentDepartment != "" {
-		params["parent_department"] = body.ParentDepartment
-	}
-
-	data, err := h.frappe.CallMethod("hr_core_ext.api.department.update_department", params)
+	tc := tenantFrappe(c, h.frappe)
+	updated, err := tc.Department().Update(c.Request().Context(), name, body)
 	if err != nil {
-		return frappeHTTPError(err, "failed to update department")
+		return departmentWriteError(err, "failed to update department")
 	}
+	_ = tc.Invalidate(c.Request().Context(), departmentReads...)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": updated,
 	})
 }
 
@@ -150,14 +113,14 @@ func (h *DepartmentHandler) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "department id required")
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.department.delete_department", map[string]string{
-		"name": name,
-	})
+	tc := tenantFrappe(c, h.frappe)
+	res, err := tc.Department().Delete(c.Request().Context(), name)
 	if err != nil {
 		return frappeHTTPError(err, "failed to delete department")
 	}
+	_ = tc.Invalidate(c.Request().Context(), departmentReads...)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": res,
 	})
 }
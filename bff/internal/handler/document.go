@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// documentFormat is a file type employees may upload.
+type documentFormat struct {
+	contentType string
+	// sniffed is what http.DetectContentType makes of the format's first bytes.
+	sniffed string
+}
+
+// documentFormats is the allow-list of uploads, by lower-case extension.
+var documentFormats = map[string]documentFormat{
+	".pdf":  {"application/pdf", "application/pdf"},
+	".jpg":  {"image/jpeg", "image/jpeg"},
+	".jpeg": {"image/jpeg", "image/jpeg"},
+	".png":  {"image/png", "image/png"},
+	".webp": {"image/webp", "image/webp"},
+	// Office documents are zip archives to the sniffer
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/zip"},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "application/zip"},
+}
+
+// documentLimits is the largest file each doc_type may be; "" is for uploads without one.
+// Frappe's max_file_size and the proxies in front of it must allow the largest.
+var documentLimits = map[string]int64{
+	"":            10 << 20,
+	"ID Card":     5 << 20,
+	"Photo":       5 << 20,
+	"Certificate": 10 << 20,
+	"Contract":    20 << 20,
+	"Scan":        20 << 20,
+}
+
+const (
+	// maxDocumentUpload bounds a whole upload request: the largest file plus room for
+	// the multipart framing and the doc_type field.
+	maxDocumentUpload = 20<<20 + 64<<10
+	// maxDocumentName is the longest file name kept, in bytes; Frappe's is 140.
+	maxDocumentName = 140
+	sniffLen        = 512
+)
+
+var (
+	errDocumentTooLarge = errors.New("file is too large")
+	// errReadingUpload marks a failure reading the caller's upload, as opposed to Frappe's.
+	errReadingUpload = errors.New("reading upload")
+)
+
+// documentTypes lists the doc_type values an upload may declare.
+func documentTypes() []string {
+	var types []string
+	for t := range documentLimits {
+		if t != "" {
+			types = append(types, t)
+		}
+	}
+	slices.Sort(types)
+	return types
+}
+
+// documentName makes an uploaded file's name safe to store and to send back in a
+// Content-Disposition: no directories, control, format or reserved characters, runs of
+// space collapsed, and no longer than maxDocumentName with the extension kept. It
+// returns "" if nothing is left.
+func documentName(name string) string {
+	name = strings.ToValidUTF8(name, "")
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r):
+			return ' '
+		// Format characters include the bidi overrides that disguise an extension
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r), strings.ContainsRune(`<>:"|?*;`, r):
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.Trim(strings.Join(strings.Fields(name), " "), " .")
+
+	if len(name) > maxDocumentName {
+		ext := filepath.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		base := strings.TrimSuffix(name, ext)
+		for len(base)+len(ext) > maxDocumentName {
+			_, size := utf8.DecodeLastRuneInString(base)
+			base = base[:len(base)-size]
+		}
+		name = strings.TrimRight(base, " .") + ext
+	}
+	return name
+}
+
+// documentFormatOf returns the allowed format of a file name, by its extension.
+func documentFormatOf(name string) (documentFormat, bool) {
+	f, ok := documentFormats[strings.ToLower(filepath.Ext(name))]
+	return f, ok
+}
+
+// documentBody is an upload as it is streamed to Frappe. It fails once more than limit
+// bytes have been read, and marks its errors with errReadingUpload.
+type documentBody struct {
+	r     io.Reader
+	limit int64
+	n     int64
+}
+
+func (b *documentBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.n += int64(n)
+	if b.n > b.limit {
+		err = errDocumentTooLarge
+	}
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%w: %w", errReadingUpload, err)
+	}
+	return n, err
+}
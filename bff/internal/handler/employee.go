@@ -1,76 +1,126 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"hr-platform/bff/internal/client"
 	"hr-platform/bff/internal/model"
-	"hr-platform/bff/internal/repository"
 
 	"github.com/labstack/echo/v4"
 )
 
+// employeeDirectory searches the BFF's copy of the employees (see internal/directory).
+type employeeDirectory interface {
+	Search(ctx context.Context, companyID string, q model.EmployeeSearch, pg client.Page) ([]model.DirectoryEmployee, int, error)
+}
+
 type EmployeeHandler struct {
-	frappe      *client.FrappeClient
-	companyRepo *repository.CompanyRepository
+	frappe    *client.FrappeClient
+	directory employeeDirectory
 }
 
-func NewEmployeeHandler(frappe *client.FrappeClient, companyRepo *repository.CompanyRepository) *EmployeeHandler {
-	return &EmployeeHandler{frappe: frappe, companyRepo: companyRepo}
+func NewEmployeeHandler(frappe *client.FrappeClient, directory employeeDirectory) *EmployeeHandler {
+	return &EmployeeHandler{frappe: frappe, directory: directory}
 }
 
-// List returns employees filtered by company and role.
+// List returns a page of employees filtered by company and role, and optionally by
+// department, designation and status.
 func (h *EmployeeHandler) List(c echo.Context) error {
-	companyID := c.Get("company_id").(string)
 	role := model.UserRole(c.Get("user_role").(string))
 	employeeID := c.Get("employee_id").(string)
 
-	company, err := h.companyRepo.GetByID(c.Request().Context(), companyID)
+	pg, err := readPage(c)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+		return err
 	}
 
-	params := map[string]string{}
-	if company.FrappeCompanyName != "" {
-		params["company"] = company.FrappeCompanyName
+	filter := client.EmployeeFilter{
+		Department:  c.QueryParam("department"),
+		Designation: c.QueryParam("designation"),
+		Status:      c.QueryParam("status"),
 	}
 
 	// Employee role can only see self
 	if role == model.RoleEmployee && employeeID != "" {
-		params["employee_id"] = employeeID
+		filter.EmployeeID = employeeID
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.employee.get_employees", params)
+	page, err := tenantFrappe(c, h.frappe).Employee().Page(c.Request().Context(), filter, pg)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch employees")
 	}
 
-	var employees json.RawMessage
-	if err := json.Unmarshal(data, &employees); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to parse employee data")
+	return respondPage(c, pg, page.Rows, page.Total)
+}
+
+// maxSearchQuery bounds the q of Search.
+const maxSearchQuery = 100
+
+// Search returns a page of employees from the directory, matching q against names in
+// Thai or Latin script, employee IDs and company emails, and filtered by department,
+// designation, status, branch and employment_type. It reads the BFF's copy rather than
+// Frappe, so rows carry synced_at and a stale flag. Employees find only themselves.
+func (h *EmployeeHandler) Search(c echo.Context) error {
+	role := model.UserRole(c.Get("user_role").(string))
+	employeeID := c.Get("employee_id").(string)
+
+	pg, err := readPage(c)
+	if err != nil {
+		return err
+	}
+	q := model.EmployeeSearch{
+		Query:          c.QueryParam("q"),
+		Department:     c.QueryParam("department"),
+		Designation:    c.QueryParam("designation"),
+		Status:         c.QueryParam("status"),
+		Branch:         c.QueryParam("branch"),
+		EmploymentType: c.QueryParam("employment_type"),
+	}
+	if len([]rune(q.Query)) > maxSearchQuery {
+		return echo.NewHTTPError(http.StatusBadRequest, "q is too long")
+	}
+	if role == model.RoleEmployee {
+		if employeeID == "" {
+			return respondPage(c, pg, []model.DirectoryEmployee{}, 0)
+		}
+		q.EmployeeID = employeeID
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": employees,
-	})
+	rows, total, err := h.directory.Search(c.Request().Context(), c.Get("company_id").(string), q, pg)
+	var invalid *client.ValidationError
+	if errors.As(err, &invalid) {
+		return echo.NewHTTPError(http.StatusBadRequest, invalid.Message)
+	}
+	if err != nil {
+		log.Printf("employee search: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to search employees")
+	}
+	return respondPage(c, pg, rows, total)
 }
 
 // Get returns a single employee by Frappe employee_id.
 func (h *EmployeeHandler) Get(c echo.Context) error {
 	id := c.Param("id")
 
-	params := map[string]string{
-		"employee_id": id,
-	}
-
-	data, err := h.frappe.CallMethod("hr_core_ext.api.employee.get_employee", params)
+	employee, err := tenantFrappe(c, h.frappe).Employee().Get(c.Request().Context(), id)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch employee")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": employee,
 	})
 }
 
@@ -85,15 +135,13 @@ func (h *EmployeeHandler) GetFull(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "you can only view your own profile")
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.employee.get_employee_full", map[string]string{
-		"employee_id": id,
-	})
+	profile, err := tenantFrappe(c, h.frappe).Employee().Full(c.Request().Context(), id)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch employee profile")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": profile,
 	})
 }
 
@@ -106,101 +154,32 @@ func (h *EmployeeHandler) Update(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
+	tc := tenantFrappe(c, h.frappe)
+
 	// If reports_to is being changed, validate no circular chain
 	if req.ReportsTo != nil && *req.ReportsTo != "" {
-		validation, err := h.frappe.CallMethod("hr_core_ext.api.employee.validate_manager", map[string]string{
-			"employee_id": id,
-			"manager_id":  *req.ReportsTo,
-		})
+		result, err := tc.Employee().ValidateManager(c.Request().Context(), id, *req.ReportsTo)
 		if err != nil {
 			return frappeHTTPError(err, "failed to validate manager")
 		}
-		var result model.ManagerValidation
-		if err := json.Unmarshal(validation, &result); err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "failed to parse validation")
-		}
 		if !result.Valid {
 			return echo.NewHTTPError(http.StatusBadRequest, result.Reason)
 		}
 	}
 
-	// Build params from non-nil fields
-	params := map[string]string{"employee_id": id}
-	if req.EmployeeName != nil {
-		params["employee_name"] = *req.EmployeeName
-	}
-	if req.Department != nil {
-		params["department"] = *req.Department
-	}
-	if req.Designation != nil {
-		params["designation"] = *req.Designation
-	}
-	if req.EmploymentType != nil {
-		params["employment_type"] = *req.EmploymentType
-	}
-	if req.Branch != nil {
-		params["branch"] = *req.Branch
-	}
-	if req.ReportsTo != nil {
-		params["reports_to"] = *req.ReportsTo
-	}
-	if req.LeaveApprover != nil {
-		params["leave_approver"] = *req.LeaveApprover
-	}
-	if req.Status != nil {
-		params["status"] = *req.Status
-	}
-	if req.CellPhone != nil {
-		params["cell_phone"] = *req.CellPhone
-	}
-	if req.PersonalEmail != nil {
-		params["personal_email"] = *req.PersonalEmail
-	}
-	if req.CompanyEmail != nil {
-		params["company_email"] = *req.CompanyEmail
-	}
-	if req.CurrentAddress != nil {
-		params["current_address"] = *req.CurrentAddress
-	}
-	if req.PermanentAddress != nil {
-		params["permanent_address"] = *req.PermanentAddress
-	}
-	if req.EmergencyPhone != nil {
-		params["emergency_phone_number"] = *req.EmergencyPhone
-	}
-	if req.PersonToBeContacted != nil {
-		params["person_to_be_contacted"] = *req.PersonToBeContacted
-	}
-	if req.Relation != nil {
-		params["relation"] = *req.Relation
-	}
-	if req.DateOfBirth != nil {
-		params["date_of_birth"] = *req.DateOfBirth
-	}
-	if req.Gender != nil {
-		params["gender"] = *req.Gender
-	}
-	if req.MaritalStatus != nil {
-		params["marital_status"] = *req.MaritalStatus
-	}
-	if req.BloodGroup != nil {
-		params["blood_group"] = *req.BloodGroup
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.employee.update_employee", params)
+	updated, err := tc.Employee().Update(c.Request().Context(), id, req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to update employee")
 	}
+	_ = tc.Invalidate(c.Request().Context(), departmentReads...)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": updated,
 	})
 }
 
 // Create creates a new employee in Frappe (admin/HR only).
 func (h *EmployeeHandler) Create(c echo.Context) error {
-	companyID := c.Get("company_id").(string)
-
 	var req struct {
 		EmployeeName string `json:"employee_name"`
 		Department   string `json:"department,omitempty"`
@@ -215,20 +194,13 @@ func (h *EmployeeHandler) Create(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "employee_name is required")
 	}
 
-	company, err := h.companyRepo.GetByID(c.Request().Context(), companyID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
-	}
-
-	companyName := company.FrappeCompanyName
-	if companyName == "" {
-		companyName = company.Name
-	}
+	companyName := c.Get("frappe_company").(string)
 
-	employeeID, err := h.frappe.CreateEmployee(req.EmployeeName, companyName, req.Department, req.Designation)
+	employeeID, err := h.frappe.CreateEmployee(c.Request().Context(), req.EmployeeName, companyName, req.Department, req.Designation)
 	if err != nil {
 		return frappeHTTPError(err, "failed to create employee in Frappe")
 	}
+	_ = tenantFrappe(c, h.frappe).Invalidate(c.Request().Context(), departmentReads...)
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
 		"data": map[string]string{
@@ -242,15 +214,13 @@ func (h *EmployeeHandler) Create(c echo.Context) error {
 func (h *EmployeeHandler) GetCompensation(c echo.Context) error {
 	id := c.Param("id")
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.compensation.get_salary_structure", map[string]string{
-		"employee_id": id,
-	})
+	compensation, err := tenantFrappe(c, h.frappe).Employee().Compensation(c.Request().Context(), id)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch compensation data")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": compensation,
 	})
 }
 
@@ -264,27 +234,29 @@ func (h *EmployeeHandler) GetLeave(c echo.Context) error {
 	if role == model.RoleEmployee && employeeID != id {
 		return echo.NewHTTPError(http.StatusForbidden, "you can only view your own leave data")
 	}
+	// Manager can only view their reporting chain
+	if err := requireInTeam(c, h.frappe, id); err != nil {
+		return err
+	}
+
+	leave := tenantFrappe(c, h.frappe).Leave()
 
 	// Get allocations
-	allocations, err := h.frappe.CallMethod("hr_core_ext.api.leave.get_leave_allocations", map[string]string{
-		"employee_id": id,
-	})
+	allocations, err := leave.Allocations(c.Request().Context(), id)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch leave allocations")
 	}
 
 	// Get leave applications
-	applications, err := h.frappe.CallMethod("hr_core_ext.api.leave.get_leave_applications", map[string]string{
-		"employee_id": id,
-	})
+	applications, err := leave.List(c.Request().Context(), client.LeaveFilter{EmployeeID: id})
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch leave applications")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"data": map[string]interface{}{
-			"allocations":  json.RawMessage(allocations),
-			"applications": json.RawMessage(applications),
+			"allocations":  allocations,
+			"applications": applications,
 		},
 	})
 }
@@ -299,22 +271,18 @@ func (h *EmployeeHandler) GetAttendance(c echo.Context) error {
 	if role == model.RoleEmployee && employeeID != id {
 		return echo.NewHTTPError(http.StatusForbidden, "you can only view your own attendance")
 	}
-
-	params := map[string]string{"employee_id": id}
-	if from := c.QueryParam("from_date"); from != "" {
-		params["from_date"] = from
-	}
-	if to := c.QueryParam("to_date"); to != "" {
-		params["to_date"] = to
+	// Manager can only view their reporting chain
+	if err := requireInTeam(c, h.frappe, id); err != nil {
+		return err
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.attendance.get_attendance_detail", params)
+	detail, err := tenantFrappe(c, h.frappe).Attendance().Detail(c.Request().Context(), id, queryDateRange(c))
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch attendance detail")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": detail,
 	})
 }
 
@@ -329,54 +297,159 @@ func (h *EmployeeHandler) GetDocuments(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "you can only view your own documents")
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.document.get_employee_documents", map[string]string{
-		"employee_id": id,
-	})
+	docs, err := tenantFrappe(c, h.frappe).Employee().Documents(c.Request().Context(), id)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch documents")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": docs,
 	})
 }
 
-// UploadDocument uploads a file to an employee.
+// DownloadDocument streams a file attached to an employee from Frappe, so that clients
+// never need Frappe's own file URLs.
+func (h *EmployeeHandler) DownloadDocument(c echo.Context) error {
+	id := c.Param("id")
+	role := model.UserRole(c.Get("user_role").(string))
+	employeeID := c.Get("employee_id").(string)
+
+	// Employee can only download own documents
+	if role == model.RoleEmployee && employeeID != id {
+		return echo.NewHTTPError(http.StatusForbidden, "you can only download your own documents")
+	}
+
+	ctx := c.Request().Context()
+	tc := tenantFrappe(c, h.frappe)
+	doc, err := tc.Document(ctx, c.Param("doc_id"), id)
+	if errors.Is(err, client.ErrCrossTenant) {
+		return echo.NewHTTPError(http.StatusNotFound, "document not found")
+	}
+	if err != nil {
+		return frappeHTTPError(err, "failed to fetch document")
+	}
+	file, err := tc.OpenFile(ctx, doc.FileURL)
+	if err != nil {
+		return frappeHTTPError(err, "failed to download document")
+	}
+	defer file.Body.Close()
+
+	name := documentName(doc.FileName)
+	if name == "" {
+		name = doc.Name
+	}
+	// The type comes from the allow-list, not from Frappe, and browsers may not guess another
+	contentType := "application/octet-stream"
+	if f, ok := documentFormatOf(name); ok {
+		contentType = f.contentType
+	}
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+	header.Set("Cache-Control", "private, no-store")
+	if file.Size >= 0 {
+		header.Set(echo.HeaderContentLength, strconv.FormatInt(file.Size, 10))
+	}
+	return c.Stream(http.StatusOK, contentType, file.Body)
+}
+
+// UploadDocument streams a multipart/form-data upload to Frappe and attaches it to the
+// employee as a private file. The file part is "file"; an optional "doc_type" part
+// picks its size limit and is only read if it comes first. The file must have an
+// allowed extension and content that matches it.
 func (h *EmployeeHandler) UploadDocument(c echo.Context) error {
 	id := c.Param("id")
 
-	var req struct {
-		Filename string `json:"filename"`
-		Content  string `json:"content"` // base64
-		DocType  string `json:"doc_type,omitempty"`
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, maxDocumentUpload)
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "send the document as multipart/form-data")
 	}
-	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+
+	docType := ""
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+		}
+		if err != nil {
+			return uploadHTTPError(err, docType)
+		}
+		switch part.FormName() {
+		case "doc_type":
+			v, err := io.ReadAll(io.LimitReader(part, 100))
+			if err != nil {
+				return uploadHTTPError(err, docType)
+			}
+			docType = strings.TrimSpace(string(v))
+			if _, ok := documentLimits[docType]; !ok {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("doc_type must be one of %q", documentTypes()))
+			}
+		case "file":
+			return h.uploadDocument(c, id, docType, part)
+		}
 	}
+}
 
-	if req.Filename == "" || req.Content == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "filename and content are required")
+func (h *EmployeeHandler) uploadDocument(c echo.Context, id, docType string, part *multipart.Part) error {
+	name := documentName(part.FileName())
+	if name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "file name is required")
+	}
+	format, ok := documentFormatOf(name)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "only PDF, JPEG, PNG, WebP, Word (.docx) and Excel (.xlsx) files can be uploaded")
 	}
 
-	params := map[string]string{
-		"employee_id": id,
-		"filename":    req.Filename,
-		"content":     req.Content,
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return uploadHTTPError(err, docType)
+	}
+	if n == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "file is empty")
 	}
-	if req.DocType != "" {
-		params["doc_type"] = req.DocType
+	if http.DetectContentType(head[:n]) != format.sniffed {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, fmt.Sprintf("file content does not match its %s extension", filepath.Ext(name)))
 	}
 
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.document.upload_employee_document", params)
+	data, err := tenantFrappe(c, h.frappe).UploadFile(c.Request().Context(), client.Upload{
+		Doctype:  "Employee",
+		Docname:  id,
+		FileName: name,
+		Private:  true,
+		Body:     &documentBody{r: io.MultiReader(bytes.NewReader(head[:n]), part), limit: documentLimits[docType]},
+	})
+	if errors.Is(err, errReadingUpload) {
+		return uploadHTTPError(err, docType)
+	}
 	if err != nil {
 		return frappeHTTPError(err, "failed to upload document")
 	}
 
+	var doc model.EmployeeDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return echo.NewHTTPError(http.StatusBadGateway, "failed to decode uploaded document")
+	}
 	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": doc,
 	})
 }
 
+// uploadHTTPError reports a failure reading the caller's upload.
+func uploadHTTPError(err error, docType string) *echo.HTTPError {
+	var tooLarge *http.MaxBytesError
+	if errors.Is(err, errDocumentTooLarge) || errors.As(err, &tooLarge) {
+		limit := documentLimits[docType]
+		if docType == "" {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("file must be at most %d MB", limit>>20))
+		}
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("%s files must be at most %d MB", docType, limit>>20))
+	}
+	return echo.NewHTTPError(http.StatusBadRequest, "failed to read upload")
+}
+
 // UpdateContact allows employee to self-edit limited contact fields.
 func (h *EmployeeHandler) UpdateContact(c echo.Context) error {
 	id := c.Param("id")
@@ -388,49 +461,18 @@ func (h *EmployeeHandler) UpdateContact(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "you can only edit your own contact info")
 	}
 
-	var req struct {
-		CellPhone           *string `json:"cell_phone,omitempty"`
-		PersonalEmail       *string `json:"personal_email,omitempty"`
-		CurrentAddress      *string `json:"current_address,omitempty"`
-		PermanentAddress    *string `json:"permanent_address,omitempty"`
-		EmergencyPhone      *string `json:"emergency_phone_number,omitempty"`
-		PersonToBeContacted *string `json:"person_to_be_contacted,omitempty"`
-		Relation            *string `json:"relation,omitempty"`
-	}
+	var req model.EmployeeContactUpdate
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	params := map[string]string{"employee_id": id}
-	if req.CellPhone != nil {
-		params["cell_phone"] = *req.CellPhone
-	}
-	if req.PersonalEmail != nil {
-		params["personal_email"] = *req.PersonalEmail
-	}
-	if req.CurrentAddress != nil {
-		params["current_address"] = *req.CurrentAddress
-	}
-	if req.PermanentAddress != nil {
-		params["permanent_address"] = *req.PermanentAddress
-	}
-	if req.EmergencyPhone != nil {
-		params["emergency_phone_number"] = *req.EmergencyPhone
-	}
-	if req.PersonToBeContacted != nil {
-		params["person_to_be_contacted"] = *req.PersonToBeContacted
-	}
-	if req.Relation != nil {
-		params["relation"] = *req.Relation
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.employee.update_employee_contact", params)
+	updated, err := tenantFrappe(c, h.frappe).Employee().UpdateContact(c.Request().Context(), id, req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to update contact info")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": updated,
 	})
 }
 
@@ -444,15 +486,13 @@ func (h *EmployeeHandler) GetTimeline(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "you can only view your own timeline")
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.employee.get_employee_timeline", map[string]string{
-		"employee_id": id,
-	})
+	events, err := tenantFrappe(c, h.frappe).Employee().Timeline(c.Request().Context(), id)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch timeline")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": events,
 	})
 }
 
@@ -460,15 +500,13 @@ func (h *EmployeeHandler) GetTimeline(c echo.Context) error {
 func (h *EmployeeHandler) DeleteDocument(c echo.Context) error {
 	docID := c.Param("doc_id")
 
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.document.delete_employee_document", map[string]string{
-		"file_name": docID,
-	})
+	res, err := tenantFrappe(c, h.frappe).Employee().DeleteDocument(c.Request().Context(), docID)
 	if err != nil {
 		return frappeHTTPError(err, "failed to delete document")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": res,
 	})
 }
 
@@ -476,14 +514,12 @@ func (h *EmployeeHandler) DeleteDocument(c echo.Context) error {
 func (h *EmployeeHandler) GetPromotions(c echo.Context) error {
 	id := c.Param("id")
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.promotion.get_promotions", map[string]string{
-		"employee_id": id,
-	})
+	promotions, err := tenantFrappe(c, h.frappe).Employee().Promotions(c.Request().Context(), id)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch promotions")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": promotions,
 	})
 }
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"hr-platform/bff/internal/auth"
+	"hr-platform/bff/internal/config"
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/repository"
+	"hr-platform/bff/internal/signing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Impersonation sessions are short and cannot be refreshed; when one expires the
+// client's own refresh token brings the admin back to their own account.
+const (
+	defaultImpersonation = 15 * time.Minute
+	maxImpersonation     = 60 * time.Minute
+)
+
+type ImpersonationHandler struct {
+	userRepo    *repository.UserRepository
+	companyRepo *repository.CompanyRepository
+	sessionRepo *repository.SessionRepository
+	roleRepo    *repository.RoleRepository
+	auditRepo   *repository.AuditRepository
+	keys        *signing.KeySet
+	cfg         *config.Config
+}
+
+func NewImpersonationHandler(
+	userRepo *repository.UserRepository,
+	companyRepo *repository.CompanyRepository,
+	sessionRepo *repository.SessionRepository,
+	roleRepo *repository.RoleRepository,
+	auditRepo *repository.AuditRepository,
+	keys *signing.KeySet,
+	cfg *config.Config,
+) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		userRepo:    userRepo,
+		companyRepo: companyRepo,
+		sessionRepo: sessionRepo,
+		roleRepo:    roleRepo,
+		auditRepo:   auditRepo,
+		keys:        keys,
+		cfg:         cfg,
+	}
+}
+
+// Start swaps the caller's access token for one that acts as the :id user for a limited
+// time (user.impersonate). The admin's refresh token is left untouched.
+func (h *ImpersonationHandler) Start(c echo.Context) error {
+	ctx := c.Request().Context()
+	targetID := c.Param("id")
+	companyID := c.Get("company_id").(string)
+	actorID := c.Get("user_id").(string)
+	sessionID, _ := c.Get("session_id").(string)
+
+	if sessionID == "" {
+		return echo.NewHTTPError(http.StatusForbidden, "impersonation requires a signed-in session")
+	}
+
+	var req model.ImpersonateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "reason is required")
+	}
+	duration := defaultImpersonation
+	if req.Minutes > 0 {
+		duration = time.Duration(req.Minutes) * time.Minute
+	}
+	if duration > maxImpersonation {
+		return echo.NewHTTPError(http.StatusBadRequest, "impersonation is limited to 60 minutes")
+	}
+
+	if targetID == actorID {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot impersonate yourself")
+	}
+
+	target, err := h.userRepo.GetInCompany(ctx, targetID, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "user not found")
+	}
+	if target.Status != model.StatusActive {
+		return echo.NewHTTPError(http.StatusBadRequest, "user is not active")
+	}
+
+	// Impersonating someone grants their permissions, so the caller must already hold them
+	access, err := h.roleRepo.AccessForUser(ctx, targetID, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load role")
+	}
+	if err := requireHeldPermissions(c, access.Permissions.List()); err != nil {
+		return err
+	}
+
+	// The refresh token is never handed out; the session exists so it can be revoked.
+	unused, err := auth.NewOpaqueToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
+	}
+	session := &model.Session{
+		UserID:           target.ID,
+		CompanyID:        target.CompanyID,
+		RefreshTokenHash: auth.HashToken(unused),
+		UserAgent:        c.Request().UserAgent(),
+		IPAddress:        c.RealIP(),
+		ExpiresAt:        time.Now().Add(duration),
+		ImpersonatorID:   &actorID,
+		ParentSessionID:  &sessionID,
+		CompanyLocked:    true,
+	}
+	if err := h.sessionRepo.Create(ctx, session); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create session")
+	}
+
+	token, err := signAccessToken(h.keys, session.ID, target, actorID, duration)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
+	}
+
+	_ = h.auditRepo.Log(ctx, actorID, companyID, "impersonation.started", "user", targetID, map[string]interface{}{
+		"reason":     req.Reason,
+		"minutes":    int(duration / time.Minute),
+		"session_id": session.ID,
+		"ip_address": c.RealIP(),
+	})
+
+	company, err := h.companyRepo.GetByID(ctx, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+	info := userToInfo(target, company.Name)
+	info.Impersonation, err = impersonationInfo(c, h.userRepo, session)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load impersonator")
+	}
+
+	setAccessCookie(c, token, session.ExpiresAt)
+
+	return c.JSON(http.StatusOK, model.LoginResponse{
+		Token: token,
+		User:  info,
+	})
+}
+
+// Stop ends the current impersonation session and hands the admin back an access token
+// for their own session.
+func (h *ImpersonationHandler) Stop(c echo.Context) error {
+	ctx := c.Request().Context()
+	impersonatorID, _ := c.Get("impersonator_id").(string)
+	if impersonatorID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "not impersonating")
+	}
+	sessionID := c.Get("session_id").(string)
+
+	session, err := h.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load session")
+	}
+	if err := h.sessionRepo.Revoke(ctx, sessionID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke session")
+	}
+
+	_ = h.auditRepo.Log(ctx, impersonatorID, c.Get("company_id").(string), "impersonation.ended", "user", session.UserID, map[string]string{
+		"session_id": sessionID,
+	})
+
+	if token := h.restoreToken(c, session); token != "" {
+		setAccessCookie(c, token, time.Now().Add(h.cfg.AccessTokenTTL()))
+		return c.JSON(http.StatusOK, map[string]string{"message": "impersonation ended", "token": token})
+	}
+
+	// The admin's own session is gone too; the client falls back to a fresh login.
+	c.SetCookie(&http.Cookie{Name: "token", Value: "", Path: "/", HttpOnly: true, MaxAge: -1})
+	return c.JSON(http.StatusOK, map[string]string{"message": "impersonation ended"})
+}
+
+// restoreToken issues the impersonator an access token for their own session, or
+// returns "" if that session or account is no longer usable.
+func (h *ImpersonationHandler) restoreToken(c echo.Context, session *model.Session) string {
+	ctx := c.Request().Context()
+	if session.ParentSessionID == nil || session.ImpersonatorID == nil {
+		return ""
+	}
+	if active, err := h.sessionRepo.IsActive(ctx, *session.ParentSessionID); err != nil || !active {
+		return ""
+	}
+	admin, err := h.userRepo.GetInCompany(ctx, *session.ImpersonatorID, session.CompanyID)
+	if err != nil || admin.Status != model.StatusActive {
+		return ""
+	}
+	token, err := issueAccessToken(h.keys, h.cfg, *session.ParentSessionID, admin)
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+// impersonationInfo describes who is impersonating through session, for /api/me.
+func impersonationInfo(c echo.Context, userRepo *repository.UserRepository, session *model.Session) (*model.ImpersonationInfo, error) {
+	if session.ImpersonatorID == nil {
+		return nil, nil
+	}
+	admin, err := userRepo.GetByID(c.Request().Context(), *session.ImpersonatorID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.ImpersonationInfo{
+		ImpersonatorID:    admin.ID,
+		ImpersonatorName:  admin.FullName,
+		ImpersonatorEmail: admin.Email,
+		ExpiresAt:         session.ExpiresAt,
+	}, nil
+}
@@ -1,16 +1,24 @@
 package handler
 
 import (
-	"crypto/rand"
-	"encoding/hex"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	netmail "net/mail"
+	"strings"
 	"time"
 
 	"hr-platform/bff/internal/auth"
+	"hr-platform/bff/internal/client"
 	"hr-platform/bff/internal/config"
-	"hr-platform/bff/internal/middleware"
+	"hr-platform/bff/internal/mail"
 	"hr-platform/bff/internal/model"
 	"hr-platform/bff/internal/repository"
+	"hr-platform/bff/internal/signing"
 
 	"github.com/labstack/echo/v4"
 )
@@ -19,7 +27,11 @@ type InviteHandler struct {
 	inviteRepo  *repository.InviteRepository
 	userRepo    *repository.UserRepository
 	companyRepo *repository.CompanyRepository
+	sessionRepo *repository.SessionRepository
 	auditRepo   *repository.AuditRepository
+	frappe      *client.FrappeClient
+	mailer      mail.Sender
+	keys        *signing.KeySet
 	cfg         *config.Config
 }
 
@@ -27,48 +39,115 @@ func NewInviteHandler(
 	inviteRepo *repository.InviteRepository,
 	userRepo *repository.UserRepository,
 	companyRepo *repository.CompanyRepository,
+	sessionRepo *repository.SessionRepository,
 	auditRepo *repository.AuditRepository,
+	frappe *client.FrappeClient,
+	mailer mail.Sender,
+	keys *signing.KeySet,
 	cfg *config.Config,
 ) *InviteHandler {
 	return &InviteHandler{
 		inviteRepo:  inviteRepo,
 		userRepo:    userRepo,
 		companyRepo: companyRepo,
+		sessionRepo: sessionRepo,
 		auditRepo:   auditRepo,
+		frappe:      frappe,
+		mailer:      mailer,
+		keys:        keys,
 		cfg:         cfg,
 	}
 }
 
-// Create creates a new invite (admin/HR only).
+const (
+	inviteTTL = 72 * time.Hour
+	// inviteResendInterval stops an admin from flooding an inbox with resends.
+	inviteResendInterval = time.Minute
+	maxInviteExtendDays  = 30
+	maxBulkInvites       = 500
+	maxBulkInviteBytes   = 1 << 20
+)
+
+// errDomainNotAllowed is returned when an invite's email is outside the company's
+// allowed_email_domains.
+var errDomainNotAllowed = echo.NewHTTPError(http.StatusForbidden, "email domain is not allowed for this company")
+
+// Create creates an invite and emails the link to the invitee (user.invite). The link is
+// not returned; if the email fails, email_sent is false and the invite can be resent.
 func (h *InviteHandler) Create(c echo.Context) error {
 	var req model.CreateInviteRequest
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
+	company, err := h.companyRepo.GetByID(c.Request().Context(), c.Get("company_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+
+	invite, sent, err := h.issue(c, company, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"data": map[string]interface{}{
+			"id":         invite.ID,
+			"email":      invite.Email,
+			"full_name":  invite.FullName,
+			"role":       invite.Role,
+			"expires_at": invite.ExpiresAt,
+			"email_sent": sent,
+		},
+	})
+}
+
+// issue validates req, creates the invite and emails it. Validation failures are
+// *echo.HTTPError so Bulk can report them per row.
+func (h *InviteHandler) issue(c echo.Context, company *model.Company, req model.CreateInviteRequest) (*model.Invite, bool, error) {
+	req.Email = strings.TrimSpace(req.Email)
+	req.FullName = strings.TrimSpace(req.FullName)
 	if req.Email == "" || req.FullName == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "email and full_name are required")
+		return nil, false, echo.NewHTTPError(http.StatusBadRequest, "email and full_name are required")
+	}
+	if addr, err := netmail.ParseAddress(req.Email); err != nil || addr.Address != req.Email {
+		return nil, false, echo.NewHTTPError(http.StatusBadRequest, "invalid email address")
+	}
+	if !company.AllowsEmail(req.Email) {
+		return nil, false, errDomainNotAllowed
 	}
 
 	if req.Role == "" {
 		req.Role = model.RoleEmployee
 	}
+	if req.Role != model.RoleAdmin && req.Role != model.RoleHR && req.Role != model.RoleManager && req.Role != model.RoleEmployee {
+		return nil, false, echo.NewHTTPError(http.StatusBadRequest, "invalid role")
+	}
+	if req.FrappeEmployeeID != "" {
+		if err := verifyTenantEmployee(c, h.frappe, req.FrappeEmployeeID); err != nil {
+			return nil, false, err
+		}
+	}
 
 	ctx := c.Request().Context()
-	companyID := c.Get("company_id").(string)
 	actorID := c.Get("user_id").(string)
 
-	// Check email not already registered
-	if _, err := h.userRepo.GetByEmail(ctx, req.Email); err == nil {
-		return echo.NewHTTPError(http.StatusConflict, "email already registered")
+	// Existing accounts can be invited too, unless they already belong to this company
+	if existing, err := h.userRepo.GetByEmail(ctx, req.Email); err == nil {
+		if _, err := h.userRepo.GetInCompany(ctx, existing.ID, company.ID); err == nil {
+			return nil, false, echo.NewHTTPError(http.StatusConflict, "user is already a member of this company")
+		}
+	}
+	if _, err := h.inviteRepo.GetPending(ctx, company.ID, req.Email); err == nil {
+		return nil, false, echo.NewHTTPError(http.StatusConflict, "an invite is already pending for this email; resend it instead")
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, echo.NewHTTPError(http.StatusInternalServerError, "failed to check existing invites")
 	}
 
-	// Generate secure token
-	tokenBytes := make([]byte, 32)
-	if _, err := rand.Read(tokenBytes); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, false, echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
 	}
-	token := hex.EncodeToString(tokenBytes)
 
 	var frappeEmpID *string
 	if req.FrappeEmployeeID != "" {
@@ -76,35 +155,280 @@ func (h *InviteHandler) Create(c echo.Context) error {
 	}
 
 	invite := &model.Invite{
-		Token:            token,
+		TokenHash:        auth.HashToken(token),
 		Email:            req.Email,
+		FullName:         req.FullName,
+		Locale:           mail.Language(req.Locale),
 		Role:             req.Role,
-		CompanyID:        companyID,
+		CompanyID:        company.ID,
 		InvitedBy:        actorID,
 		FrappeEmployeeID: frappeEmpID,
-		ExpiresAt:        time.Now().Add(72 * time.Hour),
+		ExpiresAt:        time.Now().Add(inviteTTL),
 	}
 
 	if err := h.inviteRepo.Create(ctx, invite); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create invite")
+		return nil, false, echo.NewHTTPError(http.StatusInternalServerError, "failed to create invite")
 	}
 
-	_ = h.auditRepo.Log(ctx, actorID, companyID, "invite.created", "invite", invite.ID, map[string]string{
+	_ = h.auditRepo.Log(ctx, actorID, company.ID, "invite.created", "invite", invite.ID, map[string]string{
 		"email": req.Email,
 		"role":  string(req.Role),
 	})
 
-	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"data": map[string]interface{}{
-			"id":         invite.ID,
-			"email":      invite.Email,
-			"role":       invite.Role,
-			"token":      token,
-			"expires_at": invite.ExpiresAt,
+	return invite, h.send(c, company, invite, token), nil
+}
+
+// send emails the invite link, logging rather than failing on delivery errors.
+func (h *InviteHandler) send(c echo.Context, company *model.Company, invite *model.Invite, token string) bool {
+	ctx := c.Request().Context()
+
+	inviter, _ := c.Get("user_name").(string)
+	if inviter == "" {
+		inviter = company.Name
+	}
+	msg, err := mail.Render("invite", invite.Locale, invite.Email, struct {
+		FullName    string
+		CompanyName string
+		InviterName string
+		Link        string
+		ExpiresAt   time.Time
+	}{
+		FullName:    invite.FullName,
+		CompanyName: company.Name,
+		InviterName: inviter,
+		Link:        fmt.Sprintf("%s/invite/%s", h.cfg.AppBaseURL, token),
+		ExpiresAt:   invite.ExpiresAt,
+	})
+	if err != nil {
+		log.Printf("invite email to %s: render failed: %v", invite.Email, err)
+		return false
+	}
+	if err := h.mailer.Send(ctx, msg); err != nil {
+		log.Printf("invite email to %s failed: %v", invite.Email, err)
+		return false
+	}
+	_ = h.inviteRepo.MarkSent(ctx, invite.ID)
+	return true
+}
+
+// Resend emails a pending invite again with a new link and a fresh expiry (user.invite).
+// The previous link stops working even if the email then fails, so a failure is an error
+// and the admin should resend again.
+func (h *InviteHandler) Resend(c echo.Context) error {
+	companyID := c.Get("company_id").(string)
+	actorID := c.Get("user_id").(string)
+	ctx := c.Request().Context()
+
+	invite, err := h.pendingInvite(c)
+	if err != nil {
+		return err
+	}
+	if invite.LastSentAt != nil && time.Since(*invite.LastSentAt) < inviteResendInterval {
+		return echo.NewHTTPError(http.StatusTooManyRequests, "invite was sent less than a minute ago")
+	}
+
+	company, err := h.companyRepo.GetByID(ctx, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+	if !company.AllowsEmail(invite.Email) {
+		return errDomainNotAllowed
+	}
+
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
+	}
+	invite.TokenHash = auth.HashToken(token)
+	invite.ExpiresAt = time.Now().Add(inviteTTL)
+	if err := h.inviteRepo.Reissue(ctx, invite.ID, invite.TokenHash, invite.ExpiresAt); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to reissue invite")
+	}
+
+	if !h.send(c, company, invite, token) {
+		return echo.NewHTTPError(http.StatusBadGateway, "failed to send invite email")
+	}
+
+	_ = h.auditRepo.Log(ctx, actorID, companyID, "invite.resent", "invite", invite.ID, map[string]string{
+		"email": invite.Email,
+	})
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": invite})
+}
+
+// Extend pushes back a pending invite's expiry by "days" (default 3, at most 30) without
+// changing the link (user.invite). Expired invites are extended from now.
+func (h *InviteHandler) Extend(c echo.Context) error {
+	var req model.ExtendInviteRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Days == 0 {
+		req.Days = int(inviteTTL / (24 * time.Hour))
+	}
+	if req.Days < 1 || req.Days > maxInviteExtendDays {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("days must be between 1 and %d", maxInviteExtendDays))
+	}
+
+	invite, err := h.pendingInvite(c)
+	if err != nil {
+		return err
+	}
+
+	from := invite.ExpiresAt
+	if now := time.Now(); from.Before(now) {
+		from = now
+	}
+	oldExpiry := invite.ExpiresAt
+	invite.ExpiresAt = from.AddDate(0, 0, req.Days)
+	if err := h.inviteRepo.Extend(c.Request().Context(), invite.ID, invite.ExpiresAt); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to extend invite")
+	}
+
+	_ = h.auditRepo.Log(c.Request().Context(), c.Get("user_id").(string), invite.CompanyID, "invite.extended", "invite", invite.ID, map[string]interface{}{
+		"old_expires_at": oldExpiry,
+		"new_expires_at": invite.ExpiresAt,
+	})
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": invite})
+}
+
+// pendingInvite loads the :id invite from the caller's company and checks it can still
+// be accepted.
+func (h *InviteHandler) pendingInvite(c echo.Context) (*model.Invite, error) {
+	invite, err := h.inviteRepo.GetInCompany(c.Request().Context(), c.Param("id"), c.Get("company_id").(string))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "invite not found")
+	}
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to load invite")
+	}
+	if !invite.Pending() {
+		return nil, echo.NewHTTPError(http.StatusConflict, "invite has already been accepted or revoked")
+	}
+	return invite, nil
+}
+
+// Bulk invites everyone in a CSV (user.invite), uploaded as the "file" form field or sent
+// as a text/csv body. The header row names the columns: email and name (or full_name) are
+// required, role and employee_id are optional. An optional "locale" form or query value
+// applies to every email. Rows are processed independently; the response reports each.
+func (h *InviteHandler) Bulk(c echo.Context) error {
+	body, err := bulkInviteCSV(c)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	r := csv.NewReader(io.LimitReader(body, maxBulkInviteBytes))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "could not read CSV header")
+	}
+	cols := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name == "full_name" {
+			name = "name"
+		}
+		cols[name] = i
+	}
+	if _, ok := cols["email"]; !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "CSV must have an email column")
+	}
+	if _, ok := cols["name"]; !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "CSV must have a name column")
+	}
+	field := func(record []string, col string) string {
+		if i, ok := cols[col]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	company, err := h.companyRepo.GetByID(c.Request().Context(), c.Get("company_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+	locale := c.FormValue("locale")
+
+	type row struct {
+		line   int
+		record []string
+	}
+	var rows []row
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid CSV: %v", err))
+		}
+		line, _ := r.FieldPos(0)
+		rows = append(rows, row{line, record})
+	}
+	if len(rows) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "CSV has no rows")
+	}
+	if len(rows) > maxBulkInvites {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("at most %d invites per file", maxBulkInvites))
+	}
+
+	results := make([]model.BulkInviteResult, 0, len(rows))
+	invited := 0
+	for _, row := range rows {
+		result := model.BulkInviteResult{Row: row.line, Email: field(row.record, "email")}
+		invite, sent, err := h.issue(c, company, model.CreateInviteRequest{
+			Email:            result.Email,
+			FullName:         field(row.record, "name"),
+			Role:             model.UserRole(strings.ToLower(field(row.record, "role"))),
+			FrappeEmployeeID: field(row.record, "employee_id"),
+			Locale:           locale,
+		})
+		if err != nil {
+			result.Status = "failed"
+			result.Error = err.Error()
+			var httpErr *echo.HTTPError
+			if errors.As(err, &httpErr) {
+				result.Error = fmt.Sprint(httpErr.Message)
+			}
+		} else {
+			result.Status = "invited"
+			result.InviteID = invite.ID
+			result.EmailSent = sent
+			invited++
+		}
+		results = append(results, result)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data": results,
+		"summary": map[string]int{
+			"invited": invited,
+			"failed":  len(results) - invited,
 		},
 	})
 }
 
+// bulkInviteCSV returns the uploaded file, or the request body if it is text/csv.
+func bulkInviteCSV(c echo.Context) (io.ReadCloser, error) {
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), "text/csv") {
+		return c.Request().Body, nil
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "upload a CSV as the file field or send a text/csv body")
+	}
+	if fh.Size > maxBulkInviteBytes {
+		return nil, echo.NewHTTPError(http.StatusRequestEntityTooLarge, "CSV file is too large")
+	}
+	return fh.Open()
+}
+
 // List returns all invites for the caller's company.
 func (h *InviteHandler) List(c echo.Context) error {
 	companyID := c.Get("company_id").(string)
@@ -117,24 +441,22 @@ func (h *InviteHandler) List(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]interface{}{"data": invites})
 }
 
-// Accept accepts an invite (public, no auth needed).
+// Accept accepts an invite (public, no auth needed). A new account is created and signed
+// in; an existing account joins the company once its password is confirmed and then signs
+// in as usual, so its MFA still applies.
 func (h *InviteHandler) Accept(c echo.Context) error {
 	var req model.AcceptInviteRequest
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	if req.Token == "" || req.Password == "" || req.FullName == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "token, password, and full_name are required")
-	}
-
-	if len(req.Password) < 8 {
-		return echo.NewHTTPError(http.StatusBadRequest, "password must be at least 8 characters")
+	if req.Token == "" || req.Password == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "token and password are required")
 	}
 
 	ctx := c.Request().Context()
 
-	invite, err := h.inviteRepo.GetByToken(ctx, req.Token)
+	invite, err := h.inviteRepo.GetByTokenHash(ctx, auth.HashToken(req.Token))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "invalid invite token")
 	}
@@ -151,9 +473,27 @@ func (h *InviteHandler) Accept(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusGone, "invite has expired")
 	}
 
-	// Check email not taken (could have been registered between invite and accept)
-	if _, err := h.userRepo.GetByEmail(ctx, invite.Email); err == nil {
-		return echo.NewHTTPError(http.StatusConflict, "email already registered")
+	company, err := h.companyRepo.GetByID(ctx, invite.CompanyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+	// The allow-list may have been tightened since the invite was sent
+	if !company.AllowsEmail(invite.Email) {
+		return errDomainNotAllowed
+	}
+
+	if account, err := h.userRepo.GetByEmail(ctx, invite.Email); err == nil {
+		return h.acceptExisting(c, invite, account, req.Password)
+	}
+
+	if req.FullName == "" {
+		req.FullName = invite.FullName
+	}
+	if req.FullName == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "full_name is required")
+	}
+	if len(req.Password) < 8 {
+		return echo.NewHTTPError(http.StatusBadRequest, "password must be at least 8 characters")
 	}
 
 	hash, err := auth.HashPassword(req.Password)
@@ -177,52 +517,57 @@ func (h *InviteHandler) Accept(c echo.Context) error {
 
 	_ = h.inviteRepo.MarkAccepted(ctx, invite.ID)
 
-	company, err := h.companyRepo.GetByID(ctx, invite.CompanyID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
-	}
-
-	token, err := h.generateToken(user, h.cfg)
+	token, refreshToken, err := startSession(c, h.keys, h.cfg, h.sessionRepo, user)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
 	}
 
-	h.setTokenCookie(c, token)
+	setAuthCookies(c, h.cfg, token, refreshToken)
 
 	return c.JSON(http.StatusOK, model.LoginResponse{
-		Token: token,
-		User:  userToInfo(user, company.Name),
+		Token:        token,
+		RefreshToken: refreshToken,
+		User:         userToInfo(user, company.Name),
+	})
+}
+
+// acceptExisting adds the invited company to an existing account.
+func (h *InviteHandler) acceptExisting(c echo.Context, invite *model.Invite, account *model.User, password string) error {
+	ctx := c.Request().Context()
+
+	if !auth.CheckPassword(account.PasswordHash, password) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "incorrect password for the existing account")
+	}
+	if _, err := h.userRepo.GetInCompany(ctx, account.ID, invite.CompanyID); err == nil {
+		return echo.NewHTTPError(http.StatusConflict, "you are already a member of this company")
+	}
+
+	if err := h.userRepo.AddMembership(ctx, account.ID, invite.CompanyID, invite.Role, invite.FrappeEmployeeID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to join company")
+	}
+	_ = h.inviteRepo.MarkAccepted(ctx, invite.ID)
+
+	_ = h.auditRepo.Log(ctx, account.ID, invite.CompanyID, "user.membership_added", "invite", invite.ID, map[string]string{
+		"role": string(invite.Role),
+	})
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":          "invite accepted",
+		"existing_account": true,
 	})
 }
 
-// Revoke revokes an invite (admin/HR only).
+// Revoke revokes an invite (user.invite).
 func (h *InviteHandler) Revoke(c echo.Context) error {
-	inviteID := c.Param("id")
 	companyID := c.Get("company_id").(string)
 	actorID := c.Get("user_id").(string)
 
-	invite, err := h.inviteRepo.GetByToken(c.Request().Context(), inviteID)
-	if err != nil {
-		// Try by ID
-		invites, listErr := h.inviteRepo.ListByCompany(c.Request().Context(), companyID)
-		if listErr != nil {
-			return echo.NewHTTPError(http.StatusNotFound, "invite not found")
-		}
-		found := false
-		for _, inv := range invites {
-			if inv.ID == inviteID {
-				invite = &inv
-				found = true
-				break
-			}
-		}
-		if !found {
-			return echo.NewHTTPError(http.StatusNotFound, "invite not found")
-		}
+	invite, err := h.inviteRepo.GetInCompany(c.Request().Context(), c.Param("id"), companyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return echo.NewHTTPError(http.StatusNotFound, "invite not found")
 	}
-
-	if invite.CompanyID != companyID {
-		return echo.NewHTTPError(http.StatusForbidden, "invite belongs to another company")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load invite")
 	}
 
 	if err := h.inviteRepo.Revoke(c.Request().Context(), invite.ID); err != nil {
@@ -233,26 +578,3 @@ func (h *InviteHandler) Revoke(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "invite revoked"})
 }
-
-func (h *InviteHandler) generateToken(user *model.User, cfg *config.Config) (string, error) {
-	expiryHours := 24
-	employeeID := ""
-	if user.FrappeEmployeeID != nil {
-		employeeID = *user.FrappeEmployeeID
-	}
-	return middleware.GenerateToken(
-		cfg.JWTSecret, user.ID, user.Email, user.FullName,
-		employeeID, string(user.Role), user.CompanyID, expiryHours,
-	)
-}
-
-func (h *InviteHandler) setTokenCookie(c echo.Context, token string) {
-	c.SetCookie(&http.Cookie{
-		Name:     "token",
-		Value:    token,
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		Expires:  time.Now().Add(24 * time.Hour),
-	})
-}
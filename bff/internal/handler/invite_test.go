@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"hr-platform/bff/internal/frappetest"
+	"hr-platform/bff/internal/model"
+)
+
+// Create and Bulk both issue invites through issue, so this covers the JSON and CSV
+// employee_id alike. The repositories are left nil as in TestLinkEmployeeOfAnotherCompany.
+func TestInviteEmployeeOfAnotherCompany(t *testing.T) {
+	fc, c := newAcmeFrappe(t, http.MethodPost, "")
+	h := &InviteHandler{frappe: fc}
+	company := &model.Company{ID: "company-acme", FrappeCompanyName: frappetest.CompanyAcme}
+
+	for _, id := range []string{frappetest.EmpGus, "HR-EMP-MISSING"} {
+		_, _, err := h.issue(c, company, model.CreateInviteRequest{
+			Email:            "dana@acme.test",
+			FullName:         "Dana New",
+			FrappeEmployeeID: id,
+		})
+		expectNotFound(t, err)
+	}
+}
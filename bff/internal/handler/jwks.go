@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"hr-platform/bff/internal/signing"
+
+	"github.com/labstack/echo/v4"
+)
+
+type JWKSHandler struct {
+	keys *signing.KeySet
+}
+
+func NewJWKSHandler(keys *signing.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// JWKS publishes the public keys that verify access tokens, so other services can check
+// them without sharing a secret. Verifiers should refetch when they see an unknown kid:
+// a rotated key signs before the cache below expires.
+func (h *JWKSHandler) JWKS(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"net/http"
 
@@ -14,7 +13,18 @@ import (
 )
 
 // frappeHTTPError returns an echo HTTP error that surfaces the Frappe error message if available.
+// An open circuit breaker is a 503 and a Frappe timeout a 504, so clients know to retry later.
+// Requests a typed client refused to send are a 400.
 func frappeHTTPError(err error, fallback string) *echo.HTTPError {
+	var ve *client.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		return echo.NewHTTPError(http.StatusBadRequest, ve.Message)
+	case errors.Is(err, client.ErrCircuitOpen):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "HR service is temporarily unavailable")
+	case errors.Is(err, context.DeadlineExceeded):
+		return echo.NewHTTPError(http.StatusGatewayTimeout, "HR service did not respond in time")
+	}
 	var fe *client.FrappeError
 	if errors.As(err, &fe) {
 		return echo.NewHTTPError(http.StatusBadGateway, fe.Message)
@@ -43,62 +53,53 @@ func (h *LeaveHandler) Create(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "employee not linked to this user")
 	}
 
-	params := map[string]string{
-		"employee_id": employeeID,
-	}
-	_, err := h.frappe.CallMethod("hr_core_ext.api.leave.get_leave_balance", params)
-	if err != nil {
+	leave := tenantFrappe(c, h.frappe).Leave()
+	if err := leave.CheckBalance(c.Request().Context(), employeeID); err != nil {
 		return frappeHTTPError(err, "failed to verify leave balance")
 	}
 
-	// Create leave application via Frappe
-	payload := map[string]string{
-		"employee_id": employeeID,
-		"leave_type":  req.LeaveType,
-		"from_date":   req.FromDate,
-		"to_date":     req.ToDate,
-		"reason":      req.Reason,
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.leave.create_leave_application", payload)
+	res, err := leave.Create(c.Request().Context(), employeeID, req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to create leave application")
 	}
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": res,
 	})
 }
 
-// List returns leave applications filtered by role.
+// List returns a page of leave applications filtered by role and optionally by status.
 func (h *LeaveHandler) List(c echo.Context) error {
 	employeeID := c.Get("employee_id").(string)
 	role := model.UserRole(c.Get("user_role").(string))
 
-	params := map[string]string{}
+	filter := client.LeaveFilter{Status: c.QueryParam("status")}
 
-	// Employee: only own leaves. Admin/HR/Manager: all or filtered.
+	// Employee: only own leaves. Admin/HR: all. Manager: self and reports.
 	if role == model.RoleEmployee && employeeID != "" {
-		params["employee_id"] = employeeID
+		filter.EmployeeID = employeeID
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.leave.get_leave_applications", params)
+	pg, scope, err := readTeamPage(c, h.frappe)
+	if err != nil {
+		return err
+	}
+
+	page, err := tenantFrappe(c, h.frappe).Leave().ListPage(c.Request().Context(), filter, pg)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch leave applications")
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
-	})
+	return respondPage(c, pg, inScope(page.Rows, scope), page.Total)
 }
 
 // Approve approves or rejects a leave application (admin/HR/manager).
+// Managers may only decide on leave of employees in their reporting chain.
 func (h *LeaveHandler) Approve(c echo.Context) error {
 	leaveID := c.Param("id")
 
 	var req struct {
-		Status     string `json:"status"`      // "Approved" or "Rejected"
-		EmployeeID string `json:"employee_id"` // optional, for notification
+		Status string `json:"status"` // "Approved" or "Rejected"
 	}
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
@@ -108,21 +109,21 @@ func (h *LeaveHandler) Approve(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "status must be 'Approved' or 'Rejected'")
 	}
 
-	params := map[string]string{
-		"leave_id": leaveID,
-		"status":   req.Status,
+	owner, err := authorizeApproval(c, h.frappe, client.DoctypeLeaveApplication, leaveID)
+	if err != nil {
+		return err
 	}
 
-	_, err := h.frappe.CallMethodPost("hr_core_ext.api.leave.approve_leave_application", params)
+	_, err = tenantFrappe(c, h.frappe).Leave().Decide(c.Request().Context(), leaveID, req.Status)
 	if err != nil {
 		return frappeHTTPError(err, "failed to update leave status")
 	}
 
 	// Send notification to the leave requester
-	if h.notifRepo != nil && req.EmployeeID != "" {
+	if h.notifRepo != nil && owner.Employee != "" {
 		go func() {
 			companyID := c.Get("company_id").(string)
-			u, err := h.userRepo.GetByFrappeEmployeeID(context.Background(), companyID, req.EmployeeID)
+			u, err := h.userRepo.GetByFrappeEmployeeID(context.Background(), companyID, owner.Employee)
 			if err == nil && u != nil {
 				title := "Leave " + req.Status
 				msg := "Your leave request has been " + req.Status
@@ -137,51 +138,45 @@ func (h *LeaveHandler) Approve(c echo.Context) error {
 }
 
 // Update edits an open leave application (before approval).
+// Only the applicant or an approver of the applicant may edit it.
 func (h *LeaveHandler) Update(c echo.Context) error {
 	leaveID := c.Param("id")
 
+	if _, err := authorizeOwnerOrApprover(c, h.frappe, client.DoctypeLeaveApplication, leaveID); err != nil {
+		return err
+	}
+
 	var req model.UpdateLeaveRequest
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	params := map[string]string{"leave_id": leaveID}
-	if req.LeaveType != nil {
-		params["leave_type"] = *req.LeaveType
-	}
-	if req.FromDate != nil {
-		params["from_date"] = *req.FromDate
-	}
-	if req.ToDate != nil {
-		params["to_date"] = *req.ToDate
-	}
-	if req.Reason != nil {
-		params["reason"] = *req.Reason
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.leave.update_leave_application", params)
+	res, err := tenantFrappe(c, h.frappe).Leave().Update(c.Request().Context(), leaveID, req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to update leave application")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": res,
 	})
 }
 
 // Cancel cancels an open leave application.
+// Only the applicant or an approver of the applicant may cancel it.
 func (h *LeaveHandler) Cancel(c echo.Context) error {
 	leaveID := c.Param("id")
 
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.leave.cancel_leave_application", map[string]string{
-		"leave_id": leaveID,
-	})
+	if _, err := authorizeOwnerOrApprover(c, h.frappe, client.DoctypeLeaveApplication, leaveID); err != nil {
+		return err
+	}
+
+	res, err := tenantFrappe(c, h.frappe).Leave().Cancel(c.Request().Context(), leaveID)
 	if err != nil {
 		return frappeHTTPError(err, "failed to cancel leave application")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": res,
 	})
 }
 
@@ -192,14 +187,12 @@ func (h *LeaveHandler) Balance(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "employee not linked to this user")
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.leave.get_leave_allocations", map[string]string{
-		"employee_id": employeeID,
-	})
+	allocs, err := tenantFrappe(c, h.frappe).Leave().Allocations(c.Request().Context(), employeeID)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch leave balance")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": allocs,
 	})
 }
@@ -0,0 +1,526 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"hr-platform/bff/internal/auth"
+	"hr-platform/bff/internal/config"
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/repository"
+	"hr-platform/bff/internal/signing"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	mfaIssuer         = "HR Platform"
+	mfaChallengeTTL   = 5 * time.Minute
+	mfaMaxAttempts    = 5
+	recoveryCodeCount = 10
+)
+
+type MFAHandler struct {
+	userRepo    *repository.UserRepository
+	companyRepo *repository.CompanyRepository
+	roleRepo    *repository.RoleRepository
+	mfaRepo     *repository.MFARepository
+	sessionRepo *repository.SessionRepository
+	auditRepo   *repository.AuditRepository
+	keys        *signing.KeySet
+	cfg         *config.Config
+}
+
+func NewMFAHandler(
+	userRepo *repository.UserRepository,
+	companyRepo *repository.CompanyRepository,
+	roleRepo *repository.RoleRepository,
+	mfaRepo *repository.MFARepository,
+	sessionRepo *repository.SessionRepository,
+	auditRepo *repository.AuditRepository,
+	keys *signing.KeySet,
+	cfg *config.Config,
+) *MFAHandler {
+	return &MFAHandler{
+		userRepo:    userRepo,
+		companyRepo: companyRepo,
+		roleRepo:    roleRepo,
+		mfaRepo:     mfaRepo,
+		sessionRepo: sessionRepo,
+		auditRepo:   auditRepo,
+		keys:        keys,
+		cfg:         cfg,
+	}
+}
+
+// --- second login step (challenge token from AuthHandler.Login) ---
+
+// Verify completes a login with a TOTP or recovery code. If the company policy forced
+// enrollment, the first valid code also turns MFA on and the response carries recovery codes.
+func (h *MFAHandler) Verify(c echo.Context) error {
+	var req model.MFAVerifyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Code == "" && req.RecoveryCode == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "code or recovery_code is required")
+	}
+
+	ctx := c.Request().Context()
+	ch, user, err := h.loadChallenge(ctx, req.ChallengeToken)
+	if err != nil {
+		return err
+	}
+
+	var recoveryCodes []string
+	if user.MFAEnabled {
+		usedRecovery, ok, err := h.checkSecondFactor(ctx, user, req.Code, req.RecoveryCode)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to verify code")
+		}
+		if !ok {
+			_ = h.mfaRepo.RecordFailedAttempt(ctx, ch.ID)
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid verification code")
+		}
+		if usedRecovery {
+			_ = h.auditRepo.Log(ctx, user.ID, user.CompanyID, "user.mfa_recovery_code_used", "user", user.ID, nil)
+		}
+	} else {
+		if user.MFASecret == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "mfa enrollment has not been started")
+		}
+		step, ok, err := h.validateTOTP(user, req.Code)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to verify code")
+		}
+		if !ok {
+			_ = h.mfaRepo.RecordFailedAttempt(ctx, ch.ID)
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid verification code")
+		}
+		if recoveryCodes, err = h.enable(ctx, user, step); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to enable mfa")
+		}
+	}
+
+	if err := h.mfaRepo.ConsumeChallenge(ctx, ch.ID); err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired challenge")
+	}
+
+	company, err := h.companyRepo.GetByID(ctx, user.CompanyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+
+	token, refreshToken, err := newSession(c, h.keys, h.cfg, h.sessionRepo, user, ch.CompanyLocked)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
+	}
+
+	_ = h.userRepo.UpdateLastLogin(ctx, user.ID)
+
+	setAuthCookies(c, h.cfg, token, refreshToken)
+
+	return c.JSON(http.StatusOK, model.LoginResponse{
+		Token:         token,
+		RefreshToken:  refreshToken,
+		User:          userToInfo(user, company.Name),
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// Enroll starts enrollment for a user whose company requires MFA but who has not set it up.
+// It is authorised by the login challenge token rather than a session.
+func (h *MFAHandler) Enroll(c echo.Context) error {
+	var req model.MFAEnrollRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	_, user, err := h.loadChallenge(c.Request().Context(), req.ChallengeToken)
+	if err != nil {
+		return err
+	}
+	if user.MFAEnabled {
+		return echo.NewHTTPError(http.StatusConflict, "mfa is already enabled")
+	}
+
+	return h.startEnrollment(c, user)
+}
+
+// --- self-service (authenticated) ---
+
+// Status returns the current user's MFA state.
+func (h *MFAHandler) Status(c echo.Context) error {
+	ctx := c.Request().Context()
+	user, company, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	remaining, err := h.mfaRepo.CountRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load mfa status")
+	}
+
+	return c.JSON(http.StatusOK, model.MFAStatus{
+		Enabled:                user.MFAEnabled,
+		EnrolledAt:             user.MFAEnrolledAt,
+		Required:               company.RequiresMFA(user.Role),
+		RecoveryCodesRemaining: remaining,
+	})
+}
+
+// Setup generates a new secret for the current user. MFA is enabled by Activate.
+func (h *MFAHandler) Setup(c echo.Context) error {
+	user, _, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+	if user.MFAEnabled {
+		return echo.NewHTTPError(http.StatusConflict, "mfa is already enabled")
+	}
+	return h.startEnrollment(c, user)
+}
+
+// Activate confirms the pending secret with a code and returns recovery codes.
+func (h *MFAHandler) Activate(c echo.Context) error {
+	var req model.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	user, _, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+	if user.MFAEnabled {
+		return echo.NewHTTPError(http.StatusConflict, "mfa is already enabled")
+	}
+	if user.MFASecret == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "mfa setup has not been started")
+	}
+
+	step, ok, err := h.validateTOTP(user, req.Code)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to verify code")
+	}
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid verification code")
+	}
+
+	codes, err := h.enable(c.Request().Context(), user, step)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to enable mfa")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"recovery_codes": codes})
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes. Requires a current TOTP code.
+func (h *MFAHandler) RegenerateRecoveryCodes(c echo.Context) error {
+	var req model.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.Request().Context()
+	user, _, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+	if !user.MFAEnabled {
+		return echo.NewHTTPError(http.StatusBadRequest, "mfa is not enabled")
+	}
+
+	_, ok, err := h.checkSecondFactor(ctx, user, req.Code, "")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to verify code")
+	}
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid verification code")
+	}
+
+	codes, err := h.issueRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate recovery codes")
+	}
+
+	_ = h.auditRepo.Log(ctx, user.ID, user.CompanyID, "user.mfa_recovery_codes_regenerated", "user", user.ID, nil)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"recovery_codes": codes})
+}
+
+// Disable turns off MFA for the current user, unless their company requires it for their role.
+func (h *MFAHandler) Disable(c echo.Context) error {
+	var req model.MFADisableRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.Request().Context()
+	user, company, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+	if !user.MFAEnabled {
+		return echo.NewHTTPError(http.StatusBadRequest, "mfa is not enabled")
+	}
+	if company.RequiresMFA(user.Role) {
+		return echo.NewHTTPError(http.StatusForbidden, "your company requires mfa for your role")
+	}
+	if !auth.CheckPassword(user.PasswordHash, req.Password) {
+		return echo.NewHTTPError(http.StatusBadRequest, "password is incorrect")
+	}
+	_, ok, err := h.checkSecondFactor(ctx, user, req.Code, "")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to verify code")
+	}
+	if !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid verification code")
+	}
+
+	if err := h.mfaRepo.Disable(ctx, user.ID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to disable mfa")
+	}
+
+	_ = h.auditRepo.Log(ctx, user.ID, user.CompanyID, "user.mfa_disabled", "user", user.ID, nil)
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "mfa disabled"})
+}
+
+// --- admin ---
+
+// ResetUser clears another user's MFA (e.g. lost device) and signs them out (admin only).
+func (h *MFAHandler) ResetUser(c echo.Context) error {
+	targetID := c.Param("id")
+	companyID := c.Get("company_id").(string)
+	actorID := c.Get("user_id").(string)
+	ctx := c.Request().Context()
+
+	if targetID == actorID {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot reset your own mfa")
+	}
+
+	if _, err := h.userRepo.GetInCompany(ctx, targetID, companyID); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "user not found")
+	}
+
+	// Resetting a user's MFA weakens their account, so the caller must hold their permissions
+	access, err := h.roleRepo.AccessForUser(ctx, targetID, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load role")
+	}
+	if err := requireHeldPermissions(c, access.Permissions.List()); err != nil {
+		return err
+	}
+
+	if err := h.mfaRepo.Disable(ctx, targetID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to reset mfa")
+	}
+	if _, err := h.sessionRepo.RevokeAllForUser(ctx, targetID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke sessions")
+	}
+
+	_ = h.auditRepo.Log(ctx, actorID, companyID, "user.mfa_reset", "user", targetID, nil)
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "mfa reset"})
+}
+
+// GetPolicy returns the roles the caller's company requires to use MFA.
+func (h *MFAHandler) GetPolicy(c echo.Context) error {
+	company, err := h.companyRepo.GetByID(c.Request().Context(), c.Get("company_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+	return c.JSON(http.StatusOK, model.MFAPolicy{RequiredRoles: company.MFARoles()})
+}
+
+// UpdatePolicy sets the roles that must use MFA (admin only). Affected users are asked to
+// enroll on their next login.
+func (h *MFAHandler) UpdatePolicy(c echo.Context) error {
+	var req model.MFAPolicy
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	seen := map[model.UserRole]bool{}
+	roles := []string{}
+	for _, r := range req.RequiredRoles {
+		if r != model.RoleAdmin && r != model.RoleHR && r != model.RoleManager && r != model.RoleEmployee {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid role: "+string(r))
+		}
+		if !seen[r] {
+			seen[r] = true
+			roles = append(roles, string(r))
+		}
+	}
+
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+	actorID := c.Get("user_id").(string)
+
+	if err := h.companyRepo.UpdateMFAPolicy(ctx, companyID, strings.Join(roles, ",")); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update mfa policy")
+	}
+
+	_ = h.auditRepo.Log(ctx, actorID, companyID, "company.mfa_policy_updated", "company", companyID, map[string][]string{
+		"required_roles": roles,
+	})
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"required_roles": roles})
+}
+
+// --- helpers ---
+
+// mfaChallengeStore is the part of the MFA repository that login handlers need.
+type mfaChallengeStore interface {
+	CreateChallenge(ctx context.Context, ch *model.MFAChallenge) error
+}
+
+// createMFAChallenge issues the short-lived token a client exchanges for a session in the
+// user's current company once the second factor is verified. companyLocked is passed on
+// to that session.
+func createMFAChallenge(ctx context.Context, mfaRepo mfaChallengeStore, user *model.User, companyLocked bool) (*model.MFAChallengeResponse, error) {
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	companyID := user.CompanyID
+	ch := &model.MFAChallenge{
+		TokenHash:     auth.HashToken(token),
+		UserID:        user.ID,
+		CompanyID:     &companyID,
+		CompanyLocked: companyLocked,
+		ExpiresAt:     time.Now().Add(mfaChallengeTTL),
+	}
+	if err := mfaRepo.CreateChallenge(ctx, ch); err != nil {
+		return nil, err
+	}
+	return &model.MFAChallengeResponse{
+		MFARequired:        true,
+		EnrollmentRequired: !user.MFAEnabled,
+		ChallengeToken:     token,
+		ExpiresAt:          ch.ExpiresAt,
+	}, nil
+}
+
+func (h *MFAHandler) loadChallenge(ctx context.Context, token string) (*model.MFAChallenge, *model.User, error) {
+	invalid := echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired challenge")
+	if token == "" {
+		return nil, nil, invalid
+	}
+
+	ch, err := h.mfaRepo.GetChallenge(ctx, auth.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, invalid
+	}
+	if err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to load challenge")
+	}
+	if ch.ConsumedAt != nil || time.Now().After(ch.ExpiresAt) || ch.Attempts >= mfaMaxAttempts {
+		return nil, nil, invalid
+	}
+
+	var user *model.User
+	if ch.CompanyID != nil {
+		user, err = h.userRepo.GetInCompany(ctx, ch.UserID, *ch.CompanyID)
+	} else {
+		user, err = h.userRepo.GetByID(ctx, ch.UserID)
+	}
+	if err != nil || user.Status != model.StatusActive {
+		return nil, nil, invalid
+	}
+	return ch, user, nil
+}
+
+func (h *MFAHandler) currentUser(c echo.Context) (*model.User, *model.Company, error) {
+	ctx := c.Request().Context()
+	user, err := h.userRepo.GetInCompany(ctx, c.Get("user_id").(string), c.Get("company_id").(string))
+	if err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusUnauthorized, "user not found")
+	}
+	company, err := h.companyRepo.GetByID(ctx, user.CompanyID)
+	if err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+	return user, company, nil
+}
+
+// checkSecondFactor verifies a TOTP code, or failing that a recovery code.
+func (h *MFAHandler) checkSecondFactor(ctx context.Context, user *model.User, code, recoveryCode string) (usedRecovery, ok bool, err error) {
+	if code != "" && user.MFASecret != nil {
+		step, valid, err := h.validateTOTP(user, code)
+		if err != nil || !valid {
+			return false, false, err
+		}
+		ok, err := h.mfaRepo.AdvanceStep(ctx, user.ID, step)
+		return false, ok, err
+	}
+	if recoveryCode != "" {
+		ok, err := h.mfaRepo.UseRecoveryCode(ctx, user.ID, auth.HashToken(auth.NormalizeRecoveryCode(recoveryCode)))
+		return ok, ok, err
+	}
+	return false, false, nil
+}
+
+// validateTOTP checks code against the user's stored secret, which is sealed.
+func (h *MFAHandler) validateTOTP(user *model.User, code string) (int64, bool, error) {
+	secret, err := auth.OpenTOTPSecret(*user.MFASecret, h.cfg.JWTSecret)
+	if err != nil {
+		return 0, false, err
+	}
+	step, ok := auth.ValidateTOTP(secret, code, time.Now())
+	return step, ok, nil
+}
+
+func (h *MFAHandler) startEnrollment(c echo.Context, user *model.User) error {
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate secret")
+	}
+	sealed, err := auth.SealTOTPSecret(secret, h.cfg.JWTSecret)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate secret")
+	}
+	if err := h.mfaRepo.SetPendingSecret(c.Request().Context(), user.ID, sealed); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to start mfa setup")
+	}
+	return c.JSON(http.StatusOK, model.MFASetupResponse{
+		Secret:     secret,
+		OTPAuthURL: auth.TOTPURI(mfaIssuer, user.Email, secret),
+	})
+}
+
+// enable turns MFA on, issues recovery codes and records the enrollment.
+func (h *MFAHandler) enable(ctx context.Context, user *model.User, step int64) ([]string, error) {
+	if err := h.mfaRepo.Enable(ctx, user.ID, step); err != nil {
+		return nil, err
+	}
+	codes, err := h.issueRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	user.MFAEnabled = true
+
+	_ = h.auditRepo.Log(ctx, user.ID, user.CompanyID, "user.mfa_enrolled", "user", user.ID, nil)
+	return codes, nil
+}
+
+func (h *MFAHandler) issueRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	codes, err := auth.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashToken(code)
+	}
+	if err := h.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
@@ -0,0 +1,419 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"hr-platform/bff/internal/auth"
+	"hr-platform/bff/internal/config"
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/repository"
+	"hr-platform/bff/internal/signing"
+	"hr-platform/bff/internal/sso"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/oauth2"
+)
+
+// oidcStateTTL bounds how long a user may spend at the identity provider.
+const oidcStateTTL = 10 * time.Minute
+
+// The OIDC handler's stores are interfaces so tests can run the callback without a database.
+type oidcUserStore interface {
+	Create(ctx context.Context, u *model.User) error
+	GetInCompany(ctx context.Context, id, companyID string) (*model.User, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	GetByOIDCSubject(ctx context.Context, companyID, subject string) (*model.User, error)
+	LinkOIDCSubject(ctx context.Context, id, companyID, subject string) error
+	UpdateLastLogin(ctx context.Context, id string) error
+}
+
+type oidcCompanyStore interface {
+	GetByID(ctx context.Context, id string) (*model.Company, error)
+	GetBySlug(ctx context.Context, slug string) (*model.Company, error)
+	UpdateOIDC(ctx context.Context, c *model.Company) error
+}
+
+type oidcStateStore interface {
+	Create(ctx context.Context, st *model.OIDCState) error
+	Consume(ctx context.Context, stateHash string) (*model.OIDCState, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+type oidcAuditLog interface {
+	Log(ctx context.Context, actorID, companyID, action, targetType, targetID string, details interface{}) error
+}
+
+type OIDCHandler struct {
+	userRepo    oidcUserStore
+	companyRepo oidcCompanyStore
+	sessionRepo *repository.SessionRepository
+	mfaRepo     mfaChallengeStore
+	stateRepo   oidcStateStore
+	auditRepo   oidcAuditLog
+	sso         *sso.Client
+	keys        *signing.KeySet
+	cfg         *config.Config
+}
+
+func NewOIDCHandler(
+	userRepo *repository.UserRepository,
+	companyRepo *repository.CompanyRepository,
+	sessionRepo *repository.SessionRepository,
+	mfaRepo *repository.MFARepository,
+	stateRepo *repository.OIDCStateRepository,
+	auditRepo *repository.AuditRepository,
+	ssoClient *sso.Client,
+	keys *signing.KeySet,
+	cfg *config.Config,
+) *OIDCHandler {
+	return &OIDCHandler{
+		userRepo:    userRepo,
+		companyRepo: companyRepo,
+		sessionRepo: sessionRepo,
+		mfaRepo:     mfaRepo,
+		stateRepo:   stateRepo,
+		auditRepo:   auditRepo,
+		sso:         ssoClient,
+		keys:        keys,
+		cfg:         cfg,
+	}
+}
+
+// Login starts an authorization code + PKCE flow against the company's identity provider.
+func (h *OIDCHandler) Login(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	company, err := h.companyRepo.GetBySlug(ctx, c.Param("slug"))
+	if err != nil || !company.OIDCEnabled() {
+		return echo.NewHTTPError(http.StatusNotFound, "single sign-on is not configured for this company")
+	}
+
+	state, err := auth.NewOpaqueToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to start sso login")
+	}
+	nonce, err := auth.NewOpaqueToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to start sso login")
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	_ = h.stateRepo.DeleteExpired(ctx)
+	if err := h.stateRepo.Create(ctx, &model.OIDCState{
+		StateHash:    auth.HashToken(state),
+		CompanyID:    company.ID,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		RedirectTo:   safeRedirect(c.QueryParam("redirect")),
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	}); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to start sso login")
+	}
+
+	cfg, err := h.ssoConfig(company)
+	if err != nil {
+		log.Printf("oidc login for company %s: %v", company.ID, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to start sso login")
+	}
+	authURL, err := h.sso.AuthCodeURL(ctx, cfg, state, nonce, verifier)
+	if err != nil {
+		log.Printf("oidc login for company %s: %v", company.ID, err)
+		return echo.NewHTTPError(http.StatusBadGateway, "identity provider is unavailable")
+	}
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback completes the flow: it redeems the code, verifies the ID token, resolves the
+// local user and starts a session. Failures send the browser back to the login page with
+// an sso_error code rather than rendering JSON.
+func (h *OIDCHandler) Callback(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	state := c.QueryParam("state")
+	if state == "" {
+		return h.fail(c, "invalid_state")
+	}
+	st, err := h.stateRepo.Consume(ctx, auth.HashToken(state))
+	if err != nil || time.Now().After(st.ExpiresAt) {
+		return h.fail(c, "invalid_state")
+	}
+
+	if idpErr := c.QueryParam("error"); idpErr != "" {
+		log.Printf("oidc callback for company %s: provider returned %s", st.CompanyID, idpErr)
+		return h.fail(c, "provider_error")
+	}
+	code := c.QueryParam("code")
+	if code == "" {
+		return h.fail(c, "provider_error")
+	}
+
+	company, err := h.companyRepo.GetByID(ctx, st.CompanyID)
+	if err != nil || !company.OIDCEnabled() {
+		return h.fail(c, "not_configured")
+	}
+
+	cfg, err := h.ssoConfig(company)
+	if err != nil {
+		log.Printf("oidc callback for company %s: %v", company.ID, err)
+		return h.fail(c, "login_failed")
+	}
+	ident, err := h.sso.Exchange(ctx, cfg, code, st.CodeVerifier, st.Nonce)
+	if errors.Is(err, sso.ErrEmailNotVerified) {
+		return h.fail(c, "email_unverified")
+	}
+	if err != nil {
+		log.Printf("oidc callback for company %s: %v", company.ID, err)
+		return h.fail(c, "login_failed")
+	}
+	if ident.Subject == "" || ident.Email == "" {
+		return h.fail(c, "email_missing")
+	}
+	if !company.AllowsEmail(ident.Email) {
+		return h.fail(c, "domain_not_allowed")
+	}
+
+	user, errCode := h.resolveUser(c, company, ident)
+	if errCode != "" {
+		return h.fail(c, errCode)
+	}
+	if user.Status != model.StatusActive {
+		return h.fail(c, "account_inactive")
+	}
+	return h.signIn(c, company, user, st.RedirectTo)
+}
+
+// signIn starts the session for a resolved user. Users with MFA, or whose company requires
+// it for their role, are sent to the login page's MFA step first, as after a password: the
+// BFF cannot tell whether the IdP checked a second factor.
+func (h *OIDCHandler) signIn(c echo.Context, company *model.Company, user *model.User, redirectTo string) error {
+	ctx := c.Request().Context()
+
+	// The company's IdP vouches for this company only
+	if user.MFAEnabled || company.RequiresMFA(user.Role) {
+		challenge, err := createMFAChallenge(ctx, h.mfaRepo, user, true)
+		if err != nil {
+			return h.fail(c, "login_failed")
+		}
+		// In the fragment, so the token is not sent to any server or in a Referer header.
+		v := url.Values{"mfa_challenge": {challenge.ChallengeToken}, "redirect": {redirectTo}}
+		if challenge.EnrollmentRequired {
+			v.Set("enroll", "1")
+		}
+		return c.Redirect(http.StatusFound, h.cfg.AppBaseURL+"/login#"+v.Encode())
+	}
+
+	token, refreshToken, err := newSession(c, h.keys, h.cfg, h.sessionRepo, user, true)
+	if err != nil {
+		return h.fail(c, "login_failed")
+	}
+	_ = h.userRepo.UpdateLastLogin(ctx, user.ID)
+	setAuthCookies(c, h.cfg, token, refreshToken)
+
+	return c.Redirect(http.StatusFound, h.cfg.AppBaseURL+redirectTo)
+}
+
+// resolveUser finds the local account for a verified identity: first by subject, then by
+// email among the company's members (linking the subject on first use), and finally by
+// auto-provisioning a new account when the company allows it. An existing account that is
+// not a member is never provisioned. It returns an sso_error code on refusal.
+func (h *OIDCHandler) resolveUser(c echo.Context, company *model.Company, ident *sso.Identity) (*model.User, string) {
+	ctx := c.Request().Context()
+
+	user, err := h.userRepo.GetByOIDCSubject(ctx, company.ID, ident.Subject)
+	if err == nil {
+		return user, ""
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, "login_failed"
+	}
+
+	account, err := h.userRepo.GetByEmail(ctx, ident.Email)
+	if err == nil {
+		user, err = h.userRepo.GetInCompany(ctx, account.ID, company.ID)
+	}
+	switch {
+	case err == nil:
+		if user.OIDCSubject != nil && *user.OIDCSubject != ident.Subject {
+			return nil, "account_conflict"
+		}
+		if err := h.userRepo.LinkOIDCSubject(ctx, user.ID, company.ID, ident.Subject); err != nil {
+			return nil, "login_failed"
+		}
+		_ = h.auditRepo.Log(ctx, user.ID, company.ID, "user.oidc_linked", "user", user.ID, map[string]string{
+			"issuer":  company.OIDCIssuer,
+			"subject": ident.Subject,
+		})
+		return user, ""
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, "login_failed"
+	}
+
+	if !company.OIDCAutoProvision {
+		return nil, "account_not_found"
+	}
+
+	if account != nil {
+		// The IdP only vouches for this company. An account of another company must be
+		// invited here before it can sign in through SSO.
+		return nil, "account_exists"
+	}
+
+	name := ident.Name
+	if name == "" {
+		name = ident.Email
+	}
+	// No password: provisioned users sign in through SSO until they set one via reset.
+	user = &model.User{
+		Email:     ident.Email,
+		FullName:  name,
+		Role:      company.OIDCDefaultRole,
+		Status:    model.StatusActive,
+		CompanyID: company.ID,
+	}
+	if err := h.userRepo.Create(ctx, user); err != nil {
+		return nil, "login_failed"
+	}
+	if err := h.userRepo.LinkOIDCSubject(ctx, user.ID, company.ID, ident.Subject); err != nil {
+		return nil, "login_failed"
+	}
+	_ = h.auditRepo.Log(ctx, user.ID, company.ID, "user.oidc_provisioned", "user", user.ID, map[string]string{
+		"issuer":  company.OIDCIssuer,
+		"subject": ident.Subject,
+		"role":    string(user.Role),
+	})
+	return user, ""
+}
+
+// GetConfig returns the company's SSO settings. The client secret is never returned.
+func (h *OIDCHandler) GetConfig(c echo.Context) error {
+	company, err := h.companyRepo.GetByID(c.Request().Context(), c.Get("company_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+	return c.JSON(http.StatusOK, h.configResponse(company))
+}
+
+// UpdateConfig sets the company's identity provider (admin only). A non-empty issuer is
+// checked by fetching its discovery document before it is saved; an empty issuer disables SSO.
+func (h *OIDCHandler) UpdateConfig(c echo.Context) error {
+	var req model.OIDCConfigRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+	actorID := c.Get("user_id").(string)
+
+	company, err := h.companyRepo.GetByID(ctx, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+
+	req.Issuer = strings.TrimRight(strings.TrimSpace(req.Issuer), "/")
+	req.ClientID = strings.TrimSpace(req.ClientID)
+	if req.DefaultRole == "" {
+		req.DefaultRole = model.RoleEmployee
+	}
+	// Auto-provisioned accounts never get admin rights.
+	if req.DefaultRole != model.RoleHR && req.DefaultRole != model.RoleManager && req.DefaultRole != model.RoleEmployee {
+		return echo.NewHTTPError(http.StatusBadRequest, "default_role must be hr, manager, or employee")
+	}
+
+	if req.Issuer != "" {
+		u, err := url.Parse(req.Issuer)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "issuer must be an http(s) URL")
+		}
+		if req.ClientID == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "client_id is required")
+		}
+		h.sso.Forget(req.Issuer)
+		if _, err := h.sso.Discover(ctx, req.Issuer); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "could not load the issuer's discovery document")
+		}
+	}
+
+	if company.OIDCIssuer != req.Issuer {
+		h.sso.Forget(company.OIDCIssuer)
+	}
+	company.OIDCIssuer = req.Issuer
+	company.OIDCClientID = req.ClientID
+	if req.ClientSecret != nil {
+		sealed, err := sso.SealClientSecret(*req.ClientSecret, h.cfg.JWTSecret)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to update sso settings")
+		}
+		company.OIDCClientSecret = sealed
+	}
+	if req.Issuer == "" {
+		company.OIDCClientID = ""
+		company.OIDCClientSecret = ""
+	}
+	company.OIDCAutoProvision = req.AutoProvision
+	company.OIDCDefaultRole = req.DefaultRole
+
+	if err := h.companyRepo.UpdateOIDC(ctx, company); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update sso settings")
+	}
+
+	_ = h.auditRepo.Log(ctx, actorID, companyID, "company.oidc_updated", "company", companyID, map[string]interface{}{
+		"issuer":         company.OIDCIssuer,
+		"client_id":      company.OIDCClientID,
+		"secret_changed": req.ClientSecret != nil,
+		"auto_provision": company.OIDCAutoProvision,
+		"default_role":   company.OIDCDefaultRole,
+	})
+
+	return c.JSON(http.StatusOK, h.configResponse(company))
+}
+
+// --- helpers ---
+
+// ssoConfig is the company's registration with its client secret unsealed.
+func (h *OIDCHandler) ssoConfig(company *model.Company) (sso.Config, error) {
+	secret, err := sso.OpenClientSecret(company.OIDCClientSecret, h.cfg.JWTSecret)
+	if err != nil {
+		return sso.Config{}, err
+	}
+	return sso.Config{
+		Issuer:       company.OIDCIssuer,
+		ClientID:     company.OIDCClientID,
+		ClientSecret: secret,
+		RedirectURL:  h.cfg.OIDCCallbackURL(),
+	}, nil
+}
+
+func (h *OIDCHandler) configResponse(company *model.Company) model.OIDCConfigResponse {
+	return model.OIDCConfigResponse{
+		Enabled:         company.OIDCEnabled(),
+		Issuer:          company.OIDCIssuer,
+		ClientID:        company.OIDCClientID,
+		ClientSecretSet: company.OIDCClientSecret != "",
+		AutoProvision:   company.OIDCAutoProvision,
+		DefaultRole:     company.OIDCDefaultRole,
+		RedirectURL:     h.cfg.OIDCCallbackURL(),
+		LoginURL:        h.cfg.AppBaseURL + "/api/auth/oidc/" + company.Slug + "/login",
+	}
+}
+
+// fail sends the browser back to the login page with a machine-readable error code.
+func (h *OIDCHandler) fail(c echo.Context, code string) error {
+	return c.Redirect(http.StatusFound, h.cfg.AppBaseURL+"/login?sso_error="+url.QueryEscape(code))
+}
+
+// safeRedirect only allows same-origin relative paths, so the callback cannot be used
+// as an open redirect.
+func safeRedirect(p string) string {
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") || strings.HasPrefix(p, "/\\") {
+		return "/dashboard"
+	}
+	return p
+}
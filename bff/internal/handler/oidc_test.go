@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"hr-platform/bff/internal/auth"
+	"hr-platform/bff/internal/config"
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/sso"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestOIDCCallbackRejectsBadState(t *testing.T) {
+	states := &fakeOIDCStates{states: map[string]*model.OIDCState{
+		auth.HashToken("expired"):  {CompanyID: "company-1", ExpiresAt: time.Now().Add(-time.Minute)},
+		auth.HashToken("replayed"): {CompanyID: "company-1", ExpiresAt: time.Now().Add(time.Minute)},
+	}}
+	h := &OIDCHandler{stateRepo: states, cfg: &config.Config{AppBaseURL: "https://hr.example.com"}}
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"missing", "code=abc", "invalid_state"},
+		{"unknown", "state=forged&code=abc", "invalid_state"},
+		{"expired", "state=expired&code=abc", "invalid_state"},
+		{"provider error", "state=replayed&error=access_denied", "provider_error"},
+		{"replayed", "state=replayed&code=abc", "invalid_state"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			if err := h.Callback(echo.New().NewContext(req, rec)); err != nil {
+				t.Fatal(err)
+			}
+			want := "https://hr.example.com/login?sso_error=" + tt.want
+			if rec.Code != http.StatusFound || rec.Header().Get(echo.HeaderLocation) != want {
+				t.Fatalf("got %d to %s, want a redirect to %s", rec.Code, rec.Header().Get(echo.HeaderLocation), want)
+			}
+		})
+	}
+}
+
+func TestOIDCResolveUserLinksByEmail(t *testing.T) {
+	subject, otherSubject := "idp-user-1", "idp-someone-else"
+	tests := []struct {
+		name          string
+		autoProvision bool
+		users         []*model.User
+		wantUser      string
+		wantErr       string
+		wantLinked    string
+		wantAudit     string
+	}{
+		{
+			name:       "member by email is linked",
+			users:      []*model.User{{ID: "usr-1", Email: "somchai@example.com", CompanyID: "company-1"}},
+			wantUser:   "usr-1",
+			wantLinked: "usr-1",
+			wantAudit:  "user.oidc_linked",
+		},
+		{
+			name:     "member already linked by subject",
+			users:    []*model.User{{ID: "usr-1", Email: "old@example.com", CompanyID: "company-1", OIDCSubject: &subject}},
+			wantUser: "usr-1",
+		},
+		{
+			name:    "member linked to another subject",
+			users:   []*model.User{{ID: "usr-1", Email: "somchai@example.com", CompanyID: "company-1", OIDCSubject: &otherSubject}},
+			wantErr: "account_conflict",
+		},
+		{
+			name:    "account of another company",
+			users:   []*model.User{{ID: "usr-1", Email: "somchai@example.com", CompanyID: "company-2"}},
+			wantErr: "account_not_found",
+		},
+		{
+			name:          "account of another company, auto-provisioned",
+			autoProvision: true,
+			users:         []*model.User{{ID: "usr-1", Email: "somchai@example.com", CompanyID: "company-2"}},
+			wantErr:       "account_exists",
+		},
+		{
+			name:          "new account, auto-provisioned",
+			autoProvision: true,
+			wantUser:      "usr-new",
+			wantLinked:    "usr-new",
+			wantAudit:     "user.oidc_provisioned",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeOIDCUsers{users: tt.users}
+			audit := &fakeOIDCAudit{}
+			h := &OIDCHandler{userRepo: users, auditRepo: audit}
+			company := &model.Company{ID: "company-1", OIDCIssuer: "https://idp.example.com", OIDCAutoProvision: tt.autoProvision, OIDCDefaultRole: model.RoleEmployee}
+			ident := &sso.Identity{Subject: "idp-user-1", Email: "somchai@example.com", EmailVerified: true, Name: "Somchai"}
+
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+			user, code := h.resolveUser(c, company, ident)
+			if code != tt.wantErr {
+				t.Fatalf("error code %q, want %q", code, tt.wantErr)
+			}
+			if tt.wantErr != "" {
+				if len(users.linked) != 0 {
+					t.Fatalf("linked %v after refusing", users.linked)
+				}
+				return
+			}
+			if user.ID != tt.wantUser || user.CompanyID != "company-1" {
+				t.Fatalf("resolved %s in %s, want %s in company-1", user.ID, user.CompanyID, tt.wantUser)
+			}
+			if got := users.linked[tt.wantLinked]; tt.wantLinked != "" && got != "idp-user-1" {
+				t.Fatalf("%s linked to %q, want idp-user-1", tt.wantLinked, got)
+			}
+			if tt.wantLinked == "" && len(users.linked) != 0 {
+				t.Fatalf("linked %v, want no change", users.linked)
+			}
+			if len(audit.actions) > 0 != (tt.wantAudit != "") || (tt.wantAudit != "" && audit.actions[0] != tt.wantAudit) {
+				t.Fatalf("audit %v, want %q", audit.actions, tt.wantAudit)
+			}
+		})
+	}
+}
+
+// The session repository is left nil: both users must stop at the MFA challenge.
+func TestOIDCSignInRequiresMFA(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *model.User
+		wantEnroll bool
+	}{
+		{"enrolled user", &model.User{ID: "usr-1", CompanyID: "company-1", Role: model.RoleEmployee, MFAEnabled: true}, false},
+		{"required by the company", &model.User{ID: "usr-2", CompanyID: "company-1", Role: model.RoleAdmin}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenges := &fakeMFAChallenges{}
+			h := &OIDCHandler{mfaRepo: challenges, cfg: &config.Config{AppBaseURL: "https://hr.example.com"}}
+			company := &model.Company{ID: "company-1", MFARequiredRoles: "admin"}
+
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+			if err := h.signIn(c, company, tt.user, "/leave"); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(challenges.created) != 1 {
+				t.Fatalf("created %d challenges, want 1", len(challenges.created))
+			}
+			ch := challenges.created[0]
+			if ch.UserID != tt.user.ID || ch.CompanyID == nil || *ch.CompanyID != "company-1" || !ch.CompanyLocked {
+				t.Fatalf("challenge for %s in %v (locked %v), want a locked challenge in company-1", ch.UserID, ch.CompanyID, ch.CompanyLocked)
+			}
+			loc, err := url.Parse(rec.Header().Get(echo.HeaderLocation))
+			if err != nil || loc.Path != "/login" {
+				t.Fatalf("redirected to %s, want the login page", rec.Header().Get(echo.HeaderLocation))
+			}
+			frag, _ := url.ParseQuery(loc.Fragment)
+			if auth.HashToken(frag.Get("mfa_challenge")) != ch.TokenHash || frag.Get("redirect") != "/leave" {
+				t.Fatalf("fragment %q does not carry the challenge and redirect", loc.Fragment)
+			}
+			if (frag.Get("enroll") == "1") != tt.wantEnroll {
+				t.Fatalf("enroll = %q, want %v", frag.Get("enroll"), tt.wantEnroll)
+			}
+			if len(rec.Result().Cookies()) != 0 {
+				t.Fatal("session cookies set before the second factor")
+			}
+		})
+	}
+}
+
+// fakeOIDCUsers holds memberships as users: one per account and company.
+type fakeOIDCUsers struct {
+	users  []*model.User
+	linked map[string]string
+}
+
+func (f *fakeOIDCUsers) Create(_ context.Context, u *model.User) error {
+	u.ID = "usr-new"
+	f.users = append(f.users, u)
+	return nil
+}
+
+func (f *fakeOIDCUsers) GetInCompany(_ context.Context, id, companyID string) (*model.User, error) {
+	for _, u := range f.users {
+		if u.ID == id && u.CompanyID == companyID {
+			return u, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeOIDCUsers) GetByEmail(_ context.Context, email string) (*model.User, error) {
+	for _, u := range f.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeOIDCUsers) GetByOIDCSubject(_ context.Context, companyID, subject string) (*model.User, error) {
+	for _, u := range f.users {
+		if u.CompanyID == companyID && u.OIDCSubject != nil && *u.OIDCSubject == subject {
+			return u, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeOIDCUsers) LinkOIDCSubject(_ context.Context, id, companyID, subject string) error {
+	if f.linked == nil {
+		f.linked = map[string]string{}
+	}
+	f.linked[id] = subject
+	return nil
+}
+
+func (f *fakeOIDCUsers) UpdateLastLogin(context.Context, string) error { return nil }
+
+type fakeOIDCStates struct {
+	states map[string]*model.OIDCState
+}
+
+func (f *fakeOIDCStates) Create(_ context.Context, st *model.OIDCState) error {
+	f.states[st.StateHash] = st
+	return nil
+}
+
+// Consume deletes the state like the repository's DELETE ... RETURNING.
+func (f *fakeOIDCStates) Consume(_ context.Context, stateHash string) (*model.OIDCState, error) {
+	st, ok := f.states[stateHash]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	delete(f.states, stateHash)
+	return st, nil
+}
+
+func (f *fakeOIDCStates) DeleteExpired(context.Context) error { return nil }
+
+type fakeOIDCAudit struct {
+	actions []string
+}
+
+func (f *fakeOIDCAudit) Log(_ context.Context, _, _, action, _, _ string, _ interface{}) error {
+	f.actions = append(f.actions, action)
+	return nil
+}
+
+type fakeMFAChallenges struct {
+	created []*model.MFAChallenge
+}
+
+func (f *fakeMFAChallenges) CreateChallenge(_ context.Context, ch *model.MFAChallenge) error {
+	ch.ID = "challenge-1"
+	f.created = append(f.created, ch)
+	return nil
+}
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"hr-platform/bff/internal/client"
@@ -18,27 +17,17 @@ func NewOrgChartHandler(frappe *client.FrappeClient) *OrgChartHandler {
 }
 
 func (h *OrgChartHandler) GetTree(c echo.Context) error {
-	params := map[string]string{}
-	if company := c.QueryParam("company"); company != "" {
-		params["company"] = company
-	}
-
-	data, err := h.frappe.CallMethod("hr_core_ext.api.orgchart.get_org_tree", params)
+	tree, err := tenantFrappe(c, h.frappe).OrgChart().Tree(c.Request().Context())
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch org tree")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": tree})
 }
 
 func (h *OrgChartHandler) GetDepartments(c echo.Context) error {
-	params := map[string]string{}
-	if company := c.QueryParam("company"); company != "" {
-		params["company"] = company
-	}
-
-	data, err := h.frappe.CallMethod("hr_core_ext.api.orgchart.get_department_tree", params)
+	tree, err := tenantFrappe(c, h.frappe).OrgChart().Departments(c.Request().Context())
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch department tree")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": tree})
 }
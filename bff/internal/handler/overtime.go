@@ -2,8 +2,6 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"net/http"
 
 	"hr-platform/bff/internal/client"
@@ -24,11 +22,11 @@ func NewOvertimeHandler(frappe *client.FrappeClient, notifRepo *repository.Notif
 }
 
 func (h *OvertimeHandler) GetConfig(c echo.Context) error {
-	data, err := h.frappe.CallMethod("hr_core_ext.api.overtime.get_ot_config", map[string]string{})
+	config, err := tenantFrappe(c, h.frappe).Overtime().Config(c.Request().Context())
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch OT config")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": config})
 }
 
 func (h *OvertimeHandler) UpdateConfig(c echo.Context) error {
@@ -37,31 +35,13 @@ func (h *OvertimeHandler) UpdateConfig(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	params := map[string]string{}
-	if req.WeekdayOTRate != nil {
-		params["weekday_ot_rate"] = fmt.Sprintf("%.2f", *req.WeekdayOTRate)
-	}
-	if req.HolidayWorkMonthly != nil {
-		params["holiday_work_monthly"] = fmt.Sprintf("%.2f", *req.HolidayWorkMonthly)
-	}
-	if req.HolidayWorkDaily != nil {
-		params["holiday_work_daily"] = fmt.Sprintf("%.2f", *req.HolidayWorkDaily)
-	}
-	if req.HolidayOTRate != nil {
-		params["holiday_ot_rate"] = fmt.Sprintf("%.2f", *req.HolidayOTRate)
-	}
-	if req.StandardHoursPerDay != nil {
-		params["standard_hours_per_day"] = fmt.Sprintf("%d", *req.StandardHoursPerDay)
-	}
-	if req.StandardWorkingDays != nil {
-		params["standard_working_days"] = fmt.Sprintf("%d", *req.StandardWorkingDays)
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.overtime.update_ot_config", params)
+	tc := tenantFrappe(c, h.frappe)
+	config, err := tc.Overtime().UpdateConfig(c.Request().Context(), req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to update OT config")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	_ = tc.Invalidate(c.Request().Context(), client.MethodOTConfig)
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": config})
 }
 
 func (h *OvertimeHandler) Create(c echo.Context) error {
@@ -75,45 +55,40 @@ func (h *OvertimeHandler) Create(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.overtime.create_ot_request", map[string]string{
-		"employee_id": employeeID,
-		"ot_date":     req.OTDate,
-		"ot_type":     req.OTType,
-		"hours":       fmt.Sprintf("%.2f", req.Hours),
-		"reason":      req.Reason,
-	})
+	created, err := tenantFrappe(c, h.frappe).Overtime().Create(c.Request().Context(), employeeID, req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to create OT request")
 	}
-	return c.JSON(http.StatusCreated, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusCreated, map[string]interface{}{"data": created})
 }
 
 func (h *OvertimeHandler) List(c echo.Context) error {
 	employeeID := c.Get("employee_id").(string)
 	role := model.UserRole(c.Get("user_role").(string))
 
-	params := map[string]string{}
+	filter := client.OTRequestFilter{Status: c.QueryParam("status")}
 	if role == model.RoleEmployee {
 		if employeeID == "" {
 			return echo.NewHTTPError(http.StatusBadRequest, "employee not linked to this user")
 		}
-		params["employee_id"] = employeeID
-	}
-	if status := c.QueryParam("status"); status != "" {
-		params["status"] = status
+		filter.EmployeeID = employeeID
 	}
-	if month := c.QueryParam("month"); month != "" {
-		params["month"] = month
+	month, year, err := client.ParsePeriod(c.QueryParam("month"), c.QueryParam("year"))
+	if err != nil {
+		return frappeHTTPError(err, "invalid period")
 	}
-	if year := c.QueryParam("year"); year != "" {
-		params["year"] = year
+	filter.Month, filter.Year = month, year
+
+	pg, scope, err := readTeamPage(c, h.frappe)
+	if err != nil {
+		return err
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.overtime.get_ot_requests", params)
+	page, err := tenantFrappe(c, h.frappe).Overtime().RequestPage(c.Request().Context(), filter, pg)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch OT requests")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return respondPage(c, pg, inScope(page.Rows, scope), page.Total)
 }
 
 func (h *OvertimeHandler) Approve(c echo.Context) error {
@@ -123,16 +98,16 @@ func (h *OvertimeHandler) Approve(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	var method string
-	if req.Action == "approve" {
-		method = "hr_core_ext.api.overtime.approve_ot_request"
-	} else if req.Action == "reject" {
-		method = "hr_core_ext.api.overtime.reject_ot_request"
-	} else {
+	if req.Action != "approve" && req.Action != "reject" {
 		return echo.NewHTTPError(http.StatusBadRequest, "action must be 'approve' or 'reject'")
 	}
 
-	data, err := h.frappe.CallMethodPost(method, map[string]string{"request_id": requestID})
+	owner, err := authorizeApproval(c, h.frappe, client.DoctypeOvertimeRequest, requestID)
+	if err != nil {
+		return err
+	}
+
+	decision, err := tenantFrappe(c, h.frappe).Overtime().Decide(c.Request().Context(), requestID, req.Action)
 	if err != nil {
 		return frappeHTTPError(err, "failed to process OT request")
 	}
@@ -141,10 +116,8 @@ func (h *OvertimeHandler) Approve(c echo.Context) error {
 	if h.notifRepo != nil {
 		go func() {
 			companyID := c.Get("company_id").(string)
-			employeeID := c.Get("employee_id").(string)
-			// Try to look up the OT requester - for now notify the action taker as confirmation
-			if employeeID != "" {
-				u, err := h.userRepo.GetByFrappeEmployeeID(context.Background(), companyID, employeeID)
+			if owner.Employee != "" {
+				u, err := h.userRepo.GetByFrappeEmployeeID(context.Background(), companyID, owner.Employee)
 				if err == nil && u != nil {
 					status := "approved"
 					if req.Action == "reject" {
@@ -156,16 +129,18 @@ func (h *OvertimeHandler) Approve(c echo.Context) error {
 		}()
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": decision})
 }
 
+// Cancel withdraws an OT request. Only the requester or an approver of the requester may cancel it.
 func (h *OvertimeHandler) Cancel(c echo.Context) error {
 	requestID := c.Param("id")
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.overtime.cancel_ot_request", map[string]string{
-		"request_id": requestID,
-	})
+	if _, err := authorizeOwnerOrApprover(c, h.frappe, client.DoctypeOvertimeRequest, requestID); err != nil {
+		return err
+	}
+	res, err := tenantFrappe(c, h.frappe).Overtime().Cancel(c.Request().Context(), requestID)
 	if err != nil {
 		return frappeHTTPError(err, "failed to cancel OT request")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": res})
 }
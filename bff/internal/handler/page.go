@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"hr-platform/bff/internal/client"
+
+	"github.com/labstack/echo/v4"
+)
+
+// List endpoints share one paging contract. On top of their own filters they take:
+//
+//	limit   rows per page, 1 to maxPageLimit, defaultPageLimit if unset
+//	cursor  meta.next_cursor of the previous page
+//	sort    a field to sort by, prefixed with "-" for descending
+//
+// and answer {"data": [...], "meta": {...}, "links": {"next": ...}} (see respondPage).
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+var errInvalidCursor = echo.NewHTTPError(http.StatusBadRequest, "cursor is invalid or was issued for a different query")
+
+// pageCursor is what the opaque cursor carries: where the next page starts, and a
+// digest of the filters and sort it was issued for so it cannot continue another list.
+type pageCursor struct {
+	Offset int    `json:"o"`
+	Query  string `json:"q"`
+}
+
+type pageMeta struct {
+	Total      int     `json:"total"`
+	Limit      int     `json:"limit"`
+	Sort       string  `json:"sort,omitempty"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+type pageLinks struct {
+	Next *string `json:"next"`
+}
+
+// readPage parses limit, cursor and sort from the query string.
+func readPage(c echo.Context) (client.Page, error) {
+	pg := client.Page{Limit: defaultPageLimit, Sort: c.QueryParam("sort")}
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageLimit {
+			return pg, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxPageLimit))
+		}
+		pg.Limit = n
+	}
+	if v := c.QueryParam("cursor"); v != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(v)
+		if err != nil {
+			return pg, errInvalidCursor
+		}
+		var cur pageCursor
+		if err := json.Unmarshal(raw, &cur); err != nil || cur.Offset < 0 || cur.Query != queryDigest(c) {
+			return pg, errInvalidCursor
+		}
+		pg.Offset = cur.Offset
+	}
+	return pg, nil
+}
+
+// readTeamPage is readPage for lists managers see only their team of. The team is
+// pushed down to Frappe so totals and page boundaries are right, and returned so the
+// rows can be checked again with inScope.
+func readTeamPage(c echo.Context, frappe *client.FrappeClient) (client.Page, map[string]bool, error) {
+	pg, err := readPage(c)
+	if err != nil {
+		return pg, nil, err
+	}
+	scope, err := callerTeam(c, frappe)
+	if err != nil {
+		return pg, nil, err
+	}
+	if scope != nil {
+		pg.Employees = make([]string, 0, len(scope))
+		for id := range scope {
+			pg.Employees = append(pg.Employees, id)
+		}
+		slices.Sort(pg.Employees)
+	}
+	return pg, scope, nil
+}
+
+// queryDigest identifies the list a request asks for: every query param but the
+// paging position and size.
+func queryDigest(c echo.Context) string {
+	q := url.Values{}
+	for k, v := range c.QueryParams() {
+		if k != "cursor" && k != "limit" {
+			q[k] = v
+		}
+	}
+	sum := sha256.Sum256([]byte(c.Path() + "?" + q.Encode()))
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+// respondPage writes one page of a list. meta.total counts the rows on every page;
+// meta.next_cursor and links.next are null on the last one.
+func respondPage[T any](c echo.Context, pg client.Page, rows []T, total int) error {
+	meta := pageMeta{Total: total, Limit: pg.Limit, Sort: pg.Sort}
+	var links pageLinks
+
+	if next := pg.Offset + pg.Limit; next < total {
+		raw, _ := json.Marshal(pageCursor{Offset: next, Query: queryDigest(c)})
+		cursor := base64.RawURLEncoding.EncodeToString(raw)
+		meta.NextCursor = &cursor
+
+		q := c.Request().URL.Query()
+		q.Set("cursor", cursor)
+		q.Set("limit", strconv.Itoa(pg.Limit))
+		link := c.Request().URL.Path + "?" + q.Encode()
+		links.Next = &link
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":  rows,
+		"meta":  meta,
+		"links": links,
+	})
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"hr-platform/bff/internal/auth"
+	"hr-platform/bff/internal/config"
+	"hr-platform/bff/internal/mail"
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// passwordResetTTL is how long a reset link stays valid.
+const passwordResetTTL = time.Hour
+
+type PasswordHandler struct {
+	userRepo    *repository.UserRepository
+	resetRepo   *repository.PasswordResetRepository
+	sessionRepo *repository.SessionRepository
+	auditRepo   *repository.AuditRepository
+	mailer      mail.Sender
+	cfg         *config.Config
+}
+
+func NewPasswordHandler(
+	userRepo *repository.UserRepository,
+	resetRepo *repository.PasswordResetRepository,
+	sessionRepo *repository.SessionRepository,
+	auditRepo *repository.AuditRepository,
+	mailer mail.Sender,
+	cfg *config.Config,
+) *PasswordHandler {
+	return &PasswordHandler{
+		userRepo:    userRepo,
+		resetRepo:   resetRepo,
+		sessionRepo: sessionRepo,
+		auditRepo:   auditRepo,
+		mailer:      mailer,
+		cfg:         cfg,
+	}
+}
+
+// Forgot emails a single-use reset link. The response is the same whether or not
+// the email is registered, so it cannot be used to discover accounts.
+func (h *PasswordHandler) Forgot(c echo.Context) error {
+	var req model.ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Email == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "email is required")
+	}
+
+	sent := func() error {
+		return c.JSON(http.StatusOK, map[string]string{
+			"message": "if the email is registered, a reset link has been sent",
+		})
+	}
+
+	ctx := c.Request().Context()
+	user, err := h.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil || user.Status != model.StatusActive {
+		return sent()
+	}
+
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
+	}
+
+	// Only the most recent link works.
+	if err := h.resetRepo.InvalidateForUser(ctx, user.ID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create reset token")
+	}
+	reset := &model.PasswordReset{
+		TokenHash:   auth.HashToken(token),
+		UserID:      user.ID,
+		CompanyID:   user.CompanyID,
+		RequestedIP: c.RealIP(),
+		ExpiresAt:   time.Now().Add(passwordResetTTL),
+	}
+	if err := h.resetRepo.Create(ctx, reset); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create reset token")
+	}
+
+	link := fmt.Sprintf("%s/reset-password/%s", h.cfg.AppBaseURL, token)
+	if err := h.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %d minutes.\n\n%s\n\nIf you did not ask for this, you can ignore this email.\n",
+			user.FullName, int(passwordResetTTL.Minutes()), link),
+	}); err != nil {
+		log.Printf("password reset email to %s failed: %v", user.Email, err)
+	}
+
+	_ = h.auditRepo.Log(ctx, user.ID, user.CompanyID, "user.password_reset_requested", "user", user.ID, map[string]string{
+		"ip_address": c.RealIP(),
+	})
+
+	return sent()
+}
+
+// Reset sets a new password using a reset token and signs the user out everywhere.
+func (h *PasswordHandler) Reset(c echo.Context) error {
+	var req model.ResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Token == "" || req.Password == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "token and password are required")
+	}
+	if len(req.Password) < 8 {
+		return echo.NewHTTPError(http.StatusBadRequest, "password must be at least 8 characters")
+	}
+
+	ctx := c.Request().Context()
+	reset, err := h.resetRepo.GetByTokenHash(ctx, auth.HashToken(req.Token))
+	if err != nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid or expired reset token")
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to process password")
+	}
+
+	if err := h.resetRepo.MarkUsed(ctx, reset.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid or expired reset token")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to reset password")
+	}
+	if err := h.userRepo.UpdatePassword(ctx, reset.UserID, hash); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to reset password")
+	}
+	if _, err := h.sessionRepo.RevokeAllForUser(ctx, reset.UserID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke sessions")
+	}
+
+	_ = h.auditRepo.Log(ctx, reset.UserID, reset.CompanyID, "user.password_reset", "user", reset.UserID, map[string]string{
+		"ip_address": c.RealIP(),
+	})
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "password has been reset"})
+}
+
+// Change updates the current user's password and signs out their other sessions.
+func (h *PasswordHandler) Change(c echo.Context) error {
+	var req model.ChangePasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "current_password and new_password are required")
+	}
+	if len(req.NewPassword) < 8 {
+		return echo.NewHTTPError(http.StatusBadRequest, "password must be at least 8 characters")
+	}
+
+	ctx := c.Request().Context()
+	userID := c.Get("user_id").(string)
+	companyID := c.Get("company_id").(string)
+	sessionID, _ := c.Get("session_id").(string)
+
+	user, err := h.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "user not found")
+	}
+	if !auth.CheckPassword(user.PasswordHash, req.CurrentPassword) {
+		return echo.NewHTTPError(http.StatusBadRequest, "current password is incorrect")
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return echo.NewHTTPError(http.StatusBadRequest, "new password must differ from the current one")
+	}
+
+	hash, err := auth.HashPassword(req.NewPassword)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to process password")
+	}
+	if err := h.userRepo.UpdatePassword(ctx, userID, hash); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to change password")
+	}
+	_ = h.resetRepo.InvalidateForUser(ctx, userID)
+	if _, err := h.sessionRepo.RevokeOthersForUser(ctx, userID, sessionID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke sessions")
+	}
+
+	_ = h.auditRepo.Log(ctx, userID, companyID, "user.password_changed", "user", userID, nil)
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "password changed"})
+}
@@ -1,8 +1,7 @@
 package handler
 
 import (
-	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 
 	"hr-platform/bff/internal/client"
@@ -19,36 +18,35 @@ func NewPayrollHandler(frappe *client.FrappeClient) *PayrollHandler {
 	return &PayrollHandler{frappe: frappe}
 }
 
-// ListSlips returns salary slips. Employees see only their own; admin/HR see all.
+// ListSlips returns a page of salary slips. Callers see only their own unless they hold payroll.view_all.
 func (h *PayrollHandler) ListSlips(c echo.Context) error {
 	employeeID := c.Get("employee_id").(string)
-	role := model.UserRole(c.Get("user_role").(string))
 
-	params := map[string]string{}
+	month, year, err := client.ParsePeriod(c.QueryParam("month"), c.QueryParam("year"))
+	if err != nil {
+		return frappeHTTPError(err, "invalid period")
+	}
+	filter := client.SlipFilter{Month: month, Year: year}
 
-	// Employee can only see own slips
-	if role == model.RoleEmployee || role == model.RoleManager {
+	// Without payroll.view_all, callers can only see own slips
+	if !hasPermission(c, model.PermPayrollViewAll) {
 		if employeeID == "" {
 			return echo.NewHTTPError(http.StatusBadRequest, "employee not linked to this user")
 		}
-		params["employee_id"] = employeeID
+		filter.EmployeeID = employeeID
 	}
 
-	if year := c.QueryParam("year"); year != "" {
-		params["year"] = year
-	}
-	if month := c.QueryParam("month"); month != "" {
-		params["month"] = month
+	pg, err := readPage(c)
+	if err != nil {
+		return err
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.payroll.get_salary_slips", params)
+	page, err := tenantFrappe(c, h.frappe).Payroll().SlipPage(c.Request().Context(), filter, pg)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch salary slips")
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
-	})
+	return respondPage(c, pg, page.Rows, page.Total)
 }
 
 // GetSlip returns a single salary slip detail.
@@ -58,29 +56,23 @@ func (h *PayrollHandler) GetSlip(c echo.Context) error {
 		slipID = c.Param("id")
 	}
 	employeeID := c.Get("employee_id").(string)
-	role := model.UserRole(c.Get("user_role").(string))
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.payroll.get_salary_slip_detail", map[string]string{
-		"slip_id": slipID,
-	})
+	slip, err := tenantFrappe(c, h.frappe).Payroll().Slip(c.Request().Context(), slipID)
+	if errors.Is(err, client.ErrCrossTenant) {
+		// Slips from other companies are reported as missing
+		return echo.NewHTTPError(http.StatusNotFound, "salary slip not found")
+	}
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch salary slip")
 	}
 
-	// For non-admin roles, verify the slip belongs to this employee
-	if role == model.RoleEmployee || role == model.RoleManager {
-		var slip struct {
-			Employee string `json:"employee"`
-		}
-		if err := json.Unmarshal(data, &slip); err == nil {
-			if slip.Employee != employeeID {
-				return echo.NewHTTPError(http.StatusForbidden, "access denied")
-			}
-		}
+	// Without payroll.view_all, verify the slip belongs to this employee
+	if !hasPermission(c, model.PermPayrollViewAll) && slip.Employee != employeeID {
+		return echo.NewHTTPError(http.StatusForbidden, "access denied")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": slip,
 	})
 }
 
@@ -93,24 +85,13 @@ func (h *PayrollHandler) SetupEmployee(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	if req.BaseSalary <= 0 {
-		return echo.NewHTTPError(http.StatusBadRequest, "base_salary is required and must be positive")
-	}
-
-	params := map[string]string{
-		"employee_id": employeeID,
-		"base_salary": fmt.Sprintf("%.2f", req.BaseSalary),
-		"housing":     fmt.Sprintf("%.2f", req.Housing),
-		"transport":   fmt.Sprintf("%.2f", req.Transport),
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.payroll.setup_employee_payroll", params)
+	setup, err := tenantFrappe(c, h.frappe).Payroll().SetupEmployee(c.Request().Context(), employeeID, req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to setup employee payroll")
 	}
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": setup,
 	})
 }
 
@@ -121,25 +102,13 @@ func (h *PayrollHandler) Process(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	if req.Month < 1 || req.Month > 12 || req.Year < 2000 {
-		return echo.NewHTTPError(http.StatusBadRequest, "valid month (1-12) and year are required")
-	}
-
-	params := map[string]string{
-		"month": fmt.Sprintf("%d", req.Month),
-		"year":  fmt.Sprintf("%d", req.Year),
-	}
-	if req.Company != "" {
-		params["company"] = req.Company
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.payroll.process_payroll", params)
+	run, err := tenantFrappe(c, h.frappe).Payroll().Process(c.Request().Context(), req.Month, req.Year)
 	if err != nil {
 		return frappeHTTPError(err, "failed to process payroll")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": run,
 	})
 }
 
@@ -150,20 +119,13 @@ func (h *PayrollHandler) Submit(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	if req.Month < 1 || req.Month > 12 || req.Year < 2000 {
-		return echo.NewHTTPError(http.StatusBadRequest, "valid month (1-12) and year are required")
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.payroll.submit_payroll", map[string]string{
-		"month": fmt.Sprintf("%d", req.Month),
-		"year":  fmt.Sprintf("%d", req.Year),
-	})
+	submission, err := tenantFrappe(c, h.frappe).Payroll().Submit(c.Request().Context(), req.Month, req.Year)
 	if err != nil {
 		return frappeHTTPError(err, "failed to submit payroll")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": submission,
 	})
 }
 
@@ -176,20 +138,12 @@ func (h *PayrollHandler) GenerateSlip(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	if req.Month < 1 || req.Month > 12 || req.Year < 2000 {
-		return echo.NewHTTPError(http.StatusBadRequest, "valid month (1-12) and year are required")
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.payroll.generate_salary_slip", map[string]string{
-		"employee_id": employeeID,
-		"month":       fmt.Sprintf("%d", req.Month),
-		"year":        fmt.Sprintf("%d", req.Year),
-	})
+	slip, err := tenantFrappe(c, h.frappe).Payroll().GenerateSlip(c.Request().Context(), employeeID, req.Month, req.Year)
 	if err != nil {
 		return frappeHTTPError(err, "failed to generate salary slip")
 	}
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": slip,
 	})
 }
@@ -1,8 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
-	"fmt"
 	"net/http"
 
 	"hr-platform/bff/internal/client"
@@ -20,11 +18,11 @@ func NewProvidentFundHandler(frappe *client.FrappeClient) *ProvidentFundHandler
 }
 
 func (h *ProvidentFundHandler) GetConfig(c echo.Context) error {
-	data, err := h.frappe.CallMethod("hr_core_ext.api.provident_fund.get_pvd_config", map[string]string{})
+	config, err := tenantFrappe(c, h.frappe).ProvidentFund().Config(c.Request().Context())
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch PVD config")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": config})
 }
 
 func (h *ProvidentFundHandler) UpdateConfig(c echo.Context) error {
@@ -33,36 +31,22 @@ func (h *ProvidentFundHandler) UpdateConfig(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	params := map[string]string{}
-	if req.MinRate != nil {
-		params["min_rate"] = fmt.Sprintf("%.2f", *req.MinRate)
-	}
-	if req.MaxRate != nil {
-		params["max_rate"] = fmt.Sprintf("%.2f", *req.MaxRate)
-	}
-	if req.DefaultEmployeeRate != nil {
-		params["default_employee_rate"] = fmt.Sprintf("%.2f", *req.DefaultEmployeeRate)
-	}
-	if req.DefaultEmployerRate != nil {
-		params["default_employer_rate"] = fmt.Sprintf("%.2f", *req.DefaultEmployerRate)
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.provident_fund.update_pvd_config", params)
+	tc := tenantFrappe(c, h.frappe)
+	config, err := tc.ProvidentFund().UpdateConfig(c.Request().Context(), req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to update PVD config")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	_ = tc.Invalidate(c.Request().Context(), client.MethodPVDConfig)
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": config})
 }
 
 func (h *ProvidentFundHandler) GetEmployee(c echo.Context) error {
 	employeeID := c.Param("id")
-	data, err := h.frappe.CallMethod("hr_core_ext.api.provident_fund.get_employee_pvd", map[string]string{
-		"employee_id": employeeID,
-	})
+	pvd, err := tenantFrappe(c, h.frappe).ProvidentFund().Employee(c.Request().Context(), employeeID)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch employee PVD")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": pvd})
 }
 
 func (h *ProvidentFundHandler) EnrollEmployee(c echo.Context) error {
@@ -72,19 +56,11 @@ func (h *ProvidentFundHandler) EnrollEmployee(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	params := map[string]string{"employee_id": employeeID}
-	if req.EmployeeRate != nil {
-		params["employee_rate"] = fmt.Sprintf("%.2f", *req.EmployeeRate)
-	}
-	if req.EmployerRate != nil {
-		params["employer_rate"] = fmt.Sprintf("%.2f", *req.EmployerRate)
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.provident_fund.enroll_employee_pvd", params)
+	pvd, err := tenantFrappe(c, h.frappe).ProvidentFund().Enroll(c.Request().Context(), employeeID, req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to enroll employee in PVD")
 	}
-	return c.JSON(http.StatusCreated, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusCreated, map[string]interface{}{"data": pvd})
 }
 
 func (h *ProvidentFundHandler) UpdateEmployee(c echo.Context) error {
@@ -94,44 +70,34 @@ func (h *ProvidentFundHandler) UpdateEmployee(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	params := map[string]string{"employee_id": employeeID}
-	if req.EmployeeRate != nil {
-		params["employee_rate"] = fmt.Sprintf("%.2f", *req.EmployeeRate)
-	}
-	if req.EmployerRate != nil {
-		params["employer_rate"] = fmt.Sprintf("%.2f", *req.EmployerRate)
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.provident_fund.update_employee_pvd", params)
+	pvd, err := tenantFrappe(c, h.frappe).ProvidentFund().Update(c.Request().Context(), employeeID, req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to update employee PVD")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": pvd})
 }
 
 func (h *ProvidentFundHandler) UnenrollEmployee(c echo.Context) error {
 	employeeID := c.Param("id")
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.provident_fund.unenroll_employee_pvd", map[string]string{
-		"employee_id": employeeID,
-	})
+	pvd, err := tenantFrappe(c, h.frappe).ProvidentFund().Unenroll(c.Request().Context(), employeeID)
 	if err != nil {
 		return frappeHTTPError(err, "failed to unenroll employee from PVD")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": pvd})
 }
 
 func (h *ProvidentFundHandler) GetReport(c echo.Context) error {
-	month := c.QueryParam("month")
-	year := c.QueryParam("year")
-	if month == "" || year == "" {
+	if c.QueryParam("month") == "" || c.QueryParam("year") == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "month and year are required")
 	}
+	month, year, err := client.ParsePeriod(c.QueryParam("month"), c.QueryParam("year"))
+	if err != nil {
+		return frappeHTTPError(err, "invalid period")
+	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.provident_fund.get_pvd_report", map[string]string{
-		"month": month, "year": year,
-	})
+	report, err := tenantFrappe(c, h.frappe).ProvidentFund().Report(c.Request().Context(), month, year)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch PVD report")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": report})
 }
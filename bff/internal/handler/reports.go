@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"hr-platform/bff/internal/client"
@@ -18,92 +17,82 @@ func NewReportsHandler(frappe *client.FrappeClient) *ReportsHandler {
 }
 
 func (h *ReportsHandler) EmployeeSummary(c echo.Context) error {
-	params := map[string]string{}
-	if company := c.QueryParam("company"); company != "" {
-		params["company"] = company
-	}
-
-	data, err := h.frappe.CallMethod("hr_core_ext.api.reports.get_employee_summary", params)
+	report, err := tenantFrappe(c, h.frappe).Report().EmployeeSummary(c.Request().Context())
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch employee summary")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": report})
 }
 
 func (h *ReportsHandler) AttendanceReport(c echo.Context) error {
-	month := c.QueryParam("month")
-	year := c.QueryParam("year")
-	if month == "" || year == "" {
+	if c.QueryParam("month") == "" || c.QueryParam("year") == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "month and year are required")
 	}
-
-	params := map[string]string{"month": month, "year": year}
-	if company := c.QueryParam("company"); company != "" {
-		params["company"] = company
+	month, year, err := client.ParsePeriod(c.QueryParam("month"), c.QueryParam("year"))
+	if err != nil {
+		return frappeHTTPError(err, "invalid period")
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.reports.get_attendance_report", params)
+	report, err := tenantFrappe(c, h.frappe).Report().Attendance(c.Request().Context(), month, year)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch attendance report")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": report})
 }
 
 func (h *ReportsHandler) LeaveReport(c echo.Context) error {
-	year := c.QueryParam("year")
-	if year == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "year is required")
-	}
-
-	params := map[string]string{"year": year}
-	if company := c.QueryParam("company"); company != "" {
-		params["company"] = company
+	year, err := queryYear(c)
+	if err != nil {
+		return err
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.reports.get_leave_report", params)
+	report, err := tenantFrappe(c, h.frappe).Report().Leave(c.Request().Context(), year)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch leave report")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": report})
 }
 
 func (h *ReportsHandler) PayrollReport(c echo.Context) error {
-	year := c.QueryParam("year")
-	if year == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "year is required")
-	}
-
-	params := map[string]string{"year": year}
-	if month := c.QueryParam("month"); month != "" {
-		params["month"] = month
+	year, err := queryYear(c)
+	if err != nil {
+		return err
 	}
-	if company := c.QueryParam("company"); company != "" {
-		params["company"] = company
+	month, _, err := client.ParsePeriod(c.QueryParam("month"), "")
+	if err != nil {
+		return frappeHTTPError(err, "invalid period")
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.reports.get_payroll_report", params)
+	report, err := tenantFrappe(c, h.frappe).Report().Payroll(c.Request().Context(), month, year)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch payroll report")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": report})
 }
 
+// TaxReport returns the monthly totals of a year, or the PND1 return of one month if
+// month is given.
 func (h *ReportsHandler) TaxReport(c echo.Context) error {
-	year := c.QueryParam("year")
-	if year == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "year is required")
+	year, err := queryYear(c)
+	if err != nil {
+		return err
 	}
-
-	params := map[string]string{"year": year}
-	if month := c.QueryParam("month"); month != "" {
-		params["month"] = month
+	month, _, err := client.ParsePeriod(c.QueryParam("month"), "")
+	if err != nil {
+		return frappeHTTPError(err, "invalid period")
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.reports.get_tax_report", params)
+	reports := tenantFrappe(c, h.frappe).Report()
+	var report interface{}
+	if month != 0 {
+		report, err = reports.TaxMonth(c.Request().Context(), month, year)
+	} else {
+		report, err = reports.Tax(c.Request().Context(), year)
+	}
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch tax report")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": report})
 }
 
 func (h *ReportsHandler) ExportCSV(c echo.Context) error {
@@ -111,21 +100,14 @@ func (h *ReportsHandler) ExportCSV(c echo.Context) error {
 	if reportType == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "report type is required")
 	}
-
-	params := map[string]string{"report_type": reportType}
-	if year := c.QueryParam("year"); year != "" {
-		params["year"] = year
-	}
-	if month := c.QueryParam("month"); month != "" {
-		params["month"] = month
-	}
-	if company := c.QueryParam("company"); company != "" {
-		params["company"] = company
+	month, year, err := client.ParsePeriod(c.QueryParam("month"), c.QueryParam("year"))
+	if err != nil {
+		return frappeHTTPError(err, "invalid period")
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.reports.export_report_csv", params)
+	export, err := tenantFrappe(c, h.frappe).Report().Export(c.Request().Context(), reportType, month, year)
 	if err != nil {
 		return frappeHTTPError(err, "failed to export report")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": export})
 }
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RoleHandler struct {
+	roleRepo  *repository.RoleRepository
+	auditRepo *repository.AuditRepository
+}
+
+func NewRoleHandler(roleRepo *repository.RoleRepository, auditRepo *repository.AuditRepository) *RoleHandler {
+	return &RoleHandler{roleRepo: roleRepo, auditRepo: auditRepo}
+}
+
+// ListPermissions returns the permission catalog.
+func (h *RoleHandler) ListPermissions(c echo.Context) error {
+	perms, err := h.roleRepo.ListPermissions(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to list permissions")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": perms})
+}
+
+// List returns the built-in roles and the company's custom roles with their permissions.
+func (h *RoleHandler) List(c echo.Context) error {
+	roles, err := h.roleRepo.ListForCompany(c.Request().Context(), c.Get("company_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to list roles")
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": roles})
+}
+
+// Create adds a custom role. Callers can only grant permissions they hold themselves.
+func (h *RoleHandler) Create(c echo.Context) error {
+	var req model.RoleRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	perms, err := h.validateRoleRequest(c, &req)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+	actorID := c.Get("user_id").(string)
+
+	role := &model.Role{
+		CompanyID:   &companyID,
+		Slug:        slugify(req.Name),
+		Name:        req.Name,
+		Description: req.Description,
+		BaseRole:    req.BaseRole,
+		Permissions: perms,
+	}
+	if role.Slug == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "name must contain letters or digits")
+	}
+
+	// Check slug uniqueness against built-in and existing custom roles
+	existing, err := h.roleRepo.ListForCompany(ctx, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create role")
+	}
+	for _, r := range existing {
+		if r.Slug == role.Slug {
+			return echo.NewHTTPError(http.StatusConflict, "a role with this name already exists")
+		}
+	}
+
+	if err := h.roleRepo.Create(ctx, role); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create role")
+	}
+
+	_ = h.auditRepo.Log(ctx, actorID, companyID, "role.created", "role", role.ID, map[string]interface{}{
+		"name":        role.Name,
+		"base_role":   role.BaseRole,
+		"permissions": role.Permissions,
+	})
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{"data": role})
+}
+
+// Update changes a custom role. Built-in roles cannot be edited.
+func (h *RoleHandler) Update(c echo.Context) error {
+	var req model.RoleRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+	actorID := c.Get("user_id").(string)
+
+	role, err := h.customRole(c)
+	if err != nil {
+		return err
+	}
+	// Removing a permission the caller lacks would be fine, but keeping one they lack
+	// would let them take over a role they could not have created.
+	if err := requireHeldPermissions(c, role.Permissions); err != nil {
+		return err
+	}
+	perms, err := h.validateRoleRequest(c, &req)
+	if err != nil {
+		return err
+	}
+
+	old := role.Permissions
+	role.Name = req.Name
+	role.Description = req.Description
+	role.BaseRole = req.BaseRole
+	role.Permissions = perms
+	if err := h.roleRepo.Update(ctx, role); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update role")
+	}
+
+	_ = h.auditRepo.Log(ctx, actorID, companyID, "role.updated", "role", role.ID, map[string]interface{}{
+		"name":            role.Name,
+		"base_role":       role.BaseRole,
+		"old_permissions": old,
+		"permissions":     role.Permissions,
+	})
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": role})
+}
+
+// Delete removes a custom role that no user holds.
+func (h *RoleHandler) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+	actorID := c.Get("user_id").(string)
+
+	role, err := h.customRole(c)
+	if err != nil {
+		return err
+	}
+	n, err := h.roleRepo.CountUsers(ctx, role.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete role")
+	}
+	if n > 0 {
+		return echo.NewHTTPError(http.StatusConflict, "role is still assigned to users")
+	}
+	if err := h.roleRepo.Delete(ctx, role.ID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete role")
+	}
+
+	_ = h.auditRepo.Log(ctx, actorID, companyID, "role.deleted", "role", role.ID, map[string]string{
+		"name": role.Name,
+	})
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "role deleted"})
+}
+
+// --- helpers ---
+
+// customRole loads the :id role and checks it is one of the caller's company's custom roles.
+func (h *RoleHandler) customRole(c echo.Context) (*model.Role, error) {
+	role, err := h.roleRepo.GetByID(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "role not found")
+	}
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to load role")
+	}
+	if role.BuiltIn() {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "built-in roles cannot be changed")
+	}
+	if *role.CompanyID != c.Get("company_id").(string) {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "role not found")
+	}
+	return role, nil
+}
+
+// validateRoleRequest normalises the request and returns its de-duplicated permissions.
+func (h *RoleHandler) validateRoleRequest(c echo.Context, req *model.RoleRequest) ([]string, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Name == "" || len(req.Name) > 100 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "name is required (max 100 characters)")
+	}
+	if len(req.Description) > 255 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "description is too long (max 255 characters)")
+	}
+	// Custom roles never get admin's row-level access.
+	if req.BaseRole != model.RoleHR && req.BaseRole != model.RoleManager && req.BaseRole != model.RoleEmployee {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "base_role must be hr, manager, or employee")
+	}
+	if req.BaseRole == model.RoleEmployee {
+		for _, p := range req.Permissions {
+			if slices.Contains(model.TeamPermissions, model.Permission(p)) {
+				return nil, echo.NewHTTPError(http.StatusBadRequest, "the "+p+" permission needs base_role manager or hr")
+			}
+		}
+	}
+
+	catalog, err := h.roleRepo.ListPermissions(c.Request().Context())
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to load permissions")
+	}
+	known := map[string]bool{}
+	for _, p := range catalog {
+		known[p.Name] = true
+	}
+
+	seen := map[string]bool{}
+	perms := []string{}
+	for _, p := range req.Permissions {
+		if !known[p] {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "unknown permission: "+p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			perms = append(perms, p)
+		}
+	}
+	if err := requireHeldPermissions(c, perms); err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
+
+// hasPermission reports whether the caller holds p (loaded by PermissionMiddleware).
+func hasPermission(c echo.Context, p model.Permission) bool {
+	perms, _ := c.Get("permissions").(model.PermissionSet)
+	return perms.Has(p)
+}
+
+// requireHeldPermissions stops callers from granting or reassigning permissions they do
+// not hold themselves.
+func requireHeldPermissions(c echo.Context, perms []string) error {
+	for _, p := range perms {
+		if !hasPermission(c, model.Permission(p)) {
+			return echo.NewHTTPError(http.StatusForbidden, "you cannot grant the "+p+" permission")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hr-platform/bff/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+// The repository is left nil: the base role is checked before the catalog is loaded.
+func TestRoleOnEmployeeBaseRefusesTeamPermissions(t *testing.T) {
+	h := &RoleHandler{}
+	for _, p := range model.TeamPermissions {
+		body := `{"name":"Clerk","base_role":"employee","permissions":["` + string(p) + `"]}`
+		req := httptest.NewRequest(http.MethodPost, "/api/roles", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+		c.Set("company_id", "company-acme")
+		c.Set("user_id", "user-admin")
+		c.Set("permissions", model.NewPermissionSet([]string{string(p)}))
+
+		he, ok := h.Create(c).(*echo.HTTPError)
+		if !ok || he.Code != http.StatusBadRequest {
+			t.Errorf("%s on base_role employee: got %v, want 400", p, he)
+		}
+	}
+}
@@ -0,0 +1,1049 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"hr-platform/bff/internal/auth"
+	"hr-platform/bff/internal/client"
+	"hr-platform/bff/internal/config"
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SCIM list responses are capped so a client asking for everything still gets pages.
+const (
+	scimDefaultCount = 100
+	scimMaxCount     = 500
+)
+
+// The SCIM handler only needs a slice of each repository; the interfaces let the
+// protocol be exercised without Postgres (see scim_test.go).
+type scimUserStore interface {
+	Create(ctx context.Context, u *model.User) error
+	AddMembership(ctx context.Context, userID, companyID string, role model.UserRole, frappeEmployeeID *string) error
+	GetInCompany(ctx context.Context, id, companyID string) (*model.User, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	ListByCompany(ctx context.Context, companyID string) ([]model.User, error)
+	ListMemberships(ctx context.Context, userID string) ([]model.Membership, error)
+	UpdateFullName(ctx context.Context, id, fullName string) error
+	UpdateRole(ctx context.Context, id, companyID string, role model.UserRole, roleID *string) error
+	UpdateStatus(ctx context.Context, id, companyID string, status model.UserStatus) error
+	LinkEmployee(ctx context.Context, id, companyID, frappeEmployeeID string) error
+	SetSCIMExternalID(ctx context.Context, id, companyID string, externalID *string) error
+}
+
+type scimRoleStore interface {
+	ListForCompany(ctx context.Context, companyID string) ([]model.Role, error)
+	GetBuiltIn(ctx context.Context, role model.UserRole) (*model.Role, error)
+}
+
+type scimCompanyStore interface {
+	GetByID(ctx context.Context, id string) (*model.Company, error)
+	UpdateSCIM(ctx context.Context, c *model.Company) error
+}
+
+type scimSessionStore interface {
+	RevokeAllInCompany(ctx context.Context, userID, companyID string) (int64, error)
+}
+
+type scimAuditLog interface {
+	Log(ctx context.Context, actorID, companyID, action, targetType, targetID string, details interface{}) error
+}
+
+type scimEmployeeCreator interface {
+	CreateEmployee(ctx context.Context, employeeName, company, department, designation string) (string, error)
+}
+
+// SCIMHandler serves SCIM 2.0 /Users and /Groups for a company's identity provider.
+//
+// Users are the company's memberships: deactivating one suspends the membership (DELETE
+// disables it) and revokes its sessions, like UserHandler.ChangeStatus. Groups are the
+// company's roles, built-in and custom; since a membership has exactly one role, adding a
+// user to a group moves them out of their previous one, and removing them falls back to
+// the built-in employee role. Roles themselves are managed in the app, not over SCIM.
+type SCIMHandler struct {
+	users     scimUserStore
+	roles     scimRoleStore
+	companies scimCompanyStore
+	sessions  scimSessionStore
+	audit     scimAuditLog
+	frappe    scimEmployeeCreator
+	cfg       *config.Config
+}
+
+func NewSCIMHandler(
+	userRepo *repository.UserRepository,
+	roleRepo *repository.RoleRepository,
+	companyRepo *repository.CompanyRepository,
+	sessionRepo *repository.SessionRepository,
+	auditRepo *repository.AuditRepository,
+	frappe *client.FrappeClient,
+	cfg *config.Config,
+) *SCIMHandler {
+	return &SCIMHandler{
+		users:     userRepo,
+		roles:     roleRepo,
+		companies: companyRepo,
+		sessions:  sessionRepo,
+		audit:     auditRepo,
+		frappe:    frappe,
+		cfg:       cfg,
+	}
+}
+
+// --- Token management (JWT-authenticated, company.security) ---
+
+// GetConfig returns whether SCIM is enabled. The token itself is never returned.
+func (h *SCIMHandler) GetConfig(c echo.Context) error {
+	company, err := h.companies.GetByID(c.Request().Context(), c.Get("company_id").(string))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+	return c.JSON(http.StatusOK, h.configResponse(company, ""))
+}
+
+// UpdateConfig sets whether provisioned users also get a Frappe employee record.
+func (h *SCIMHandler) UpdateConfig(c echo.Context) error {
+	var req model.SCIMConfigRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+	company, err := h.companies.GetByID(ctx, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+	company.SCIMCreateEmployees = req.CreateEmployees
+	if err := h.companies.UpdateSCIM(ctx, company); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update scim settings")
+	}
+
+	_ = h.audit.Log(ctx, c.Get("user_id").(string), companyID, "company.scim_updated", "company", companyID, map[string]bool{
+		"create_employees": req.CreateEmployees,
+	})
+	return c.JSON(http.StatusOK, h.configResponse(company, ""))
+}
+
+// RotateToken issues a new SCIM token, replacing any previous one. The token is only
+// shown in this response.
+func (h *SCIMHandler) RotateToken(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+	company, err := h.companies.GetByID(ctx, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+
+	secret, err := auth.NewOpaqueToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
+	}
+	raw := model.SCIMTokenPrefix + secret
+	company.SCIMTokenPrefix = raw[:len(model.SCIMTokenPrefix)+6]
+	company.SCIMTokenHash = auth.HashToken(raw)
+	if err := h.companies.UpdateSCIM(ctx, company); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to save token")
+	}
+
+	_ = h.audit.Log(ctx, c.Get("user_id").(string), companyID, "company.scim_token_rotated", "company", companyID, map[string]string{
+		"token_prefix": company.SCIMTokenPrefix,
+	})
+	return c.JSON(http.StatusCreated, h.configResponse(company, raw))
+}
+
+// RevokeToken turns SCIM off. Provisioned users are left as they are.
+func (h *SCIMHandler) RevokeToken(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+	company, err := h.companies.GetByID(ctx, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+	company.SCIMTokenPrefix, company.SCIMTokenHash = "", ""
+	if err := h.companies.UpdateSCIM(ctx, company); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke token")
+	}
+
+	_ = h.audit.Log(ctx, c.Get("user_id").(string), companyID, "company.scim_token_revoked", "company", companyID, nil)
+	return c.JSON(http.StatusOK, map[string]string{"message": "scim token revoked"})
+}
+
+func (h *SCIMHandler) configResponse(company *model.Company, token string) model.SCIMConfigResponse {
+	return model.SCIMConfigResponse{
+		Enabled:         company.SCIMEnabled(),
+		TokenPrefix:     company.SCIMTokenPrefix,
+		CreateEmployees: company.SCIMCreateEmployees,
+		BaseURL:         h.scimBaseURL(),
+		Token:           token,
+	}
+}
+
+func (h *SCIMHandler) scimBaseURL() string {
+	return h.cfg.AppBaseURL + "/api/scim/v2"
+}
+
+// --- SCIM protocol (SCIMAuth) ---
+
+// ServiceProviderConfig advertises what this server supports (RFC 7643 section 5).
+func (h *SCIMHandler) ServiceProviderConfig(c echo.Context) error {
+	return scimJSON(c, http.StatusOK, map[string]interface{}{
+		"schemas":        []string{model.SCIMSchemaSPConfig},
+		"patch":          map[string]bool{"supported": true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": scimMaxCount},
+		"changePassword": map[string]bool{"supported": false},
+		"sort":           map[string]bool{"supported": false},
+		"etag":           map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer token",
+			"description": "The company's SCIM token, issued under company settings",
+			"primary":     true,
+		}},
+	})
+}
+
+func (h *SCIMHandler) ListUsers(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+
+	attr, value, err := parseSCIMFilter(c.QueryParam("filter"))
+	if err != nil {
+		return scimError(http.StatusBadRequest, "invalidFilter", err.Error())
+	}
+	users, err := h.users.ListByCompany(ctx, companyID)
+	if err != nil {
+		return scimError(http.StatusInternalServerError, "", "failed to list users")
+	}
+	roles, err := h.roles.ListForCompany(ctx, companyID)
+	if err != nil {
+		return scimError(http.StatusInternalServerError, "", "failed to list groups")
+	}
+
+	matched := []model.SCIMUser{}
+	for i := range users {
+		u := &users[i]
+		switch attr {
+		case "":
+		case "username", "emails.value", "emails":
+			if !strings.EqualFold(u.Email, value) {
+				continue
+			}
+		case "externalid":
+			if u.SCIMExternalID == nil || *u.SCIMExternalID != value {
+				continue
+			}
+		case "id":
+			if u.ID != value {
+				continue
+			}
+		default:
+			return scimError(http.StatusBadRequest, "invalidFilter", "unsupported filter attribute: "+attr)
+		}
+		matched = append(matched, h.toSCIMUser(u, roles))
+	}
+	return scimList(c, matched)
+}
+
+func (h *SCIMHandler) GetUser(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+
+	user, err := h.users.GetInCompany(ctx, c.Param("id"), companyID)
+	if err != nil {
+		return scimError(http.StatusNotFound, "", "user not found")
+	}
+	roles, err := h.roles.ListForCompany(ctx, companyID)
+	if err != nil {
+		return scimError(http.StatusInternalServerError, "", "failed to list groups")
+	}
+	return scimJSON(c, http.StatusOK, h.toSCIMUser(user, roles))
+}
+
+// CreateUser provisions a member with the built-in employee role. An account that already
+// exists in another company only gains a membership here; its name and password stay
+// its own. New accounts have no password and sign in through SSO or a password reset.
+func (h *SCIMHandler) CreateUser(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+
+	var req model.SCIMUser
+	if err := scimBind(c, &req); err != nil {
+		return err
+	}
+	email := scimEmail(&req)
+	if email == "" {
+		return scimError(http.StatusBadRequest, "invalidValue", "userName must be an email address")
+	}
+
+	company, err := h.companies.GetByID(ctx, companyID)
+	if err != nil {
+		return scimError(http.StatusInternalServerError, "", "failed to load company")
+	}
+
+	account, err := h.users.GetByEmail(ctx, email)
+	if err == nil {
+		if _, err := h.users.GetInCompany(ctx, account.ID, companyID); err == nil {
+			return scimError(http.StatusConflict, "uniqueness", "user is already a member of this company")
+		}
+		if err := h.users.AddMembership(ctx, account.ID, companyID, model.RoleEmployee, nil); err != nil {
+			return scimError(http.StatusInternalServerError, "", "failed to create user")
+		}
+	} else {
+		account = &model.User{
+			Email:     email,
+			FullName:  scimFullName(&req, email),
+			Role:      model.RoleEmployee,
+			Status:    model.StatusActive,
+			CompanyID: companyID,
+		}
+		if err := h.users.Create(ctx, account); err != nil {
+			return scimError(http.StatusInternalServerError, "", "failed to create user")
+		}
+	}
+
+	if req.ExternalID != "" {
+		if err := h.users.SetSCIMExternalID(ctx, account.ID, companyID, &req.ExternalID); err != nil {
+			return scimError(http.StatusConflict, "uniqueness", "externalId is already in use")
+		}
+	}
+
+	user, err := h.users.GetInCompany(ctx, account.ID, companyID)
+	if err != nil {
+		return scimError(http.StatusInternalServerError, "", "failed to load user")
+	}
+
+	_ = h.audit.Log(ctx, "", companyID, "user.scim_provisioned", "user", user.ID, map[string]string{
+		"email":       user.Email,
+		"external_id": req.ExternalID,
+	})
+
+	if company.SCIMCreateEmployees && user.FrappeEmployeeID == nil {
+		h.createEmployee(ctx, company, user)
+	}
+	if req.Active != nil && !*req.Active {
+		if err := h.setStatus(ctx, user, model.StatusSuspended); err != nil {
+			return scimError(http.StatusInternalServerError, "", "failed to update status")
+		}
+	}
+
+	return h.respondUser(c, http.StatusCreated, user.ID)
+}
+
+// ReplaceUser applies a full representation (PUT). Attributes this server does not
+// store are ignored.
+func (h *SCIMHandler) ReplaceUser(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+
+	user, err := h.users.GetInCompany(ctx, c.Param("id"), companyID)
+	if err != nil {
+		return scimError(http.StatusNotFound, "", "user not found")
+	}
+
+	var req model.SCIMUser
+	if err := scimBind(c, &req); err != nil {
+		return err
+	}
+	if email := scimEmail(&req); email != "" && !strings.EqualFold(email, user.Email) {
+		return scimError(http.StatusBadRequest, "mutability", "userName cannot be changed")
+	}
+
+	name := scimFullName(&req, "")
+	update := scimUserUpdate{ExternalID: &req.ExternalID, Active: req.Active}
+	if name != "" {
+		update.FullName = &name
+	}
+	if err := h.applyUserUpdate(ctx, user, update); err != nil {
+		return scimError(http.StatusInternalServerError, "", err.Error())
+	}
+	return h.respondUser(c, http.StatusOK, user.ID)
+}
+
+// PatchUser applies PATCH operations to active, the name and externalId, in both the
+// path form ({"path":"active","value":false}) and the object form ({"value":{"active":false}}).
+func (h *SCIMHandler) PatchUser(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+
+	user, err := h.users.GetInCompany(ctx, c.Param("id"), companyID)
+	if err != nil {
+		return scimError(http.StatusNotFound, "", "user not found")
+	}
+
+	var req model.SCIMPatchRequest
+	if err := scimBind(c, &req); err != nil {
+		return err
+	}
+
+	var (
+		update scimUserUpdate
+		name   model.SCIMName
+	)
+	for _, op := range req.Operations {
+		verb := strings.ToLower(op.Op)
+		if verb != "add" && verb != "replace" && verb != "remove" {
+			return scimError(http.StatusBadRequest, "invalidSyntax", "unsupported op: "+op.Op)
+		}
+		values := map[string]interface{}{}
+		if op.Path == "" {
+			obj, ok := op.Value.(map[string]interface{})
+			if !ok {
+				return scimError(http.StatusBadRequest, "invalidValue", "value must be an object when path is omitted")
+			}
+			flattenSCIMValue("", obj, values)
+		} else {
+			values[strings.ToLower(op.Path)] = op.Value
+		}
+		if verb == "remove" {
+			for path := range values {
+				values[path] = nil
+			}
+		}
+
+		for path, v := range values {
+			switch path {
+			case "active":
+				active, ok := scimBool(v)
+				if !ok {
+					return scimError(http.StatusBadRequest, "invalidValue", "active must be a boolean")
+				}
+				update.Active = &active
+			case "externalid":
+				s, _ := v.(string)
+				update.ExternalID = &s
+			case "displayname":
+				name.Formatted, _ = v.(string)
+			case "name.formatted":
+				name.Formatted, _ = v.(string)
+			case "name.givenname":
+				name.GivenName, _ = v.(string)
+			case "name.familyname":
+				name.FamilyName, _ = v.(string)
+			case "username":
+				if s, _ := v.(string); !strings.EqualFold(s, user.Email) {
+					return scimError(http.StatusBadRequest, "mutability", "userName cannot be changed")
+				}
+			}
+		}
+	}
+	if full := scimFullName(&model.SCIMUser{Name: &name}, ""); full != "" {
+		update.FullName = &full
+	}
+
+	if err := h.applyUserUpdate(ctx, user, update); err != nil {
+		return scimError(http.StatusInternalServerError, "", err.Error())
+	}
+	return h.respondUser(c, http.StatusOK, user.ID)
+}
+
+// DeleteUser disables the membership rather than deleting anything, so the audit trail
+// and the user's other companies are kept.
+func (h *SCIMHandler) DeleteUser(c echo.Context) error {
+	ctx := c.Request().Context()
+	user, err := h.users.GetInCompany(ctx, c.Param("id"), c.Get("company_id").(string))
+	if err != nil {
+		return scimError(http.StatusNotFound, "", "user not found")
+	}
+	if err := h.setStatus(ctx, user, model.StatusDisabled); err != nil {
+		return scimError(http.StatusInternalServerError, "", "failed to update status")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *SCIMHandler) ListGroups(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+
+	attr, value, err := parseSCIMFilter(c.QueryParam("filter"))
+	if err != nil {
+		return scimError(http.StatusBadRequest, "invalidFilter", err.Error())
+	}
+	roles, err := h.roles.ListForCompany(ctx, companyID)
+	if err != nil {
+		return scimError(http.StatusInternalServerError, "", "failed to list groups")
+	}
+	users, err := h.users.ListByCompany(ctx, companyID)
+	if err != nil {
+		return scimError(http.StatusInternalServerError, "", "failed to list users")
+	}
+	withMembers := !strings.Contains(strings.ToLower(c.QueryParam("excludedAttributes")), "members")
+
+	matched := []model.SCIMGroup{}
+	for i := range roles {
+		role := &roles[i]
+		switch attr {
+		case "":
+		case "displayname":
+			if !strings.EqualFold(role.Name, value) {
+				continue
+			}
+		case "id":
+			if role.ID != value {
+				continue
+			}
+		default:
+			return scimError(http.StatusBadRequest, "invalidFilter", "unsupported filter attribute: "+attr)
+		}
+		matched = append(matched, h.toSCIMGroup(role, roles, users, withMembers))
+	}
+	return scimList(c, matched)
+}
+
+func (h *SCIMHandler) GetGroup(c echo.Context) error {
+	role, roles, users, err := h.loadGroup(c)
+	if err != nil {
+		return err
+	}
+	return scimJSON(c, http.StatusOK, h.toSCIMGroup(role, roles, users, true))
+}
+
+// ReplaceGroup sets the group's members to exactly those listed (PUT).
+func (h *SCIMHandler) ReplaceGroup(c echo.Context) error {
+	role, roles, users, err := h.loadGroup(c)
+	if err != nil {
+		return err
+	}
+	var req model.SCIMGroup
+	if err := scimBind(c, &req); err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(req.Members))
+	for _, m := range req.Members {
+		ids = append(ids, m.Value)
+	}
+	if err := h.replaceMembers(c, role, roles, users, ids); err != nil {
+		return err
+	}
+	return h.respondGroup(c)
+}
+
+// scimMemberPath matches the member filter Okta and Entra ID use to remove one member.
+var scimMemberPath = regexp.MustCompile(`(?i)^members\[value eq "([^"]+)"\]$`)
+
+// PatchGroup adds, removes or replaces members. Other attributes are ignored: roles are
+// named in the app.
+func (h *SCIMHandler) PatchGroup(c echo.Context) error {
+	role, roles, users, err := h.loadGroup(c)
+	if err != nil {
+		return err
+	}
+	var req model.SCIMPatchRequest
+	if err := scimBind(c, &req); err != nil {
+		return err
+	}
+
+	for _, op := range req.Operations {
+		verb := strings.ToLower(op.Op)
+		path := strings.ToLower(op.Path)
+		value := op.Value
+		if path == "" {
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return scimError(http.StatusBadRequest, "invalidValue", "value must be an object when path is omitted")
+			}
+			if _, ok := obj["members"]; !ok {
+				continue
+			}
+			path, value = "members", obj["members"]
+		}
+
+		var ids []string
+		all := false
+		if m := scimMemberPath.FindStringSubmatch(op.Path); m != nil {
+			ids = []string{m[1]}
+		} else if path == "members" {
+			ids = scimMemberIDs(value)
+			all = value == nil
+		} else {
+			continue
+		}
+
+		switch verb {
+		case "add":
+			for _, id := range ids {
+				if err := h.assignRole(c, id, role); err != nil {
+					return err
+				}
+			}
+		case "remove":
+			if all {
+				// Removing "members" without a value empties the group
+				ids = nil
+				for i := range users {
+					if roleOf(&users[i], roles) == role {
+						ids = append(ids, users[i].ID)
+					}
+				}
+			}
+			for _, id := range ids {
+				if err := h.removeFromRole(c, id, role, roles); err != nil {
+					return err
+				}
+			}
+		case "replace":
+			if err := h.replaceMembers(c, role, roles, users, ids); err != nil {
+				return err
+			}
+		default:
+			return scimError(http.StatusBadRequest, "invalidSyntax", "unsupported op: "+op.Op)
+		}
+
+		// Later operations see the roles as they are now
+		if users, err = h.users.ListByCompany(c.Request().Context(), c.Get("company_id").(string)); err != nil {
+			return scimError(http.StatusInternalServerError, "", "failed to list users")
+		}
+	}
+	return h.respondGroup(c)
+}
+
+// --- helpers ---
+
+// scimUserUpdate holds the attributes a PUT or PATCH set; nil means unchanged. An empty
+// ExternalID clears it.
+type scimUserUpdate struct {
+	FullName   *string
+	Active     *bool
+	ExternalID *string
+}
+
+func (h *SCIMHandler) applyUserUpdate(ctx context.Context, user *model.User, update scimUserUpdate) error {
+	if update.FullName != nil && *update.FullName != user.FullName {
+		// The name belongs to the account, so a company's directory only renames
+		// accounts that are not shared with another company.
+		memberships, err := h.users.ListMemberships(ctx, user.ID)
+		if err != nil {
+			return errors.New("failed to load memberships")
+		}
+		if len(memberships) == 1 {
+			if err := h.users.UpdateFullName(ctx, user.ID, *update.FullName); err != nil {
+				return errors.New("failed to update user")
+			}
+			_ = h.audit.Log(ctx, "", user.CompanyID, "user.scim_updated", "user", user.ID, map[string]string{
+				"full_name": *update.FullName,
+			})
+		}
+	}
+
+	if update.ExternalID != nil {
+		current := ""
+		if user.SCIMExternalID != nil {
+			current = *user.SCIMExternalID
+		}
+		if *update.ExternalID != current {
+			var externalID *string
+			if *update.ExternalID != "" {
+				externalID = update.ExternalID
+			}
+			if err := h.users.SetSCIMExternalID(ctx, user.ID, user.CompanyID, externalID); err != nil {
+				return errors.New("failed to update externalId")
+			}
+		}
+	}
+
+	if update.Active != nil {
+		status := model.StatusSuspended
+		if *update.Active {
+			status = model.StatusActive
+		}
+		if err := h.setStatus(ctx, user, status); err != nil {
+			return errors.New("failed to update status")
+		}
+	}
+	return nil
+}
+
+// setStatus changes the membership's status and, unless reactivating, revokes the user's
+// sessions in the company, as UserHandler.ChangeStatus does.
+func (h *SCIMHandler) setStatus(ctx context.Context, user *model.User, status model.UserStatus) error {
+	if user.Status == status {
+		return nil
+	}
+	if err := h.users.UpdateStatus(ctx, user.ID, user.CompanyID, status); err != nil {
+		return err
+	}
+	_ = h.audit.Log(ctx, "", user.CompanyID, "user.status_changed", "user", user.ID, map[string]string{
+		"old_status": string(user.Status),
+		"new_status": string(status),
+		"source":     "scim",
+	})
+	user.Status = status
+
+	if status == model.StatusActive {
+		return nil
+	}
+	revoked, err := h.sessions.RevokeAllInCompany(ctx, user.ID, user.CompanyID)
+	if err != nil {
+		return err
+	}
+	_ = h.audit.Log(ctx, "", user.CompanyID, "user.sessions_revoked", "user", user.ID, map[string]interface{}{
+		"sessions": revoked,
+		"source":   "scim",
+	})
+	return nil
+}
+
+// createEmployee creates and links a Frappe employee for a newly provisioned member. It is
+// best effort: the user is provisioned either way and HR can link an employee later.
+func (h *SCIMHandler) createEmployee(ctx context.Context, company *model.Company, user *model.User) {
+	if h.frappe == nil {
+		return
+	}
+	employeeID, err := h.frappe.CreateEmployee(ctx, user.FullName, company.FrappeCompanyName, "", "")
+	if err != nil || employeeID == "" {
+		log.Printf("scim: creating employee for user %s in company %s: %v", user.ID, company.ID, err)
+		return
+	}
+	if err := h.users.LinkEmployee(ctx, user.ID, company.ID, employeeID); err != nil {
+		log.Printf("scim: linking employee %s to user %s: %v", employeeID, user.ID, err)
+		return
+	}
+	user.FrappeEmployeeID = &employeeID
+	_ = h.audit.Log(ctx, "", company.ID, "user.employee_linked", "user", user.ID, map[string]string{
+		"frappe_employee_id": employeeID,
+		"source":             "scim",
+	})
+}
+
+func (h *SCIMHandler) assignRole(c echo.Context, userID string, role *model.Role) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+
+	user, err := h.users.GetInCompany(ctx, userID, companyID)
+	if err != nil {
+		return scimError(http.StatusBadRequest, "invalidValue", "unknown member: "+userID)
+	}
+	var roleID *string
+	if !role.BuiltIn() {
+		roleID = &role.ID
+	}
+	if user.Role == role.BaseRole && equalStringPtr(user.RoleID, roleID) {
+		return nil
+	}
+	if err := h.users.UpdateRole(ctx, user.ID, companyID, role.BaseRole, roleID); err != nil {
+		return scimError(http.StatusInternalServerError, "", "failed to update role")
+	}
+	_ = h.audit.Log(ctx, "", companyID, "user.role_changed", "user", user.ID, map[string]string{
+		"old_role":      string(user.Role),
+		"new_role":      string(role.BaseRole),
+		"new_role_name": role.Name,
+		"source":        "scim",
+	})
+	return nil
+}
+
+// removeFromRole moves the user to the built-in employee role if they currently hold role.
+func (h *SCIMHandler) removeFromRole(c echo.Context, userID string, role *model.Role, roles []model.Role) error {
+	user, err := h.users.GetInCompany(c.Request().Context(), userID, c.Get("company_id").(string))
+	if err != nil || roleOf(user, roles) != role {
+		return nil
+	}
+	employee, err := h.roles.GetBuiltIn(c.Request().Context(), model.RoleEmployee)
+	if err != nil {
+		return scimError(http.StatusInternalServerError, "", "failed to load role")
+	}
+	return h.assignRole(c, userID, employee)
+}
+
+func (h *SCIMHandler) replaceMembers(c echo.Context, role *model.Role, roles []model.Role, users []model.User, ids []string) error {
+	keep := map[string]bool{}
+	for _, id := range ids {
+		keep[id] = true
+		if err := h.assignRole(c, id, role); err != nil {
+			return err
+		}
+	}
+	for i := range users {
+		if !keep[users[i].ID] && roleOf(&users[i], roles) == role {
+			if err := h.removeFromRole(c, users[i].ID, role, roles); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h *SCIMHandler) loadGroup(c echo.Context) (*model.Role, []model.Role, []model.User, error) {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+
+	roles, err := h.roles.ListForCompany(ctx, companyID)
+	if err != nil {
+		return nil, nil, nil, scimError(http.StatusInternalServerError, "", "failed to list groups")
+	}
+	var role *model.Role
+	for i := range roles {
+		if roles[i].ID == c.Param("id") {
+			role = &roles[i]
+		}
+	}
+	if role == nil {
+		return nil, nil, nil, scimError(http.StatusNotFound, "", "group not found")
+	}
+	users, err := h.users.ListByCompany(ctx, companyID)
+	if err != nil {
+		return nil, nil, nil, scimError(http.StatusInternalServerError, "", "failed to list users")
+	}
+	return role, roles, users, nil
+}
+
+func (h *SCIMHandler) respondUser(c echo.Context, status int, id string) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(string)
+
+	user, err := h.users.GetInCompany(ctx, id, companyID)
+	if err != nil {
+		return scimError(http.StatusInternalServerError, "", "failed to load user")
+	}
+	roles, err := h.roles.ListForCompany(ctx, companyID)
+	if err != nil {
+		return scimError(http.StatusInternalServerError, "", "failed to list groups")
+	}
+	resource := h.toSCIMUser(user, roles)
+	if status == http.StatusCreated {
+		c.Response().Header().Set(echo.HeaderLocation, resource.Meta.Location)
+	}
+	return scimJSON(c, status, resource)
+}
+
+func (h *SCIMHandler) respondGroup(c echo.Context) error {
+	role, roles, users, err := h.loadGroup(c)
+	if err != nil {
+		return err
+	}
+	return scimJSON(c, http.StatusOK, h.toSCIMGroup(role, roles, users, true))
+}
+
+func (h *SCIMHandler) toSCIMUser(u *model.User, roles []model.Role) model.SCIMUser {
+	active := u.Status == model.StatusActive
+	created, modified := u.CreatedAt, u.UpdatedAt
+	out := model.SCIMUser{
+		Schemas:     []string{model.SCIMSchemaUser},
+		ID:          u.ID,
+		UserName:    u.Email,
+		Name:        &model.SCIMName{Formatted: u.FullName},
+		DisplayName: u.FullName,
+		Emails:      []model.SCIMEmail{{Value: u.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &model.SCIMMeta{
+			ResourceType: "User",
+			Created:      &created,
+			LastModified: &modified,
+			Location:     h.scimBaseURL() + "/Users/" + u.ID,
+		},
+	}
+	if u.SCIMExternalID != nil {
+		out.ExternalID = *u.SCIMExternalID
+	}
+	if role := roleOf(u, roles); role != nil {
+		out.Groups = []model.SCIMRef{{Value: role.ID, Display: role.Name, Ref: h.scimBaseURL() + "/Groups/" + role.ID}}
+	}
+	if u.FrappeEmployeeID != nil {
+		out.Schemas = append(out.Schemas, model.SCIMSchemaEnterprise)
+		out.Enterprise = &model.SCIMEnterpriseUser{EmployeeNumber: *u.FrappeEmployeeID}
+	}
+	return out
+}
+
+func (h *SCIMHandler) toSCIMGroup(role *model.Role, roles []model.Role, users []model.User, withMembers bool) model.SCIMGroup {
+	created, modified := role.CreatedAt, role.UpdatedAt
+	out := model.SCIMGroup{
+		Schemas:     []string{model.SCIMSchemaGroup},
+		ID:          role.ID,
+		DisplayName: role.Name,
+		Meta: &model.SCIMMeta{
+			ResourceType: "Group",
+			Created:      &created,
+			LastModified: &modified,
+			Location:     h.scimBaseURL() + "/Groups/" + role.ID,
+		},
+	}
+	if withMembers {
+		out.Members = []model.SCIMRef{}
+		for i := range users {
+			if roleOf(&users[i], roles) == role {
+				out.Members = append(out.Members, model.SCIMRef{
+					Value:   users[i].ID,
+					Display: users[i].Email,
+					Ref:     h.scimBaseURL() + "/Users/" + users[i].ID,
+				})
+			}
+		}
+	}
+	return out
+}
+
+// roleOf returns the entry in roles that the user holds: their custom role, or the
+// built-in role for their base role.
+func roleOf(u *model.User, roles []model.Role) *model.Role {
+	for i := range roles {
+		r := &roles[i]
+		if u.RoleID != nil {
+			if r.ID == *u.RoleID {
+				return r
+			}
+		} else if r.BuiltIn() && r.Slug == string(u.Role) {
+			return r
+		}
+	}
+	return nil
+}
+
+// scimEmail is the login email for a SCIM user: userName when it is an email address,
+// otherwise the primary email.
+func scimEmail(u *model.SCIMUser) string {
+	if strings.Contains(u.UserName, "@") {
+		return strings.TrimSpace(u.UserName)
+	}
+	for _, e := range u.Emails {
+		if e.Primary && strings.Contains(e.Value, "@") {
+			return strings.TrimSpace(e.Value)
+		}
+	}
+	if len(u.Emails) > 0 && strings.Contains(u.Emails[0].Value, "@") {
+		return strings.TrimSpace(u.Emails[0].Value)
+	}
+	return ""
+}
+
+// scimFullName picks the best display name the client sent, or fallback.
+func scimFullName(u *model.SCIMUser, fallback string) string {
+	if u.Name != nil && strings.TrimSpace(u.Name.Formatted) != "" {
+		return strings.TrimSpace(u.Name.Formatted)
+	}
+	if strings.TrimSpace(u.DisplayName) != "" {
+		return strings.TrimSpace(u.DisplayName)
+	}
+	if u.Name != nil {
+		if full := strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName); full != "" {
+			return full
+		}
+	}
+	return fallback
+}
+
+// flattenSCIMValue turns {"name":{"givenName":"A"}} into {"name.givenname":"A"}.
+func flattenSCIMValue(prefix string, obj map[string]interface{}, out map[string]interface{}) {
+	for k, v := range obj {
+		key := strings.ToLower(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := v.(map[string]interface{}); ok && key == "name" {
+			flattenSCIMValue(key, nested, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// scimBool accepts JSON booleans and the "True"/"False" strings some clients send.
+func scimBool(v interface{}) (bool, bool) {
+	switch b := v.(type) {
+	case bool:
+		return b, true
+	case string:
+		if strings.EqualFold(b, "true") {
+			return true, true
+		}
+		if strings.EqualFold(b, "false") {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// scimMemberIDs reads the ids from a members value: [{"value":"id"}, ...].
+func scimMemberIDs(v interface{}) []string {
+	list, _ := v.([]interface{})
+	ids := make([]string, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			if id, ok := m["value"].(string); ok && id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+var errInvalidFilter = errors.New(`only filters of the form attribute eq "value" are supported`)
+
+var scimFilterPattern = regexp.MustCompile(`(?i)^\s*([a-z][a-z0-9.]*)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// parseSCIMFilter supports the single `attribute eq "value"` form identity providers use
+// to look resources up. It returns the attribute lower-cased; an empty filter gives "".
+func parseSCIMFilter(filter string) (attr, value string, err error) {
+	if strings.TrimSpace(filter) == "" {
+		return "", "", nil
+	}
+	m := scimFilterPattern.FindStringSubmatch(filter)
+	if m == nil {
+		return "", "", errInvalidFilter
+	}
+	value, err = strconv.Unquote(`"` + m[2] + `"`)
+	if err != nil {
+		return "", "", errInvalidFilter
+	}
+	return strings.ToLower(m[1]), value, nil
+}
+
+func scimList[T any](c echo.Context, all []T) error {
+	start, _ := strconv.Atoi(c.QueryParam("startIndex"))
+	if start < 1 {
+		start = 1
+	}
+	count := scimDefaultCount
+	if s := c.QueryParam("count"); s != "" {
+		count, _ = strconv.Atoi(s)
+	}
+	count = max(0, min(count, scimMaxCount))
+
+	page := []T{}
+	if from := start - 1; from < len(all) {
+		page = all[from:min(len(all), from+count)]
+	}
+	return scimJSON(c, http.StatusOK, model.SCIMListResponse{
+		Schemas:      []string{model.SCIMSchemaListResponse},
+		TotalResults: len(all),
+		StartIndex:   start,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	})
+}
+
+// scimBind decodes the body whatever its content type: clients send application/scim+json,
+// which echo's binder does not accept.
+func scimBind(c echo.Context, v interface{}) error {
+	if err := json.NewDecoder(c.Request().Body).Decode(v); err != nil {
+		return scimError(http.StatusBadRequest, "invalidSyntax", "invalid request body")
+	}
+	return nil
+}
+
+func scimJSON(c echo.Context, status int, v interface{}) error {
+	c.Response().Header().Set(echo.HeaderContentType, "application/scim+json")
+	return c.JSON(status, v)
+}
+
+func scimError(status int, scimType, detail string) error {
+	return model.NewSCIMError(status, scimType, detail)
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
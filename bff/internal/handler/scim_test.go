@@ -0,0 +1,396 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hr-platform/bff/internal/config"
+	"hr-platform/bff/internal/middleware"
+	"hr-platform/bff/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var updateSCIM = flag.Bool("update", false, "re-record testdata/scim_conversation.json from the current handler")
+
+// scimExchange is one request/response pair of a recorded SCIM client conversation.
+type scimExchange struct {
+	Name    string `json:"name"`
+	Request struct {
+		Method string          `json:"method"`
+		Path   string          `json:"path"`
+		Token  string          `json:"token,omitempty"`
+		Body   json.RawMessage `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		Status int             `json:"status"`
+		Body   json.RawMessage `json:"body,omitempty"`
+	} `json:"response"`
+}
+
+// TestSCIMConversation replays an identity provider's provisioning conversation (lookup,
+// create, group push, deactivate, reactivate, rename, unassign, delete) against the
+// handler and compares every response with the recording.
+func TestSCIMConversation(t *testing.T) {
+	const path = "testdata/scim_conversation.json"
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conversation []scimExchange
+	if err := json.Unmarshal(raw, &conversation); err != nil {
+		t.Fatalf("decoding %s: %v", path, err)
+	}
+
+	backend := newFakeSCIMBackend()
+	e := newSCIMTestServer(backend)
+
+	for i := range conversation {
+		ex := &conversation[i]
+		req := httptest.NewRequest(ex.Request.Method, ex.Request.Path, bytes.NewReader(ex.Request.Body))
+		req.Header.Set(echo.HeaderContentType, "application/scim+json")
+		if ex.Request.Token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+ex.Request.Token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if *updateSCIM {
+			ex.Response.Status = rec.Code
+			ex.Response.Body = nil
+			if rec.Body.Len() > 0 {
+				ex.Response.Body = json.RawMessage(bytes.TrimSpace(rec.Body.Bytes()))
+			}
+			continue
+		}
+
+		if rec.Code != ex.Response.Status {
+			t.Fatalf("%s: status %d, recorded %d\n%s", ex.Name, rec.Code, ex.Response.Status, rec.Body)
+		}
+		if rec.Body.Len() > 0 {
+			if ct := rec.Header().Get(echo.HeaderContentType); !strings.HasPrefix(ct, "application/scim+json") {
+				t.Errorf("%s: content type %q", ex.Name, ct)
+			}
+		}
+		if !sameJSON(t, rec.Body.Bytes(), ex.Response.Body) {
+			t.Fatalf("%s: response differs from recording\n got: %s\nwant: %s", ex.Name, rec.Body, ex.Response.Body)
+		}
+	}
+
+	if *updateSCIM {
+		out, err := json.MarshalIndent(conversation, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, append(out, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	// Side effects the responses do not show
+	if got := backend.revoked; !reflect.DeepEqual(got, []string{"usr-1", "usr-bob", "usr-1"}) {
+		t.Errorf("sessions revoked for %v, want each deactivation and the delete", got)
+	}
+	if got := backend.employees; !reflect.DeepEqual(got, []string{"Alice Smith", "Bob Jones"}) {
+		t.Errorf("created employees %v, want one per new member", got)
+	}
+	if bob := backend.account("usr-bob"); bob.FullName != "Bob Jones" {
+		t.Errorf("shared account renamed to %q by one company's directory", bob.FullName)
+	}
+	wantAudit := []string{
+		"user.scim_provisioned", "user.employee_linked", // create alice
+		"user.role_changed",                            // push to HR
+		"user.status_changed", "user.sessions_revoked", // deactivate
+		"user.scim_updated", "user.status_changed", // rename and reactivate
+		"user.role_changed",                             // move to Payroll Clerk
+		"user.scim_provisioned", "user.employee_linked", // add bob
+		"user.role_changed", "user.role_changed", // replace members: bob in, alice out
+		"user.status_changed", "user.sessions_revoked", // deactivate bob
+		"user.role_changed",                            // remove bob
+		"user.status_changed", "user.sessions_revoked", // delete alice
+	}
+	if !reflect.DeepEqual(backend.audit, wantAudit) {
+		t.Errorf("audit log\n got: %v\nwant: %v", backend.audit, wantAudit)
+	}
+}
+
+func TestParseSCIMFilter(t *testing.T) {
+	tests := []struct {
+		filter, attr, value string
+		ok                  bool
+	}{
+		{``, "", "", true},
+		{`userName eq "alice@example.com"`, "username", "alice@example.com", true},
+		{`externalId EQ "00u1\"x"`, "externalid", `00u1"x`, true},
+		{`emails.value eq "a@b.c"`, "emails.value", "a@b.c", true},
+		{`userName sw "al"`, "", "", false},
+		{`userName eq "a" and active eq true`, "", "", false},
+	}
+	for _, tt := range tests {
+		attr, value, err := parseSCIMFilter(tt.filter)
+		if (err == nil) != tt.ok || attr != tt.attr || value != tt.value {
+			t.Errorf("parseSCIMFilter(%q) = %q, %q, %v", tt.filter, attr, value, err)
+		}
+	}
+}
+
+func sameJSON(t *testing.T, got, want []byte) bool {
+	t.Helper()
+	if len(bytes.TrimSpace(got)) == 0 || len(want) == 0 {
+		return len(bytes.TrimSpace(got)) == len(want)
+	}
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("response is not JSON: %v\n%s", err, got)
+	}
+	if err := json.Unmarshal(want, &w); err != nil {
+		t.Fatalf("recording is not JSON: %v\n%s", err, want)
+	}
+	return reflect.DeepEqual(g, w)
+}
+
+// newSCIMTestServer mounts the SCIM routes as main.go does, with the bearer token check
+// done against the fake company instead of Postgres.
+func newSCIMTestServer(backend *fakeSCIMBackend) *echo.Echo {
+	h := &SCIMHandler{
+		users:     backend,
+		roles:     backend,
+		companies: backend,
+		sessions:  backend,
+		audit:     backend,
+		frappe:    backend,
+		cfg:       &config.Config{AppBaseURL: "https://hr.example.com"},
+	}
+	auth := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get(echo.HeaderAuthorization) != "Bearer "+fakeSCIMToken {
+				return model.NewSCIMError(http.StatusUnauthorized, "", "invalid bearer token")
+			}
+			c.Set("company_id", "company-1")
+			return next(c)
+		}
+	}
+
+	e := echo.New()
+	scim := e.Group("/api/scim/v2", middleware.SCIMErrors, auth)
+	scim.GET("/ServiceProviderConfig", h.ServiceProviderConfig)
+	scim.GET("/Users", h.ListUsers)
+	scim.POST("/Users", h.CreateUser)
+	scim.GET("/Users/:id", h.GetUser)
+	scim.PUT("/Users/:id", h.ReplaceUser)
+	scim.PATCH("/Users/:id", h.PatchUser)
+	scim.DELETE("/Users/:id", h.DeleteUser)
+	scim.GET("/Groups", h.ListGroups)
+	scim.GET("/Groups/:id", h.GetGroup)
+	scim.PUT("/Groups/:id", h.ReplaceGroup)
+	scim.PATCH("/Groups/:id", h.PatchGroup)
+	return e
+}
+
+const fakeSCIMToken = "hrs_recorded"
+
+// fakeSCIMBackend stands in for every store the SCIM handler uses. Timestamps are fixed
+// and ids sequential so responses are reproducible.
+type fakeSCIMBackend struct {
+	accounts    []*model.User
+	memberships []*model.User // one per user and company; only the membership fields are used
+	roles       []model.Role
+	company     model.Company
+	revoked     []string
+	employees   []string
+	audit       []string
+	lastID      int
+}
+
+var fakeSCIMTime = time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+
+func newFakeSCIMBackend() *fakeSCIMBackend {
+	companyID := "company-1"
+	b := &fakeSCIMBackend{
+		company: model.Company{ID: companyID, Name: "Acme", FrappeCompanyName: "Acme Co", SCIMCreateEmployees: true},
+	}
+	for _, r := range []struct {
+		role model.UserRole
+		name string
+	}{{model.RoleAdmin, "Admin"}, {model.RoleHR, "HR"}, {model.RoleManager, "Manager"}, {model.RoleEmployee, "Employee"}} {
+		b.roles = append(b.roles, model.Role{
+			ID: "role-" + string(r.role), Slug: string(r.role), Name: r.name,
+			BaseRole: r.role, CreatedAt: fakeSCIMTime, UpdatedAt: fakeSCIMTime,
+		})
+	}
+	b.roles = append(b.roles, model.Role{
+		ID: "role-payroll-clerk", CompanyID: &companyID, Slug: "payroll-clerk", Name: "Payroll Clerk",
+		BaseRole: model.RoleEmployee, CreatedAt: fakeSCIMTime, UpdatedAt: fakeSCIMTime,
+	})
+
+	// Bob already works for another company on the platform
+	bobEmployee := "HR-EMP-00900"
+	b.accounts = append(b.accounts, &model.User{ID: "usr-bob", Email: "bob@example.com", FullName: "Bob Jones", Status: model.StatusActive, CreatedAt: fakeSCIMTime, UpdatedAt: fakeSCIMTime})
+	b.memberships = append(b.memberships, &model.User{ID: "usr-bob", CompanyID: "company-2", Role: model.RoleManager, Status: model.StatusActive, FrappeEmployeeID: &bobEmployee})
+	return b
+}
+
+func (b *fakeSCIMBackend) account(id string) *model.User {
+	for _, a := range b.accounts {
+		if a.ID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+func (b *fakeSCIMBackend) membership(id, companyID string) *model.User {
+	for _, m := range b.memberships {
+		if m.ID == id && m.CompanyID == companyID {
+			return m
+		}
+	}
+	return nil
+}
+
+// view joins an account with one of its memberships, as UserRepository's selectUser does.
+func (b *fakeSCIMBackend) view(m *model.User) *model.User {
+	u := *b.account(m.ID)
+	u.CompanyID, u.Role, u.RoleID, u.Status = m.CompanyID, m.Role, m.RoleID, m.Status
+	u.FrappeEmployeeID, u.SCIMExternalID = m.FrappeEmployeeID, m.SCIMExternalID
+	return &u
+}
+
+func (b *fakeSCIMBackend) Create(_ context.Context, u *model.User) error {
+	b.lastID++
+	u.ID = fmt.Sprintf("usr-%d", b.lastID)
+	u.CreatedAt, u.UpdatedAt = fakeSCIMTime, fakeSCIMTime
+	a := *u
+	b.accounts = append(b.accounts, &a)
+	return b.AddMembership(context.Background(), u.ID, u.CompanyID, u.Role, u.FrappeEmployeeID)
+}
+
+func (b *fakeSCIMBackend) AddMembership(_ context.Context, userID, companyID string, role model.UserRole, frappeEmployeeID *string) error {
+	if b.membership(userID, companyID) != nil {
+		return fmt.Errorf("duplicate membership")
+	}
+	b.memberships = append(b.memberships, &model.User{ID: userID, CompanyID: companyID, Role: role, Status: model.StatusActive, FrappeEmployeeID: frappeEmployeeID})
+	return nil
+}
+
+func (b *fakeSCIMBackend) GetInCompany(_ context.Context, id, companyID string) (*model.User, error) {
+	m := b.membership(id, companyID)
+	if m == nil {
+		return nil, sql.ErrNoRows
+	}
+	return b.view(m), nil
+}
+
+func (b *fakeSCIMBackend) GetByEmail(_ context.Context, email string) (*model.User, error) {
+	for _, m := range b.memberships {
+		if b.account(m.ID).Email == email {
+			return b.view(m), nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (b *fakeSCIMBackend) ListByCompany(_ context.Context, companyID string) ([]model.User, error) {
+	users := []model.User{}
+	for _, m := range b.memberships {
+		if m.CompanyID == companyID {
+			users = append(users, *b.view(m))
+		}
+	}
+	return users, nil
+}
+
+func (b *fakeSCIMBackend) ListMemberships(_ context.Context, userID string) ([]model.Membership, error) {
+	var out []model.Membership
+	for _, m := range b.memberships {
+		if m.ID == userID {
+			out = append(out, model.Membership{UserID: m.ID, CompanyID: m.CompanyID, Role: m.Role, Status: m.Status})
+		}
+	}
+	return out, nil
+}
+
+func (b *fakeSCIMBackend) UpdateFullName(_ context.Context, id, fullName string) error {
+	b.account(id).FullName = fullName
+	return nil
+}
+
+func (b *fakeSCIMBackend) UpdateRole(_ context.Context, id, companyID string, role model.UserRole, roleID *string) error {
+	m := b.membership(id, companyID)
+	m.Role, m.RoleID = role, roleID
+	return nil
+}
+
+func (b *fakeSCIMBackend) UpdateStatus(_ context.Context, id, companyID string, status model.UserStatus) error {
+	b.membership(id, companyID).Status = status
+	return nil
+}
+
+func (b *fakeSCIMBackend) LinkEmployee(_ context.Context, id, companyID, frappeEmployeeID string) error {
+	b.membership(id, companyID).FrappeEmployeeID = &frappeEmployeeID
+	return nil
+}
+
+func (b *fakeSCIMBackend) SetSCIMExternalID(_ context.Context, id, companyID string, externalID *string) error {
+	b.membership(id, companyID).SCIMExternalID = externalID
+	return nil
+}
+
+func (b *fakeSCIMBackend) ListForCompany(_ context.Context, companyID string) ([]model.Role, error) {
+	var out []model.Role
+	for _, r := range b.roles {
+		if r.CompanyID == nil || *r.CompanyID == companyID {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (b *fakeSCIMBackend) GetBuiltIn(_ context.Context, role model.UserRole) (*model.Role, error) {
+	for i := range b.roles {
+		if b.roles[i].BuiltIn() && b.roles[i].BaseRole == role {
+			return &b.roles[i], nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (b *fakeSCIMBackend) GetByID(_ context.Context, id string) (*model.Company, error) {
+	if id != b.company.ID {
+		return nil, sql.ErrNoRows
+	}
+	c := b.company
+	return &c, nil
+}
+
+func (b *fakeSCIMBackend) UpdateSCIM(_ context.Context, c *model.Company) error {
+	b.company = *c
+	return nil
+}
+
+func (b *fakeSCIMBackend) RevokeAllInCompany(_ context.Context, userID, _ string) (int64, error) {
+	b.revoked = append(b.revoked, userID)
+	return 1, nil
+}
+
+func (b *fakeSCIMBackend) Log(_ context.Context, _, _, action, _, _ string, _ interface{}) error {
+	b.audit = append(b.audit, action)
+	return nil
+}
+
+func (b *fakeSCIMBackend) CreateEmployee(_ context.Context, employeeName, _, _, _ string) (string, error) {
+	b.employees = append(b.employees, employeeName)
+	return fmt.Sprintf("HR-EMP-%05d", len(b.employees)), nil
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"hr-platform/bff/internal/client"
+	"hr-platform/bff/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// errOutsideReportingLine is returned when a manager acts on someone outside their reporting chain.
+	errOutsideReportingLine = errors.New("employee is outside your reporting line")
+	// errNotRecordOwner is returned when a caller mutates a request that is neither theirs nor theirs to approve.
+	errNotRecordOwner = errors.New("you can only modify your own requests")
+)
+
+// teamScope returns the employees the caller's base role may see. Admin and HR get nil,
+// meaning no restriction applies; a manager gets themselves plus their direct and
+// indirect reports; anyone else only themselves.
+func teamScope(ctx context.Context, tc *client.TenantClient, role, employeeID string) (map[string]bool, error) {
+	switch model.UserRole(role) {
+	case model.RoleAdmin, model.RoleHR:
+		return nil, nil
+	}
+	if employeeID == "" {
+		return map[string]bool{}, nil
+	}
+	if model.UserRole(role) != model.RoleManager {
+		return map[string]bool{employeeID: true}, nil
+	}
+	reports, err := tc.Reports(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	reports[employeeID] = true
+	return reports, nil
+}
+
+// checkApprover loads a request record and verifies the caller may approve it.
+// Managers may only act on their reports, never on their own requests. Other base
+// roles below HR approve nothing, whatever permissions a custom role grants.
+func checkApprover(ctx context.Context, tc *client.TenantClient, role, employeeID, doctype, recordID string) (*model.RecordOwner, error) {
+	owner, err := tc.RecordOwner(ctx, doctype, recordID)
+	if err != nil {
+		return nil, err
+	}
+	switch model.UserRole(role) {
+	case model.RoleAdmin, model.RoleHR:
+		return owner, nil
+	}
+	if model.UserRole(role) != model.RoleManager || employeeID == "" || owner.Employee == employeeID {
+		return nil, errOutsideReportingLine
+	}
+	ok, err := reportsTo(ctx, tc, owner.Employee, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errOutsideReportingLine
+	}
+	return owner, nil
+}
+
+// checkOwnerOrApprover loads a request record and verifies the caller may modify it:
+// the employee who raised it, admin/HR, or a manager of that employee.
+func checkOwnerOrApprover(ctx context.Context, tc *client.TenantClient, role, employeeID, doctype, recordID string) (*model.RecordOwner, error) {
+	owner, err := tc.RecordOwner(ctx, doctype, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if employeeID != "" && owner.Employee == employeeID {
+		return owner, nil
+	}
+	switch model.UserRole(role) {
+	case model.RoleAdmin, model.RoleHR:
+		return owner, nil
+	case model.RoleManager:
+		if employeeID == "" {
+			break
+		}
+		ok, err := reportsTo(ctx, tc, owner.Employee, employeeID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return owner, nil
+		}
+	}
+	return nil, errNotRecordOwner
+}
+
+// reportsTo reports whether employeeID is in managerID's reporting chain.
+func reportsTo(ctx context.Context, tc *client.TenantClient, employeeID, managerID string) (bool, error) {
+	reports, err := tc.Reports(ctx, managerID)
+	if err != nil {
+		return false, err
+	}
+	return reports[employeeID], nil
+}
+
+// employeeRecord is a decoded Frappe list row that belongs to one employee.
+type employeeRecord interface {
+	EmployeeID() string
+}
+
+// inScope drops rows whose employee is not in scope. A nil scope returns rows unchanged.
+func inScope[T employeeRecord](rows []T, scope map[string]bool) []T {
+	if scope == nil {
+		return rows
+	}
+	kept := make([]T, 0, len(rows))
+	for _, row := range rows {
+		if scope[row.EmployeeID()] {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+// callerTeam resolves teamScope for the current request.
+func callerTeam(c echo.Context, frappe *client.FrappeClient) (map[string]bool, error) {
+	scope, err := teamScope(c.Request().Context(), tenantFrappe(c, frappe), c.Get("user_role").(string), c.Get("employee_id").(string))
+	if err != nil {
+		return nil, frappeHTTPError(err, "failed to resolve reporting line")
+	}
+	return scope, nil
+}
+
+// requireInTeam rejects managers asking for an employee outside their team.
+func requireInTeam(c echo.Context, frappe *client.FrappeClient, employeeID string) error {
+	scope, err := callerTeam(c, frappe)
+	if err != nil {
+		return err
+	}
+	if scope != nil && !scope[employeeID] {
+		return forbidden(errOutsideReportingLine)
+	}
+	return nil
+}
+
+// authorizeApproval runs checkApprover for the current request and maps failures to HTTP errors.
+func authorizeApproval(c echo.Context, frappe *client.FrappeClient, doctype, recordID string) (*model.RecordOwner, error) {
+	owner, err := checkApprover(c.Request().Context(), tenantFrappe(c, frappe), c.Get("user_role").(string), c.Get("employee_id").(string), doctype, recordID)
+	if err != nil {
+		return nil, recordAccessError(err)
+	}
+	return owner, nil
+}
+
+// authorizeOwnerOrApprover runs checkOwnerOrApprover for the current request and maps failures to HTTP errors.
+func authorizeOwnerOrApprover(c echo.Context, frappe *client.FrappeClient, doctype, recordID string) (*model.RecordOwner, error) {
+	owner, err := checkOwnerOrApprover(c.Request().Context(), tenantFrappe(c, frappe), c.Get("user_role").(string), c.Get("employee_id").(string), doctype, recordID)
+	if err != nil {
+		return nil, recordAccessError(err)
+	}
+	return owner, nil
+}
+
+// forbidden is the single 403 shape for record-level access denials: {"message": "<reason>"}.
+func forbidden(err error) *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusForbidden, err.Error())
+}
+
+// recordAccessError maps a record access check failure to an HTTP error.
+// Records from other companies are reported as missing so their IDs are not revealed.
+func recordAccessError(err error) *echo.HTTPError {
+	switch {
+	case errors.Is(err, errOutsideReportingLine), errors.Is(err, errNotRecordOwner):
+		return forbidden(err)
+	case errors.Is(err, client.ErrCrossTenant):
+		return echo.NewHTTPError(http.StatusNotFound, "request not found")
+	default:
+		return frappeHTTPError(err, "failed to load request")
+	}
+}
@@ -0,0 +1,335 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"hr-platform/bff/internal/auth"
+	"hr-platform/bff/internal/config"
+	"hr-platform/bff/internal/middleware"
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/repository"
+	"hr-platform/bff/internal/signing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// refreshCookiePath limits the refresh token cookie to the auth endpoints.
+const refreshCookiePath = "/api/auth"
+
+type SessionHandler struct {
+	sessionRepo *repository.SessionRepository
+	userRepo    *repository.UserRepository
+	companyRepo *repository.CompanyRepository
+	auditRepo   *repository.AuditRepository
+	keys        *signing.KeySet
+	cfg         *config.Config
+}
+
+func NewSessionHandler(
+	sessionRepo *repository.SessionRepository,
+	userRepo *repository.UserRepository,
+	companyRepo *repository.CompanyRepository,
+	auditRepo *repository.AuditRepository,
+	keys *signing.KeySet,
+	cfg *config.Config,
+) *SessionHandler {
+	return &SessionHandler{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		companyRepo: companyRepo,
+		auditRepo:   auditRepo,
+		keys:        keys,
+		cfg:         cfg,
+	}
+}
+
+// Refresh exchanges a refresh token for a new access token and rotates the refresh token.
+// Presenting a refresh token that has already been rotated out revokes the whole session.
+func (h *SessionHandler) Refresh(c echo.Context) error {
+	var req model.RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.RefreshToken == "" {
+		if cookie, err := c.Cookie("refresh_token"); err == nil {
+			req.RefreshToken = cookie.Value
+		}
+	}
+	if req.RefreshToken == "" {
+		return echo.NewHTTPError(http.StatusUnauthorized, "missing refresh token")
+	}
+
+	ctx := c.Request().Context()
+	hash := auth.HashToken(req.RefreshToken)
+
+	session, err := h.sessionRepo.GetByRefreshHash(ctx, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		if reused, err := h.sessionRepo.GetByPreviousHash(ctx, hash); err == nil && reused.RevokedAt == nil {
+			_ = h.sessionRepo.Revoke(ctx, reused.ID)
+			_ = h.auditRepo.Log(ctx, reused.UserID, reused.CompanyID, "session.refresh_reused", "session", reused.ID, map[string]string{
+				"ip_address": c.RealIP(),
+			})
+		}
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid refresh token")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load session")
+	}
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid refresh token")
+	}
+
+	user, err := h.userRepo.GetInCompany(ctx, session.UserID, session.CompanyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid refresh token")
+	}
+	if user.Status != model.StatusActive {
+		_ = h.sessionRepo.Revoke(ctx, session.ID)
+		return echo.NewHTTPError(http.StatusUnauthorized, "account is not active")
+	}
+
+	refreshToken, err := auth.NewOpaqueToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
+	}
+	if err := h.sessionRepo.Rotate(ctx, session.ID, hash, auth.HashToken(refreshToken), c.Request().UserAgent(), c.RealIP()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid refresh token")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to rotate session")
+	}
+
+	token, err := issueAccessToken(h.keys, h.cfg, session.ID, user)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
+	}
+
+	company, err := h.companyRepo.GetByID(ctx, user.CompanyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+
+	setAuthCookies(c, h.cfg, token, refreshToken)
+
+	return c.JSON(http.StatusOK, model.LoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		User:         userToInfo(user, company.Name),
+	})
+}
+
+// List returns the current user's active sessions.
+func (h *SessionHandler) List(c echo.Context) error {
+	userID := c.Get("user_id").(string)
+	currentID, _ := c.Get("session_id").(string)
+
+	sessions, err := h.sessionRepo.ListActiveByUser(c.Request().Context(), userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to list sessions")
+	}
+	if sessions == nil {
+		sessions = []model.Session{}
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": sessions})
+}
+
+// Revoke ends one of the current user's sessions.
+func (h *SessionHandler) Revoke(c echo.Context) error {
+	sessionID := c.Param("id")
+	userID := c.Get("user_id").(string)
+	companyID := c.Get("company_id").(string)
+
+	if err := h.sessionRepo.RevokeForUser(c.Request().Context(), sessionID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, "session not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke session")
+	}
+
+	_ = h.auditRepo.Log(c.Request().Context(), userID, companyID, "session.revoked", "session", sessionID, nil)
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "session revoked"})
+}
+
+// --- helpers shared by the handlers that log a user in ---
+
+// SwitchCompany moves the current session to another company the user belongs to and
+// re-issues the access token for it. The refresh token is unchanged and, from now on,
+// refreshes into the new company.
+func (h *SessionHandler) SwitchCompany(c echo.Context) error {
+	var req model.SwitchCompanyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.CompanyID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "company_id is required")
+	}
+
+	ctx := c.Request().Context()
+	userID := c.Get("user_id").(string)
+	sessionID, _ := c.Get("session_id").(string)
+	if sessionID == "" {
+		return echo.NewHTTPError(http.StatusForbidden, "switching company requires a signed-in session")
+	}
+
+	session, err := h.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load session")
+	}
+	if session.CompanyLocked {
+		return echo.NewHTTPError(http.StatusForbidden, "this session is limited to the company you signed in to")
+	}
+
+	user, err := h.userRepo.GetInCompany(ctx, userID, req.CompanyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return echo.NewHTTPError(http.StatusNotFound, "company not found")
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load membership")
+	}
+	if user.Status != model.StatusActive {
+		return echo.NewHTTPError(http.StatusForbidden, "your access to this company is not active")
+	}
+
+	company, err := h.companyRepo.GetByID(ctx, req.CompanyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+	}
+	// Login would send the user through MFA enrollment; switching must not skip it
+	if company.RequiresMFA(user.Role) && !user.MFAEnabled {
+		return echo.NewHTTPError(http.StatusForbidden, "this company requires two-factor authentication")
+	}
+
+	if err := h.sessionRepo.SwitchCompany(ctx, sessionID, req.CompanyID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to switch company")
+	}
+	_ = h.userRepo.TouchMembership(ctx, userID, req.CompanyID)
+
+	token, err := issueAccessToken(h.keys, h.cfg, sessionID, user)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate token")
+	}
+
+	_ = h.auditRepo.Log(ctx, userID, req.CompanyID, "auth.company_switched", "session", sessionID, map[string]string{
+		"from_company_id": session.CompanyID,
+	})
+
+	info := userToInfo(user, company.Name)
+	if info.Memberships, err = h.userRepo.ListMemberships(ctx, userID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load memberships")
+	}
+
+	setAccessCookie(c, token, time.Now().Add(h.cfg.AccessTokenTTL()))
+
+	return c.JSON(http.StatusOK, model.LoginResponse{
+		Token: token,
+		User:  info,
+	})
+}
+
+// startSession records a new session for the user in user.CompanyID and issues its
+// access and refresh tokens.
+func startSession(c echo.Context, keys *signing.KeySet, cfg *config.Config, sessionRepo *repository.SessionRepository, user *model.User) (string, string, error) {
+	return newSession(c, keys, cfg, sessionRepo, user, false)
+}
+
+// newSession is startSession with control over whether the session may later switch to
+// the user's other companies.
+func newSession(c echo.Context, keys *signing.KeySet, cfg *config.Config, sessionRepo *repository.SessionRepository, user *model.User, companyLocked bool) (string, string, error) {
+	refreshToken, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	session := &model.Session{
+		UserID:           user.ID,
+		CompanyID:        user.CompanyID,
+		RefreshTokenHash: auth.HashToken(refreshToken),
+		UserAgent:        c.Request().UserAgent(),
+		IPAddress:        c.RealIP(),
+		ExpiresAt:        time.Now().Add(cfg.RefreshTokenTTL()),
+		CompanyLocked:    companyLocked,
+	}
+	if err := sessionRepo.Create(c.Request().Context(), session); err != nil {
+		return "", "", err
+	}
+
+	token, err := issueAccessToken(keys, cfg, session.ID, user)
+	if err != nil {
+		return "", "", err
+	}
+	return token, refreshToken, nil
+}
+
+func issueAccessToken(keys *signing.KeySet, cfg *config.Config, sessionID string, user *model.User) (string, error) {
+	return signAccessToken(keys, sessionID, user, "", cfg.AccessTokenTTL())
+}
+
+// signAccessToken issues an access token for user. impersonatorID is set only for
+// impersonation sessions.
+func signAccessToken(keys *signing.KeySet, sessionID string, user *model.User, impersonatorID string, ttl time.Duration) (string, error) {
+	employeeID := ""
+	if user.FrappeEmployeeID != nil {
+		employeeID = *user.FrappeEmployeeID
+	}
+	return middleware.GenerateToken(
+		keys,
+		sessionID,
+		user.ID,
+		user.Email,
+		user.FullName,
+		employeeID,
+		string(user.Role),
+		user.CompanyID,
+		impersonatorID,
+		ttl,
+	)
+}
+
+func setAuthCookies(c echo.Context, cfg *config.Config, token, refreshToken string) {
+	setAccessCookie(c, token, time.Now().Add(cfg.AccessTokenTTL()))
+	c.SetCookie(&http.Cookie{
+		Name:     "refresh_token",
+		Value:    refreshToken,
+		Path:     refreshCookiePath,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Expires:  time.Now().Add(cfg.RefreshTokenTTL()),
+	})
+}
+
+// setAccessCookie replaces only the access token cookie, leaving the refresh token alone.
+func setAccessCookie(c echo.Context, token string, expires time.Time) {
+	c.SetCookie(&http.Cookie{
+		Name:     "token",
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Expires:  expires,
+	})
+}
+
+func clearAuthCookies(c echo.Context) {
+	c.SetCookie(&http.Cookie{
+		Name:     "token",
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		MaxAge:   -1,
+	})
+	c.SetCookie(&http.Cookie{
+		Name:     "refresh_token",
+		Value:    "",
+		Path:     refreshCookiePath,
+		HttpOnly: true,
+		MaxAge:   -1,
+	})
+}
@@ -2,8 +2,7 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 
 	"hr-platform/bff/internal/client"
@@ -23,19 +22,35 @@ func NewShiftHandler(frappe *client.FrappeClient, notifRepo *repository.Notifica
 	return &ShiftHandler{frappe: frappe, notifRepo: notifRepo, userRepo: userRepo}
 }
 
-// ListShiftTypes returns all shift types.
+// shiftTypeError reports a shift type Frappe does not find in the tenant's company with
+// status: not found when the path names it, a bad request when the body does. A shared
+// shift type the tenant may not change is forbidden.
+func shiftTypeError(err error, status int, fallback string) *echo.HTTPError {
+	var fe *client.FrappeError
+	if errors.As(err, &fe) {
+		switch fe.StatusCode {
+		case http.StatusNotFound:
+			return echo.NewHTTPError(status, fe.Message)
+		case http.StatusForbidden:
+			return echo.NewHTTPError(http.StatusForbidden, fe.Message)
+		}
+	}
+	return frappeHTTPError(err, fallback)
+}
+
+// ListShiftTypes returns the shared shift types and the company's own.
 func (h *ShiftHandler) ListShiftTypes(c echo.Context) error {
-	data, err := h.frappe.CallMethod("hr_core_ext.api.shift.get_shift_types", map[string]string{})
+	types, err := tenantFrappe(c, h.frappe).Shift().Types(c.Request().Context())
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch shift types")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": types,
 	})
 }
 
-// CreateShiftType creates a new shift type (admin/HR only).
+// CreateShiftType creates a shift type for the company (admin/HR only).
 func (h *ShiftHandler) CreateShiftType(c echo.Context) error {
 	var req model.CreateShiftTypeRequest
 	if err := c.Bind(&req); err != nil {
@@ -46,28 +61,15 @@ func (h *ShiftHandler) CreateShiftType(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "name, start_time, and end_time are required")
 	}
 
-	params := map[string]string{
-		"name":       req.Name,
-		"start_time": req.StartTime,
-		"end_time":   req.EndTime,
-	}
-	if req.LateEntryGracePeriod > 0 {
-		params["late_entry_grace_period"] = fmt.Sprintf("%d", req.LateEntryGracePeriod)
-	}
-	if req.EarlyExitGracePeriod > 0 {
-		params["early_exit_grace_period"] = fmt.Sprintf("%d", req.EarlyExitGracePeriod)
-	}
-	if req.HolidayList != "" {
-		params["holiday_list"] = req.HolidayList
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.shift.create_shift_type", params)
+	tc := tenantFrappe(c, h.frappe)
+	res, err := tc.Shift().CreateType(c.Request().Context(), req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to create shift type")
 	}
+	_ = tc.Invalidate(c.Request().Context(), client.MethodShiftTypes)
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": res,
 	})
 }
 
@@ -80,64 +82,55 @@ func (h *ShiftHandler) UpdateShiftType(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	params := map[string]string{
-		"shift_type_name": shiftName,
-	}
-	if req.StartTime != nil {
-		params["start_time"] = *req.StartTime
-	}
-	if req.EndTime != nil {
-		params["end_time"] = *req.EndTime
-	}
-	if req.LateEntryGracePeriod != nil {
-		params["late_entry_grace_period"] = fmt.Sprintf("%d", *req.LateEntryGracePeriod)
-	}
-	if req.EarlyExitGracePeriod != nil {
-		params["early_exit_grace_period"] = fmt.Sprintf("%d", *req.EarlyExitGracePeriod)
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.shift.update_shift_type", params)
+	tc := tenantFrappe(c, h.frappe)
+	res, err := tc.Shift().UpdateType(c.Request().Context(), shiftName, req)
 	if err != nil {
-		return frappeHTTPError(err, "failed to update shift type")
+		return shiftTypeError(err, http.StatusNotFound, "failed to update shift type")
 	}
+	_ = tc.Invalidate(c.Request().Context(), client.MethodShiftTypes)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": res,
 	})
 }
 
-// ListAssignments returns shift assignments. Employees see only their own.
+// ListAssignments returns a page of shift assignments. Employees see only their own, managers their reporting chain.
 func (h *ShiftHandler) ListAssignments(c echo.Context) error {
 	employeeID := c.Get("employee_id").(string)
 	role := model.UserRole(c.Get("user_role").(string))
 
-	params := map[string]string{}
+	filter := client.ShiftAssignmentFilter{
+		ShiftType: c.QueryParam("shift_type"),
+		Date:      c.QueryParam("date"),
+	}
 
 	// Employee can only see own assignments
 	if role == model.RoleEmployee {
 		if employeeID == "" {
 			return echo.NewHTTPError(http.StatusBadRequest, "employee not linked to this user")
 		}
-		params["employee_id"] = employeeID
+		filter.EmployeeID = employeeID
 	} else if qEmployee := c.QueryParam("employee_id"); qEmployee != "" {
-		params["employee_id"] = qEmployee
+		if err := requireInTeam(c, h.frappe, qEmployee); err != nil {
+			return err
+		}
+		if err := verifyTenantEmployee(c, h.frappe, qEmployee); err != nil {
+			return err
+		}
+		filter.EmployeeID = qEmployee
 	}
 
-	if shiftType := c.QueryParam("shift_type"); shiftType != "" {
-		params["shift_type"] = shiftType
-	}
-	if date := c.QueryParam("date"); date != "" {
-		params["date"] = date
+	pg, scope, err := readTeamPage(c, h.frappe)
+	if err != nil {
+		return err
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.shift.get_shift_assignments", params)
+	page, err := tenantFrappe(c, h.frappe).Shift().AssignmentPage(c.Request().Context(), filter, pg)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch shift assignments")
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
-	})
+	return respondPage(c, pg, inScope(page.Rows, scope), page.Total)
 }
 
 // AssignShift assigns an employee to a shift type (admin/HR only).
@@ -151,22 +144,17 @@ func (h *ShiftHandler) AssignShift(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "employee_id, shift_type, and start_date are required")
 	}
 
-	params := map[string]string{
-		"employee_id": req.EmployeeID,
-		"shift_type":  req.ShiftType,
-		"start_date":  req.StartDate,
-	}
-	if req.EndDate != "" {
-		params["end_date"] = req.EndDate
+	if err := verifyTenantEmployee(c, h.frappe, req.EmployeeID); err != nil {
+		return err
 	}
 
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.shift.assign_shift", params)
+	assignment, err := tenantFrappe(c, h.frappe).Shift().Assign(c.Request().Context(), req)
 	if err != nil {
-		return frappeHTTPError(err, "failed to assign shift")
+		return shiftTypeError(err, http.StatusBadRequest, "failed to assign shift")
 	}
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": assignment,
 	})
 }
 
@@ -174,44 +162,41 @@ func (h *ShiftHandler) AssignShift(c echo.Context) error {
 func (h *ShiftHandler) UnassignShift(c echo.Context) error {
 	assignmentID := c.Param("id")
 
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.shift.unassign_shift", map[string]string{
-		"assignment_id": assignmentID,
-	})
+	res, err := tenantFrappe(c, h.frappe).Shift().Unassign(c.Request().Context(), assignmentID)
 	if err != nil {
 		return frappeHTTPError(err, "failed to unassign shift")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": res,
 	})
 }
 
-// ListRequests returns shift change requests. Employees see only their own.
+// ListRequests returns a page of shift change requests. Employees see only their own.
 func (h *ShiftHandler) ListRequests(c echo.Context) error {
 	employeeID := c.Get("employee_id").(string)
 	role := model.UserRole(c.Get("user_role").(string))
 
-	params := map[string]string{}
+	filter := client.ShiftRequestFilter{Status: c.QueryParam("status")}
 
 	if role == model.RoleEmployee {
 		if employeeID == "" {
 			return echo.NewHTTPError(http.StatusBadRequest, "employee not linked to this user")
 		}
-		params["employee_id"] = employeeID
+		filter.EmployeeID = employeeID
 	}
 
-	if status := c.QueryParam("status"); status != "" {
-		params["status"] = status
+	pg, scope, err := readTeamPage(c, h.frappe)
+	if err != nil {
+		return err
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.shift.get_shift_requests", params)
+	page, err := tenantFrappe(c, h.frappe).Shift().RequestPage(c.Request().Context(), filter, pg)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch shift requests")
 	}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
-	})
+	return respondPage(c, pg, inScope(page.Rows, scope), page.Total)
 }
 
 // CreateRequest submits a shift change request (any employee).
@@ -230,18 +215,13 @@ func (h *ShiftHandler) CreateRequest(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "shift_type, from_date, and to_date are required")
 	}
 
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.shift.create_shift_request", map[string]string{
-		"employee_id": employeeID,
-		"shift_type":  req.ShiftType,
-		"from_date":   req.FromDate,
-		"to_date":     req.ToDate,
-	})
+	res, err := tenantFrappe(c, h.frappe).Shift().CreateRequest(c.Request().Context(), employeeID, req)
 	if err != nil {
-		return frappeHTTPError(err, "failed to create shift request")
+		return shiftTypeError(err, http.StatusBadRequest, "failed to create shift request")
 	}
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": res,
 	})
 }
 
@@ -250,8 +230,7 @@ func (h *ShiftHandler) ApproveRequest(c echo.Context) error {
 	requestID := c.Param("id")
 
 	var req struct {
-		Action     string `json:"action"`
-		EmployeeID string `json:"employee_id"`
+		Action string `json:"action"`
 	}
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
@@ -261,19 +240,21 @@ func (h *ShiftHandler) ApproveRequest(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "action must be 'approve' or 'reject'")
 	}
 
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.shift.approve_shift_request", map[string]string{
-		"request_id": requestID,
-		"action":     req.Action,
-	})
+	owner, err := authorizeApproval(c, h.frappe, client.DoctypeShiftRequest, requestID)
+	if err != nil {
+		return err
+	}
+
+	res, err := tenantFrappe(c, h.frappe).Shift().DecideRequest(c.Request().Context(), requestID, req.Action)
 	if err != nil {
 		return frappeHTTPError(err, "failed to process shift request")
 	}
 
 	// Send notification to the shift request owner
-	if h.notifRepo != nil && req.EmployeeID != "" {
+	if h.notifRepo != nil && owner.Employee != "" {
 		go func() {
 			companyID := c.Get("company_id").(string)
-			u, err := h.userRepo.GetByFrappeEmployeeID(context.Background(), companyID, req.EmployeeID)
+			u, err := h.userRepo.GetByFrappeEmployeeID(context.Background(), companyID, owner.Employee)
 			if err == nil && u != nil {
 				status := "approved"
 				if req.Action == "reject" {
@@ -287,7 +268,7 @@ func (h *ShiftHandler) ApproveRequest(c echo.Context) error {
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": res,
 	})
 }
 
@@ -298,15 +279,13 @@ func (h *ShiftHandler) GetMyShift(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "employee not linked to this user")
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.shift.get_employee_current_shift", map[string]string{
-		"employee_id": employeeID,
-	})
+	shift, err := tenantFrappe(c, h.frappe).Shift().Current(c.Request().Context(), employeeID)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch current shift")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": shift,
 	})
 }
 
@@ -317,20 +296,12 @@ func (h *ShiftHandler) ProcessAutoAttendance(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	params := map[string]string{}
-	if req.Date != "" {
-		params["date"] = req.Date
-	}
-	if req.Company != "" {
-		params["company"] = req.Company
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.shift.process_auto_attendance", params)
+	run, err := tenantFrappe(c, h.frappe).Shift().ProcessAutoAttendance(c.Request().Context(), req.Date)
 	if err != nil {
 		return frappeHTTPError(err, "failed to process auto attendance")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"data": json.RawMessage(data),
+		"data": run,
 	})
 }
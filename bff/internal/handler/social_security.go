@@ -1,8 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
-	"fmt"
 	"net/http"
 
 	"hr-platform/bff/internal/client"
@@ -20,11 +18,11 @@ func NewSocialSecurityHandler(frappe *client.FrappeClient) *SocialSecurityHandle
 }
 
 func (h *SocialSecurityHandler) GetConfig(c echo.Context) error {
-	data, err := h.frappe.CallMethod("hr_core_ext.api.social_security.get_sso_config", map[string]string{})
+	config, err := tenantFrappe(c, h.frappe).SocialSecurity().Config(c.Request().Context())
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch SSO config")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": config})
 }
 
 func (h *SocialSecurityHandler) UpdateConfig(c echo.Context) error {
@@ -33,49 +31,38 @@ func (h *SocialSecurityHandler) UpdateConfig(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	params := map[string]string{}
-	if req.Rate != nil {
-		params["rate"] = fmt.Sprintf("%.2f", *req.Rate)
-	}
-	if req.MaxSalary != nil {
-		params["max_salary"] = fmt.Sprintf("%.2f", *req.MaxSalary)
-	}
-	if req.MaxContribution != nil {
-		params["max_contribution"] = fmt.Sprintf("%.2f", *req.MaxContribution)
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.social_security.update_sso_config", params)
+	tc := tenantFrappe(c, h.frappe)
+	config, err := tc.SocialSecurity().UpdateConfig(c.Request().Context(), req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to update SSO config")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	_ = tc.Invalidate(c.Request().Context(), client.MethodSSOConfig)
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": config})
 }
 
 func (h *SocialSecurityHandler) GetReport(c echo.Context) error {
-	month := c.QueryParam("month")
-	year := c.QueryParam("year")
-	if month == "" || year == "" {
+	if c.QueryParam("month") == "" || c.QueryParam("year") == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "month and year are required")
 	}
+	month, year, err := client.ParsePeriod(c.QueryParam("month"), c.QueryParam("year"))
+	if err != nil {
+		return frappeHTTPError(err, "invalid period")
+	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.social_security.get_sso_report", map[string]string{
-		"month": month, "year": year,
-	})
+	report, err := tenantFrappe(c, h.frappe).SocialSecurity().Report(c.Request().Context(), month, year)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch SSO report")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": report})
 }
 
 func (h *SocialSecurityHandler) GetEmployeeSSO(c echo.Context) error {
 	employeeID := c.Param("id")
-	data, err := h.frappe.CallMethod("hr_core_ext.api.social_security.get_employee_sso_number", map[string]string{
-		"employee_id": employeeID,
-	})
+	sso, err := tenantFrappe(c, h.frappe).SocialSecurity().Number(c.Request().Context(), employeeID)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch employee SSO number")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": sso})
 }
 
 func (h *SocialSecurityHandler) UpdateEmployeeSSO(c echo.Context) error {
@@ -85,11 +72,9 @@ func (h *SocialSecurityHandler) UpdateEmployeeSSO(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.social_security.update_employee_sso_number", map[string]string{
-		"employee_id": employeeID, "sso_number": req.SSONumber,
-	})
+	sso, err := tenantFrappe(c, h.frappe).SocialSecurity().UpdateNumber(c.Request().Context(), employeeID, req.SSONumber)
 	if err != nil {
 		return frappeHTTPError(err, "failed to update employee SSO number")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": sso})
 }
@@ -1,9 +1,8 @@
 package handler
 
 import (
-	"encoding/json"
-	"fmt"
 	"net/http"
+	"strconv"
 
 	"hr-platform/bff/internal/client"
 	"hr-platform/bff/internal/model"
@@ -20,32 +19,29 @@ func NewTaxHandler(frappe *client.FrappeClient) *TaxHandler {
 }
 
 func (h *TaxHandler) GetSlabs(c echo.Context) error {
-	data, err := h.frappe.CallMethod("hr_core_ext.api.tax.get_tax_slabs", map[string]string{})
+	slabs, err := tenantFrappe(c, h.frappe).Tax().Slabs(c.Request().Context())
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch tax slabs")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": slabs})
 }
 
 func (h *TaxHandler) GetEmployeeDeductions(c echo.Context) error {
 	employeeID := c.Param("id")
-	role := model.UserRole(c.Get("user_role").(string))
 
-	// Employees can only see their own
-	if role == model.RoleEmployee {
+	// Without tax.view_all, callers can only see their own
+	if !hasPermission(c, model.PermTaxViewAll) {
 		myEmployeeID := c.Get("employee_id").(string)
 		if employeeID != myEmployeeID {
 			return echo.NewHTTPError(http.StatusForbidden, "access denied")
 		}
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.tax.get_employee_tax_deductions", map[string]string{
-		"employee_id": employeeID,
-	})
+	deductions, err := tenantFrappe(c, h.frappe).Tax().Deductions(c.Request().Context(), employeeID)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch tax deductions")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": deductions})
 }
 
 func (h *TaxHandler) UpdateEmployeeDeductions(c echo.Context) error {
@@ -65,91 +61,72 @@ func (h *TaxHandler) UpdateEmployeeDeductions(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	params := map[string]string{"employee_id": employeeID}
-	if req.TaxID != nil {
-		params["tax_id"] = *req.TaxID
-	}
-	if req.PersonalAllowance != nil {
-		params["personal_allowance"] = fmt.Sprintf("%.2f", *req.PersonalAllowance)
-	}
-	if req.SpouseAllowance != nil {
-		params["spouse_allowance"] = fmt.Sprintf("%.2f", *req.SpouseAllowance)
-	}
-	if req.ChildrenCount != nil {
-		params["children_count"] = fmt.Sprintf("%d", *req.ChildrenCount)
-	}
-	if req.LifeInsurancePremium != nil {
-		params["life_insurance_premium"] = fmt.Sprintf("%.2f", *req.LifeInsurancePremium)
-	}
-	if req.HealthInsurancePremium != nil {
-		params["health_insurance_premium"] = fmt.Sprintf("%.2f", *req.HealthInsurancePremium)
-	}
-	if req.HousingLoanInterest != nil {
-		params["housing_loan_interest"] = fmt.Sprintf("%.2f", *req.HousingLoanInterest)
-	}
-	if req.DonationDeduction != nil {
-		params["donation_deduction"] = fmt.Sprintf("%.2f", *req.DonationDeduction)
-	}
-
-	data, err := h.frappe.CallMethodPost("hr_core_ext.api.tax.update_employee_tax_deductions", params)
+	deductions, err := tenantFrappe(c, h.frappe).Tax().UpdateDeductions(c.Request().Context(), employeeID, req)
 	if err != nil {
 		return frappeHTTPError(err, "failed to update tax deductions")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": deductions})
 }
 
 func (h *TaxHandler) GetEmployeeSummary(c echo.Context) error {
 	employeeID := c.Param("id")
-	year := c.QueryParam("year")
-	if year == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "year is required")
+	year, err := queryYear(c)
+	if err != nil {
+		return err
 	}
 
-	role := model.UserRole(c.Get("user_role").(string))
-	if role == model.RoleEmployee {
+	if !hasPermission(c, model.PermTaxViewAll) {
 		myEmployeeID := c.Get("employee_id").(string)
 		if employeeID != myEmployeeID {
 			return echo.NewHTTPError(http.StatusForbidden, "access denied")
 		}
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.tax.get_employee_tax_summary", map[string]string{
-		"employee_id": employeeID, "year": year,
-	})
+	summary, err := tenantFrappe(c, h.frappe).Tax().Summary(c.Request().Context(), employeeID, year)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch tax summary")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": summary})
 }
 
 func (h *TaxHandler) GetPND1(c echo.Context) error {
-	month := c.QueryParam("month")
-	year := c.QueryParam("year")
-	if month == "" || year == "" {
+	if c.QueryParam("month") == "" || c.QueryParam("year") == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "month and year are required")
 	}
+	month, year, err := client.ParsePeriod(c.QueryParam("month"), c.QueryParam("year"))
+	if err != nil {
+		return frappeHTTPError(err, "invalid period")
+	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.tax.get_pnd1_data", map[string]string{
-		"month": month, "year": year,
-	})
+	report, err := tenantFrappe(c, h.frappe).Tax().PND1(c.Request().Context(), month, year)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch PND1 data")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": report})
 }
 
 func (h *TaxHandler) GetWithholdingCert(c echo.Context) error {
 	employeeID := c.Param("id")
-	year := c.QueryParam("year")
-	if year == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "year is required")
+	year, err := queryYear(c)
+	if err != nil {
+		return err
 	}
 
-	data, err := h.frappe.CallMethod("hr_core_ext.api.tax.get_withholding_cert_data", map[string]string{
-		"employee_id": employeeID, "year": year,
-	})
+	cert, err := tenantFrappe(c, h.frappe).Tax().WithholdingCert(c.Request().Context(), employeeID, year)
 	if err != nil {
 		return frappeHTTPError(err, "failed to fetch withholding certificate data")
 	}
-	return c.JSON(http.StatusOK, map[string]interface{}{"data": json.RawMessage(data)})
+	return c.JSON(http.StatusOK, map[string]interface{}{"data": cert})
+}
+
+// queryYear reads the required year query param.
+func queryYear(c echo.Context) (int, error) {
+	if c.QueryParam("year") == "" {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "year is required")
+	}
+	year, err := strconv.Atoi(c.QueryParam("year"))
+	if err != nil {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "year must be a number")
+	}
+	return year, nil
 }
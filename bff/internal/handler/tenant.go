@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"hr-platform/bff/internal/client"
+
+	"github.com/labstack/echo/v4"
+)
+
+// tenantFrappe returns a Frappe client scoped to the caller's company (set by TenantMiddleware).
+func tenantFrappe(c echo.Context, frappe *client.FrappeClient) *client.TenantClient {
+	company, _ := c.Get("frappe_company").(string)
+	return frappe.ForCompany(company)
+}
+
+// verifyTenantEmployee checks an employee ID taken from a query param or body
+// against the caller's company. Path params are covered by RequireTenantEmployee.
+func verifyTenantEmployee(c echo.Context, frappe *client.FrappeClient, employeeID string) error {
+	if err := tenantFrappe(c, frappe).VerifyEmployee(c.Request().Context(), employeeID); err != nil {
+		if errors.Is(err, client.ErrCrossTenant) {
+			return echo.NewHTTPError(http.StatusNotFound, "employee not found")
+		}
+		return frappeHTTPError(err, "failed to verify employee")
+	}
+	return nil
+}
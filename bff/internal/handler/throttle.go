@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hr-platform/bff/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// throttleWindow is how long a failure counts towards backoff and lockout.
+const throttleWindow = time.Hour
+
+// throttlePolicy is an exponential backoff that turns into a lockout after lockAt failures.
+type throttlePolicy struct {
+	free     int           // failures allowed before any delay
+	lockAt   int           // failures that lock the key for lockout
+	maxDelay time.Duration // cap on the backoff delay before lockAt
+	lockout  time.Duration
+}
+
+var (
+	// accountThrottle protects a single account from password guessing.
+	accountThrottle = throttlePolicy{free: 3, lockAt: 10, maxDelay: time.Minute, lockout: 15 * time.Minute}
+	// ipThrottle is looser because offices share an address behind NAT.
+	ipThrottle = throttlePolicy{free: 30, lockAt: 200, maxDelay: time.Minute, lockout: 30 * time.Minute}
+)
+
+// delay returns how long the key is blocked after the given number of failures:
+// nothing for the first few, then 1s, 2s, 4s... up to maxDelay, then the full lockout.
+func (p throttlePolicy) delay(failures int) time.Duration {
+	switch {
+	case failures >= p.lockAt:
+		return p.lockout
+	case failures < p.free:
+		return 0
+	}
+	d := time.Duration(math.Pow(2, float64(failures-p.free))) * time.Second
+	if d > p.maxDelay {
+		return p.maxDelay
+	}
+	return d
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// throttleWait returns how long the caller must wait before trying any of the keys again.
+func throttleWait(ctx context.Context, repo *repository.LoginThrottleRepository, keys ...string) (time.Duration, error) {
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range keys {
+		t, err := repo.Get(ctx, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if t.Locked(now) && t.LockedUntil.Sub(now) > wait {
+			wait = t.LockedUntil.Sub(now)
+		}
+	}
+	return wait, nil
+}
+
+// recordThrottleFailure counts a failure against key and applies the policy's delay.
+// It returns the new failure count.
+func recordThrottleFailure(ctx context.Context, repo *repository.LoginThrottleRepository, key string, p throttlePolicy) (int, error) {
+	t, err := repo.RecordFailure(ctx, key, throttleWindow)
+	if err != nil {
+		return 0, err
+	}
+	if d := p.delay(t.Failures); d > 0 {
+		if err := repo.Lock(ctx, key, time.Now().Add(d)); err != nil {
+			return t.Failures, err
+		}
+	}
+	return t.Failures, nil
+}
+
+// tooManyAttempts is the single response for every throttled login, whether or not
+// the account exists.
+func tooManyAttempts(c echo.Context, wait time.Duration) error {
+	c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	return echo.NewHTTPError(http.StatusTooManyRequests, "too many login attempts, try again later")
+}
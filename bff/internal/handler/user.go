@@ -2,7 +2,9 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
+	"hr-platform/bff/internal/client"
 	"hr-platform/bff/internal/model"
 	"hr-platform/bff/internal/repository"
 
@@ -10,12 +12,30 @@ import (
 )
 
 type UserHandler struct {
-	userRepo  *repository.UserRepository
-	auditRepo *repository.AuditRepository
+	userRepo     *repository.UserRepository
+	sessionRepo  *repository.SessionRepository
+	throttleRepo *repository.LoginThrottleRepository
+	roleRepo     *repository.RoleRepository
+	auditRepo    *repository.AuditRepository
+	frappe       *client.FrappeClient
 }
 
-func NewUserHandler(userRepo *repository.UserRepository, auditRepo *repository.AuditRepository) *UserHandler {
-	return &UserHandler{userRepo: userRepo, auditRepo: auditRepo}
+func NewUserHandler(
+	userRepo *repository.UserRepository,
+	sessionRepo *repository.SessionRepository,
+	throttleRepo *repository.LoginThrottleRepository,
+	roleRepo *repository.RoleRepository,
+	auditRepo *repository.AuditRepository,
+	frappe *client.FrappeClient,
+) *UserHandler {
+	return &UserHandler{
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		throttleRepo: throttleRepo,
+		roleRepo:     roleRepo,
+		auditRepo:    auditRepo,
+		frappe:       frappe,
+	}
 }
 
 // List returns all users in the caller's company.
@@ -33,6 +53,7 @@ func (h *UserHandler) List(c echo.Context) error {
 		Email            string          `json:"email"`
 		FullName         string          `json:"full_name"`
 		Role             model.UserRole  `json:"role"`
+		RoleID           *string         `json:"role_id,omitempty"`
 		Status           model.UserStatus `json:"status"`
 		FrappeEmployeeID *string         `json:"frappe_employee_id,omitempty"`
 		LastLoginAt      interface{}     `json:"last_login_at,omitempty"`
@@ -46,6 +67,7 @@ func (h *UserHandler) List(c echo.Context) error {
 			Email:            u.Email,
 			FullName:         u.FullName,
 			Role:             u.Role,
+			RoleID:           u.RoleID,
 			Status:           u.Status,
 			FrappeEmployeeID: u.FrappeEmployeeID,
 			LastLoginAt:      u.LastLoginAt,
@@ -61,13 +83,14 @@ func (h *UserHandler) Get(c echo.Context) error {
 	userID := c.Param("id")
 	companyID := c.Get("company_id").(string)
 
-	user, err := h.userRepo.GetByID(c.Request().Context(), userID)
+	user, err := h.userRepo.GetInCompany(c.Request().Context(), userID, companyID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "user not found")
 	}
 
-	if user.CompanyID != companyID {
-		return echo.NewHTTPError(http.StatusForbidden, "user belongs to another company")
+	var lockedUntil *time.Time
+	if t, err := h.throttleRepo.Get(c.Request().Context(), accountThrottleKey(user.Email)); err == nil && t.Locked(time.Now()) {
+		lockedUntil = t.LockedUntil
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -76,29 +99,50 @@ func (h *UserHandler) Get(c echo.Context) error {
 			"email":              user.Email,
 			"full_name":          user.FullName,
 			"role":               user.Role,
+			"role_id":            user.RoleID,
 			"status":             user.Status,
 			"frappe_employee_id": user.FrappeEmployeeID,
 			"last_login_at":      user.LastLoginAt,
+			"locked_until":       lockedUntil,
 			"created_at":         user.CreatedAt,
 		},
 	})
 }
 
-// ChangeRole changes the role of a user (admin only).
+// ChangeRole assigns a built-in role ("role") or one of the company's custom roles ("role_id").
+// Callers can only move users between roles whose permissions they hold themselves, so
+// HR cannot promote anyone to admin or demote an admin.
 func (h *UserHandler) ChangeRole(c echo.Context) error {
 	targetID := c.Param("id")
 	companyID := c.Get("company_id").(string)
 	actorID := c.Get("user_id").(string)
+	ctx := c.Request().Context()
 
 	var req struct {
-		Role model.UserRole `json:"role"`
+		Role   model.UserRole `json:"role"`
+		RoleID string         `json:"role_id"`
 	}
 	if err := c.Bind(&req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
 	}
 
-	if req.Role != model.RoleAdmin && req.Role != model.RoleHR && req.Role != model.RoleManager && req.Role != model.RoleEmployee {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid role")
+	var (
+		newRole *model.Role
+		err     error
+	)
+	if req.RoleID != "" {
+		newRole, err = h.roleRepo.GetByID(ctx, req.RoleID)
+		if err != nil || newRole.BuiltIn() || *newRole.CompanyID != companyID {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid role")
+		}
+	} else {
+		if req.Role != model.RoleAdmin && req.Role != model.RoleHR && req.Role != model.RoleManager && req.Role != model.RoleEmployee {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid role")
+		}
+		newRole, err = h.roleRepo.GetBuiltIn(ctx, req.Role)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to load role")
+		}
 	}
 
 	// Prevent self-demotion
@@ -106,29 +150,42 @@ func (h *UserHandler) ChangeRole(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "cannot change your own role")
 	}
 
-	user, err := h.userRepo.GetByID(c.Request().Context(), targetID)
+	user, err := h.userRepo.GetInCompany(ctx, targetID, companyID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "user not found")
 	}
 
-	if user.CompanyID != companyID {
-		return echo.NewHTTPError(http.StatusForbidden, "user belongs to another company")
+	current, err := h.roleRepo.AccessForUser(ctx, targetID, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load role")
+	}
+	if err := requireHeldPermissions(c, current.Permissions.List()); err != nil {
+		return err
+	}
+	if err := requireHeldPermissions(c, newRole.Permissions); err != nil {
+		return err
 	}
 
-	oldRole := user.Role
-	if err := h.userRepo.UpdateRole(c.Request().Context(), targetID, req.Role); err != nil {
+	var roleID *string
+	if !newRole.BuiltIn() {
+		roleID = &newRole.ID
+	}
+	if err := h.userRepo.UpdateRole(ctx, targetID, companyID, newRole.BaseRole, roleID); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update role")
 	}
 
-	_ = h.auditRepo.Log(c.Request().Context(), actorID, companyID, "user.role_changed", "user", targetID, map[string]string{
-		"old_role": string(oldRole),
-		"new_role": string(req.Role),
+	_ = h.auditRepo.Log(ctx, actorID, companyID, "user.role_changed", "user", targetID, map[string]string{
+		"old_role":      string(user.Role),
+		"old_role_name": current.RoleName,
+		"new_role":      string(newRole.BaseRole),
+		"new_role_name": newRole.Name,
 	})
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "role updated"})
 }
 
-// ChangeStatus suspends or disables a user (admin/HR).
+// ChangeStatus suspends or disables a user's membership of the caller's company (user.manage).
+// Suspending or disabling a user also revokes their sessions in this company.
 func (h *UserHandler) ChangeStatus(c echo.Context) error {
 	targetID := c.Param("id")
 	companyID := c.Get("company_id").(string)
@@ -149,17 +206,22 @@ func (h *UserHandler) ChangeStatus(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "cannot change your own status")
 	}
 
-	user, err := h.userRepo.GetByID(c.Request().Context(), targetID)
+	user, err := h.userRepo.GetInCompany(c.Request().Context(), targetID, companyID)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, "user not found")
 	}
 
-	if user.CompanyID != companyID {
-		return echo.NewHTTPError(http.StatusForbidden, "user belongs to another company")
+	// Only users whose permissions the caller holds can be suspended, so HR cannot lock out an admin
+	access, err := h.roleRepo.AccessForUser(c.Request().Context(), targetID, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to load role")
+	}
+	if err := requireHeldPermissions(c, access.Permissions.List()); err != nil {
+		return err
 	}
 
 	oldStatus := user.Status
-	if err := h.userRepo.UpdateStatus(c.Request().Context(), targetID, req.Status); err != nil {
+	if err := h.userRepo.UpdateStatus(c.Request().Context(), targetID, companyID, req.Status); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update status")
 	}
 
@@ -168,9 +230,39 @@ func (h *UserHandler) ChangeStatus(c echo.Context) error {
 		"new_status": string(req.Status),
 	})
 
+	if req.Status != model.StatusActive {
+		revoked, err := h.sessionRepo.RevokeAllInCompany(c.Request().Context(), targetID, companyID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke sessions")
+		}
+		_ = h.auditRepo.Log(c.Request().Context(), actorID, companyID, "user.sessions_revoked", "user", targetID, map[string]int64{
+			"sessions": revoked,
+		})
+	}
+
 	return c.JSON(http.StatusOK, map[string]string{"message": "status updated"})
 }
 
+// Unlock clears a user's failed-login counter so they can sign in again immediately (admin/HR).
+func (h *UserHandler) Unlock(c echo.Context) error {
+	targetID := c.Param("id")
+	companyID := c.Get("company_id").(string)
+	actorID := c.Get("user_id").(string)
+
+	user, err := h.userRepo.GetInCompany(c.Request().Context(), targetID, companyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "user not found")
+	}
+
+	if err := h.throttleRepo.Clear(c.Request().Context(), accountThrottleKey(user.Email)); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unlock user")
+	}
+
+	_ = h.auditRepo.Log(c.Request().Context(), actorID, companyID, "user.unlocked", "user", targetID, nil)
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "user unlocked"})
+}
+
 // LinkEmployee links a user to a Frappe employee (admin/HR).
 func (h *UserHandler) LinkEmployee(c echo.Context) error {
 	targetID := c.Param("id")
@@ -187,17 +279,15 @@ func (h *UserHandler) LinkEmployee(c echo.Context) error {
 	if req.FrappeEmployeeID == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "frappe_employee_id is required")
 	}
-
-	user, err := h.userRepo.GetByID(c.Request().Context(), targetID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, "user not found")
+	if err := verifyTenantEmployee(c, h.frappe, req.FrappeEmployeeID); err != nil {
+		return err
 	}
 
-	if user.CompanyID != companyID {
-		return echo.NewHTTPError(http.StatusForbidden, "user belongs to another company")
+	if _, err := h.userRepo.GetInCompany(c.Request().Context(), targetID, companyID); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "user not found")
 	}
 
-	if err := h.userRepo.LinkEmployee(c.Request().Context(), targetID, req.FrappeEmployeeID); err != nil {
+	if err := h.userRepo.LinkEmployee(c.Request().Context(), targetID, companyID, req.FrappeEmployeeID); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to link employee")
 	}
 
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hr-platform/bff/internal/client"
+	"hr-platform/bff/internal/frappetest"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newAcmeFrappe starts a fake Frappe and returns its client with a request context of
+// an Acme admin, as TenantMiddleware and JWTMiddleware would set it up.
+func newAcmeFrappe(t *testing.T, method, body string) (*client.FrappeClient, echo.Context) {
+	t.Helper()
+	fake := frappetest.New()
+	t.Cleanup(fake.Close)
+
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	c.Set("user_id", "user-admin")
+	c.Set("company_id", "company-acme")
+	c.Set("frappe_company", frappetest.CompanyAcme)
+	return fake.Client(client.Options{MaxRetries: -1}), c
+}
+
+// expectNotFound fails unless err is the 404 verifyTenantEmployee answers for an
+// employee of another company.
+func expectNotFound(t *testing.T, err error) {
+	t.Helper()
+	he, ok := err.(*echo.HTTPError)
+	if !ok || he.Code != http.StatusNotFound {
+		t.Fatalf("err = %v, want 404 employee not found", err)
+	}
+}
+
+// The repositories are left nil: the employee check must refuse before any is used.
+func TestLinkEmployeeOfAnotherCompany(t *testing.T) {
+	fc, c := newAcmeFrappe(t, http.MethodPut, `{"frappe_employee_id":"`+frappetest.EmpGus+`"}`)
+	c.SetParamNames("id")
+	c.SetParamValues("user-alice")
+
+	h := &UserHandler{frappe: fc}
+	expectNotFound(t, h.LinkEmployee(c))
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"hr-platform/bff/internal/webhook"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxWebhookBody bounds a delivery; events carry a handful of fields.
+const maxWebhookBody = 64 << 10
+
+type webhookDeliveryStore interface {
+	Claim(ctx context.Context, source, id string) (bool, error)
+	Release(ctx context.Context, id string) error
+}
+
+type webhookDispatcher interface {
+	Dispatch(ctx context.Context, ev webhook.Event) error
+}
+
+// WebhookHandler receives document changes Frappe pushes to the BFF. Deliveries are
+// authenticated by their signature rather than a user token.
+type WebhookHandler struct {
+	secret     []byte
+	deliveries webhookDeliveryStore
+	dispatcher webhookDispatcher
+	now        func() time.Time
+}
+
+// NewWebhookHandler returns a handler that refuses every delivery if secret is empty.
+func NewWebhookHandler(secret string, deliveries webhookDeliveryStore, dispatcher webhookDispatcher) *WebhookHandler {
+	return &WebhookHandler{secret: []byte(secret), deliveries: deliveries, dispatcher: dispatcher, now: time.Now}
+}
+
+// Frappe accepts an event from hr_core_ext/webhooks.py. A delivery that was already
+// processed is acknowledged without acting on it again; a failure to process one is a
+// 500 so that Frappe sends it again.
+func (h *WebhookHandler) Frappe(c echo.Context) error {
+	if len(h.secret) == 0 {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "webhooks are not configured")
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxWebhookBody+1))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to read body")
+	}
+	if len(body) > maxWebhookBody {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "webhook body is too large")
+	}
+
+	id := c.Request().Header.Get(webhook.HeaderID)
+	err = webhook.Verify(h.secret, id, c.Request().Header.Get(webhook.HeaderTimestamp), c.Request().Header.Get(webhook.HeaderSignature), body, h.now())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	}
+
+	var ev webhook.Event
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid event")
+	}
+	if ev.ID != id {
+		return echo.NewHTTPError(http.StatusBadRequest, "event id does not match "+webhook.HeaderID)
+	}
+
+	ctx := c.Request().Context()
+	first, err := h.deliveries.Claim(ctx, "frappe", id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to record delivery")
+	}
+	if !first {
+		return c.JSON(http.StatusOK, map[string]string{"status": "duplicate"})
+	}
+
+	if err := h.dispatcher.Dispatch(ctx, ev); err != nil {
+		if errors.Is(err, webhook.ErrUnsupported) {
+			return c.JSON(http.StatusOK, map[string]string{"status": "ignored"})
+		}
+		log.Printf("webhook: dispatching %s %s (%s): %v", ev.Doctype, ev.Name, id, err)
+		_ = h.deliveries.Release(context.WithoutCancel(ctx), id)
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to process event")
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "accepted"})
+}
@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/webhook"
+
+	"github.com/labstack/echo/v4"
+)
+
+const fakeWebhookSecret = "webhook-secret"
+
+var fakeWebhookTime = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+// fakeWebhookBackend stands in for the repositories behind WebhookHandler and its dispatcher.
+type fakeWebhookBackend struct {
+	claimed  map[string]bool
+	released []string
+	notices  []string // "<user>:<type>:<title>"
+	audits   []string // action
+	failLog  bool
+	// directory changes, "refresh:<employee>" or "remove:<employee>"
+	directory []string
+}
+
+func (b *fakeWebhookBackend) Claim(_ context.Context, _, id string) (bool, error) {
+	if b.claimed[id] {
+		return false, nil
+	}
+	b.claimed[id] = true
+	return true, nil
+}
+
+func (b *fakeWebhookBackend) Release(_ context.Context, id string) error {
+	delete(b.claimed, id)
+	b.released = append(b.released, id)
+	return nil
+}
+
+func (b *fakeWebhookBackend) GetByFrappeCompanyName(_ context.Context, name string) (*model.Company, error) {
+	if name != "Acme Co" {
+		return nil, sql.ErrNoRows
+	}
+	return &model.Company{ID: "company-1", FrappeCompanyName: name}, nil
+}
+
+func (b *fakeWebhookBackend) GetByFrappeEmployeeID(_ context.Context, companyID, employee string) (*model.User, error) {
+	if companyID != "company-1" || employee != "HR-EMP-00001" {
+		return nil, sql.ErrNoRows
+	}
+	return &model.User{ID: "usr-alice", CompanyID: companyID}, nil
+}
+
+func (b *fakeWebhookBackend) CreateForUser(_ context.Context, userID, _, notifType, title, _ string) error {
+	b.notices = append(b.notices, userID+":"+notifType+":"+title)
+	return nil
+}
+
+func (b *fakeWebhookBackend) Log(_ context.Context, _, _, action, _, _ string, _ interface{}) error {
+	if b.failLog {
+		return sql.ErrConnDone
+	}
+	b.audits = append(b.audits, action)
+	return nil
+}
+
+func (b *fakeWebhookBackend) Refresh(_ context.Context, _ *model.Company, employeeID string) error {
+	b.directory = append(b.directory, "refresh:"+employeeID)
+	return nil
+}
+
+func (b *fakeWebhookBackend) Remove(_ context.Context, _ *model.Company, employeeID string) error {
+	b.directory = append(b.directory, "remove:"+employeeID)
+	return nil
+}
+
+func newWebhookTestServer(secret string, b *fakeWebhookBackend) *echo.Echo {
+	h := NewWebhookHandler(secret, b, webhook.NewDispatcher(b, b, b, b, b))
+	h.now = func() time.Time { return fakeWebhookTime }
+	e := echo.New()
+	e.POST("/api/webhooks/frappe", h.Frappe)
+	return e
+}
+
+// postWebhook signs ev the way hr_core_ext/webhooks.py does and posts it.
+func postWebhook(t *testing.T, e *echo.Echo, ev webhook.Event, sent time.Time, secret string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := strconv.FormatInt(sent.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/api/webhooks/frappe", strings.NewReader(string(body)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(webhook.HeaderID, ev.ID)
+	req.Header.Set(webhook.HeaderTimestamp, ts)
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign([]byte(secret), ev.ID, ts, body))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func leaveEvent(id, origin string) webhook.Event {
+	return webhook.Event{
+		ID: id, Event: "on_update", Doctype: "Leave Application", Name: "HR-LAP-0001",
+		Company: "Acme Co", Employee: "HR-EMP-00001", Status: "Approved", PreviousStatus: "Open",
+		Origin: origin, User: "hr@example.com",
+	}
+}
+
+func TestFrappeWebhook(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string // the handler's; deliveries are signed with fakeWebhookSecret
+		event     webhook.Event
+		sent      time.Time
+		failLog   bool
+		status    int
+		result    string
+		notices   []string
+		audits    []string
+		directory []string
+		released  bool
+	}{
+		{
+			name: "approval in the desk notifies the employee", event: leaveEvent("evt-1", "desk"),
+			status: http.StatusOK, result: "accepted",
+			notices: []string{"usr-alice:leave_approval:Leave Approved"}, audits: []string{"frappe.leave_application.approved"},
+		},
+		{
+			name: "approval through the BFF is only audited", event: leaveEvent("evt-1", "api"),
+			status: http.StatusOK, result: "accepted", audits: []string{"frappe.leave_application.approved"},
+		},
+		{
+			name: "employee without an account is only audited",
+			event: func() webhook.Event {
+				ev := leaveEvent("evt-1", "desk")
+				ev.Employee = "HR-EMP-00002"
+				return ev
+			}(),
+			status: http.StatusOK, result: "accepted", audits: []string{"frappe.leave_application.approved"},
+		},
+		{
+			name: "unknown company is ignored",
+			event: func() webhook.Event {
+				ev := leaveEvent("evt-1", "desk")
+				ev.Company = "Other Co"
+				return ev
+			}(),
+			status: http.StatusOK, result: "accepted",
+		},
+		{
+			name: "submitted payslip",
+			event: webhook.Event{
+				ID: "evt-1", Event: "on_submit", Doctype: "Salary Slip", Name: "Sal Slip/HR-EMP-00001/00001",
+				Company: "Acme Co", Employee: "HR-EMP-00001", Status: "Submitted", DocStatus: 1, PreviousStatus: "Draft",
+				Origin: "system", Fields: map[string]any{"start_date": "2026-02-01"},
+			},
+			status: http.StatusOK, result: "accepted",
+			notices: []string{"usr-alice:payroll_processed:Payslip available"}, audits: []string{"frappe.salary_slip.submitted"},
+		},
+		{
+			name:   "employee change refreshes the directory",
+			event:  webhook.Event{ID: "evt-1", Event: "on_update", Doctype: "Employee", Name: "HR-EMP-00001", Company: "Acme Co", Status: "Active"},
+			status: http.StatusOK, result: "accepted", directory: []string{"refresh:HR-EMP-00001"},
+		},
+		{
+			name:   "deleted employee leaves the directory",
+			event:  webhook.Event{ID: "evt-1", Event: "on_trash", Doctype: "Employee", Name: "HR-EMP-00001", Company: "Acme Co"},
+			status: http.StatusOK, result: "accepted", directory: []string{"remove:HR-EMP-00001"},
+		},
+		{
+			name:   "unsupported doctype",
+			event:  webhook.Event{ID: "evt-1", Doctype: "Expense Claim", Name: "HR-EXP-0001", Company: "Acme Co"},
+			status: http.StatusOK, result: "ignored",
+		},
+		{
+			name: "timestamp outside the window", event: leaveEvent("evt-1", "desk"),
+			sent: fakeWebhookTime.Add(-webhook.Tolerance - time.Second), status: http.StatusUnauthorized,
+		},
+		{
+			name: "wrong secret", secret: "another-secret", event: leaveEvent("evt-1", "desk"),
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "dispatch failure releases the delivery", event: leaveEvent("evt-1", "desk"), failLog: true,
+			status: http.StatusInternalServerError, notices: []string{"usr-alice:leave_approval:Leave Approved"}, released: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := tt.secret
+			if secret == "" {
+				secret = fakeWebhookSecret
+			}
+			sent := tt.sent
+			if sent.IsZero() {
+				sent = fakeWebhookTime
+			}
+			b := &fakeWebhookBackend{claimed: map[string]bool{}, failLog: tt.failLog}
+			rec := postWebhook(t, newWebhookTestServer(secret, b), tt.event, sent, fakeWebhookSecret)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status %d, want %d\n%s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.result != "" {
+				var got struct{ Status string }
+				if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.Status != tt.result {
+					t.Errorf("result %s, want %q", rec.Body, tt.result)
+				}
+			}
+			if strings.Join(b.notices, ",") != strings.Join(tt.notices, ",") {
+				t.Errorf("notices %v, want %v", b.notices, tt.notices)
+			}
+			if strings.Join(b.audits, ",") != strings.Join(tt.audits, ",") {
+				t.Errorf("audits %v, want %v", b.audits, tt.audits)
+			}
+			if strings.Join(b.directory, ",") != strings.Join(tt.directory, ",") {
+				t.Errorf("directory %v, want %v", b.directory, tt.directory)
+			}
+			if released := len(b.released) > 0; released != tt.released {
+				t.Errorf("released %v, want %v", b.released, tt.released)
+			}
+		})
+	}
+}
+
+// TestFrappeWebhookReplay checks that a redelivered event is acknowledged but not acted
+// on twice, and that a handler without a secret refuses deliveries.
+func TestFrappeWebhookReplay(t *testing.T) {
+	b := &fakeWebhookBackend{claimed: map[string]bool{}}
+	e := newWebhookTestServer(fakeWebhookSecret, b)
+	ev := leaveEvent("evt-1", "desk")
+
+	if rec := postWebhook(t, e, ev, fakeWebhookTime, fakeWebhookSecret); rec.Code != http.StatusOK {
+		t.Fatalf("first delivery: status %d\n%s", rec.Code, rec.Body)
+	}
+	rec := postWebhook(t, e, ev, fakeWebhookTime.Add(time.Minute), fakeWebhookSecret)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"duplicate"`) {
+		t.Fatalf("redelivery: status %d\n%s", rec.Code, rec.Body)
+	}
+	if len(b.notices) != 1 || len(b.audits) != 1 {
+		t.Errorf("redelivery acted again: notices %v, audits %v", b.notices, b.audits)
+	}
+
+	unconfigured := newWebhookTestServer("", &fakeWebhookBackend{claimed: map[string]bool{}})
+	if rec := postWebhook(t, unconfigured, ev, fakeWebhookTime, fakeWebhookSecret); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("without a secret: status %d, want 503", rec.Code)
+	}
+}
@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"context"
+	"log"
+)
+
+// Message is an email with a plain-text body and, optionally, an HTML alternative.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	HTML    string
+}
+
+// Sender delivers outgoing email. Handlers depend on this interface so the
+// transport can be swapped per environment.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender writes messages to the server log instead of delivering them, tokens
+// included. It is only allowed in local development.
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("[mail] to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,56 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+)
+
+// Templates live in templates/<name>.<lang>.txt and .html. The .txt file also defines
+// "<name>.<lang>.subject".
+//
+//go:embed templates
+var templateFS embed.FS
+
+// DefaultLanguage is used when a recipient's language is unknown or unsupported.
+const DefaultLanguage = "th"
+
+// bangkok is Thailand's fixed UTC+7 offset; the runtime image ships without tzdata.
+var bangkok = time.FixedZone("ICT", 7*60*60)
+
+var templateFuncs = map[string]any{
+	// date formats t in Thai time, which is what recipients expect on a deadline
+	"date": func(t time.Time) string { return t.In(bangkok).Format("2 Jan 2006 15:04") },
+}
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.New("").Funcs(templateFuncs).ParseFS(templateFS, "templates/*.txt"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.New("").Funcs(templateFuncs).ParseFS(templateFS, "templates/*.html"))
+)
+
+// Language returns lang if there are templates in it, else DefaultLanguage.
+func Language(lang string) string {
+	if lang == "en" || lang == "th" {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// Render executes the name template in lang for to.
+func Render(name, lang, to string, data any) (Message, error) {
+	base := name + "." + Language(lang)
+
+	var subject, text, html bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&subject, base+".subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := textTemplates.ExecuteTemplate(&text, base+".txt", data); err != nil {
+		return Message{}, err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&html, base+".html", data); err != nil {
+		return Message{}, err
+	}
+	return Message{To: to, Subject: subject.String(), Body: text.String(), HTML: html.String()}, nil
+}
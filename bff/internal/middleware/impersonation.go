@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"hr-platform/bff/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// auditLog is the part of repository.AuditRepository ImpersonationMiddleware needs.
+type auditLog interface {
+	Log(ctx context.Context, actorID, companyID, action, targetType, targetID string, details interface{}) error
+}
+
+// ImpersonationMiddleware audits every request made with an impersonation token as
+// "impersonation.request", and tags the request context so audit entries written by
+// handlers also record the impersonator (see repository.WithImpersonator).
+func ImpersonationMiddleware(auditRepo auditLog) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			impersonatorID, _ := c.Get("impersonator_id").(string)
+			if impersonatorID == "" {
+				return next(c)
+			}
+
+			ctx := repository.WithImpersonator(c.Request().Context(), impersonatorID)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+
+			// Log even if the client has gone away.
+			_ = auditRepo.Log(context.WithoutCancel(ctx), c.Get("user_id").(string), c.Get("company_id").(string),
+				"impersonation.request", "session", c.Get("session_id").(string), map[string]interface{}{
+					"method":     c.Request().Method,
+					"path":       c.Request().URL.Path,
+					"status":     responseStatus(c, err),
+					"ip_address": c.RealIP(),
+				})
+			return err
+		}
+	}
+}
+
+// BlockImpersonation rejects the request when it is made with an impersonation token.
+// It guards routes that change credentials or sensitive personal data.
+func BlockImpersonation(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if id, _ := c.Get("impersonator_id").(string); id != "" {
+			return echo.NewHTTPError(http.StatusForbidden, "not allowed while impersonating")
+		}
+		return next(c)
+	}
+}
+
+// responseStatus returns the status code the request ended with, including errors
+// that Echo's error handler has not written yet.
+func responseStatus(c echo.Context, err error) int {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return he.Code
+	}
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	return c.Response().Status
+}
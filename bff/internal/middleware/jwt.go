@@ -5,6 +5,10 @@ import (
 	"strings"
 	"time"
 
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/repository"
+	"hr-platform/bff/internal/signing"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
@@ -16,28 +20,44 @@ type JWTClaims struct {
 	EmployeeID string `json:"employee_id"`
 	Role       string `json:"role"`
 	CompanyID  string `json:"company_id"`
+	SessionID  string `json:"sid"`
+	// ImpersonatorID is the admin acting as UserID, set only on impersonation tokens.
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(secret string, userID, email, fullName, employeeID, role, companyID string, expiryHours int) (string, error) {
+// GenerateToken issues a short-lived access token bound to a server-side session, signed
+// with the current key in keys. impersonatorID is empty except for impersonation tokens.
+func GenerateToken(keys *signing.KeySet, sessionID, userID, email, fullName, employeeID, role, companyID, impersonatorID string, ttl time.Duration) (string, error) {
 	claims := &JWTClaims{
-		UserID:     userID,
-		Email:      email,
-		FullName:   fullName,
-		EmployeeID: employeeID,
-		Role:       role,
-		CompanyID:  companyID,
+		SessionID:      sessionID,
+		UserID:         userID,
+		Email:          email,
+		FullName:       fullName,
+		EmployeeID:     employeeID,
+		Role:           role,
+		CompanyID:      companyID,
+		ImpersonatorID: impersonatorID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expiryHours) * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return keys.Sign(claims)
 }
 
-func JWTMiddleware(secret string) echo.MiddlewareFunc {
+// JWTMiddleware validates the access token against keys and rejects it once its session
+// has been revoked.
+// A bearer token starting with model.APIKeyPrefix is authenticated as an API key instead
+// (see authenticateAPIKey) and sets the same context keys.
+func JWTMiddleware(
+	keys *signing.KeySet,
+	sessionRepo *repository.SessionRepository,
+	apiKeyRepo *repository.APIKeyRepository,
+	userRepo *repository.UserRepository,
+	auditRepo *repository.AuditRepository,
+) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			authHeader := c.Request().Header.Get("Authorization")
@@ -54,21 +74,36 @@ func JWTMiddleware(secret string) echo.MiddlewareFunc {
 				return echo.NewHTTPError(http.StatusUnauthorized, "invalid authorization header")
 			}
 
+			if strings.HasPrefix(tokenStr, model.APIKeyPrefix) {
+				return authenticateAPIKey(c, next, tokenStr, apiKeyRepo, userRepo, auditRepo)
+			}
+
 			claims := &JWTClaims{}
-			token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
-				return []byte(secret), nil
-			})
+			token, err := jwt.ParseWithClaims(tokenStr, claims, keys.Keyfunc, jwt.WithValidMethods(signing.Algorithms))
 
 			if err != nil || !token.Valid {
 				return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired token")
 			}
 
+			if claims.SessionID == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired token")
+			}
+			active, err := sessionRepo.IsActive(c.Request().Context(), claims.SessionID)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to verify session")
+			}
+			if !active {
+				return echo.NewHTTPError(http.StatusUnauthorized, "session has been revoked")
+			}
+
 			c.Set("user_id", claims.UserID)
 			c.Set("user_email", claims.Email)
 			c.Set("user_name", claims.FullName)
 			c.Set("employee_id", claims.EmployeeID)
 			c.Set("user_role", claims.Role)
 			c.Set("company_id", claims.CompanyID)
+			c.Set("session_id", claims.SessionID)
+			c.Set("impersonator_id", claims.ImpersonatorID)
 
 			return next(c)
 		}
@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 
 	"hr-platform/bff/internal/model"
@@ -8,24 +11,55 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-func RequireRole(allowed ...model.UserRole) echo.MiddlewareFunc {
+// accessStore is the part of repository.RoleRepository PermissionMiddleware needs.
+type accessStore interface {
+	AccessForUser(ctx context.Context, userID, companyID string) (*model.Access, error)
+}
+
+// PermissionMiddleware loads the caller's current role and permissions in their active
+// company from Postgres and stores them as "permissions" (model.PermissionSet), "role_id"
+// and "role_name". It also refreshes "user_role" so a role change applies before the access token is renewed.
+func PermissionMiddleware(roleRepo accessStore) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			roleStr, ok := c.Get("user_role").(string)
-			if !ok {
-				return echo.NewHTTPError(http.StatusForbidden, "role not found in token")
+			userID, _ := c.Get("user_id").(string)
+			if userID == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "user not found in token")
 			}
-			role := model.UserRole(roleStr)
-			for _, r := range allowed {
-				if role == r {
-					return next(c)
-				}
+
+			companyID, _ := c.Get("company_id").(string)
+			access, err := roleRepo.AccessForUser(c.Request().Context(), userID, companyID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return echo.NewHTTPError(http.StatusForbidden, "not a member of this company")
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to load permissions")
 			}
-			return echo.NewHTTPError(http.StatusForbidden, "insufficient permissions")
+
+			c.Set("user_role", string(access.Role))
+			c.Set("role_id", access.RoleID)
+			c.Set("role_name", access.RoleName)
+			c.Set("permissions", access.Permissions)
+
+			return next(c)
 		}
 	}
 }
 
-func RequireAdminOrHR() echo.MiddlewareFunc {
-	return RequireRole(model.RoleAdmin, model.RoleHR)
+// RequirePermission rejects the request unless the caller holds every listed permission.
+func RequirePermission(required ...model.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			perms, ok := c.Get("permissions").(model.PermissionSet)
+			if !ok {
+				return echo.NewHTTPError(http.StatusForbidden, "permissions not loaded")
+			}
+			for _, p := range required {
+				if !perms.Has(p) {
+					return echo.NewHTTPError(http.StatusForbidden, "insufficient permissions")
+				}
+			}
+			return next(c)
+		}
+	}
 }
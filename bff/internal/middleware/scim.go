@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"hr-platform/bff/internal/auth"
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SCIMErrors renders errors from the SCIM routes as SCIM error responses (RFC 7644
+// section 3.12) instead of echo's {"message": ...}.
+func SCIMErrors(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		err := next(c)
+		if err == nil || c.Response().Committed {
+			return err
+		}
+		var scimErr *model.SCIMError
+		var httpErr *echo.HTTPError
+		switch {
+		case errors.As(err, &scimErr):
+		case errors.As(err, &httpErr):
+			scimErr = model.NewSCIMError(httpErr.Code, "", strings.ToLower(http.StatusText(httpErr.Code)))
+			if msg, ok := httpErr.Message.(string); ok {
+				scimErr.Detail = msg
+			}
+		default:
+			return err
+		}
+		if scimErr.HTTPStatus == http.StatusUnauthorized {
+			c.Response().Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+		}
+		c.Response().Header().Set(echo.HeaderContentType, "application/scim+json")
+		return c.JSON(scimErr.HTTPStatus, scimErr)
+	}
+}
+
+// SCIMAuth authenticates identity providers by the company's SCIM bearer token and sets
+// "company_id". There is no user behind the token, so nothing else is set.
+func SCIMAuth(companyRepo *repository.CompanyRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := strings.CutPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+			if !ok || !strings.HasPrefix(token, model.SCIMTokenPrefix) {
+				return model.NewSCIMError(http.StatusUnauthorized, "", "missing or invalid bearer token")
+			}
+
+			company, err := companyRepo.GetBySCIMTokenHash(c.Request().Context(), auth.HashToken(token))
+			if errors.Is(err, sql.ErrNoRows) {
+				return model.NewSCIMError(http.StatusUnauthorized, "", "invalid bearer token")
+			}
+			if err != nil {
+				return model.NewSCIMError(http.StatusInternalServerError, "", "failed to verify token")
+			}
+
+			c.Set("company_id", company.ID)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"hr-platform/bff/internal/client"
+	"hr-platform/bff/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+// companyStore is the part of repository.CompanyRepository TenantMiddleware needs.
+type companyStore interface {
+	GetByID(ctx context.Context, id string) (*model.Company, error)
+}
+
+// TenantMiddleware resolves the JWT company_id to its Frappe company and stores it
+// as "frappe_company" so handlers can build a tenant-scoped Frappe client.
+func TenantMiddleware(companyRepo companyStore) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			companyID, _ := c.Get("company_id").(string)
+			if companyID == "" {
+				return echo.NewHTTPError(http.StatusForbidden, "company not found in token")
+			}
+
+			company, err := companyRepo.GetByID(c.Request().Context(), companyID)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to load company")
+			}
+
+			frappeCompany := company.FrappeCompanyName
+			if frappeCompany == "" {
+				frappeCompany = company.Name
+			}
+			c.Set("frappe_company", frappeCompany)
+
+			return next(c)
+		}
+	}
+}
+
+// RequireTenantEmployee rejects the request unless the employee in the given path
+// param belongs to the caller's company.
+func RequireTenantEmployee(frappe *client.FrappeClient, param string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			company, _ := c.Get("frappe_company").(string)
+			if err := frappe.ForCompany(company).VerifyEmployee(c.Request().Context(), c.Param(param)); err != nil {
+				return tenantHTTPError(err, "employee not found")
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireTenantDocument rejects the request unless the file in docParam is attached
+// to the employee in employeeParam and that employee belongs to the caller's company.
+func RequireTenantDocument(frappe *client.FrappeClient, employeeParam, docParam string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			company, _ := c.Get("frappe_company").(string)
+			if err := frappe.ForCompany(company).VerifyDocument(c.Request().Context(), c.Param(docParam), c.Param(employeeParam)); err != nil {
+				return tenantHTTPError(err, "document not found")
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireTenantRecord rejects the request unless the record of doctype in the given
+// path param belongs to the caller's company. It guards records whose Frappe method
+// takes no company, such as shift assignments and departments.
+func RequireTenantRecord(frappe *client.FrappeClient, doctype, param string) echo.MiddlewareFunc {
+	notFound := strings.ToLower(doctype) + " not found"
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			company, _ := c.Get("frappe_company").(string)
+			if _, err := frappe.ForCompany(company).RecordOwner(c.Request().Context(), doctype, c.Param(param)); err != nil {
+				return tenantHTTPError(err, notFound)
+			}
+			return next(c)
+		}
+	}
+}
+
+// tenantHTTPError maps cross-tenant access to 404 so record IDs from other companies are not revealed.
+func tenantHTTPError(err error, notFound string) *echo.HTTPError {
+	switch {
+	case errors.Is(err, client.ErrCrossTenant):
+		return echo.NewHTTPError(http.StatusNotFound, notFound)
+	case errors.Is(err, client.ErrCircuitOpen):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "HR service is temporarily unavailable")
+	case errors.Is(err, context.DeadlineExceeded):
+		return echo.NewHTTPError(http.StatusGatewayTimeout, "HR service did not respond in time")
+	}
+	return echo.NewHTTPError(http.StatusBadGateway, "failed to verify tenant access")
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix marks bearer tokens that are API keys rather than access JWTs.
+const APIKeyPrefix = "hrk_"
+
+// APIScopeResources are the API areas a key can be granted, each as read:<resource>
+// and write:<resource>.
+var APIScopeResources = []string{
+	"employees", "leave", "attendance", "shifts", "overtime",
+	"payroll", "tax", "benefits", "departments", "reports",
+}
+
+// ValidAPIScope reports whether s is read:<resource> or write:<resource> for a known resource.
+func ValidAPIScope(s string) bool {
+	action, resource, ok := strings.Cut(s, ":")
+	if !ok || (action != "read" && action != "write") {
+		return false
+	}
+	for _, r := range APIScopeResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKey struct {
+	ID         string     `db:"id" json:"id"`
+	CompanyID  string     `db:"company_id" json:"company_id"`
+	UserID     string     `db:"user_id" json:"user_id"`
+	Name       string     `db:"name" json:"name"`
+	KeyPrefix  string     `db:"key_prefix" json:"key_prefix"`
+	KeyHash    string     `db:"key_hash" json:"-"`
+	Scopes     string     `db:"scopes" json:"-"`
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	LastUsedIP *string    `db:"last_used_ip" json:"last_used_ip,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+// ScopeList returns the key's scopes.
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// HasScope reports whether the key was granted scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Usable reports whether the key can authenticate at now.
+func (k *APIKey) Usable(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"` // 0 means no expiry
+}
+
+type APIKeyResponse struct {
+	APIKey
+	ScopeNames []string `json:"scopes"`
+	Key        string   `json:"key,omitempty"` // only returned once, on creation
+}
@@ -1,10 +1,15 @@
 package model
 
+// AttendanceRecord is one day of hr_core_ext.api.attendance.get_attendance_summary.
+// Name, LateEntry and EarlyExit are only filled in by get_attendance_detail.
 type AttendanceRecord struct {
-	Date         string  `json:"date"`
-	Status       string  `json:"status"`
-	WorkingHours float64 `json:"working_hours"`
-	LeaveType    string  `json:"leave_type,omitempty"`
+	Name           string  `json:"name,omitempty"`
+	AttendanceDate string  `json:"attendance_date"`
+	Status         string  `json:"status"`
+	WorkingHours   float64 `json:"working_hours"`
+	LeaveType      *string `json:"leave_type"`
+	LateEntry      int     `json:"late_entry,omitempty"`
+	EarlyExit      int     `json:"early_exit,omitempty"`
 }
 
 type AttendanceSummary struct {
@@ -22,11 +27,51 @@ type AttendanceDetailSummary struct {
 	LateDays  int `json:"late_days"`
 }
 
+// EmployeeAttendance is an employee's attendance over a date range.
+type EmployeeAttendance struct {
+	Records []AttendanceRecord `json:"records"`
+	Summary AttendanceSummary  `json:"summary"`
+}
+
+// AttendanceDetail is EmployeeAttendance with the check-ins of the range.
+type AttendanceDetail struct {
+	Records  []AttendanceRecord      `json:"records"`
+	Checkins []AttendanceCheckin     `json:"checkins"`
+	Summary  AttendanceDetailSummary `json:"summary"`
+}
+
+// AttendanceCheckin is one check-in or check-out. Name is only returned when recording one.
 type AttendanceCheckin struct {
+	Name    string `json:"name,omitempty"`
 	Time    string `json:"time"`
 	LogType string `json:"log_type"`
 }
 
+// TodayCheckin is an employee's check-in status for today.
+type TodayCheckin struct {
+	Checkins     []AttendanceCheckin `json:"checkins"`
+	FirstIn      *string             `json:"first_in"`
+	LastOut      *string             `json:"last_out"`
+	WorkingHours float64             `json:"working_hours"`
+	IsCheckedIn  bool                `json:"is_checked_in"`
+}
+
+// CheckinDay sums up one day of an employee's check-ins.
+type CheckinDay struct {
+	Date         string              `json:"date"`
+	FirstIn      *string             `json:"first_in"`
+	LastOut      *string             `json:"last_out"`
+	WorkingHours float64             `json:"working_hours"`
+	CheckinCount int                 `json:"checkin_count"`
+	Checkins     []AttendanceCheckin `json:"checkins"`
+}
+
+// CheckinHistory is an employee's check-ins by day, most recent first.
+type CheckinHistory struct {
+	Days []CheckinDay `json:"days"`
+}
+
+// AttendanceRequest is one row of hr_core_ext.api.attendance.get_attendance_requests.
 type AttendanceRequest struct {
 	Name         string `json:"name"`
 	Employee     string `json:"employee"`
@@ -36,6 +81,22 @@ type AttendanceRequest struct {
 	Reason       string `json:"reason"`
 	Status       string `json:"status"`
 	HalfDay      int    `json:"half_day"`
+	DocStatus    int    `json:"docstatus"`
+}
+
+// EmployeeID is the employee the request belongs to.
+func (r AttendanceRequest) EmployeeID() string { return r.Employee }
+
+// AttendanceRequestResult is what Frappe returns after creating an attendance request.
+type AttendanceRequestResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// AttendanceDecision is what Frappe returns after approving or rejecting an attendance request.
+type AttendanceDecision struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
 }
 
 type CreateAttendanceRequestBody struct {
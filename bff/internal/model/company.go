@@ -1,16 +1,96 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Company struct {
-	ID                string    `db:"id" json:"id"`
-	Name              string    `db:"name" json:"name"`
-	Slug              string    `db:"slug" json:"slug"`
-	FrappeCompanyName string    `db:"frappe_company_name" json:"frappe_company_name,omitempty"`
-	Industry          string    `db:"industry" json:"industry,omitempty"`
-	Size              string    `db:"size" json:"size,omitempty"`
-	CreatedAt         time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+	ID                  string    `db:"id" json:"id"`
+	Name                string    `db:"name" json:"name"`
+	Slug                string    `db:"slug" json:"slug"`
+	FrappeCompanyName   string    `db:"frappe_company_name" json:"frappe_company_name,omitempty"`
+	Industry            string    `db:"industry" json:"industry,omitempty"`
+	Size                string    `db:"size" json:"size,omitempty"`
+	MFARequiredRoles    string    `db:"mfa_required_roles" json:"-"`
+	OIDCIssuer          string    `db:"oidc_issuer" json:"-"`
+	OIDCClientID        string    `db:"oidc_client_id" json:"-"`
+	OIDCClientSecret    string    `db:"oidc_client_secret" json:"-"` // sealed; see sso.SealClientSecret
+	OIDCAutoProvision   bool      `db:"oidc_auto_provision" json:"-"`
+	OIDCDefaultRole     UserRole  `db:"oidc_default_role" json:"-"`
+	SCIMTokenPrefix     string    `db:"scim_token_prefix" json:"-"`
+	SCIMTokenHash       string    `db:"scim_token_hash" json:"-"`
+	SCIMCreateEmployees bool      `db:"scim_create_employees" json:"-"`
+	AllowedEmailDomains string    `db:"allowed_email_domains" json:"-"`
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// OIDCEnabled reports whether single sign-on is configured for the company.
+func (c *Company) OIDCEnabled() bool {
+	return c.OIDCIssuer != "" && c.OIDCClientID != ""
+}
+
+// SCIMEnabled reports whether the company has a SCIM provisioning token.
+func (c *Company) SCIMEnabled() bool {
+	return c.SCIMTokenHash != ""
+}
+
+// MFARoles returns the roles this company requires to use MFA.
+func (c *Company) MFARoles() []UserRole {
+	roles := []UserRole{}
+	for _, r := range strings.Split(c.MFARequiredRoles, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			roles = append(roles, UserRole(r))
+		}
+	}
+	return roles
+}
+
+// RequiresMFA reports whether users with the given role must use MFA.
+func (c *Company) RequiresMFA(role UserRole) bool {
+	for _, r := range c.MFARoles() {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// EmailDomains returns the domains invites and SSO logins are restricted to. None means
+// any domain is allowed.
+func (c *Company) EmailDomains() []string {
+	domains := []string{}
+	for _, d := range strings.Split(c.AllowedEmailDomains, ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
+
+// AllowsEmail reports whether email's domain is on the allow-list. Subdomains must be
+// listed separately.
+func (c *Company) AllowsEmail(email string) bool {
+	domains := c.EmailDomains()
+	if len(domains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, d := range domains {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
+
+type EmailDomainPolicy struct {
+	AllowedDomains []string `json:"allowed_domains"`
 }
 
 type SignupRequest struct {
@@ -20,4 +100,6 @@ type SignupRequest struct {
 	FullName    string `json:"full_name"`
 	Industry    string `json:"industry,omitempty"`
 	Size        string `json:"size,omitempty"`
+	// Locale picks the verification email language, "th" or "en".
+	Locale string `json:"locale,omitempty"`
 }
@@ -0,0 +1,92 @@
+package model
+
+// Department is one row of hr_core_ext.api.department.get_departments.
+type Department struct {
+	Name             string `json:"name"`
+	DepartmentName   string `json:"department_name"`
+	ParentDepartment string `json:"parent_department"`
+	Company          string `json:"company"`
+	IsGroup          int    `json:"is_group"`
+	EmployeeCount    int    `json:"employee_count"`
+}
+
+type DepartmentList struct {
+	Departments []Department `json:"departments"`
+	Total       int          `json:"total"`
+}
+
+type DepartmentInfo struct {
+	Name             string `json:"name"`
+	DepartmentName   string `json:"department_name"`
+	ParentDepartment string `json:"parent_department"`
+	Company          string `json:"company"`
+	IsGroup          int    `json:"is_group"`
+}
+
+// DepartmentMember is an active employee of a department.
+type DepartmentMember struct {
+	EmployeeID   string `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	Designation  string `json:"designation"`
+	Image        string `json:"image"`
+	Status       string `json:"status"`
+}
+
+// DepartmentDetail is a department with its active employees.
+type DepartmentDetail struct {
+	Department DepartmentInfo     `json:"department"`
+	Employees  []DepartmentMember `json:"employees"`
+}
+
+type DepartmentRequest struct {
+	DepartmentName   string `json:"department_name"`
+	ParentDepartment string `json:"parent_department"`
+}
+
+// DepartmentResult is what Frappe returns after creating or updating a department.
+// ParentDepartment is only returned by an update.
+type DepartmentResult struct {
+	Name             string `json:"name"`
+	DepartmentName   string `json:"department_name"`
+	ParentDepartment string `json:"parent_department,omitempty"`
+}
+
+type DepartmentDeleted struct {
+	Message string `json:"message"`
+}
+
+// OrgNode is an employee in the org tree, with the employees who report to them.
+type OrgNode struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Designation string    `json:"designation"`
+	Department  string    `json:"department"`
+	Image       string    `json:"image"`
+	Children    []OrgNode `json:"children"`
+}
+
+type OrgTree struct {
+	Tree           []OrgNode `json:"tree"`
+	TotalEmployees int       `json:"total_employees"`
+}
+
+// OrgMember is an employee in a DepartmentGroup.
+type OrgMember struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Designation string `json:"designation"`
+	Image       string `json:"image"`
+}
+
+// DepartmentGroup is the active employees of one department; those without one are
+// grouped under "Unassigned".
+type DepartmentGroup struct {
+	Department string      `json:"department"`
+	Members    []OrgMember `json:"members"`
+	Count      int         `json:"count"`
+}
+
+type DepartmentTree struct {
+	Departments      []DepartmentGroup `json:"departments"`
+	TotalDepartments int               `json:"total_departments"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// DirectoryEmployee is an employee as the BFF keeps it in employees_cache, and as
+// hr_core_ext.api.employee.get_employee_directory returns it.
+type DirectoryEmployee struct {
+	EmployeeID     string `db:"employee_id" json:"employee_id"`
+	EmployeeName   string `db:"employee_name" json:"employee_name"`
+	FirstName      string `db:"first_name" json:"first_name,omitempty"`
+	LastName       string `db:"last_name" json:"last_name,omitempty"`
+	Department     string `db:"department" json:"department,omitempty"`
+	Designation    string `db:"designation" json:"designation,omitempty"`
+	Status         string `db:"status" json:"status"`
+	Branch         string `db:"branch" json:"branch,omitempty"`
+	EmploymentType string `db:"employment_type" json:"employment_type,omitempty"`
+	CompanyEmail   string `db:"company_email" json:"company_email,omitempty"`
+	ReportsTo      string `db:"reports_to" json:"reports_to,omitempty"`
+	Image          string `db:"image" json:"image,omitempty"`
+	DateOfJoining  string `db:"date_of_joining" json:"date_of_joining,omitempty"`
+	// Modified is Frappe's last-modified time of the employee, which orders the sync.
+	Modified string `db:"frappe_modified" json:"modified,omitempty"`
+
+	SyncedAt   time.Time  `db:"synced_at" json:"synced_at"`
+	StaleSince *time.Time `db:"stale_since" json:"-"`
+	// Stale is set on rows that may no longer match Frappe: a change to the employee
+	// could not be read back, or the row has not been confirmed for a while.
+	Stale bool `db:"-" json:"stale"`
+}
+
+// EmployeeSearch narrows a search of the directory. Query matches names, employee IDs
+// and company emails; empty fields match everything.
+type EmployeeSearch struct {
+	Query          string
+	Department     string
+	Designation    string
+	Status         string
+	Branch         string
+	EmploymentType string
+	// EmployeeID restricts the search to one employee, for callers who may only see themselves.
+	EmployeeID string
+}
+
+// EmployeeSyncState is how far a company's directory sync has got.
+type EmployeeSyncState struct {
+	CompanyID   string     `db:"company_id"`
+	Watermark   string     `db:"watermark"`
+	WatermarkID string     `db:"watermark_id"`
+	SyncedAt    *time.Time `db:"synced_at"`
+	FullSyncAt  *time.Time `db:"full_sync_at"`
+	LastError   string     `db:"last_error"`
+	NextSyncAt  time.Time  `db:"next_sync_at"`
+	LockedUntil *time.Time `db:"locked_until"`
+}
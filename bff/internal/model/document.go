@@ -9,3 +9,9 @@ type EmployeeDocument struct {
 	Modified string `json:"modified"`
 	Owner    string `json:"owner"`
 }
+
+// DocumentDeleted is what Frappe returns after deleting an employee's file.
+type DocumentDeleted struct {
+	Status string `json:"status"`
+	Name   string `json:"name"`
+}
@@ -1,6 +1,7 @@
 package model
 
 type Employee struct {
+	Name          string `json:"name,omitempty"`
 	EmployeeID    string `json:"employee_id"`
 	EmployeeName  string `json:"employee_name"`
 	Department    string `json:"department"`
@@ -11,6 +12,16 @@ type Employee struct {
 	Image         string `json:"image,omitempty"`
 }
 
+// EmployeeDetail is an employee as hr_core_ext.api.employee.get_employee returns them.
+type EmployeeDetail struct {
+	Employee
+	DateOfBirth   string `json:"date_of_birth,omitempty"`
+	Gender        string `json:"gender,omitempty"`
+	CellPhone     string `json:"cell_phone,omitempty"`
+	PersonalEmail string `json:"personal_email,omitempty"`
+	CompanyEmail  string `json:"company_email,omitempty"`
+}
+
 type EmployeeFull struct {
 	// Basic
 	EmployeeID   string `json:"employee_id"`
@@ -74,7 +85,54 @@ type EmployeeUpdate struct {
 	BloodGroup         *string `json:"blood_group,omitempty"`
 }
 
+// EmployeeContactUpdate holds the contact fields employees may change themselves.
+type EmployeeContactUpdate struct {
+	CellPhone           *string `json:"cell_phone,omitempty"`
+	PersonalEmail       *string `json:"personal_email,omitempty"`
+	CurrentAddress      *string `json:"current_address,omitempty"`
+	PermanentAddress    *string `json:"permanent_address,omitempty"`
+	EmergencyPhone      *string `json:"emergency_phone_number,omitempty"`
+	PersonToBeContacted *string `json:"person_to_be_contacted,omitempty"`
+	Relation            *string `json:"relation,omitempty"`
+}
+
+// EmployeeUpdated is what Frappe returns after changing an employee.
+type EmployeeUpdated struct {
+	EmployeeID string `json:"employee_id"`
+	Status     string `json:"status"`
+}
+
+// TimelineEvent is a change to an employee's profile ("field_change") or one of their
+// leave applications ("leave").
+type TimelineEvent struct {
+	Type        string `json:"type"`
+	Date        string `json:"date"`
+	Actor       string `json:"actor"`
+	Field       string `json:"field,omitempty"`
+	OldValue    string `json:"old_value,omitempty"`
+	NewValue    string `json:"new_value,omitempty"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status,omitempty"`
+}
+
 type ManagerValidation struct {
 	Valid  bool   `json:"valid"`
 	Reason string `json:"reason,omitempty"`
 }
+
+// ReportingLine holds the reports_to / leave_approver links of one employee.
+// LeaveApprover is a Frappe user, matched against the manager's UserID.
+type ReportingLine struct {
+	EmployeeID    string `json:"employee_id"`
+	ReportsTo     string `json:"reports_to,omitempty"`
+	LeaveApprover string `json:"leave_approver,omitempty"`
+	UserID        string `json:"user_id,omitempty"`
+}
+
+// RecordOwner identifies the employee and company a record belongs to.
+type RecordOwner struct {
+	Name      string `json:"name"`
+	Employee  string `json:"employee"`
+	Company   string `json:"company"`
+	DocStatus int    `json:"docstatus"`
+}
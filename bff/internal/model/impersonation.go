@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+type ImpersonateRequest struct {
+	Reason  string `json:"reason"`
+	Minutes int    `json:"minutes"`
+}
+
+// ImpersonationInfo is returned by /api/me while an admin is viewing the app as the
+// user, so the client can show a banner.
+type ImpersonationInfo struct {
+	ImpersonatorID    string    `json:"impersonator_id"`
+	ImpersonatorName  string    `json:"impersonator_name"`
+	ImpersonatorEmail string    `json:"impersonator_email"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
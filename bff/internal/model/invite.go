@@ -4,8 +4,10 @@ import "time"
 
 type Invite struct {
 	ID               string     `db:"id" json:"id"`
-	Token            string     `db:"token" json:"-"`
+	TokenHash        string     `db:"token_hash" json:"-"`
 	Email            string     `db:"email" json:"email"`
+	FullName         string     `db:"full_name" json:"full_name"`
+	Locale           string     `db:"locale" json:"locale"`
 	Role             UserRole   `db:"role" json:"role"`
 	CompanyID        string     `db:"company_id" json:"company_id"`
 	InvitedBy        string     `db:"invited_by" json:"invited_by"`
@@ -13,14 +15,39 @@ type Invite struct {
 	AcceptedAt       *time.Time `db:"accepted_at" json:"accepted_at,omitempty"`
 	ExpiresAt        time.Time  `db:"expires_at" json:"expires_at"`
 	Revoked          bool       `db:"revoked" json:"revoked"`
+	LastSentAt       *time.Time `db:"last_sent_at" json:"last_sent_at,omitempty"`
+	SendCount        int        `db:"send_count" json:"send_count"`
 	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
 }
 
+// Pending reports whether the invite can still be accepted, resent or extended.
+// Expired invites count: resending or extending them brings them back.
+func (i *Invite) Pending() bool {
+	return !i.Revoked && i.AcceptedAt == nil
+}
+
 type CreateInviteRequest struct {
 	Email            string   `json:"email"`
 	Role             UserRole `json:"role"`
 	FullName         string   `json:"full_name"`
 	FrappeEmployeeID string   `json:"frappe_employee_id,omitempty"`
+	// Locale picks the email language, "th" or "en"; defaults to "th".
+	Locale string `json:"locale,omitempty"`
+}
+
+type ExtendInviteRequest struct {
+	Days int `json:"days"`
+}
+
+// BulkInviteResult reports what happened to one CSV row. Row is the line number in the
+// file, counting the header as line 1.
+type BulkInviteResult struct {
+	Row       int    `json:"row"`
+	Email     string `json:"email"`
+	Status    string `json:"status"` // "invited" or "failed"
+	InviteID  string `json:"invite_id,omitempty"`
+	EmailSent bool   `json:"email_sent"`
+	Error     string `json:"error,omitempty"`
 }
 
 type AcceptInviteRequest struct {
@@ -1,15 +1,26 @@
 package model
 
+// LeaveApplication is one row of hr_core_ext.api.leave.get_leave_applications.
 type LeaveApplication struct {
-	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Employee     string  `json:"employee"`
 	EmployeeName string  `json:"employee_name"`
 	LeaveType    string  `json:"leave_type"`
 	FromDate     string  `json:"from_date"`
 	ToDate       string  `json:"to_date"`
-	TotalDays    float64 `json:"total_days"`
+	TotalDays    float64 `json:"total_leave_days"`
 	Status       string  `json:"status"`
 	PostingDate  string  `json:"posting_date"`
-	Description  string  `json:"description,omitempty"`
+	Description  string  `json:"description"`
+}
+
+// EmployeeID is the employee the application belongs to.
+func (a LeaveApplication) EmployeeID() string { return a.Employee }
+
+// LeaveResult is what Frappe returns after creating or changing a leave application.
+type LeaveResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
 }
 
 type LeaveBalance struct {
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+type LoginThrottle struct {
+	ThrottleKey   string     `db:"throttle_key" json:"-"`
+	Failures      int        `db:"failures" json:"failures"`
+	LockedUntil   *time.Time `db:"locked_until" json:"locked_until,omitempty"`
+	LastFailureAt time.Time  `db:"last_failure_at" json:"last_failure_at"`
+}
+
+// Locked reports whether attempts are currently blocked.
+func (t *LoginThrottle) Locked(now time.Time) bool {
+	return t.LockedUntil != nil && now.Before(*t.LockedUntil)
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Membership gives a user access to one company, with a role and employee link there.
+type Membership struct {
+	ID               string     `db:"id" json:"id"`
+	UserID           string     `db:"user_id" json:"-"`
+	CompanyID        string     `db:"company_id" json:"company_id"`
+	CompanyName      string     `db:"company_name" json:"company_name"`
+	Role             UserRole   `db:"role" json:"role"`
+	RoleID           *string    `db:"role_id" json:"role_id,omitempty"`
+	Status           UserStatus `db:"status" json:"status"`
+	FrappeEmployeeID *string    `db:"frappe_employee_id" json:"frappe_employee_id,omitempty"`
+	LastUsedAt       *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
+}
+
+type SwitchCompanyRequest struct {
+	CompanyID string `json:"company_id"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+type MFAChallenge struct {
+	ID        string `db:"id"`
+	TokenHash string `db:"token_hash"`
+	UserID    string `db:"user_id"`
+	// CompanyID is nil for challenges issued before it was recorded; those complete
+	// into the user's default membership.
+	CompanyID     *string    `db:"company_id"`
+	CompanyLocked bool       `db:"company_locked"`
+	Attempts      int        `db:"attempts"`
+	ExpiresAt     time.Time  `db:"expires_at"`
+	ConsumedAt    *time.Time `db:"consumed_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+// MFAChallengeResponse is returned by login instead of a token when a second factor is needed.
+type MFAChallengeResponse struct {
+	MFARequired        bool      `json:"mfa_required"`
+	EnrollmentRequired bool      `json:"enrollment_required"`
+	ChallengeToken     string    `json:"challenge_token"`
+	ExpiresAt          time.Time `json:"expires_at"`
+}
+
+type MFAVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+type MFAEnrollRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+}
+
+type MFASetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+type MFADisableRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnrolledAt             *time.Time `json:"enrolled_at,omitempty"`
+	Required               bool       `json:"required"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+type MFAPolicy struct {
+	RequiredRoles []UserRole `json:"required_roles"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// OIDCState is the server side of an in-flight SSO login, keyed by the hashed state param.
+type OIDCState struct {
+	StateHash    string    `db:"state_hash"`
+	CompanyID    string    `db:"company_id"`
+	CodeVerifier string    `db:"code_verifier"`
+	Nonce        string    `db:"nonce"`
+	RedirectTo   string    `db:"redirect_to"`
+	ExpiresAt    time.Time `db:"expires_at"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+type OIDCConfigRequest struct {
+	Issuer        string   `json:"issuer"`
+	ClientID      string   `json:"client_id"`
+	ClientSecret  *string  `json:"client_secret,omitempty"` // omitted keeps the stored secret
+	AutoProvision bool     `json:"auto_provision"`
+	DefaultRole   UserRole `json:"default_role"`
+}
+
+type OIDCConfigResponse struct {
+	Enabled         bool     `json:"enabled"`
+	Issuer          string   `json:"issuer"`
+	ClientID        string   `json:"client_id"`
+	ClientSecretSet bool     `json:"client_secret_set"`
+	AutoProvision   bool     `json:"auto_provision"`
+	DefaultRole     UserRole `json:"default_role"`
+	RedirectURL     string   `json:"redirect_url"`
+	LoginURL        string   `json:"login_url"`
+}
@@ -20,3 +20,42 @@ type OTConfigRequest struct {
 	StandardHoursPerDay *int    `json:"standard_hours_per_day,omitempty"`
 	StandardWorkingDays *int    `json:"standard_working_days,omitempty"`
 }
+
+// OTConfig is a company's OT pay multipliers and standard working time.
+type OTConfig struct {
+	WeekdayOTRate       float64 `json:"weekday_ot_rate"`
+	HolidayWorkMonthly  float64 `json:"holiday_work_monthly"`
+	HolidayWorkDaily    float64 `json:"holiday_work_daily"`
+	HolidayOTRate       float64 `json:"holiday_ot_rate"`
+	StandardHoursPerDay int     `json:"standard_hours_per_day"`
+	StandardWorkingDays int     `json:"standard_working_days"`
+}
+
+// OTRequest is one row of hr_core_ext.api.overtime.get_ot_requests. ApprovedBy and
+// Amount are not returned when a request is created.
+type OTRequest struct {
+	Name         string  `json:"name"`
+	Employee     string  `json:"employee"`
+	EmployeeName string  `json:"employee_name"`
+	OTDate       string  `json:"ot_date"`
+	OTType       string  `json:"ot_type"`
+	Hours        float64 `json:"hours"`
+	Reason       string  `json:"reason"`
+	Status       string  `json:"status"`
+	ApprovedBy   string  `json:"approved_by,omitempty"`
+	Amount       float64 `json:"amount"`
+}
+
+// EmployeeID is the employee the request belongs to.
+func (r OTRequest) EmployeeID() string { return r.Employee }
+
+// OTDecision is what Frappe returns after approving, rejecting or cancelling an OT
+// request. The pay fields are only filled in on approval.
+type OTDecision struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Amount     float64 `json:"amount,omitempty"`
+	HourlyRate float64 `json:"hourly_rate,omitempty"`
+	Multiplier float64 `json:"multiplier,omitempty"`
+	Hours      float64 `json:"hours,omitempty"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+type PasswordReset struct {
+	ID          string     `db:"id" json:"id"`
+	TokenHash   string     `db:"token_hash" json:"-"`
+	UserID      string     `db:"user_id" json:"user_id"`
+	CompanyID   string     `db:"company_id" json:"company_id"`
+	RequestedIP string     `db:"requested_ip" json:"requested_ip"`
+	UsedAt      *time.Time `db:"used_at" json:"used_at,omitempty"`
+	ExpiresAt   time.Time  `db:"expires_at" json:"expires_at"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
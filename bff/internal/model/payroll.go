@@ -7,9 +7,8 @@ type SetupEmployeePayrollRequest struct {
 }
 
 type ProcessPayrollRequest struct {
-	Month   int    `json:"month"`
-	Year    int    `json:"year"`
-	Company string `json:"company,omitempty"`
+	Month int `json:"month"`
+	Year  int `json:"year"`
 }
 
 type SubmitPayrollRequest struct {
@@ -21,3 +20,80 @@ type GenerateSalarySlipRequest struct {
 	Month int `json:"month"`
 	Year  int `json:"year"`
 }
+
+// SalarySlip is a salary slip as listed by hr_core_ext.api.payroll.get_salary_slips.
+// Company, Earnings and Deductions are only filled in by the slip detail.
+type SalarySlip struct {
+	Name           string         `json:"name"`
+	Employee       string         `json:"employee"`
+	EmployeeName   string         `json:"employee_name"`
+	Company        string         `json:"company,omitempty"`
+	StartDate      string         `json:"start_date"`
+	EndDate        string         `json:"end_date"`
+	PostingDate    *string        `json:"posting_date"`
+	GrossPay       float64        `json:"gross_pay"`
+	TotalDeduction float64        `json:"total_deduction"`
+	NetPay         float64        `json:"net_pay"`
+	Status         string         `json:"status"`
+	Earnings       []SalaryDetail `json:"earnings,omitempty"`
+	Deductions     []SalaryDetail `json:"deductions,omitempty"`
+}
+
+// SalaryDetail is one earning or deduction line of a salary slip.
+type SalaryDetail struct {
+	SalaryComponent string  `json:"salary_component"`
+	Amount          float64 `json:"amount"`
+	Formula         *string `json:"formula"`
+}
+
+// PayrollSetup is the salary structure assignment created for an employee.
+type PayrollSetup struct {
+	Name            string  `json:"name"`
+	Employee        string  `json:"employee"`
+	SalaryStructure string  `json:"salary_structure"`
+	Base            float64 `json:"base"`
+	FromDate        string  `json:"from_date"`
+}
+
+// PayrollSlipTotal is a salary slip created or submitted by a payroll run.
+// Submitting only reports the net pay.
+type PayrollSlipTotal struct {
+	Name           string  `json:"name"`
+	Employee       string  `json:"employee"`
+	EmployeeName   string  `json:"employee_name"`
+	GrossPay       float64 `json:"gross_pay,omitempty"`
+	TotalDeduction float64 `json:"total_deduction,omitempty"`
+	NetPay         float64 `json:"net_pay"`
+}
+
+// PayrollRun is the outcome of hr_core_ext.api.payroll.process_payroll.
+type PayrollRun struct {
+	Month          int                `json:"month"`
+	Year           int                `json:"year"`
+	CreatedCount   int                `json:"created_count"`
+	SkippedCount   int                `json:"skipped_count"`
+	ErrorCount     int                `json:"error_count"`
+	TotalGross     float64            `json:"total_gross"`
+	TotalDeduction float64            `json:"total_deduction"`
+	TotalNet       float64            `json:"total_net"`
+	Slips          []PayrollSlipTotal `json:"slips"`
+	Skipped        []BatchIssue       `json:"skipped"`
+	Errors         []BatchIssue       `json:"errors"`
+}
+
+// PayrollSubmission is the outcome of hr_core_ext.api.payroll.submit_payroll.
+type PayrollSubmission struct {
+	SubmittedCount int                `json:"submitted_count"`
+	ErrorCount     int                `json:"error_count"`
+	Submitted      []PayrollSlipTotal `json:"submitted"`
+	Errors         []BatchIssue       `json:"errors"`
+}
+
+// BatchIssue is an employee a batch run in Frappe skipped (Reason) or failed on (Error).
+type BatchIssue struct {
+	Name         string `json:"name,omitempty"`
+	Employee     string `json:"employee"`
+	EmployeeName string `json:"employee_name,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
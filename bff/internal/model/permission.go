@@ -0,0 +1,108 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// Permission names a capability checked by RequirePermission and the chat tools.
+// The catalog lives in the permissions table; these are the names code refers to.
+// A migration that adds a permission must also grant it to the built-in roles.
+type Permission string
+
+const (
+	PermEmployeeCreate          Permission = "employee.create"
+	PermEmployeeUpdateSensitive Permission = "employee.update_sensitive"
+	PermEmployeeViewSensitive   Permission = "employee.view_sensitive"
+	PermEmployeeViewAll         Permission = "employee.view_all"
+	PermLeaveApprove            Permission = "leave.approve"
+	PermAttendanceViewTeam      Permission = "attendance.view_team"
+	PermAttendanceApprove       Permission = "attendance.approve"
+	PermShiftApprove            Permission = "shift.approve"
+	PermShiftManage             Permission = "shift.manage"
+	PermOvertimeApprove         Permission = "overtime.approve"
+	PermOvertimeConfigure       Permission = "overtime.configure"
+	PermPayrollViewAll          Permission = "payroll.view_all"
+	PermPayrollProcess          Permission = "payroll.process"
+	PermTaxViewAll              Permission = "tax.view_all"
+	PermTaxReport               Permission = "tax.report"
+	PermBenefitsManage          Permission = "benefits.manage"
+	PermDepartmentManage        Permission = "department.manage"
+	PermReportView              Permission = "report.view"
+	PermUserView                Permission = "user.view"
+	PermUserManage              Permission = "user.manage"
+	PermUserInvite              Permission = "user.invite"
+	PermUserImpersonate         Permission = "user.impersonate"
+	PermUserResetMFA            Permission = "user.reset_mfa"
+	PermCompanySecurity         Permission = "company.security"
+	PermRoleManage              Permission = "role.manage"
+)
+
+// TeamPermissions act on other employees' records within the caller's row scope, which
+// the base role decides: the reporting chain for a manager, the company for HR and admin.
+// An employee base role reaches no one else, so roles built on it cannot hold them.
+var TeamPermissions = []Permission{
+	PermLeaveApprove, PermAttendanceViewTeam, PermAttendanceApprove, PermShiftApprove, PermOvertimeApprove,
+}
+
+// PermissionSet is the set of permissions a caller holds.
+type PermissionSet map[Permission]bool
+
+func NewPermissionSet(names []string) PermissionSet {
+	s := make(PermissionSet, len(names))
+	for _, n := range names {
+		s[Permission(n)] = true
+	}
+	return s
+}
+
+func (s PermissionSet) Has(p Permission) bool {
+	return s[p]
+}
+
+// List returns the permission names in sorted order.
+func (s PermissionSet) List() []string {
+	out := make([]string, 0, len(s))
+	for p := range s {
+		out = append(out, string(p))
+	}
+	sort.Strings(out)
+	return out
+}
+
+type PermissionInfo struct {
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description"`
+}
+
+// Role is a built-in role (CompanyID nil) or a company's custom role.
+type Role struct {
+	ID          string    `db:"id" json:"id"`
+	CompanyID   *string   `db:"company_id" json:"company_id,omitempty"`
+	Slug        string    `db:"slug" json:"slug"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	BaseRole    UserRole  `db:"base_role" json:"base_role"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	Permissions []string  `db:"-" json:"permissions"`
+}
+
+func (r *Role) BuiltIn() bool {
+	return r.CompanyID == nil
+}
+
+type RoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	BaseRole    UserRole `json:"base_role"`
+	Permissions []string `json:"permissions"`
+}
+
+// Access is what the permission middleware resolves for the caller on each request.
+type Access struct {
+	Role        UserRole
+	RoleID      string
+	RoleName    string
+	Permissions PermissionSet
+}
@@ -11,3 +11,42 @@ type EnrollPVDRequest struct {
 	EmployeeRate *float64 `json:"employee_rate,omitempty"`
 	EmployerRate *float64 `json:"employer_rate,omitempty"`
 }
+
+// PVDConfig is the range of contribution rates a company allows, in percent of base
+// salary, and the rates employees are enrolled at by default.
+type PVDConfig struct {
+	MinRate             float64 `json:"min_rate"`
+	MaxRate             float64 `json:"max_rate"`
+	DefaultEmployeeRate float64 `json:"default_employee_rate"`
+	DefaultEmployerRate float64 `json:"default_employer_rate"`
+}
+
+// EmployeePVD is an employee's provident fund enrollment. EmployeeName and
+// EnrollmentDate are only returned by get_employee_pvd and enroll_employee_pvd.
+type EmployeePVD struct {
+	Employee       string  `json:"employee"`
+	EmployeeName   string  `json:"employee_name,omitempty"`
+	Enrolled       bool    `json:"pvd_enrolled"`
+	EmployeeRate   float64 `json:"pvd_employee_rate"`
+	EmployerRate   float64 `json:"pvd_employer_rate"`
+	EnrollmentDate string  `json:"pvd_enrollment_date,omitempty"`
+}
+
+type PVDContribution struct {
+	Employee             string  `json:"employee"`
+	EmployeeName         string  `json:"employee_name"`
+	EmployeeRate         float64 `json:"employee_rate"`
+	EmployerRate         float64 `json:"employer_rate"`
+	EmployeeContribution float64 `json:"employee_contribution"`
+	EmployerContribution float64 `json:"employer_contribution"`
+}
+
+// PVDReport is a month's provident fund contributions of the enrolled employees.
+type PVDReport struct {
+	Month                     int               `json:"month"`
+	Year                      int               `json:"year"`
+	Employees                 []PVDContribution `json:"employees"`
+	TotalEmployeeContribution float64           `json:"total_employee_contribution"`
+	TotalEmployerContribution float64           `json:"total_employer_contribution"`
+	EnrolledCount             int               `json:"enrolled_count"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+type ProvisioningStep string
+
+const (
+	StepFrappeCompany  ProvisioningStep = "frappe_company"
+	StepFrappeEmployee ProvisioningStep = "frappe_employee"
+)
+
+// SignupSteps are the Frappe steps a self-service signup needs, in the order they run.
+var SignupSteps = []ProvisioningStep{StepFrappeCompany, StepFrappeEmployee}
+
+type JobStatus string
+
+const (
+	JobPending JobStatus = "pending"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	// JobFailed steps have used up their automatic retries and wait for an admin.
+	JobFailed JobStatus = "failed"
+)
+
+// ProvisioningJob is one Frappe step of setting up a company. UserID is set for steps
+// that act on a user, such as creating the admin's employee record.
+type ProvisioningJob struct {
+	ID          string           `db:"id" json:"id"`
+	CompanyID   string           `db:"company_id" json:"-"`
+	UserID      *string          `db:"user_id" json:"user_id,omitempty"`
+	Step        ProvisioningStep `db:"step" json:"step"`
+	Position    int              `db:"position" json:"-"`
+	Status      JobStatus        `db:"status" json:"status"`
+	Attempts    int              `db:"attempts" json:"attempts"`
+	LastError   string           `db:"last_error" json:"last_error,omitempty"`
+	NextRunAt   time.Time        `db:"next_run_at" json:"next_run_at"`
+	LockedUntil *time.Time       `db:"locked_until" json:"-"`
+	CompletedAt *time.Time       `db:"completed_at" json:"completed_at,omitempty"`
+	CreatedAt   time.Time        `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time        `db:"updated_at" json:"updated_at"`
+}
+
+const (
+	ProvisioningReady      = "ready"
+	ProvisioningInProgress = "in_progress"
+	ProvisioningFailed     = "failed"
+)
+
+// ProvisioningStatus summarises a company's steps: failed if any step needs an admin,
+// ready once all are done. Companies that predate provisioning jobs have none and are ready.
+type ProvisioningStatus struct {
+	Status string            `json:"status"`
+	Steps  []ProvisioningJob `json:"steps"`
+}
+
+func NewProvisioningStatus(jobs []ProvisioningJob) ProvisioningStatus {
+	status := ProvisioningReady
+	for _, j := range jobs {
+		if j.Status == JobFailed {
+			status = ProvisioningFailed
+			break
+		}
+		if j.Status != JobDone {
+			status = ProvisioningInProgress
+		}
+	}
+	if jobs == nil {
+		jobs = []ProvisioningJob{}
+	}
+	return ProvisioningStatus{Status: status, Steps: jobs}
+}
@@ -0,0 +1,95 @@
+package model
+
+type DepartmentHeadcount struct {
+	Department string `json:"department"`
+	Total      int    `json:"total"`
+	Active     int    `json:"active"`
+}
+
+// EmployeeSummaryReport is a company's headcount. TurnoverRate is the percentage of
+// active employees who left this year.
+type EmployeeSummaryReport struct {
+	TotalEmployees    int                   `json:"total_employees"`
+	ActiveEmployees   int                   `json:"active_employees"`
+	InactiveEmployees int                   `json:"inactive_employees"`
+	TurnoverRate      float64               `json:"turnover_rate"`
+	LeftThisYear      int                   `json:"left_this_year"`
+	Departments       []DepartmentHeadcount `json:"departments"`
+}
+
+// EmployeeAttendanceTotals counts an employee's attendance days of a month by status.
+type EmployeeAttendanceTotals struct {
+	Employee     string  `json:"employee"`
+	EmployeeName string  `json:"employee_name"`
+	Present      int     `json:"present"`
+	Absent       int     `json:"absent"`
+	HalfDay      int     `json:"half_day"`
+	OnLeave      int     `json:"on_leave"`
+	Late         int     `json:"late"`
+	EarlyExit    int     `json:"early_exit"`
+	TotalHours   float64 `json:"total_hours"`
+}
+
+type AttendanceReport struct {
+	Month        int                        `json:"month"`
+	Year         int                        `json:"year"`
+	WorkingDays  int                        `json:"working_days"`
+	Employees    []EmployeeAttendanceTotals `json:"employees"`
+	TotalRecords int                        `json:"total_records"`
+}
+
+type LeaveTypeTotals struct {
+	LeaveType string  `json:"leave_type"`
+	TotalDays float64 `json:"total_days"`
+	Count     int     `json:"count"`
+}
+
+type EmployeeLeaveTotals struct {
+	Employee     string  `json:"employee"`
+	EmployeeName string  `json:"employee_name"`
+	TotalDays    float64 `json:"total_days"`
+	Count        int     `json:"count"`
+}
+
+// LeaveReport is a year's approved leave, each breakdown by most days first.
+type LeaveReport struct {
+	Year              int                   `json:"year"`
+	ByType            []LeaveTypeTotals     `json:"by_type"`
+	ByEmployee        []EmployeeLeaveTotals `json:"by_employee"`
+	TotalApplications int                   `json:"total_applications"`
+	TotalDays         float64               `json:"total_days"`
+}
+
+// PayrollReport totals the draft and submitted salary slips of a month, or of a year
+// when Month is null.
+type PayrollReport struct {
+	Year           int     `json:"year"`
+	Month          *int    `json:"month"`
+	TotalSlips     int     `json:"total_slips"`
+	DraftCount     int     `json:"draft_count"`
+	SubmittedCount int     `json:"submitted_count"`
+	TotalGross     float64 `json:"total_gross"`
+	TotalDeduction float64 `json:"total_deduction"`
+	TotalNet       float64 `json:"total_net"`
+}
+
+type MonthlyTaxTotals struct {
+	Month         int     `json:"month"`
+	TotalIncome   float64 `json:"total_income"`
+	TotalTax      float64 `json:"total_tax"`
+	EmployeeCount int     `json:"employee_count"`
+}
+
+type TaxYearReport struct {
+	Year          int                `json:"year"`
+	MonthlyTotals []MonthlyTaxTotals `json:"monthly_totals"`
+	AnnualIncome  float64            `json:"annual_income"`
+	AnnualTax     float64            `json:"annual_tax"`
+}
+
+// ReportExport is a report as spreadsheet rows; each cell is a string or a number.
+type ReportExport struct {
+	Headers    []string `json:"headers"`
+	Rows       [][]any  `json:"rows"`
+	ReportType string   `json:"report_type"`
+}
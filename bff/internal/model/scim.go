@@ -0,0 +1,129 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// SCIMTokenPrefix marks SCIM provisioning tokens so they are recognisable in logs and vaults.
+const SCIMTokenPrefix = "hrs_"
+
+// SCIM 2.0 schema URNs (RFC 7643, RFC 7644).
+const (
+	SCIMSchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMSchemaEnterprise   = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	SCIMSchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMSchemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMSchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SCIMSchemaSPConfig     = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+type SCIMMeta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type SCIMEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMRef points at another resource: a group's member or a user's group.
+type SCIMRef struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type SCIMEnterpriseUser struct {
+	EmployeeNumber string `json:"employeeNumber,omitempty"`
+}
+
+type SCIMUser struct {
+	Schemas     []string            `json:"schemas"`
+	ID          string              `json:"id,omitempty"`
+	ExternalID  string              `json:"externalId,omitempty"`
+	UserName    string              `json:"userName"`
+	Name        *SCIMName           `json:"name,omitempty"`
+	DisplayName string              `json:"displayName,omitempty"`
+	Emails      []SCIMEmail         `json:"emails,omitempty"`
+	Active      *bool               `json:"active,omitempty"`
+	Groups      []SCIMRef           `json:"groups,omitempty"`
+	Enterprise  *SCIMEnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Meta        *SCIMMeta           `json:"meta,omitempty"`
+}
+
+type SCIMGroup struct {
+	Schemas     []string  `json:"schemas"`
+	ID          string    `json:"id,omitempty"`
+	DisplayName string    `json:"displayName"`
+	Members     []SCIMRef `json:"members,omitempty"`
+	Meta        *SCIMMeta `json:"meta,omitempty"`
+}
+
+type SCIMListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// SCIMPatchOperation is one operation of a PATCH request. Value is kept raw because
+// identity providers send it as an object, a list or a bare string depending on the path.
+type SCIMPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+// SCIMError is both the SCIM error response and the error SCIM handlers return to send it
+// (rendered by middleware.SCIMErrors).
+type SCIMError struct {
+	Schemas    []string `json:"schemas"`
+	Status     string   `json:"status"`
+	ScimType   string   `json:"scimType,omitempty"`
+	Detail     string   `json:"detail"`
+	HTTPStatus int      `json:"-"`
+}
+
+func NewSCIMError(status int, scimType, detail string) *SCIMError {
+	return &SCIMError{
+		Schemas:    []string{SCIMSchemaError},
+		Status:     strconv.Itoa(status),
+		ScimType:   scimType,
+		Detail:     detail,
+		HTTPStatus: status,
+	}
+}
+
+func (e *SCIMError) Error() string {
+	return e.Detail
+}
+
+type SCIMConfigRequest struct {
+	CreateEmployees bool `json:"create_employees"`
+}
+
+type SCIMConfigResponse struct {
+	Enabled         bool   `json:"enabled"`
+	TokenPrefix     string `json:"token_prefix,omitempty"`
+	CreateEmployees bool   `json:"create_employees"`
+	BaseURL         string `json:"base_url"`
+	Token           string `json:"token,omitempty"` // only returned once, when rotated
+}
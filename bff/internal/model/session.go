@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+type Session struct {
+	ID                string     `db:"id" json:"id"`
+	UserID            string     `db:"user_id" json:"-"`
+	CompanyID         string     `db:"company_id" json:"-"`
+	RefreshTokenHash  string     `db:"refresh_token_hash" json:"-"`
+	PreviousTokenHash *string    `db:"previous_token_hash" json:"-"`
+	UserAgent         string     `db:"user_agent" json:"user_agent"`
+	IPAddress         string     `db:"ip_address" json:"ip_address"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+	LastUsedAt        time.Time  `db:"last_used_at" json:"last_used_at"`
+	ExpiresAt         time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt         *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	ImpersonatorID    *string    `db:"impersonator_id" json:"impersonator_id,omitempty"`
+	ParentSessionID   *string    `db:"parent_session_id" json:"-"`
+	CompanyLocked     bool       `db:"company_locked" json:"-"`
+	Current           bool       `db:"-" json:"current"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -40,6 +40,110 @@ type ApproveShiftRequestBody struct {
 
 // ProcessAutoAttendanceRequest is the body for triggering auto-attendance.
 type ProcessAutoAttendanceRequest struct {
-	Date    string `json:"date,omitempty"`
-	Company string `json:"company,omitempty"`
+	Date string `json:"date,omitempty"`
+}
+
+// ShiftType is one row of hr_core_ext.api.shift.get_shift_types.
+type ShiftType struct {
+	Name                  string  `json:"name"`
+	StartTime             string  `json:"start_time"`
+	EndTime               string  `json:"end_time"`
+	HolidayList           string  `json:"holiday_list"`
+	LateEntryGracePeriod  int     `json:"late_entry_grace_period"`
+	EarlyExitGracePeriod  int     `json:"early_exit_grace_period"`
+	EnableAutoAttendance  int     `json:"enable_auto_attendance"`
+	HalfDayThresholdHours float64 `json:"working_hours_threshold_for_half_day"`
+	AbsentThresholdHours  float64 `json:"working_hours_threshold_for_absent"`
+}
+
+// ShiftTypeResult is what Frappe returns after creating or updating a shift type.
+// The grace periods are only returned by an update.
+type ShiftTypeResult struct {
+	Name                 string `json:"name"`
+	StartTime            string `json:"start_time"`
+	EndTime              string `json:"end_time"`
+	LateEntryGracePeriod *int   `json:"late_entry_grace_period,omitempty"`
+	EarlyExitGracePeriod *int   `json:"early_exit_grace_period,omitempty"`
+}
+
+// ShiftAssignment is a shift assigned to an employee. EndDate is nil for an open-ended
+// assignment; Company and Status are only set when listing.
+type ShiftAssignment struct {
+	Name         string  `json:"name"`
+	Employee     string  `json:"employee"`
+	EmployeeName string  `json:"employee_name"`
+	ShiftType    string  `json:"shift_type"`
+	StartDate    string  `json:"start_date"`
+	EndDate      *string `json:"end_date"`
+	Company      string  `json:"company,omitempty"`
+	Status       string  `json:"status,omitempty"`
+}
+
+// EmployeeID is the employee the assignment belongs to.
+func (a ShiftAssignment) EmployeeID() string { return a.Employee }
+
+// ShiftRequest is one row of hr_core_ext.api.shift.get_shift_requests.
+type ShiftRequest struct {
+	Name         string `json:"name"`
+	Employee     string `json:"employee"`
+	EmployeeName string `json:"employee_name"`
+	ShiftType    string `json:"shift_type"`
+	FromDate     string `json:"from_date"`
+	ToDate       string `json:"to_date"`
+	Status       string `json:"status"`
+	Approver     string `json:"approver"`
+}
+
+// EmployeeID is the employee who raised the request.
+func (r ShiftRequest) EmployeeID() string { return r.Employee }
+
+// ShiftRequestResult is what Frappe returns after creating or deciding a shift request.
+type ShiftRequestResult struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	ShiftType string `json:"shift_type,omitempty"`
+	Action    string `json:"action,omitempty"`
+}
+
+// ShiftAssignmentResult is what Frappe returns after cancelling a shift assignment.
+type ShiftAssignmentResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// CurrentShift is the shift an employee is assigned to today. When HasShift is false
+// every other field is empty.
+type CurrentShift struct {
+	HasShift             bool    `json:"has_shift"`
+	ShiftType            string  `json:"shift_type,omitempty"`
+	StartTime            string  `json:"start_time,omitempty"`
+	EndTime              string  `json:"end_time,omitempty"`
+	AssignmentStart      string  `json:"assignment_start,omitempty"`
+	AssignmentEnd        *string `json:"assignment_end,omitempty"`
+	AssignmentName       string  `json:"assignment_name,omitempty"`
+	LateEntryGracePeriod *int    `json:"late_entry_grace_period,omitempty"`
+	EarlyExitGracePeriod *int    `json:"early_exit_grace_period,omitempty"`
+}
+
+// AutoAttendanceRun is the outcome of hr_core_ext.api.shift.process_auto_attendance.
+type AutoAttendanceRun struct {
+	Date           string                 `json:"date"`
+	ProcessedCount int                    `json:"processed_count"`
+	SkippedCount   int                    `json:"skipped_count"`
+	ErrorCount     int                    `json:"error_count"`
+	LateCount      int                    `json:"late_count"`
+	EarlyExitCount int                    `json:"early_exit_count"`
+	Created        []AutoAttendanceRecord `json:"created"`
+	Skipped        []BatchIssue           `json:"skipped"`
+	Errors         []BatchIssue           `json:"errors"`
+}
+
+// AutoAttendanceRecord is an attendance record created by an auto-attendance run.
+type AutoAttendanceRecord struct {
+	Employee     string  `json:"employee"`
+	EmployeeName string  `json:"employee_name"`
+	Status       string  `json:"status"`
+	WorkingHours float64 `json:"working_hours"`
+	LateEntry    bool    `json:"late_entry"`
+	EarlyExit    bool    `json:"early_exit"`
 }
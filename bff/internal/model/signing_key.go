@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// SigningKey is a stored access-token signing key. PrivateKey is sealed (see
+// signing.Generate) and never leaves the BFF; verifiers get the public half from the JWKS.
+type SigningKey struct {
+	ID         string     `db:"id" json:"kid"`
+	Algorithm  string     `db:"algorithm" json:"alg"`
+	PrivateKey string     `db:"private_key" json:"-"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	RetiredAt  *time.Time `db:"retired_at" json:"retired_at,omitempty"`
+}
+
+// Verifies reports whether tokens signed with the key are still accepted at now.
+func (k *SigningKey) Verifies(now time.Time) bool {
+	return k.RetiredAt == nil || now.Before(*k.RetiredAt)
+}
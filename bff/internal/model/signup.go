@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// PendingSignup is a self-service signup waiting for its email address to be verified.
+type PendingSignup struct {
+	ID           string    `db:"id" json:"id"`
+	Email        string    `db:"email" json:"email"`
+	TokenHash    string    `db:"token_hash" json:"-"`
+	PasswordHash string    `db:"password_hash" json:"-"`
+	FullName     string    `db:"full_name" json:"full_name"`
+	CompanyName  string    `db:"company_name" json:"company_name"`
+	Industry     string    `db:"industry" json:"industry,omitempty"`
+	Size         string    `db:"size" json:"size,omitempty"`
+	Locale       string    `db:"locale" json:"locale"`
+	ExpiresAt    time.Time `db:"expires_at" json:"expires_at"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
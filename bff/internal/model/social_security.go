@@ -9,3 +9,34 @@ type SSOConfigRequest struct {
 type UpdateSSONumberRequest struct {
 	SSONumber string `json:"sso_number"`
 }
+
+// SSOConfig is the social security contribution rate, in percent of salary, and the
+// salary and contribution caps it applies to.
+type SSOConfig struct {
+	Rate            float64 `json:"rate"`
+	MaxSalary       float64 `json:"max_salary"`
+	MaxContribution float64 `json:"max_contribution"`
+}
+
+type SSOContribution struct {
+	Employee             string  `json:"employee"`
+	EmployeeName         string  `json:"employee_name"`
+	BaseSalary           float64 `json:"base_salary"`
+	EmployeeContribution float64 `json:"employee_contribution"`
+	EmployerContribution float64 `json:"employer_contribution"`
+}
+
+// SSOReport is a month's social security contributions of the active employees.
+type SSOReport struct {
+	Month                     int               `json:"month"`
+	Year                      int               `json:"year"`
+	Employees                 []SSOContribution `json:"employees"`
+	TotalEmployeeContribution float64           `json:"total_employee_contribution"`
+	TotalEmployerContribution float64           `json:"total_employer_contribution"`
+	EmployeeCount             int               `json:"employee_count"`
+}
+
+type EmployeeSSO struct {
+	Employee  string `json:"employee"`
+	SSONumber string `json:"sso_number"`
+}
@@ -10,3 +10,77 @@ type UpdateTaxDeductionsRequest struct {
 	HousingLoanInterest    *float64 `json:"housing_loan_interest,omitempty"`
 	DonationDeduction      *float64 `json:"donation_deduction,omitempty"`
 }
+
+// TaxSlabs is the income tax slab currently in effect.
+type TaxSlabs struct {
+	Name          string    `json:"name"`
+	EffectiveFrom string    `json:"effective_from,omitempty"`
+	Slabs         []TaxSlab `json:"slabs"`
+}
+
+// TaxSlab is one progressive band of a TaxSlabs.
+type TaxSlab struct {
+	FromAmount       float64 `json:"from_amount"`
+	ToAmount         float64 `json:"to_amount"`
+	PercentDeduction float64 `json:"percent_deduction"`
+}
+
+// TaxDeductions are the allowances and deductions an employee claims.
+type TaxDeductions struct {
+	Employee               string  `json:"employee"`
+	EmployeeName           string  `json:"employee_name"`
+	TaxID                  string  `json:"tax_id"`
+	PersonalAllowance      float64 `json:"personal_allowance"`
+	SpouseAllowance        float64 `json:"spouse_allowance"`
+	ChildrenCount          int     `json:"children_count"`
+	LifeInsurancePremium   float64 `json:"life_insurance_premium"`
+	HealthInsurancePremium float64 `json:"health_insurance_premium"`
+	HousingLoanInterest    float64 `json:"housing_loan_interest"`
+	DonationDeduction      float64 `json:"donation_deduction"`
+}
+
+// TaxSummary is an employee's withholding for each month of a year.
+type TaxSummary struct {
+	Employee       string       `json:"employee"`
+	Year           int          `json:"year"`
+	MonthlyData    []MonthlyTax `json:"monthly_data"`
+	TotalAnnualTax float64      `json:"total_annual_tax"`
+}
+
+// MonthlyTax is the withholding for one month.
+type MonthlyTax struct {
+	Month              int     `json:"month"`
+	MonthlyWithholding float64 `json:"monthly_withholding"`
+	TaxableIncome      float64 `json:"taxable_income"`
+}
+
+// PND1Report is the monthly withholding return (ภ.ง.ด.1) for all active employees.
+type PND1Report struct {
+	Month         int          `json:"month"`
+	Year          int          `json:"year"`
+	Records       []PND1Record `json:"records"`
+	TotalIncome   float64      `json:"total_income"`
+	TotalTax      float64      `json:"total_tax"`
+	EmployeeCount int          `json:"employee_count"`
+}
+
+// PND1Record is one employee line of a PND1Report.
+type PND1Record struct {
+	Employee      string  `json:"employee"`
+	EmployeeName  string  `json:"employee_name"`
+	TaxID         string  `json:"tax_id"`
+	MonthlyIncome float64 `json:"monthly_income"`
+	TaxWithheld   float64 `json:"tax_withheld"`
+}
+
+// WithholdingCert is the data for an employee's annual withholding certificate (50 ทวิ).
+type WithholdingCert struct {
+	Employee         string       `json:"employee"`
+	EmployeeName     string       `json:"employee_name"`
+	TaxID            string       `json:"tax_id"`
+	Year             int          `json:"year"`
+	TotalIncome      float64      `json:"total_income"`
+	TotalDeduction   float64      `json:"total_deduction"`
+	TotalTaxWithheld float64      `json:"total_tax_withheld"`
+	MonthlyBreakdown []MonthlyTax `json:"monthly_breakdown"`
+}
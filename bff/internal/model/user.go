@@ -20,28 +20,43 @@ const (
 	StatusDisabled  UserStatus = "disabled"
 )
 
+// User is a login identity seen through one of its memberships: CompanyID, Role, RoleID,
+// Status, FrappeEmployeeID, OIDCSubject and SCIMExternalID belong to that membership (see
+// UserRepository). Status is the membership's status unless the account itself is not yet active.
 type User struct {
 	ID               string     `db:"id" json:"id"`
 	Email            string     `db:"email" json:"email"`
 	PasswordHash     string     `db:"password_hash" json:"-"`
 	FullName         string     `db:"full_name" json:"full_name"`
 	Role             UserRole   `db:"role" json:"role"`
+	RoleID           *string    `db:"role_id" json:"role_id,omitempty"`
 	Status           UserStatus `db:"status" json:"status"`
 	CompanyID        string     `db:"company_id" json:"company_id"`
 	FrappeEmployeeID *string    `db:"frappe_employee_id" json:"frappe_employee_id,omitempty"`
 	LastLoginAt      *time.Time `db:"last_login_at" json:"last_login_at,omitempty"`
+	MFASecret        *string    `db:"mfa_secret" json:"-"`
+	MFAEnabled       bool       `db:"mfa_enabled" json:"mfa_enabled"`
+	MFAEnrolledAt    *time.Time `db:"mfa_enrolled_at" json:"mfa_enrolled_at,omitempty"`
+	MFALastStep      int64      `db:"mfa_last_step" json:"-"`
+	OIDCSubject      *string    `db:"oidc_subject" json:"-"`
+	SCIMExternalID   *string    `db:"scim_external_id" json:"-"`
 	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt        time.Time  `db:"updated_at" json:"updated_at"`
 }
 
 type UserInfo struct {
-	ID               string   `json:"id"`
-	Email            string   `json:"email"`
-	FullName         string   `json:"full_name"`
-	Role             UserRole `json:"role"`
-	CompanyID        string   `json:"company_id"`
-	CompanyName      string   `json:"company_name"`
-	FrappeEmployeeID string   `json:"frappe_employee_id,omitempty"`
+	ID               string             `json:"id"`
+	Email            string             `json:"email"`
+	FullName         string             `json:"full_name"`
+	Role             UserRole           `json:"role"`
+	CompanyID        string             `json:"company_id"`
+	CompanyName      string             `json:"company_name"`
+	FrappeEmployeeID string             `json:"frappe_employee_id,omitempty"`
+	MFAEnabled       bool               `json:"mfa_enabled"`
+	RoleName         string             `json:"role_name,omitempty"`
+	Permissions      []string           `json:"permissions,omitempty"`
+	Impersonation    *ImpersonationInfo `json:"impersonation,omitempty"`
+	Memberships      []Membership       `json:"memberships,omitempty"`
 }
 
 type LoginRequest struct {
@@ -50,6 +65,8 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string   `json:"token"`
-	User  UserInfo `json:"user"`
+	Token         string   `json:"token"`
+	RefreshToken  string   `json:"refresh_token,omitempty"`
+	User          UserInfo `json:"user"`
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
 }
@@ -0,0 +1,203 @@
+// Package provisioning runs the Frappe side of setting up a company. The Postgres side
+// is committed first, in one transaction with a provisioning job per Frappe step (see
+// ProvisioningRepository.CreateTenant); the Runner then works through those jobs.
+//
+// Steps are idempotent: each first looks in Frappe for what an interrupted attempt may
+// already have created, so retrying a step never creates a second company or employee.
+package provisioning
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"hr-platform/bff/internal/client"
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/repository"
+)
+
+const (
+	// PollInterval is how often Watch looks for steps that are due.
+	PollInterval = 30 * time.Second
+	// MaxAttempts is how many times a step runs before it is marked failed and waits for
+	// an admin to retry it.
+	MaxAttempts = 6
+	// lease bounds how long a step may run before another instance may take it over.
+	lease = 5 * time.Minute
+
+	firstRetryDelay = 30 * time.Second
+	maxRetryDelay   = 30 * time.Minute
+	pollBatch       = 20
+)
+
+type Runner struct {
+	jobs      *repository.ProvisioningRepository
+	companies *repository.CompanyRepository
+	users     *repository.UserRepository
+	frappe    *client.FrappeClient
+}
+
+func NewRunner(
+	jobs *repository.ProvisioningRepository,
+	companies *repository.CompanyRepository,
+	users *repository.UserRepository,
+	frappe *client.FrappeClient,
+) *Runner {
+	return &Runner{jobs: jobs, companies: companies, users: users, frappe: frappe}
+}
+
+// Run runs the company's due steps in order. It stops at the first step that fails or is
+// not due, since later steps depend on earlier ones, and returns the failing step's error.
+func (r *Runner) Run(ctx context.Context, companyID string) error {
+	jobs, err := r.jobs.ListByCompany(ctx, companyID)
+	if err != nil {
+		return err
+	}
+	for _, j := range jobs {
+		if j.Status == model.JobDone {
+			continue
+		}
+		job, err := r.jobs.Claim(ctx, j.ID, lease)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := r.runStep(ctx, job); err != nil {
+			var next *time.Time
+			if job.Attempts < MaxAttempts {
+				t := time.Now().Add(retryDelay(job.Attempts))
+				next = &t
+			}
+			if ferr := r.jobs.Fail(ctx, job.ID, err.Error(), next); ferr != nil {
+				log.Printf("provisioning: recording failure of %s for company %s: %v", job.Step, companyID, ferr)
+			}
+			return fmt.Errorf("%s: %w", job.Step, err)
+		}
+		if err := r.jobs.Complete(ctx, job.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Watch runs due steps every PollInterval until ctx is done.
+func (r *Runner) Watch(ctx context.Context) {
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ids, err := r.jobs.DueCompanies(ctx, pollBatch)
+			if err != nil {
+				log.Printf("provisioning: listing due jobs failed: %v", err)
+				continue
+			}
+			for _, id := range ids {
+				if err := r.Run(ctx, id); err != nil {
+					log.Printf("provisioning: company %s: %v", id, err)
+				}
+			}
+		}
+	}
+}
+
+func (r *Runner) runStep(ctx context.Context, job *model.ProvisioningJob) error {
+	switch job.Step {
+	case model.StepFrappeCompany:
+		return r.createCompany(ctx, job.CompanyID)
+	case model.StepFrappeEmployee:
+		if job.UserID == nil {
+			return errors.New("job has no user")
+		}
+		return r.createEmployee(ctx, job.CompanyID, *job.UserID)
+	}
+	return fmt.Errorf("unknown step %q", job.Step)
+}
+
+func (r *Runner) createCompany(ctx context.Context, companyID string) error {
+	company, err := r.companies.GetByID(ctx, companyID)
+	if err != nil {
+		return err
+	}
+	if company.FrappeCompanyName != "" {
+		return nil
+	}
+
+	name, err := r.frappe.FindCompany(ctx, company.Name)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		if name, err = r.frappe.CreateCompany(ctx, company.Name, abbreviate(company.Name), "Thailand"); err != nil {
+			return err
+		}
+	}
+	company.FrappeCompanyName = name
+	return r.companies.Update(ctx, company)
+}
+
+func (r *Runner) createEmployee(ctx context.Context, companyID, userID string) error {
+	company, err := r.companies.GetByID(ctx, companyID)
+	if err != nil {
+		return err
+	}
+	if company.FrappeCompanyName == "" {
+		return errors.New("company has no Frappe company yet")
+	}
+	user, err := r.users.GetInCompany(ctx, userID, companyID)
+	if err != nil {
+		return err
+	}
+	if user.FrappeEmployeeID != nil {
+		return nil
+	}
+
+	employeeID, err := r.frappe.FindEmployee(ctx, user.FullName, company.FrappeCompanyName)
+	if err != nil {
+		return err
+	}
+	if employeeID == "" {
+		if employeeID, err = r.frappe.CreateEmployee(ctx, user.FullName, company.FrappeCompanyName, "", ""); err != nil {
+			return err
+		}
+		if employeeID == "" {
+			return errors.New("frappe returned no employee id")
+		}
+	}
+	return r.users.LinkEmployee(ctx, userID, companyID, employeeID)
+}
+
+// retryDelay doubles from firstRetryDelay after each attempt, up to maxRetryDelay.
+func retryDelay(attempts int) time.Duration {
+	d := firstRetryDelay
+	for i := 1; i < attempts && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxRetryDelay)
+}
+
+func abbreviate(name string) string {
+	words := strings.Fields(name)
+	abbr := ""
+	for _, w := range words {
+		if len(w) > 0 {
+			abbr += strings.ToUpper(w[:1])
+		}
+	}
+	if len(abbr) < 2 {
+		abbr = strings.ToUpper(name)
+		if len(abbr) > 4 {
+			abbr = abbr[:4]
+		}
+	}
+	return abbr
+}
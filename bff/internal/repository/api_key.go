@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"hr-platform/bff/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type APIKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, k *model.APIKey) error {
+	query := `INSERT INTO api_keys (company_id, user_id, name, key_prefix, key_hash, scopes, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7)
+	          RETURNING id, created_at`
+	return r.db.QueryRowContext(ctx, query,
+		k.CompanyID, k.UserID, k.Name, k.KeyPrefix, k.KeyHash, k.Scopes, k.ExpiresAt,
+	).Scan(&k.ID, &k.CreatedAt)
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	var k model.APIKey
+	err := r.db.GetContext(ctx, &k, `SELECT * FROM api_keys WHERE key_hash = $1`, keyHash)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *APIKeyRepository) GetByID(ctx context.Context, id string) (*model.APIKey, error) {
+	var k model.APIKey
+	err := r.db.GetContext(ctx, &k, `SELECT * FROM api_keys WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *APIKeyRepository) ListByCompany(ctx context.Context, companyID string) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.db.SelectContext(ctx, &keys,
+		`SELECT * FROM api_keys WHERE company_id = $1 ORDER BY created_at DESC`, companyID)
+	return keys, err
+}
+
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID string) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.db.SelectContext(ctx, &keys,
+		`SELECT * FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	return keys, err
+}
+
+// Touch records the key's most recent use.
+func (r *APIKeyRepository) Touch(ctx context.Context, id, ip string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE api_keys SET last_used_at = NOW(), last_used_ip = $1 WHERE id = $2`, ip, id)
+	return err
+}
+
+// Revoke returns sql.ErrNoRows if the key is already revoked.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -26,8 +26,32 @@ func (r *AuditRepository) Log(ctx context.Context, actorID, companyID, action, t
 	}
 
 	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO audit_log (actor_id, company_id, action, target_type, target_id, details)
-		 VALUES ($1, $2, $3, $4, $5, $6)`,
-		actorID, companyID, action, targetType, targetID, detailsJSON)
+		`INSERT INTO audit_log (actor_id, company_id, action, target_type, target_id, details, impersonator_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		nullIfEmpty(actorID), nullIfEmpty(companyID), action, targetType, targetID, detailsJSON,
+		nullIfEmpty(ImpersonatorFrom(ctx)))
 	return err
 }
+
+type impersonatorKey struct{}
+
+// WithImpersonator marks ctx as belonging to a request made by impersonatorID while
+// impersonating another user. Log records it on every entry written with that context.
+func WithImpersonator(ctx context.Context, impersonatorID string) context.Context {
+	return context.WithValue(ctx, impersonatorKey{}, impersonatorID)
+}
+
+// ImpersonatorFrom returns the impersonator set by WithImpersonator, or "".
+func ImpersonatorFrom(ctx context.Context) string {
+	id, _ := ctx.Value(impersonatorKey{}).(string)
+	return id
+}
+
+// nullIfEmpty lets callers pass "" for events with no known actor or company,
+// such as a failed login for an unknown email.
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -52,6 +52,16 @@ func (r *CompanyRepository) GetByName(ctx context.Context, name string) (*model.
 	return &c, nil
 }
 
+// GetByFrappeCompanyName returns the company linked to a Frappe company.
+func (r *CompanyRepository) GetByFrappeCompanyName(ctx context.Context, name string) (*model.Company, error) {
+	var c model.Company
+	err := r.db.GetContext(ctx, &c, `SELECT * FROM companies WHERE frappe_company_name = $1`, name)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
 func (r *CompanyRepository) Update(ctx context.Context, c *model.Company) error {
 	query := `UPDATE companies SET name = $1, slug = $2, frappe_company_name = $3,
 	          industry = $4, size = $5, updated_at = NOW()
@@ -60,3 +70,42 @@ func (r *CompanyRepository) Update(ctx context.Context, c *model.Company) error
 		c.Name, c.Slug, c.FrappeCompanyName, c.Industry, c.Size, c.ID)
 	return err
 }
+
+func (r *CompanyRepository) UpdateMFAPolicy(ctx context.Context, id, requiredRoles string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE companies SET mfa_required_roles = $1, updated_at = NOW() WHERE id = $2`, requiredRoles, id)
+	return err
+}
+
+// UpdateEmailDomains sets the comma-separated email domain allow-list.
+func (r *CompanyRepository) UpdateEmailDomains(ctx context.Context, id, domains string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE companies SET allowed_email_domains = $1, updated_at = NOW() WHERE id = $2`, domains, id)
+	return err
+}
+
+func (r *CompanyRepository) UpdateOIDC(ctx context.Context, c *model.Company) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE companies SET oidc_issuer = $1, oidc_client_id = $2, oidc_client_secret = $3,
+		 oidc_auto_provision = $4, oidc_default_role = $5, updated_at = NOW()
+		 WHERE id = $6`,
+		c.OIDCIssuer, c.OIDCClientID, c.OIDCClientSecret, c.OIDCAutoProvision, c.OIDCDefaultRole, c.ID)
+	return err
+}
+
+func (r *CompanyRepository) GetBySCIMTokenHash(ctx context.Context, hash string) (*model.Company, error) {
+	var c model.Company
+	err := r.db.GetContext(ctx, &c, `SELECT * FROM companies WHERE scim_token_hash = $1 AND scim_token_hash <> ''`, hash)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *CompanyRepository) UpdateSCIM(ctx context.Context, c *model.Company) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE companies SET scim_token_prefix = $1, scim_token_hash = $2, scim_create_employees = $3,
+		 updated_at = NOW() WHERE id = $4`,
+		c.SCIMTokenPrefix, c.SCIMTokenHash, c.SCIMCreateEmployees, c.ID)
+	return err
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"hr-platform/bff/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// EmployeeCacheSort maps the fields Search can sort by to their columns.
+var EmployeeCacheSort = map[string]string{
+	"employee_id":     "employee_id",
+	"employee_name":   "employee_name",
+	"department":      "department",
+	"designation":     "designation",
+	"status":          "status",
+	"branch":          "branch",
+	"employment_type": "employment_type",
+	// Qualified, since the selected date_of_joining is its text form
+	"date_of_joining": "employees_cache.date_of_joining",
+}
+
+const employeeCacheColumns = `employee_id, employee_name, first_name, last_name, department, designation,
+	status, branch, employment_type, company_email, reports_to, image,
+	COALESCE(to_char(date_of_joining, 'YYYY-MM-DD'), '') AS date_of_joining,
+	frappe_modified, synced_at, stale_since`
+
+type EmployeeCacheRepository struct {
+	db *sqlx.DB
+}
+
+func NewEmployeeCacheRepository(db *sqlx.DB) *EmployeeCacheRepository {
+	return &EmployeeCacheRepository{db: db}
+}
+
+// Upsert stores employees read from Frappe as confirmed at syncedAt. A row that already
+// holds a later Frappe version, written by a webhook while a sync was reading, is kept.
+func (r *EmployeeCacheRepository) Upsert(ctx context.Context, companyID string, employees []model.DirectoryEmployee, syncedAt time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, e := range employees {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO employees_cache (company_id, employee_id, employee_name, first_name, last_name,
+			     department, designation, status, branch, employment_type, company_email, reports_to, image,
+			     date_of_joining, frappe_modified, synced_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14::date, $15, $16)
+			 ON CONFLICT (company_id, employee_id) DO UPDATE SET
+			     employee_name = EXCLUDED.employee_name, first_name = EXCLUDED.first_name,
+			     last_name = EXCLUDED.last_name, department = EXCLUDED.department,
+			     designation = EXCLUDED.designation, status = EXCLUDED.status, branch = EXCLUDED.branch,
+			     employment_type = EXCLUDED.employment_type, company_email = EXCLUDED.company_email,
+			     reports_to = EXCLUDED.reports_to, image = EXCLUDED.image,
+			     date_of_joining = EXCLUDED.date_of_joining, frappe_modified = EXCLUDED.frappe_modified,
+			     synced_at = EXCLUDED.synced_at, stale_since = NULL
+			 WHERE employees_cache.frappe_modified <= EXCLUDED.frappe_modified`,
+			companyID, e.EmployeeID, e.EmployeeName, e.FirstName, e.LastName,
+			e.Department, e.Designation, e.Status, e.Branch, e.EmploymentType, e.CompanyEmail, e.ReportsTo, e.Image,
+			nullIfEmpty(e.DateOfJoining), e.Modified, syncedAt); err != nil {
+			return fmt.Errorf("storing employee %s: %w", e.EmployeeID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// MarkStale flags an employee whose change Frappe reported but could not be read back.
+func (r *EmployeeCacheRepository) MarkStale(ctx context.Context, companyID, employeeID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE employees_cache SET stale_since = COALESCE(stale_since, NOW())
+		 WHERE company_id = $1 AND employee_id = $2`, companyID, employeeID)
+	return err
+}
+
+// StaleIDs returns up to limit employees flagged by MarkStale, oldest first.
+func (r *EmployeeCacheRepository) StaleIDs(ctx context.Context, companyID string, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.SelectContext(ctx, &ids,
+		`SELECT employee_id FROM employees_cache
+		 WHERE company_id = $1 AND stale_since IS NOT NULL
+		 ORDER BY stale_since LIMIT $2`, companyID, limit)
+	return ids, err
+}
+
+func (r *EmployeeCacheRepository) Delete(ctx context.Context, companyID, employeeID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM employees_cache WHERE company_id = $1 AND employee_id = $2`, companyID, employeeID)
+	return err
+}
+
+// Confirm marks every row that is not stale as current at syncedAt. It follows a sync
+// that read all changes up to syncedAt, which leaves the other rows as they were.
+func (r *EmployeeCacheRepository) Confirm(ctx context.Context, companyID string, syncedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE employees_cache SET synced_at = $2
+		 WHERE company_id = $1 AND stale_since IS NULL AND synced_at < $2`, companyID, syncedAt)
+	return err
+}
+
+// DeleteUnseen drops rows a full sync that started at syncedAt did not read, which
+// are employees deleted in Frappe.
+func (r *EmployeeCacheRepository) DeleteUnseen(ctx context.Context, companyID string, syncedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM employees_cache WHERE company_id = $1 AND synced_at < $2`, companyID, syncedAt)
+	return err
+}
+
+// Search returns one page of a company's employees and the number of matches. sort is
+// a key of EmployeeCacheSort, prefixed with "-" for descending; without one, rows are
+// ordered by how well they match the query, then by name.
+func (r *EmployeeCacheRepository) Search(ctx context.Context, companyID string, q model.EmployeeSearch, limit, offset int, sort string) ([]model.DirectoryEmployee, int, error) {
+	where := []string{"company_id = $1"}
+	args := []any{companyID}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	for _, f := range []struct{ col, v string }{
+		{"department", q.Department},
+		{"designation", q.Designation},
+		{"status", q.Status},
+		{"branch", q.Branch},
+		{"employment_type", q.EmploymentType},
+		{"employee_id", q.EmployeeID},
+	} {
+		if f.v != "" {
+			where = append(where, f.col+" = "+arg(f.v))
+		}
+	}
+
+	var rank []string
+	if text := strings.ToLower(strings.TrimSpace(q.Query)); text != "" {
+		// Substring match finds Thai names, which the text search parser does not split
+		// into words; the prefix query finds Latin words in any order.
+		match := "search_text LIKE " + arg("%"+escapeLike(text)+"%")
+		if tsq := prefixQuery(text); tsq != "" {
+			p := arg(tsq)
+			match = "(search_vector @@ to_tsquery('simple', " + p + ") OR " + match + ")"
+			rank = append(rank, "ts_rank(search_vector, to_tsquery('simple', "+p+")) DESC")
+		}
+		where = append(where, match)
+		rank = append(rank, "similarity(search_text, "+arg(text)+") DESC")
+	}
+	cond := strings.Join(where, " AND ")
+
+	order := append(rank, "employee_name ASC", "employee_id ASC")
+	if sort != "" {
+		field, dir := sort, "ASC"
+		if rest, ok := strings.CutPrefix(sort, "-"); ok {
+			field, dir = rest, "DESC"
+		}
+		col, ok := EmployeeCacheSort[field]
+		if !ok {
+			return nil, 0, fmt.Errorf("cannot sort employees by %q", field)
+		}
+		order = []string{col + " " + dir + " NULLS LAST", "employee_id " + dir}
+	}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM employees_cache WHERE `+cond, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + employeeCacheColumns + ` FROM employees_cache
+		 WHERE ` + cond + `
+		 ORDER BY ` + strings.Join(order, ", ") + `
+		 LIMIT ` + arg(limit) + ` OFFSET ` + arg(offset)
+	rows := []model.DirectoryEmployee{}
+	err := r.db.SelectContext(ctx, &rows, query, args...)
+	return rows, total, err
+}
+
+// DueSyncs returns companies whose directory is due a sync, including ones never synced.
+func (r *EmployeeCacheRepository) DueSyncs(ctx context.Context, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.SelectContext(ctx, &ids,
+		`SELECT c.id FROM companies c
+		 LEFT JOIN employees_cache_sync s ON s.company_id = c.id
+		 WHERE c.frappe_company_name <> ''
+		   AND (s.company_id IS NULL
+		        OR (s.next_sync_at <= NOW() AND (s.locked_until IS NULL OR s.locked_until < NOW())))
+		 ORDER BY s.next_sync_at NULLS FIRST
+		 LIMIT $1`, limit)
+	return ids, err
+}
+
+// ClaimSync locks a company's due sync for lease. It returns sql.ErrNoRows if the sync is
+// not due or another instance holds it.
+func (r *EmployeeCacheRepository) ClaimSync(ctx context.Context, companyID string, lease time.Duration) (*model.EmployeeSyncState, error) {
+	var s model.EmployeeSyncState
+	err := r.db.GetContext(ctx, &s,
+		`INSERT INTO employees_cache_sync (company_id, locked_until) VALUES ($1, $2)
+		 ON CONFLICT (company_id) DO UPDATE SET locked_until = EXCLUDED.locked_until
+		 WHERE employees_cache_sync.next_sync_at <= NOW()
+		   AND (employees_cache_sync.locked_until IS NULL OR employees_cache_sync.locked_until < NOW())
+		 RETURNING *`,
+		companyID, time.Now().Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SaveSync records the outcome of a claimed sync and releases it.
+func (r *EmployeeCacheRepository) SaveSync(ctx context.Context, s *model.EmployeeSyncState) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE employees_cache_sync
+		 SET watermark = $2, watermark_id = $3, synced_at = $4, full_sync_at = $5,
+		     last_error = $6, next_sync_at = $7, locked_until = NULL
+		 WHERE company_id = $1`,
+		s.CompanyID, s.Watermark, s.WatermarkID, s.SyncedAt, s.FullSyncAt, s.LastError, s.NextSyncAt)
+	return err
+}
+
+// escapeLike quotes LIKE's wildcards and its escape character.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// prefixQuery turns "som ja" into the tsquery "som:* & ja:*". Characters that are not
+// letters, digits or combining marks are dropped so the result always parses.
+func prefixQuery(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		word = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+				return r
+			}
+			return -1
+		}, word)
+		if word != "" {
+			terms = append(terms, word+":*")
+		}
+	}
+	return strings.Join(terms, " & ")
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"hr-platform/bff/internal/model"
 
@@ -17,17 +18,41 @@ func NewInviteRepository(db *sqlx.DB) *InviteRepository {
 }
 
 func (r *InviteRepository) Create(ctx context.Context, inv *model.Invite) error {
-	query := `INSERT INTO invites (token, email, role, company_id, invited_by, frappe_employee_id, expires_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7)
+	query := `INSERT INTO invites (token_hash, email, full_name, locale, role, company_id, invited_by, frappe_employee_id, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	          RETURNING id, created_at`
 	return r.db.QueryRowContext(ctx, query,
-		inv.Token, inv.Email, inv.Role, inv.CompanyID, inv.InvitedBy, inv.FrappeEmployeeID, inv.ExpiresAt,
+		inv.TokenHash, inv.Email, inv.FullName, inv.Locale, inv.Role, inv.CompanyID, inv.InvitedBy, inv.FrappeEmployeeID, inv.ExpiresAt,
 	).Scan(&inv.ID, &inv.CreatedAt)
 }
 
-func (r *InviteRepository) GetByToken(ctx context.Context, token string) (*model.Invite, error) {
+func (r *InviteRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*model.Invite, error) {
 	var inv model.Invite
-	err := r.db.GetContext(ctx, &inv, `SELECT * FROM invites WHERE token = $1`, token)
+	err := r.db.GetContext(ctx, &inv, `SELECT * FROM invites WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// GetInCompany returns sql.ErrNoRows for invites of other companies.
+func (r *InviteRepository) GetInCompany(ctx context.Context, id, companyID string) (*model.Invite, error) {
+	var inv model.Invite
+	err := r.db.GetContext(ctx, &inv, `SELECT * FROM invites WHERE id = $1 AND company_id = $2`, id, companyID)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// GetPending returns the company's unexpired, unaccepted invite for email, if any.
+func (r *InviteRepository) GetPending(ctx context.Context, companyID, email string) (*model.Invite, error) {
+	var inv model.Invite
+	err := r.db.GetContext(ctx, &inv,
+		`SELECT * FROM invites
+		 WHERE company_id = $1 AND LOWER(email) = LOWER($2)
+		   AND NOT revoked AND accepted_at IS NULL AND expires_at > NOW()
+		 ORDER BY created_at DESC LIMIT 1`, companyID, email)
 	if err != nil {
 		return nil, err
 	}
@@ -47,6 +72,26 @@ func (r *InviteRepository) MarkAccepted(ctx context.Context, id string) error {
 	return err
 }
 
+// MarkSent records a successful delivery of the invite email.
+func (r *InviteRepository) MarkSent(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE invites SET last_sent_at = NOW(), send_count = send_count + 1 WHERE id = $1`, id)
+	return err
+}
+
+// Reissue replaces the invite's token, invalidating the previously emailed link.
+func (r *InviteRepository) Reissue(ctx context.Context, id, tokenHash string, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE invites SET token_hash = $2, expires_at = $3 WHERE id = $1`, id, tokenHash, expiresAt)
+	return err
+}
+
+func (r *InviteRepository) Extend(ctx context.Context, id string, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE invites SET expires_at = $2 WHERE id = $1`, id, expiresAt)
+	return err
+}
+
 func (r *InviteRepository) Revoke(ctx context.Context, id string) error {
 	_, err := r.db.ExecContext(ctx,
 		`UPDATE invites SET revoked = TRUE WHERE id = $1`, id)
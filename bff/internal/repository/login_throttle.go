@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"hr-platform/bff/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type LoginThrottleRepository struct {
+	db *sqlx.DB
+}
+
+func NewLoginThrottleRepository(db *sqlx.DB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: db}
+}
+
+func (r *LoginThrottleRepository) Get(ctx context.Context, key string) (*model.LoginThrottle, error) {
+	var t model.LoginThrottle
+	err := r.db.GetContext(ctx, &t, `SELECT * FROM login_throttles WHERE throttle_key = $1`, key)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RecordFailure increments the failure count, starting over when the previous failure is
+// older than window, and returns the updated row.
+func (r *LoginThrottleRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*model.LoginThrottle, error) {
+	var t model.LoginThrottle
+	err := r.db.GetContext(ctx, &t,
+		`INSERT INTO login_throttles (throttle_key, failures, last_failure_at)
+		 VALUES ($1, 1, NOW())
+		 ON CONFLICT (throttle_key) DO UPDATE SET
+		     failures = CASE WHEN login_throttles.last_failure_at < NOW() - make_interval(secs => $2)
+		                     THEN 1 ELSE login_throttles.failures + 1 END,
+		     last_failure_at = NOW()
+		 RETURNING *`,
+		key, window.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *LoginThrottleRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE login_throttles SET locked_until = $1 WHERE throttle_key = $2`, until, key)
+	return err
+}
+
+// Clear removes the counter, e.g. after a successful login or an admin unlock.
+func (r *LoginThrottleRepository) Clear(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM login_throttles WHERE throttle_key = $1`, key)
+	return err
+}
+
+// DeleteStale removes counters whose last failure is older than window and that are not locked.
+func (r *LoginThrottleRepository) DeleteStale(ctx context.Context, window time.Duration) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM login_throttles
+		 WHERE last_failure_at < NOW() - make_interval(secs => $1)
+		   AND (locked_until IS NULL OR locked_until < NOW())`,
+		window.Seconds())
+	return err
+}
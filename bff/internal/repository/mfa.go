@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"hr-platform/bff/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type MFARepository struct {
+	db *sqlx.DB
+}
+
+func NewMFARepository(db *sqlx.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// SetPendingSecret stores a secret that is not yet confirmed. MFA stays disabled until Enable.
+func (r *MFARepository) SetPendingSecret(ctx context.Context, userID, secret string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE users SET mfa_secret = $1, mfa_enabled = FALSE, mfa_last_step = 0, updated_at = NOW()
+		 WHERE id = $2 AND mfa_enabled = FALSE`, secret, userID)
+	return err
+}
+
+// UnsealedSecrets returns the TOTP secrets stored in plaintext before secrets were sealed,
+// by user ID. Those are the 32-character base32 values; sealed ones are longer.
+func (r *MFARepository) UnsealedSecrets(ctx context.Context) (map[string]string, error) {
+	var rows []struct {
+		ID     string `db:"id"`
+		Secret string `db:"mfa_secret"`
+	}
+	if err := r.db.SelectContext(ctx, &rows,
+		`SELECT id, mfa_secret FROM users WHERE mfa_secret IS NOT NULL AND length(mfa_secret) = 32`); err != nil {
+		return nil, err
+	}
+	secrets := make(map[string]string, len(rows))
+	for _, row := range rows {
+		secrets[row.ID] = row.Secret
+	}
+	return secrets, nil
+}
+
+// ReplaceSecret swaps the stored secret for sealed, unless it has changed since it was read.
+func (r *MFARepository) ReplaceSecret(ctx context.Context, userID, old, sealed string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE users SET mfa_secret = $1 WHERE id = $2 AND mfa_secret = $3`, sealed, userID, old)
+	return err
+}
+
+// Enable turns MFA on once the pending secret has been confirmed at the given time step.
+func (r *MFARepository) Enable(ctx context.Context, userID string, step int64) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE users SET mfa_enabled = TRUE, mfa_enrolled_at = NOW(), mfa_last_step = $1, updated_at = NOW()
+		 WHERE id = $2`, step, userID)
+	return err
+}
+
+// Disable removes the user's secret and recovery codes.
+func (r *MFARepository) Disable(ctx context.Context, userID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE users SET mfa_secret = NULL, mfa_enabled = FALSE, mfa_enrolled_at = NULL, mfa_last_step = 0, updated_at = NOW()
+		 WHERE id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AdvanceStep records a used TOTP step. It returns false if that step (or a later one)
+// was already used, which rejects replayed codes.
+func (r *MFARepository) AdvanceStep(ctx context.Context, userID string, step int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE users SET mfa_last_step = $1 WHERE id = $2 AND mfa_last_step < $1`, step, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// ReplaceRecoveryCodes discards the user's recovery codes and stores the given hashes.
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, h := range hashes {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, h); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// UseRecoveryCode consumes a recovery code. It returns false if no unused code matches.
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID, hash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE mfa_recovery_codes SET used_at = NOW()
+		 WHERE id = (SELECT id FROM mfa_recovery_codes WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL LIMIT 1)`,
+		userID, hash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *MFARepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := r.db.GetContext(ctx, &n,
+		`SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`, userID)
+	return n, err
+}
+
+func (r *MFARepository) CreateChallenge(ctx context.Context, ch *model.MFAChallenge) error {
+	query := `INSERT INTO mfa_challenges (token_hash, user_id, company_id, company_locked, expires_at)
+	          VALUES ($1, $2, $3, $4, $5)
+	          RETURNING id, created_at`
+	return r.db.QueryRowContext(ctx, query, ch.TokenHash, ch.UserID, ch.CompanyID, ch.CompanyLocked, ch.ExpiresAt).
+		Scan(&ch.ID, &ch.CreatedAt)
+}
+
+func (r *MFARepository) GetChallenge(ctx context.Context, hash string) (*model.MFAChallenge, error) {
+	var ch model.MFAChallenge
+	err := r.db.GetContext(ctx, &ch, `SELECT * FROM mfa_challenges WHERE token_hash = $1`, hash)
+	if err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
+
+func (r *MFARepository) RecordFailedAttempt(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id = $1`, id)
+	return err
+}
+
+// ConsumeChallenge marks the challenge used. It returns sql.ErrNoRows if it was already consumed.
+func (r *MFARepository) ConsumeChallenge(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE mfa_challenges SET consumed_at = NOW() WHERE id = $1 AND consumed_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -32,9 +32,10 @@ func (r *NotificationRepository) CreateForCompanyUsers(ctx context.Context, comp
 
 	_, err := r.db.ExecContext(ctx, `
 		INSERT INTO notifications (user_id, company_id, type, title, message, metadata)
-		SELECT id, company_id, $2, $3, $4, $5
-		FROM users
-		WHERE company_id = $1 AND status = 'active'
+		SELECT m.user_id, m.company_id, $2, $3, $4, $5
+		FROM memberships m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.company_id = $1 AND m.status = 'active' AND u.status = 'active'
 	`, companyID, notifType, title, message, metaStr)
 	return err
 }
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"hr-platform/bff/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type OIDCStateRepository struct {
+	db *sqlx.DB
+}
+
+func NewOIDCStateRepository(db *sqlx.DB) *OIDCStateRepository {
+	return &OIDCStateRepository{db: db}
+}
+
+func (r *OIDCStateRepository) Create(ctx context.Context, st *model.OIDCState) error {
+	query := `INSERT INTO oidc_states (state_hash, company_id, code_verifier, nonce, redirect_to, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6)
+	          RETURNING created_at`
+	return r.db.QueryRowContext(ctx, query,
+		st.StateHash, st.CompanyID, st.CodeVerifier, st.Nonce, st.RedirectTo, st.ExpiresAt,
+	).Scan(&st.CreatedAt)
+}
+
+// Consume deletes and returns the state so each callback can be used only once.
+func (r *OIDCStateRepository) Consume(ctx context.Context, stateHash string) (*model.OIDCState, error) {
+	var st model.OIDCState
+	err := r.db.GetContext(ctx, &st, `DELETE FROM oidc_states WHERE state_hash = $1 RETURNING *`, stateHash)
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// DeleteExpired removes abandoned logins.
+func (r *OIDCStateRepository) DeleteExpired(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM oidc_states WHERE expires_at < NOW()`)
+	return err
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"hr-platform/bff/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PasswordResetRepository struct {
+	db *sqlx.DB
+}
+
+func NewPasswordResetRepository(db *sqlx.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+func (r *PasswordResetRepository) Create(ctx context.Context, pr *model.PasswordReset) error {
+	query := `INSERT INTO password_resets (token_hash, user_id, company_id, requested_ip, expires_at)
+	          VALUES ($1, $2, $3, $4, $5)
+	          RETURNING id, created_at`
+	return r.db.QueryRowContext(ctx, query,
+		pr.TokenHash, pr.UserID, pr.CompanyID, pr.RequestedIP, pr.ExpiresAt,
+	).Scan(&pr.ID, &pr.CreatedAt)
+}
+
+func (r *PasswordResetRepository) GetByTokenHash(ctx context.Context, hash string) (*model.PasswordReset, error) {
+	var pr model.PasswordReset
+	err := r.db.GetContext(ctx, &pr, `SELECT * FROM password_resets WHERE token_hash = $1`, hash)
+	if err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// MarkUsed consumes the reset token. It returns sql.ErrNoRows if the token was already used,
+// so a token can only ever reset a password once.
+func (r *PasswordResetRepository) MarkUsed(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE password_resets SET used_at = NOW() WHERE id = $1 AND used_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// InvalidateForUser consumes every outstanding reset token of the user.
+func (r *PasswordResetRepository) InvalidateForUser(ctx context.Context, userID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE password_resets SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`, userID)
+	return err
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"hr-platform/bff/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ProvisioningRepository struct {
+	db *sqlx.DB
+}
+
+func NewProvisioningRepository(db *sqlx.DB) *ProvisioningRepository {
+	return &ProvisioningRepository{db: db}
+}
+
+// CreateTenant turns a verified signup into a company, its admin and the company's
+// provisioning jobs in one transaction. Deleting the signup in the same transaction means
+// a link submitted twice creates one company; the loser gets sql.ErrNoRows.
+func (r *ProvisioningRepository) CreateTenant(ctx context.Context, signupID string, c *model.Company, u *model.User, steps []model.ProvisioningStep) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM pending_signups WHERE id = $1`, signupID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := tx.QueryRowContext(ctx,
+		`INSERT INTO companies (name, slug, frappe_company_name, industry, size)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, created_at, updated_at`,
+		c.Name, c.Slug, c.FrappeCompanyName, c.Industry, c.Size,
+	).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return err
+	}
+
+	u.CompanyID = c.ID
+	if err := tx.QueryRowContext(ctx,
+		`INSERT INTO users (email, password_hash, full_name, status)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id, created_at, updated_at`,
+		u.Email, u.PasswordHash, u.FullName, u.Status,
+	).Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO memberships (user_id, company_id, role, role_id, frappe_employee_id, last_used_at)
+		 VALUES ($1, $2, $3, $4, $5, NOW())`,
+		u.ID, c.ID, u.Role, u.RoleID, u.FrappeEmployeeID); err != nil {
+		return err
+	}
+
+	for i, step := range steps {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO provisioning_jobs (company_id, user_id, step, position) VALUES ($1, $2, $3, $4)`,
+			c.ID, u.ID, step, i); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListByCompany returns the company's steps in the order they run.
+func (r *ProvisioningRepository) ListByCompany(ctx context.Context, companyID string) ([]model.ProvisioningJob, error) {
+	var jobs []model.ProvisioningJob
+	err := r.db.SelectContext(ctx, &jobs,
+		`SELECT * FROM provisioning_jobs WHERE company_id = $1 ORDER BY position`, companyID)
+	return jobs, err
+}
+
+// DueCompanies returns companies with a step that is due to run, or whose runner's lease
+// has expired.
+func (r *ProvisioningRepository) DueCompanies(ctx context.Context, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.SelectContext(ctx, &ids,
+		`SELECT company_id FROM provisioning_jobs
+		 WHERE (status = 'pending' AND next_run_at <= NOW())
+		    OR (status = 'running' AND locked_until < NOW())
+		 GROUP BY company_id
+		 ORDER BY MIN(next_run_at)
+		 LIMIT $1`, limit)
+	return ids, err
+}
+
+// Claim marks a due step as running for lease and counts the attempt. It returns
+// sql.ErrNoRows if the step is not due or another instance holds it.
+func (r *ProvisioningRepository) Claim(ctx context.Context, id string, lease time.Duration) (*model.ProvisioningJob, error) {
+	var j model.ProvisioningJob
+	err := r.db.GetContext(ctx, &j,
+		`UPDATE provisioning_jobs
+		 SET status = 'running', attempts = attempts + 1, locked_until = $2, updated_at = NOW()
+		 WHERE id = $1
+		   AND ((status = 'pending' AND next_run_at <= NOW()) OR (status = 'running' AND locked_until < NOW()))
+		 RETURNING *`,
+		id, time.Now().Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (r *ProvisioningRepository) Complete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE provisioning_jobs
+		 SET status = 'done', last_error = '', locked_until = NULL, completed_at = NOW(), updated_at = NOW()
+		 WHERE id = $1`, id)
+	return err
+}
+
+// Fail records a failed attempt. The step runs again at nextRunAt, or, if nextRunAt is
+// nil, is marked failed until an admin retries it.
+func (r *ProvisioningRepository) Fail(ctx context.Context, id, lastError string, nextRunAt *time.Time) error {
+	status, next := model.JobPending, time.Now()
+	if nextRunAt == nil {
+		status = model.JobFailed
+	} else {
+		next = *nextRunAt
+	}
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE provisioning_jobs
+		 SET status = $2, last_error = $3, next_run_at = $4, locked_until = NULL, updated_at = NOW()
+		 WHERE id = $1`, id, status, lastError, next)
+	return err
+}
+
+// Retry makes the company's failed and waiting steps due now with a fresh set of
+// attempts. It returns how many steps were reset.
+func (r *ProvisioningRepository) Retry(ctx context.Context, companyID string) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE provisioning_jobs
+		 SET status = 'pending', attempts = 0, next_run_at = NOW(), updated_at = NOW()
+		 WHERE company_id = $1 AND status IN ('pending', 'failed')`, companyID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+
+	"hr-platform/bff/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type RoleRepository struct {
+	db *sqlx.DB
+}
+
+func NewRoleRepository(db *sqlx.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+func (r *RoleRepository) ListPermissions(ctx context.Context) ([]model.PermissionInfo, error) {
+	var perms []model.PermissionInfo
+	err := r.db.SelectContext(ctx, &perms, `SELECT * FROM permissions ORDER BY name`)
+	return perms, err
+}
+
+// ListForCompany returns the built-in roles followed by the company's custom roles,
+// each with its permissions.
+func (r *RoleRepository) ListForCompany(ctx context.Context, companyID string) ([]model.Role, error) {
+	var roles []model.Role
+	err := r.db.SelectContext(ctx, &roles,
+		`SELECT * FROM roles WHERE company_id IS NULL OR company_id = $1
+		 ORDER BY company_id NULLS FIRST, name`, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		RoleID     string `db:"role_id"`
+		Permission string `db:"permission"`
+	}
+	err = r.db.SelectContext(ctx, &rows,
+		`SELECT rp.role_id, rp.permission FROM role_permissions rp
+		 JOIN roles r ON r.id = rp.role_id
+		 WHERE r.company_id IS NULL OR r.company_id = $1
+		 ORDER BY rp.permission`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	byRole := map[string][]string{}
+	for _, row := range rows {
+		byRole[row.RoleID] = append(byRole[row.RoleID], row.Permission)
+	}
+	for i := range roles {
+		roles[i].Permissions = byRole[roles[i].ID]
+		if roles[i].Permissions == nil {
+			roles[i].Permissions = []string{}
+		}
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) GetByID(ctx context.Context, id string) (*model.Role, error) {
+	var role model.Role
+	if err := r.db.GetContext(ctx, &role, `SELECT * FROM roles WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	if err := r.loadPermissions(ctx, &role); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetBuiltIn returns the built-in role for a UserRole.
+func (r *RoleRepository) GetBuiltIn(ctx context.Context, role model.UserRole) (*model.Role, error) {
+	var out model.Role
+	if err := r.db.GetContext(ctx, &out,
+		`SELECT * FROM roles WHERE company_id IS NULL AND slug = $1`, string(role)); err != nil {
+		return nil, err
+	}
+	if err := r.loadPermissions(ctx, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *RoleRepository) loadPermissions(ctx context.Context, role *model.Role) error {
+	role.Permissions = []string{}
+	return r.db.SelectContext(ctx, &role.Permissions,
+		`SELECT permission FROM role_permissions WHERE role_id = $1 ORDER BY permission`, role.ID)
+}
+
+// Create inserts a custom role and its permissions.
+func (r *RoleRepository) Create(ctx context.Context, role *model.Role) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRowContext(ctx,
+		`INSERT INTO roles (company_id, slug, name, description, base_role)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, created_at, updated_at`,
+		role.CompanyID, role.Slug, role.Name, role.Description, role.BaseRole,
+	).Scan(&role.ID, &role.CreatedAt, &role.UpdatedAt); err != nil {
+		return err
+	}
+	if err := insertRolePermissions(ctx, tx, role); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Update replaces a custom role's details and permissions, and moves its users to the
+// new base role so row-level visibility follows the change.
+func (r *RoleRepository) Update(ctx context.Context, role *model.Role) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE roles SET name = $1, description = $2, base_role = $3, updated_at = NOW() WHERE id = $4`,
+		role.Name, role.Description, role.BaseRole, role.ID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE memberships SET role = $1, updated_at = NOW() WHERE role_id = $2`, role.BaseRole, role.ID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, role.ID); err != nil {
+		return err
+	}
+	if err := insertRolePermissions(ctx, tx, role); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertRolePermissions(ctx context.Context, tx *sqlx.Tx, role *model.Role) error {
+	for _, p := range role.Permissions {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO role_permissions (role_id, permission) VALUES ($1, $2)`, role.ID, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RoleRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM roles WHERE id = $1 AND company_id IS NOT NULL`, id)
+	return err
+}
+
+func (r *RoleRepository) CountUsers(ctx context.Context, id string) (int, error) {
+	var n int
+	err := r.db.GetContext(ctx, &n, `SELECT COUNT(*) FROM memberships WHERE role_id = $1`, id)
+	return n, err
+}
+
+// AccessForUser resolves the user's current role and permissions in companyID: their
+// custom role if one is assigned, otherwise the built-in role named by memberships.role.
+// It returns sql.ErrNoRows if the user is not a member of the company.
+func (r *RoleRepository) AccessForUser(ctx context.Context, userID, companyID string) (*model.Access, error) {
+	var row struct {
+		Role     model.UserRole `db:"role"`
+		RoleID   string         `db:"role_id"`
+		RoleName string         `db:"role_name"`
+	}
+	err := r.db.GetContext(ctx, &row,
+		`SELECT m.role, r.id AS role_id, r.name AS role_name
+		 FROM memberships m
+		 JOIN roles r ON r.id = m.role_id
+		     OR (m.role_id IS NULL AND r.company_id IS NULL AND r.slug = m.role::text)
+		 WHERE m.user_id = $1 AND m.company_id = $2`, userID, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var perms []string
+	if err := r.db.SelectContext(ctx, &perms,
+		`SELECT permission FROM role_permissions WHERE role_id = $1`, row.RoleID); err != nil {
+		return nil, err
+	}
+	return &model.Access{
+		Role:        row.Role,
+		RoleID:      row.RoleID,
+		RoleName:    row.RoleName,
+		Permissions: model.NewPermissionSet(perms),
+	}, nil
+}
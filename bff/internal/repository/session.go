@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"hr-platform/bff/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SessionRepository struct {
+	db *sqlx.DB
+}
+
+func NewSessionRepository(db *sqlx.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, s *model.Session) error {
+	query := `INSERT INTO sessions (user_id, company_id, refresh_token_hash, user_agent, ip_address, expires_at,
+	                                impersonator_id, parent_session_id, company_locked)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	          RETURNING id, created_at, last_used_at`
+	return r.db.QueryRowContext(ctx, query,
+		s.UserID, s.CompanyID, s.RefreshTokenHash, s.UserAgent, s.IPAddress, s.ExpiresAt,
+		s.ImpersonatorID, s.ParentSessionID, s.CompanyLocked,
+	).Scan(&s.ID, &s.CreatedAt, &s.LastUsedAt)
+}
+
+func (r *SessionRepository) GetByID(ctx context.Context, id string) (*model.Session, error) {
+	var s model.Session
+	err := r.db.GetContext(ctx, &s, `SELECT * FROM sessions WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *SessionRepository) GetByRefreshHash(ctx context.Context, hash string) (*model.Session, error) {
+	var s model.Session
+	err := r.db.GetContext(ctx, &s, `SELECT * FROM sessions WHERE refresh_token_hash = $1`, hash)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetByPreviousHash finds the session whose last rotated-out refresh token matches hash.
+// A hit means a refresh token was replayed after rotation.
+func (r *SessionRepository) GetByPreviousHash(ctx context.Context, hash string) (*model.Session, error) {
+	var s model.Session
+	err := r.db.GetContext(ctx, &s, `SELECT * FROM sessions WHERE previous_token_hash = $1`, hash)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Rotate swaps the session's refresh token for a new one. It returns sql.ErrNoRows if
+// oldHash is no longer current, so two concurrent refreshes cannot both succeed.
+func (r *SessionRepository) Rotate(ctx context.Context, id, oldHash, newHash, userAgent, ipAddress string) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE sessions
+		 SET previous_token_hash = refresh_token_hash, refresh_token_hash = $3,
+		     user_agent = $4, ip_address = $5, last_used_at = NOW()
+		 WHERE id = $1 AND refresh_token_hash = $2 AND revoked_at IS NULL`,
+		id, oldHash, newHash, userAgent, ipAddress)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// IsActive reports whether the session exists, is not revoked and has not expired.
+// An impersonation session is also inactive once the impersonator's own session is.
+func (r *SessionRepository) IsActive(ctx context.Context, id string) (bool, error) {
+	var active bool
+	err := r.db.GetContext(ctx, &active,
+		`SELECT EXISTS (
+		     SELECT 1 FROM sessions s
+		     LEFT JOIN sessions p ON p.id = s.parent_session_id
+		     WHERE s.id = $1 AND s.revoked_at IS NULL AND s.expires_at > NOW()
+		       AND (s.parent_session_id IS NULL OR (p.revoked_at IS NULL AND p.expires_at > NOW()))
+		 )`, id)
+	return active, err
+}
+
+func (r *SessionRepository) ListActiveByUser(ctx context.Context, userID string) ([]model.Session, error) {
+	var sessions []model.Session
+	err := r.db.SelectContext(ctx, &sessions,
+		`SELECT * FROM sessions WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		 ORDER BY last_used_at DESC`, userID)
+	return sessions, err
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+	return err
+}
+
+// RevokeForUser revokes one of the user's sessions. It returns sql.ErrNoRows if the
+// session does not exist, belongs to someone else or is already revoked.
+func (r *SessionRepository) RevokeForUser(ctx context.Context, id, userID string) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, id, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RevokeOthersForUser revokes every active session of the user except keepID.
+func (r *SessionRepository) RevokeOthersForUser(ctx context.Context, userID, keepID string) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`, userID, keepID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// RevokeAllForUser revokes every active session of the user and returns how many were revoked.
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID string) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// RevokeAllInCompany revokes the user's active sessions in one company, leaving their
+// other memberships signed in, and returns how many were revoked.
+func (r *SessionRepository) RevokeAllInCompany(ctx context.Context, userID, companyID string) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND company_id = $2 AND revoked_at IS NULL`,
+		userID, companyID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// SwitchCompany moves the session to another of the user's companies. Access tokens
+// issued from it afterwards, including on refresh, carry the new company.
+func (r *SessionRepository) SwitchCompany(ctx context.Context, id, companyID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE sessions SET company_id = $1, last_used_at = NOW() WHERE id = $2 AND revoked_at IS NULL`,
+		companyID, id)
+	return err
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"hr-platform/bff/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SigningKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewSigningKeyRepository(db *sqlx.DB) *SigningKeyRepository {
+	return &SigningKeyRepository{db: db}
+}
+
+func (r *SigningKeyRepository) Create(ctx context.Context, k *model.SigningKey) error {
+	return r.db.QueryRowContext(ctx,
+		`INSERT INTO signing_keys (id, algorithm, private_key) VALUES ($1, $2, $3) RETURNING created_at`,
+		k.ID, k.Algorithm, k.PrivateKey,
+	).Scan(&k.CreatedAt)
+}
+
+// List returns every key, newest first, including retired ones.
+func (r *SigningKeyRepository) List(ctx context.Context) ([]model.SigningKey, error) {
+	var keys []model.SigningKey
+	err := r.db.SelectContext(ctx, &keys, `SELECT * FROM signing_keys ORDER BY created_at DESC`)
+	return keys, err
+}
+
+// ListVerifying returns the keys that still verify tokens, newest first.
+func (r *SigningKeyRepository) ListVerifying(ctx context.Context) ([]model.SigningKey, error) {
+	var keys []model.SigningKey
+	err := r.db.SelectContext(ctx, &keys,
+		`SELECT * FROM signing_keys WHERE retired_at IS NULL OR retired_at > NOW() ORDER BY created_at DESC`)
+	return keys, err
+}
+
+// RetireOthers schedules every key except keepID to stop verifying at at. Keys already
+// retiring sooner keep their earlier time.
+func (r *SigningKeyRepository) RetireOthers(ctx context.Context, keepID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE signing_keys SET retired_at = $2
+		 WHERE id <> $1 AND (retired_at IS NULL OR retired_at > $2)`, keepID, at)
+	return err
+}
+
+// Retire stops the key verifying immediately. It returns sql.ErrNoRows if the key does
+// not exist or is already retired.
+func (r *SigningKeyRepository) Retire(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE signing_keys SET retired_at = NOW()
+		 WHERE id = $1 AND (retired_at IS NULL OR retired_at > NOW())`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"hr-platform/bff/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SignupRepository struct {
+	db *sqlx.DB
+}
+
+func NewSignupRepository(db *sqlx.DB) *SignupRepository {
+	return &SignupRepository{db: db}
+}
+
+// Save stores the signup, replacing any earlier one for the same email and with it the
+// previously emailed link.
+func (r *SignupRepository) Save(ctx context.Context, s *model.PendingSignup) error {
+	query := `INSERT INTO pending_signups (email, token_hash, password_hash, full_name, company_name, industry, size, locale, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	          ON CONFLICT ((LOWER(email))) DO UPDATE SET
+	              email = EXCLUDED.email, token_hash = EXCLUDED.token_hash, password_hash = EXCLUDED.password_hash,
+	              full_name = EXCLUDED.full_name, company_name = EXCLUDED.company_name, industry = EXCLUDED.industry,
+	              size = EXCLUDED.size, locale = EXCLUDED.locale, expires_at = EXCLUDED.expires_at, created_at = NOW()
+	          RETURNING id, created_at`
+	return r.db.QueryRowContext(ctx, query,
+		s.Email, s.TokenHash, s.PasswordHash, s.FullName, s.CompanyName, s.Industry, s.Size, s.Locale, s.ExpiresAt,
+	).Scan(&s.ID, &s.CreatedAt)
+}
+
+func (r *SignupRepository) GetByEmail(ctx context.Context, email string) (*model.PendingSignup, error) {
+	var s model.PendingSignup
+	err := r.db.GetContext(ctx, &s, `SELECT * FROM pending_signups WHERE LOWER(email) = LOWER($1)`, email)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *SignupRepository) GetByTokenHash(ctx context.Context, hash string) (*model.PendingSignup, error) {
+	var s model.PendingSignup
+	err := r.db.GetContext(ctx, &s, `SELECT * FROM pending_signups WHERE token_hash = $1`, hash)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// DeleteExpired removes signups whose link expired more than olderThan ago.
+func (r *SignupRepository) DeleteExpired(ctx context.Context, olderThan time.Duration) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM pending_signups WHERE expires_at < $1`, time.Now().Add(-olderThan))
+	return err
+}
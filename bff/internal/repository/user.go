@@ -16,27 +16,78 @@ func NewUserRepository(db *sqlx.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// selectUser reads a user through one of their memberships (m). Queries append the
+// WHERE clause that picks the membership.
+const selectUser = `SELECT u.id, u.email, u.password_hash, u.full_name, u.last_login_at,
+       u.mfa_secret, u.mfa_enabled, u.mfa_enrolled_at, u.mfa_last_step, u.created_at, u.updated_at,
+       m.company_id, m.role, m.role_id, m.frappe_employee_id, m.oidc_subject, m.scim_external_id,
+       CASE WHEN u.status = 'active' THEN m.status ELSE u.status END AS status
+FROM users u
+JOIN memberships m ON m.user_id = u.id`
+
+// defaultMembership orders a user's memberships so the first is the one to sign in to:
+// active ones first, then the most recently used.
+const defaultMembership = ` ORDER BY (m.status = 'active') DESC, m.last_used_at DESC NULLS LAST, m.created_at LIMIT 1`
+
+// Create inserts the user and their membership of u.CompanyID.
 func (r *UserRepository) Create(ctx context.Context, u *model.User) error {
-	query := `INSERT INTO users (email, password_hash, full_name, role, status, company_id, frappe_employee_id)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO users (email, password_hash, full_name, status)
+	          VALUES ($1, $2, $3, $4)
 	          RETURNING id, created_at, updated_at`
-	return r.db.QueryRowContext(ctx, query,
-		u.Email, u.PasswordHash, u.FullName, u.Role, u.Status, u.CompanyID, u.FrappeEmployeeID,
-	).Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt)
+	if err := tx.QueryRowContext(ctx, query,
+		u.Email, u.PasswordHash, u.FullName, u.Status,
+	).Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO memberships (user_id, company_id, role, role_id, frappe_employee_id, last_used_at)
+		 VALUES ($1, $2, $3, $4, $5, NOW())`,
+		u.ID, u.CompanyID, u.Role, u.RoleID, u.FrappeEmployeeID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddMembership gives an existing user access to another company.
+func (r *UserRepository) AddMembership(ctx context.Context, userID, companyID string, role model.UserRole, frappeEmployeeID *string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO memberships (user_id, company_id, role, frappe_employee_id)
+		 VALUES ($1, $2, $3, $4)`,
+		userID, companyID, role, frappeEmployeeID)
+	return err
 }
 
+// GetByID returns the user through their default membership.
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*model.User, error) {
 	var u model.User
-	err := r.db.GetContext(ctx, &u, `SELECT * FROM users WHERE id = $1`, id)
+	err := r.db.GetContext(ctx, &u, selectUser+` WHERE u.id = $1`+defaultMembership, id)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// GetInCompany returns the user through their membership of companyID. It returns
+// sql.ErrNoRows if they are not a member.
+func (r *UserRepository) GetInCompany(ctx context.Context, id, companyID string) (*model.User, error) {
+	var u model.User
+	err := r.db.GetContext(ctx, &u, selectUser+` WHERE u.id = $1 AND m.company_id = $2`, id, companyID)
 	if err != nil {
 		return nil, err
 	}
 	return &u, nil
 }
 
+// GetByEmail returns the user through their default membership.
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var u model.User
-	err := r.db.GetContext(ctx, &u, `SELECT * FROM users WHERE email = $1`, email)
+	err := r.db.GetContext(ctx, &u, selectUser+` WHERE u.email = $1`+defaultMembership, email)
 	if err != nil {
 		return nil, err
 	}
@@ -46,19 +97,57 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.U
 func (r *UserRepository) ListByCompany(ctx context.Context, companyID string) ([]model.User, error) {
 	var users []model.User
 	err := r.db.SelectContext(ctx, &users,
-		`SELECT * FROM users WHERE company_id = $1 ORDER BY created_at`, companyID)
+		selectUser+` WHERE m.company_id = $1 ORDER BY m.created_at`, companyID)
 	return users, err
 }
 
-func (r *UserRepository) UpdateRole(ctx context.Context, id string, role model.UserRole) error {
+// ListMemberships returns every company the user belongs to, most recently used first.
+func (r *UserRepository) ListMemberships(ctx context.Context, userID string) ([]model.Membership, error) {
+	var memberships []model.Membership
+	err := r.db.SelectContext(ctx, &memberships,
+		`SELECT m.id, m.user_id, m.company_id, c.name AS company_name, m.role, m.role_id, m.status,
+		        m.frappe_employee_id, m.last_used_at, m.created_at
+		 FROM memberships m
+		 JOIN companies c ON c.id = m.company_id
+		 WHERE m.user_id = $1
+		 ORDER BY m.last_used_at DESC NULLS LAST, m.created_at`, userID)
+	return memberships, err
+}
+
+// TouchMembership marks the membership as the user's most recent, which makes it the
+// default at their next sign-in.
+func (r *UserRepository) TouchMembership(ctx context.Context, id, companyID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE memberships SET last_used_at = NOW() WHERE user_id = $1 AND company_id = $2`, id, companyID)
+	return err
+}
+
+// UpdateRole sets the user's role in companyID. roleID is the custom role, or nil for the built-in role.
+func (r *UserRepository) UpdateRole(ctx context.Context, id, companyID string, role model.UserRole, roleID *string) error {
 	_, err := r.db.ExecContext(ctx,
-		`UPDATE users SET role = $1, updated_at = NOW() WHERE id = $2`, role, id)
+		`UPDATE memberships SET role = $1, role_id = $2, updated_at = NOW() WHERE user_id = $3 AND company_id = $4`,
+		role, roleID, id, companyID)
 	return err
 }
 
-func (r *UserRepository) UpdateStatus(ctx context.Context, id string, status model.UserStatus) error {
+// UpdateStatus suspends, disables or reactivates the user's membership of companyID.
+func (r *UserRepository) UpdateStatus(ctx context.Context, id, companyID string, status model.UserStatus) error {
 	_, err := r.db.ExecContext(ctx,
-		`UPDATE users SET status = $1, updated_at = NOW() WHERE id = $2`, status, id)
+		`UPDATE memberships SET status = $1, updated_at = NOW() WHERE user_id = $2 AND company_id = $3`,
+		status, id, companyID)
+	return err
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id, passwordHash string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`, passwordHash, id)
+	return err
+}
+
+// UpdateFullName renames the account in every company it belongs to.
+func (r *UserRepository) UpdateFullName(ctx context.Context, id, fullName string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE users SET full_name = $1, updated_at = NOW() WHERE id = $2`, fullName, id)
 	return err
 }
 
@@ -70,16 +159,42 @@ func (r *UserRepository) UpdateLastLogin(ctx context.Context, id string) error {
 
 func (r *UserRepository) GetByFrappeEmployeeID(ctx context.Context, companyID, frappeEmployeeID string) (*model.User, error) {
 	var u model.User
-	err := r.db.GetContext(ctx, &u, `SELECT * FROM users WHERE company_id = $1 AND frappe_employee_id = $2`, companyID, frappeEmployeeID)
+	err := r.db.GetContext(ctx, &u,
+		selectUser+` WHERE m.company_id = $1 AND m.frappe_employee_id = $2`, companyID, frappeEmployeeID)
 	if err != nil {
 		return nil, err
 	}
 	return &u, nil
 }
 
-func (r *UserRepository) LinkEmployee(ctx context.Context, id string, frappeEmployeeID string) error {
+func (r *UserRepository) LinkEmployee(ctx context.Context, id, companyID, frappeEmployeeID string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE memberships SET frappe_employee_id = $1, updated_at = NOW() WHERE user_id = $2 AND company_id = $3`,
+		frappeEmployeeID, id, companyID)
+	return err
+}
+
+func (r *UserRepository) GetByOIDCSubject(ctx context.Context, companyID, subject string) (*model.User, error) {
+	var u model.User
+	err := r.db.GetContext(ctx, &u,
+		selectUser+` WHERE m.company_id = $1 AND m.oidc_subject = $2`, companyID, subject)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *UserRepository) LinkOIDCSubject(ctx context.Context, id, companyID, subject string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE memberships SET oidc_subject = $1, updated_at = NOW() WHERE user_id = $2 AND company_id = $3`,
+		subject, id, companyID)
+	return err
+}
+
+// SetSCIMExternalID records the identity provider's id for the user's membership of companyID.
+func (r *UserRepository) SetSCIMExternalID(ctx context.Context, id, companyID string, externalID *string) error {
 	_, err := r.db.ExecContext(ctx,
-		`UPDATE users SET frappe_employee_id = $1, updated_at = NOW() WHERE id = $2`,
-		frappeEmployeeID, id)
+		`UPDATE memberships SET scim_external_id = $1, updated_at = NOW() WHERE user_id = $2 AND company_id = $3`,
+		externalID, id, companyID)
 	return err
 }
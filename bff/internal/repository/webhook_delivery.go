@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// deliveryRetention is how long delivery IDs are kept; it must outlast the window in
+// which a signed delivery is accepted (webhook.Tolerance).
+const deliveryRetention = 24 * time.Hour
+
+type WebhookDeliveryRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookDeliveryRepository(db *sqlx.DB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+// Claim records a delivery ID and reports whether it is new. IDs past their retention
+// are dropped on the way.
+func (r *WebhookDeliveryRepository) Claim(ctx context.Context, source, id string) (bool, error) {
+	if _, err := r.db.ExecContext(ctx,
+		`DELETE FROM webhook_deliveries WHERE received_at < NOW() - make_interval(secs => $1)`,
+		deliveryRetention.Seconds()); err != nil {
+		return false, err
+	}
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (id, source) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`,
+		id, source)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// Release forgets a claimed ID so the sender's retry of a delivery that failed is processed.
+func (r *WebhookDeliveryRepository) Release(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,150 @@
+// Package signing manages the asymmetric keys that sign access tokens.
+//
+// Keys live in the signing_keys table with their private half sealed under a key derived
+// from JWT_SECRET, so a database dump alone cannot mint tokens. The public halves are
+// published as a JWKS so other services can verify tokens without any shared secret.
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"hr-platform/bff/internal/auth"
+	"hr-platform/bff/internal/model"
+)
+
+// Supported algorithms, as they appear in the JWT "alg" header.
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// Algorithms lists the accepted "alg" values; tokens with any other algorithm are rejected.
+var Algorithms = []string{AlgRS256, AlgEdDSA}
+
+// DefaultAlgorithm is used for keys generated at startup and by rotation unless told otherwise.
+const DefaultAlgorithm = AlgEdDSA
+
+const rsaBits = 2048
+
+// Generate creates a new key pair for alg with a fresh kid and seals its private half
+// with secret. The key is not stored.
+func Generate(alg, secret string) (*model.SigningKey, error) {
+	var priv crypto.Signer
+	var err error
+	switch alg {
+	case AlgRS256:
+		priv, err = rsa.GenerateKey(rand.Reader, rsaBits)
+	case AlgEdDSA:
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q (use %s or %s)", alg, AlgRS256, AlgEdDSA)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := auth.Seal(der, secret, sealPurpose)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := newKID()
+	if err != nil {
+		return nil, err
+	}
+	return &model.SigningKey{ID: kid, Algorithm: alg, PrivateKey: sealed}, nil
+}
+
+// newKID returns a kid that sorts by creation date and is easy to read in logs.
+func newKID() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return time.Now().UTC().Format("20060102") + "-" + hex.EncodeToString(b), nil
+}
+
+// key is a stored key with its private half opened.
+type key struct {
+	id     string
+	alg    string
+	signer crypto.Signer
+}
+
+// open unseals k with secret and checks that the key matches its declared algorithm.
+func open(k model.SigningKey, secret string) (*key, error) {
+	der, err := auth.Open(k.PrivateKey, secret, sealPurpose)
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: cannot decrypt private key (has JWT_SECRET changed?)", k.ID)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %w", k.ID, err)
+	}
+
+	switch priv := parsed.(type) {
+	case *rsa.PrivateKey:
+		if k.Algorithm == AlgRS256 {
+			return &key{id: k.ID, alg: k.Algorithm, signer: priv}, nil
+		}
+	case ed25519.PrivateKey:
+		if k.Algorithm == AlgEdDSA {
+			return &key{id: k.ID, alg: k.Algorithm, signer: priv}, nil
+		}
+	}
+	return nil, fmt.Errorf("signing key %s: private key does not match algorithm %s", k.ID, k.Algorithm)
+}
+
+// JWK is the public half of a key in RFC 7517 form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 (RFC 8037)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (k *key) jwk() JWK {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch pub := k.signer.Public().(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA", Kid: k.id, Use: "sig", Alg: k.alg,
+			N: b64(pub.N.Bytes()),
+			E: b64(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Kid: k.id, Use: "sig", Alg: k.alg, Crv: "Ed25519", X: b64(pub)}
+	}
+	return JWK{Kid: k.id, Alg: k.alg}
+}
+
+// verifyKey is the key jwt-go expects when verifying k's signatures.
+func (k *key) verifyKey() crypto.PublicKey {
+	return k.signer.Public()
+}
+
+// sealPurpose derives the key that protects private keys at rest from JWT_SECRET.
+const sealPurpose = "hr-platform signing keys"
@@ -0,0 +1,192 @@
+package signing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"hr-platform/bff/internal/model"
+	"hr-platform/bff/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ReloadInterval is how often a KeySet picks up keys rotated by another instance or by
+// `bff keys`. A token signed with an unknown kid also triggers a reload, at most once
+// per unknownKIDBackoff.
+const ReloadInterval = time.Minute
+
+const unknownKIDBackoff = 10 * time.Second
+
+// KeySet holds the keys that currently verify access tokens and signs new tokens with
+// the newest of them.
+type KeySet struct {
+	repo   *repository.SigningKeyRepository
+	secret string
+
+	mu       sync.RWMutex
+	keys     map[string]*key
+	current  *key
+	loadedAt time.Time
+}
+
+// Load reads the verifying keys. If there are none, as on first start, it generates and
+// stores one with DefaultAlgorithm.
+func Load(ctx context.Context, repo *repository.SigningKeyRepository, secret string) (*KeySet, error) {
+	s := &KeySet{repo: repo, secret: secret}
+	if err := s.Reload(ctx); err != nil {
+		return nil, err
+	}
+	if s.current == nil {
+		k, err := Generate(DefaultAlgorithm, secret)
+		if err != nil {
+			return nil, err
+		}
+		if err := repo.Create(ctx, k); err != nil {
+			return nil, err
+		}
+		log.Printf("Generated access token signing key %s (%s)", k.ID, k.Algorithm)
+		if err := s.Reload(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Reload replaces the in-memory keys with the ones in the database.
+func (s *KeySet) Reload(ctx context.Context) error {
+	stored, err := s.repo.ListVerifying(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*key, len(stored))
+	var current *key
+	for _, sk := range stored {
+		k, err := open(sk, s.secret)
+		if err != nil {
+			return err
+		}
+		keys[k.id] = k
+		// Newest first; a key with retired_at set is only kept for verification
+		if current == nil && sk.RetiredAt == nil {
+			current = k
+		}
+	}
+
+	s.mu.Lock()
+	s.keys, s.current, s.loadedAt = keys, current, time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// Watch reloads every ReloadInterval until ctx is done.
+func (s *KeySet) Watch(ctx context.Context) {
+	ticker := time.NewTicker(ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reload(ctx); err != nil {
+				log.Printf("signing keys: reload failed: %v", err)
+			}
+		}
+	}
+}
+
+// Sign signs claims with the current key and sets its kid header.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	s.mu.RLock()
+	k := s.current
+	s.mu.RUnlock()
+	if k == nil {
+		return "", errors.New("no active signing key; run `bff keys rotate`")
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(k.alg), claims)
+	token.Header["kid"] = k.id
+	return token.SignedString(k.signer)
+}
+
+// Keyfunc resolves a token's kid to its verification key, for jwt.Parse. Use it with
+// jwt.WithValidMethods(Algorithms).
+func (s *KeySet) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no kid")
+	}
+
+	k := s.lookup(kid)
+	if k == nil && s.reloadForUnknownKID() {
+		k = s.lookup(kid)
+	}
+	if k == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if t.Method.Alg() != k.alg {
+		return nil, fmt.Errorf("token algorithm %s does not match key %s", t.Method.Alg(), kid)
+	}
+	return k.verifyKey(), nil
+}
+
+func (s *KeySet) lookup(kid string) *key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys[kid]
+}
+
+// reloadForUnknownKID reloads unless that happened within unknownKIDBackoff, so tokens
+// with made-up kids cannot hammer the database.
+func (s *KeySet) reloadForUnknownKID() bool {
+	s.mu.RLock()
+	recent := time.Since(s.loadedAt) < unknownKIDBackoff
+	s.mu.RUnlock()
+	if recent {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Reload(ctx); err != nil {
+		log.Printf("signing keys: reload failed: %v", err)
+		return false
+	}
+	return true
+}
+
+// JWKS returns the public halves of every key that still verifies.
+func (s *KeySet) JWKS() JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, k := range s.keys {
+		set.Keys = append(set.Keys, k.jwk())
+	}
+	// kids start with their creation date, so this lists the newest first
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid > set.Keys[j].Kid })
+	return set
+}
+
+// Rotate generates and stores a new key, which signs from the next reload on. Every
+// other key keeps verifying for grace, which should cover the access token lifetime
+// plus ReloadInterval so that tokens signed just before the switch stay valid.
+func Rotate(ctx context.Context, repo *repository.SigningKeyRepository, secret, alg string, grace time.Duration) (*model.SigningKey, error) {
+	k, err := Generate(alg, secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Create(ctx, k); err != nil {
+		return nil, err
+	}
+	if err := repo.RetireOthers(ctx, k.ID, time.Now().Add(grace)); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
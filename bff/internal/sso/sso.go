@@ -0,0 +1,176 @@
+// Package sso implements the OpenID Connect relying party used for company single sign-on.
+package sso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"hr-platform/bff/internal/auth"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// Config is one company's registration with its identity provider.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// Identity is the verified subset of ID token claims the BFF relies on.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// clientSecretPurpose derives the key that seals client secrets in the companies table.
+const clientSecretPurpose = "hr-platform oidc client secrets"
+
+// SealClientSecret encrypts a company's client secret for storage under a key derived
+// from secret (JWT_SECRET). An empty client secret stays empty.
+func SealClientSecret(clientSecret, secret string) (string, error) {
+	if clientSecret == "" {
+		return "", nil
+	}
+	return auth.Seal([]byte(clientSecret), secret, clientSecretPurpose)
+}
+
+// OpenClientSecret reverses SealClientSecret.
+func OpenClientSecret(sealed, secret string) (string, error) {
+	if sealed == "" {
+		return "", nil
+	}
+	b, err := auth.Open(sealed, secret, clientSecretPurpose)
+	if err != nil {
+		return "", errors.New("cannot decrypt oidc client secret (has JWT_SECRET changed?)")
+	}
+	return string(b), nil
+}
+
+// ErrEmailNotVerified is returned when the IdP explicitly marks the email as unverified.
+var ErrEmailNotVerified = errors.New("identity provider reports email as unverified")
+
+// Client performs discovery, the code exchange and ID token verification.
+// Providers are cached per issuer so discovery and JWKS fetches happen once.
+type Client struct {
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	providers map[string]*oidc.Provider
+}
+
+// NewClient returns a Client. httpClient may be nil; tests pass one that trusts a local mock issuer.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{httpClient: httpClient, providers: map[string]*oidc.Provider{}}
+}
+
+func (c *Client) context(ctx context.Context) context.Context {
+	return oidc.ClientContext(ctx, c.httpClient)
+}
+
+// Discover fetches the issuer's discovery document, caching the result.
+func (c *Client) Discover(ctx context.Context, issuer string) (*oidc.Provider, error) {
+	c.mu.Lock()
+	p, ok := c.providers[issuer]
+	c.mu.Unlock()
+	if ok {
+		return p, nil
+	}
+
+	// The provider keeps this context for later JWKS refreshes, so it must not be request-scoped.
+	p, err := oidc.NewProvider(c.context(context.Background()), issuer)
+	if err != nil {
+		return nil, fmt.Errorf("oidc discovery for %s: %w", issuer, err)
+	}
+
+	c.mu.Lock()
+	c.providers[issuer] = p
+	c.mu.Unlock()
+	return p, nil
+}
+
+// Forget drops a cached provider, e.g. after a company changes its issuer.
+func (c *Client) Forget(issuer string) {
+	c.mu.Lock()
+	delete(c.providers, issuer)
+	c.mu.Unlock()
+}
+
+func (c *Client) oauth2Config(p *oidc.Provider, cfg Config) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Endpoint:     p.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+	}
+}
+
+// AuthCodeURL returns the provider URL to send the browser to, with a PKCE S256 challenge.
+func (c *Client) AuthCodeURL(ctx context.Context, cfg Config, state, nonce, verifier string) (string, error) {
+	p, err := c.Discover(ctx, cfg.Issuer)
+	if err != nil {
+		return "", err
+	}
+	return c.oauth2Config(p, cfg).AuthCodeURL(state,
+		oidc.Nonce(nonce),
+		oauth2.S256ChallengeOption(verifier),
+	), nil
+}
+
+// Exchange redeems the authorization code and verifies the returned ID token's
+// signature, issuer, audience, expiry and nonce.
+func (c *Client) Exchange(ctx context.Context, cfg Config, code, verifier, nonce string) (*Identity, error) {
+	p, err := c.Discover(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	tok, err := c.oauth2Config(p, cfg).Exchange(c.context(ctx), code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchanging code: %w", err)
+	}
+	raw, ok := tok.Extra("id_token").(string)
+	if !ok || raw == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	idToken, err := p.Verifier(&oidc.Config{ClientID: cfg.ClientID}).Verify(c.context(ctx), raw)
+	if err != nil {
+		return nil, fmt.Errorf("verifying id_token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("id_token nonce mismatch")
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified *bool  `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("decoding id_token claims: %w", err)
+	}
+	// Some IdPs (e.g. Entra ID) omit email_verified; only an explicit false is rejected.
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	return &Identity{
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified != nil,
+		Name:          claims.Name,
+	}, nil
+}
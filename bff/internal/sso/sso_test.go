@@ -0,0 +1,264 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "hr-platform"
+	testClientSecret = "s3cret"
+	testCode         = "auth-code"
+	testVerifier     = "pkce-verifier-0123456789012345678901234567890123"
+)
+
+// mockIssuer is a minimal OpenID provider: discovery, JWKS and a token endpoint that
+// redeems testCode for an ID token carrying claims.
+type mockIssuer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu          sync.Mutex
+	claims      jwt.MapClaims
+	discoveries int
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		m.discoveries++
+		m.mu.Unlock()
+		writeJSON(w, map[string]any{
+			"issuer":                                m.URL,
+			"authorization_endpoint":                m.URL + "/authorize",
+			"token_endpoint":                        m.URL + "/token",
+			"jwks_uri":                              m.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		b64 := base64.RawURLEncoding.EncodeToString
+		writeJSON(w, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "test", "use": "sig", "alg": "RS256",
+			"n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != testClientID || secret != testClientSecret {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		if r.PostFormValue("code") != testCode || r.PostFormValue("code_verifier") != testVerifier {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		m.mu.Lock()
+		claims := m.claims
+		m.mu.Unlock()
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		tok.Header["kid"] = "test"
+		idToken, err := tok.SignedString(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, map[string]any{"access_token": "at", "token_type": "Bearer", "expires_in": 300, "id_token": idToken})
+	})
+	m.Server = httptest.NewTLSServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// idToken sets the claims of the next ID token; overrides replace or, when nil, drop
+// the defaults.
+func (m *mockIssuer) idToken(overrides map[string]any) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": m.URL, "sub": "idp-user-1", "aud": testClientID,
+		"iat": now.Unix(), "exp": now.Add(5 * time.Minute).Unix(),
+		"nonce": "nonce-1", "email": "somchai@example.com", "email_verified": true, "name": "Somchai",
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(claims, k)
+			continue
+		}
+		claims[k] = v
+	}
+	m.mu.Lock()
+	m.claims = claims
+	m.mu.Unlock()
+}
+
+func (m *mockIssuer) discoveryCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.discoveries
+}
+
+func (m *mockIssuer) config() Config {
+	return Config{Issuer: m.URL, ClientID: testClientID, ClientSecret: testClientSecret, RedirectURL: "https://hr.example.com/api/auth/oidc/callback"}
+}
+
+func TestDiscoverCachesProvider(t *testing.T) {
+	idp := newMockIssuer(t)
+	c := NewClient(idp.Client())
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := c.Discover(ctx, idp.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := idp.discoveryCount(); n != 1 {
+		t.Fatalf("discovery fetched %d times, want 1", n)
+	}
+
+	c.Forget(idp.URL)
+	if _, err := c.Discover(ctx, idp.URL); err != nil {
+		t.Fatal(err)
+	}
+	if n := idp.discoveryCount(); n != 2 {
+		t.Fatalf("discovery fetched %d times after Forget, want 2", n)
+	}
+
+	if _, err := c.Discover(ctx, idp.URL+"/other"); err == nil {
+		t.Fatal("discovery of an issuer without a document succeeded")
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	idp := newMockIssuer(t)
+	c := NewClient(idp.Client())
+
+	raw, err := c.AuthCodeURL(context.Background(), idp.config(), "state-1", "nonce-1", testVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(raw, idp.URL+"/authorize?") {
+		t.Fatalf("auth URL %s is not the issuer's authorization endpoint", raw)
+	}
+	for _, want := range []string{"state=state-1", "nonce=nonce-1", "code_challenge_method=S256", "client_id=" + testClientID} {
+		if !strings.Contains(raw, want) {
+			t.Errorf("auth URL %s is missing %s", raw, want)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	idp := newMockIssuer(t)
+	c := NewClient(idp.Client())
+	wrongAudience := idp.config()
+	wrongAudience.ClientID = "someone-else"
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		code     string
+		nonce    string
+		claims   map[string]any
+		want     *Identity
+		wantErr  error
+		errMatch string
+	}{
+		{
+			name: "verified email",
+			want: &Identity{Subject: "idp-user-1", Email: "somchai@example.com", EmailVerified: true, Name: "Somchai"},
+		},
+		{
+			name:   "email_verified omitted",
+			claims: map[string]any{"email_verified": nil},
+			want:   &Identity{Subject: "idp-user-1", Email: "somchai@example.com", Name: "Somchai"},
+		},
+		{name: "email unverified", claims: map[string]any{"email_verified": false}, wantErr: ErrEmailNotVerified},
+		{name: "nonce mismatch", nonce: "nonce-2", errMatch: "nonce mismatch"},
+		{name: "nonce missing", claims: map[string]any{"nonce": nil}, errMatch: "nonce mismatch"},
+		{name: "other issuer", claims: map[string]any{"iss": "https://evil.example.com"}, errMatch: "verifying id_token"},
+		{name: "expired", claims: map[string]any{"exp": time.Now().Add(-time.Minute).Unix()}, errMatch: "verifying id_token"},
+		{name: "bad code", code: "stolen", errMatch: "exchanging code"},
+		{name: "wrong client", cfg: wrongAudience, errMatch: "exchanging code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp.idToken(tt.claims)
+			cfg, code, nonce := tt.cfg, tt.code, tt.nonce
+			if cfg.Issuer == "" {
+				cfg = idp.config()
+			}
+			if code == "" {
+				code = testCode
+			}
+			if nonce == "" {
+				nonce = "nonce-1"
+			}
+
+			ident, err := c.Exchange(context.Background(), cfg, code, testVerifier, nonce)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.errMatch != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errMatch) {
+					t.Fatalf("err = %v, want one containing %q", err, tt.errMatch)
+				}
+			default:
+				if err != nil {
+					t.Fatal(err)
+				}
+				if *ident != *tt.want {
+					t.Fatalf("identity = %+v, want %+v", *ident, *tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClientSecretSealing(t *testing.T) {
+	sealed, err := SealClientSecret(testClientSecret, "jwt-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sealed, testClientSecret) {
+		t.Fatalf("sealed secret %q contains the plaintext", sealed)
+	}
+	if got, err := OpenClientSecret(sealed, "jwt-secret"); err != nil || got != testClientSecret {
+		t.Fatalf("OpenClientSecret = %q, %v", got, err)
+	}
+	if _, err := OpenClientSecret(sealed, "another-secret"); err == nil {
+		t.Fatal("opened a client secret with the wrong JWT secret")
+	}
+
+	if sealed, err := SealClientSecret("", "jwt-secret"); err != nil || sealed != "" {
+		t.Fatalf("SealClientSecret of an empty secret = %q, %v", sealed, err)
+	}
+}
@@ -0,0 +1,225 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"hr-platform/bff/internal/client"
+	"hr-platform/bff/internal/model"
+)
+
+const (
+	doctypeSalarySlip = "Salary Slip"
+	doctypeEmployee   = "Employee"
+)
+
+// Event is a change to a Frappe document, as posted by hr_core_ext/webhooks.py.
+type Event struct {
+	ID                string `json:"id"`
+	Event             string `json:"event"` // on_update, on_submit, on_cancel or on_trash
+	Doctype           string `json:"doctype"`
+	Name              string `json:"name"`
+	Company           string `json:"company"` // Frappe company name
+	Employee          string `json:"employee"`
+	Status            string `json:"status"`
+	DocStatus         int    `json:"docstatus"`
+	PreviousStatus    string `json:"previous_status"`
+	PreviousDocStatus int    `json:"previous_docstatus"`
+	// Origin is "api" for token-authenticated calls such as the BFF's own, "desk" for a
+	// user signed in to Frappe and "system" for scheduled and background jobs.
+	Origin string         `json:"origin"`
+	User   string         `json:"user"`
+	Fields map[string]any `json:"fields"`
+}
+
+// ErrUnsupported is returned by Dispatch for doctypes it has no route for.
+var ErrUnsupported = errors.New("unsupported doctype")
+
+// The dispatcher only needs a slice of each repository.
+type companyStore interface {
+	GetByFrappeCompanyName(ctx context.Context, name string) (*model.Company, error)
+}
+
+type userStore interface {
+	GetByFrappeEmployeeID(ctx context.Context, companyID, frappeEmployeeID string) (*model.User, error)
+}
+
+type notifier interface {
+	CreateForUser(ctx context.Context, userID, companyID, notifType, title, message string) error
+}
+
+type auditLog interface {
+	Log(ctx context.Context, actorID, companyID, action, targetType, targetID string, details interface{}) error
+}
+
+// employeeDirectory is the BFF's copy of the employees (see internal/directory).
+type employeeDirectory interface {
+	Refresh(ctx context.Context, company *model.Company, employeeID string) error
+	Remove(ctx context.Context, company *model.Company, employeeID string) error
+}
+
+// Dispatcher routes events by doctype. Each route names what happened, which is
+// recorded in the audit log, and may notify the employee the document belongs to.
+// Employee events update the directory instead.
+type Dispatcher struct {
+	companies companyStore
+	users     userStore
+	notifs    notifier
+	audit     auditLog
+	directory employeeDirectory
+}
+
+func NewDispatcher(companies companyStore, users userStore, notifs notifier, audit auditLog, directory employeeDirectory) *Dispatcher {
+	return &Dispatcher{companies: companies, users: users, notifs: notifs, audit: audit, directory: directory}
+}
+
+// outcome is what a route makes of an event. An empty action means the event is of no
+// interest; a nil notice means nobody is told.
+type outcome struct {
+	action string
+	notice *notice
+}
+
+type notice struct {
+	kind, title, message string
+}
+
+var routes = map[string]func(Event) outcome{
+	client.DoctypeLeaveApplication:  leaveRoute,
+	client.DoctypeShiftRequest:      shiftRequestRoute,
+	client.DoctypeAttendanceRequest: attendanceRequestRoute,
+	doctypeSalarySlip:               salarySlipRoute,
+}
+
+// Dispatch handles one event. Events for companies the BFF does not know are ignored.
+// Errors, from Postgres or from reading an employee back, mean the event should be
+// delivered again.
+func (d *Dispatcher) Dispatch(ctx context.Context, ev Event) error {
+	route, ok := routes[ev.Doctype]
+	if !ok && ev.Doctype != doctypeEmployee {
+		return fmt.Errorf("%w: %s", ErrUnsupported, ev.Doctype)
+	}
+	var out outcome
+	if ok {
+		if out = route(ev); out.action == "" {
+			return nil
+		}
+	}
+
+	company, err := d.companies.GetByFrappeCompanyName(ctx, ev.Company)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Printf("webhook: ignoring %s %s of unknown company %q", ev.Doctype, ev.Name, ev.Company)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if ev.Doctype == doctypeEmployee {
+		if ev.Event == "on_trash" {
+			return d.directory.Remove(ctx, company, ev.Name)
+		}
+		return d.directory.Refresh(ctx, company, ev.Name)
+	}
+
+	var recipient string
+	if out.notice != nil && ev.Employee != "" {
+		u, err := d.users.GetByFrappeEmployeeID(ctx, company.ID, ev.Employee)
+		switch {
+		case err == nil:
+			recipient = u.ID
+			if err := d.notifs.CreateForUser(ctx, u.ID, company.ID, out.notice.kind, out.notice.title, out.notice.message); err != nil {
+				return err
+			}
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+	}
+
+	target := strings.ToLower(strings.ReplaceAll(ev.Doctype, " ", "_"))
+	return d.audit.Log(ctx, "", company.ID, "frappe."+target+"."+out.action, target, ev.Name, map[string]string{
+		"event_id": ev.ID,
+		"employee": ev.Employee,
+		"status":   ev.Status,
+		"origin":   ev.Origin,
+		"user":     ev.User,
+		"notified": recipient,
+	})
+}
+
+// decided is the approval outcome of an event that changed a request's status.
+func decided(ev Event) string {
+	if ev.Status == ev.PreviousStatus {
+		return ""
+	}
+	switch ev.Status {
+	case "Approved", "Rejected":
+		return ev.Status
+	}
+	return ""
+}
+
+func leaveRoute(ev Event) outcome {
+	status := decided(ev)
+	if ev.Event == "on_cancel" || (ev.Status == "Cancelled" && ev.PreviousStatus != "Cancelled") {
+		status = "Cancelled"
+	}
+	if status == "" {
+		return outcome{}
+	}
+	out := outcome{action: strings.ToLower(status)}
+	// Changes made through the BFF were either the employee's own cancellation or a
+	// decision LeaveHandler.Approve has already told them about.
+	if ev.Origin != "api" {
+		out.notice = &notice{"leave_approval", "Leave " + status, "Your leave request has been " + status}
+	}
+	return out
+}
+
+func shiftRequestRoute(ev Event) outcome {
+	status := strings.ToLower(decided(ev))
+	if status == "" {
+		return outcome{}
+	}
+	out := outcome{action: status}
+	// ShiftHandler.ApproveRequest has already told the employee about decisions made through the BFF.
+	if ev.Origin != "api" {
+		out.notice = &notice{"shift_approval", "Shift Request " + status, "Your shift change request has been " + status}
+	}
+	return out
+}
+
+// Attendance requests have no status field: submitting one approves it and cancelling
+// it rejects it.
+func attendanceRequestRoute(ev Event) outcome {
+	var status string
+	switch {
+	case ev.DocStatus == 1 && ev.PreviousDocStatus == 0:
+		status = "approved"
+	case ev.DocStatus == 2 && ev.PreviousDocStatus == 0:
+		status = "rejected"
+	case ev.DocStatus == 2:
+		status = "cancelled"
+	default:
+		return outcome{}
+	}
+	return outcome{action: status, notice: &notice{"attendance_approval", "Attendance Request " + status, "Your attendance correction request has been " + status}}
+}
+
+func salarySlipRoute(ev Event) outcome {
+	period, _ := ev.Fields["start_date"].(string)
+	if len(period) >= 7 {
+		period = period[:7]
+	}
+	switch {
+	case ev.DocStatus == 1 && ev.PreviousDocStatus == 0:
+		return outcome{action: "submitted", notice: &notice{"payroll_processed", "Payslip available", "Your payslip for " + period + " is available"}}
+	case ev.DocStatus == 2:
+		return outcome{action: "cancelled", notice: &notice{"payroll_processed", "Payslip withdrawn", "Your payslip for " + period + " has been withdrawn"}}
+	}
+	return outcome{}
+}
@@ -0,0 +1,63 @@
+// Package webhook receives document changes that Frappe pushes to the BFF (see
+// hr_core_ext/webhooks.py) and turns them into notifications and audit entries.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of a delivery. The signature is "v1=" followed by the hex HMAC-SHA256 of
+// "<id>.<timestamp>.<body>" under the shared secret.
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Tolerance is how far a delivery's timestamp may be from the receiver's clock. Replays
+// inside it are caught by the delivery ID instead.
+const Tolerance = 5 * time.Minute
+
+var (
+	ErrBadSignature = errors.New("webhook signature does not match")
+	ErrStale        = errors.New("webhook timestamp is outside the allowed window")
+)
+
+// Sign returns the signature header value for a delivery.
+func Sign(secret []byte, id, timestamp string, body []byte) string {
+	return "v1=" + hex.EncodeToString(mac(secret, id, timestamp, body))
+}
+
+// Verify checks a delivery's signature, then that its timestamp is within Tolerance of now.
+func Verify(secret []byte, id, timestamp, signature string, body []byte, now time.Time) error {
+	hexSig, ok := strings.CutPrefix(signature, "v1=")
+	if !ok || id == "" {
+		return ErrBadSignature
+	}
+	got, err := hex.DecodeString(hexSig)
+	if err != nil || !hmac.Equal(got, mac(secret, id, timestamp, body)) {
+		return ErrBadSignature
+	}
+
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrStale
+	}
+	if skew := now.Sub(time.Unix(sec, 0)); skew > Tolerance || skew < -Tolerance {
+		return ErrStale
+	}
+	return nil
+}
+
+func mac(secret []byte, id, timestamp string, body []byte) []byte {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte(id + "." + timestamp + "."))
+	m.Write(body)
+	return m.Sum(nil)
+}
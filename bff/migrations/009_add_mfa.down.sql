@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS mfa_challenges;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+ALTER TABLE companies DROP COLUMN IF EXISTS mfa_required_roles;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS mfa_last_step,
+    DROP COLUMN IF EXISTS mfa_enrolled_at,
+    DROP COLUMN IF EXISTS mfa_enabled,
+    DROP COLUMN IF EXISTS mfa_secret;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS oidc_states;
+DROP INDEX IF EXISTS idx_users_oidc_subject;
+ALTER TABLE users DROP COLUMN IF EXISTS oidc_subject;
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS oidc_default_role,
+    DROP COLUMN IF EXISTS oidc_auto_provision,
+    DROP COLUMN IF EXISTS oidc_client_secret,
+    DROP COLUMN IF EXISTS oidc_client_id,
+    DROP COLUMN IF EXISTS oidc_issuer;
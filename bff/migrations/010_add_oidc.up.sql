@@ -0,0 +1,20 @@
+ALTER TABLE companies
+    ADD COLUMN oidc_issuer VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN oidc_client_id VARCHAR(255) NOT NULL DEFAULT '',
+    -- Sealed with AES-GCM under a key derived from JWT_SECRET, like signing_keys.private_key
+    ADD COLUMN oidc_client_secret TEXT NOT NULL DEFAULT '',
+    ADD COLUMN oidc_auto_provision BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN oidc_default_role user_role NOT NULL DEFAULT 'employee';
+
+ALTER TABLE users ADD COLUMN oidc_subject VARCHAR(255);
+CREATE UNIQUE INDEX idx_users_oidc_subject ON users(company_id, oidc_subject) WHERE oidc_subject IS NOT NULL;
+
+CREATE TABLE oidc_states (
+    state_hash VARCHAR(64) PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    redirect_to VARCHAR(512) NOT NULL DEFAULT '/',
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
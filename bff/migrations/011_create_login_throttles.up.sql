@@ -0,0 +1,10 @@
+-- Failed login counters. throttle_key is 'account:<lowercased email>' or 'ip:<address>',
+-- so unknown emails are throttled exactly like real ones.
+CREATE TABLE login_throttles (
+    throttle_key VARCHAR(320) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ,
+    last_failure_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_login_throttles_last_failure ON login_throttles(last_failure_at);
@@ -0,0 +1,76 @@
+CREATE TABLE permissions (
+    name VARCHAR(64) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+-- company_id NULL marks the built-in roles shared by every company. Custom roles keep a
+-- base_role, which decides row-level visibility (own records, reporting chain, company).
+CREATE TABLE roles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    slug VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    base_role user_role NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_roles_builtin_slug ON roles(slug) WHERE company_id IS NULL;
+CREATE UNIQUE INDEX idx_roles_company_slug ON roles(company_id, slug) WHERE company_id IS NOT NULL;
+
+CREATE TABLE role_permissions (
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission VARCHAR(64) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission)
+);
+
+-- NULL means the built-in role named by users.role.
+ALTER TABLE users ADD COLUMN role_id UUID REFERENCES roles(id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('employee.create', 'Create employees'),
+    ('employee.update_sensitive', 'Edit employment details and manage employee documents'),
+    ('employee.view_sensitive', 'View compensation and promotion history'),
+    ('employee.view_all', 'Look up any employee through the HR assistant'),
+    ('leave.approve', 'Approve or reject leave applications'),
+    ('attendance.view_team', 'View attendance records of other employees'),
+    ('attendance.approve', 'Approve or reject attendance corrections'),
+    ('shift.approve', 'Approve or reject shift requests'),
+    ('shift.manage', 'Manage shift types and assignments'),
+    ('overtime.approve', 'Approve or reject overtime requests'),
+    ('overtime.configure', 'Change overtime rates and rules'),
+    ('payroll.view_all', 'View every employee''s salary slips'),
+    ('payroll.process', 'Set up, generate, process and submit payroll'),
+    ('tax.view_all', 'View every employee''s tax deductions and summaries'),
+    ('tax.report', 'Produce PND1 reports and withholding certificates'),
+    ('benefits.manage', 'Manage social security and provident fund'),
+    ('department.manage', 'Create, edit and delete departments'),
+    ('report.view', 'View and export company reports'),
+    ('user.view', 'View user accounts'),
+    ('user.manage', 'Change user roles and status, link employees, unlock accounts'),
+    ('user.invite', 'Invite users and manage invitations'),
+    ('user.reset_mfa', 'Reset another user''s two-factor authentication'),
+    ('company.security', 'Change MFA policy and single sign-on settings'),
+    ('role.manage', 'Create and edit custom roles');
+
+INSERT INTO roles (slug, name, base_role) VALUES
+    ('admin', 'Admin', 'admin'),
+    ('hr', 'HR', 'hr'),
+    ('manager', 'Manager', 'manager'),
+    ('employee', 'Employee', 'employee');
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.name FROM roles r CROSS JOIN permissions p
+WHERE r.company_id IS NULL AND r.slug = 'admin';
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.name FROM roles r CROSS JOIN permissions p
+WHERE r.company_id IS NULL AND r.slug = 'hr'
+  AND p.name NOT IN ('user.reset_mfa', 'company.security', 'role.manage');
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.name FROM roles r CROSS JOIN permissions p
+WHERE r.company_id IS NULL AND r.slug = 'manager'
+  AND p.name IN ('leave.approve', 'attendance.view_team', 'attendance.approve',
+                 'shift.approve', 'overtime.approve', 'tax.view_all');
@@ -0,0 +1,9 @@
+DELETE FROM role_permissions WHERE permission = 'user.impersonate';
+DELETE FROM permissions WHERE name = 'user.impersonate';
+
+DROP INDEX IF EXISTS idx_audit_impersonator;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS impersonator_id;
+
+ALTER TABLE sessions
+    DROP COLUMN IF EXISTS parent_session_id,
+    DROP COLUMN IF EXISTS impersonator_id;
@@ -0,0 +1,16 @@
+-- An impersonation session belongs to the impersonated user and dies with the
+-- impersonator's own session (parent_session_id).
+ALTER TABLE sessions
+    ADD COLUMN impersonator_id UUID REFERENCES users(id),
+    ADD COLUMN parent_session_id UUID REFERENCES sessions(id) ON DELETE CASCADE;
+
+-- Set on every audit entry written while an admin is impersonating actor_id.
+ALTER TABLE audit_log ADD COLUMN impersonator_id UUID REFERENCES users(id);
+
+CREATE INDEX idx_audit_impersonator ON audit_log(impersonator_id) WHERE impersonator_id IS NOT NULL;
+
+INSERT INTO permissions (name, description) VALUES
+    ('user.impersonate', 'Temporarily view the app as another user');
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT id, 'user.impersonate' FROM roles WHERE company_id IS NULL AND slug = 'admin';
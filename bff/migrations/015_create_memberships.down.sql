@@ -0,0 +1,29 @@
+ALTER TABLE sessions DROP COLUMN IF EXISTS company_locked;
+
+ALTER TABLE users
+    ADD COLUMN role user_role NOT NULL DEFAULT 'employee',
+    ADD COLUMN role_id UUID REFERENCES roles(id),
+    ADD COLUMN company_id UUID REFERENCES companies(id),
+    ADD COLUMN frappe_employee_id VARCHAR(100),
+    ADD COLUMN oidc_subject VARCHAR(255);
+
+-- Users with several memberships keep only the most recently used one.
+UPDATE users u
+SET role = m.role, role_id = m.role_id, company_id = m.company_id,
+    frappe_employee_id = m.frappe_employee_id, oidc_subject = m.oidc_subject,
+    status = CASE WHEN u.status = 'active' THEN m.status ELSE u.status END
+FROM (
+    SELECT DISTINCT ON (user_id) *
+    FROM memberships
+    ORDER BY user_id, last_used_at DESC NULLS LAST, created_at
+) m
+WHERE m.user_id = u.id;
+
+DELETE FROM users WHERE company_id IS NULL;
+ALTER TABLE users ALTER COLUMN company_id SET NOT NULL;
+
+CREATE INDEX idx_users_company ON users(company_id);
+CREATE INDEX idx_users_frappe_employee ON users(frappe_employee_id);
+CREATE UNIQUE INDEX idx_users_oidc_subject ON users(company_id, oidc_subject) WHERE oidc_subject IS NOT NULL;
+
+DROP TABLE IF EXISTS memberships;
@@ -0,0 +1,39 @@
+-- A membership gives a user access to one company. Everything that used to be
+-- per-user but is really per-company moves here, so one login can serve several companies.
+CREATE TABLE memberships (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    role user_role NOT NULL DEFAULT 'employee',
+    role_id UUID REFERENCES roles(id),
+    status user_status NOT NULL DEFAULT 'active',
+    frappe_employee_id VARCHAR(100),
+    oidc_subject VARCHAR(255),
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, company_id)
+);
+
+CREATE INDEX idx_memberships_company ON memberships(company_id);
+CREATE INDEX idx_memberships_frappe_employee ON memberships(company_id, frappe_employee_id);
+CREATE UNIQUE INDEX idx_memberships_oidc_subject ON memberships(company_id, oidc_subject) WHERE oidc_subject IS NOT NULL;
+
+-- Suspension and disabling are per company; users.status keeps only invited/active.
+INSERT INTO memberships (user_id, company_id, role, role_id, status, frappe_employee_id, oidc_subject, last_used_at, created_at)
+SELECT id, company_id, role, role_id,
+       CASE WHEN status IN ('suspended', 'disabled') THEN status ELSE 'active' END,
+       frappe_employee_id, oidc_subject, last_login_at, created_at
+FROM users;
+
+UPDATE users SET status = 'active' WHERE status IN ('suspended', 'disabled');
+
+-- Sessions started through a company's SSO cannot switch to the user's other companies.
+ALTER TABLE sessions ADD COLUMN company_locked BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE users
+    DROP COLUMN role,
+    DROP COLUMN role_id,
+    DROP COLUMN company_id,
+    DROP COLUMN frappe_employee_id,
+    DROP COLUMN oidc_subject;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_memberships_scim_external_id;
+ALTER TABLE memberships DROP COLUMN IF EXISTS scim_external_id;
+
+DROP INDEX IF EXISTS idx_companies_scim_token;
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS scim_token_prefix,
+    DROP COLUMN IF EXISTS scim_token_hash,
+    DROP COLUMN IF EXISTS scim_create_employees;
@@ -0,0 +1,11 @@
+-- A company's identity provider provisions users over SCIM with one bearer token.
+ALTER TABLE companies
+    ADD COLUMN scim_token_prefix VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN scim_token_hash VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN scim_create_employees BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX idx_companies_scim_token ON companies(scim_token_hash) WHERE scim_token_hash <> '';
+
+-- The identity provider's own id for the user, echoed back as externalId.
+ALTER TABLE memberships ADD COLUMN scim_external_id VARCHAR(255);
+CREATE UNIQUE INDEX idx_memberships_scim_external_id ON memberships(company_id, scim_external_id) WHERE scim_external_id IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Keys that sign access tokens. The id is the JWT "kid" header. The newest key without
+-- retired_at signs; every key not yet past retired_at still verifies and is published
+-- at /.well-known/jwks.json.
+CREATE TABLE signing_keys (
+    id VARCHAR(64) PRIMARY KEY,
+    algorithm VARCHAR(16) NOT NULL,
+    -- PKCS#8 DER sealed with AES-GCM under a key derived from JWT_SECRET
+    private_key TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    retired_at TIMESTAMPTZ
+);
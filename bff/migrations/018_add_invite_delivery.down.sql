@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_invites_company_email;
+ALTER TABLE invites
+    DROP COLUMN IF EXISTS full_name,
+    DROP COLUMN IF EXISTS locale,
+    DROP COLUMN IF EXISTS last_sent_at,
+    DROP COLUMN IF EXISTS send_count;
+
+-- The original tokens cannot be recovered; outstanding invite links stop working.
+ALTER TABLE invites ADD COLUMN token VARCHAR(255);
+UPDATE invites SET token = token_hash;
+ALTER TABLE invites ALTER COLUMN token SET NOT NULL;
+ALTER TABLE invites ADD CONSTRAINT invites_token_key UNIQUE (token);
+CREATE INDEX idx_invites_token ON invites(token);
+DROP INDEX IF EXISTS idx_invites_token_hash;
+ALTER TABLE invites DROP COLUMN token_hash;
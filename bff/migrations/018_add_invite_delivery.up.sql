@@ -0,0 +1,16 @@
+-- Invites are now emailed, so the link is never shown again and only its hash is kept
+-- (as for password resets).
+ALTER TABLE invites ADD COLUMN token_hash VARCHAR(64);
+UPDATE invites SET token_hash = encode(sha256(convert_to(token, 'UTF8')), 'hex');
+ALTER TABLE invites ALTER COLUMN token_hash SET NOT NULL;
+CREATE UNIQUE INDEX idx_invites_token_hash ON invites(token_hash);
+DROP INDEX IF EXISTS idx_invites_token;
+ALTER TABLE invites DROP COLUMN token;
+
+ALTER TABLE invites
+    ADD COLUMN full_name VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN locale VARCHAR(8) NOT NULL DEFAULT 'th',
+    ADD COLUMN last_sent_at TIMESTAMPTZ,
+    ADD COLUMN send_count INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_invites_company_email ON invites(company_id, email);
@@ -0,0 +1,2 @@
+ALTER TABLE companies DROP COLUMN IF EXISTS allowed_email_domains;
+DROP TABLE IF EXISTS pending_signups;
@@ -0,0 +1,21 @@
+-- Self-service signups wait here until the email address is confirmed. Only then are
+-- the company, its Frappe company and the admin account created.
+CREATE TABLE pending_signups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    full_name VARCHAR(255) NOT NULL,
+    company_name VARCHAR(255) NOT NULL,
+    industry VARCHAR(100) NOT NULL DEFAULT '',
+    size VARCHAR(50) NOT NULL DEFAULT '',
+    locale VARCHAR(8) NOT NULL DEFAULT 'th',
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Signing up again replaces the earlier request and its link
+CREATE UNIQUE INDEX idx_pending_signups_email ON pending_signups(LOWER(email));
+
+-- Comma-separated domains that invites and SSO logins must come from; empty allows any.
+ALTER TABLE companies ADD COLUMN allowed_email_domains TEXT NOT NULL DEFAULT '';
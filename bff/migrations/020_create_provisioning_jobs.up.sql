@@ -0,0 +1,23 @@
+-- Frappe side of tenant provisioning. Signup commits the company and admin in one
+-- Postgres transaction together with these rows; each step then runs, and is retried
+-- with backoff, until it succeeds or runs out of attempts and waits for an admin.
+CREATE TABLE provisioning_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    step VARCHAR(50) NOT NULL,
+    -- Steps of a company run in position order; a step waits for the ones before it
+    position SMALLINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- A running step whose lease has passed was abandoned by a crashed instance
+    locked_until TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (company_id, step)
+);
+
+CREATE INDEX idx_provisioning_jobs_due ON provisioning_jobs(next_run_at) WHERE status IN ('pending', 'running');
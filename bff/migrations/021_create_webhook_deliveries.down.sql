@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_companies_frappe_company_name;
+DROP TABLE IF EXISTS webhook_deliveries;
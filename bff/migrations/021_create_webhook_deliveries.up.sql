@@ -0,0 +1,13 @@
+-- IDs of webhook deliveries already acted on, so a captured or retried delivery is not
+-- processed twice. Deliveries older than the signature window are refused anyway, so
+-- rows are only kept for a day.
+CREATE TABLE webhook_deliveries (
+    id VARCHAR(64) PRIMARY KEY,
+    source VARCHAR(20) NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_deliveries_received ON webhook_deliveries(received_at);
+
+-- Webhooks name the Frappe company; look the tenant up by it
+CREATE INDEX idx_companies_frappe_company_name ON companies(frappe_company_name);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS employees_cache_sync;
+DROP TABLE IF EXISTS employees_cache;
@@ -0,0 +1,53 @@
+-- Trigram indexes serve substring search. Thai is written without spaces between
+-- words, so full-text search alone would only match Thai names from their first letter.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Copy of each company's Frappe employees for search and the directory, kept in sync
+-- by internal/directory. synced_at is when a row was last confirmed against Frappe;
+-- stale_since is set when Frappe reported a change the BFF could not read back yet.
+CREATE TABLE employees_cache (
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    employee_id VARCHAR(140) NOT NULL,
+    employee_name VARCHAR(255) NOT NULL DEFAULT '',
+    first_name VARCHAR(140) NOT NULL DEFAULT '',
+    last_name VARCHAR(140) NOT NULL DEFAULT '',
+    department VARCHAR(140) NOT NULL DEFAULT '',
+    designation VARCHAR(140) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT '',
+    branch VARCHAR(140) NOT NULL DEFAULT '',
+    employment_type VARCHAR(140) NOT NULL DEFAULT '',
+    company_email VARCHAR(255) NOT NULL DEFAULT '',
+    reports_to VARCHAR(140) NOT NULL DEFAULT '',
+    image TEXT NOT NULL DEFAULT '',
+    date_of_joining DATE,
+    -- Frappe's modified, as get_employee_directory formats it; only compared with itself
+    frappe_modified VARCHAR(26) NOT NULL DEFAULT '',
+    synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    stale_since TIMESTAMPTZ,
+    search_text TEXT GENERATED ALWAYS AS (
+        lower(employee_name || ' ' || first_name || ' ' || last_name || ' ' || employee_id || ' ' || company_email)
+    ) STORED,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('simple', employee_name || ' ' || first_name || ' ' || last_name)
+    ) STORED,
+    PRIMARY KEY (company_id, employee_id)
+);
+
+CREATE INDEX idx_employees_cache_search_vector ON employees_cache USING GIN (search_vector);
+CREATE INDEX idx_employees_cache_search_text ON employees_cache USING GIN (search_text gin_trgm_ops);
+CREATE INDEX idx_employees_cache_name ON employees_cache(company_id, employee_name);
+
+-- Where each company's sync has got to. watermark and watermark_id are the modified and
+-- employee_id of the last employee read, so the next sync asks only for later changes.
+CREATE TABLE employees_cache_sync (
+    company_id UUID PRIMARY KEY REFERENCES companies(id) ON DELETE CASCADE,
+    watermark VARCHAR(26) NOT NULL DEFAULT '',
+    watermark_id VARCHAR(140) NOT NULL DEFAULT '',
+    synced_at TIMESTAMPTZ,
+    -- Last sync that read every employee and dropped the ones Frappe no longer has
+    full_sync_at TIMESTAMPTZ,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_sync_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- A sync whose lease has passed was abandoned by a crashed instance
+    locked_until TIMESTAMPTZ
+);
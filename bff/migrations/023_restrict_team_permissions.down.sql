@@ -0,0 +1 @@
+-- The dropped grants are not restored.
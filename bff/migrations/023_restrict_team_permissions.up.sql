@@ -0,0 +1,10 @@
+-- A custom role's base_role decides whose records it reaches. With an employee base that
+-- is only the holder's own, so approving or viewing a team's requests would have no
+-- reporting chain to check against. The role handler now refuses these grants; drop
+-- any made before it did.
+DELETE FROM role_permissions rp
+USING roles r
+WHERE rp.role_id = r.id
+  AND r.company_id IS NOT NULL
+  AND r.base_role = 'employee'
+  AND rp.permission IN ('leave.approve', 'attendance.view_team', 'attendance.approve', 'shift.approve', 'overtime.approve');
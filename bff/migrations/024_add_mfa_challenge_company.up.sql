@@ -0,0 +1,5 @@
+-- The membership a challenge was issued for, so the second factor completes the login
+-- into that company. SSO logins stay locked to the company whose IdP vouched for them.
+ALTER TABLE mfa_challenges
+    ADD COLUMN company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    ADD COLUMN company_locked BOOLEAN NOT NULL DEFAULT FALSE;
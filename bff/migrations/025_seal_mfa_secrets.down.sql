@@ -0,0 +1,6 @@
+-- Sealed secrets do not fit the old column and cannot be unsealed here; their users
+-- enroll again.
+DELETE FROM mfa_recovery_codes WHERE user_id IN (SELECT id FROM users WHERE length(mfa_secret) > 64);
+UPDATE users SET mfa_secret = NULL, mfa_enabled = FALSE, mfa_enrolled_at = NULL, mfa_last_step = 0
+WHERE length(mfa_secret) > 64;
+ALTER TABLE users ALTER COLUMN mfa_secret TYPE VARCHAR(64);
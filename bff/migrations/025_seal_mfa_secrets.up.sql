@@ -0,0 +1,3 @@
+-- TOTP secrets are stored sealed with a key derived from JWT_SECRET, which is longer than
+-- the base32 secret. The server seals existing plaintext secrets when it starts.
+ALTER TABLE users ALTER COLUMN mfa_secret TYPE TEXT;
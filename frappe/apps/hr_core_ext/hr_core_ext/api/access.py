@@ -0,0 +1,33 @@
+import frappe
+
+
+# Doctypes whose owner the BFF may look up before acting on a record, with the field
+# that names the owning employee, or None for records that belong to a company only.
+RECORD_OWNER_DOCTYPES = {
+    "Leave Application": "employee",
+    "Attendance Request": "employee",
+    "Shift Request": "employee",
+    "Additional Salary": "employee",
+    "Shift Assignment": "employee",
+    "Department": None,
+}
+
+
+@frappe.whitelist(allow_guest=False)
+def get_record_owner(doctype, name):
+    """Return the employee and company that own a record."""
+    if doctype not in RECORD_OWNER_DOCTYPES:
+        frappe.throw(f"Owner lookup is not allowed for {doctype}")
+
+    if not frappe.db.exists(doctype, name):
+        frappe.throw(f"{doctype} {name} not found", frappe.DoesNotExistError)
+
+    employee_field = RECORD_OWNER_DOCTYPES[doctype]
+    fields = ["name", "company", "docstatus"] + ([employee_field] if employee_field else [])
+    record = frappe.db.get_value(doctype, name, fields, as_dict=True)
+    return {
+        "name": record.name,
+        "employee": record.get(employee_field) if employee_field else None,
+        "company": record.company,
+        "docstatus": record.docstatus,
+    }
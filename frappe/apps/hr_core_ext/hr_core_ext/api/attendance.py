@@ -1,5 +1,16 @@
 import frappe
 
+from hr_core_ext.api import paging
+
+
+# Fields get_attendance_requests can be sorted by, mapped to their columns.
+ATTENDANCE_REQUEST_SORTABLE = {
+    "employee_name": "employee_name",
+    "from_date": "from_date",
+    "to_date": "to_date",
+    "created": "creation",
+}
+
 
 @frappe.whitelist(allow_guest=False)
 def get_attendance_summary(employee_id, from_date=None, to_date=None):
@@ -121,26 +132,32 @@ def create_attendance_request(employee_id, attendance_date, reason, status="Pres
 
 
 @frappe.whitelist(allow_guest=False)
-def get_attendance_requests(employee_id=None, limit_page_length=20):
-    """Get attendance correction requests."""
+def get_attendance_requests(employee_id=None, limit_page_length=20, company=None,
+                            limit_start=0, employee_ids=None, order_by=None, with_total=0):
+    """Get attendance correction requests. See paging.py for the paging arguments."""
     filters = {}
+    if company:
+        filters["company"] = company
     if employee_id:
         filters["employee"] = employee_id
+    paging.employee_filter(filters, employee_ids)
 
-    requests = frappe.get_list(
+    page = paging.get_page(
         "Attendance Request",
-        fields=[
+        filters,
+        [
             "name", "employee", "employee_name",
             "from_date", "to_date", "reason",
             "docstatus", "half_day",
         ],
-        filters=filters,
-        limit_page_length=int(limit_page_length),
-        order_by="creation desc",
+        paging.order_by(order_by, ATTENDANCE_REQUEST_SORTABLE, "creation desc"),
+        limit_page_length,
+        limit_start,
+        with_total,
     )
 
     # Map docstatus to human-readable status
-    for req in requests:
+    for req in paging.rows_of(page):
         if req.docstatus == 0:
             req["status"] = "Pending"
         elif req.docstatus == 1:
@@ -148,7 +165,7 @@ def get_attendance_requests(employee_id=None, limit_page_length=20):
         elif req.docstatus == 2:
             req["status"] = "Rejected"
 
-    return requests
+    return page
 
 
 @frappe.whitelist(allow_guest=False)
@@ -47,10 +47,28 @@ def get_departments(company=None):
     return {"departments": result, "total": len(result)}
 
 
+def _get_department(name, company=None):
+    """Load a department; with company, departments of other companies are not found."""
+    if not frappe.db.exists("Department", name):
+        frappe.throw(f"Department {name} not found", frappe.DoesNotExistError)
+    dept = frappe.get_doc("Department", name)
+    if company and dept.company != company:
+        frappe.throw(f"Department {name} not found", frappe.DoesNotExistError)
+    return dept
+
+
+def _check_parent(parent_department, company=None):
+    """A parent must be the company's own department or a shared one, such as the root."""
+    if parent_department and company:
+        parent_company = frappe.db.get_value("Department", parent_department, "company")
+        if parent_company and parent_company != company:
+            frappe.throw(f"Department {parent_department} not found", frappe.DoesNotExistError)
+
+
 @frappe.whitelist(allow_guest=False)
-def get_department(name):
+def get_department(name, company=None):
     """Get a single department with its employee list."""
-    dept = frappe.get_doc("Department", name)
+    dept = _get_department(name, company)
 
     # Get all employees in this department
     employees = frappe.get_list(
@@ -85,6 +103,7 @@ def get_department(name):
 @frappe.whitelist(allow_guest=False)
 def create_department(department_name, parent_department=None, company=None):
     """Create a new Department."""
+    _check_parent(parent_department, company)
     doc = frappe.new_doc("Department")
     doc.department_name = department_name
     if parent_department:
@@ -97,9 +116,10 @@ def create_department(department_name, parent_department=None, company=None):
 
 
 @frappe.whitelist(allow_guest=False)
-def update_department(name, department_name=None, parent_department=None):
+def update_department(name, department_name=None, parent_department=None, company=None):
     """Update an existing Department."""
-    doc = frappe.get_doc("Department", name)
+    doc = _get_department(name, company)
+    _check_parent(parent_department, company)
     if department_name:
         doc.department_name = department_name
     if parent_department is not None:
@@ -114,8 +134,9 @@ def update_department(name, department_name=None, parent_department=None):
 
 
 @frappe.whitelist(allow_guest=False)
-def delete_department(name):
+def delete_department(name, company=None):
     """Delete a Department."""
+    _get_department(name, company)
     # Check if any active employees are assigned
     count = frappe.db.count("Employee", {"department": name, "status": "Active"})
     if count > 0:
@@ -1,18 +1,41 @@
 import frappe
+from frappe.utils import cint
+
+from hr_core_ext.api import paging
+
+
+# Fields get_employees can be sorted by, mapped to their columns.
+EMPLOYEE_SORTABLE = {
+    "employee_id": "name",
+    "employee_name": "employee_name",
+    "department": "department",
+    "designation": "designation",
+    "date_of_joining": "date_of_joining",
+}
 
 
 @frappe.whitelist(allow_guest=False)
-def get_employees(company=None, employee_id=None, filters=None, limit_page_length=20, limit_start=0):
-    """Get list of employees with basic fields."""
-    f = filters or {}
+def get_employees(company=None, employee_id=None, filters=None, limit_page_length=20, limit_start=0,
+                  department=None, designation=None, status=None, employee_ids=None, order_by=None,
+                  with_total=0):
+    """Get list of employees with basic fields. See paging.py for the paging arguments."""
+    f = frappe.parse_json(filters) if isinstance(filters, str) else dict(filters or {})
     if company:
         f["company"] = company
     if employee_id:
         f["name"] = employee_id
-
-    employees = frappe.get_list(
+    if department:
+        f["department"] = department
+    if designation:
+        f["designation"] = designation
+    if status:
+        f["status"] = status
+    paging.employee_filter(f, employee_ids, field="name")
+
+    page = paging.get_page(
         "Employee",
-        fields=[
+        f,
+        [
             "name",
             "employee_name",
             "department",
@@ -22,15 +45,15 @@ def get_employees(company=None, employee_id=None, filters=None, limit_page_lengt
             "date_of_joining",
             "image",
         ],
-        filters=f,
-        limit_page_length=int(limit_page_length),
-        limit_start=int(limit_start),
-        order_by="employee_name asc",
+        paging.order_by(order_by, EMPLOYEE_SORTABLE, "employee_name asc"),
+        limit_page_length,
+        limit_start,
+        with_total,
     )
     # Map 'name' to 'employee_id' for frontend consistency
-    for emp in employees:
+    for emp in paging.rows_of(page):
         emp["employee_id"] = emp["name"]
-    return employees
+    return page
 
 
 @frappe.whitelist(allow_guest=False)
@@ -157,6 +180,88 @@ def update_employee(employee_id, **kwargs):
     return {"employee_id": employee.name, "status": "updated"}
 
 
+@frappe.whitelist(allow_guest=False)
+def get_reporting_lines(company=None):
+    """Get reports_to / leave_approver links for all active employees (manager scoping)."""
+    filters = {"status": "Active"}
+    if company:
+        filters["company"] = company
+
+    employees = frappe.get_list(
+        "Employee",
+        filters=filters,
+        fields=["name", "reports_to", "leave_approver", "user_id"],
+        limit_page_length=0,
+    )
+
+    return [
+        {
+            "employee_id": e.name,
+            "reports_to": e.reports_to or "",
+            "leave_approver": e.leave_approver or "",
+            "user_id": e.user_id or "",
+        }
+        for e in employees
+    ]
+
+
+# Columns of the BFF's employee directory (its employees_cache table), besides name and
+# modified.
+DIRECTORY_FIELDS = (
+    "employee_name",
+    "first_name",
+    "last_name",
+    "department",
+    "designation",
+    "status",
+    "branch",
+    "employment_type",
+    "company_email",
+    "reports_to",
+    "image",
+    "date_of_joining",
+)
+
+
+@frappe.whitelist(allow_guest=False)
+def get_employee_directory(company, employee_id=None, modified_after=None, after=None, limit_page_length=500):
+    """Employees of every status in the order they were last modified, for the BFF to keep
+    its directory in sync. Pass the modified and employee_id of the last row received as
+    modified_after and after to get the ones changed since; employee_id fetches one.
+
+    modified is formatted with microseconds so the BFF can hand it back unchanged."""
+    conditions = ["company = %(company)s"]
+    values = {"company": company, "limit": min(cint(limit_page_length) or 500, paging.MAX_PAGE_LENGTH)}
+    if employee_id:
+        conditions.append("name = %(employee_id)s")
+        values["employee_id"] = employee_id
+    if modified_after:
+        # (modified, name) is unique, so a batch ending among rows saved in the same
+        # microsecond continues after the last one rather than repeating or skipping them
+        conditions.append("(modified > %(modified_after)s or (modified = %(modified_after)s and name > %(after)s))")
+        values["modified_after"] = modified_after
+        values["after"] = after or ""
+
+    rows = frappe.db.sql(
+        f"""select name, modified, {", ".join(DIRECTORY_FIELDS)}
+        from `tabEmployee`
+        where {" and ".join(conditions)}
+        order by modified asc, name asc
+        limit %(limit)s""",
+        values,
+        as_dict=True,
+    )
+    return [
+        {
+            "employee_id": r.name,
+            "modified": r.modified.strftime("%Y-%m-%d %H:%M:%S.%f"),
+            **{f: r[f] for f in DIRECTORY_FIELDS if f != "date_of_joining"},
+            "date_of_joining": str(r.date_of_joining) if r.date_of_joining else None,
+        }
+        for r in rows
+    ]
+
+
 @frappe.whitelist(allow_guest=False)
 def validate_manager(employee_id, manager_id):
     """Check if assigning manager_id as manager of employee_id would create a circular chain."""
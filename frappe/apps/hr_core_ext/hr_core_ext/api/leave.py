@@ -1,5 +1,18 @@
 import frappe
 
+from hr_core_ext.api import paging
+
+
+# Fields get_leave_applications can be sorted by, mapped to their columns.
+LEAVE_SORTABLE = {
+    "employee_name": "employee_name",
+    "leave_type": "leave_type",
+    "from_date": "from_date",
+    "to_date": "to_date",
+    "posting_date": "posting_date",
+    "status": "status",
+}
+
 
 @frappe.whitelist(allow_guest=False)
 def get_leave_balance(employee_id):
@@ -15,17 +28,22 @@ def get_leave_balance(employee_id):
 
 
 @frappe.whitelist(allow_guest=False)
-def get_leave_applications(employee_id=None, status=None, limit_page_length=20):
-    """Get leave applications with optional filters."""
+def get_leave_applications(employee_id=None, status=None, limit_page_length=20, company=None,
+                           limit_start=0, employee_ids=None, order_by=None, with_total=0):
+    """Get leave applications with optional filters. See paging.py for the paging arguments."""
     filters = {}
+    if company:
+        filters["company"] = company
     if employee_id:
         filters["employee"] = employee_id
     if status:
         filters["status"] = status
+    paging.employee_filter(filters, employee_ids)
 
-    applications = frappe.get_list(
+    return paging.get_page(
         "Leave Application",
-        fields=[
+        filters,
+        [
             "name",
             "employee",
             "employee_name",
@@ -37,11 +55,11 @@ def get_leave_applications(employee_id=None, status=None, limit_page_length=20):
             "posting_date",
             "description",
         ],
-        filters=filters,
-        limit_page_length=int(limit_page_length),
-        order_by="posting_date desc",
+        paging.order_by(order_by, LEAVE_SORTABLE, "posting_date desc"),
+        limit_page_length,
+        limit_start,
+        with_total,
     )
-    return applications
 
 
 @frappe.whitelist(allow_guest=False)
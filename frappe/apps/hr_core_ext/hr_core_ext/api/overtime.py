@@ -1,10 +1,20 @@
 import frappe
 
+from hr_core_ext.api import paging, settings as company_settings
+
+
+# Fields get_ot_requests can be sorted by, mapped to their columns.
+OT_SORTABLE = {
+    "employee_name": "employee_name",
+    "ot_date": "payroll_date",
+    "amount": "amount",
+}
+
 
 # ── OT Settings ──────────────────────────────────────────────
 
-def _get_ot_settings():
-    """Get OT settings or Thai labour law defaults."""
+def _get_ot_settings(company=None):
+    """Get a company's OT settings or Thai labour law defaults."""
     defaults = {
         "weekday_ot_rate": 1.5,        # Overtime on regular working day
         "holiday_work_monthly": 1.0,    # Holiday work for monthly-paid employees
@@ -13,16 +23,7 @@ def _get_ot_settings():
         "standard_hours_per_day": 8,
         "standard_working_days": 26,
     }
-    stored = {}
-    try:
-        rows = frappe.db.sql(
-            "SELECT field, value FROM tabSingles WHERE doctype='OT Settings'",
-            as_dict=True
-        )
-        for r in rows:
-            stored[r["field"]] = r["value"]
-    except Exception:
-        pass
+    stored = company_settings.read("OT Settings", company)
 
     return {
         "weekday_ot_rate": float(stored.get("weekday_ot_rate", defaults["weekday_ot_rate"])),
@@ -34,35 +35,21 @@ def _get_ot_settings():
     }
 
 
-def _save_ot_settings(settings):
-    """Save OT settings to Singles table."""
-    for key, value in settings.items():
-        existing = frappe.db.sql(
-            "SELECT value FROM tabSingles WHERE doctype='OT Settings' AND field=%s",
-            (key,), as_dict=True
-        )
-        if existing:
-            frappe.db.sql(
-                "UPDATE tabSingles SET value=%s WHERE doctype='OT Settings' AND field=%s",
-                (str(value), key)
-            )
-        else:
-            frappe.db.sql(
-                "INSERT INTO tabSingles (doctype, field, value) VALUES ('OT Settings', %s, %s)",
-                (key, str(value))
-            )
+def _save_ot_settings(company, settings):
+    """Save a company's OT settings."""
+    company_settings.save("OT Settings", company, settings)
 
 
 @frappe.whitelist(allow_guest=False)
-def get_ot_config():
-    """Get OT configuration."""
-    return _get_ot_settings()
+def get_ot_config(company=None):
+    """Get the OT configuration of a company."""
+    return _get_ot_settings(company)
 
 
 @frappe.whitelist(allow_guest=False)
-def update_ot_config(**kwargs):
-    """Update OT configuration."""
-    settings = _get_ot_settings()
+def update_ot_config(company=None, **kwargs):
+    """Update the OT configuration of a company."""
+    settings = _get_ot_settings(company)
 
     for key in settings:
         if key in kwargs and kwargs[key] is not None:
@@ -71,7 +58,7 @@ def update_ot_config(**kwargs):
             else:
                 settings[key] = float(kwargs[key])
 
-    _save_ot_settings(settings)
+    _save_ot_settings(company, settings)
     frappe.db.commit()
     return settings
 
@@ -134,20 +121,30 @@ def create_ot_request(employee_id, ot_date, ot_type, hours, reason=None):
 
 
 @frappe.whitelist(allow_guest=False)
-def get_ot_requests(employee_id=None, status=None, month=None, year=None):
-    """List OT requests."""
+def get_ot_requests(employee_id=None, status=None, month=None, year=None, company=None,
+                    limit_page_length=0, limit_start=0, employee_ids=None, order_by=None, with_total=0):
+    """List OT requests. See paging.py for the paging arguments."""
     filters = {
         "salary_component": "Overtime",
         "docstatus": ["!=", 2],
     }
+    if company:
+        filters["company"] = company
     if employee_id:
         filters["employee"] = employee_id
-
+    paging.employee_filter(filters, employee_ids)
+    if month and year:
+        import calendar
+        year, month = int(year), int(month)
+        last_day = calendar.monthrange(year, month)[1]
+        filters["payroll_date"] = ["between", [f"{year}-{month:02d}-01", f"{year}-{month:02d}-{last_day}"]]
+
+    # The status lives in the notes JSON, so it is filtered and paged here rather than in SQL.
     requests = frappe.get_list(
         "Additional Salary",
         filters=filters,
         fields=["name", "employee", "employee_name", "payroll_date", "amount", "notes", "docstatus"],
-        order_by="payroll_date desc",
+        order_by=paging.order_by(order_by, OT_SORTABLE, "payroll_date desc"),
         limit_page_length=0,
     )
 
@@ -162,13 +159,6 @@ def get_ot_requests(employee_id=None, status=None, month=None, year=None):
         if status and req_status != status:
             continue
 
-        if month and year:
-            from datetime import date
-            rd = r.payroll_date
-            if hasattr(rd, 'month'):
-                if rd.month != int(month) or rd.year != int(year):
-                    continue
-
         result.append({
             "name": r.name,
             "employee": r.employee,
@@ -182,7 +172,7 @@ def get_ot_requests(employee_id=None, status=None, month=None, year=None):
             "amount": float(r.amount or 0),
         })
 
-    return result
+    return paging.slice_page(result, limit_page_length, limit_start, with_total)
 
 
 @frappe.whitelist(allow_guest=False)
@@ -198,7 +188,7 @@ def approve_ot_request(request_id):
         frappe.throw("Can only approve pending OT requests")
 
     # Calculate OT amount
-    settings = _get_ot_settings()
+    settings = _get_ot_settings(doc.company)
     from hr_core_ext.api.social_security import _get_employee_base_salary
 
     payroll_date = doc.payroll_date
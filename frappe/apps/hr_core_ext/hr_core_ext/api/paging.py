@@ -0,0 +1,69 @@
+import frappe
+from frappe.utils import cint
+
+
+# Upper bound on limit_page_length for callers that ask for a total. The BFF caps
+# its own page size well below this; the bound only stops a runaway query.
+MAX_PAGE_LENGTH = 500
+
+
+def employee_filter(filters, employee_ids, field="employee"):
+    """Restrict filters to a JSON list of employees. An empty list matches nothing."""
+    if employee_ids is None or employee_ids == "":
+        return filters
+    ids = frappe.parse_json(employee_ids) if isinstance(employee_ids, str) else employee_ids
+    if not isinstance(ids, list):
+        frappe.throw("employee_ids must be a JSON list")
+    filters[field] = ["in", ids or [""]]
+    return filters
+
+
+def order_by(requested, sortable, default):
+    """Validate a "<field> asc|desc" ordering against sortable, which maps the field
+    names callers use to columns. The document name breaks ties so pages are stable."""
+    if not requested:
+        return default
+    parts = requested.split()
+    direction = parts[1].lower() if len(parts) == 2 else "asc"
+    if len(parts) > 2 or parts[0] not in sortable or direction not in ("asc", "desc"):
+        frappe.throw(f"Cannot sort by '{requested}'")
+    return f"{sortable[parts[0]]} {direction}, name {direction}"
+
+
+def page_length(limit_page_length):
+    length = cint(limit_page_length)
+    if length < 0 or length > MAX_PAGE_LENGTH:
+        frappe.throw(f"limit_page_length must be between 0 and {MAX_PAGE_LENGTH}")
+    return length
+
+
+def get_page(doctype, filters, fields, order, limit_page_length, limit_start, with_total):
+    """frappe.get_list for one page. With with_total it returns {"rows", "total"},
+    otherwise the bare list older callers expect."""
+    length = page_length(limit_page_length) if cint(with_total) else cint(limit_page_length)
+    rows = frappe.get_list(
+        doctype,
+        fields=fields,
+        filters=filters,
+        order_by=order,
+        limit_page_length=length,
+        limit_start=cint(limit_start),
+    )
+    if not cint(with_total):
+        return rows
+    return {"rows": rows, "total": frappe.db.count(doctype, filters)}
+
+
+def slice_page(rows, limit_page_length, limit_start, with_total):
+    """Page a list that had to be filtered in Python, in the same shapes as get_page."""
+    start = cint(limit_start)
+    length = page_length(limit_page_length) if cint(with_total) else cint(limit_page_length)
+    page = rows[start:start + length] if length else rows[start:]
+    if not cint(with_total):
+        return page
+    return {"rows": page, "total": len(rows)}
+
+
+def rows_of(page):
+    """The rows of a get_page or slice_page result, for post-processing in place."""
+    return page["rows"] if isinstance(page, dict) else page
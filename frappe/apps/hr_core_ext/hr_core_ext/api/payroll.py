@@ -1,5 +1,17 @@
 import frappe
 
+from hr_core_ext.api import paging
+
+
+# Fields get_salary_slips can be sorted by, mapped to their columns.
+SLIP_SORTABLE = {
+    "employee_name": "employee_name",
+    "start_date": "start_date",
+    "posting_date": "posting_date",
+    "gross_pay": "gross_pay",
+    "net_pay": "net_pay",
+}
+
 
 @frappe.whitelist(allow_guest=False)
 def setup_employee_payroll(employee_id, base_salary, housing=0, transport=0):
@@ -58,13 +70,17 @@ def setup_employee_payroll(employee_id, base_salary, housing=0, transport=0):
 
 
 @frappe.whitelist(allow_guest=False)
-def get_salary_slips(employee_id=None, year=None, month=None):
-    """List salary slips filtered by employee and/or period."""
+def get_salary_slips(employee_id=None, year=None, month=None, company=None,
+                     limit_page_length=0, limit_start=0, employee_ids=None, order_by=None, with_total=0):
+    """List salary slips filtered by employee and/or period. See paging.py for the paging arguments."""
     filters = {}
+    if company:
+        filters["company"] = company
     if employee_id:
         if not frappe.db.exists("Employee", employee_id):
             frappe.throw(f"Employee {employee_id} not found", frappe.DoesNotExistError)
         filters["employee"] = employee_id
+    paging.employee_filter(filters, employee_ids)
 
     if year and month:
         year = int(year)
@@ -78,21 +94,23 @@ def get_salary_slips(employee_id=None, year=None, month=None):
         filters["start_date"] = [">=", f"{year}-01-01"]
         filters["end_date"] = ["<=", f"{year}-12-31"]
 
-    slips = frappe.get_list(
+    page = paging.get_page(
         "Salary Slip",
-        fields=[
+        filters,
+        [
             "name", "employee", "employee_name",
             "start_date", "end_date",
             "gross_pay", "total_deduction", "net_pay",
             "docstatus", "posting_date",
         ],
-        filters=filters,
-        order_by="start_date desc",
-        limit_page_length=0,
+        paging.order_by(order_by, SLIP_SORTABLE, "start_date desc"),
+        limit_page_length,
+        limit_start,
+        with_total,
     )
 
     # Map docstatus to human-readable status
-    for slip in slips:
+    for slip in paging.rows_of(page):
         if slip.docstatus == 0:
             slip["status"] = "Draft"
         elif slip.docstatus == 1:
@@ -106,7 +124,7 @@ def get_salary_slips(employee_id=None, year=None, month=None):
         slip["total_deduction"] = float(slip["total_deduction"] or 0)
         slip["net_pay"] = float(slip["net_pay"] or 0)
 
-    return slips
+    return page
 
 
 @frappe.whitelist(allow_guest=False)
@@ -139,6 +157,7 @@ def get_salary_slip_detail(slip_id):
         "name": doc.name,
         "employee": doc.employee,
         "employee_name": doc.employee_name,
+        "company": doc.company,
         "start_date": str(doc.start_date),
         "end_date": str(doc.end_date),
         "posting_date": str(doc.posting_date) if doc.posting_date else None,
@@ -339,6 +358,8 @@ def submit_payroll(month, year, company=None):
         "end_date": end_date,
         "docstatus": 0,  # Draft only
     }
+    if company:
+        filters["company"] = company
 
     slips = frappe.get_list(
         "Salary Slip",
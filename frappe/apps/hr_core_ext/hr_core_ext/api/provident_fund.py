@@ -1,26 +1,19 @@
 import frappe
 
+from hr_core_ext.api import settings as company_settings
+
 
 # ── PVD Settings ─────────────────────────────────────────────
 
-def _get_pvd_settings():
-    """Get PVD settings or defaults."""
+def _get_pvd_settings(company=None):
+    """Get a company's PVD settings or defaults."""
     defaults = {
         "min_rate": 3.0,
         "max_rate": 15.0,
         "default_employee_rate": 5.0,
         "default_employer_rate": 5.0,
     }
-    stored = {}
-    try:
-        rows = frappe.db.sql(
-            "SELECT field, value FROM tabSingles WHERE doctype='PVD Settings'",
-            as_dict=True
-        )
-        for r in rows:
-            stored[r["field"]] = r["value"]
-    except Exception:
-        pass
+    stored = company_settings.read("PVD Settings", company)
 
     return {
         "min_rate": float(stored.get("min_rate", defaults["min_rate"])),
@@ -30,35 +23,21 @@ def _get_pvd_settings():
     }
 
 
-def _save_pvd_settings(settings):
-    """Save PVD settings to Singles table."""
-    for key, value in settings.items():
-        existing = frappe.db.sql(
-            "SELECT value FROM tabSingles WHERE doctype='PVD Settings' AND field=%s",
-            (key,), as_dict=True
-        )
-        if existing:
-            frappe.db.sql(
-                "UPDATE tabSingles SET value=%s WHERE doctype='PVD Settings' AND field=%s",
-                (str(value), key)
-            )
-        else:
-            frappe.db.sql(
-                "INSERT INTO tabSingles (doctype, field, value) VALUES ('PVD Settings', %s, %s)",
-                (key, str(value))
-            )
+def _save_pvd_settings(company, settings):
+    """Save a company's PVD settings."""
+    company_settings.save("PVD Settings", company, settings)
 
 
 @frappe.whitelist(allow_guest=False)
-def get_pvd_config():
-    """Get PVD configuration."""
-    return _get_pvd_settings()
+def get_pvd_config(company=None):
+    """Get the PVD configuration of a company."""
+    return _get_pvd_settings(company)
 
 
 @frappe.whitelist(allow_guest=False)
-def update_pvd_config(min_rate=None, max_rate=None, default_employee_rate=None, default_employer_rate=None):
-    """Update PVD configuration."""
-    settings = _get_pvd_settings()
+def update_pvd_config(min_rate=None, max_rate=None, default_employee_rate=None, default_employer_rate=None, company=None):
+    """Update the PVD configuration of a company."""
+    settings = _get_pvd_settings(company)
 
     if min_rate is not None:
         settings["min_rate"] = float(min_rate)
@@ -69,7 +48,7 @@ def update_pvd_config(min_rate=None, max_rate=None, default_employee_rate=None,
     if default_employer_rate is not None:
         settings["default_employer_rate"] = float(default_employer_rate)
 
-    _save_pvd_settings(settings)
+    _save_pvd_settings(company, settings)
     frappe.db.commit()
     return settings
 
@@ -80,7 +59,7 @@ def enroll_employee_pvd(employee_id, employee_rate=None, employer_rate=None):
     if not frappe.db.exists("Employee", employee_id):
         frappe.throw(f"Employee {employee_id} not found", frappe.DoesNotExistError)
 
-    settings = _get_pvd_settings()
+    settings = _get_pvd_settings(company_settings.employee_company(employee_id))
     emp_rate = float(employee_rate) if employee_rate else settings["default_employee_rate"]
     emr_rate = float(employer_rate) if employer_rate else settings["default_employer_rate"]
 
@@ -112,7 +91,7 @@ def update_employee_pvd(employee_id, employee_rate=None, employer_rate=None):
     if not frappe.db.exists("Employee", employee_id):
         frappe.throw(f"Employee {employee_id} not found", frappe.DoesNotExistError)
 
-    settings = _get_pvd_settings()
+    settings = _get_pvd_settings(company_settings.employee_company(employee_id))
     updates = {}
 
     if employee_rate is not None:
@@ -212,14 +191,18 @@ def calculate_pvd_contribution(employee_id, month, year):
 
 
 @frappe.whitelist(allow_guest=False)
-def get_pvd_report(month, year):
+def get_pvd_report(month, year, company=None):
     """Get PVD report for all enrolled employees."""
     month = int(month)
     year = int(year)
 
+    filters = {"status": "Active", "pvd_enrolled": 1}
+    if company:
+        filters["company"] = company
+
     employees = frappe.get_list(
         "Employee",
-        filters={"status": "Active", "pvd_enrolled": 1},
+        filters=filters,
         fields=["name", "employee_name", "pvd_employee_rate", "pvd_employer_rate"],
         limit_page_length=0,
     )
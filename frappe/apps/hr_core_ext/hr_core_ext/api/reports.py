@@ -176,6 +176,8 @@ def get_payroll_report(year, month=None, company=None):
         "end_date": ["<=", f"{year}-12-31"],
         "docstatus": ["in", [0, 1]],
     }
+    if company:
+        filters["company"] = company
     if month:
         month = int(month)
         import calendar
@@ -209,14 +211,14 @@ def get_payroll_report(year, month=None, company=None):
 
 
 @frappe.whitelist(allow_guest=False)
-def get_tax_report(year, month=None):
+def get_tax_report(year, month=None, company=None):
     """Get tax summary report."""
     from hr_core_ext.api.tax import get_pnd1_data
 
     year = int(year)
 
     if month:
-        return get_pnd1_data(int(month), year)
+        return get_pnd1_data(int(month), year, company=company)
 
     # Annual summary
     monthly_totals = []
@@ -225,7 +227,7 @@ def get_tax_report(year, month=None):
 
     for m in range(1, 13):
         try:
-            pnd1 = get_pnd1_data(m, year)
+            pnd1 = get_pnd1_data(m, year, company=company)
             monthly_totals.append({
                 "month": m,
                 "total_income": pnd1["total_income"],
@@ -0,0 +1,57 @@
+import frappe
+
+
+# Settings are key-value rows in tabSingles. Each company's rows are kept under
+# "<doctype>::<company>"; rows under the bare doctype, seeded by setup, are the
+# site-wide defaults a company reads until it saves its own.
+
+def _scoped(doctype, company):
+    return f"{doctype}::{company}"
+
+
+def _rows(doctype):
+    try:
+        rows = frappe.db.sql(
+            "SELECT field, value FROM tabSingles WHERE doctype=%s",
+            (doctype,), as_dict=True
+        )
+    except Exception:
+        return {}
+    return {r["field"]: r["value"] for r in rows}
+
+
+def read(doctype, company=None):
+    """Stored values of doctype for company, over the site-wide defaults."""
+    stored = _rows(doctype)
+    if company:
+        stored.update(_rows(_scoped(doctype, company)))
+    return stored
+
+
+def save(doctype, company, settings):
+    """Save settings for company only. Other companies keep their own values."""
+    if not company:
+        frappe.throw("company is required")
+    if not frappe.db.exists("Company", company):
+        frappe.throw(f"Company {company} not found", frappe.DoesNotExistError)
+
+    scoped = _scoped(doctype, company)
+    for key, value in settings.items():
+        existing = frappe.db.sql(
+            "SELECT value FROM tabSingles WHERE doctype=%s AND field=%s",
+            (scoped, key), as_dict=True
+        )
+        if existing:
+            frappe.db.sql(
+                "UPDATE tabSingles SET value=%s WHERE doctype=%s AND field=%s",
+                (str(value), scoped, key)
+            )
+        else:
+            frappe.db.sql(
+                "INSERT INTO tabSingles (doctype, field, value) VALUES (%s, %s, %s)",
+                (scoped, key, str(value))
+            )
+
+
+def employee_company(employee_id):
+    return frappe.db.get_value("Employee", employee_id, "company")
@@ -1,12 +1,45 @@
 import frappe
 from frappe.utils import today, getdate, get_datetime
 
+from hr_core_ext.api import paging
+
+
+# Fields the shift list methods can be sorted by, mapped to their columns.
+SHIFT_ASSIGNMENT_SORTABLE = {
+    "employee_name": "employee_name",
+    "shift_type": "shift_type",
+    "start_date": "start_date",
+    "end_date": "end_date",
+}
+SHIFT_REQUEST_SORTABLE = {
+    "employee_name": "employee_name",
+    "shift_type": "shift_type",
+    "from_date": "from_date",
+    "to_date": "to_date",
+    "status": "status",
+}
+
+
+def _check_shift_type(shift_type, company=None):
+    """Return the company owning shift_type, or None for the shared defaults every company
+    can use. With company, shift types of other companies are not found."""
+    if not frappe.db.exists("Shift Type", shift_type):
+        frappe.throw(f"Shift Type '{shift_type}' not found", frappe.DoesNotExistError)
+    owner = frappe.db.get_value("Shift Type", shift_type, "company")
+    if company and owner and owner != company:
+        frappe.throw(f"Shift Type '{shift_type}' not found", frappe.DoesNotExistError)
+    return owner
+
 
 @frappe.whitelist(allow_guest=False)
-def get_shift_types():
-    """List all shift types."""
+def get_shift_types(company=None):
+    """List the shared shift types and, with company, that company's own."""
+    or_filters = None
+    if company:
+        or_filters = [["company", "=", company], ["company", "is", "not set"]]
     shifts = frappe.get_list(
         "Shift Type",
+        or_filters=or_filters,
         fields=[
             "name", "start_time", "end_time", "holiday_list",
             "late_entry_grace_period", "early_exit_grace_period",
@@ -26,8 +59,8 @@ def get_shift_types():
 def create_shift_type(name, start_time, end_time,
                       late_entry_grace_period=15,
                       early_exit_grace_period=15,
-                      holiday_list=None):
-    """Create a new shift type."""
+                      holiday_list=None, company=None):
+    """Create a shift type. With company it belongs to that company; without, it is shared."""
     if frappe.db.exists("Shift Type", name):
         frappe.throw(f"Shift Type '{name}' already exists")
 
@@ -47,6 +80,8 @@ def create_shift_type(name, start_time, end_time,
     }
     if holiday_list:
         doc_data["holiday_list"] = holiday_list
+    if company:
+        doc_data["company"] = company
 
     doc = frappe.get_doc(doc_data)
     doc.insert(ignore_permissions=True)
@@ -61,10 +96,12 @@ def create_shift_type(name, start_time, end_time,
 
 @frappe.whitelist(allow_guest=False)
 def update_shift_type(shift_type_name, start_time=None, end_time=None,
-                      late_entry_grace_period=None, early_exit_grace_period=None):
-    """Update an existing shift type."""
-    if not frappe.db.exists("Shift Type", shift_type_name):
-        frappe.throw(f"Shift Type '{shift_type_name}' not found", frappe.DoesNotExistError)
+                      late_entry_grace_period=None, early_exit_grace_period=None, company=None):
+    """Update a shift type. With company, only that company's own shift types can be changed."""
+    owner = _check_shift_type(shift_type_name, company)
+    if company and not owner:
+        frappe.throw(f"Shift Type '{shift_type_name}' is shared by every company and cannot be changed",
+                     frappe.PermissionError)
 
     doc = frappe.get_doc("Shift Type", shift_type_name)
     if start_time is not None:
@@ -89,8 +126,10 @@ def update_shift_type(shift_type_name, start_time=None, end_time=None,
 
 
 @frappe.whitelist(allow_guest=False)
-def get_shift_assignments(employee_id=None, shift_type=None, date=None, company=None):
-    """List shift assignments with optional filters."""
+def get_shift_assignments(employee_id=None, shift_type=None, date=None, company=None,
+                          limit_page_length=100, limit_start=0, employee_ids=None, order_by=None,
+                          with_total=0):
+    """List shift assignments with optional filters. See paging.py for the paging arguments."""
     filters = {"docstatus": 1}
 
     if employee_id:
@@ -99,33 +138,38 @@ def get_shift_assignments(employee_id=None, shift_type=None, date=None, company=
         filters["shift_type"] = shift_type
     if company:
         filters["company"] = company
+    paging.employee_filter(filters, employee_ids)
 
-    assignments = frappe.get_list(
-        "Shift Assignment",
-        filters=filters,
-        fields=[
-            "name", "employee", "employee_name", "shift_type",
-            "start_date", "end_date", "company", "docstatus",
-        ],
-        order_by="start_date desc",
-        limit_page_length=100,
-    )
+    fields = [
+        "name", "employee", "employee_name", "shift_type",
+        "start_date", "end_date", "company", "docstatus",
+    ]
+    order = paging.order_by(order_by, SHIFT_ASSIGNMENT_SORTABLE, "start_date desc")
 
-    # If date filter provided, filter in Python (Frappe doesn't support complex date logic in filters easily)
     if date:
+        # Open-ended assignments have no end_date, which a plain filter cannot express,
+        # so the end of the range is checked and the result paged here.
         target = getdate(date)
+        filters["start_date"] = ["<=", target]
+        assignments = frappe.get_list(
+            "Shift Assignment", filters=filters, fields=fields, order_by=order, limit_page_length=0,
+        )
         assignments = [
             a for a in assignments
-            if getdate(a["start_date"]) <= target
-            and (not a["end_date"] or getdate(a["end_date"]) >= target)
+            if not a["end_date"] or getdate(a["end_date"]) >= target
         ]
+        page = paging.slice_page(assignments, limit_page_length, limit_start, with_total)
+    else:
+        page = paging.get_page(
+            "Shift Assignment", filters, fields, order, limit_page_length, limit_start, with_total,
+        )
 
-    for a in assignments:
+    for a in paging.rows_of(page):
         a["start_date"] = str(a["start_date"])
         a["end_date"] = str(a["end_date"]) if a["end_date"] else None
         a["status"] = "Active" if a["docstatus"] == 1 else "Cancelled"
 
-    return assignments
+    return page
 
 
 @frappe.whitelist(allow_guest=False)
@@ -138,8 +182,7 @@ def assign_shift(employee_id, shift_type, start_date, end_date=None, company=Non
     if emp.status != "Active":
         frappe.throw(f"Employee '{employee_id}' is not Active")
 
-    if not frappe.db.exists("Shift Type", shift_type):
-        frappe.throw(f"Shift Type '{shift_type}' not found", frappe.DoesNotExistError)
+    _check_shift_type(shift_type, emp.company)
 
     # Get company from employee if not provided
     if not company:
@@ -197,12 +240,14 @@ def assign_shift(employee_id, shift_type, start_date, end_date=None, company=Non
 
 
 @frappe.whitelist(allow_guest=False)
-def unassign_shift(assignment_id):
-    """Cancel a shift assignment."""
+def unassign_shift(assignment_id, company=None):
+    """Cancel a shift assignment. With company, assignments of other companies are not found."""
     if not frappe.db.exists("Shift Assignment", assignment_id):
         frappe.throw(f"Shift Assignment '{assignment_id}' not found", frappe.DoesNotExistError)
 
     doc = frappe.get_doc("Shift Assignment", assignment_id)
+    if company and doc.company != company:
+        frappe.throw(f"Shift Assignment '{assignment_id}' not found", frappe.DoesNotExistError)
     if doc.docstatus != 1:
         frappe.throw("Only submitted assignments can be cancelled")
 
@@ -213,30 +258,37 @@ def unassign_shift(assignment_id):
 
 
 @frappe.whitelist(allow_guest=False)
-def get_shift_requests(employee_id=None, status=None):
-    """List shift change requests."""
+def get_shift_requests(employee_id=None, status=None, company=None,
+                       limit_page_length=100, limit_start=0, employee_ids=None, order_by=None,
+                       with_total=0):
+    """List shift change requests. See paging.py for the paging arguments."""
     filters = {}
+    if company:
+        filters["company"] = company
     if employee_id:
         filters["employee"] = employee_id
     if status:
         filters["status"] = status
+    paging.employee_filter(filters, employee_ids)
 
-    requests = frappe.get_list(
+    page = paging.get_page(
         "Shift Request",
-        filters=filters,
-        fields=[
+        filters,
+        [
             "name", "employee", "employee_name", "shift_type",
             "from_date", "to_date", "status", "approver",
         ],
-        order_by="creation desc",
-        limit_page_length=100,
+        paging.order_by(order_by, SHIFT_REQUEST_SORTABLE, "creation desc"),
+        limit_page_length,
+        limit_start,
+        with_total,
     )
 
-    for r in requests:
+    for r in paging.rows_of(page):
         r["from_date"] = str(r["from_date"])
         r["to_date"] = str(r["to_date"])
 
-    return requests
+    return page
 
 
 @frappe.whitelist(allow_guest=False)
@@ -249,8 +301,7 @@ def create_shift_request(employee_id, shift_type, from_date, to_date, approver=N
     if emp.status != "Active":
         frappe.throw(f"Employee '{employee_id}' is not Active")
 
-    if not frappe.db.exists("Shift Type", shift_type):
-        frappe.throw(f"Shift Type '{shift_type}' not found", frappe.DoesNotExistError)
+    _check_shift_type(shift_type, emp.company)
 
     # Use reports_to's User email if no approver specified
     if not approver and emp.reports_to:
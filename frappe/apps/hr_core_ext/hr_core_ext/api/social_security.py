@@ -1,16 +1,18 @@
 import frappe
 
+from hr_core_ext.api import settings as company_settings
 
-# ── SSO Settings (Single Doctype simulation using site config) ─────
 
-def _get_sso_settings():
-    """Get SSO settings from Site Config or return defaults."""
+# ── SSO Settings ─────────────────────────────────────────────
+
+def _get_sso_settings(company=None):
+    """Get a company's SSO settings or the statutory defaults."""
     defaults = {
         "rate": 5.0,            # 5%
         "max_salary": 15000.0,  # Salary cap for SSO calculation
         "max_contribution": 750.0,  # Maximum monthly contribution
     }
-    stored = frappe.db.get_singles_dict("SSO Settings") if frappe.db.exists("DocType", "SSO Settings") else {}
+    stored = company_settings.read("SSO Settings", company)
     return {
         "rate": float(stored.get("rate", defaults["rate"])),
         "max_salary": float(stored.get("max_salary", defaults["max_salary"])),
@@ -19,15 +21,15 @@ def _get_sso_settings():
 
 
 @frappe.whitelist(allow_guest=False)
-def get_sso_config():
-    """Get current SSO configuration."""
-    return _get_sso_settings()
+def get_sso_config(company=None):
+    """Get the SSO configuration of a company."""
+    return _get_sso_settings(company)
 
 
 @frappe.whitelist(allow_guest=False)
-def update_sso_config(rate=None, max_salary=None, max_contribution=None):
-    """Update SSO configuration."""
-    settings = _get_sso_settings()
+def update_sso_config(rate=None, max_salary=None, max_contribution=None, company=None):
+    """Update the SSO configuration of a company."""
+    settings = _get_sso_settings(company)
 
     if rate is not None:
         settings["rate"] = float(rate)
@@ -36,29 +38,14 @@ def update_sso_config(rate=None, max_salary=None, max_contribution=None):
     if max_contribution is not None:
         settings["max_contribution"] = float(max_contribution)
 
-    # Store in Singles table (custom approach using site config or custom doctype)
-    _save_sso_settings(settings)
+    _save_sso_settings(company, settings)
     frappe.db.commit()
     return settings
 
 
-def _save_sso_settings(settings):
-    """Save SSO settings. Uses a simple key-value approach in Singles."""
-    for key, value in settings.items():
-        existing = frappe.db.sql(
-            "SELECT value FROM tabSingles WHERE doctype='SSO Settings' AND field=%s",
-            (key,), as_dict=True
-        )
-        if existing:
-            frappe.db.sql(
-                "UPDATE tabSingles SET value=%s WHERE doctype='SSO Settings' AND field=%s",
-                (str(value), key)
-            )
-        else:
-            frappe.db.sql(
-                "INSERT INTO tabSingles (doctype, field, value) VALUES ('SSO Settings', %s, %s)",
-                (key, str(value))
-            )
+def _save_sso_settings(company, settings):
+    """Save a company's SSO settings."""
+    company_settings.save("SSO Settings", company, settings)
 
 
 @frappe.whitelist(allow_guest=False)
@@ -70,7 +57,7 @@ def calculate_sso_contribution(employee_id, month, year):
     month = int(month)
     year = int(year)
 
-    settings = _get_sso_settings()
+    settings = _get_sso_settings(company_settings.employee_company(employee_id))
     rate = settings["rate"] / 100.0
     max_salary = settings["max_salary"]
     max_contribution = settings["max_contribution"]
@@ -94,14 +81,18 @@ def calculate_sso_contribution(employee_id, month, year):
 
 
 @frappe.whitelist(allow_guest=False)
-def get_sso_report(month, year):
+def get_sso_report(month, year, company=None):
     """Get SSO report for all employees for a given month."""
     month = int(month)
     year = int(year)
 
+    filters = {"status": "Active"}
+    if company:
+        filters["company"] = company
+
     employees = frappe.get_list(
         "Employee",
-        filters={"status": "Active"},
+        filters=filters,
         fields=["name", "employee_name", "company"],
         limit_page_length=0,
     )
@@ -264,14 +264,18 @@ def get_employee_tax_summary(employee_id, year):
 
 
 @frappe.whitelist(allow_guest=False)
-def get_pnd1_data(month, year):
+def get_pnd1_data(month, year, company=None):
     """Get PND1 (ภ.ง.ด.1) report data for all employees."""
     month = int(month)
     year = int(year)
 
+    filters = {"status": "Active"}
+    if company:
+        filters["company"] = company
+
     employees = frappe.get_list(
         "Employee",
-        filters={"status": "Active"},
+        filters=filters,
         fields=["name", "employee_name"],
         limit_page_length=0,
     )
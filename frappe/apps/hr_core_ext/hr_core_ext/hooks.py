@@ -10,3 +10,18 @@ website_route_rules = []
 
 # Override whitelisted methods
 override_whitelisted_methods = {}
+
+# Tell the BFF about request and payslip changes, and about every employee change for
+# its directory (see webhooks.py)
+doc_events = {
+    doctype: {
+        "on_update": "hr_core_ext.webhooks.on_change",
+        "on_submit": "hr_core_ext.webhooks.on_change",
+        "on_cancel": "hr_core_ext.webhooks.on_change",
+    }
+    for doctype in ("Leave Application", "Salary Slip", "Shift Request", "Attendance Request")
+}
+doc_events["Employee"] = {
+    "on_update": "hr_core_ext.webhooks.on_change",
+    "on_trash": "hr_core_ext.webhooks.on_change",
+}
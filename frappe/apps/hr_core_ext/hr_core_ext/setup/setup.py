@@ -356,7 +356,7 @@ def setup_shift_types():
 
 
 def setup_custom_fields():
-    """Create custom fields on Employee for SSO, PVD, and Tax."""
+    """Create custom fields on Employee for SSO, PVD, and Tax, and the owning company on Shift Type."""
     custom_fields = {
         "Employee": [
             # SSO
@@ -388,7 +388,11 @@ def setup_custom_fields():
              "insert_after": "health_insurance_premium", "default": "0"},
             {"fieldname": "donation_deduction", "label": "Donation Deduction", "fieldtype": "Currency",
              "insert_after": "housing_loan_interest", "default": "0"},
-        ]
+        ],
+        "Shift Type": [
+            {"fieldname": "company", "label": "Company", "fieldtype": "Link", "options": "Company",
+             "insert_after": "holiday_list", "description": "Empty for shift types every company can use"},
+        ],
     }
 
     for doctype, fields in custom_fields.items():
@@ -411,7 +415,10 @@ def setup_custom_fields():
 
 
 def setup_default_settings():
-    """Initialize default settings for SSO, PVD, and OT in Singles table."""
+    """Initialize default settings for SSO, PVD, and OT in Singles table.
+
+    These are the site-wide defaults; a company reads them until it saves its own.
+    """
     settings_defaults = {
         "SSO Settings": {
             "rate": "5",
@@ -0,0 +1,123 @@
+"""Posts changes to request documents to the BFF, so users hear about approvals and
+payslips even when they happen in the desk UI or a scheduled job rather than the BFF.
+Employee changes are posted too, to keep the BFF's employee directory current.
+
+Set in common_site_config.json (see entrypoint.sh):
+    bff_webhook_url     the BFF's /api/webhooks/frappe endpoint
+    bff_webhook_secret  the shared signing secret, BFF_FRAPPE_WEBHOOK_SECRET on the BFF
+
+Each delivery carries X-Webhook-Id, X-Webhook-Timestamp (Unix seconds) and
+X-Webhook-Signature, "v1=" + hex HMAC-SHA256 of "<id>.<timestamp>.<body>" under the
+secret. Retries reuse the id, so the BFF acts on an event once.
+"""
+import hashlib
+import hmac
+import json
+import time
+
+import frappe
+import requests
+
+
+# Fields sent along with each doctype's event, besides employee, company and status.
+EVENT_FIELDS = {
+    "Leave Application": ("employee_name", "leave_type", "from_date", "to_date", "total_leave_days"),
+    "Salary Slip": ("employee_name", "start_date", "end_date", "net_pay"),
+    "Shift Request": ("employee_name", "shift_type", "from_date", "to_date"),
+    "Attendance Request": ("employee_name", "from_date", "to_date", "reason"),
+    "Employee": ("employee_name", "department", "designation"),
+}
+
+# Doctypes the BFF keeps a copy of: every save and deletion is sent, not only status
+# changes, and the BFF reads the document back rather than relying on the fields above.
+MIRRORED = {"Employee"}
+
+DELIVERY_ATTEMPTS = 3
+DELIVERY_TIMEOUT = 10
+
+
+def on_change(doc, method=None):
+    """doc_events hook for on_update, on_submit, on_cancel and on_trash. Only changes to
+    the status or docstatus of an existing document are sent, except for MIRRORED
+    doctypes."""
+    url = frappe.conf.get("bff_webhook_url")
+    secret = frappe.conf.get("bff_webhook_secret")
+    if not url or not secret or doc.doctype not in EVENT_FIELDS:
+        return
+
+    before = doc.get_doc_before_save()
+    if doc.doctype in MIRRORED:
+        before = before or frappe._dict()
+    elif not before or (before.get("status") == doc.get("status") and before.docstatus == doc.docstatus):
+        return
+
+    event = {
+        "id": frappe.generate_hash(length=32),
+        "event": method,
+        "doctype": doc.doctype,
+        "name": doc.name,
+        "company": doc.get("company"),
+        "employee": doc.get("employee"),
+        "status": doc.get("status") or "",
+        "docstatus": doc.docstatus,
+        "previous_status": before.get("status") or "",
+        "previous_docstatus": before.get("docstatus") or 0,
+        "origin": _origin(),
+        "user": frappe.session.user,
+        "fields": {f: doc.get(f) for f in EVENT_FIELDS[doc.doctype]},
+    }
+    frappe.enqueue(
+        "hr_core_ext.webhooks.deliver",
+        queue="short",
+        enqueue_after_commit=True,
+        event=event,
+    )
+
+
+def deliver(event):
+    """Background job: POST the event to the BFF, retrying failed attempts."""
+    url = frappe.conf.get("bff_webhook_url")
+    secret = frappe.conf.get("bff_webhook_secret")
+    if not url or not secret:
+        return
+
+    body = json.dumps(event, separators=(",", ":"), default=str)
+    for attempt in range(1, DELIVERY_ATTEMPTS + 1):
+        timestamp = str(int(time.time()))
+        try:
+            resp = requests.post(
+                url,
+                data=body.encode(),
+                headers={
+                    "Content-Type": "application/json",
+                    "X-Webhook-Id": event["id"],
+                    "X-Webhook-Timestamp": timestamp,
+                    "X-Webhook-Signature": sign(secret, event["id"], timestamp, body),
+                },
+                timeout=DELIVERY_TIMEOUT,
+            )
+            # 4xx means the BFF refused the event itself; sending it again will not help
+            if resp.status_code < 500:
+                if resp.status_code >= 400:
+                    frappe.log_error(f"BFF refused webhook {event['id']}: {resp.status_code} {resp.text}", "BFF webhook")
+                return
+        except requests.RequestException:
+            pass
+        if attempt < DELIVERY_ATTEMPTS:
+            time.sleep(2 ** attempt)
+
+    frappe.log_error(f"Could not deliver webhook {event['id']} for {event['doctype']} {event['name']}", "BFF webhook")
+
+
+def sign(secret, event_id, timestamp, body):
+    mac = hmac.new(secret.encode(), f"{event_id}.{timestamp}.{body}".encode(), hashlib.sha256)
+    return "v1=" + mac.hexdigest()
+
+
+def _origin():
+    """Where the change came from: "api" for token-authenticated calls such as the BFF's,
+    "desk" for a signed-in user, "system" for the scheduler and background jobs."""
+    if not getattr(frappe.local, "request", None):
+        return "system"
+    auth = frappe.get_request_header("Authorization") or ""
+    return "api" if auth.startswith("token ") else "desk"
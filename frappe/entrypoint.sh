@@ -52,7 +52,9 @@ cat > sites/common_site_config.json <<EOF
   "socketio_port": 9000,
   "webserver_port": 8000,
   "serve_default_site": true,
-  "allow_cors": "*"
+  "allow_cors": "*",
+  "bff_webhook_url": "${BFF_WEBHOOK_URL:-}",
+  "bff_webhook_secret": "${FRAPPE_WEBHOOK_SECRET:-}"
 }
 EOF
 
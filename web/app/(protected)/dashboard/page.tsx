@@ -15,6 +15,9 @@ import {
   checkout as apiCheckout,
   getPayrollSlips,
   getMyShift,
+  getProvisioningStatus,
+  retryProvisioning,
+  type ProvisioningStatus,
 } from "@/lib/api";
 import Badge, { statusVariant } from "@/components/ui/Badge";
 import { useToast } from "@/components/ui/Toast";
@@ -1035,6 +1038,56 @@ function EmployeeDashboard() {
 
 // ── Main Page ────────────────────────────────────────────────
 
+// ── Provisioning Banner ──────────────────────────────────────
+
+// Shown to admins until the company's Frappe setup has finished.
+function ProvisioningBanner() {
+  const t = useTranslations("dashboard");
+  const [status, setStatus] = useState<ProvisioningStatus | null>(null);
+  const [retrying, setRetrying] = useState(false);
+
+  useEffect(() => {
+    getProvisioningStatus()
+      .then(setStatus)
+      .catch(() => setStatus(null));
+  }, []);
+
+  if (!status || status.status === "ready") return null;
+
+  async function handleRetry() {
+    setRetrying(true);
+    try {
+      setStatus(await retryProvisioning());
+    } catch {
+      // keep the current status; the banner stays up
+    } finally {
+      setRetrying(false);
+    }
+  }
+
+  const failed = status.steps.find((s) => s.status === "failed");
+
+  return (
+    <div
+      className={`mb-6 p-4 rounded-lg text-sm ${
+        failed ? "bg-red-50 text-red-700" : "bg-yellow-50 text-yellow-800"
+      }`}
+    >
+      <p>{failed ? t("provisioningFailed", { error: failed.last_error ?? "" }) : t("provisioningInProgress")}</p>
+      {failed && (
+        <button
+          type="button"
+          onClick={handleRetry}
+          disabled={retrying}
+          className="mt-2 px-3 py-1 bg-red-600 text-white rounded-md hover:bg-red-700 disabled:opacity-50"
+        >
+          {retrying ? t("provisioningRetrying") : t("provisioningRetry")}
+        </button>
+      )}
+    </div>
+  );
+}
+
 export default function DashboardPage() {
   const { user } = useAuth();
   const t = useTranslations("dashboard");
@@ -1057,6 +1110,8 @@ export default function DashboardPage() {
         <p className="text-sm text-gray-400 hidden md:block">{today()}</p>
       </div>
 
+      {user.role === "admin" && <ProvisioningBanner />}
+
       {isAdminOrHR ? <AdminDashboard /> : <EmployeeDashboard />}
     </div>
   );
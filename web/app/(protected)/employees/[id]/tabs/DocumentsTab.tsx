@@ -1,7 +1,7 @@
 "use client";
 
 import { useEffect, useState } from "react";
-import { getEmployeeDocuments, deleteEmployeeDocument } from "@/lib/api";
+import { getEmployeeDocuments, deleteEmployeeDocument, employeeDocumentDownloadUrl } from "@/lib/api";
 import { useAuth } from "@/lib/auth-context";
 import EmptyState from "@/components/ui/EmptyState";
 
@@ -72,16 +72,12 @@ export default function DocumentsTab({ employeeId }: Props) {
                   {String(doc.creation || "")}
                 </td>
                 <td className="px-6 py-4 text-sm space-x-3">
-                  {doc.file_url ? (
-                    <a
-                      href={String(doc.file_url)}
-                      target="_blank"
-                      rel="noopener noreferrer"
-                      className="text-blue-600 hover:text-blue-800 text-xs font-medium"
-                    >
-                      Download
-                    </a>
-                  ) : null}
+                  <a
+                    href={employeeDocumentDownloadUrl(employeeId, String(doc.name))}
+                    className="text-blue-600 hover:text-blue-800 text-xs font-medium"
+                  >
+                    Download
+                  </a>
                   {isAdminOrHR && (
                     <button
                       onClick={() => handleDelete(String(doc.name))}
@@ -3,6 +3,36 @@
 import { AuthProvider, useAuth } from "@/lib/auth-context";
 import { ToastProvider } from "@/components/ui/Toast";
 import Sidebar from "@/components/ui/Sidebar";
+import { stopImpersonation } from "@/lib/api";
+import { useTranslations } from "@/lib/i18n";
+
+function ImpersonationBanner() {
+  const { user } = useAuth();
+  const t = useTranslations("common");
+  if (!user?.impersonation) return null;
+
+  async function handleStop() {
+    await stopImpersonation().catch(() => {});
+    window.location.href = "/users";
+  }
+
+  return (
+    <div className="bg-amber-100 border-b border-amber-300 px-8 py-2 flex items-center justify-between text-sm text-amber-900">
+      <span>
+        {t("impersonating", { name: user.full_name, email: user.email })}{" "}
+        {t("impersonationEnds", {
+          time: new Date(user.impersonation.expires_at).toLocaleTimeString(),
+        })}
+      </span>
+      <button
+        onClick={handleStop}
+        className="px-3 py-1 bg-amber-600 text-white rounded-md hover:bg-amber-700"
+      >
+        {t("stopImpersonating")}
+      </button>
+    </div>
+  );
+}
 
 function ProtectedContent({ children }: { children: React.ReactNode }) {
   const { loading } = useAuth();
@@ -16,9 +46,12 @@ function ProtectedContent({ children }: { children: React.ReactNode }) {
   }
 
   return (
-    <div className="flex min-h-screen">
-      <Sidebar />
-      <main className="flex-1 p-8">{children}</main>
+    <div className="min-h-screen flex flex-col">
+      <ImpersonationBanner />
+      <div className="flex flex-1">
+        <Sidebar />
+        <main className="flex-1 p-8">{children}</main>
+      </div>
     </div>
   );
 }
@@ -1,110 +1,170 @@
 "use client";
 
-import { useState } from "react";
+import { useEffect, useState } from "react";
 import { useRouter } from "next/navigation";
-import { createInvite } from "@/lib/api";
+import {
+  bulkInvite,
+  createInvite,
+  extendInvite,
+  getInvites,
+  resendInvite,
+  revokeInvite,
+  type BulkInviteResult,
+  type Invite,
+} from "@/lib/api";
+import { useLocale, useTranslations } from "@/lib/i18n";
 
 export default function InviteUserPage() {
   const router = useRouter();
+  const { locale } = useLocale();
+  const t = useTranslations("invites");
+  const tc = useTranslations("common");
   const [form, setForm] = useState({ email: "", full_name: "", role: "employee" });
-  const [inviteLink, setInviteLink] = useState("");
+  const [emailLocale, setEmailLocale] = useState<string>(locale);
+  const [notice, setNotice] = useState<{ ok: boolean; text: string } | null>(null);
   const [error, setError] = useState("");
   const [loading, setLoading] = useState(false);
+  const [csvFile, setCsvFile] = useState<File | null>(null);
+  const [bulkResults, setBulkResults] = useState<BulkInviteResult[] | null>(null);
+  const [bulkLoading, setBulkLoading] = useState(false);
+  const [invites, setInvites] = useState<Invite[]>([]);
 
   function update(field: string, value: string) {
     setForm((prev) => ({ ...prev, [field]: value }));
   }
 
+  function fetchInvites() {
+    getInvites()
+      .then((res) => setInvites((res.data || []).filter((i) => !i.accepted_at && !i.revoked)))
+      .catch(() => setInvites([]));
+  }
+
+  useEffect(() => {
+    fetchInvites();
+  }, []);
+
   async function handleSubmit(e: React.FormEvent) {
     e.preventDefault();
     setError("");
-    setInviteLink("");
+    setNotice(null);
     setLoading(true);
 
     try {
-      const res = await createInvite(form);
-      const link = `${window.location.origin}/invite/${res.data.token}`;
-      setInviteLink(link);
+      const res = await createInvite({ ...form, locale: emailLocale });
+      setNotice(
+        res.data.email_sent
+          ? { ok: true, text: t("sent", { email: res.data.email }) }
+          : { ok: false, text: t("notSent", { email: res.data.email }) }
+      );
+      setForm({ email: "", full_name: "", role: form.role });
+      fetchInvites();
     } catch (err) {
-      setError(err instanceof Error ? err.message : "Failed to create invite");
+      setError(err instanceof Error ? err.message : t("failedCreate"));
     } finally {
       setLoading(false);
     }
   }
 
-  function copyLink() {
-    navigator.clipboard.writeText(inviteLink);
+  async function handleBulk(e: React.FormEvent) {
+    e.preventDefault();
+    if (!csvFile) return;
+    setError("");
+    setBulkResults(null);
+    setBulkLoading(true);
+
+    try {
+      const res = await bulkInvite(await csvFile.text(), emailLocale);
+      setBulkResults(res.data);
+      fetchInvites();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : t("failedBulk"));
+    } finally {
+      setBulkLoading(false);
+    }
   }
 
+  async function handleAction(action: () => Promise<unknown>, failure: string) {
+    try {
+      await action();
+      fetchInvites();
+    } catch (err) {
+      alert(err instanceof Error ? err.message : failure);
+    }
+  }
+
+  const inputClass =
+    "w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500";
+
   return (
-    <div>
-      <h1 className="text-2xl font-bold text-gray-900 mb-6">Invite User</h1>
+    <div className="space-y-6">
+      <div className="flex items-center justify-between">
+        <h1 className="text-2xl font-bold text-gray-900">{t("title")}</h1>
+        <button
+          type="button"
+          onClick={() => router.push("/users")}
+          className="px-4 py-2 bg-gray-100 text-gray-700 rounded-md hover:bg-gray-200 text-sm"
+        >
+          {t("backToUsers")}
+        </button>
+      </div>
 
-      <form onSubmit={handleSubmit} className="max-w-lg bg-white rounded-lg shadow p-6 space-y-4">
-        {error && (
-          <div className="bg-red-50 text-red-600 p-3 rounded text-sm">{error}</div>
-        )}
+      {error && <div className="bg-red-50 text-red-600 p-3 rounded text-sm">{error}</div>}
 
-        {inviteLink && (
-          <div className="bg-green-50 p-4 rounded">
-            <p className="text-sm font-medium text-green-800 mb-2">Invite link created!</p>
-            <div className="flex gap-2">
-              <input
-                type="text"
-                value={inviteLink}
-                readOnly
-                className="flex-1 px-3 py-2 border border-green-300 rounded-md text-sm bg-white"
-              />
-              <button
-                type="button"
-                onClick={copyLink}
-                className="px-3 py-2 bg-green-600 text-white rounded-md hover:bg-green-700 text-sm"
-              >
-                Copy
-              </button>
-            </div>
-            <p className="text-xs text-green-600 mt-2">Share this link with the user. It expires in 72 hours.</p>
+      <div className="max-w-lg">
+        <label htmlFor="email_locale" className="block text-sm font-medium text-gray-700 mb-1">
+          {t("emailLanguage")}
+        </label>
+        <select
+          id="email_locale"
+          value={emailLocale}
+          onChange={(e) => setEmailLocale(e.target.value)}
+          className={inputClass}
+        >
+          <option value="th">ไทย</option>
+          <option value="en">English</option>
+        </select>
+      </div>
+
+      <form onSubmit={handleSubmit} className="max-w-lg bg-white rounded-lg shadow p-6 space-y-4">
+        {notice && (
+          <div className={`p-3 rounded text-sm ${notice.ok ? "bg-green-50 text-green-800" : "bg-yellow-50 text-yellow-800"}`}>
+            {notice.text}
           </div>
         )}
 
         <div>
           <label htmlFor="full_name" className="block text-sm font-medium text-gray-700 mb-1">
-            Full Name
+            {tc("name")}
           </label>
           <input
             id="full_name"
             type="text"
             value={form.full_name}
             onChange={(e) => update("full_name", e.target.value)}
-            className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500"
+            className={inputClass}
             required
           />
         </div>
 
         <div>
           <label htmlFor="email" className="block text-sm font-medium text-gray-700 mb-1">
-            Email
+            {tc("email")}
           </label>
           <input
             id="email"
             type="email"
             value={form.email}
             onChange={(e) => update("email", e.target.value)}
-            className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500"
+            className={inputClass}
             required
           />
         </div>
 
         <div>
           <label htmlFor="role" className="block text-sm font-medium text-gray-700 mb-1">
-            Role
+            {tc("role")}
           </label>
-          <select
-            id="role"
-            value={form.role}
-            onChange={(e) => update("role", e.target.value)}
-            className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500"
-          >
+          <select id="role" value={form.role} onChange={(e) => update("role", e.target.value)} className={inputClass}>
             <option value="employee">Employee</option>
             <option value="manager">Manager</option>
             <option value="hr">HR</option>
@@ -112,23 +172,109 @@ export default function InviteUserPage() {
           </select>
         </div>
 
-        <div className="flex gap-3">
-          <button
-            type="submit"
-            disabled={loading}
-            className="px-4 py-2 bg-blue-600 text-white rounded-md hover:bg-blue-700 disabled:opacity-50 text-sm"
-          >
-            {loading ? "Creating..." : "Create Invite"}
-          </button>
-          <button
-            type="button"
-            onClick={() => router.push("/users")}
-            className="px-4 py-2 bg-gray-100 text-gray-700 rounded-md hover:bg-gray-200 text-sm"
-          >
-            Back to Users
-          </button>
-        </div>
+        <button
+          type="submit"
+          disabled={loading}
+          className="px-4 py-2 bg-blue-600 text-white rounded-md hover:bg-blue-700 disabled:opacity-50 text-sm"
+        >
+          {loading ? t("sending") : t("send")}
+        </button>
       </form>
+
+      <form onSubmit={handleBulk} className="max-w-lg bg-white rounded-lg shadow p-6 space-y-4">
+        <h2 className="text-lg font-semibold text-gray-900">{t("bulkTitle")}</h2>
+        <p className="text-sm text-gray-500">{t("bulkHint")}</p>
+        <input
+          type="file"
+          accept=".csv,text/csv"
+          onChange={(e) => setCsvFile(e.target.files?.[0] ?? null)}
+          className="block text-sm"
+        />
+        <button
+          type="submit"
+          disabled={!csvFile || bulkLoading}
+          className="px-4 py-2 bg-blue-600 text-white rounded-md hover:bg-blue-700 disabled:opacity-50 text-sm"
+        >
+          {bulkLoading ? t("sending") : t("bulkSend")}
+        </button>
+
+        {bulkResults && (
+          <table className="min-w-full text-sm">
+            <thead>
+              <tr className="text-left text-gray-500">
+                <th className="py-1 pr-3">{t("rowCol")}</th>
+                <th className="py-1 pr-3">{tc("email")}</th>
+                <th className="py-1">{tc("status")}</th>
+              </tr>
+            </thead>
+            <tbody>
+              {bulkResults.map((r) => (
+                <tr key={r.row}>
+                  <td className="py-1 pr-3 text-gray-500">{r.row}</td>
+                  <td className="py-1 pr-3">{r.email}</td>
+                  <td className={`py-1 ${r.status === "invited" ? (r.email_sent ? "text-green-700" : "text-yellow-700") : "text-red-600"}`}>
+                    {r.status === "invited" ? (r.email_sent ? t("rowSent") : t("rowNotSent")) : r.error}
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        )}
+      </form>
+
+      <div className="bg-white rounded-lg shadow overflow-hidden">
+        <h2 className="text-lg font-semibold text-gray-900 px-6 pt-4">{t("pendingTitle")}</h2>
+        {invites.length === 0 ? (
+          <p className="px-6 py-4 text-sm text-gray-500">{t("noPending")}</p>
+        ) : (
+          <table className="min-w-full divide-y divide-gray-200">
+            <thead className="bg-gray-50">
+              <tr>
+                <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">{tc("name")}</th>
+                <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">{tc("email")}</th>
+                <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">{tc("role")}</th>
+                <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">{t("expiresCol")}</th>
+                <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">{tc("actions")}</th>
+              </tr>
+            </thead>
+            <tbody className="divide-y divide-gray-200">
+              {invites.map((inv) => {
+                const expired = new Date(inv.expires_at) < new Date();
+                return (
+                  <tr key={inv.id}>
+                    <td className="px-6 py-4 text-sm text-gray-900">{inv.full_name}</td>
+                    <td className="px-6 py-4 text-sm text-gray-500">{inv.email}</td>
+                    <td className="px-6 py-4 text-sm text-gray-500 capitalize">{inv.role}</td>
+                    <td className={`px-6 py-4 text-sm ${expired ? "text-red-600" : "text-gray-500"}`}>
+                      {expired ? t("expired") : new Date(inv.expires_at).toLocaleString()}
+                    </td>
+                    <td className="px-6 py-4 text-sm space-x-3">
+                      <button
+                        onClick={() => handleAction(() => resendInvite(inv.id), t("failedResend"))}
+                        className="text-blue-600 hover:underline"
+                      >
+                        {t("resend")}
+                      </button>
+                      <button
+                        onClick={() => handleAction(() => extendInvite(inv.id), t("failedExtend"))}
+                        className="text-blue-600 hover:underline"
+                      >
+                        {t("extend")}
+                      </button>
+                      <button
+                        onClick={() => handleAction(() => revokeInvite(inv.id), t("failedRevoke"))}
+                        className="text-red-600 hover:underline"
+                      >
+                        {t("revoke")}
+                      </button>
+                    </td>
+                  </tr>
+                );
+              })}
+            </tbody>
+          </table>
+        )}
+      </div>
     </div>
   );
 }
@@ -2,7 +2,7 @@
 
 import { useEffect, useState } from "react";
 import Link from "next/link";
-import { getUsers, changeUserRole, changeUserStatus } from "@/lib/api";
+import { getUsers, changeUserRole, changeUserStatus, impersonateUser } from "@/lib/api";
 import { useAuth } from "@/lib/auth-context";
 import { useTranslations } from "@/lib/i18n";
 
@@ -57,6 +57,19 @@ export default function UsersPage() {
     }
   }
 
+  async function handleImpersonate(userId: string) {
+    const reason = window.prompt(t("impersonateReason"));
+    if (!reason?.trim()) return;
+    try {
+      await impersonateUser(userId, reason.trim());
+      window.location.href = "/dashboard";
+    } catch (err) {
+      alert(err instanceof Error ? err.message : t("failedImpersonate"));
+    }
+  }
+
+  const canImpersonate = me?.permissions?.includes("user.impersonate") && !me?.impersonation;
+
   return (
     <div>
       <div className="flex items-center justify-between mb-6">
@@ -82,6 +95,9 @@ export default function UsersPage() {
                 <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">{t("roleCol")}</th>
                 <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">{tc("status")}</th>
                 <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">{t("employeeIdCol")}</th>
+                {canImpersonate && (
+                  <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase">{tc("actions")}</th>
+                )}
               </tr>
             </thead>
             <tbody className="divide-y divide-gray-200">
@@ -139,12 +155,24 @@ export default function UsersPage() {
                     <td className="px-6 py-4 text-sm text-gray-500">
                       {u.frappe_employee_id || "-"}
                     </td>
+                    {canImpersonate && (
+                      <td className="px-6 py-4 text-sm">
+                        {!isSelf && u.status === "active" && (
+                          <button
+                            onClick={() => handleImpersonate(u.id)}
+                            className="text-blue-600 hover:text-blue-800"
+                          >
+                            {t("viewAs")}
+                          </button>
+                        )}
+                      </td>
+                    )}
                   </tr>
                 );
               })}
               {users.length === 0 && (
                 <tr>
-                  <td colSpan={5} className="px-6 py-4 text-sm text-gray-500 text-center">
+                  <td colSpan={canImpersonate ? 6 : 5} className="px-6 py-4 text-sm text-gray-500 text-center">
                     {t("noUsers")}
                   </td>
                 </tr>
@@ -19,8 +19,9 @@ export default function AcceptInvitePage() {
     setLoading(true);
 
     try {
-      await acceptInvite({ token, ...form });
-      router.push("/dashboard");
+      const res = await acceptInvite({ token, ...form });
+      // Existing accounts join the company and sign in as usual
+      router.push(res.existing_account ? "/login" : "/dashboard");
     } catch (err) {
       setError(err instanceof Error ? err.message : "Failed to accept invite");
     } finally {
@@ -55,8 +56,10 @@ export default function AcceptInvitePage() {
               value={form.full_name}
               onChange={(e) => setForm({ ...form, full_name: e.target.value })}
               className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500"
-              required
             />
+            <p className="text-xs text-gray-400 mt-1">
+              Leave blank if you already have an account
+            </p>
           </div>
 
           <div>
@@ -72,7 +75,9 @@ export default function AcceptInvitePage() {
               minLength={8}
               required
             />
-            <p className="text-xs text-gray-400 mt-1">Minimum 8 characters</p>
+            <p className="text-xs text-gray-400 mt-1">
+              Minimum 8 characters, or your current password if you already have an account
+            </p>
           </div>
 
           <button
@@ -1,9 +1,9 @@
 "use client";
 
-import { useState } from "react";
+import { useEffect, useState } from "react";
 import { useRouter } from "next/navigation";
 import Link from "next/link";
-import { login } from "@/lib/api";
+import { login, verifyMfa, enrollMfa, ssoLoginUrl, type MfaChallenge } from "@/lib/api";
 import { useTranslations } from "@/lib/i18n";
 import LocaleSwitcher from "@/components/ui/LocaleSwitcher";
 
@@ -14,6 +14,44 @@ export default function LoginPage() {
   const [password, setPassword] = useState("");
   const [error, setError] = useState("");
   const [loading, setLoading] = useState(false);
+  const [challenge, setChallenge] = useState<MfaChallenge | null>(null);
+  const [setupKey, setSetupKey] = useState("");
+  const [code, setCode] = useState("");
+  const [useRecovery, setUseRecovery] = useState(false);
+  const [recoveryCodes, setRecoveryCodes] = useState<string[]>([]);
+  const [companySlug, setCompanySlug] = useState("");
+  const [redirectTo, setRedirectTo] = useState("/dashboard");
+
+  useEffect(() => {
+    const ssoError = new URLSearchParams(window.location.search).get("sso_error");
+    if (ssoError) setError(t("ssoFailed", { code: ssoError }));
+  }, [t]);
+
+  // An SSO login that needs a second factor comes back with its challenge in the fragment.
+  useEffect(() => {
+    const params = new URLSearchParams(window.location.hash.slice(1));
+    const token = params.get("mfa_challenge");
+    if (!token) return;
+    window.history.replaceState(null, "", window.location.pathname + window.location.search);
+
+    const redirect = params.get("redirect") ?? "";
+    if (redirect.startsWith("/") && !redirect.startsWith("//") && !redirect.startsWith("/\\")) {
+      setRedirectTo(redirect);
+    }
+    const enroll = params.get("enroll") === "1";
+    setChallenge({ mfa_required: true, enrollment_required: enroll, challenge_token: token, expires_at: "" });
+    if (enroll) {
+      enrollMfa(token)
+        .then((setup) => setSetupKey(setup.secret))
+        .catch((err) => setError(err instanceof Error ? err.message : t("loginFailed")));
+    }
+  }, [t]);
+
+  function handleSso(e: React.FormEvent) {
+    e.preventDefault();
+    if (!companySlug.trim()) return;
+    window.location.href = ssoLoginUrl(companySlug.trim());
+  }
 
   async function handleSubmit(e: React.FormEvent) {
     e.preventDefault();
@@ -21,7 +59,15 @@ export default function LoginPage() {
     setLoading(true);
 
     try {
-      await login(email, password);
+      const res = await login(email, password);
+      if ("mfa_required" in res) {
+        setChallenge(res);
+        if (res.enrollment_required) {
+          const setup = await enrollMfa(res.challenge_token);
+          setSetupKey(setup.secret);
+        }
+        return;
+      }
       router.push("/dashboard");
     } catch (err) {
       setError(err instanceof Error ? err.message : t("loginFailed"));
@@ -30,6 +76,115 @@ export default function LoginPage() {
     }
   }
 
+  async function handleVerify(e: React.FormEvent) {
+    e.preventDefault();
+    if (!challenge) return;
+    setError("");
+    setLoading(true);
+
+    try {
+      const res = await verifyMfa(
+        challenge.challenge_token,
+        useRecovery ? { recovery_code: code } : { code }
+      );
+      if (res.recovery_codes?.length) {
+        setRecoveryCodes(res.recovery_codes);
+        return;
+      }
+      router.push(redirectTo);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : t("loginFailed"));
+    } finally {
+      setLoading(false);
+    }
+  }
+
+  if (recoveryCodes.length > 0) {
+    return (
+      <div className="min-h-screen flex items-center justify-center bg-gray-50">
+        <div className="max-w-md w-full bg-white rounded-lg shadow-md p-8">
+          <h1 className="text-2xl font-bold text-center text-gray-900 mb-2">{t("mfaRecoveryTitle")}</h1>
+          <p className="text-center text-sm text-gray-500 mb-6">{t("mfaRecoveryHint")}</p>
+          <ul className="grid grid-cols-2 gap-2 font-mono text-sm mb-6">
+            {recoveryCodes.map((rc) => (
+              <li key={rc} className="bg-gray-100 rounded px-2 py-1 text-center">{rc}</li>
+            ))}
+          </ul>
+          <button
+            onClick={() => router.push(redirectTo)}
+            className="w-full py-2 px-4 bg-blue-600 text-white rounded-md hover:bg-blue-700"
+          >
+            {t("continue")}
+          </button>
+        </div>
+      </div>
+    );
+  }
+
+  if (challenge) {
+    return (
+      <div className="min-h-screen flex items-center justify-center bg-gray-50">
+        <div className="max-w-md w-full bg-white rounded-lg shadow-md p-8">
+          <h1 className="text-2xl font-bold text-center text-gray-900 mb-2">{t("mfaTitle")}</h1>
+          <p className="text-center text-sm text-gray-500 mb-6">
+            {challenge.enrollment_required ? t("mfaEnrollPrompt") : t("mfaPrompt")}
+          </p>
+
+          <form onSubmit={handleVerify} className="space-y-6">
+            {error && (
+              <div className="bg-red-50 text-red-600 p-3 rounded text-sm">
+                {error}
+              </div>
+            )}
+
+            {setupKey && (
+              <div>
+                <p className="block text-sm font-medium text-gray-700 mb-1">{t("mfaSecret")}</p>
+                <p className="font-mono text-sm break-all bg-gray-100 rounded px-3 py-2">{setupKey}</p>
+              </div>
+            )}
+
+            <div>
+              <label htmlFor="code" className="block text-sm font-medium text-gray-700 mb-1">
+                {useRecovery ? t("mfaRecoveryCode") : t("mfaCode")}
+              </label>
+              <input
+                id="code"
+                type="text"
+                inputMode={useRecovery ? "text" : "numeric"}
+                autoComplete="one-time-code"
+                value={code}
+                onChange={(e) => setCode(e.target.value)}
+                className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500"
+                required
+              />
+            </div>
+
+            <button
+              type="submit"
+              disabled={loading}
+              className="w-full py-2 px-4 bg-blue-600 text-white rounded-md hover:bg-blue-700 disabled:opacity-50 disabled:cursor-not-allowed"
+            >
+              {t("mfaVerify")}
+            </button>
+          </form>
+
+          {!challenge.enrollment_required && (
+            <button
+              onClick={() => {
+                setUseRecovery(!useRecovery);
+                setCode("");
+              }}
+              className="mt-4 w-full text-center text-sm text-blue-600 hover:text-blue-500"
+            >
+              {useRecovery ? t("mfaUseCode") : t("mfaUseRecovery")}
+            </button>
+          )}
+        </div>
+      </div>
+    );
+  }
+
   return (
     <div className="min-h-screen flex items-center justify-center bg-gray-50">
       <div className="max-w-md w-full bg-white rounded-lg shadow-md p-8">
@@ -84,6 +239,28 @@ export default function LoginPage() {
           </button>
         </form>
 
+        <form onSubmit={handleSso} className="mt-6 pt-6 border-t border-gray-200 space-y-3">
+          <label htmlFor="company" className="block text-sm font-medium text-gray-700">
+            {t("ssoLabel")}
+          </label>
+          <div className="flex gap-2">
+            <input
+              id="company"
+              type="text"
+              placeholder={t("ssoPlaceholder")}
+              value={companySlug}
+              onChange={(e) => setCompanySlug(e.target.value)}
+              className="flex-1 px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-2 focus:ring-blue-500"
+            />
+            <button
+              type="submit"
+              className="py-2 px-4 border border-blue-600 text-blue-600 rounded-md hover:bg-blue-50"
+            >
+              {t("ssoButton")}
+            </button>
+          </div>
+        </form>
+
         <p className="mt-6 text-center text-sm text-gray-500">
           {t("noAccount")}{" "}
           <Link href="/signup" className="text-blue-600 hover:text-blue-500 font-medium">
@@ -1,14 +1,13 @@
 "use client";
 
 import { useState } from "react";
-import { useRouter } from "next/navigation";
 import Link from "next/link";
 import { signup } from "@/lib/api";
-import { useTranslations } from "@/lib/i18n";
+import { useLocale, useTranslations } from "@/lib/i18n";
 import LocaleSwitcher from "@/components/ui/LocaleSwitcher";
 
 export default function SignupPage() {
-  const router = useRouter();
+  const { locale } = useLocale();
   const t = useTranslations("signup");
   const [form, setForm] = useState({
     company_name: "",
@@ -18,6 +17,7 @@ export default function SignupPage() {
   });
   const [error, setError] = useState("");
   const [loading, setLoading] = useState(false);
+  const [sentTo, setSentTo] = useState("");
 
   function update(field: string, value: string) {
     setForm((prev) => ({ ...prev, [field]: value }));
@@ -29,8 +29,8 @@ export default function SignupPage() {
     setLoading(true);
 
     try {
-      await signup(form);
-      router.push("/dashboard");
+      await signup({ ...form, locale });
+      setSentTo(form.email);
     } catch (err) {
       setError(err instanceof Error ? err.message : t("signupFailed"));
     } finally {
@@ -38,6 +38,24 @@ export default function SignupPage() {
     }
   }
 
+  if (sentTo) {
+    return (
+      <div className="min-h-screen flex items-center justify-center bg-gray-50">
+        <div className="max-w-md w-full bg-white rounded-lg shadow-md p-8 text-center">
+          <h1 className="text-2xl font-bold text-gray-900 mb-2">{t("checkEmailTitle")}</h1>
+          <p className="text-sm text-gray-500 mb-6">{t("checkEmailBody", { email: sentTo })}</p>
+          <button
+            type="button"
+            onClick={() => setSentTo("")}
+            className="text-sm text-blue-600 hover:text-blue-500 font-medium"
+          >
+            {t("useDifferentEmail")}
+          </button>
+        </div>
+      </div>
+    );
+  }
+
   return (
     <div className="min-h-screen flex items-center justify-center bg-gray-50">
       <div className="max-w-md w-full bg-white rounded-lg shadow-md p-8">
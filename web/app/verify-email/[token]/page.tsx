@@ -0,0 +1,59 @@
+"use client";
+
+import { useState } from "react";
+import { useRouter, useParams } from "next/navigation";
+import { verifyEmail } from "@/lib/api";
+import { useTranslations } from "@/lib/i18n";
+
+// Verification waits for a click rather than running on load, so mail scanners that
+// prefetch links do not create the company.
+export default function VerifyEmailPage() {
+  const router = useRouter();
+  const params = useParams();
+  const token = params.token as string;
+  const t = useTranslations("signup");
+
+  const [error, setError] = useState("");
+  const [loading, setLoading] = useState(false);
+
+  async function handleConfirm() {
+    setError("");
+    setLoading(true);
+
+    try {
+      await verifyEmail(token);
+      router.push("/dashboard");
+    } catch (err) {
+      setError(err instanceof Error ? err.message : t("verifyFailed"));
+      setLoading(false);
+    }
+  }
+
+  return (
+    <div className="min-h-screen flex items-center justify-center bg-gray-50">
+      <div className="max-w-md w-full bg-white rounded-lg shadow-md p-8">
+        <h1 className="text-2xl font-bold text-center text-gray-900 mb-2">
+          {t("verifyTitle")}
+        </h1>
+        <p className="text-center text-sm text-gray-500 mb-8">
+          {t("verifySubtitle")}
+        </p>
+
+        {error && (
+          <div className="bg-red-50 text-red-600 p-3 rounded text-sm mb-5">
+            {error}
+          </div>
+        )}
+
+        <button
+          type="button"
+          onClick={handleConfirm}
+          disabled={loading}
+          className="w-full py-2 px-4 bg-blue-600 text-white rounded-md hover:bg-blue-700 disabled:opacity-50 disabled:cursor-not-allowed"
+        >
+          {loading ? t("creatingAccount") : t("verifyButton")}
+        </button>
+      </div>
+    </div>
+  );
+}
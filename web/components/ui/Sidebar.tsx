@@ -4,6 +4,7 @@ import { useState } from "react";
 import Link from "next/link";
 import { usePathname } from "next/navigation";
 import { useAuth } from "@/lib/auth-context";
+import { switchCompany } from "@/lib/api";
 import { getMenuForRole } from "@/lib/role-menu";
 import { useTranslations } from "@/lib/i18n";
 import LocaleSwitcher from "@/components/ui/LocaleSwitcher";
@@ -18,6 +19,16 @@ export default function Sidebar() {
 
   const menuItems = user ? getMenuForRole(user.role) : [];
 
+  async function handleSwitchCompany(companyId: string) {
+    if (!user || companyId === user.company_id) return;
+    try {
+      await switchCompany(companyId);
+      window.location.href = "/dashboard";
+    } catch (err) {
+      window.alert(err instanceof Error ? err.message : t("common.failedSwitchCompany"));
+    }
+  }
+
   return (
     <>
       <aside className="w-64 bg-white border-r border-gray-200 min-h-screen p-4 flex flex-col">
@@ -27,11 +38,27 @@ export default function Sidebar() {
             <h2 className="text-xl font-bold text-gray-800">
               {t("common.appName")}
             </h2>
-            {user && (
-              <p className="text-xs text-gray-400 mt-1 truncate">
-                {user.company_name}
-              </p>
-            )}
+            {user &&
+              (user.memberships && user.memberships.length > 1 ? (
+                <select
+                  value={user.company_id}
+                  onChange={(e) => handleSwitchCompany(e.target.value)}
+                  title={t("common.switchCompany")}
+                  className="text-xs text-gray-500 mt-1 max-w-[9rem] truncate bg-transparent border-none p-0 focus:ring-0"
+                >
+                  {user.memberships
+                    .filter((m) => m.status === "active")
+                    .map((m) => (
+                      <option key={m.company_id} value={m.company_id}>
+                        {m.company_name}
+                      </option>
+                    ))}
+                </select>
+              ) : (
+                <p className="text-xs text-gray-400 mt-1 truncate">
+                  {user.company_name}
+                </p>
+              ))}
           </div>
           <div className="flex items-center gap-1">
             {/* AI Chat button */}
@@ -14,20 +14,67 @@ export interface AuthUser {
   company_id: string;
   company_name: string;
   frappe_employee_id?: string;
+  mfa_enabled?: boolean;
+  role_name?: string;
+  permissions?: string[];
+  impersonation?: {
+    impersonator_id: string;
+    impersonator_name: string;
+    impersonator_email: string;
+    expires_at: string;
+  };
+  memberships?: Membership[];
+}
+
+export interface Membership {
+  id: string;
+  company_id: string;
+  company_name: string;
+  role: AuthUser["role"];
+  status: string;
+  last_used_at?: string;
+}
+
+let refreshing: Promise<boolean> | null = null;
+
+// refreshSession rotates the refresh cookie for a new access token.
+// Concurrent 401s share a single refresh request.
+export function refreshSession(): Promise<boolean> {
+  if (!refreshing) {
+    refreshing = fetch(`${API_BASE}/auth/refresh`, {
+      method: "POST",
+      credentials: "include",
+    })
+      .then((res) => res.ok)
+      .catch(() => false)
+      .finally(() => {
+        refreshing = null;
+      });
+  }
+  return refreshing;
 }
 
 export async function api<T>(path: string, options: ApiOptions = {}): Promise<T> {
   const { method = "GET", body, headers = {} } = options;
 
-  const res = await fetch(`${API_BASE}${path}`, {
-    method,
-    headers: {
-      "Content-Type": "application/json",
-      ...headers,
-    },
-    body: body ? JSON.stringify(body) : undefined,
-    credentials: "include",
-  });
+  // The browser sets a FormData body's multipart Content-Type, boundary included
+  const form = body instanceof FormData;
+  const send = () =>
+    fetch(`${API_BASE}${path}`, {
+      method,
+      headers: {
+        ...(form ? {} : { "Content-Type": "application/json" }),
+        ...headers,
+      },
+      // Strings are sent as-is, for non-JSON bodies such as CSV uploads
+      body: form || typeof body === "string" ? (body as BodyInit) : body ? JSON.stringify(body) : undefined,
+      credentials: "include",
+    });
+
+  let res = await send();
+  if (res.status === 401 && !path.startsWith("/auth/") && (await refreshSession())) {
+    res = await send();
+  }
 
   if (!res.ok) {
     const error = await res.json().catch(() => ({ message: "Request failed" }));
@@ -38,25 +85,66 @@ export async function api<T>(path: string, options: ApiOptions = {}): Promise<T>
 }
 
 // Auth
+export interface MfaChallenge {
+  mfa_required: true;
+  enrollment_required: boolean;
+  challenge_token: string;
+  expires_at: string;
+}
+
+export interface LoginResult {
+  token: string;
+  user: AuthUser;
+  recovery_codes?: string[];
+}
+
 export async function login(email: string, password: string) {
-  return api<{ token: string; user: AuthUser }>("/auth/login", {
+  return api<LoginResult | MfaChallenge>("/auth/login", {
     method: "POST",
     body: { email, password },
   });
 }
 
+// SSO is a full-page redirect through the company's identity provider, not a fetch.
+export function ssoLoginUrl(companySlug: string) {
+  return `${API_BASE}/auth/oidc/${encodeURIComponent(companySlug)}/login?redirect=/dashboard`;
+}
+
+export async function verifyMfa(challenge_token: string, code: { code?: string; recovery_code?: string }) {
+  return api<LoginResult>("/auth/mfa/verify", {
+    method: "POST",
+    body: { challenge_token, ...code },
+  });
+}
+
+export async function enrollMfa(challenge_token: string) {
+  return api<{ secret: string; otpauth_url: string }>("/auth/mfa/enroll", {
+    method: "POST",
+    body: { challenge_token },
+  });
+}
+
+// Signup only emails a verification link; the company is created by verifyEmail.
 export async function signup(data: {
   company_name: string;
   email: string;
   password: string;
   full_name: string;
+  locale?: string;
 }) {
-  return api<{ token: string; user: AuthUser }>("/auth/signup", {
+  return api<{ message: string; verification_required: boolean }>("/auth/signup", {
     method: "POST",
     body: data,
   });
 }
 
+export async function verifyEmail(token: string) {
+  return api<{ token: string; user: AuthUser }>("/auth/verify-email", {
+    method: "POST",
+    body: { token },
+  });
+}
+
 export async function logout() {
   return api<{ message: string }>("/auth/logout", { method: "POST" });
 }
@@ -65,6 +153,146 @@ export async function getMe() {
   return api<AuthUser>("/me");
 }
 
+export async function switchCompany(company_id: string) {
+  return api<{ token: string; user: AuthUser }>("/auth/switch-company", {
+    method: "POST",
+    body: { company_id },
+  });
+}
+
+export async function forgotPassword(email: string) {
+  return api<{ message: string }>("/auth/forgot-password", {
+    method: "POST",
+    body: { email },
+  });
+}
+
+export async function resetPassword(token: string, password: string) {
+  return api<{ message: string }>("/auth/reset-password", {
+    method: "POST",
+    body: { token, password },
+  });
+}
+
+export async function changePassword(current_password: string, new_password: string) {
+  return api<{ message: string }>("/auth/password", {
+    method: "PUT",
+    body: { current_password, new_password },
+  });
+}
+
+export interface Session {
+  id: string;
+  user_agent: string;
+  ip_address: string;
+  created_at: string;
+  last_used_at: string;
+  expires_at: string;
+  current: boolean;
+}
+
+export async function getSessions() {
+  return api<{ data: Session[] }>("/auth/sessions");
+}
+
+export async function revokeSession(id: string) {
+  return api<{ message: string }>(`/auth/sessions/${id}`, { method: "DELETE" });
+}
+
+// API keys
+export interface ApiKey {
+  id: string;
+  user_id: string;
+  name: string;
+  key_prefix: string;
+  scopes: string[];
+  expires_at?: string;
+  last_used_at?: string;
+  last_used_ip?: string;
+  revoked_at?: string;
+  created_at: string;
+  key?: string; // only present right after creation
+}
+
+export async function getApiKeys() {
+  return api<{ data: ApiKey[] }>("/api-keys");
+}
+
+export async function createApiKey(name: string, scopes: string[], expires_in_days?: number) {
+  return api<{ data: ApiKey }>("/api-keys", {
+    method: "POST",
+    body: { name, scopes, expires_in_days },
+  });
+}
+
+export async function revokeApiKey(id: string) {
+  return api<{ message: string }>(`/api-keys/${id}`, { method: "DELETE" });
+}
+
+// SCIM provisioning
+export interface ScimConfig {
+  enabled: boolean;
+  token_prefix?: string;
+  create_employees: boolean;
+  base_url: string;
+  token?: string; // only present right after rotation
+}
+
+export async function getScimConfig() {
+  return api<ScimConfig>("/company/scim");
+}
+
+export async function updateScimConfig(create_employees: boolean) {
+  return api<ScimConfig>("/company/scim", {
+    method: "PUT",
+    body: { create_employees },
+  });
+}
+
+export async function rotateScimToken() {
+  return api<ScimConfig>("/company/scim/token", { method: "POST" });
+}
+
+export async function revokeScimToken() {
+  return api<{ message: string }>("/company/scim/token", { method: "DELETE" });
+}
+
+// Frappe setup of a new company
+export interface ProvisioningStep {
+  id: string;
+  step: string;
+  status: "pending" | "running" | "done" | "failed";
+  attempts: number;
+  last_error?: string;
+  next_run_at: string;
+  completed_at?: string;
+}
+
+export interface ProvisioningStatus {
+  status: "ready" | "in_progress" | "failed";
+  steps: ProvisioningStep[];
+}
+
+export async function getProvisioningStatus() {
+  return api<ProvisioningStatus>("/company/provisioning");
+}
+
+export async function retryProvisioning() {
+  return api<ProvisioningStatus>("/company/provisioning/retry", { method: "POST" });
+}
+
+// Email domain allow-list for invites and SSO
+export async function getEmailDomains() {
+  return api<{ allowed_domains: string[] }>("/company/email-domains");
+}
+
+export async function updateEmailDomains(allowed_domains: string[]) {
+  return api<{ allowed_domains: string[] }>("/company/email-domains", {
+    method: "PUT",
+    body: { allowed_domains },
+  });
+}
+
 // Employees
 export async function getEmployees() {
   return api<{ data: Array<Record<string, string>> }>("/employees");
@@ -122,18 +350,22 @@ export async function getEmployeeDocuments(id: string) {
   return api<{ data: Array<Record<string, unknown>> }>(`/employees/${id}/documents`);
 }
 
-export async function uploadEmployeeDocument(
-  id: string,
-  filename: string,
-  content: string,
-  docType?: string
-) {
+export async function uploadEmployeeDocument(id: string, file: File, docType?: string) {
+  const form = new FormData();
+  // doc_type must come before the file, which the BFF streams on as it arrives
+  if (docType) form.append("doc_type", docType);
+  form.append("file", file);
   return api<{ data: Record<string, unknown> }>(`/employees/${id}/documents`, {
     method: "POST",
-    body: { filename, content, doc_type: docType },
+    body: form,
   });
 }
 
+// Downloads are links to the BFF, which streams the file from Frappe with the session cookie.
+export function employeeDocumentDownloadUrl(employeeId: string, docId: string) {
+  return `${API_BASE}/employees/${employeeId}/documents/${encodeURIComponent(docId)}/download`;
+}
+
 export async function getEmployeePromotions(id: string) {
   return api<{ data: Array<Record<string, unknown>> }>(`/employees/${id}/promotions`);
 }
@@ -278,13 +510,24 @@ export async function getUser(id: string) {
   return api<{ data: Record<string, unknown> }>(`/users/${id}`);
 }
 
-export async function changeUserRole(id: string, role: string) {
+export async function changeUserRole(id: string, role: string, role_id?: string) {
   return api<{ message: string }>(`/users/${id}/role`, {
     method: "PUT",
-    body: { role },
+    body: role_id ? { role_id } : { role },
   });
 }
 
+export async function impersonateUser(id: string, reason: string, minutes?: number) {
+  return api<{ token: string; user: AuthUser }>(`/users/${id}/impersonate`, {
+    method: "POST",
+    body: { reason, minutes },
+  });
+}
+
+export async function stopImpersonation() {
+  return api<{ message: string }>("/auth/impersonation/stop", { method: "POST" });
+}
+
 export async function changeUserStatus(id: string, status: string) {
   return api<{ message: string }>(`/users/${id}/status`, {
     method: "PUT",
@@ -292,28 +535,108 @@ export async function changeUserStatus(id: string, status: string) {
   });
 }
 
+// Roles
+export interface Role {
+  id: string;
+  company_id?: string;
+  slug: string;
+  name: string;
+  description: string;
+  base_role: "hr" | "manager" | "employee" | "admin";
+  permissions: string[];
+}
+
+export interface RoleInput {
+  name: string;
+  description: string;
+  base_role: string;
+  permissions: string[];
+}
+
+export async function getRoles() {
+  return api<{ data: Role[] }>("/roles");
+}
+
+export async function getPermissions() {
+  return api<{ data: Array<{ name: string; description: string }> }>("/permissions");
+}
+
+export async function createRole(data: RoleInput) {
+  return api<{ data: Role }>("/roles", { method: "POST", body: data });
+}
+
+export async function updateRole(id: string, data: RoleInput) {
+  return api<{ data: Role }>(`/roles/${id}`, { method: "PUT", body: data });
+}
+
+export async function deleteRole(id: string) {
+  return api<{ message: string }>(`/roles/${id}`, { method: "DELETE" });
+}
+
 // Invites
+export interface Invite {
+  id: string;
+  email: string;
+  full_name: string;
+  locale: "th" | "en";
+  role: string;
+  expires_at: string;
+  accepted_at?: string;
+  revoked: boolean;
+  last_sent_at?: string;
+  send_count: number;
+  created_at: string;
+}
+
+export interface BulkInviteResult {
+  row: number;
+  email: string;
+  status: "invited" | "failed";
+  invite_id?: string;
+  email_sent: boolean;
+  error?: string;
+}
+
 export async function createInvite(data: {
   email: string;
   role: string;
   full_name: string;
+  locale?: string;
 }) {
-  return api<{ data: { id: string; token: string; email: string } }>("/invites", {
+  return api<{ data: { id: string; email: string; expires_at: string; email_sent: boolean } }>("/invites", {
     method: "POST",
     body: data,
   });
 }
 
 export async function getInvites() {
-  return api<{ data: Array<Record<string, unknown>> }>("/invites");
+  return api<{ data: Invite[] }>("/invites");
+}
+
+export async function resendInvite(id: string) {
+  return api<{ data: Invite }>(`/invites/${id}/resend`, { method: "POST" });
+}
+
+export async function extendInvite(id: string, days?: number) {
+  return api<{ data: Invite }>(`/invites/${id}/extend`, { method: "POST", body: { days } });
+}
+
+// bulkInvite sends a CSV with email, name, role and employee_id columns.
+export async function bulkInvite(csv: string, locale?: string) {
+  const query = locale ? `?locale=${locale}` : "";
+  return api<{ data: BulkInviteResult[]; summary: { invited: number; failed: number } }>(`/invites/bulk${query}`, {
+    method: "POST",
+    body: csv,
+    headers: { "Content-Type": "text/csv" },
+  });
 }
 
 export async function acceptInvite(data: {
   token: string;
   password: string;
-  full_name: string;
+  full_name?: string;
 }) {
-  return api<{ token: string; user: AuthUser }>("/invites/accept", {
+  return api<{ token?: string; user?: AuthUser; existing_account?: boolean }>("/invites/accept", {
     method: "POST",
     body: data,
   });
@@ -1,3 +1,5 @@
+import { refreshSession } from "./api";
+
 const API_BASE = process.env.NEXT_PUBLIC_API_URL || "/api";
 
 export interface ChatMessage {
@@ -17,13 +19,19 @@ export async function streamChat(
   callbacks: StreamCallbacks,
   signal?: AbortSignal
 ) {
-  const res = await fetch(`${API_BASE}/chat`, {
-    method: "POST",
-    headers: { "Content-Type": "application/json" },
-    body: JSON.stringify({ messages }),
-    credentials: "include",
-    signal,
-  });
+  const send = () =>
+    fetch(`${API_BASE}/chat`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ messages }),
+      credentials: "include",
+      signal,
+    });
+
+  let res = await send();
+  if (res.status === 401 && (await refreshSession())) {
+    res = await send();
+  }
 
   if (!res.ok) {
     const err = await res.json().catch(() => ({ message: "Request failed" }));